@@ -27,7 +27,7 @@ pub struct Buffer {
 	pub b: HashMap<ChangeKey, TableMutations>,
 }
 
-#[derive(Hash, Eq, PartialEq, Debug)]
+#[derive(Clone, Hash, Eq, PartialEq, Debug)]
 #[non_exhaustive]
 pub struct ChangeKey {
 	pub ns: String,
@@ -121,6 +121,23 @@ impl Writer {
 		)
 	}
 
+	// checkpoint returns the number of mutations currently buffered for each table,
+	// so that the buffer can later be truncated back to this point.
+	pub(crate) fn checkpoint(&self) -> HashMap<ChangeKey, usize> {
+		self.buf.b.iter().map(|(k, ms)| (k.clone(), ms.1.len())).collect()
+	}
+
+	// truncate discards any mutations buffered since the specified checkpoint was taken.
+	pub(crate) fn truncate(&mut self, checkpoint: &HashMap<ChangeKey, usize>) {
+		self.buf.b.retain(|k, ms| match checkpoint.get(k) {
+			Some(len) => {
+				ms.1.truncate(*len);
+				true
+			}
+			None => false,
+		});
+	}
+
 	// get returns all the mutations buffered for this transaction,
 	// that are to be written onto the key composed of the specified prefix + the current timestamp + the specified suffix.
 	pub(crate) fn get(&self) -> Vec<PreparedWrite> {
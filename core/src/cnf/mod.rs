@@ -20,6 +20,60 @@ pub const MAX_CONCURRENT_TASKS: usize = 64;
 pub static MAX_COMPUTATION_DEPTH: Lazy<u32> =
 	lazy_env_parse!("SURREAL_MAX_COMPUTATION_DEPTH", u32, 120);
 
+/// Specifies how many times a recursive graph traversal (`->edge->node DEPTH n`) will be
+/// repeated. This is used when no depth is given, and caps any depth which is given.
+pub static MAX_GRAPH_TRAVERSAL_DEPTH: Lazy<u32> =
+	lazy_env_parse!("SURREAL_MAX_GRAPH_TRAVERSAL_DEPTH", u32, 16);
+
+/// Specifies how many rows a statement can return before the query fails with
+/// [`crate::err::Error::ResultRowsExceeded`]. The limit is disabled when set to 0, which is the default.
+pub static MAX_RESULT_ROWS: Lazy<usize> = lazy_env_parse!("SURREAL_MAX_RESULT_ROWS", usize, 0);
+
+/// Specifies how many records a statement can create, update, or delete before the query fails
+/// with [`crate::err::Error::MutatedRecordsExceeded`]. The limit is disabled when set to 0, which
+/// is the default.
+pub static MAX_MUTATED_RECORDS: Lazy<usize> =
+	lazy_env_parse!("SURREAL_MAX_MUTATED_RECORDS", usize, 0);
+
+/// Specifies how many parsed queries are cached by each datastore. Set to 0 to disable the cache.
+pub static QUERY_CACHE_SIZE: Lazy<usize> = lazy_env_parse!("SURREAL_QUERY_CACHE_SIZE", usize, 1000);
+
+/// Specifies how many results of SELECT statements with a CACHE clause are cached by each datastore. The cache is disabled when set to 0, which is the default. While the cache is enabled, each write transaction also stores a new version of each table which it writes to, so concurrent writes to the same table conflict with each other.
+pub static RESULT_CACHE_SIZE: Lazy<usize> = lazy_env_parse!("SURREAL_RESULT_CACHE_SIZE", usize, 0);
+
+/// Specifies how many seconds a cached result is kept for, when the CACHE clause does not specify a duration.
+pub static RESULT_CACHE_TTL: Lazy<u64> = lazy_env_parse!("SURREAL_RESULT_CACHE_TTL", u64, 60);
+
+/// Specifies how many times a statement is attempted when its transaction conflicts with another transaction. Set to 1 to disable retries.
+pub static TRANSACTION_RETRY_ATTEMPTS: Lazy<u32> =
+	lazy_env_parse!("SURREAL_TRANSACTION_RETRY_ATTEMPTS", u32, 5);
+
+/// The delay, in milliseconds, before a conflicting statement is first retried. The delay doubles with each further attempt, up to 1024 times this delay.
+pub static TRANSACTION_RETRY_BACKOFF: Lazy<u64> =
+	lazy_env_parse!("SURREAL_TRANSACTION_RETRY_BACKOFF", u64, 10);
+
+/// The duration, in milliseconds, after which a statement is logged as a slow query. Set to 0 to disable the slow query log.
+pub static SLOW_QUERY_THRESHOLD: Lazy<u64> =
+	lazy_env_parse!("SURREAL_SLOW_QUERY_THRESHOLD", u64, 0);
+
+/// The number of seconds for which a node holds the lease on a running DEFINE TASK definition.
+/// Once the lease expires, another node can run the task, even if the previous run has not finished.
+pub static TASK_LEASE_DURATION: Lazy<u64> =
+	lazy_env_parse!("SURREAL_TASK_LEASE_DURATION", u64, 600);
+
+/// The memory cost, in KiB, used when generating argon2 password hashes.
+pub static ARGON2_MEMORY_COST: Lazy<u32> =
+	lazy_env_parse!("SURREAL_ARGON2_MEMORY_COST", u32, 19_456);
+
+/// The number of iterations used when generating argon2 password hashes.
+pub static ARGON2_TIME_COST: Lazy<u32> = lazy_env_parse!("SURREAL_ARGON2_TIME_COST", u32, 2);
+
+/// The degree of parallelism used when generating argon2 password hashes.
+pub static ARGON2_PARALLELISM: Lazy<u32> = lazy_env_parse!("SURREAL_ARGON2_PARALLELISM", u32, 1);
+
+/// The cost factor used when generating bcrypt password hashes.
+pub static BCRYPT_COST: Lazy<u32> = lazy_env_parse!("SURREAL_BCRYPT_COST", u32, 12);
+
 /// Specifies the names of parameters which can not be specified in a query.
 pub const PROTECTED_PARAM_NAMES: &[&str] = &["access", "auth", "token", "session"];
 
@@ -35,6 +89,9 @@ pub const SERVER_NAME: &str = "SurrealDB";
 /// Datastore processor batch size for scan operations
 pub const PROCESSOR_BATCH_SIZE: u32 = 50;
 
+/// The maximum number of records which EXPLAIN counts in each table or index, when estimating how many records a statement iterates
+pub const EXPLAIN_SAMPLE_SIZE: usize = 1000;
+
 /// Forward all signup/signin query errors to a client performing record access. Do not use in production.
 pub static INSECURE_FORWARD_RECORD_ACCESS_ERRORS: Lazy<bool> =
 	lazy_env_parse!("SURREAL_INSECURE_FORWARD_RECORD_ACCESS_ERRORS", bool, false);
@@ -53,3 +110,22 @@ pub static EXTERNAL_SORTING_BUFFER_LIMIT: Lazy<usize> =
 
 /// The number of records that should be fetched and grouped together in an INSERT statement when exporting.
 pub static EXPORT_BATCH_SIZE: Lazy<u32> = lazy_env_parse!("SURREAL_EXPORT_BATCH_SIZE", u32, 1000);
+
+/// Specifies how many seconds a session is trusted for after its token was last checked against
+/// the revocation list. Tokens which are revoked on another node are rejected after at most this interval.
+pub static REVOCATION_CHECK_INTERVAL: Lazy<u64> =
+	lazy_env_parse!("SURREAL_REVOCATION_CHECK_INTERVAL", u64, 5);
+
+/// Specifies how many seconds a refresh token is valid for, when the record access method does not
+/// specify a grant duration.
+pub static REFRESH_TOKEN_EXPIRY: Lazy<u64> =
+	lazy_env_parse!("SURREAL_REFRESH_TOKEN_EXPIRY", u64, 30 * 24 * 60 * 60);
+
+/// The key used to sign the pagination cursors which are returned from SELECT statements.
+/// If the environment variable is not present, a random key is generated once and stored
+/// in the datastore, so that cursors remain valid across restarts and across nodes.
+pub static CURSOR_SECRET: Lazy<Option<Vec<u8>>> =
+	Lazy::new(|| match std::env::var("SURREAL_CURSOR_SECRET") {
+		Ok(v) if !v.is_empty() => Some(v.into_bytes()),
+		_ => None,
+	});
@@ -48,6 +48,8 @@ pub struct Context<'a> {
 	deadline: Option<Instant>,
 	// Whether or not this context is cancelled.
 	cancelled: Arc<AtomicBool>,
+	// Whether any external side effects have been made.
+	side_effects: Arc<AtomicBool>,
 	// A collection of read only values stored in this context.
 	values: HashMap<Cow<'static, str>, Cow<'a, Value>>,
 	// Stores the notification channel if available
@@ -62,6 +64,8 @@ pub struct Context<'a> {
 	index_stores: IndexStores,
 	// Capabilities
 	capabilities: Arc<Capabilities>,
+	// The key used to sign pagination cursors
+	cursor_secret: Option<Arc<[u8]>>,
 	#[cfg(any(
 		feature = "kv-mem",
 		feature = "kv-surrealkv",
@@ -111,11 +115,13 @@ impl<'a> Context<'a> {
 			parent: None,
 			deadline: None,
 			cancelled: Arc::new(AtomicBool::new(false)),
+			side_effects: Arc::new(AtomicBool::new(false)),
 			notifications: None,
 			query_planner: None,
 			query_executor: None,
 			iteration_stage: None,
 			capabilities: Arc::new(capabilities),
+			cursor_secret: None,
 			index_stores,
 			#[cfg(any(
 				feature = "kv-mem",
@@ -139,11 +145,13 @@ impl<'a> Context<'a> {
 			parent: None,
 			deadline: None,
 			cancelled: Arc::new(AtomicBool::new(false)),
+			side_effects: Arc::new(AtomicBool::new(false)),
 			notifications: None,
 			query_planner: None,
 			query_executor: None,
 			iteration_stage: None,
 			capabilities: Arc::new(Capabilities::default()),
+			cursor_secret: None,
 			index_stores: IndexStores::default(),
 			#[cfg(any(
 				feature = "kv-mem",
@@ -164,11 +172,13 @@ impl<'a> Context<'a> {
 			parent: Some(parent),
 			deadline: parent.deadline,
 			cancelled: Arc::new(AtomicBool::new(false)),
+			side_effects: parent.side_effects.clone(),
 			notifications: parent.notifications.clone(),
 			query_planner: parent.query_planner,
 			query_executor: parent.query_executor.clone(),
 			iteration_stage: parent.iteration_stage.clone(),
 			capabilities: parent.capabilities.clone(),
+			cursor_secret: parent.cursor_secret.clone(),
 			index_stores: parent.index_stores.clone(),
 			#[cfg(any(
 				feature = "kv-mem",
@@ -199,6 +209,23 @@ impl<'a> Context<'a> {
 		Canceller::new(cancelled)
 	}
 
+	/// Track the external side effects which are made within this context
+	/// and its children separately from those made within its parent.
+	pub(crate) fn add_side_effects(&mut self) {
+		self.side_effects = Arc::new(AtomicBool::new(false));
+	}
+
+	/// Record that an external side effect, such as a network request, has
+	/// been made, so that the statement can not be safely run again.
+	pub(crate) fn set_side_effect(&self) {
+		self.side_effects.store(true, Ordering::Relaxed);
+	}
+
+	/// Check if any external side effects have been made
+	pub(crate) fn has_side_effects(&self) -> bool {
+		self.side_effects.load(Ordering::Relaxed)
+	}
+
 	/// Add a deadline to the context. If the current deadline is sooner than
 	/// the provided deadline, this method does nothing.
 	pub fn add_deadline(&mut self, deadline: Instant) {
@@ -227,6 +254,11 @@ impl<'a> Context<'a> {
 		self.notifications = chn.cloned()
 	}
 
+	/// Add the key which is used to sign the pagination cursors of SELECT statements
+	pub(crate) fn add_cursor_secret(&mut self, key: Arc<[u8]>) {
+		self.cursor_secret = Some(key)
+	}
+
 	pub(crate) fn set_query_planner(&mut self, qp: &'a QueryPlanner) {
 		self.query_planner = Some(qp);
 	}
@@ -274,6 +306,11 @@ impl<'a> Context<'a> {
 		self.iteration_stage.as_ref()
 	}
 
+	/// Get the key which is used to sign pagination cursors, if cursors can be used
+	pub(crate) fn get_cursor_secret(&self) -> Result<&[u8], Error> {
+		self.cursor_secret.as_deref().ok_or(Error::CursorUnsupported)
+	}
+
 	/// Get the index_store for this context/ds
 	pub(crate) fn get_index_stores(&self) -> &IndexStores {
 		&self.index_stores
@@ -0,0 +1,88 @@
+//! Opaque, signed cursors used to paginate SELECT statements.
+//!
+//! A cursor contains the ordering of the statement, along with the position
+//! of the last record on a page, and is signed with an HMAC-SHA256 of the
+//! datastore's cursor secret, so that a client is not able to forge or alter
+//! the position of a cursor, or use it with a differently ordered statement.
+use crate::err::Error;
+use crate::sql::{Array, Strand, Value};
+use base64::{engine::general_purpose::URL_SAFE_NO_PAD, Engine};
+use hmac::{Hmac, Mac};
+use sha2::Sha256;
+
+type HmacSha256 = Hmac<Sha256>;
+
+/// Creates a cursor which resumes after the specified position
+pub(crate) fn encode(key: &[u8], order: &str, after: &Value) -> String {
+	let payload = Value::from(Array::from(vec![Value::from(Strand::from(order)), after.clone()]));
+	let payload = payload.to_string();
+	let signature = sign(key, payload.as_bytes());
+	format!("{}.{}", URL_SAFE_NO_PAD.encode(payload), URL_SAFE_NO_PAD.encode(signature))
+}
+
+/// Verifies a cursor for the specified ordering, returning the position which it resumes after
+pub(crate) fn decode(key: &[u8], order: &str, cursor: &str) -> Result<Value, Error> {
+	let invalid = || Error::InvalidCursor {
+		value: cursor.to_owned(),
+	};
+	let (payload, signature) = cursor.split_once('.').ok_or_else(invalid)?;
+	let payload = URL_SAFE_NO_PAD.decode(payload).map_err(|_| invalid())?;
+	let signature = URL_SAFE_NO_PAD.decode(signature).map_err(|_| invalid())?;
+	// Compare the signatures in constant time
+	let mut mac = HmacSha256::new_from_slice(key).map_err(|_| invalid())?;
+	mac.update(&payload);
+	mac.verify_slice(&signature).map_err(|_| invalid())?;
+	let payload = String::from_utf8(payload).map_err(|_| invalid())?;
+	// Check that the cursor was created for this ordering
+	match crate::syn::value(&payload).map_err(|_| invalid())? {
+		Value::Array(mut v) if v.len() == 2 => match v.remove(0) {
+			Value::Strand(o) if o.as_str() == order => Ok(v.remove(0)),
+			_ => Err(invalid()),
+		},
+		_ => Err(invalid()),
+	}
+}
+
+/// Computes the HMAC-SHA256 of a message
+fn sign(key: &[u8], message: &[u8]) -> [u8; 32] {
+	// HMAC accepts keys of any length
+	let mut mac = HmacSha256::new_from_slice(key).expect("HMAC accepts keys of any length");
+	mac.update(message);
+	mac.finalize().into_bytes().into()
+}
+
+#[cfg(test)]
+mod tests {
+	use super::*;
+	use crate::sql::{Id, Thing};
+
+	const KEY: &[u8] = b"secret";
+
+	#[test]
+	fn hmac_sha256() {
+		// Test case 2 from RFC 4231
+		let res = sign(b"Jefe", b"what do ya want for nothing?");
+		let exp = "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843";
+		assert_eq!(hex::encode(res), exp);
+	}
+
+	#[test]
+	fn cursor_roundtrip() {
+		let rid = Value::from(Thing::from(("person", Id::from(42))));
+		let cursor = encode(KEY, "ORDER BY id", &rid);
+		assert_eq!(decode(KEY, "ORDER BY id", &cursor).unwrap(), rid);
+	}
+
+	#[test]
+	fn cursor_tampered() {
+		let rid = Value::from(Thing::from(("person", Id::from(42))));
+		let cursor = encode(KEY, "", &rid);
+		let (_, signature) = cursor.split_once('.').unwrap();
+		let forged = format!("{}.{signature}", URL_SAFE_NO_PAD.encode("['', person:43]"));
+		assert!(matches!(decode(KEY, "", &forged), Err(Error::InvalidCursor { .. })));
+		assert!(matches!(decode(KEY, "", "person:42"), Err(Error::InvalidCursor { .. })));
+		// A cursor is only valid for the ordering and key which created it
+		assert!(matches!(decode(KEY, "ORDER BY id", &cursor), Err(Error::InvalidCursor { .. })));
+		assert!(matches!(decode(b"other", "", &cursor), Err(Error::InvalidCursor { .. })));
+	}
+}
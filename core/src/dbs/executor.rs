@@ -1,8 +1,13 @@
+use std::future::Future;
+use std::pin::pin;
 use std::sync::Arc;
+use std::time::Duration;
 
 use channel::Receiver;
+use futures::future::{self, Either};
 use futures::lock::Mutex;
 use futures::StreamExt;
+use rand::Rng;
 use reblessive::TreeStack;
 #[cfg(not(target_arch = "wasm32"))]
 use tokio::spawn;
@@ -11,12 +16,16 @@ use trice::Instant;
 #[cfg(target_arch = "wasm32")]
 use wasm_bindgen_futures::spawn_local as spawn;
 
+use crate::cnf::{TRANSACTION_RETRY_ATTEMPTS, TRANSACTION_RETRY_BACKOFF};
 use crate::ctx::Context;
+use crate::dbs::params;
 use crate::dbs::response::Response;
+use crate::dbs::slowlog;
 use crate::dbs::Force;
 use crate::dbs::Notification;
 use crate::dbs::Options;
 use crate::dbs::QueryType;
+use crate::dbs::ResultStream;
 use crate::dbs::Transaction;
 use crate::err::Error;
 use crate::iam::Action;
@@ -28,6 +37,7 @@ use crate::sql::paths::DB;
 use crate::sql::paths::NS;
 use crate::sql::query::Query;
 use crate::sql::statement::Statement;
+use crate::sql::statements::SavepointStatement;
 use crate::sql::value::Value;
 use crate::sql::Base;
 
@@ -35,6 +45,7 @@ pub(crate) struct Executor<'a> {
 	err: bool,
 	kvs: &'a Datastore,
 	txn: Option<Transaction>,
+	stream: Option<ResultStream>,
 }
 
 impl<'a> Executor<'a> {
@@ -43,9 +54,16 @@ impl<'a> Executor<'a> {
 			kvs,
 			txn: None,
 			err: false,
+			stream: None,
 		}
 	}
 
+	/// Sends the records of SELECT statements to a stream as they are produced
+	pub fn with_stream(mut self, stream: Option<ResultStream>) -> Executor<'a> {
+		self.stream = stream;
+		self
+	}
+
 	fn txn(&self) -> Transaction {
 		self.txn.clone().expect("unreachable: txn was None after successful begin")
 	}
@@ -134,6 +152,7 @@ impl<'a> Executor<'a> {
 		Response {
 			time: v.time,
 			result: Err(Error::QueryCancelled),
+			cursor: None,
 			query_type: QueryType::Other,
 		}
 	}
@@ -151,6 +170,7 @@ impl<'a> Executor<'a> {
 						.unwrap_or(Error::QueryNotExecuted)),
 					Err(e) => Err(e),
 				},
+				cursor: None,
 				query_type: QueryType::Other,
 			},
 			_ => v,
@@ -226,6 +246,21 @@ impl<'a> Executor<'a> {
 		// Initialise array of responses
 		let mut out: Vec<Response> = vec![];
 		let mut live_queries: Vec<TrackedResult> = vec![];
+		// Check the bound values of any declared parameters
+		let mut declared = false;
+		for stm in qry.iter() {
+			if let Statement::Set(stm) = stm {
+				if stm.is_declaration() {
+					let val = stm.check(&ctx)?;
+					ctx.add_value(stm.name.clone(), val);
+					declared = true;
+				}
+			}
+		}
+		// Unknown parameters are errors in queries which declare their parameters
+		if declared {
+			opt = opt.with_strict_params(true);
+		}
 		// Process all statements in query
 		for stm in qry.into_iter() {
 			// Log the statement
@@ -242,10 +277,21 @@ impl<'a> Executor<'a> {
 			let is_stm_kill = matches!(stm, Statement::Kill(_));
 			// Check if this is a RETURN statement
 			let is_stm_output = matches!(stm, Statement::Output(_));
+			// Get the statement type for the metrics
+			let kind = stm.kind();
+			// Any cursor for the next page of a SELECT statement
+			let mut cursor = None;
+			// Check for unknown parameters before the statement is run
+			let unknown = match opt.strict_params {
+				true => params::check(self.kvs, self.txn.as_ref(), &ctx, &opt, &stm).await.err(),
+				false => None,
+			};
 			// Process a single statement
-			let res = match stm {
+			let res = match (unknown, stm) {
+				// The statement references a parameter which has not been set
+				(Some(e), _) => Err(e),
 				// Specify runtime options
-				Statement::Option(mut stm) => {
+				(None, Statement::Option(mut stm)) => {
 					// Allowed to run?
 					opt.is_allowed(Action::Edit, ResourceKind::Option, &Base::Db)?;
 					// Convert to uppercase
@@ -253,6 +299,9 @@ impl<'a> Executor<'a> {
 					// Process the option
 					opt = match stm.name.0.as_str() {
 						"IMPORT" => opt.with_import(stm.what),
+						"STRICT_CONDITIONS" => opt.with_strict_conditions(stm.what),
+						"STRICT_PARAMS" => opt.with_strict_params(stm.what),
+						"CREATE_PATHS" => opt.with_create_paths(stm.what),
 						"FORCE" => opt.with_force(if stm.what {
 							Force::All
 						} else {
@@ -264,12 +313,12 @@ impl<'a> Executor<'a> {
 					continue;
 				}
 				// Begin a new transaction
-				Statement::Begin(_) => {
+				(None, Statement::Begin(_)) => {
 					self.begin(Write).await;
 					continue;
 				}
 				// Cancel a running transaction
-				Statement::Cancel(_) => {
+				(None, Statement::Cancel(_)) => {
 					self.cancel(true).await;
 					self.clear(&ctx, recv.clone()).await;
 					buf = buf.into_iter().map(|v| self.buf_cancel(v)).collect();
@@ -279,7 +328,7 @@ impl<'a> Executor<'a> {
 					continue;
 				}
 				// Commit a running transaction
-				Statement::Commit(_) => {
+				(None, Statement::Commit(_)) => {
 					let commit_error = self.commit(true).await.err();
 					buf = buf.into_iter().map(|v| self.buf_commit(v, &commit_error)).collect();
 					self.flush(&ctx, recv.clone()).await;
@@ -291,8 +340,31 @@ impl<'a> Executor<'a> {
 					self.txn = None;
 					continue;
 				}
+				// Manage a savepoint within a running transaction
+				(None, Statement::Savepoint(ref stm)) => match (&self.txn, self.err) {
+					// Savepoints only exist within a transaction
+					(None, _) => Err(Error::TxNoSavepoints),
+					// This transaction has failed
+					(Some(_), true) => Err(Error::QueryNotExecuted),
+					// Checkpoint or restore the transaction
+					(Some(txn), false) => {
+						let mut txn = txn.lock().await;
+						match stm {
+							SavepointStatement::Define(name) => {
+								txn.new_savepoint(name);
+								Ok(Value::None)
+							}
+							SavepointStatement::Release(name) => {
+								txn.release_savepoint(name).map(|_| Value::None)
+							}
+							SavepointStatement::Rollback(name) => {
+								txn.rollback_to_savepoint(name).await.map(|_| Value::None)
+							}
+						}
+					}
+				},
 				// Switch to a different NS or DB
-				Statement::Use(stm) => {
+				(None, Statement::Use(ref stm)) => {
 					if let Some(ref ns) = stm.ns {
 						self.set_ns(&mut ctx, &mut opt, ns).await;
 					}
@@ -301,8 +373,10 @@ impl<'a> Executor<'a> {
 					}
 					Ok(Value::None)
 				}
+				// Declared parameters are checked before the query is run
+				(None, Statement::Set(ref stm)) if stm.is_declaration() => Ok(Value::None),
 				// Process param definition statements
-				Statement::Set(stm) => {
+				(None, Statement::Set(ref stm)) => {
 					// Create a transaction
 					let loc = self.begin(stm.writeable().into()).await;
 					// Check the transaction
@@ -322,7 +396,7 @@ impl<'a> Executor<'a> {
 									// Check if writeable
 									let writeable = stm.writeable();
 									// Set the parameter
-									ctx.add_value(stm.name, val);
+									ctx.add_value(stm.name.clone(), val);
 									// Finalise transaction, returning nothing unless it couldn't commit
 									if writeable {
 										match self.commit(loc).await {
@@ -362,86 +436,148 @@ impl<'a> Executor<'a> {
 					}
 				}
 				// Process all other normal statements
-				_ => match self.err {
+				(None, stm) => match self.err {
 					// This transaction has failed
 					true => Err(Error::QueryNotExecuted),
 					// Compute the statement normally
 					false => {
-						// Create a transaction
-						let loc = self.begin(stm.writeable().into()).await;
-						// Check the transaction
-						match self.err {
-							// We failed to create a transaction
-							true => Err(Error::TxFailure),
-							// The transaction began successfully
-							false => {
-								let mut ctx = Context::new(&ctx);
-								// Process the statement
-								let res = match stm.timeout() {
-									// There is a timeout clause
-									Some(timeout) => {
-										// Set statement timeout or propagate the error
-										if let Err(err) = ctx.add_timeout(timeout) {
-											Err(err)
-										} else {
+						let mut attempt = 1;
+						loop {
+							// Create a transaction
+							let loc = self.begin(stm.writeable().into()).await;
+							// Only stream statements which run in their own transaction
+							let stream = match (&self.stream, loc) {
+								(Some(stream), true) => Some(ResultStream {
+									query: out.len() + buf.len(),
+									..stream.clone()
+								}),
+								_ => None,
+							};
+							let mut ctx = Context::new(&ctx);
+							// Track the side effects of this attempt
+							ctx.add_side_effects();
+							// Whether the transaction failed to commit due to a conflict
+							let mut conflict = false;
+							// Check the transaction
+							let res = match self.err {
+								// We failed to create a transaction
+								true => Err(Error::TxFailure),
+								// The transaction began successfully
+								false => {
+									// Process the statement
+									let res = match stm.timeout() {
+										// There is a timeout clause
+										Some(timeout) => {
+											// Set statement timeout or propagate the error
+											if let Err(err) = ctx.add_timeout(timeout) {
+												Err(err)
+											} else {
+												ctx.set_transaction_mut(self.txn());
+												// Process the statement
+												let res = stack.enter(|stk| {
+													stm.compute_page(
+														stk,
+														&ctx,
+														&opt,
+														stream.as_ref(),
+													)
+												});
+												let res = deadline(&ctx, res.finish()).await.map(
+													|(v, c)| {
+														cursor = c;
+														v
+													},
+												);
+												// Catch statement timeout
+												match ctx.is_timedout() {
+													true => Err(Error::QueryTimedout),
+													false => res,
+												}
+											}
+										}
+										// There is no timeout clause
+										None => {
 											ctx.set_transaction_mut(self.txn());
-											// Process the statement
-											let res = stack
-												.enter(|stk| stm.compute(stk, &ctx, &opt, None))
-												.finish()
-												.await;
-											// Catch statement timeout
-											match ctx.is_timedout() {
-												true => Err(Error::QueryTimedout),
-												false => res,
+											let res = stack.enter(|stk| {
+												stm.compute_page(stk, &ctx, &opt, stream.as_ref())
+											});
+											deadline(&ctx, res.finish()).await.map(|(v, c)| {
+												cursor = c;
+												v
+											})
+										}
+									};
+									// Catch global timeout
+									let res = match ctx.is_timedout() {
+										true => Err(Error::QueryTimedout),
+										false => res,
+									};
+									// Finalise transaction and return the result.
+									if res.is_ok() && stm.writeable() {
+										if let Err(e) = self.commit(loc).await {
+											// Clear live query notification details
+											self.clear(&ctx, recv.clone()).await;
+											// The commit failed
+											conflict = matches!(e, Error::TxRetryable);
+											Err(Error::QueryNotExecutedDetail {
+												message: e.to_string(),
+											})
+										} else {
+											// Flush the live query change notifications
+											self.flush(&ctx, recv.clone()).await;
+											if let Some(lqs) = self
+												.consume_committed_live_query_registrations()
+												.await
+											{
+												live_queries.extend(lqs);
 											}
+											res
 										}
-									}
-									// There is no timeout clause
-									None => {
-										ctx.set_transaction_mut(self.txn());
-										stack
-											.enter(|stk| stm.compute(stk, &ctx, &opt, None))
-											.finish()
-											.await
-									}
-								};
-								// Catch global timeout
-								let res = match ctx.is_timedout() {
-									true => Err(Error::QueryTimedout),
-									false => res,
-								};
-								// Finalise transaction and return the result.
-								if res.is_ok() && stm.writeable() {
-									if let Err(e) = self.commit(loc).await {
+									} else {
+										self.cancel(loc).await;
 										// Clear live query notification details
 										self.clear(&ctx, recv.clone()).await;
-										// The commit failed
-										Err(Error::QueryNotExecutedDetail {
-											message: e.to_string(),
-										})
-									} else {
-										// Flush the live query change notifications
-										self.flush(&ctx, recv.clone()).await;
-										if let Some(lqs) =
-											self.consume_committed_live_query_registrations().await
-										{
-											live_queries.extend(lqs);
-										}
+										// Return an error
 										res
 									}
-								} else {
-									self.cancel(loc).await;
-									// Clear live query notification details
-									self.clear(&ctx, recv.clone()).await;
-									// Return an error
-									res
 								}
+							};
+							// Retry statements which conflicted with another transaction,
+							// as long as they run in their own transaction, and running
+							// them again can not repeat any external side effects. Only
+							// network requests and scripts count as side effects, so
+							// functions such as rand() and time::now() are computed
+							// again, and a retried statement may store different values
+							// than the attempt which conflicted. Those values were never
+							// committed or returned, so this can not be observed.
+							let conflict = conflict || matches!(res, Err(Error::TxRetryable));
+							if conflict
+								&& loc && stream.is_none()
+								&& !ctx.has_side_effects()
+								&& attempt < *TRANSACTION_RETRY_ATTEMPTS
+							{
+								self.err = false;
+								self.kvs.metrics().retry();
+								backoff(attempt).await;
+								attempt += 1;
+								continue;
 							}
+							break res;
 						}
 					}
 				},
 			};
+			// Only return a cursor when the statement succeeded
+			let cursor = cursor.filter(|_| res.is_ok());
+			// Record the statement in the metrics
+			let elapsed = now.elapsed();
+			self.kvs.metrics().statement(kind, elapsed, res.is_err());
+			// Log the statement if it took longer than the threshold
+			if let Some(threshold) = self.kvs.slow_query_threshold() {
+				if elapsed > threshold {
+					slowlog::log(&stm, elapsed, &opt.auth);
+				}
+			}
 			// Produce the response
 			let res = Response {
 				// Get the statement end time
@@ -452,6 +588,7 @@ impl<'a> Executor<'a> {
 					self.err = true;
 					e
 				}),
+				cursor,
 				query_type: match (is_stm_live, is_stm_kill) {
 					(true, _) => {
 						if let Some(lqs) = self.consume_committed_live_query_registrations().await {
@@ -478,14 +615,68 @@ impl<'a> Executor<'a> {
 				out.push(res)
 			}
 		}
+		// Cancel any transaction which was never committed
+		if self.txn.is_some() {
+			self.cancel(true).await;
+			self.clear(&ctx, recv).await;
+			buf = buf.into_iter().map(|v| self.buf_cancel(v)).collect();
+			out.append(&mut buf);
+		}
 		// Return responses
 		Ok((out, live_queries))
 	}
 }
 
+/// Wait before a statement which conflicted with another transaction is
+/// run again. The delay doubles with each attempt, up to 1024 times the
+/// initial delay, and is randomised so that conflicting statements are
+/// less likely to conflict again.
+async fn backoff(attempt: u32) {
+	let delay = Duration::from_millis(*TRANSACTION_RETRY_BACKOFF)
+		.saturating_mul(2u32.pow(attempt.saturating_sub(1).min(10)));
+	let jitter = rand::thread_rng().gen_range(0.5..=1.0);
+	let delay = Duration::try_from_secs_f64(delay.as_secs_f64() * jitter).unwrap_or(delay);
+	#[cfg(target_arch = "wasm32")]
+	wasmtimer::tokio::sleep(delay).await;
+	#[cfg(not(target_arch = "wasm32"))]
+	tokio::time::sleep(delay).await;
+}
+
+/// Wait for a statement to be processed, giving up as soon as the
+/// context deadline passes, rather than once the statement returns.
+/// This ensures that a statement which is blocked waiting on the
+/// datastore still times out promptly, with the transaction then
+/// being cancelled by the caller.
+async fn deadline<T>(
+	ctx: &Context<'_>,
+	fut: impl Future<Output = Result<T, Error>>,
+) -> Result<T, Error> {
+	// There is no deadline so wait for the result
+	let Some(dur) = ctx.timeout() else {
+		return fut.await;
+	};
+	#[cfg(target_arch = "wasm32")]
+	let sleep = wasmtimer::tokio::sleep(dur);
+	#[cfg(not(target_arch = "wasm32"))]
+	let sleep = tokio::time::sleep(dur);
+	// Return whichever finishes first
+	match future::select(pin!(fut), pin!(sleep)).await {
+		Either::Left((res, _)) => res,
+		Either::Right(_) => Err(Error::QueryTimedout),
+	}
+}
+
 #[cfg(test)]
 mod tests {
+	use crate::cnf::TRANSACTION_RETRY_ATTEMPTS;
+	use crate::ctx::Context;
+	use crate::err::Error;
+	use crate::kvs::conflicts::SimulatedConflicts;
+	use crate::sql::Value;
+	use crate::syn::Parse;
 	use crate::{dbs::Session, iam::Role, kvs::Datastore};
+	use std::time::Duration;
+	use trice::Instant;
 
 	#[tokio::test]
 	async fn check_execute_option_permissions() {
@@ -595,4 +786,157 @@ mod tests {
 			);
 		}
 	}
+
+	#[tokio::test]
+	async fn check_deadline_cancels_blocked_statement() {
+		// A statement which is waiting on a datastore that never responds
+		let blocked = futures::future::pending::<Result<(), Error>>();
+		let mut ctx = Context::background();
+		ctx.add_timeout(Duration::from_millis(50)).unwrap();
+		let now = Instant::now();
+		let res = super::deadline(&ctx, blocked).await;
+		assert!(matches!(res, Err(Error::QueryTimedout)), "Expected a timeout: {:?}", res);
+		assert!(now.elapsed() < Duration::from_secs(5), "The deadline was not observed promptly");
+	}
+
+	#[tokio::test]
+	async fn check_execute_timeout_rolls_back() {
+		let ds = Datastore::new("memory").await.unwrap();
+		let ses = Session::owner().with_ns("NS").with_db("DB");
+		let stmt = "CREATE person:test SET slow = sleep(500ms) TIMEOUT 50ms; SELECT * FROM person;";
+		let res = &mut ds.execute(stmt, &ses, None).await.unwrap();
+		assert_eq!(res.len(), 2);
+		let err = res.remove(0).result.unwrap_err();
+		assert!(matches!(err, Error::QueryTimedout), "Expected a timeout: {:?}", err);
+		let val = res.remove(0).result.unwrap();
+		assert_eq!(val, Value::Array(Default::default()));
+	}
+	#[tokio::test]
+	async fn check_execute_retries_conflicts() {
+		let ds = Datastore::new("memory").await.unwrap();
+		let ses = Session::owner().with_ns("NS").with_db("DB");
+		// The statement succeeds once the conflicts stop
+		let _conflicts = SimulatedConflicts::new(2);
+		let res = &mut ds.execute("CREATE person:test", &ses, None).await.unwrap();
+		assert!(res.remove(0).result.is_ok());
+		assert_eq!(ds.transaction_retries(), 2);
+		// The statement was only applied once
+		let res = &mut ds.execute("SELECT VALUE id FROM person", &ses, None).await.unwrap();
+		assert_eq!(res.remove(0).result.unwrap(), Value::parse("[person:test]"));
+	}
+
+	#[tokio::test]
+	async fn check_execute_retries_are_bounded() {
+		let ds = Datastore::new("memory").await.unwrap();
+		let ses = Session::owner().with_ns("NS").with_db("DB");
+		let _conflicts = SimulatedConflicts::new(u32::MAX);
+		let res = &mut ds.execute("CREATE person:test", &ses, None).await.unwrap();
+		let err = res.remove(0).result.unwrap_err();
+		assert!(err.to_string().contains("can be retried"), "Expected a conflict: {:?}", err);
+		assert_eq!(ds.transaction_retries(), (*TRANSACTION_RETRY_ATTEMPTS - 1) as u64);
+	}
+
+	#[tokio::test]
+	async fn check_execute_does_not_retry_other_errors() {
+		let ds = Datastore::new("memory").await.unwrap();
+		let ses = Session::owner().with_ns("NS").with_db("DB");
+		ds.execute("CREATE person:test", &ses, None).await.unwrap();
+		// Errors which are not conflicts are returned immediately
+		let _conflicts = SimulatedConflicts::new(1);
+		let res = &mut ds.execute("CREATE person:test", &ses, None).await.unwrap();
+		let err = res.remove(0).result.unwrap_err();
+		assert!(matches!(err, Error::RecordExists { .. }), "Expected an error: {:?}", err);
+		assert_eq!(ds.transaction_retries(), 0);
+		// Statements within a transaction are not retried on their own
+		let res = &mut ds.execute("BEGIN; CREATE person:other; COMMIT", &ses, None).await.unwrap();
+		let err = res.remove(0).result.unwrap_err();
+		assert!(err.to_string().contains("can be retried"), "Expected a conflict: {:?}", err);
+		assert_eq!(ds.transaction_retries(), 0);
+	}
+
+	#[cfg(feature = "scripting")]
+	#[tokio::test]
+	async fn check_execute_does_not_retry_side_effects() {
+		let ds = Datastore::new("memory")
+			.await
+			.unwrap()
+			.with_capabilities(crate::dbs::Capabilities::default().with_scripting(true));
+		let ses = Session::owner().with_ns("NS").with_db("DB");
+		let _conflicts = SimulatedConflicts::new(1);
+		let sql = "CREATE person:test SET value = function() { return 1; }";
+		let res = &mut ds.execute(sql, &ses, None).await.unwrap();
+		let err = res.remove(0).result.unwrap_err();
+		assert!(err.to_string().contains("can be retried"), "Expected a conflict: {:?}", err);
+		assert_eq!(ds.transaction_retries(), 0);
+	}
+
+	/// Captures the logs which are written during a test
+	#[derive(Clone, Default)]
+	struct Logs(std::sync::Arc<std::sync::Mutex<Vec<u8>>>);
+
+	impl Logs {
+		fn contents(&self) -> String {
+			String::from_utf8(self.0.lock().unwrap().clone()).unwrap()
+		}
+	}
+
+	impl std::io::Write for Logs {
+		fn write(&mut self, buf: &[u8]) -> std::io::Result<usize> {
+			self.0.lock().unwrap().write(buf)
+		}
+		fn flush(&mut self) -> std::io::Result<()> {
+			Ok(())
+		}
+	}
+
+	impl<'a> tracing_subscriber::fmt::MakeWriter<'a> for Logs {
+		type Writer = Self;
+		fn make_writer(&'a self) -> Self::Writer {
+			self.clone()
+		}
+	}
+
+	#[tokio::test]
+	async fn check_execute_logs_slow_queries() {
+		let logs = Logs::default();
+		let subscriber =
+			tracing_subscriber::fmt().with_writer(logs.clone()).with_ansi(false).finish();
+		let _guard = tracing::subscriber::set_default(subscriber);
+		let ds = Datastore::new("memory")
+			.await
+			.unwrap()
+			.with_slow_query_threshold(Some(Duration::from_millis(100)));
+		let ses = Session::owner().with_ns("NS").with_db("DB");
+		// Statements which are faster than the threshold are not logged
+		let sql = "SELECT * FROM [{ name: 'Jaime' }] WHERE name = 'Jaime'";
+		let res = &mut ds.execute(sql, &ses, None).await.unwrap();
+		assert!(res.remove(0).result.is_ok());
+		assert!(!logs.contents().contains("Slow query"), "{}", logs.contents());
+		// Statements which are slower than the threshold are logged
+		let sql = "SELECT * FROM [{ name: 'Tobie', wait: sleep(150ms) }] WHERE name = 'Tobie'";
+		let res = &mut ds.execute(sql, &ses, None).await.unwrap();
+		assert!(res.remove(0).result.is_ok());
+		let out = logs.contents();
+		assert!(out.contains("Slow query: select statement took"), "{out}");
+		assert!(
+			out.contains("at root: SELECT * FROM [{ name: $?, wait: sleep($?) }] WHERE name = $?"),
+			"{out}"
+		);
+		// The literal values in the statement are not logged
+		assert!(!out.contains("Tobie"), "{out}");
+		assert!(!out.contains("150ms"), "{out}");
+	}
+
+	#[tokio::test]
+	async fn check_execute_does_not_log_without_threshold() {
+		let logs = Logs::default();
+		let subscriber =
+			tracing_subscriber::fmt().with_writer(logs.clone()).with_ansi(false).finish();
+		let _guard = tracing::subscriber::set_default(subscriber);
+		let ds = Datastore::new("memory").await.unwrap().with_slow_query_threshold(None);
+		let ses = Session::owner().with_ns("NS").with_db("DB");
+		let res = &mut ds.execute("SLEEP 150ms", &ses, None).await.unwrap();
+		assert!(res.remove(0).result.is_ok());
+		assert!(!logs.contents().contains("Slow query"), "{}", logs.contents());
+	}
 }
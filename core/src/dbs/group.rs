@@ -13,11 +13,13 @@ use std::collections::{BTreeMap, HashMap};
 pub(super) struct GroupsCollector {
 	base: Vec<Aggregator>,
 	idioms: Vec<Idiom>,
+	// Whether each aggregate is only passed the rows which match its filter
+	filtered: Vec<bool>,
 	grp: BTreeMap<Array, Vec<Aggregator>>,
 }
 
 #[derive(Default)]
-struct Aggregator {
+pub(super) struct Aggregator {
 	array: Option<Array>,
 	first_val: Option<Value>,
 	count: Option<usize>,
@@ -32,28 +34,39 @@ struct Aggregator {
 
 impl GroupsCollector {
 	pub(super) fn new(stm: &Statement<'_>) -> Self {
-		let mut idioms_agr: HashMap<Idiom, Aggregator> = HashMap::new();
+		let mut idioms_agr: HashMap<Idiom, (Aggregator, bool)> = HashMap::new();
 		if let Some(fields) = stm.expr() {
 			for field in fields.other() {
-				if let Field::Single {
-					expr,
-					alias,
-				} = field
-				{
-					let idiom = alias.as_ref().cloned().unwrap_or_else(|| expr.to_idiom());
-					idioms_agr.entry(idiom).or_default().prepare(expr);
-				}
+				let (expr, alias, filtered) = match field {
+					Field::Single {
+						expr,
+						alias,
+					} => (expr, alias, false),
+					Field::Filter {
+						expr,
+						alias,
+						..
+					} => (expr, alias, true),
+					_ => continue,
+				};
+				let idiom = alias.as_ref().cloned().unwrap_or_else(|| expr.to_idiom());
+				let (agr, filter) = idioms_agr.entry(idiom).or_default();
+				agr.prepare(expr);
+				*filter |= filtered;
 			}
 		}
 		let mut base = Vec::with_capacity(idioms_agr.len());
 		let mut idioms = Vec::with_capacity(idioms_agr.len());
-		for (idiom, agr) in idioms_agr {
+		let mut filtered = Vec::with_capacity(idioms_agr.len());
+		for (idiom, (agr, filter)) in idioms_agr {
 			base.push(agr);
 			idioms.push(idiom);
+			filtered.push(filter);
 		}
 		Self {
 			base,
 			idioms,
+			filtered,
 			grp: Default::default(),
 		}
 	}
@@ -81,7 +94,7 @@ impl GroupsCollector {
 				.grp
 				.entry(arr)
 				.or_insert_with(|| self.base.iter().map(|a| a.new_instance()).collect());
-			Self::pushes(stk, ctx, opt, agr, &self.idioms, obj).await?
+			Self::pushes(stk, ctx, opt, agr, &self.idioms, &self.filtered, obj).await?
 		}
 		Ok(())
 	}
@@ -92,10 +105,18 @@ impl GroupsCollector {
 		opt: &Options,
 		agrs: &mut [Aggregator],
 		idioms: &[Idiom],
+		filtered: &[bool],
 		obj: Value,
 	) -> Result<(), Error> {
-		for (agr, idiom) in agrs.iter_mut().zip(idioms) {
+		for ((agr, idiom), filtered) in agrs.iter_mut().zip(idioms).zip(filtered) {
 			let val = stk.run(|stk| obj.get(stk, ctx, opt, None, idiom)).await?;
+			// The value of a row which matches the filter is wrapped in an array
+			let val = match (filtered, val) {
+				(false, val) => val,
+				(true, Value::Array(mut v)) if v.len() == 1 => v.0.remove(0),
+				// Rows which don't match the filter are skipped
+				(true, _) => continue,
+			};
 			agr.push(stk, ctx, opt, val).await?;
 		}
 		Ok(())
@@ -124,6 +145,11 @@ impl GroupsCollector {
 					if let Field::Single {
 						expr,
 						alias,
+					}
+					| Field::Filter {
+						expr,
+						alias,
+						..
 					} = field
 					{
 						let idiom = alias
@@ -175,7 +201,7 @@ impl GroupsCollector {
 }
 
 impl Aggregator {
-	fn prepare(&mut self, expr: &Value) {
+	pub(super) fn prepare(&mut self, expr: &Value) {
 		let (a, f) = match expr {
 			Value::Function(f) => (f.get_optimised_aggregate(), Some(f)),
 			_ => {
@@ -253,7 +279,7 @@ impl Aggregator {
 		}
 	}
 
-	async fn push(
+	pub(super) async fn push(
 		&mut self,
 		stk: &mut Stk,
 		ctx: &Context<'_>,
@@ -317,6 +343,27 @@ impl Aggregator {
 		Ok(())
 	}
 
+	/// The value of an optimised aggregate over the values which have been pushed so far
+	pub(super) fn current(&self, a: &OptimisedAggregate) -> Result<Value, Error> {
+		Ok(match a {
+			OptimisedAggregate::None => Value::None,
+			OptimisedAggregate::Count => self.count.map(|v| v.into()).unwrap_or(Value::None),
+			OptimisedAggregate::CountFunction => {
+				self.count_function.as_ref().map(|(_, v)| (*v).into()).unwrap_or(Value::None)
+			}
+			OptimisedAggregate::MathMax => self.math_max.clone().unwrap_or(Value::None),
+			OptimisedAggregate::MathMin => self.math_min.clone().unwrap_or(Value::None),
+			OptimisedAggregate::MathSum => self.math_sum.clone().unwrap_or(Value::None),
+			OptimisedAggregate::MathMean => match &self.math_mean {
+				Some((_, 0)) => f64::NAN.into(),
+				Some((v, i)) => v.clone().try_div((*i).into())?,
+				None => Value::None,
+			},
+			OptimisedAggregate::TimeMax => self.time_max.clone().unwrap_or(Value::None),
+			OptimisedAggregate::TimeMin => self.time_min.clone().unwrap_or(Value::None),
+		})
+	}
+
 	fn compute(&mut self, a: OptimisedAggregate) -> Result<Value, Error> {
 		Ok(match a {
 			OptimisedAggregate::None => Value::None,
@@ -329,7 +376,11 @@ impl Aggregator {
 			OptimisedAggregate::MathSum => self.math_sum.take().unwrap_or(Value::None),
 			OptimisedAggregate::MathMean => {
 				if let Some((v, i)) = self.math_mean.take() {
-					v.try_div(i.into())?
+					match i {
+						// There are no values when every row is filtered out
+						0 => f64::NAN.into(),
+						_ => v.try_div(i.into())?,
+					}
 				} else {
 					Value::None
 				}
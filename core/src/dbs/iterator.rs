@@ -1,24 +1,41 @@
+use crate::cnf::{EXPLAIN_SAMPLE_SIZE, PROCESSOR_BATCH_SIZE};
 use crate::ctx::Canceller;
 use crate::ctx::Context;
 #[cfg(not(target_arch = "wasm32"))]
 use crate::dbs::distinct::AsyncDistinct;
 use crate::dbs::distinct::SyncDistinct;
-use crate::dbs::plan::Plan;
+use crate::dbs::plan::{Plan, Summary};
 use crate::dbs::result::Results;
+use crate::dbs::window;
 use crate::dbs::Options;
+use crate::dbs::ResultStream;
 use crate::dbs::Statement;
 use crate::doc::Document;
 use crate::err::Error;
-use crate::idx::planner::iterators::{IteratorRecord, IteratorRef};
+use crate::iam::Action;
+use crate::idx::planner::iterators::{CollectorRecord, IteratorRecord, IteratorRef};
 use crate::idx::planner::IterationStage;
+use crate::key::thing;
+use crate::kvs::ScanPage;
 use crate::sql::edges::Edges;
+use crate::sql::function::OptimisedAggregate;
+use crate::sql::permission::Permission;
 use crate::sql::range::Range;
 use crate::sql::table::Table;
 use crate::sql::thing::Thing;
-use crate::sql::value::Value;
+use crate::sql::value::{FetchCache, Value};
+use crate::sql::{Field, Id, IdStrategy, Idiom, Kind, Orders};
 use reblessive::{tree::Stk, TreeStack};
+use std::cmp::Ordering;
+use std::collections::HashSet;
 use std::mem;
 
+/// Check if a field of this kind always holds a single value
+/// which is indexed, and ordered, exactly as it is compared
+fn is_scalar(kind: &Kind) -> bool {
+	matches!(kind, Kind::Bool | Kind::Int | Kind::Float | Kind::String | Kind::Uuid)
+}
+
 #[derive(Clone)]
 pub(crate) enum Iterable {
 	Value(Value),
@@ -64,6 +81,16 @@ pub(crate) struct Iterator {
 	results: Results,
 	// Iterator input values
 	entries: Vec<Iterable>,
+	// Iterator output stream
+	stream: Option<ResultStream>,
+	// Iterator streamed results count
+	streamed: usize,
+	// Iterator modified records count
+	mutated: usize,
+	// Iterator keyset pagination position
+	after: Option<(Orders, Value)>,
+	// Iterator summary of an explained statement
+	summary: Option<Summary>,
 }
 
 impl Clone for Iterator {
@@ -75,6 +102,11 @@ impl Clone for Iterator {
 			error: None,
 			results: Results::default(),
 			entries: self.entries.clone(),
+			stream: None,
+			streamed: 0,
+			mutated: 0,
+			after: self.after.clone(),
+			summary: None,
 		}
 	}
 }
@@ -85,11 +117,56 @@ impl Iterator {
 		Self::default()
 	}
 
+	/// Sends the results to a stream in batches, instead of collecting them
+	pub fn with_stream(mut self, stream: ResultStream) -> Self {
+		self.stream = Some(stream);
+		self
+	}
+
+	/// Only outputs the results which are ordered after the specified position
+	pub fn with_after(mut self, orders: Orders, after: Value) -> Self {
+		self.after = Some((orders, after));
+		self
+	}
+
+	/// Summarises how the records are found, when the statement is explained
+	pub fn with_summary(mut self) -> Self {
+		self.summary = Some(Summary::default());
+		self
+	}
+
+	/// Takes the summary of an explained statement
+	pub fn take_summary(&mut self) -> Option<Summary> {
+		self.summary.take()
+	}
+
 	/// Ingests an iterable for processing
 	pub fn ingest(&mut self, val: Iterable) {
 		self.entries.push(val)
 	}
 
+	/// Generates the id of a new record, using the id generation
+	/// strategy of the table if it has been defined
+	async fn generate(ctx: &Context<'_>, opt: &Options, tb: &Table) -> Result<Thing, Error> {
+		let (ns, db) = (opt.ns()?, opt.db()?);
+		let mut run = ctx.tx_lock().await;
+		let strategy = match run.get_and_cache_tb(ns, db, tb).await {
+			Ok(tb) => tb.id_strategy,
+			Err(Error::TbNotFound {
+				..
+			}) => IdStrategy::Rand,
+			Err(e) => return Err(e),
+		};
+		let id = match strategy.generate(opt)? {
+			Some(id) => id,
+			None => Id::from(run.get_next_record_id(ns, db, tb).await?),
+		};
+		Ok(Thing {
+			tb: tb.0.to_owned(),
+			id,
+		})
+	}
+
 	/// Prepares a value for processing
 	pub async fn prepare(
 		&mut self,
@@ -108,8 +185,8 @@ impl Iterator {
 						let id = match data.rid(stk, ctx, opt).await? {
 							// Generate a new id from the id field
 							Some(id) => id.generate(&v, false)?,
-							// Generate a new random table id
-							None => v.generate(),
+							// Generate a new table id
+							None => Self::generate(ctx, opt, &v).await?,
 						};
 						self.ingest(Iterable::Thing(id))
 					}
@@ -121,8 +198,8 @@ impl Iterator {
 				// There is no data clause so create a record id
 				None => match stm {
 					Statement::Create(_) => {
-						// Generate a new random table id
-						self.ingest(Iterable::Thing(v.generate()))
+						// Generate a new table id
+						self.ingest(Iterable::Thing(Self::generate(ctx, opt, &v).await?))
 					}
 					_ => {
 						// Ingest the table for scanning
@@ -306,38 +383,67 @@ impl Iterator {
 		)?;
 		// Extract the expected behaviour depending on the presence of EXPLAIN with or without FULL
 		let mut plan = Plan::new(ctx, stm, &self.entries, &self.results);
+		// Summarise the plan before the iterables are consumed
+		if plan.explanation.is_some() && self.summary.is_some() {
+			self.summary = Some(self.summarise(&cancel_ctx, opt).await?);
+		}
 		if plan.do_iterate {
-			// Process prepared values
-			if let Some(qp) = ctx.get_query_planner() {
-				while let Some(s) = qp.next_iteration_stage().await {
-					let is_last = matches!(s, IterationStage::Iterate(_));
-					cancel_ctx.set_iteration_stage(s);
-					if !is_last {
-						self.clone().iterate(stk, &cancel_ctx, opt, stm).await?;
-					};
+			// Count the records from their keys, if nothing else is needed
+			let counted = plan.explanation.is_none() && self.output_count(ctx, opt, stm).await?;
+			if !counted {
+				// Process prepared values
+				if let Some(qp) = ctx.get_query_planner() {
+					while let Some(s) = qp.next_iteration_stage().await {
+						let is_last = matches!(s, IterationStage::Iterate(_));
+						cancel_ctx.set_iteration_stage(s);
+						if !is_last {
+							self.clone().iterate(stk, &cancel_ctx, opt, stm).await?;
+						};
+					}
 				}
+				self.iterate(stk, &cancel_ctx, opt, stm).await?;
 			}
-			self.iterate(stk, &cancel_ctx, opt, stm).await?;
 			// Return any document errors
 			if let Some(e) = self.error.take() {
 				return Err(e);
 			}
+			// Send any remaining results to the stream
+			self.output_stream().await?;
 			// Process any SPLIT clause
 			self.output_split(stk, ctx, opt, stm).await?;
+			// Process any window aggregates
+			self.output_windows(stk, ctx, opt, stm).await?;
 			// Process any GROUP clause
 			if let Results::Groups(g) = &mut self.results {
 				self.results = Results::Memory(g.output(stk, ctx, opt, stm).await?);
 			}
+			// Process any HAVING clause
+			self.output_having(stk, ctx, opt, stm).await?;
 
 			// Process any ORDER clause
 			if let Some(orders) = stm.order() {
 				self.results.sort(orders);
 			}
 
+			// Process any DISTINCT ON clause
+			self.output_distinct(stm)?;
+
 			// Process any START & LIMIT clause
 			self.results.start_limit(self.start.as_ref(), self.limit.as_ref());
 
+			// Check the number of rows which are returned
+			if let Some(limit) = opt.max_result_rows {
+				if self.results.len() + self.streamed > limit {
+					return Err(Error::ResultRowsExceeded {
+						limit,
+					});
+				}
+			}
+
 			if let Some(e) = &mut plan.explanation {
+				if let Some(s) = &mut self.summary {
+					s.actual = Some(self.results.len());
+				}
 				e.add_fetch(self.results.len());
 			} else {
 				// Process any FETCH clause
@@ -388,6 +494,26 @@ impl Iterator {
 		Ok(())
 	}
 
+	#[inline]
+	async fn output_stream(&mut self) -> Result<(), Error> {
+		if let Some(stream) = &self.stream {
+			let mut values = self.results.take()?;
+			// Don't send more results than the LIMIT clause allows
+			if let Some(l) = self.limit {
+				values.truncate(l.saturating_sub(self.streamed));
+			}
+			if values.is_empty() {
+				return Ok(());
+			}
+			self.streamed += values.len();
+			// The receiver is no longer listening
+			if !stream.send(values).await {
+				return Err(Error::QueryCancelled);
+			}
+		}
+		Ok(())
+	}
+
 	#[inline]
 	async fn output_split(
 		&mut self,
@@ -432,6 +558,64 @@ impl Iterator {
 		Ok(())
 	}
 
+	#[inline]
+	async fn output_windows(
+		&mut self,
+		stk: &mut Stk,
+		ctx: &Context<'_>,
+		opt: &Options,
+		stm: &Statement<'_>,
+	) -> Result<(), Error> {
+		if let Some(fields) = stm.expr().filter(|f| f.has_windows()) {
+			// Get the query result
+			let mut values = self.results.take()?;
+			// Compute the aggregates over all of the results
+			window::compute(stk, ctx, opt, fields, &mut values).await?;
+			self.results = values.into();
+		}
+		Ok(())
+	}
+
+	#[inline]
+	async fn output_having(
+		&mut self,
+		stk: &mut Stk,
+		ctx: &Context<'_>,
+		opt: &Options,
+		stm: &Statement<'_>,
+	) -> Result<(), Error> {
+		if let Some(having) = stm.having() {
+			// Get the grouped results
+			let values = self.results.take()?;
+			let mut results = Vec::with_capacity(values.len());
+			// Loop over each grouped value
+			for obj in values {
+				// Check the condition against the aggregated fields
+				if having.compute(stk, ctx, opt, Some(&(&obj).into())).await?.is_truthy() {
+					results.push(obj);
+				}
+			}
+			self.results = results.into();
+		}
+		Ok(())
+	}
+
+	#[inline]
+	fn output_distinct(&mut self, stm: &Statement<'_>) -> Result<(), Error> {
+		if let Some(idioms) = stm.distinct() {
+			let mut keys = HashSet::new();
+			// Keep the first result for each distinct key, in the current order
+			let results: Vec<Value> = self
+				.results
+				.take()?
+				.into_iter()
+				.filter(|v| keys.insert(idioms.iter().map(|i| v.pick(i)).collect::<Vec<_>>()))
+				.collect();
+			self.results = results.into();
+		}
+		Ok(())
+	}
+
 	#[inline]
 	async fn output_fetch(
 		&mut self,
@@ -441,12 +625,14 @@ impl Iterator {
 		stm: &Statement<'_>,
 	) -> Result<(), Error> {
 		if let Some(fetchs) = stm.fetch() {
+			// Each linked record is only loaded once
+			let cache = FetchCache::default();
 			for fetch in fetchs.iter() {
 				let mut values = self.results.take()?;
 				// Loop over each result value
 				for obj in &mut values {
 					// Fetch the value at the path
-					stk.run(|stk| obj.fetch(stk, ctx, opt, fetch)).await?;
+					stk.run(|stk| obj.fetch(stk, ctx, opt, &cache, fetch)).await?;
 				}
 				self.results = values.into();
 			}
@@ -454,6 +640,224 @@ impl Iterator {
 		Ok(())
 	}
 
+	/// Counts the records of a `SELECT count() FROM ...` statement by
+	/// scanning the keys of each table, or of an index which satisfies
+	/// the whole WHERE clause, without fetching or processing the records.
+	/// Returns `false` if the records have to be iterated instead.
+	async fn output_count(
+		&mut self,
+		ctx: &Context<'_>,
+		opt: &Options,
+		stm: &Statement<'_>,
+	) -> Result<bool, Error> {
+		// Check if only the records are counted
+		let Some(idiom) = Self::count_idiom(stm) else {
+			return Ok(false);
+		};
+		// Check if each table can be counted
+		for v in self.entries.iter() {
+			let (tb, idx) = match (v, stm.conds()) {
+				(Iterable::Table(tb), None) => (tb, None),
+				(Iterable::Index(tb, irf), Some(cond)) => (tb, Some((*irf, cond))),
+				_ => return Ok(false),
+			};
+			let mut tx = ctx.tx_lock().await;
+			// Check that the table exists
+			tx.check_ns_db_tb(opt.ns()?, opt.db()?, &tb.0, opt.strict).await?;
+			let perms = opt.check_perms(Action::View)?;
+			match tx.get_and_cache_tb(opt.ns()?, opt.db()?, &tb.0).await {
+				// Check that every record can be selected
+				Ok(v) if perms && !matches!(v.permissions.select, Permission::Full) => {
+					return Ok(false)
+				}
+				// Soft-deleted records are still stored in the table, but are not indexed
+				Ok(v) if v.soft_delete => match idx {
+					None if !stm.deleted() => return Ok(false),
+					Some(_) if stm.deleted() => return Ok(false),
+					_ => (),
+				},
+				Err(Error::TbNotFound {
+					..
+				})
+				| Ok(_) => (),
+				Err(e) => return Err(e),
+			}
+			// Check that the index is only ever used for a single scalar value
+			if let Some((irf, cond)) = idx {
+				let Some(exe) = ctx.get_query_planner().and_then(|qp| qp.get_query_executor(&tb.0))
+				else {
+					return Ok(false);
+				};
+				let Some(id) = exe.is_covering(irf, cond) else {
+					return Ok(false);
+				};
+				let fields = tx.all_tb_fields(opt.ns()?, opt.db()?, &tb.0).await?;
+				if !fields.iter().any(|f| f.name.eq(id) && f.kind.as_ref().is_some_and(is_scalar)) {
+					return Ok(false);
+				}
+			}
+		}
+		// A count without a GROUP clause returns a row for each record
+		let grouped = stm.group().is_some();
+		let max = match (grouped, self.limit) {
+			(false, Some(l)) => l + self.start.unwrap_or(0),
+			_ => usize::MAX,
+		};
+		// Count the records in each table
+		let mut count = 0;
+		for v in mem::take(&mut self.entries) {
+			if count >= max || ctx.is_done() {
+				break;
+			}
+			count += match v {
+				Iterable::Table(tb) => Self::count_table(ctx, opt, &tb, max - count).await?,
+				Iterable::Index(tb, irf) => {
+					Self::count_index(ctx, opt, &tb, irf, max - count).await?
+				}
+				_ => 0,
+			};
+		}
+		// Output the count in the same shape as the processed records
+		let results: Vec<Value> = match grouped {
+			true if count == 0 => vec![],
+			true => {
+				let mut obj = Value::base();
+				obj.put(&idiom, count.into());
+				vec![obj]
+			}
+			false => {
+				let mut obj = Value::base();
+				obj.put(&idiom, Value::from(1));
+				vec![obj; count.min(max)]
+			}
+		};
+		self.results = results.into();
+		Ok(true)
+	}
+
+	/// Returns the output field of a statement which only counts records
+	fn count_idiom(stm: &Statement<'_>) -> Option<Idiom> {
+		let Statement::Select(s) = stm else {
+			return None;
+		};
+		// Check that the records themselves are not needed
+		if s.omit.is_some()
+			|| s.split.is_some()
+			|| s.fetch.is_some()
+			|| s.version.is_some()
+			|| s.tempfiles
+			|| s.group.as_ref().is_some_and(|g| !g.is_empty())
+		{
+			return None;
+		}
+		// Check that count() is the only field
+		match s.expr.0.as_slice() {
+			[Field::Single {
+				expr,
+				alias,
+			}] if !s.expr.1 => match expr {
+				Value::Function(f)
+					if matches!(f.get_optimised_aggregate(), OptimisedAggregate::Count) =>
+				{
+					Some(alias.clone().unwrap_or_else(|| expr.to_idiom()))
+				}
+				_ => None,
+			},
+			_ => None,
+		}
+	}
+
+	/// Summarises how the records are found, counting the records in any
+	/// tables or indexes which are iterated, up to a sample size for each
+	async fn summarise(&self, ctx: &Context<'_>, opt: &Options) -> Result<Summary, Error> {
+		let mut summary = Summary {
+			estimated: Some(0),
+			..Default::default()
+		};
+		let sample = EXPLAIN_SAMPLE_SIZE;
+		for v in self.entries.iter() {
+			let count = match v {
+				// Every record in the table is scanned
+				Iterable::Table(tb) => {
+					summary.full_scan = true;
+					Some(Self::count_table(ctx, opt, tb, sample).await?)
+				}
+				// The records are looked up through an index
+				Iterable::Index(tb, irf) => {
+					if let Some(exe) =
+						ctx.get_query_planner().and_then(|qp| qp.get_query_executor(&tb.0))
+					{
+						summary.indexes.push(exe.explain(*irf));
+					}
+					Some(Self::count_index(ctx, opt, tb, *irf, sample).await?)
+				}
+				// The number of records in a range or graph edge is not known in advance
+				Iterable::Range(_) | Iterable::Edges(_) => None,
+				// Any other iterable yields a single record or value
+				_ => Some(1),
+			};
+			// Larger tables and indexes are only counted up to the sample size
+			let count = count.map(|c| {
+				summary.lower_bound |= c >= sample;
+				c.min(sample)
+			});
+			summary.estimated = summary.estimated.zip(count).map(|(a, b)| a + b);
+		}
+		Ok(summary)
+	}
+
+	async fn count_table(
+		ctx: &Context<'_>,
+		opt: &Options,
+		tb: &Table,
+		max: usize,
+	) -> Result<usize, Error> {
+		let beg = thing::prefix(opt.ns()?, opt.db()?, tb);
+		let end = thing::suffix(opt.ns()?, opt.db()?, tb);
+		let mut count = 0;
+		let mut next_page = Some(ScanPage::from(beg..end));
+		while let Some(page) = next_page {
+			if count >= max || ctx.is_done() {
+				break;
+			}
+			// The values are dropped without being deserialized
+			let res = ctx.tx_lock().await.scan_paged(page, PROCESSOR_BATCH_SIZE).await?;
+			next_page = res.next_page;
+			count += res.values.len();
+		}
+		Ok(count)
+	}
+
+	async fn count_index(
+		ctx: &Context<'_>,
+		opt: &Options,
+		tb: &Table,
+		irf: IteratorRef,
+		max: usize,
+	) -> Result<usize, Error> {
+		let Some(exe) = ctx.get_query_planner().and_then(|qp| qp.get_query_executor(&tb.0)) else {
+			return Ok(0);
+		};
+		let Some(mut iterator) = exe.new_iterator(opt, irf).await? else {
+			return Ok(0);
+		};
+		let mut count = 0;
+		loop {
+			if count >= max || ctx.is_done() {
+				break;
+			}
+			// The index entries hold the record ids, which are not fetched
+			let mut tx = ctx.tx_lock().await;
+			let records: Vec<CollectorRecord> =
+				iterator.next_batch(ctx, &mut tx, PROCESSOR_BATCH_SIZE).await?;
+			if records.is_empty() {
+				break;
+			}
+			count += records.len();
+		}
+		Ok(count)
+	}
+
 	#[cfg(target_arch = "wasm32")]
 	async fn iterate(
 		&mut self,
@@ -607,6 +1011,23 @@ impl Iterator {
 				return;
 			}
 			Ok(v) => {
+				// Skip the results which are on a previous page
+				if let Some((orders, after)) = &self.after {
+					if orders.compare(&v, after) != Ordering::Greater {
+						return;
+					}
+				}
+				// Check the number of records which are modified
+				if !stm.is_select() {
+					self.mutated += 1;
+					if let Some(limit) = opt.max_mutated_records.filter(|l| self.mutated > *l) {
+						self.error = Some(Error::MutatedRecordsExceeded {
+							limit,
+						});
+						self.run.cancel();
+						return;
+					}
+				}
 				if let Err(e) = self.results.push(stk, ctx, opt, stm, v).await {
 					self.error = Some(e);
 					self.run.cancel();
@@ -614,17 +1035,46 @@ impl Iterator {
 				}
 			}
 		}
+		// Include any results which have already been streamed
+		let len = self.results.len() + self.streamed;
+		// Stop once more rows are collected than can be returned, as long as
+		// the rows are not reduced by grouping or filtering after collection
+		if let Some(limit) = opt.max_result_rows {
+			if stm.group().is_none()
+				&& stm.distinct().is_none()
+				&& stm.having().is_none()
+				&& self.limit.map_or(true, |l| l > limit)
+				&& len > limit.saturating_add(self.start.unwrap_or(0))
+			{
+				self.error = Some(Error::ResultRowsExceeded {
+					limit,
+				});
+				self.run.cancel();
+				return;
+			}
+		}
 		// Check if we can exit
-		if stm.group().is_none() && stm.order().is_none() {
+		if stm.group().is_none()
+			&& stm.order().is_none()
+			&& stm.distinct().is_none()
+			&& !stm.expr().is_some_and(|f| f.has_windows())
+		{
 			if let Some(l) = self.limit {
 				if let Some(s) = self.start {
-					if self.results.len() == l + s {
+					if len == l + s {
 						self.run.cancel()
 					}
-				} else if self.results.len() == l {
+				} else if len == l {
 					self.run.cancel()
 				}
 			}
 		}
+		// Send a full batch of results to the stream
+		if self.stream.as_ref().is_some_and(|s| self.results.len() >= s.batch_size) {
+			if let Err(e) = self.output_stream().await {
+				self.error = Some(e);
+				self.run.cancel();
+			}
+		}
 	}
 }
@@ -2,21 +2,27 @@
 //! In this module we essentially manage the entire lifecycle of a database request acting as the
 //! glue between the API and the response. In this module we use channels as a transport layer
 //! and executors to process the operations. This module also gives a `context` to the transaction.
+pub(crate) mod cursor;
 mod distinct;
 mod executor;
 mod group;
 mod iterator;
 mod notification;
 mod options;
+mod params;
 mod plan;
 mod processor;
+mod pushdown;
 mod response;
 mod result;
 mod session;
+mod slowlog;
 mod statement;
 mod store;
+mod stream;
 mod transaction;
 mod variables;
+mod window;
 
 pub mod capabilities;
 pub mod lifecycle;
@@ -28,9 +34,11 @@ pub use self::notification::*;
 pub use self::options::*;
 pub use self::response::*;
 pub use self::session::*;
+pub use self::stream::*;
 
 pub(crate) use self::executor::*;
 pub(crate) use self::iterator::*;
+pub(crate) use self::plan::Summary;
 pub(crate) use self::statement::*;
 pub(crate) use self::transaction::*;
 pub(crate) use self::variables::*;
@@ -1,4 +1,4 @@
-use crate::cnf::MAX_COMPUTATION_DEPTH;
+use crate::cnf::{MAX_COMPUTATION_DEPTH, MAX_MUTATED_RECORDS, MAX_RESULT_ROWS};
 use crate::dbs::Notification;
 use crate::err::Error;
 use crate::iam::{Action, Auth, ResourceKind, Role};
@@ -26,6 +26,10 @@ pub struct Options {
 	db: Option<Arc<str>>,
 	/// Approximately how large is the current call stack?
 	dive: u32,
+	/// How many rows can a statement return?
+	pub max_result_rows: Option<usize>,
+	/// How many records can a statement modify?
+	pub max_mutated_records: Option<usize>,
 	/// Connection authentication data
 	pub auth: Arc<Auth>,
 	/// Is authentication enabled?
@@ -38,6 +42,12 @@ pub struct Options {
 	pub perms: bool,
 	/// Should we error if tables don't exist?
 	pub strict: bool,
+	/// Should we error if a condition is not a boolean?
+	pub strict_conditions: bool,
+	/// Should we error if a parameter has not been set?
+	pub strict_params: bool,
+	/// Should we create missing array elements matched by a filter?
+	pub create_paths: bool,
 	/// Should we process field queries?
 	pub import: bool,
 	/// Should we process function futures?
@@ -81,10 +91,15 @@ impl Options {
 			ns: None,
 			db: None,
 			dive: *MAX_COMPUTATION_DEPTH,
+			max_result_rows: Some(*MAX_RESULT_ROWS).filter(|v| *v > 0),
+			max_mutated_records: Some(*MAX_MUTATED_RECORDS).filter(|v| *v > 0),
 			live: false,
 			perms: true,
 			force: Force::None,
 			strict: false,
+			strict_conditions: false,
+			strict_params: false,
+			create_paths: false,
 			import: false,
 			futures: false,
 			projections: false,
@@ -133,6 +148,18 @@ impl Options {
 		self
 	}
 
+	/// Set the maximum number of rows a statement can return.
+	pub fn with_max_result_rows(mut self, rows: Option<usize>) -> Self {
+		self.max_result_rows = rows;
+		self
+	}
+
+	/// Set the maximum number of records a statement can modify.
+	pub fn with_max_mutated_records(mut self, records: Option<usize>) -> Self {
+		self.max_mutated_records = records;
+		self
+	}
+
 	/// Set the Node ID for subsequent code which uses
 	/// this `Options`, with support for chaining.
 	pub fn with_id(mut self, id: Uuid) -> Self {
@@ -187,6 +214,24 @@ impl Options {
 		self
 	}
 
+	/// Specify if we should error when a condition is not a boolean
+	pub fn with_strict_conditions(mut self, strict_conditions: bool) -> Self {
+		self.strict_conditions = strict_conditions;
+		self
+	}
+
+	/// Specify if we should error when a parameter has not been set
+	pub fn with_strict_params(mut self, strict_params: bool) -> Self {
+		self.strict_params = strict_params;
+		self
+	}
+
+	/// Specify if we should create array elements when a filter matches none
+	pub fn with_create_paths(mut self, create_paths: bool) -> Self {
+		self.create_paths = create_paths;
+		self
+	}
+
 	/// Specify if we are currently importing data
 	pub fn with_import(mut self, import: bool) -> Self {
 		self.import = import;
@@ -0,0 +1,351 @@
+//! Checks for parameters which are referenced by a query, but which have not
+//! been set, when the query is run with strict parameters.
+//!
+//! Only the parameters in the text of the query are checked, before each
+//! statement is run. Parameters which are referenced by stored definitions,
+//! such as functions, events, and permissions, resolve to NONE as normal.
+use crate::ctx::Context;
+use crate::dbs::{Options, Transaction};
+use crate::err::Error;
+use crate::kvs::{self, Datastore, LockType::*, TransactionType::*};
+use crate::sql::param::ParamCollector;
+use crate::sql::statement::Statement;
+use crate::sql::statements::{
+	CreateStatement, DeleteStatement, ForeachStatement, IfelseStatement, InsertStatement,
+	OutputStatement, RelateStatement, SetStatement, UpdateStatement, UpsertStatement,
+};
+use crate::sql::{Block, Cond, Data, Entry, Output, Param, Subquery, Value};
+
+/// The parameters which are set by the database while a query is running
+const BUILTIN_PARAMS: &[&str] = &[
+	"this", "self", "parent", "value", "before", "after", "input", "event", "field", "access",
+	"auth", "token", "session",
+];
+
+/// Check that each of the parameters referenced by a statement has been set.
+///
+/// A parameter is set if it is set within the statement, if it has been
+/// bound to the query or set by an earlier statement, or if it has been
+/// defined in the current database.
+pub(super) async fn check(
+	kvs: &Datastore,
+	txn: Option<&Transaction>,
+	ctx: &Context<'_>,
+	opt: &Options,
+	stm: &Statement,
+) -> Result<(), Error> {
+	// Find the parameters which are not set within the statement
+	let mut params = Params::default();
+	statement(&mut params, stm);
+	let unknown: Vec<&str> = params
+		.found
+		.into_iter()
+		.filter(|v| !BUILTIN_PARAMS.contains(v) && ctx.value(v).is_none())
+		.collect();
+	let Some(name) = unknown.first() else {
+		return Ok(());
+	};
+	// Parameters can only be defined in a database
+	let (Ok(ns), Ok(db)) = (opt.ns(), opt.db()) else {
+		return Err(Error::UnknownParam {
+			name: name.to_string(),
+		});
+	};
+	// Check the parameters in the current transaction, if there is one
+	match txn {
+		Some(txn) => defined(&mut *txn.lock().await, ns, db, &unknown).await,
+		None => {
+			let mut tx = kvs.transaction(Read, Optimistic).await?;
+			let res = defined(&mut tx, ns, db, &unknown).await;
+			tx.cancel().await?;
+			res
+		}
+	}
+}
+
+/// Check that each of the parameters has been defined in the database
+async fn defined(
+	tx: &mut kvs::Transaction,
+	ns: &str,
+	db: &str,
+	names: &[&str],
+) -> Result<(), Error> {
+	for name in names {
+		match tx.get_and_cache_db_param(ns, db, name).await {
+			Ok(_) => (),
+			Err(Error::PaNotFound {
+				..
+			}) => {
+				return Err(Error::UnknownParam {
+					name: name.to_string(),
+				})
+			}
+			Err(e) => return Err(e),
+		}
+	}
+	Ok(())
+}
+
+/// The parameters found in a statement, excluding those set within it
+#[derive(Default)]
+struct Params<'a> {
+	// The parameters which are set in the current scope
+	scope: Vec<&'a str>,
+	// The parameters which are referenced, but not set
+	found: Vec<&'a str>,
+}
+
+impl<'a> ParamCollector<'a> for Params<'a> {
+	fn param(&mut self, v: &'a Param) {
+		let name = v.as_str();
+		if !self.scope.contains(&name) && !self.found.contains(&name) {
+			self.found.push(name);
+		}
+	}
+	// Blocks and statements are walked here, as they can set parameters
+	fn other(&mut self, v: &'a Value) -> bool {
+		match v {
+			Value::Block(v) => block(self, v),
+			Value::Future(v) => block(self, &v.0),
+			Value::Model(v) => values(self, &v.args),
+			Value::Subquery(v) => match v.as_ref() {
+				Subquery::Ifelse(v) => ifelse(self, v),
+				Subquery::Output(v) => output(self, v),
+				Subquery::Create(v) => create(self, v),
+				Subquery::Update(v) => update(self, v),
+				Subquery::Upsert(v) => upsert(self, v),
+				Subquery::Delete(v) => delete(self, v),
+				Subquery::Relate(v) => relate(self, v),
+				Subquery::Insert(v) => insert(self, v),
+				_ => (),
+			},
+			_ => (),
+		}
+		true
+	}
+}
+
+fn statement<'a>(p: &mut Params<'a>, stm: &'a Statement) {
+	match stm {
+		Statement::Value(v) => value(p, v),
+		Statement::Select(v) => {
+			v.params(p);
+		}
+		Statement::Create(v) => create(p, v),
+		Statement::Update(v) => update(p, v),
+		Statement::Upsert(v) => upsert(p, v),
+		Statement::Delete(v) => delete(p, v),
+		Statement::Relate(v) => relate(p, v),
+		Statement::Insert(v) => insert(p, v),
+		Statement::Output(v) => output(p, v),
+		Statement::Ifelse(v) => ifelse(p, v),
+		Statement::Foreach(v) => foreach(p, v),
+		Statement::Live(v) => {
+			v.expr.params(p);
+			value(p, &v.what);
+			cond(p, &v.cond);
+		}
+		Statement::Explain(v) => {
+			v.what.params(p);
+		}
+		Statement::Set(v) => set(p, v),
+		Statement::Throw(v) => value(p, &v.error),
+		Statement::Kill(v) => value(p, &v.id),
+		// Definitions are stored, and are not checked
+		_ => (),
+	}
+}
+
+fn create<'a>(p: &mut Params<'a>, v: &'a CreateStatement) {
+	values(p, &v.what.0);
+	data(p, v.data.as_ref());
+	out(p, &v.output);
+}
+
+fn update<'a>(p: &mut Params<'a>, v: &'a UpdateStatement) {
+	values(p, &v.what.0);
+	data(p, v.data.as_ref());
+	cond(p, &v.cond);
+	out(p, &v.output);
+}
+
+fn upsert<'a>(p: &mut Params<'a>, v: &'a UpsertStatement) {
+	values(p, &v.what.0);
+	data(p, v.data.as_ref());
+	cond(p, &v.cond);
+	out(p, &v.output);
+}
+
+fn delete<'a>(p: &mut Params<'a>, v: &'a DeleteStatement) {
+	values(p, &v.what.0);
+	cond(p, &v.cond);
+	out(p, &v.output);
+}
+
+fn relate<'a>(p: &mut Params<'a>, v: &'a RelateStatement) {
+	value(p, &v.kind);
+	value(p, &v.from);
+	value(p, &v.with);
+	data(p, v.data.as_ref());
+	out(p, &v.output);
+}
+
+fn insert<'a>(p: &mut Params<'a>, v: &'a InsertStatement) {
+	if let Some(v) = &v.into {
+		value(p, v);
+	}
+	data(p, Some(&v.data));
+	data(p, v.update.as_ref());
+	out(p, &v.output);
+}
+
+fn output<'a>(p: &mut Params<'a>, v: &'a OutputStatement) {
+	value(p, &v.what);
+}
+
+fn set<'a>(p: &mut Params<'a>, v: &'a SetStatement) {
+	// Declared parameters are checked before the query is run
+	if !v.is_declaration() {
+		value(p, &v.what);
+	}
+	p.scope.push(&v.name);
+}
+
+fn ifelse<'a>(p: &mut Params<'a>, v: &'a IfelseStatement) {
+	let len = p.scope.len();
+	for v in v.lets.iter().flatten() {
+		set(p, v);
+	}
+	for (c, t) in v.exprs.iter() {
+		value(p, c);
+		value(p, t);
+	}
+	if let Some(v) = &v.close {
+		value(p, v);
+	}
+	p.scope.truncate(len);
+}
+
+fn foreach<'a>(p: &mut Params<'a>, v: &'a ForeachStatement) {
+	value(p, &v.range);
+	let len = p.scope.len();
+	p.scope.push(v.param.as_str());
+	block(p, &v.block);
+	p.scope.truncate(len);
+}
+
+fn block<'a>(p: &mut Params<'a>, v: &'a Block) {
+	// Parameters which are set in a block are only set within it
+	let len = p.scope.len();
+	for v in v.0.iter() {
+		match v {
+			Entry::Value(v) => value(p, v),
+			Entry::Set(v) => set(p, v),
+			Entry::Ifelse(v) => ifelse(p, v),
+			Entry::Select(v) => {
+				v.params(p);
+			}
+			Entry::Create(v) => create(p, v),
+			Entry::Update(v) => update(p, v),
+			Entry::Upsert(v) => upsert(p, v),
+			Entry::Delete(v) => delete(p, v),
+			Entry::Relate(v) => relate(p, v),
+			Entry::Insert(v) => insert(p, v),
+			Entry::Output(v) => output(p, v),
+			Entry::Throw(v) => value(p, &v.error),
+			Entry::Foreach(v) => foreach(p, v),
+			_ => (),
+		}
+	}
+	p.scope.truncate(len);
+}
+
+fn values<'a>(p: &mut Params<'a>, v: &'a [Value]) {
+	for v in v.iter() {
+		value(p, v);
+	}
+}
+
+fn cond<'a>(p: &mut Params<'a>, v: &'a Option<Cond>) {
+	if let Some(v) = v {
+		value(p, &v.0);
+	}
+}
+
+fn out<'a>(p: &mut Params<'a>, v: &'a Option<Output>) {
+	if let Some(Output::Fields(v)) = v {
+		v.params(p);
+	}
+}
+
+fn data<'a>(p: &mut Params<'a>, v: Option<&'a Data>) {
+	match v {
+		Some(Data::SetExpression(v) | Data::UpdateExpression(v)) => {
+			for (i, _, v) in v.iter() {
+				i.params(p);
+				value(p, v);
+			}
+		}
+		Some(Data::ValuesExpression(v)) => {
+			for (i, v) in v.iter().flatten() {
+				i.params(p);
+				value(p, v);
+			}
+		}
+		Some(
+			Data::PatchExpression(v)
+			| Data::MergeExpression(v)
+			| Data::MergePatchExpression(v)
+			| Data::ReplaceExpression(v)
+			| Data::ContentExpression(v)
+			| Data::SingleExpression(v),
+		) => value(p, v),
+		Some(Data::UnsetExpression(v)) => {
+			for v in v.iter() {
+				v.params(p);
+			}
+		}
+		Some(Data::EmptyExpression) | None => (),
+	}
+}
+
+fn value<'a>(p: &mut Params<'a>, v: &'a Value) {
+	// Every value is walked, so the result is always true
+	v.params(p);
+}
+
+#[cfg(test)]
+mod tests {
+	use super::*;
+
+	fn unknown(sql: &str) -> Vec<String> {
+		let query = crate::syn::parse(sql).unwrap();
+		let mut params = Params::default();
+		statement(&mut params, &query.0 .0[0]);
+		params.found.into_iter().map(String::from).collect()
+	}
+
+	#[test]
+	fn finds_referenced_params() {
+		assert_eq!(
+			unknown("SELECT * FROM $table WHERE age > $age LIMIT $limit"),
+			["table", "age", "limit"]
+		);
+		assert_eq!(unknown("CREATE person:[$id, 1] SET name = $name.first"), ["id", "name"]);
+		assert_eq!(unknown("RETURN fn::greet($name) + (SELECT * FROM $x)"), ["name", "x"]);
+		assert_eq!(unknown("SELECT * FROM person FETCH friends[WHERE age > $age]"), ["age"]);
+	}
+
+	#[test]
+	fn skips_params_set_within_the_statement() {
+		assert_eq!(unknown("FOR $v IN $list { CREATE person SET v = $v, w = $w; }"), ["list", "w"]);
+		assert_eq!(unknown("{ LET $a = 1; RETURN $a + $b; }"), ["b"]);
+		// Parameters set in a block are not set outside of it
+		assert_eq!(unknown("IF $c { LET $a = 1; RETURN $a; } ELSE { RETURN $a; }"), ["c", "a"]);
+	}
+
+	#[test]
+	fn skips_stored_definitions() {
+		assert!(unknown("DEFINE FUNCTION fn::greet() { RETURN $name; }").is_empty());
+	}
+}
@@ -39,6 +39,22 @@ impl Plan {
 	}
 }
 
+/// A summary of how the records of a statement are found
+#[derive(Default)]
+pub(crate) struct Summary {
+	/// Whether every record in a table is scanned
+	pub(crate) full_scan: bool,
+	/// The plans of the indexes which the records are looked up through
+	pub(crate) indexes: Vec<Value>,
+	/// The number of records which are iterated, if this can be known in advance
+	pub(crate) estimated: Option<usize>,
+	/// Whether a table or index has more records than were counted, so that
+	/// the estimated number of records is only a lower bound
+	pub(crate) lower_bound: bool,
+	/// The number of records which are fetched, if the statement was run
+	pub(crate) actual: Option<usize>,
+}
+
 #[derive(Default)]
 pub(super) struct Explanation(Vec<ExplainItem>);
 
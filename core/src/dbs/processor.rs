@@ -3,6 +3,7 @@ use crate::ctx::Context;
 #[cfg(not(target_arch = "wasm32"))]
 use crate::dbs::distinct::AsyncDistinct;
 use crate::dbs::distinct::SyncDistinct;
+use crate::dbs::pushdown::Pushdown;
 use crate::dbs::{Iterable, Iterator, Operable, Options, Processed, Statement};
 use crate::err::Error;
 use crate::idx::planner::iterators::{CollectorRecord, IteratorRef, ThingIterator};
@@ -308,6 +309,8 @@ impl<'a> Processor<'a> {
 		// Prepare the start and end keys
 		let beg = thing::prefix(opt.ns()?, opt.db()?, v);
 		let end = thing::suffix(opt.ns()?, opt.db()?, v);
+		// Check the WHERE clause while decoding records
+		let pushdown = Pushdown::new(stm);
 		// Loop until no more keys
 		let mut next_page = Some(ScanPage::from(beg..end));
 		while let Some(page) = next_page {
@@ -330,8 +333,15 @@ impl<'a> Processor<'a> {
 					break;
 				}
 				// Parse the data from the store
+				let val: Value = match &pushdown {
+					// Skip records which can not match the WHERE clause
+					Some(p) => match p.decode(&v)? {
+						Some(v) => v,
+						None => continue,
+					},
+					None => (&v).into(),
+				};
 				let key: thing::Thing = (&k).into();
-				let val: Value = (&v).into();
 				let rid = Thing::from((key.tb, key.id));
 				// Create a new operable value
 				let val = Operable::Value(val);
@@ -379,6 +389,8 @@ impl<'a> Processor<'a> {
 				key
 			}
 		};
+		// Check the WHERE clause while decoding records
+		let pushdown = Pushdown::new(stm);
 		// Loop until no more keys
 		let mut next_page = Some(ScanPage::from(beg..end));
 		while let Some(page) = next_page {
@@ -401,8 +413,15 @@ impl<'a> Processor<'a> {
 					break;
 				}
 				// Parse the data from the store
+				let val: Value = match &pushdown {
+					// Skip records which can not match the WHERE clause
+					Some(p) => match p.decode(&v)? {
+						Some(v) => v,
+						None => continue,
+					},
+					None => (&v).into(),
+				};
 				let key: thing::Thing = (&k).into();
-				let val: Value = (&v).into();
 				let rid = Thing::from((key.tb, key.id));
 				// Create a new operable value
 				let val = Operable::Value(val);
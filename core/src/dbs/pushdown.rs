@@ -0,0 +1,290 @@
+use crate::dbs::Statement;
+use crate::err::Error;
+use crate::fnc::operate;
+use crate::sql::{Expression, Id, Object, Operator, Part, Value};
+use revision::Revisioned;
+use std::collections::BTreeMap;
+
+/// The revision of the stored `Value` enum
+const VALUE_REVISION: u16 = 1;
+/// The index of the `Value::Object` variant in the stored `Value` enum
+const OBJECT_VARIANT: u32 = 9;
+/// The revision of the stored `Object` struct
+const OBJECT_REVISION: u16 = 1;
+
+/// The leading comparisons of a WHERE clause, which are checked while a
+/// record is being decoded.
+///
+/// The fields of a stored record are decoded one at a time, in key order.
+/// Once every field which is compared has been decoded, the comparisons are
+/// checked, and if any of them is not truthy then the WHERE clause can not
+/// match, so the rest of the record is never decoded. Otherwise the rest of
+/// the record is decoded as usual, and the whole WHERE clause is checked
+/// against the record later on.
+pub(super) struct Pushdown {
+	predicates: Vec<Predicate>,
+	// The last field, in key order, which is compared
+	last: String,
+}
+
+/// A comparison between a top-level field of a record and a literal value
+struct Predicate {
+	field: String,
+	op: Operator,
+	value: Value,
+	// Whether the field is on the left of the comparison
+	left: bool,
+}
+
+impl Pushdown {
+	/// Find the comparisons which can be checked while decoding records
+	pub(super) fn new(stm: &Statement<'_>) -> Option<Self> {
+		// Only a SELECT checks the WHERE clause before anything else is done
+		let Statement::Select(_) = stm else {
+			return None;
+		};
+		let mut conds = Vec::new();
+		conjunction(&stm.conds()?.0, &mut conds);
+		// Conditions are checked from left to right, up to the first which
+		// isn't truthy, so the leading comparisons can be checked on their own
+		let predicates: Vec<_> = conds.into_iter().map_while(Predicate::new).collect();
+		let last = predicates.iter().map(|p| &p.field).max()?.clone();
+		Some(Self {
+			predicates,
+			last,
+		})
+	}
+	/// Decode a stored record, returning `None` if it can not match
+	pub(super) fn decode(&self, val: &[u8]) -> Result<Option<Value>, Error> {
+		let reader = &mut &val[..];
+		// Only records which are objects are decoded a field at a time
+		if u16::deserialize_revisioned(reader)? != VALUE_REVISION
+			|| u32::deserialize_revisioned(reader)? != OBJECT_VARIANT
+			|| u16::deserialize_revisioned(reader)? != OBJECT_REVISION
+		{
+			return Ok(Some(Value::deserialize_revisioned(&mut &val[..])?));
+		}
+		let len = usize::deserialize_revisioned(reader)?;
+		let mut obj = BTreeMap::new();
+		let mut checked = false;
+		for _ in 0..len {
+			let key = String::deserialize_revisioned(reader)?;
+			let val = Value::deserialize_revisioned(reader)?;
+			let last = key >= self.last;
+			obj.insert(key, val);
+			// Every compared field has been decoded, or is missing
+			if !checked && last {
+				if !self.matches(&obj) {
+					return Ok(None);
+				}
+				checked = true;
+			}
+		}
+		if !checked && !self.matches(&obj) {
+			return Ok(None);
+		}
+		Ok(Some(Value::Object(Object(obj))))
+	}
+	/// Check if all of the comparisons could be truthy
+	fn matches(&self, obj: &BTreeMap<String, Value>) -> bool {
+		self.predicates.iter().all(|p| p.matches(obj.get(&p.field).unwrap_or(&Value::None)))
+	}
+}
+
+impl Predicate {
+	fn new(cond: &Value) -> Option<Self> {
+		let Value::Expression(e) = cond else {
+			return None;
+		};
+		let Expression::Binary {
+			l,
+			o,
+			r,
+		} = e.as_ref()
+		else {
+			return None;
+		};
+		if !matches!(
+			o,
+			Operator::Equal
+				| Operator::Exact
+				| Operator::NotEqual
+				| Operator::LessThan
+				| Operator::LessThanOrEqual
+				| Operator::MoreThan
+				| Operator::MoreThanOrEqual
+		) {
+			return None;
+		}
+		let (field, value, left) = match (field(l), field(r)) {
+			(Some(f), None) if literal(r) => (f, r, true),
+			(None, Some(f)) if literal(l) => (f, l, false),
+			_ => return None,
+		};
+		Some(Self {
+			field: field.to_owned(),
+			op: o.to_owned(),
+			value: value.to_owned(),
+			left,
+		})
+	}
+	/// Check if the comparison could be truthy for this field value
+	fn matches(&self, val: &Value) -> bool {
+		// Values which need to be computed are checked later on
+		if !plain(val) {
+			return true;
+		}
+		let (l, r) = match self.left {
+			true => (val, &self.value),
+			false => (&self.value, val),
+		};
+		let res = match self.op {
+			Operator::Equal => operate::equal(l, r),
+			Operator::Exact => operate::exact(l, r),
+			Operator::NotEqual => operate::not_equal(l, r),
+			Operator::LessThan => operate::less_than(l, r),
+			Operator::LessThanOrEqual => operate::less_than_or_equal(l, r),
+			Operator::MoreThan => operate::more_than(l, r),
+			Operator::MoreThanOrEqual => operate::more_than_or_equal(l, r),
+			_ => return true,
+		};
+		res.map_or(true, |v| v.is_truthy())
+	}
+}
+
+/// Collect the conditions which are joined with AND, in the order they are checked
+fn conjunction<'a>(cond: &'a Value, out: &mut Vec<&'a Value>) {
+	match cond {
+		Value::Expression(e) => match e.as_ref() {
+			Expression::Binary {
+				l,
+				o: Operator::And,
+				r,
+			} => {
+				conjunction(l, out);
+				conjunction(r, out);
+			}
+			_ => out.push(cond),
+		},
+		_ => out.push(cond),
+	}
+}
+
+/// Get the name of a top-level field
+fn field(v: &Value) -> Option<&str> {
+	match v {
+		Value::Idiom(i) => match i.0.as_slice() {
+			[Part::Field(f)] => Some(f.as_str()),
+			_ => None,
+		},
+		_ => None,
+	}
+}
+
+/// Check if a value is a literal which computes to itself
+fn literal(v: &Value) -> bool {
+	matches!(
+		v,
+		Value::None
+			| Value::Null
+			| Value::Bool(_)
+			| Value::Number(_)
+			| Value::Strand(_)
+			| Value::Duration(_)
+			| Value::Datetime(_)
+			| Value::Uuid(_)
+	)
+}
+
+/// Check if a stored value computes to itself
+fn plain(v: &Value) -> bool {
+	match v {
+		Value::Array(v) => v.iter().all(plain),
+		Value::Object(v) => v.values().all(plain),
+		Value::Thing(v) => matches!(v.id, Id::Number(_) | Id::String(_)),
+		v => literal(v) || matches!(v, Value::Bytes(_) | Value::Geometry(_)),
+	}
+}
+
+#[cfg(test)]
+mod tests {
+	use super::*;
+	use crate::syn::{self, Parse};
+
+	fn pushdown(sql: &str) -> Option<Pushdown> {
+		let mut query = syn::parse(sql).unwrap();
+		let stm = query.0 .0.pop().unwrap();
+		Pushdown::new(&match &stm {
+			crate::sql::Statement::Select(v) => Statement::from(v),
+			crate::sql::Statement::Update(v) => Statement::from(v),
+			_ => panic!("Expected a select or update statement"),
+		})
+	}
+
+	fn encode(val: &Value) -> Vec<u8> {
+		let mut buf = Vec::new();
+		val.serialize_revisioned(&mut buf).unwrap();
+		buf
+	}
+
+	#[test]
+	fn matches_the_stored_object_format() {
+		// The stored format changes when the revision of a value or object changes
+		assert_eq!(Value::revision(), VALUE_REVISION);
+		assert_eq!(Object::revision(), OBJECT_REVISION);
+		// A stored object starts with the revision of the value, the index of
+		// the object variant, and the revision of the object
+		let mut header = Vec::new();
+		VALUE_REVISION.serialize_revisioned(&mut header).unwrap();
+		OBJECT_VARIANT.serialize_revisioned(&mut header).unwrap();
+		OBJECT_REVISION.serialize_revisioned(&mut header).unwrap();
+		let val = encode(&Value::parse("{ a: 1 }"));
+		assert!(val.starts_with(&header), "{val:?}");
+		// Other values do not start with the same header
+		let val = encode(&Value::parse("[{ a: 1 }]"));
+		assert!(!val.starts_with(&header), "{val:?}");
+	}
+
+	#[test]
+	fn finds_the_leading_comparisons() {
+		let p = pushdown("SELECT * FROM t WHERE a = 1 AND 'x' < b AND c.d = 2 AND e = 3").unwrap();
+		let fields: Vec<_> = p.predicates.iter().map(|p| p.field.as_str()).collect();
+		assert_eq!(fields, ["a", "b"]);
+		assert_eq!(p.last, "b");
+		assert!(!p.predicates[1].left);
+		// Nothing is pushed down when the first condition isn't a comparison
+		assert!(pushdown("SELECT * FROM t WHERE string::len(a) = 1 AND b = 2").is_none());
+		assert!(pushdown("SELECT * FROM t WHERE a = 1 OR b = 2").is_none());
+		assert!(pushdown("SELECT * FROM t WHERE a = $param").is_none());
+		assert!(pushdown("SELECT * FROM t").is_none());
+		assert!(pushdown("UPDATE t SET a = 2 WHERE a = 1").is_none());
+	}
+
+	#[test]
+	fn decodes_the_same_record() {
+		let p = pushdown("SELECT * FROM t WHERE b >= 2").unwrap();
+		for val in [
+			"{ a: 'a', b: 2, c: [1, { d: d'2024-01-01T00:00:00Z' }], z: t:1 }",
+			"{ b: 3, z: NONE }",
+			"{ a: 1, c: 2, b: 10 }",
+		] {
+			let val = Value::parse(val);
+			assert_eq!(p.decode(&encode(&val)).unwrap(), Some(val));
+		}
+		// Records which are not objects are decoded as usual
+		let val = Value::parse("[1, 2]");
+		assert_eq!(p.decode(&encode(&val)).unwrap(), Some(val));
+	}
+
+	#[test]
+	fn skips_records_which_can_not_match() {
+		let p = pushdown("SELECT * FROM t WHERE b >= 2 AND a != 'x'").unwrap();
+		assert_eq!(p.decode(&encode(&Value::parse("{ a: 'a', b: 1, c: 3 }"))).unwrap(), None);
+		assert_eq!(p.decode(&encode(&Value::parse("{ a: 'x', b: 2 }"))).unwrap(), None);
+		assert_eq!(p.decode(&encode(&Value::parse("{ a: 'a', c: 3 }"))).unwrap(), None);
+		assert!(p.decode(&encode(&Value::parse("{ a: 'a', b: 2 }"))).unwrap().is_some());
+		// Values which need to be computed are checked later on
+		let val = Value::parse("{ a: 'a', b: <future> { 1 } }");
+		assert_eq!(p.decode(&encode(&val)).unwrap(), Some(val));
+	}
+}
@@ -26,6 +26,8 @@ pub enum QueryType {
 pub struct Response {
 	pub time: Duration,
 	pub result: Result<Value, Error>,
+	/// The cursor for the next page of a paginated SELECT statement
+	pub cursor: Option<String>,
 	// Record the query type in case processing the response is necessary (such as tracking live queries).
 	pub query_type: QueryType,
 }
@@ -57,7 +59,12 @@ impl Serialize for Response {
 	where
 		S: serde::Serializer,
 	{
-		let mut val = serializer.serialize_struct(TOKEN, 3)?;
+		let len = if self.cursor.is_some() {
+			4
+		} else {
+			3
+		};
+		let mut val = serializer.serialize_struct(TOKEN, len)?;
 		val.serialize_field("time", self.speed().as_str())?;
 		match &self.result {
 			Ok(v) => {
@@ -69,6 +76,9 @@ impl Serialize for Response {
 				val.serialize_field("result", &Value::from(e.to_string()))?;
 			}
 		}
+		if let Some(cursor) = &self.cursor {
+			val.serialize_field("cursor", cursor)?;
+		}
 		val.end()
 	}
 }
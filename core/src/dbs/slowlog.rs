@@ -0,0 +1,397 @@
+use crate::iam::{Auth, Level};
+use crate::sql::statement::Statement;
+use crate::sql::statements::{
+	CreateStatement, DeleteStatement, ForeachStatement, IfelseStatement, InsertStatement,
+	OutputStatement, RelateStatement, SelectStatement, UpdateStatement, UpsertStatement,
+};
+use crate::sql::{
+	Block, Cond, Data, Entry, Expression, Field, Fields, Function, Id, Idiom, Output, Param, Part,
+	Subquery, Value,
+};
+use std::ops::Bound;
+use std::time::Duration;
+
+/// Log a statement which took longer than the slow query threshold.
+///
+/// The statement is logged with its literal values redacted, and the
+/// user is identified only by the level at which they are authenticated,
+/// so that the log does not contain any of the data being queried.
+/// Statements which can not be redacted, such as DEFINE statements which
+/// may contain passwords, are logged without their text.
+pub(super) fn log(stm: &Statement, duration: Duration, auth: &Auth) {
+	warn!(
+		"Slow query: {} statement took {:?} at {}: {}",
+		stm.kind(),
+		duration,
+		scope(auth),
+		redact(stm).as_deref().unwrap_or("<redacted>")
+	);
+}
+
+/// Describe the level at which a user is authenticated
+fn scope(auth: &Auth) -> String {
+	match auth.level() {
+		Level::No => String::from("anonymous"),
+		Level::Root => String::from("root"),
+		Level::Namespace(ns) => format!("/ns:{ns}/"),
+		Level::Database(ns, db) => format!("/ns:{ns}/db:{db}/"),
+		// The record id may identify the user
+		Level::Record(ns, db, _) => format!("/ns:{ns}/db:{db}/record/"),
+	}
+}
+
+/// The text of a statement with its literal values replaced with placeholders.
+///
+/// Strings, numbers, durations, and other literal values are each replaced
+/// with a `$?` placeholder, and the ids of record ids with `⟨?⟩`, while the
+/// keywords, identifiers, and parameters are kept, so that the shape of the
+/// query is still recognisable. Returns none if the statement contains any
+/// values which can not be redacted.
+fn redact(stm: &Statement) -> Option<String> {
+	let mut stm = stm.clone();
+	match statement(&mut stm) {
+		true => Some(stm.to_string()),
+		false => None,
+	}
+}
+
+/// The placeholder for a redacted literal value
+fn placeholder() -> Value {
+	Value::Param(Param::from("?"))
+}
+
+/// The placeholder for a redacted record id
+fn placeholder_id() -> Id {
+	Id::String("?".to_owned())
+}
+
+fn statement(stm: &mut Statement) -> bool {
+	match stm {
+		Statement::Value(v) => value(v),
+		Statement::Select(v) => select(v),
+		Statement::Create(v) => create(v),
+		Statement::Update(v) => update(v),
+		Statement::Upsert(v) => upsert(v),
+		Statement::Delete(v) => delete(v),
+		Statement::Relate(v) => relate(v),
+		Statement::Insert(v) => insert(v),
+		Statement::Output(v) => output(v),
+		Statement::Ifelse(v) => ifelse(v),
+		Statement::Foreach(v) => foreach(v),
+		Statement::Live(v) => fields(&mut v.expr) && value(&mut v.what) && cond(&mut v.cond),
+		Statement::Explain(v) => select(&mut v.what),
+		Statement::Set(v) => value(&mut v.what),
+		Statement::Throw(v) => value(&mut v.error),
+		Statement::Kill(v) => value(&mut v.id),
+		// These statements do not contain any data
+		Statement::Analyze(_)
+		| Statement::Begin(_)
+		| Statement::Break(_)
+		| Statement::Cancel(_)
+		| Statement::Commit(_)
+		| Statement::Continue(_)
+		| Statement::Option(_)
+		| Statement::Rebuild(_)
+		| Statement::Remove(_)
+		| Statement::Savepoint(_)
+		| Statement::Sleep(_)
+		| Statement::Use(_) => true,
+		// Definitions may contain passwords and keys
+		_ => false,
+	}
+}
+
+fn select(v: &mut SelectStatement) -> bool {
+	fields(&mut v.expr)
+		&& values(&mut v.what.0)
+		&& cond(&mut v.cond)
+		&& cond(&mut v.having)
+		&& v.limit.as_mut().map_or(true, |v| value(&mut v.0))
+		&& v.start.as_mut().map_or(true, |v| value(&mut v.0))
+		&& v.cursor.as_mut().map_or(true, value)
+}
+
+fn create(v: &mut CreateStatement) -> bool {
+	values(&mut v.what.0) && data(v.data.as_mut()) && out(&mut v.output)
+}
+
+fn update(v: &mut UpdateStatement) -> bool {
+	values(&mut v.what.0) && data(v.data.as_mut()) && cond(&mut v.cond) && out(&mut v.output)
+}
+
+fn upsert(v: &mut UpsertStatement) -> bool {
+	values(&mut v.what.0) && data(v.data.as_mut()) && cond(&mut v.cond) && out(&mut v.output)
+}
+
+fn delete(v: &mut DeleteStatement) -> bool {
+	values(&mut v.what.0) && cond(&mut v.cond) && out(&mut v.output)
+}
+
+fn relate(v: &mut RelateStatement) -> bool {
+	value(&mut v.kind)
+		&& value(&mut v.from)
+		&& value(&mut v.with)
+		&& data(v.data.as_mut())
+		&& out(&mut v.output)
+}
+
+fn insert(v: &mut InsertStatement) -> bool {
+	v.into.as_mut().map_or(true, value)
+		&& data(Some(&mut v.data))
+		&& data(v.update.as_mut())
+		&& out(&mut v.output)
+}
+
+fn output(v: &mut OutputStatement) -> bool {
+	value(&mut v.what)
+}
+
+fn ifelse(v: &mut IfelseStatement) -> bool {
+	v.exprs.iter_mut().all(|(c, t)| value(c) && value(t))
+		&& v.close.as_mut().map_or(true, value)
+		&& v.lets.iter_mut().flatten().all(|v| value(&mut v.what))
+}
+
+fn foreach(v: &mut ForeachStatement) -> bool {
+	value(&mut v.range) && block(&mut v.block)
+}
+
+fn block(v: &mut Block) -> bool {
+	v.0.iter_mut().all(|v| match v {
+		Entry::Value(v) => value(v),
+		Entry::Set(v) => value(&mut v.what),
+		Entry::Ifelse(v) => ifelse(v),
+		Entry::Select(v) => select(v),
+		Entry::Create(v) => create(v),
+		Entry::Update(v) => update(v),
+		Entry::Upsert(v) => upsert(v),
+		Entry::Delete(v) => delete(v),
+		Entry::Relate(v) => relate(v),
+		Entry::Insert(v) => insert(v),
+		Entry::Output(v) => output(v),
+		Entry::Throw(v) => value(&mut v.error),
+		Entry::Foreach(v) => foreach(v),
+		Entry::Break(_) | Entry::Continue(_) | Entry::Remove(_) | Entry::Rebuild(_) => true,
+		Entry::Define(_) => false,
+	})
+}
+
+fn values(v: &mut [Value]) -> bool {
+	v.iter_mut().all(value)
+}
+
+fn cond(v: &mut Option<Cond>) -> bool {
+	v.as_mut().map_or(true, |v| value(&mut v.0))
+}
+
+fn out(v: &mut Option<Output>) -> bool {
+	match v {
+		Some(Output::Fields(v)) => fields(v),
+		_ => true,
+	}
+}
+
+fn data(v: Option<&mut Data>) -> bool {
+	match v {
+		Some(Data::SetExpression(v) | Data::UpdateExpression(v)) => {
+			v.iter_mut().all(|(i, _, v)| idiom(i) && value(v))
+		}
+		Some(Data::ValuesExpression(v)) => {
+			v.iter_mut().flatten().all(|(i, v)| idiom(i) && value(v))
+		}
+		Some(
+			Data::PatchExpression(v)
+			| Data::MergeExpression(v)
+			| Data::MergePatchExpression(v)
+			| Data::ReplaceExpression(v)
+			| Data::ContentExpression(v)
+			| Data::SingleExpression(v),
+		) => value(v),
+		Some(Data::UnsetExpression(v)) => v.iter_mut().all(idiom),
+		Some(Data::EmptyExpression) | None => true,
+	}
+}
+
+fn fields(v: &mut Fields) -> bool {
+	v.0.iter_mut().all(|v| match v {
+		Field::All => true,
+		Field::Single {
+			expr,
+			..
+		}
+		| Field::Window {
+			expr,
+			..
+		} => value(expr),
+		Field::Filter {
+			expr,
+			cond,
+			..
+		} => value(expr) && value(&mut cond.0),
+	})
+}
+
+fn idiom(v: &mut Idiom) -> bool {
+	v.0.iter_mut().all(part)
+}
+
+fn part(v: &mut Part) -> bool {
+	match v {
+		Part::Where(v) | Part::Value(v) | Part::Start(v) => value(v),
+		Part::Method(_, v) => values(v),
+		Part::Recurse(v, _) => v.iter_mut().all(part),
+		Part::Graph(v) => {
+			fields(&mut v.expr)
+				&& cond(&mut v.cond)
+				&& v.limit.as_mut().map_or(true, |v| value(&mut v.0))
+				&& v.start.as_mut().map_or(true, |v| value(&mut v.0))
+		}
+		_ => true,
+	}
+}
+
+fn value(v: &mut Value) -> bool {
+	match v {
+		Value::Strand(_)
+		| Value::Number(_)
+		| Value::Duration(_)
+		| Value::Datetime(_)
+		| Value::Uuid(_)
+		| Value::Bytes(_)
+		| Value::Geometry(_)
+		| Value::Regex(_) => {
+			*v = placeholder();
+			true
+		}
+		Value::Thing(v) => {
+			v.id = placeholder_id();
+			true
+		}
+		Value::Range(v) => {
+			for b in [&mut v.beg, &mut v.end] {
+				if let Bound::Included(id) | Bound::Excluded(id) = b {
+					*id = placeholder_id();
+				}
+			}
+			true
+		}
+		Value::Edges(v) => {
+			v.from.id = placeholder_id();
+			true
+		}
+		Value::Array(v) => values(&mut v.0),
+		Value::Object(v) => v.0.values_mut().all(value),
+		Value::Idiom(v) => idiom(v),
+		Value::Cast(v) => value(&mut v.1),
+		Value::Function(v) => match v.as_mut() {
+			Function::Normal(_, v) | Function::Custom(_, v) => values(v),
+			// The source of a script may contain literal values
+			Function::Script(..) => false,
+		},
+		Value::Model(v) => values(&mut v.args),
+		Value::Expression(v) => match v.as_mut() {
+			Expression::Unary {
+				v,
+				..
+			} => value(v),
+			Expression::Binary {
+				l,
+				r,
+				..
+			} => value(l) && value(r),
+		},
+		Value::Subquery(v) => match v.as_mut() {
+			Subquery::Value(v) => value(v),
+			Subquery::Ifelse(v) => ifelse(v),
+			Subquery::Output(v) => output(v),
+			Subquery::Select(v) => select(v),
+			Subquery::Create(v) => create(v),
+			Subquery::Update(v) => update(v),
+			Subquery::Upsert(v) => upsert(v),
+			Subquery::Delete(v) => delete(v),
+			Subquery::Relate(v) => relate(v),
+			Subquery::Insert(v) => insert(v),
+			Subquery::Case(v) => {
+				v.subject.as_mut().map_or(true, value)
+					&& v.exprs.iter_mut().all(|(w, t)| value(w) && value(t))
+					&& v.close.as_mut().map_or(true, value)
+			}
+			Subquery::Remove(_) | Subquery::Rebuild(_) => true,
+			Subquery::Define(_) => false,
+		},
+		Value::Block(v) => block(v),
+		Value::Future(v) => block(&mut v.0),
+		Value::Query(_) => false,
+		_ => true,
+	}
+}
+
+#[cfg(test)]
+mod tests {
+	use super::*;
+
+	fn redact(sql: &str) -> Option<String> {
+		let mut query = crate::syn::parse(sql).unwrap();
+		super::redact(&query.0 .0.remove(0))
+	}
+
+	#[test]
+	fn redacts_literals() {
+		let tests = [
+			(
+				"SELECT * FROM person WHERE name = 'Tobie' AND age > 30",
+				"SELECT * FROM person WHERE name = $? AND age > $?",
+			),
+			(
+				"CREATE person:tobie SET email = \"tobie@surrealdb.com\", score = 1.5f",
+				"CREATE person:⟨?⟩ SET email = $?, score = $?",
+			),
+			(
+				"SELECT * FROM person:⟨tobie morgan⟩, person:100, person:1..5",
+				"SELECT * FROM person:⟨?⟩, person:⟨?⟩, person:⟨?⟩..⟨?⟩",
+			),
+			(
+				"UPDATE person SET born = d'2020-01-01T00:00:00Z' WHERE ttl > 1h30m",
+				"UPDATE person SET born = $? WHERE ttl > $?",
+			),
+			("RETURN 'it\\'s' + $name", "RETURN $? + $name"),
+			("RETURN { name: 'Tobie', tags: ['a', 2] }", "RETURN { name: $?, tags: [$?, $?] }"),
+			(
+				"RETURN string::len(word) + math::sum([1, 2]) + table2.field",
+				"RETURN string::len(word) + math::sum([$?, $?]) + table2.field",
+			),
+			("SELECT * FROM `user:email` LIMIT 10", "SELECT * FROM `user:email` LIMIT $?"),
+			(
+				"IF $x > 5 { CREATE person CONTENT { pass: 'secret' } }",
+				"IF $x > $? { CREATE person CONTENT { pass: $? }; }",
+			),
+		];
+		for (sql, expected) in tests {
+			assert_eq!(redact(sql).as_deref(), Some(expected), "{sql}");
+		}
+	}
+
+	#[test]
+	fn does_not_log_unredactable_statements() {
+		let tests = [
+			"DEFINE USER tobie ON ROOT PASSWORD 'secret' ROLES OWNER",
+			"RETURN function() { return 'secret'; }",
+			"INFO FOR ROOT",
+		];
+		for sql in tests {
+			assert_eq!(redact(sql), None, "{sql}");
+		}
+	}
+
+	#[test]
+	fn describes_the_auth_scope() {
+		use crate::iam::Role;
+		assert_eq!(scope(&Auth::default()), "anonymous");
+		assert_eq!(scope(&Auth::for_root(Role::Owner)), "root");
+		assert_eq!(scope(&Auth::for_db(Role::Viewer, "ns", "db")), "/ns:ns/db:db/");
+		assert_eq!(
+			scope(&Auth::for_record("user:tobie".into(), "ns", "db", "ac")),
+			"/ns:ns/db:db/record/"
+		);
+	}
+}
@@ -14,6 +14,7 @@ use crate::sql::statements::delete::DeleteStatement;
 use crate::sql::statements::insert::InsertStatement;
 use crate::sql::statements::live::LiveStatement;
 use crate::sql::statements::relate::RelateStatement;
+use crate::sql::statements::restore::RestoreStatement;
 use crate::sql::statements::select::SelectStatement;
 use crate::sql::statements::show::ShowStatement;
 use crate::sql::statements::update::UpdateStatement;
@@ -21,6 +22,9 @@ use crate::sql::statements::upsert::UpsertStatement;
 use crate::sql::Explain;
 use std::fmt;
 
+/// The groups used when a HAVING clause is specified without a GROUP clause
+static GROUP_ALL: Groups = Groups(Vec::new());
+
 #[derive(Clone, Debug)]
 pub(crate) enum Statement<'a> {
 	Live(&'a LiveStatement),
@@ -32,6 +36,7 @@ pub(crate) enum Statement<'a> {
 	Relate(&'a RelateStatement),
 	Delete(&'a DeleteStatement),
 	Insert(&'a InsertStatement),
+	Restore(&'a RestoreStatement),
 }
 
 impl<'a> From<&'a LiveStatement> for Statement<'a> {
@@ -88,6 +93,12 @@ impl<'a> From<&'a InsertStatement> for Statement<'a> {
 	}
 }
 
+impl<'a> From<&'a RestoreStatement> for Statement<'a> {
+	fn from(v: &'a RestoreStatement) -> Self {
+		Statement::Restore(v)
+	}
+}
+
 impl<'a> fmt::Display for Statement<'a> {
 	fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {
 		match self {
@@ -100,6 +111,7 @@ impl<'a> fmt::Display for Statement<'a> {
 			Statement::Relate(v) => write!(f, "{v}"),
 			Statement::Delete(v) => write!(f, "{v}"),
 			Statement::Insert(v) => write!(f, "{v}"),
+			Statement::Restore(v) => write!(f, "{v}"),
 		}
 	}
 }
@@ -153,6 +165,7 @@ impl<'a> Statement<'a> {
 			Statement::Upsert(v) => v.cond.as_ref(),
 			Statement::Update(v) => v.cond.as_ref(),
 			Statement::Delete(v) => v.cond.as_ref(),
+			Statement::Restore(v) => v.cond.as_ref(),
 			_ => None,
 		}
 	}
@@ -168,10 +181,28 @@ impl<'a> Statement<'a> {
 	#[inline]
 	pub fn group(&self) -> Option<&Groups> {
 		match self {
+			// A HAVING clause without a GROUP clause treats the whole result as one group
+			Statement::Select(v) if v.group.is_none() && v.having.is_some() => Some(&GROUP_ALL),
 			Statement::Select(v) => v.group.as_ref(),
 			_ => None,
 		}
 	}
+	/// Returns any HAVING clause if specified
+	#[inline]
+	pub fn having(&self) -> Option<&Cond> {
+		match self {
+			Statement::Select(v) => v.having.as_ref(),
+			_ => None,
+		}
+	}
+	/// Returns any DISTINCT ON clause if specified
+	#[inline]
+	pub fn distinct(&self) -> Option<&Idioms> {
+		match self {
+			Statement::Select(v) => v.distinct.as_ref(),
+			_ => None,
+		}
+	}
 	/// Returns any ORDER clause if specified
 	#[inline]
 	pub fn order(&self) -> Option<&Orders> {
@@ -214,6 +245,7 @@ impl<'a> Statement<'a> {
 			Statement::Relate(v) => v.output.as_ref(),
 			Statement::Delete(v) => v.output.as_ref(),
 			Statement::Insert(v) => v.output.as_ref(),
+			Statement::Restore(v) => v.output.as_ref(),
 			_ => None,
 		}
 	}
@@ -229,6 +261,7 @@ impl<'a> Statement<'a> {
 			Statement::Relate(v) => v.parallel,
 			Statement::Delete(v) => v.parallel,
 			Statement::Insert(v) => v.parallel,
+			Statement::Restore(v) => v.parallel,
 			_ => false,
 		}
 	}
@@ -257,4 +290,14 @@ impl<'a> Statement<'a> {
 			_ => None,
 		}
 	}
+
+	/// Returns whether soft-deleted records are processed
+	#[inline]
+	pub fn deleted(&self) -> bool {
+		match self {
+			Statement::Select(v) => v.deleted,
+			Statement::Restore(_) => true,
+			_ => false,
+		}
+	}
 }
@@ -0,0 +1,46 @@
+use crate::sql::value::Value;
+use channel::Sender;
+
+/// A batch of records which has been produced by a streamed SELECT statement
+#[derive(Debug)]
+#[non_exhaustive]
+pub struct Batch {
+	/// The position of the statement response within the query responses
+	pub query: usize,
+	/// The records which were produced in this batch
+	pub values: Vec<Value>,
+}
+
+/// Sends the records of top-level SELECT statements in batches as they are
+/// produced, instead of collecting every record in memory before responding.
+///
+/// A SELECT statement is only streamed if it runs in its own transaction, and
+/// has no clauses which need the full result set, such as ORDER, GROUP, SPLIT,
+/// START, or FETCH. The response for a streamed statement has a result of NONE.
+#[derive(Clone, Debug)]
+#[non_exhaustive]
+pub struct ResultStream {
+	pub(crate) sender: Sender<Batch>,
+	pub(crate) batch_size: usize,
+	pub(crate) query: usize,
+}
+
+impl ResultStream {
+	/// Creates a stream which sends batches of up to `batch_size` records
+	pub fn new(sender: Sender<Batch>, batch_size: usize) -> Self {
+		Self {
+			sender,
+			batch_size: batch_size.max(1),
+			query: 0,
+		}
+	}
+
+	/// Sends a batch of records, returning false if the receiver has been dropped
+	pub(crate) async fn send(&self, values: Vec<Value>) -> bool {
+		let batch = Batch {
+			query: self.query,
+			values,
+		};
+		self.sender.send(batch).await.is_ok()
+	}
+}
@@ -0,0 +1,82 @@
+use crate::ctx::Context;
+use crate::dbs::group::Aggregator;
+use crate::dbs::Options;
+use crate::err::Error;
+use crate::sql::function::OptimisedAggregate;
+use crate::sql::{Array, Field, Fields, Value};
+use reblessive::tree::Stk;
+use std::borrow::Cow;
+
+/// Compute the window aggregates in the selected fields.
+///
+/// Each row holds the argument of the aggregate function, which was
+/// computed when the row was selected, and this is replaced with the
+/// aggregate of the argument over all of the rows. With an ORDER BY
+/// in the window, each row is given the aggregate of the rows up to
+/// and including that row, with rows sorted in that order. Only the
+/// aggregates which can be computed incrementally can be ordered, so
+/// that the running aggregate is updated once for each row.
+pub(super) async fn compute(
+	stk: &mut Stk,
+	ctx: &Context<'_>,
+	opt: &Options,
+	fields: &Fields,
+	rows: &mut [Value],
+) -> Result<(), Error> {
+	for field in fields.iter() {
+		if let Field::Window {
+			expr,
+			order,
+			alias,
+		} = field
+		{
+			// Only aggregate functions are parsed as windows
+			let Value::Function(f) = expr else {
+				continue;
+			};
+			let idiom =
+				alias.as_ref().map(Cow::Borrowed).unwrap_or_else(|| Cow::Owned(expr.to_idiom()));
+			// Get the aggregated value from each row
+			let vals: Vec<Value> = match fields.single() {
+				Some(_) => rows.to_vec(),
+				None => rows.iter().map(|v| v.pick(idiom.as_ref())).collect(),
+			};
+			// Compute the aggregate for each row
+			let results = match order {
+				None => {
+					let x = f
+						.aggregate(Value::from(Array::from(vals)))
+						.compute(stk, ctx, opt, None)
+						.await?;
+					vec![x; rows.len()]
+				}
+				Some(orders) => {
+					// Sort the rows, keeping rows which are equal in their current order
+					let mut index: Vec<usize> = (0..rows.len()).collect();
+					index.sort_by(|&a, &b| orders.compare(&rows[a], &rows[b]));
+					// The parser only allows ordered windows of rolling aggregates
+					let a = f.get_optimised_aggregate();
+					if matches!(a, OptimisedAggregate::None) {
+						return Err(Error::Unreachable("ordered window aggregate"));
+					}
+					let mut agr = Aggregator::default();
+					agr.prepare(expr);
+					let mut results = vec![Value::None; rows.len()];
+					for i in index {
+						agr.push(stk, ctx, opt, vals[i].clone()).await?;
+						results[i] = agr.current(&a)?;
+					}
+					results
+				}
+			};
+			// Set the aggregate on each row
+			for (row, x) in rows.iter_mut().zip(results) {
+				match fields.single() {
+					Some(_) => *row = x,
+					None => row.set(stk, ctx, opt, idiom.as_ref(), x).await?,
+				}
+			}
+		}
+	}
+	Ok(())
+}
@@ -32,6 +32,10 @@ impl<'a> Document<'a> {
 					let data = data.compute(stk, ctx, opt, Some(&self.current)).await?;
 					self.current.doc.to_mut().merge(data)?
 				}
+				Data::MergePatchExpression(data) => {
+					let data = data.compute(stk, ctx, opt, Some(&self.current)).await?;
+					self.current.doc.to_mut().merge_patch(data)?
+				}
 				Data::ReplaceExpression(data) => {
 					let data = data.compute(stk, ctx, opt, Some(&self.current)).await?;
 					self.current.doc.to_mut().replace(data)?
@@ -4,6 +4,9 @@ use crate::dbs::Statement;
 use crate::doc::{CursorDoc, Document};
 use crate::err::Error;
 use crate::sql::Cond;
+use crate::sql::Expression;
+use crate::sql::Operator;
+use crate::sql::Value;
 use reblessive::tree::Stk;
 
 impl<'a> Document<'a> {
@@ -14,6 +17,9 @@ impl<'a> Document<'a> {
 		opt: &Options,
 		stm: &Statement<'_>,
 	) -> Result<(), Error> {
+		// Check the expected record version
+		self.check_version(stk, ctx, opt, stm).await?;
+		// Check the where condition
 		Self::check_cond(stk, ctx, opt, stm.conds(), &self.current).await
 	}
 
@@ -35,4 +41,82 @@ impl<'a> Document<'a> {
 		// Carry on
 		Ok(())
 	}
+
+	/// An update of a single record in a versioned table whose WHERE clause
+	/// matches on `version = ...` is a conditional write, which fails instead
+	/// of skipping the record if it has since moved on to another version.
+	/// Updates of many records skip the records which are at another version.
+	async fn check_version(
+		&self,
+		stk: &mut Stk,
+		ctx: &Context<'_>,
+		opt: &Options,
+		stm: &Statement<'_>,
+	) -> Result<(), Error> {
+		// Only updates of existing records are checked
+		if !matches!(stm, Statement::Update(_) | Statement::Upsert(_)) || self.initial.doc.is_none()
+		{
+			return Ok(());
+		}
+		// Check if the statement expects a version
+		let Some(expected) = stm.conds().and_then(|cond| expected_version(&cond.0)) else {
+			return Ok(());
+		};
+		// Check if the statement targets a single record
+		let what = match stm {
+			Statement::Update(v) => &v.what,
+			Statement::Upsert(v) => &v.what,
+			_ => return Ok(()),
+		};
+		let single = match what.0.as_slice() {
+			[Value::Thing(_)] => true,
+			[v @ Value::Param(_)] => v.compute(stk, ctx, opt, None).await?.is_thing(),
+			_ => false,
+		};
+		if !single {
+			return Ok(());
+		}
+		// Check if the table is versioned
+		if !self.tb(ctx, opt).await?.versioned {
+			return Ok(());
+		}
+		// Compare the expected and actual versions
+		let expected = expected.compute(stk, ctx, opt, Some(&self.initial)).await?;
+		let actual = self.version();
+		if !expected.equal(&Value::from(actual)) {
+			return Err(Error::RecordVersionConflict {
+				thing: self.id.as_ref().unwrap().to_string(),
+				expected: expected.to_string(),
+				actual,
+			});
+		}
+		// Carry on
+		Ok(())
+	}
+}
+
+/// Find the value compared with the `version` field in a condition,
+/// looking through any conditions which are joined with AND
+fn expected_version(v: &Value) -> Option<&Value> {
+	match v {
+		Value::Expression(e) => match e.as_ref() {
+			Expression::Binary {
+				l: Value::Idiom(i),
+				o: Operator::Equal,
+				r,
+			} if i.is_version() => Some(r),
+			Expression::Binary {
+				l,
+				o: Operator::Equal,
+				r: Value::Idiom(i),
+			} if i.is_version() => Some(l),
+			Expression::Binary {
+				l,
+				o: Operator::And,
+				r,
+			} => expected_version(l).or_else(|| expected_version(r)),
+			_ => None,
+		},
+		_ => None,
+	}
 }
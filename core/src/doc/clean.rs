@@ -46,6 +46,8 @@ impl<'a> Document<'a> {
 						fd if fd.is_in() => continue,
 						fd if fd.is_out() => continue,
 						fd if fd.is_meta() => continue,
+						fd if fd.is_version() && tb.versioned => continue,
+						fd if fd.is_deleted() => continue,
 						fd => self.current.doc.to_mut().del(stk, ctx, opt, fd).await?,
 					}
 				}
@@ -3,7 +3,9 @@ use crate::dbs::Options;
 use crate::dbs::Statement;
 use crate::doc::Document;
 use crate::err::Error;
+use crate::sql::paths::DELETED;
 use crate::sql::value::Value;
+use crate::sql::Datetime;
 use reblessive::tree::Stk;
 
 impl<'a> Document<'a> {
@@ -18,6 +20,10 @@ impl<'a> Document<'a> {
 		self.check(stk, ctx, opt, stm).await?;
 		// Check if allowed
 		self.allow(stk, ctx, opt, stm).await?;
+		// This table uses soft deletes
+		if self.tb(ctx, opt).await?.soft_delete {
+			return self.soft_delete(stk, ctx, opt, stm).await;
+		}
 		// Erase document
 		self.erase(ctx, opt, stm).await?;
 		// Purge index data
@@ -35,4 +41,37 @@ impl<'a> Document<'a> {
 		// Yield document
 		self.pluck(stk, ctx, opt, stm).await
 	}
+	/// Mark the record as deleted, instead of removing it. The record is
+	/// removed from any indexes, but keeps its graph edges, so that it can
+	/// be restored.
+	async fn soft_delete(
+		&mut self,
+		stk: &mut Stk,
+		ctx: &Context<'_>,
+		opt: &Options,
+		stm: &Statement<'_>,
+	) -> Result<Value, Error> {
+		// The record does not exist, or has already been deleted
+		if self.current.doc.is_none() {
+			return Err(Error::Ignore);
+		}
+		// Mark the record as deleted
+		self.current.doc.to_mut().put(&*DELETED, Datetime::default().into());
+		// Reset fields data
+		self.reset(ctx, opt, stm).await?;
+		// Store record data
+		self.store(ctx, opt, stm).await?;
+		// Store index data
+		self.index(stk, ctx, opt, stm).await?;
+		// Run table queries
+		self.table(stk, ctx, opt, stm).await?;
+		// Run lives queries
+		self.lives(stk, ctx, opt, stm).await?;
+		// Run change feeds queries
+		self.changefeeds(ctx, opt, stm).await?;
+		// Run event queries
+		self.event(stk, ctx, opt, stm).await?;
+		// Yield document
+		self.pluck(stk, ctx, opt, stm).await
+	}
 }
@@ -1,10 +1,12 @@
 use crate::ctx::Context;
 use crate::dbs::Options;
+use crate::dbs::Statement;
 use crate::dbs::Workable;
 use crate::err::Error;
 use crate::iam::Action;
 use crate::iam::ResourceKind;
 use crate::idx::planner::iterators::IteratorRecord;
+use crate::sql::paths::{DELETED, VERSION};
 use crate::sql::statements::define::DefineEventStatement;
 use crate::sql::statements::define::DefineFieldStatement;
 use crate::sql::statements::define::DefineIndexStatement;
@@ -46,6 +48,13 @@ impl<'a> CursorDoc<'a> {
 	}
 }
 
+impl<'a> CursorDoc<'a> {
+	/// Check if this record has been marked as deleted
+	pub(crate) fn is_soft_deleted(&self) -> bool {
+		self.doc.pick(&*DELETED).is_some()
+	}
+}
+
 impl<'a> From<&'a Value> for CursorDoc<'a> {
 	fn from(doc: &'a Value) -> Self {
 		Self {
@@ -112,6 +121,15 @@ impl<'a> Document<'a> {
 		}
 	}
 
+	/// Treat a soft-deleted record as if it does not exist, unless the
+	/// statement works with soft-deleted records
+	pub(crate) fn hide_soft_deleted(&mut self, stm: &Statement<'_>) {
+		if self.initial.is_soft_deleted() && !stm.deleted() {
+			self.initial.doc = Cow::Owned(Value::None);
+			self.current.doc = Cow::Owned(Value::None);
+		}
+	}
+
 	/// Get the current document, as it is being modified
 	#[allow(unused)]
 	pub(crate) fn current_doc(&self) -> &Value {
@@ -131,14 +149,25 @@ impl<'a> Document<'a> {
 		self.initial.doc != self.current.doc
 	}
 
-	/// Check if document is being created
+	/// Check if document is being created, or restored after being soft-deleted
 	pub fn is_new(&self) -> bool {
-		self.initial.doc.is_none() && self.current.doc.is_some()
+		(self.initial.doc.is_none() || self.initial.is_soft_deleted())
+			&& self.current.doc.is_some()
+			&& !self.current.is_soft_deleted()
+	}
+
+	/// Get the version of the record before this write,
+	/// which is zero if it hasn't been written to yet
+	pub fn version(&self) -> i64 {
+		match self.initial.doc.pick(&*VERSION) {
+			Value::Number(v) => v.as_int(),
+			_ => 0,
+		}
 	}
 
-	/// Check if document is being deleted
+	/// Check if document is being deleted, or soft-deleted
 	pub fn is_delete(&self) -> bool {
-		self.current.doc.is_none()
+		self.current.doc.is_none() || self.current.is_soft_deleted()
 	}
 
 	/// Get the table for this document
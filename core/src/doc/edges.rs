@@ -41,6 +41,9 @@ impl<'a> Document<'a> {
 			// Store the right pointer edge
 			let key = crate::key::graph::new(opt.ns()?, opt.db()?, &r.tb, &r.id, i, rid);
 			run.set(key, vec![]).await?;
+			// Store new versions of the connected tables
+			run.changed(opt.ns()?, opt.db()?, &l.tb).await?;
+			run.changed(opt.ns()?, opt.db()?, &r.tb).await?;
 			// Store the edges on the record
 			self.current.doc.to_mut().put(&*EDGE, Value::Bool(true));
 			self.current.doc.to_mut().put(&*IN, l.clone().into());
@@ -118,6 +118,19 @@ impl<'a> Document<'a> {
 					ctx.add_value("before", &old);
 					// Process the ASSERT clause
 					if !expr.compute(stk, &ctx, opt, Some(&self.current)).await?.is_truthy() {
+						// Check for a custom ASSERT message
+						if let Some(msg) = &fd.assert_message {
+							let field = Value::from(k.to_string());
+							let mut ctx = Context::new(&ctx);
+							ctx.add_value("field", &field);
+							// Process the ASSERT message
+							let msg = msg.compute(stk, &ctx, opt, Some(&self.current)).await?;
+							return Err(Error::FieldAssert {
+								thing: rid.to_string(),
+								field: fd.name.clone(),
+								message: msg.as_raw_string(),
+							});
+						}
 						return Err(Error::FieldValue {
 							thing: rid.to_string(),
 							field: fd.name.clone(),
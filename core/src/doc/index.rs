@@ -80,7 +80,8 @@ async fn build_opt_values(
 	ix: &DefineIndexStatement,
 	doc: &CursorDoc<'_>,
 ) -> Result<Option<Vec<Value>>, Error> {
-	if !doc.doc.is_some() {
+	// Soft-deleted records are not indexed
+	if !doc.doc.is_some() || doc.is_soft_deleted() {
 		return Ok(None);
 	}
 	let mut o = Vec::with_capacity(ix.cols.len());
@@ -8,9 +8,9 @@ use crate::doc::Document;
 use crate::err::Error;
 use crate::fflags::FFLAGS;
 use crate::sql::paths::AC;
-use crate::sql::paths::META;
 use crate::sql::paths::RD;
 use crate::sql::paths::TK;
+use crate::sql::paths::{DELETED, META};
 use crate::sql::permission::Permission;
 use crate::sql::statements::LiveStatement;
 use crate::sql::Value;
@@ -175,14 +175,31 @@ impl<'a> Document<'a> {
 			// First of all, let's check to see if the WHERE
 			// clause of the LIVE query is matched by this
 			// document. If it is then we can continue.
-			match self.lq_check(stk, &lqctx, &lqopt, &lq, doc).await {
-				Err(Error::Ignore) => {
-					trace!("live query did not match the where clause, skipping");
-					continue;
-				}
+			let matched = match self.lq_check(stk, &lqctx, &lqopt, &lq, doc).await {
+				Err(Error::Ignore) => false,
 				Err(e) => return Err(e),
-				Ok(_) => (),
+				Ok(_) => true,
+			};
+			// An UPDATE can move a document into or out of
+			// the WHERE clause of the LIVE query, in which
+			// case the subscriber sees a CREATE or DELETE.
+			let before = match !is_delete && !self.is_new() {
+				true => match self.lq_check(stk, &lqctx, &lqopt, &lq, &self.initial).await {
+					Err(Error::Ignore) => false,
+					Err(e) => return Err(e),
+					Ok(_) => true,
+				},
+				false => matched,
+			};
+			let (entered, left) = (matched && !before, before && !matched);
+			if !matched && !left {
+				trace!("live query did not match the where clause, skipping");
+				continue;
 			}
+			let doc = match left {
+				true => &self.initial,
+				false => doc,
+			};
 			// Secondly, let's check to see if any PERMISSIONS
 			// clause for this table allows this document to
 			// be viewed by the user who created this LIVE
@@ -211,7 +228,7 @@ impl<'a> Document<'a> {
 				node_id,
 				lv.node.0
 			);
-			if is_delete {
+			if is_delete || left {
 				// Send a DELETE notification
 				if node_matches_live_query {
 					sender
@@ -231,13 +248,14 @@ impl<'a> Document<'a> {
 								}
 								// Remove metadata fields on output
 								value.del(stk, &lqctx, lqopt, &*META).await?;
+								value.del(stk, &lqctx, lqopt, &*DELETED).await?;
 								// Output result
 								value
 							},
 						})
 						.await?;
 				}
-			} else if self.is_new() {
+			} else if self.is_new() || entered {
 				// Send a CREATE notification
 				if node_matches_live_query {
 					trace!("Sending lq create notification");
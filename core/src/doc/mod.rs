@@ -16,6 +16,7 @@ mod create; // Processes a CREATE statement for this document
 mod delete; // Processes a DELETE statement for this document
 mod insert; // Processes a INSERT statement for this document
 mod relate; // Processes a RELATE statement for this document
+mod restore; // Processes a RESTORE statement for this document
 mod select; // Processes a SELECT statement for this document
 mod update; // Processes a UPDATE statement for this document
 mod upsert; // Processes a UPSERT statement for this document
@@ -6,7 +6,7 @@ use crate::err::Error;
 use crate::iam::Action;
 use crate::sql::idiom::Idiom;
 use crate::sql::output::Output;
-use crate::sql::paths::META;
+use crate::sql::paths::{DELETED, META};
 use crate::sql::permission::Permission;
 use crate::sql::value::Value;
 use reblessive::tree::Stk;
@@ -73,6 +73,9 @@ impl<'a> Document<'a> {
 				Statement::Insert(_) => {
 					self.current.doc.compute(stk, ctx, opt, Some(&self.current)).await
 				}
+				Statement::Restore(_) => {
+					self.current.doc.compute(stk, ctx, opt, Some(&self.current)).await
+				}
 				_ => Err(Error::Ignore),
 			},
 		}?;
@@ -118,6 +121,7 @@ impl<'a> Document<'a> {
 		}
 		// Remove metadata fields on output
 		out.cut(&*META);
+		out.cut(&*DELETED);
 		// Output result
 		Ok(out)
 	}
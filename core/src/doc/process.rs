@@ -27,6 +27,8 @@ impl<'a> Document<'a> {
 			};
 			// Setup a new document
 			let mut doc = Document::new(pro.rid.as_ref(), pro.ir.as_ref(), &ins.0, ins.1);
+			// Hide any soft-deleted record
+			doc.hide_soft_deleted(stm);
 			// Process the statement
 			let res = match stm {
 				Statement::Select(_) => doc.select(stk, ctx, opt, stm).await,
@@ -36,6 +38,7 @@ impl<'a> Document<'a> {
 				Statement::Relate(_) => doc.relate(stk, ctx, opt, stm).await,
 				Statement::Delete(_) => doc.delete(stk, ctx, opt, stm).await,
 				Statement::Insert(_) => doc.insert(stk, ctx, opt, stm).await,
+				Statement::Restore(_) => doc.restore(stk, ctx, opt, stm).await,
 				_ => unreachable!(),
 			};
 			// Check the result
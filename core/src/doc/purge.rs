@@ -32,6 +32,8 @@ impl<'a> Document<'a> {
 			// Purge the record data
 			let key = crate::key::thing::new(opt.ns()?, opt.db()?, &rid.tb, &rid.id);
 			run.del(key).await?;
+			// Store a new version of the table
+			run.changed(opt.ns()?, opt.db()?, &rid.tb).await?;
 			// Purge the record edges
 			match (
 				self.initial.doc.pick(&*EDGE),
@@ -53,6 +55,9 @@ impl<'a> Document<'a> {
 					// Purge the right pointer edge
 					let key = crate::key::graph::new(opt.ns()?, opt.db()?, &r.tb, &r.id, i, rid);
 					run.del(key).await?;
+					// Store new versions of the connected tables
+					run.changed(opt.ns()?, opt.db()?, &l.tb).await?;
+					run.changed(opt.ns()?, opt.db()?, &r.tb).await?;
 				}
 				_ => {
 					// Release the transaction
@@ -7,13 +7,14 @@ use crate::err::Error;
 use crate::sql::paths::EDGE;
 use crate::sql::paths::IN;
 use crate::sql::paths::OUT;
+use crate::sql::paths::VERSION;
 use crate::sql::value::Value;
 
 impl<'a> Document<'a> {
 	pub async fn reset(
 		&mut self,
-		_ctx: &Context<'_>,
-		_opt: &Options,
+		ctx: &Context<'_>,
+		opt: &Options,
 		_stm: &Statement<'_>,
 	) -> Result<(), Error> {
 		// Get the record id
@@ -32,6 +33,11 @@ impl<'a> Document<'a> {
 			self.current.doc.to_mut().put(&*IN, self.initial.doc.pick(&*IN));
 			self.current.doc.to_mut().put(&*OUT, self.initial.doc.pick(&*OUT));
 		}
+		// This table is versioned, so move on to the next version
+		if self.tb(ctx, opt).await?.versioned {
+			let version = self.version() + 1;
+			self.current.doc.to_mut().put(&*VERSION, version.into());
+		}
 		// Carry on
 		Ok(())
 	}
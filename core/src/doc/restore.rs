@@ -0,0 +1,45 @@
+use crate::ctx::Context;
+use crate::dbs::Options;
+use crate::dbs::Statement;
+use crate::doc::Document;
+use crate::err::Error;
+use crate::sql::paths::DELETED;
+use crate::sql::value::Value;
+use reblessive::tree::Stk;
+
+impl<'a> Document<'a> {
+	pub async fn restore(
+		&mut self,
+		stk: &mut Stk,
+		ctx: &Context<'_>,
+		opt: &Options,
+		stm: &Statement<'_>,
+	) -> Result<Value, Error> {
+		// Check if record exists
+		self.empty(ctx, opt, stm).await?;
+		// Only soft-deleted records are restored
+		if !self.initial.is_soft_deleted() {
+			return Err(Error::Ignore);
+		}
+		// Check where clause
+		self.check(stk, ctx, opt, stm).await?;
+		// Clear the deletion marker
+		self.current.doc.to_mut().cut(&*DELETED);
+		// Check if allowed
+		self.allow(stk, ctx, opt, stm).await?;
+		// Store record data
+		self.store(ctx, opt, stm).await?;
+		// Store index data
+		self.index(stk, ctx, opt, stm).await?;
+		// Run table queries
+		self.table(stk, ctx, opt, stm).await?;
+		// Run lives queries
+		self.lives(stk, ctx, opt, stm).await?;
+		// Run change feeds queries
+		self.changefeeds(ctx, opt, stm).await?;
+		// Run event queries
+		self.event(stk, ctx, opt, stm).await?;
+		// Yield document
+		self.pluck(stk, ctx, opt, stm).await
+	}
+}
@@ -4,6 +4,8 @@ use crate::dbs::Statement;
 use crate::doc::Document;
 use crate::err::Error;
 use crate::key::key_req::KeyRequirements;
+use crate::sql::paths::DELETED;
+use crate::sql::Value;
 
 impl<'a> Document<'a> {
 	pub async fn store(
@@ -29,11 +31,16 @@ impl<'a> Document<'a> {
 		//
 		match stm {
 			// This is a CREATE statement so try to insert the key
-			Statement::Create(_) => match run.put(key.key_category(), key, self).await {
-				// The key already exists, so return an error
-				Err(Error::TxKeyAlreadyExistsCategory(_)) => Err(Error::RecordExists {
-					thing: rid.to_string(),
-				}),
+			Statement::Create(_) => match run.put(key.key_category(), key.clone(), self).await {
+				// The key already exists, so check if it is soft-deleted
+				Err(Error::TxKeyAlreadyExistsCategory(_)) => match run.get(key.clone()).await? {
+					// A soft-deleted record is replaced
+					Some(v) if Value::from(v).pick(&*DELETED).is_some() => run.set(key, self).await,
+					// The key already exists, so return an error
+					_ => Err(Error::RecordExists {
+						thing: rid.to_string(),
+					}),
+				},
 				// Return any other received error
 				Err(e) => Err(e),
 				// Record creation worked fine
@@ -42,6 +49,8 @@ impl<'a> Document<'a> {
 			// This is not a CREATE statement, so update the key
 			_ => run.set(key, self).await,
 		}?;
+		// Store a new version of the table
+		run.changed(opt.ns()?, opt.db()?, &rid.tb).await?;
 		// Carry on
 		Ok(())
 	}
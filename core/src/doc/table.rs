@@ -465,6 +465,8 @@ impl<'a> Document<'a> {
 							val,
 						)],
 						close: Some(Value::Idiom(key)),
+						parallel: false,
+						lets: Vec::new(),
 					}))),
 				));
 				set_ops.push((key_c, Operator::Inc, Value::from(1)))
@@ -512,6 +514,8 @@ impl<'a> Document<'a> {
 							val,
 						)],
 						close: Some(Value::Idiom(key)),
+						parallel: false,
+						lets: Vec::new(),
 					}))),
 				));
 				set_ops.push((key_c, Operator::Inc, Value::from(1)))
@@ -601,6 +605,8 @@ impl<'a> Document<'a> {
 				compute_query,
 			)],
 			close: Some(Value::Idiom(key.clone())),
+			parallel: false,
+			lets: Vec::new(),
 		})))
 	}
 
@@ -90,6 +90,20 @@ pub enum Error {
 	#[error("Value being checked was not correct")]
 	TxConditionNotMet,
 
+	/// The transaction conflicted with another transaction, and can be retried
+	#[error("Failed to commit transaction due to a read or write conflict. This transaction can be retried")]
+	TxRetryable,
+
+	/// A savepoint statement was run outside of a transaction
+	#[error("Savepoints can only be used within a transaction")]
+	TxNoSavepoints,
+
+	/// The requested savepoint has not been defined in the transaction
+	#[error("The savepoint '{name}' does not exist")]
+	SavepointNotFound {
+		name: String,
+	},
+
 	/// The key being inserted in the transaction already exists
 	#[error("The key being inserted already exists")]
 	#[deprecated(note = "Use TxKeyAlreadyExistsCategory")]
@@ -162,6 +176,29 @@ pub enum Error {
 		name: String,
 	},
 
+	/// A parameter was declared with a type, but no value was bound to it
+	#[error(
+		"Expected a value of type '{kind}' for the parameter '${name}', but no value was provided"
+	)]
+	MissingParam {
+		name: String,
+		kind: String,
+	},
+
+	/// The value of a parameter could not be coerced to the declared type
+	#[error("Expected a value of type '{kind}' for the parameter '${name}', but found {value}")]
+	InvalidParamType {
+		name: String,
+		kind: String,
+		value: String,
+	},
+
+	/// A parameter was referenced which has not been set
+	#[error("Found unknown parameter '${name}'")]
+	UnknownParam {
+		name: String,
+	},
+
 	#[error("Found '{field}' in SELECT clause on line {line}, but field is not an aggregate function, and is not present in GROUP BY expression")]
 	InvalidField {
 		line: usize,
@@ -186,6 +223,13 @@ pub enum Error {
 		field: String,
 	},
 
+	/// A line in an import file could not be parsed
+	#[error("Unable to import line {line}: {message}")]
+	InvalidImport {
+		line: usize,
+		message: String,
+	},
+
 	/// The LIMIT clause must evaluate to a positive integer
 	#[error("Found {value} but the LIMIT clause must evaluate to a positive integer")]
 	InvalidLimit {
@@ -198,6 +242,18 @@ pub enum Error {
 		value: String,
 	},
 
+	/// The START clause contained a cursor which could not be verified
+	#[error(
+		"Found '{value}' for the START clause, but the cursor is invalid or has been tampered with"
+	)]
+	InvalidCursor {
+		value: String,
+	},
+
+	/// A START cursor was used with a query which can not be paginated by record id
+	#[error("A START cursor can only be used when selecting records with an id from a single table, without ORDER, GROUP, SPLIT, DISTINCT, or HAVING clauses")]
+	CursorUnsupported,
+
 	/// There was an error with the provided JavaScript code
 	#[error("Problem with embedded script function. {message}")]
 	InvalidScript {
@@ -370,6 +426,12 @@ pub enum Error {
 		value: String,
 	},
 
+	/// The requested task does not exist
+	#[error("The task '{value}' does not exist")]
+	TkNotFound {
+		value: String,
+	},
+
 	/// The requested table does not exist
 	#[error("The table '{value}' does not exist")]
 	TbNotFound {
@@ -394,6 +456,14 @@ pub enum Error {
 		value: String,
 	},
 
+	/// The analyzer can not be removed while an index uses it
+	#[error("The analyzer '{value}' is used by the index '{index}' on the table '{table}'")]
+	AzInUse {
+		value: String,
+		table: String,
+		index: String,
+	},
+
 	/// The requested analyzer does not exist
 	#[error("The index '{value}' does not exist")]
 	IxNotFound {
@@ -432,12 +502,41 @@ pub enum Error {
 	#[error("Reached excessive computation depth due to functions, subqueries, or futures")]
 	ComputationDepthExceeded,
 
+	/// Reached the maximum number of rows which a statement can return
+	#[error("Reached the maximum of {limit} rows which can be returned by a statement")]
+	ResultRowsExceeded {
+		limit: usize,
+	},
+
+	/// Reached the maximum number of records which a statement can modify
+	#[error("Reached the maximum of {limit} records which can be modified by a statement")]
+	MutatedRecordsExceeded {
+		limit: usize,
+	},
+
 	/// Can not execute statement using the specified value
 	#[error("Can not execute statement using value '{value}'")]
 	InvalidStatementTarget {
 		value: String,
 	},
 
+	/// There was an error when evaluating the condition of an IF statement branch
+	#[error("Failed to evaluate IF condition {branch} `{cond}`: {source}")]
+	IfelseCondition {
+		branch: usize,
+		cond: String,
+		#[source]
+		source: Box<Error>,
+	},
+
+	/// The condition of an IF statement branch did not evaluate to a boolean
+	#[error("Expected IF condition {branch} `{cond}` to be a boolean, but found '{value}'")]
+	IfelseConditionNotBool {
+		branch: usize,
+		cond: String,
+		value: Value,
+	},
+
 	/// Can not execute CREATE statement using the specified value
 	#[error("Can not execute CREATE statement using value '{value}'")]
 	CreateStatement {
@@ -556,6 +655,16 @@ pub enum Error {
 		thing: String,
 	},
 
+	/// The specified record was written since the expected version was read
+	#[error(
+		"Database record `{thing}` is at version {actual}, but version {expected} was expected"
+	)]
+	RecordVersionConflict {
+		thing: String,
+		expected: String,
+		actual: i64,
+	},
+
 	/// A database index entry for the specified record already exists
 	#[error("Database index `{index}` already contains {value}, with record `{thing}`")]
 	IndexExists {
@@ -590,6 +699,13 @@ pub enum Error {
 		check: String,
 	},
 
+	#[error("{message}")]
+	FieldAssert {
+		thing: String,
+		field: Idiom,
+		message: String,
+	},
+
 	/// The specified field did not conform to the field ASSERT clause
 	#[error(
 		"Found changed value for field `{field}`, with record `{thing}`, but field is readonly"
@@ -854,6 +970,12 @@ pub enum Error {
 		value: String,
 	},
 
+	/// The VALUE clause of the requested field depends on itself
+	#[error("The field '{value}' has a VALUE clause which depends on itself through other fields")]
+	FdCircularDependency {
+		value: String,
+	},
+
 	/// The requested function already exists
 	#[error("The function 'fn::{value}' already exists")]
 	FcAlreadyExists {
@@ -884,6 +1006,12 @@ pub enum Error {
 		value: String,
 	},
 
+	/// The requested task already exists
+	#[error("The task '{value}' already exists")]
+	TkAlreadyExists {
+		value: String,
+	},
+
 	/// The requested table already exists
 	#[error("The table '{value}' already exists")]
 	TbAlreadyExists {
@@ -929,6 +1057,10 @@ pub enum Error {
 	#[error("The session has expired")]
 	ExpiredSession,
 
+	/// The token used to establish the session has been revoked
+	#[error("The token has been revoked")]
+	RevokedToken,
+
 	/// A node task has failed
 	#[error("A node task has failed: {0}")]
 	NodeAgent(&'static str),
@@ -1071,6 +1203,7 @@ impl From<tikv::Error> for Error {
 			}
 			tikv::Error::KeyError(ke) if ke.abort.contains("KeyTooLarge") => Error::TxKeyTooLarge,
 			tikv::Error::RegionError(re) if re.raft_entry_too_large.is_some() => Error::TxTooLarge,
+			tikv::Error::KeyError(ke) if ke.conflict.is_some() => Error::TxRetryable,
 			_ => Error::Tx(e.to_string()),
 		}
 	}
@@ -1079,14 +1212,20 @@ impl From<tikv::Error> for Error {
 #[cfg(feature = "kv-rocksdb")]
 impl From<rocksdb::Error> for Error {
 	fn from(e: rocksdb::Error) -> Error {
-		Error::Tx(e.to_string())
+		match e.kind() {
+			rocksdb::ErrorKind::Busy | rocksdb::ErrorKind::TryAgain => Error::TxRetryable,
+			_ => Error::Tx(e.to_string()),
+		}
 	}
 }
 
 #[cfg(feature = "kv-surrealkv")]
 impl From<surrealkv::Error> for Error {
 	fn from(e: surrealkv::Error) -> Error {
-		Error::Tx(e.to_string())
+		match e {
+			surrealkv::Error::TransactionWriteConflict => Error::TxRetryable,
+			_ => Error::Tx(e.to_string()),
+		}
 	}
 }
 
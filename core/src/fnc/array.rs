@@ -1,4 +1,6 @@
+use crate::cnf::MAX_COMPUTATION_DEPTH;
 use crate::err::Error;
+use crate::fnc::args::FromArg;
 use crate::sql::array::Array;
 use crate::sql::array::Clump;
 use crate::sql::array::Combine;
@@ -11,9 +13,24 @@ use crate::sql::array::Transpose;
 use crate::sql::array::Union;
 use crate::sql::array::Uniq;
 use crate::sql::value::Value;
+use crate::syn;
+
+use std::collections::BTreeMap;
 
 use rand::prelude::SliceRandom;
 
+/// An array argument to a set operation, where NULL is treated as an empty array
+pub struct Set(Array);
+
+impl FromArg for Set {
+	fn from_arg(arg: Value) -> Result<Self, Error> {
+		match arg {
+			Value::Null => Ok(Set(Array::new())),
+			arg => arg.coerce_to_array().map(Set),
+		}
+	}
+}
+
 pub fn add((mut array, value): (Array, Value)) -> Result<Value, Error> {
 	match value {
 		Value::Array(value) => {
@@ -126,11 +143,11 @@ pub fn concat(mut arrays: Vec<Array>) -> Result<Value, Error> {
 	Ok(arr.into())
 }
 
-pub fn difference((array, other): (Array, Array)) -> Result<Value, Error> {
+pub fn difference((Set(array), Set(other)): (Set, Set)) -> Result<Value, Error> {
 	Ok(array.difference(other).into())
 }
 
-pub fn distinct((array,): (Array,)) -> Result<Value, Error> {
+pub fn distinct((Set(array),): (Set,)) -> Result<Value, Error> {
 	Ok(array.uniq().into())
 }
 
@@ -169,10 +186,51 @@ pub fn flatten((array,): (Array,)) -> Result<Value, Error> {
 	Ok(array.flatten().into())
 }
 
+pub fn flatten_deep((array,): (Array,)) -> Result<Value, Error> {
+	fn flatten(array: Array, out: &mut Array, depth: u32) -> Result<(), Error> {
+		// Limit the depth of the nested arrays
+		if depth > *MAX_COMPUTATION_DEPTH {
+			return Err(Error::ComputationDepthExceeded);
+		}
+		for v in array {
+			match v {
+				Value::Array(a) => flatten(a, out, depth + 1)?,
+				v => out.push(v),
+			}
+		}
+		Ok(())
+	}
+	let mut out = Array::new();
+	flatten(array, &mut out, 0)?;
+	Ok(out.into())
+}
+
 pub fn group((array,): (Array,)) -> Result<Value, Error> {
 	Ok(array.flatten().uniq().into())
 }
 
+pub fn group_by((array, path): (Array, String)) -> Result<Value, Error> {
+	// Parse the string as an Idiom
+	let path = syn::idiom(&path)?;
+	let mut groups: BTreeMap<Value, Vec<Value>> = BTreeMap::new();
+	for v in array {
+		if !v.is_object() {
+			return Err(Error::InvalidArguments {
+				name: String::from("array::group_by"),
+				message: format!("Expected an array of objects but found {v}"),
+			});
+		}
+		// Group the objects by the value at the path
+		groups.entry(v.pick(&path)).or_default().push(v);
+	}
+	// Output each group as a pair of the value and the grouped objects
+	Ok(groups
+		.into_iter()
+		.map(|(k, v)| Value::from(vec![k, Value::from(v)]))
+		.collect::<Vec<_>>()
+		.into())
+}
+
 pub fn insert((mut array, value, index): (Array, Value, Option<i64>)) -> Result<Value, Error> {
 	match index {
 		Some(mut index) => {
@@ -196,7 +254,7 @@ pub fn insert((mut array, value, index): (Array, Value, Option<i64>)) -> Result<
 	}
 }
 
-pub fn intersect((array, other): (Array, Array)) -> Result<Value, Error> {
+pub fn intersect((Set(array), Set(other)): (Set, Set)) -> Result<Value, Error> {
 	Ok(array.intersect(other).into())
 }
 
@@ -395,10 +453,24 @@ pub fn transpose((array,): (Array,)) -> Result<Value, Error> {
 	Ok(array.transpose().into())
 }
 
-pub fn union((array, other): (Array, Array)) -> Result<Value, Error> {
+pub fn union((Set(array), Set(other)): (Set, Set)) -> Result<Value, Error> {
 	Ok(array.union(other).into())
 }
 
+pub fn windows((array, size): (Array, i64)) -> Result<Value, Error> {
+	if size < 1 {
+		return Err(Error::InvalidArguments {
+			name: String::from("array::windows"),
+			message: String::from("The second argument must be an integer greater than 0"),
+		});
+	}
+	Ok(array
+		.windows(size as usize)
+		.map::<Value, _>(|window| window.to_vec().into())
+		.collect::<Vec<_>>()
+		.into())
+}
+
 pub mod sort {
 
 	use crate::err::Error;
@@ -418,7 +490,9 @@ pub mod sort {
 
 #[cfg(test)]
 mod tests {
-	use super::{at, first, join, last, slice};
+	use super::{at, first, flatten_deep, join, last, slice};
+	use crate::cnf::MAX_COMPUTATION_DEPTH;
+	use crate::err::Error;
 	use crate::sql::{Array, Value};
 
 	#[test]
@@ -441,6 +515,21 @@ mod tests {
 		test(array, Some(-4), Some(-1), &[b'd', b'e', b'f']);
 	}
 
+	#[test]
+	fn array_flatten_deep_is_bounded() {
+		fn nested(depth: u32) -> Array {
+			let mut array = Array::from(vec![Value::from(1)]);
+			for _ in 0..depth {
+				array = Array::from(vec![Value::from(array)]);
+			}
+			array
+		}
+		let res = flatten_deep((nested(*MAX_COMPUTATION_DEPTH),)).unwrap();
+		assert_eq!(res, Value::from(vec![Value::from(1)]));
+		let res = flatten_deep((nested(*MAX_COMPUTATION_DEPTH + 1),));
+		assert!(matches!(res, Err(Error::ComputationDepthExceeded)), "{res:?}");
+	}
+
 	#[test]
 	fn array_join() {
 		fn test(arr: Array, sep: &str, expected: &str) {
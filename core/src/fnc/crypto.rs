@@ -57,6 +57,7 @@ macro_rules! bounded_verify_password {
 					*salt,
 				) {
 					if let Some(computed_output) = &computed_hash.hash {
+						// Password hash outputs are compared in constant time
 						expected_output == computed_output
 					} else {
 						false
@@ -80,23 +81,27 @@ macro_rules! bounded_verify_password {
 pub mod argon2 {
 
 	use super::COST_ALLOWANCE;
+	use crate::cnf::{ARGON2_MEMORY_COST, ARGON2_PARALLELISM, ARGON2_TIME_COST};
 	use crate::err::Error;
 	use crate::sql::value::Value;
 	use argon2::{
 		password_hash::{PasswordHash, PasswordHasher, SaltString},
-		Argon2,
+		Algorithm, Argon2, Params, Version,
 	};
 	use rand::rngs::OsRng;
 
 	pub fn cmp((hash, pass): (String, String)) -> Result<Value, Error> {
-		type Params<'a> = <Argon2<'a> as PasswordHasher>::Params;
+		// The hash describes its own parameters, which are checked against the configured cost
+		let m_cost = (*ARGON2_MEMORY_COST).max(Params::DEFAULT_M_COST);
+		let t_cost = (*ARGON2_TIME_COST).max(Params::DEFAULT_T_COST);
+		let p_cost = (*ARGON2_PARALLELISM).max(Params::DEFAULT_P_COST);
 		Ok(PasswordHash::new(&hash)
 			.ok()
 			.filter(|test| {
 				bounded_verify_password!(Argon2, pass, test, |params: &Params| {
-					params.m_cost() <= Params::DEFAULT_M_COST.saturating_mul(COST_ALLOWANCE)
-						&& params.t_cost() <= Params::DEFAULT_T_COST.saturating_mul(COST_ALLOWANCE)
-						&& params.p_cost() <= Params::DEFAULT_P_COST.saturating_mul(COST_ALLOWANCE)
+					params.m_cost() <= m_cost.saturating_mul(COST_ALLOWANCE)
+						&& params.t_cost() <= t_cost.saturating_mul(COST_ALLOWANCE)
+						&& params.p_cost() <= p_cost.saturating_mul(COST_ALLOWANCE)
 				})
 			})
 			.is_some()
@@ -104,7 +109,9 @@ pub mod argon2 {
 	}
 
 	pub fn gen((pass,): (String,)) -> Result<Value, Error> {
-		let algo = Argon2::default();
+		let params = Params::new(*ARGON2_MEMORY_COST, *ARGON2_TIME_COST, *ARGON2_PARALLELISM, None)
+			.map_err(|e| Error::Internal(format!("Invalid argon2 configuration: {e}")))?;
+		let algo = Argon2::new(Algorithm::Argon2id, Version::V0x13, params);
 		let salt = SaltString::generate(&mut OsRng);
 		let hash = algo.hash_password(pass.as_ref(), &salt).unwrap().to_string();
 		Ok(hash.into())
@@ -113,6 +120,7 @@ pub mod argon2 {
 
 pub mod bcrypt {
 
+	use crate::cnf::BCRYPT_COST;
 	use crate::err::Error;
 	use crate::fnc::crypto::COST_ALLOWANCE;
 	use crate::sql::value::Value;
@@ -124,8 +132,9 @@ pub mod bcrypt {
 			Ok(parts) => parts,
 			Err(_) => return Ok(Value::Bool(false)),
 		};
+		let cost = (*BCRYPT_COST).max(bcrypt::DEFAULT_COST);
 		// Note: Bcrypt cost is exponential, so add the cost allowance as opposed to multiplying.
-		Ok(if parts.get_cost() > bcrypt::DEFAULT_COST.saturating_add(COST_ALLOWANCE) {
+		Ok(if parts.get_cost() > cost.saturating_add(COST_ALLOWANCE) {
 			// Too expensive to compute.
 			Value::Bool(false)
 		} else {
@@ -136,7 +145,8 @@ pub mod bcrypt {
 	}
 
 	pub fn gen((pass,): (String,)) -> Result<Value, Error> {
-		let hash = bcrypt::hash(pass, bcrypt::DEFAULT_COST).unwrap();
+		let hash = bcrypt::hash(pass, *BCRYPT_COST)
+			.map_err(|e| Error::Internal(format!("Invalid bcrypt configuration: {e}")))?;
 		Ok(hash.into())
 	}
 }
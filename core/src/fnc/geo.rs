@@ -1,4 +1,5 @@
 use crate::err::Error;
+use crate::fnc::util::geo::{crosses_antimeridian, unwrap_antimeridian};
 use crate::sql::geometry::Geometry;
 use crate::sql::value::Value;
 use geo::algorithm::bearing::HaversineBearing;
@@ -6,9 +7,17 @@ use geo::algorithm::centroid::Centroid;
 use geo::algorithm::chamberlain_duquette_area::ChamberlainDuquetteArea;
 use geo::algorithm::haversine_distance::HaversineDistance;
 
+/// Make a geometry which crosses the antimeridian contiguous
+fn antimeridian(v: Geometry) -> Geometry {
+	match crosses_antimeridian(&v) {
+		true => unwrap_antimeridian(&v),
+		false => v,
+	}
+}
+
 pub fn area((arg,): (Value,)) -> Result<Value, Error> {
 	match arg {
-		Value::Geometry(v) => match v {
+		Value::Geometry(v) => match antimeridian(v) {
 			Geometry::Point(v) => Ok(v.chamberlain_duquette_unsigned_area().into()),
 			Geometry::Line(v) => Ok(v.chamberlain_duquette_unsigned_area().into()),
 			Geometry::Polygon(v) => Ok(v.chamberlain_duquette_unsigned_area().into()),
@@ -50,6 +59,16 @@ pub fn centroid((arg,): (Value,)) -> Result<Value, Error> {
 	Ok(centroid.map(Into::into).unwrap_or(Value::None))
 }
 
+pub fn contains(geometries: (Value, Value)) -> Result<Value, Error> {
+	Ok(match geometries {
+		(Value::Geometry(v), Value::Geometry(w)) => match crosses_antimeridian(&v) {
+			true => unwrap_antimeridian(&v).contains(&unwrap_antimeridian(&w)).into(),
+			false => v.contains(&w).into(),
+		},
+		_ => Value::None,
+	})
+}
+
 pub fn distance(points: (Value, Value)) -> Result<Value, Error> {
 	Ok(match points {
 		(Value::Geometry(Geometry::Point(v)), Value::Geometry(Geometry::Point(w))) => {
@@ -1,4 +1,5 @@
 use crate::err::Error;
+use crate::fnc::args::FromArg;
 use crate::fnc::util::math::bottom::Bottom;
 use crate::fnc::util::math::deviation::Deviation;
 use crate::fnc::util::math::interquartile::Interquartile;
@@ -15,6 +16,22 @@ use crate::fnc::util::math::variance::Variance;
 use crate::sql::number::{Number, Sort};
 use crate::sql::value::{TryPow, Value};
 
+/// An array of numbers, from which any NULL or NONE elements have been removed
+pub struct Numbers(Vec<Number>);
+
+impl FromArg for Numbers {
+	fn from_arg(arg: Value) -> Result<Self, Error> {
+		let arg = match arg {
+			Value::Array(mut v) => {
+				v.retain(|v| !v.is_none_or_null());
+				Value::Array(v)
+			}
+			v => v,
+		};
+		Vec::<Number>::from_arg(arg).map(Numbers)
+	}
+}
+
 pub fn abs((arg,): (Number,)) -> Result<Value, Error> {
 	Ok(arg.abs().into())
 }
@@ -119,7 +136,7 @@ pub fn mean((array,): (Vec<Number>,)) -> Result<Value, Error> {
 	Ok(array.mean().into())
 }
 
-pub fn median((mut array,): (Vec<Number>,)) -> Result<Value, Error> {
+pub fn median((Numbers(mut array),): (Numbers,)) -> Result<Value, Error> {
 	Ok(match array.is_empty() {
 		true => Value::None,
 		false => array.sorted().median().into(),
@@ -137,7 +154,7 @@ pub fn min((array,): (Vec<Number>,)) -> Result<Value, Error> {
 	})
 }
 
-pub fn mode((array,): (Vec<Number>,)) -> Result<Value, Error> {
+pub fn mode((Numbers(array),): (Numbers,)) -> Result<Value, Error> {
 	Ok(array.mode().into())
 }
 
@@ -145,7 +162,7 @@ pub fn nearestrank((mut array, n): (Vec<Number>, Number)) -> Result<Value, Error
 	Ok(array.sorted().nearestrank(n).into())
 }
 
-pub fn percentile((mut array, n): (Vec<Number>, Number)) -> Result<Value, Error> {
+pub fn percentile((Numbers(mut array), n): (Numbers, Number)) -> Result<Value, Error> {
 	Ok(array.sorted().percentile(n).into())
 }
 
@@ -184,7 +201,7 @@ pub fn sqrt((arg,): (Number,)) -> Result<Value, Error> {
 	})
 }
 
-pub fn stddev((array,): (Vec<Number>,)) -> Result<Value, Error> {
+pub fn stddev((Numbers(array),): (Numbers,)) -> Result<Value, Error> {
 	Ok(array.deviation(true).into())
 }
 
@@ -43,6 +43,10 @@ pub async fn run(
 	name: &str,
 	args: Vec<Value>,
 ) -> Result<Value, Error> {
+	// Network requests can not be undone by running the statement again
+	if name.starts_with("http") {
+		ctx.set_side_effect();
+	}
 	if name.eq("sleep")
 		|| name.starts_with("search")
 		|| name.starts_with("http")
@@ -103,6 +107,7 @@ pub fn synchronous(
 		"array::boolean_not" => array::boolean_not,
 		"array::boolean_or" => array::boolean_or,
 		"array::boolean_xor" => array::boolean_xor,
+		"array::chunk" => array::clump,
 		"array::clump" => array::clump,
 		"array::combine" => array::combine,
 		"array::complement" => array::complement,
@@ -113,7 +118,9 @@ pub fn synchronous(
 		"array::find_index" => array::find_index,
 		"array::first" => array::first,
 		"array::flatten" => array::flatten,
+		"array::flatten_deep" => array::flatten_deep,
 		"array::group" => array::group,
+		"array::group_by" => array::group_by,
 		"array::insert" => array::insert,
 		"array::intersect" => array::intersect,
 		"array::join" => array::join,
@@ -135,6 +142,7 @@ pub fn synchronous(
 		"array::sort" => array::sort,
 		"array::transpose" => array::transpose,
 		"array::union" => array::union,
+		"array::windows" => array::windows,
 		"array::sort::asc" => array::sort::asc,
 		"array::sort::desc" => array::sort::desc,
 		//
@@ -171,6 +179,7 @@ pub fn synchronous(
 		"geo::area" => geo::area,
 		"geo::bearing" => geo::bearing,
 		"geo::centroid" => geo::centroid,
+		"geo::contains" => geo::contains,
 		"geo::distance" => geo::distance,
 		"geo::hash::decode" => geo::hash::decode,
 		"geo::hash::encode" => geo::hash::encode,
@@ -265,16 +274,19 @@ pub fn synchronous(
 		"string::concat" => string::concat,
 		"string::contains" => string::contains,
 		"string::endsWith" => string::ends_with,
+		"string::extract" => string::extract,
 		"string::join" => string::join,
 		"string::len" => string::len,
 		"string::lowercase" => string::lowercase,
 		"string::matches" => string::matches,
 		"string::repeat" => string::repeat,
 		"string::replace" => string::replace,
+		"string::replace_regex" => string::replace_regex,
 		"string::reverse" => string::reverse,
 		"string::slice" => string::slice,
 		"string::slug" => string::slug,
 		"string::split" => string::split,
+		"string::split_regex" => string::split_regex,
 		"string::startsWith" => string::starts_with,
 		"string::trim" => string::trim,
 		"string::uppercase" => string::uppercase,
@@ -301,6 +313,7 @@ pub fn synchronous(
 		"string::is::uuid" => string::is::uuid,
 		"string::similarity::fuzzy" => string::similarity::fuzzy,
 		"string::similarity::jaro" => string::similarity::jaro,
+		"string::similarity::jaro_winkler" => string::similarity::jaro_winkler,
 		"string::similarity::smithwaterman" => string::similarity::smithwaterman,
 		"string::semver::compare" => string::semver::compare,
 		"string::semver::major" => string::semver::major,
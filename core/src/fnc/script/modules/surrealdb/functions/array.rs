@@ -17,6 +17,7 @@ impl_module_def!(
 	"boolean_not" => run,
 	"boolean_or" => run,
 	"boolean_xor" => run,
+	"chunk" => run,
 	"clump" => run,
 	"combine" => run,
 	"complement" => run,
@@ -27,7 +28,9 @@ impl_module_def!(
 	"find_index" => run,
 	"first" => run,
 	"flatten" => run,
+	"flatten_deep" => run,
 	"group" => run,
+	"group_by" => run,
 	"insert" => run,
 	"intersect" => run,
 	"join" => run,
@@ -49,5 +52,6 @@ impl_module_def!(
 	"slice" => run,
 	"sort" => (sort::Package),
 	"transpose" => run,
-	"union" => run
+	"union" => run,
+	"windows" => run
 );
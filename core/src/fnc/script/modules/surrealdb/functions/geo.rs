@@ -12,6 +12,7 @@ impl_module_def!(
 	"area" => run,
 	"bearing" => run,
 	"centroid" => run,
+	"contains" => run,
 	"distance" => run,
 	"hash" => (hash::Package)
 );
@@ -17,6 +17,7 @@ impl_module_def!(
 	"contains" => run,
 	"distance" => (distance::Package),
 	"endsWith" => run,
+	"extract" => run,
 	"html" => (html::Package),
 	"is" => (is::Package),
 	"join" => run,
@@ -25,11 +26,13 @@ impl_module_def!(
 	"matches" => run,
 	"repeat" => run,
 	"replace" => run,
+	"replace_regex" => run,
 	"reverse" => run,
 	"similarity" => (similarity::Package),
 	"slice" => run,
 	"slug" => run,
 	"split" => run,
+	"split_regex" => run,
 	"startsWith" => run,
 	"trim" => run,
 	"uppercase" => run,
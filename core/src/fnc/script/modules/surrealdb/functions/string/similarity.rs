@@ -9,5 +9,6 @@ impl_module_def!(
 	"string::similarity",
 	"fuzzy" => run,
 	"jaro" => run,
+	"jaro_winkler" => run,
 	"smithwaterman" => run
 );
@@ -1,7 +1,7 @@
 use crate::err::Error;
 use crate::fnc::util::string;
 use crate::sql::value::Value;
-use crate::sql::Regex;
+use crate::sql::{Number, Regex};
 
 /// Returns `true` if a string of this length is too much to allocate.
 fn limit(name: &str, n: usize) -> Result<(), Error> {
@@ -16,6 +16,14 @@ fn limit(name: &str, n: usize) -> Result<(), Error> {
 	}
 }
 
+/// Converts two arguments to strings, or returns `None` if either of them is NULL or NONE.
+fn strings(a: Value, b: Value) -> Result<Option<(String, String)>, Error> {
+	if a.is_none_or_null() || b.is_none_or_null() {
+		return Ok(None);
+	}
+	Ok(Some((a.coerce_to_string()?, b.coerce_to_string()?)))
+}
+
 pub fn concat(args: Vec<Value>) -> Result<Value, Error> {
 	let strings = args.into_iter().map(Value::as_string).collect::<Vec<_>>();
 	limit("string::concat", strings.iter().map(String::len).sum::<usize>())?;
@@ -30,6 +38,37 @@ pub fn ends_with((val, chr): (String, String)) -> Result<Value, Error> {
 	Ok(val.ends_with(&chr).into())
 }
 
+pub fn extract((val, regex, group): (String, Regex, Option<Value>)) -> Result<Value, Error> {
+	let re = regex.regex();
+	// Find the index of the capture group, defaulting to the whole match
+	let index = match group {
+		None | Some(Value::None) => Some(0),
+		Some(Value::Number(Number::Int(n))) => {
+			usize::try_from(n).ok().filter(|&i| i < re.captures_len())
+		}
+		Some(Value::Strand(s)) => re.capture_names().position(|n| n == Some(s.as_str())),
+		Some(v) => {
+			return Err(Error::InvalidArguments {
+				name: "string::extract".to_owned(),
+				message: format!(
+					"Argument 3 was the wrong type. Expected an integer or a string but found {v}"
+				),
+			})
+		}
+	};
+	let Some(index) = index else {
+		return Err(Error::InvalidArguments {
+			name: "string::extract".to_owned(),
+			message: format!("Argument 3 is not a capture group in the regular expression {regex}"),
+		});
+	};
+	// The group may not have matched, even if the expression did
+	Ok(match re.captures(&val).and_then(|c| c.get(index)) {
+		Some(m) => m.as_str().into(),
+		None => Value::None,
+	})
+}
+
 pub fn join(args: Vec<Value>) -> Result<Value, Error> {
 	let mut args = args.into_iter().map(Value::as_string);
 	let chr = args.next().ok_or_else(|| Error::InvalidArguments {
@@ -91,6 +130,14 @@ pub fn replace((val, old_or_regexp, new): (String, Value, String)) -> Result<Val
 		}),
 	}
 }
+
+pub fn replace_regex((val, regex, new): (String, Regex, String)) -> Result<Value, Error> {
+	// The replacement can refer to capture groups as $1 or ${name}
+	let val = regex.regex().replace_all(&val, new.as_str()).into_owned();
+	limit("string::replace_regex", val.len())?;
+	Ok(val.into())
+}
+
 pub fn reverse((string,): (String,)) -> Result<Value, Error> {
 	Ok(string.chars().rev().collect::<String>().into())
 }
@@ -133,6 +180,10 @@ pub fn split((val, chr): (String, String)) -> Result<Value, Error> {
 	Ok(val.split(&chr).collect::<Vec<&str>>().into())
 }
 
+pub fn split_regex((val, regex): (String, Regex)) -> Result<Value, Error> {
+	Ok(regex.regex().split(&val).collect::<Vec<&str>>().into())
+}
+
 pub fn starts_with((val, chr): (String, String)) -> Result<Value, Error> {
 	Ok(val.starts_with(&chr).into())
 }
@@ -152,6 +203,7 @@ pub fn words((string,): (String,)) -> Result<Value, Error> {
 pub mod distance {
 
 	use crate::err::Error;
+	use crate::fnc::util::string::similarity;
 	use crate::sql::Value;
 
 	pub fn hamming((_, _): (String, String)) -> Result<Value, Error> {
@@ -160,9 +212,10 @@ pub mod distance {
 		})
 	}
 
-	pub fn levenshtein((_, _): (String, String)) -> Result<Value, Error> {
-		Err(Error::FeatureNotYetImplemented {
-			feature: "string::distance::levenshtein() function".to_string(),
+	pub fn levenshtein((a, b): (Value, Value)) -> Result<Value, Error> {
+		Ok(match super::strings(a, b)? {
+			Some((a, b)) => similarity::levenshtein(&a, &b).into(),
+			None => Value::None,
 		})
 	}
 }
@@ -269,15 +322,27 @@ pub mod similarity {
 
 	use crate::err::Error;
 	use crate::fnc::util::string::fuzzy::Fuzzy;
+	use crate::fnc::util::string::similarity;
 	use crate::sql::Value;
 
-	pub fn fuzzy((a, b): (String, String)) -> Result<Value, Error> {
-		Ok(a.as_str().fuzzy_score(b.as_str()).into())
+	pub fn fuzzy((a, b): (Value, Value)) -> Result<Value, Error> {
+		Ok(match super::strings(a, b)? {
+			Some((a, b)) => similarity::fuzzy(&a, &b).into(),
+			None => Value::None,
+		})
 	}
 
-	pub fn jaro((_, _): (String, String)) -> Result<Value, Error> {
-		Err(Error::FeatureNotYetImplemented {
-			feature: "string::similarity::jaro() function".to_string(),
+	pub fn jaro((a, b): (Value, Value)) -> Result<Value, Error> {
+		Ok(match super::strings(a, b)? {
+			Some((a, b)) => similarity::jaro(&a, &b).into(),
+			None => Value::None,
+		})
+	}
+
+	pub fn jaro_winkler((a, b): (Value, Value)) -> Result<Value, Error> {
+		Ok(match super::strings(a, b)? {
+			Some((a, b)) => similarity::jaro_winkler(&a, &b).into(),
+			None => Value::None,
 		})
 	}
 
@@ -84,6 +84,11 @@ pub fn group((val, group): (Datetime, String)) -> Result<Value, Error> {
 			.earliest()
 			.unwrap()
 			.into()),
+		"week" => Ok((Utc
+			.with_ymd_and_hms(val.year(), val.month(), val.day(), 0,0,0)
+			.earliest()
+			.unwrap() - chrono::Duration::days(val.weekday().num_days_from_monday() as i64))
+			.into()),
 		"day" => Ok(Utc
 			.with_ymd_and_hms(val.year(), val.month(), val.day(), 0,0,0)
 			.earliest()
@@ -106,7 +111,7 @@ pub fn group((val, group): (Datetime, String)) -> Result<Value, Error> {
 			.into()),
 		_ => Err(Error::InvalidArguments {
 			name: String::from("time::group"),
-			message: String::from("The second argument must be a string, and can be one of 'year', 'month', 'day', 'hour', 'minute', or 'second'."),
+			message: String::from("The second argument must be a string, and can be one of 'year', 'month', 'week', 'day', 'hour', 'minute', or 'second'."),
 		}),
 	}
 }
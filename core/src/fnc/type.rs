@@ -11,20 +11,28 @@ use crate::sql::{Id, Range, Strand};
 use crate::syn;
 use reblessive::tree::Stk;
 
+/// Convert a value with the given conversion, passing NONE and NULL values through unchanged
+fn convert<T: Into<Value>>(val: Value, f: fn(Value) -> Result<T, Error>) -> Result<Value, Error> {
+	match val {
+		Value::None | Value::Null => Ok(val),
+		val => f(val).map(Into::into),
+	}
+}
+
 pub fn bool((val,): (Value,)) -> Result<Value, Error> {
-	val.convert_to_bool().map(Value::from)
+	convert(val, Value::convert_to_bool)
 }
 
 pub fn datetime((val,): (Value,)) -> Result<Value, Error> {
-	val.convert_to_datetime().map(Value::from)
+	convert(val, Value::convert_to_datetime)
 }
 
 pub fn decimal((val,): (Value,)) -> Result<Value, Error> {
-	val.convert_to_decimal().map(Value::from)
+	convert(val, Value::convert_to_decimal)
 }
 
 pub fn duration((val,): (Value,)) -> Result<Value, Error> {
-	val.convert_to_duration().map(Value::from)
+	convert(val, Value::convert_to_duration)
 }
 
 pub async fn field(
@@ -68,23 +76,23 @@ pub async fn fields(
 }
 
 pub fn float((val,): (Value,)) -> Result<Value, Error> {
-	val.convert_to_float().map(Value::from)
+	convert(val, Value::convert_to_float)
 }
 
 pub fn int((val,): (Value,)) -> Result<Value, Error> {
-	val.convert_to_int().map(Value::from)
+	convert(val, Value::convert_to_int)
 }
 
 pub fn number((val,): (Value,)) -> Result<Value, Error> {
-	val.convert_to_number().map(Value::from)
+	convert(val, Value::convert_to_number)
 }
 
 pub fn point((val,): (Value,)) -> Result<Value, Error> {
-	val.convert_to_point().map(Value::from)
+	convert(val, Value::convert_to_point)
 }
 
 pub fn string((val,): (Value,)) -> Result<Value, Error> {
-	val.convert_to_strand().map(Value::from)
+	convert(val, Value::convert_to_strand)
 }
 
 pub fn table((val,): (Value,)) -> Result<Value, Error> {
@@ -337,6 +345,77 @@ mod tests {
 		assert_eq!(value, Value::Bool(false));
 	}
 
+	#[test]
+	fn conversions() {
+		use crate::syn::Parse;
+		type Conversion = fn((Value,)) -> Result<Value, Error>;
+		let tests: [(&str, Conversion, &str, Option<&str>); 30] = [
+			("bool", super::bool, "true", Some("true")),
+			("bool", super::bool, "'false'", Some("false")),
+			("bool", super::bool, "'yes'", None),
+			("bool", super::bool, "1", None),
+			(
+				"datetime",
+				super::datetime,
+				"'2024-01-01T00:00:00Z'",
+				Some("d'2024-01-01T00:00:00Z'"),
+			),
+			("datetime", super::datetime, "'2024-01-01'", Some("d'2024-01-01T00:00:00Z'")),
+			("datetime", super::datetime, "'tomorrow'", None),
+			("datetime", super::datetime, "1", None),
+			("decimal", super::decimal, "'1.50'", Some("1.50dec")),
+			("decimal", super::decimal, "'one'", None),
+			("duration", super::duration, "'1h30m'", Some("1h30m")),
+			("duration", super::duration, "1h", Some("1h")),
+			("duration", super::duration, "'soon'", None),
+			("duration", super::duration, "60", None),
+			("float", super::float, "'1.5'", Some("1.5f")),
+			("float", super::float, "2", Some("2f")),
+			("int", super::int, "'42'", Some("42")),
+			("int", super::int, "4.0", Some("4")),
+			("int", super::int, "4.7", None),
+			("int", super::int, "'4.7'", None),
+			("number", super::number, "'42'", Some("42")),
+			("number", super::number, "'1.5'", Some("1.5f")),
+			("number", super::number, "'forty two'", None),
+			("number", super::number, "true", None),
+			("number", super::number, "[1]", None),
+			("point", super::point, "[1, 2]", Some("(1, 2)")),
+			("point", super::point, "[1]", None),
+			("string", super::string, "123", Some("'123'")),
+			("string", super::string, "d'2024-01-01T00:00:00Z'", Some("'2024-01-01T00:00:00Z'")),
+			("string", super::string, "1h", Some("'1h'")),
+		];
+		for (name, f, val, expected) in tests {
+			let res = f((Value::parse(val),));
+			match expected {
+				Some(expected) => {
+					assert_eq!(res.unwrap().to_string(), expected, "type::{name}({val})")
+				}
+				None => assert!(
+					matches!(res, Err(Error::ConvertTo { .. })),
+					"type::{name}({val}) should fail: {res:?}"
+				),
+			}
+		}
+		// NONE and NULL values are passed through unchanged
+		let conversions: [Conversion; 9] = [
+			super::bool,
+			super::datetime,
+			super::decimal,
+			super::duration,
+			super::float,
+			super::int,
+			super::number,
+			super::point,
+			super::string,
+		];
+		for f in conversions {
+			assert_eq!(f((Value::None,)).unwrap(), Value::None);
+			assert_eq!(f((Value::Null,)).unwrap(), Value::Null);
+		}
+	}
+
 	#[test]
 	fn no_empty_thing() {
 		let value = super::thing(("".into(), None));
@@ -1,6 +1,6 @@
 use crate::sql::geometry::Geometry;
 use crate::sql::strand::Strand;
-use geo::Point;
+use geo::{Coord, LineString, MapCoords, Point, Polygon};
 
 static BASE32: &[char] = &[
 	'0', '1', '2', '3', '4', '5', '6', '7', '8', '9', 'b', 'c', 'd', 'e', 'f', 'g', 'h', 'j', 'k',
@@ -99,3 +99,48 @@ pub fn decode(v: Strand) -> Geometry {
 
 	(x, y).into()
 }
+
+/// Check whether a geometry crosses the antimeridian, which is assumed when
+/// the longitudes of any two consecutive coordinates are more than half of the
+/// globe apart, as the shorter way between them is across the antimeridian.
+pub fn crosses_antimeridian(v: &Geometry) -> bool {
+	match v {
+		Geometry::Point(_) | Geometry::MultiPoint(_) => false,
+		Geometry::Line(v) => jumps_antimeridian(v),
+		Geometry::Polygon(v) => polygon_jumps_antimeridian(v),
+		Geometry::MultiLine(v) => v.iter().any(jumps_antimeridian),
+		Geometry::MultiPolygon(v) => v.iter().any(polygon_jumps_antimeridian),
+		Geometry::Collection(v) => v.iter().any(crosses_antimeridian),
+	}
+}
+
+/// Shift every negative longitude east by a full turn, so that
+/// a geometry crossing the antimeridian becomes contiguous.
+pub fn unwrap_antimeridian(v: &Geometry) -> Geometry {
+	let f = |c: Coord<f64>| match c.x < 0f64 {
+		true => Coord {
+			x: c.x + 360f64,
+			y: c.y,
+		},
+		false => c,
+	};
+	match v {
+		Geometry::Point(v) => Geometry::Point(v.map_coords(f)),
+		Geometry::Line(v) => Geometry::Line(v.map_coords(f)),
+		Geometry::Polygon(v) => Geometry::Polygon(v.map_coords(f)),
+		Geometry::MultiPoint(v) => Geometry::MultiPoint(v.map_coords(f)),
+		Geometry::MultiLine(v) => Geometry::MultiLine(v.map_coords(f)),
+		Geometry::MultiPolygon(v) => Geometry::MultiPolygon(v.map_coords(f)),
+		Geometry::Collection(v) => {
+			Geometry::Collection(v.iter().map(unwrap_antimeridian).collect())
+		}
+	}
+}
+
+fn jumps_antimeridian(v: &LineString<f64>) -> bool {
+	v.0.windows(2).any(|w| (w[1].x - w[0].x).abs() > 180f64)
+}
+
+fn polygon_jumps_antimeridian(v: &Polygon<f64>) -> bool {
+	std::iter::once(v.exterior()).chain(v.interiors()).any(jumps_antimeridian)
+}
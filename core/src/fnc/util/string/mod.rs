@@ -1,2 +1,3 @@
 pub mod fuzzy;
+pub mod similarity;
 pub mod slug;
@@ -0,0 +1,139 @@
+use crate::fnc::util::string::fuzzy::Fuzzy;
+
+/// Calculate the number of single character edits needed to turn one string
+/// into another. Characters are compared as unicode code points, not bytes.
+pub fn levenshtein(a: &str, b: &str) -> usize {
+	let b: Vec<char> = b.chars().collect();
+	// The edit distances for the previous row
+	let mut prev: Vec<usize> = (0..=b.len()).collect();
+	// The edit distances for the current row
+	let mut curr = vec![0; b.len() + 1];
+	for (i, ca) in a.chars().enumerate() {
+		curr[0] = i + 1;
+		for (j, cb) in b.iter().enumerate() {
+			let cost = usize::from(ca != *cb);
+			curr[j + 1] = (prev[j] + cost).min(prev[j + 1] + 1).min(curr[j] + 1);
+		}
+		std::mem::swap(&mut prev, &mut curr);
+	}
+	prev[b.len()]
+}
+
+/// Calculate the Jaro similarity of two strings, between 0 and 1.
+/// Characters are compared as unicode code points, not bytes.
+pub fn jaro(a: &str, b: &str) -> f64 {
+	let a: Vec<char> = a.chars().collect();
+	let b: Vec<char> = b.chars().collect();
+	// Two empty strings are identical
+	if a.is_empty() && b.is_empty() {
+		return 1.0;
+	}
+	// An empty string has nothing in common
+	if a.is_empty() || b.is_empty() {
+		return 0.0;
+	}
+	// Characters only match if they are this close
+	let window = (a.len().max(b.len()) / 2).saturating_sub(1);
+	let mut a_matches = vec![false; a.len()];
+	let mut b_matches = vec![false; b.len()];
+	let mut matches = 0;
+	for (i, ca) in a.iter().enumerate() {
+		let start = i.saturating_sub(window);
+		let end = (i + window + 1).min(b.len());
+		for j in start..end {
+			if !b_matches[j] && b[j] == *ca {
+				a_matches[i] = true;
+				b_matches[j] = true;
+				matches += 1;
+				break;
+			}
+		}
+	}
+	if matches == 0 {
+		return 0.0;
+	}
+	// Count the matching characters which are out of order
+	let mut transpositions = 0;
+	let mut j = 0;
+	for (i, ca) in a.iter().enumerate() {
+		if a_matches[i] {
+			while !b_matches[j] {
+				j += 1;
+			}
+			if *ca != b[j] {
+				transpositions += 1;
+			}
+			j += 1;
+		}
+	}
+	let m = matches as f64;
+	let t = (transpositions / 2) as f64;
+	(m / a.len() as f64 + m / b.len() as f64 + (m - t) / m) / 3.0
+}
+
+/// Calculate the Jaro-Winkler similarity of two strings, between 0 and 1,
+/// which gives a higher score to strings which share a common prefix.
+pub fn jaro_winkler(a: &str, b: &str) -> f64 {
+	let sim = jaro(a, b);
+	// Only a prefix of up to 4 characters is considered
+	let prefix = a.chars().zip(b.chars()).take(4).take_while(|(a, b)| a == b).count();
+	sim + prefix as f64 * 0.1 * (1.0 - sim)
+}
+
+/// Calculate the fuzzy similarity of a string to a pattern, between 0 and 1.
+/// The fuzzy match score is divided by the score of the pattern matched
+/// against itself, which is the highest score that the pattern can have.
+pub fn fuzzy(a: &str, b: &str) -> f64 {
+	match b.fuzzy_score(b) {
+		// An empty pattern only matches an empty string
+		0 => match a.is_empty() {
+			true => 1.0,
+			false => 0.0,
+		},
+		max => (a.fuzzy_score(b) as f64 / max as f64).clamp(0.0, 1.0),
+	}
+}
+
+#[cfg(test)]
+mod tests {
+	use super::*;
+
+	#[test]
+	fn levenshtein_distance() {
+		assert_eq!(levenshtein("", ""), 0);
+		assert_eq!(levenshtein("", "abc"), 3);
+		assert_eq!(levenshtein("kitten", "sitting"), 3);
+		assert_eq!(levenshtein("café", "cafe"), 1);
+		assert_eq!(levenshtein("naïve", "naïve"), 0);
+	}
+
+	#[test]
+	fn jaro_similarity() {
+		assert_eq!(jaro("", ""), 1.0);
+		assert_eq!(jaro("", "abc"), 0.0);
+		assert_eq!(jaro("abc", "xyz"), 0.0);
+		assert!((jaro("martha", "marhta") - 0.944).abs() < 0.001);
+		assert!((jaro("crème", "creme") - 0.867).abs() < 0.001);
+	}
+
+	#[test]
+	fn jaro_winkler_similarity() {
+		assert_eq!(jaro_winkler("", ""), 1.0);
+		assert!((jaro_winkler("martha", "marhta") - 0.961).abs() < 0.001);
+		assert!((jaro_winkler("dwayne", "duane") - 0.840).abs() < 0.001);
+		assert_eq!(jaro_winkler("éclair", "éclair"), 1.0);
+	}
+
+	#[test]
+	fn fuzzy_similarity() {
+		assert_eq!(fuzzy("", ""), 1.0);
+		assert_eq!(fuzzy("text", ""), 0.0);
+		assert_eq!(fuzzy("some", "text"), 0.0);
+		assert_eq!(fuzzy("TEXT", "TEXT"), 1.0);
+		assert_eq!(fuzzy("crème brûlée", "crème brûlée"), 1.0);
+		let sim = fuzzy("this could be a tricky test", "this test");
+		assert!(sim > 0.0 && sim < 1.0, "{sim}");
+		let sim = fuzzy("crème brûlée", "brûlée");
+		assert!(sim > 0.0 && sim <= 1.0, "{sim}");
+	}
+}
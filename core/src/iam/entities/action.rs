@@ -54,6 +54,7 @@ impl From<&Statement<'_>> for Action {
 			Statement::Relate(_) => Action::Edit,
 			Statement::Delete(_) => Action::Edit,
 			Statement::Insert(_) => Action::Edit,
+			Statement::Restore(_) => Action::Edit,
 		}
 	}
 }
@@ -11,6 +11,8 @@ pub mod issue;
 #[cfg(feature = "jwks")]
 pub mod jwks;
 pub mod policies;
+pub mod refresh;
+pub mod revoke;
 pub mod signin;
 pub mod signup;
 pub mod token;
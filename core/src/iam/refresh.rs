@@ -0,0 +1,88 @@
+use crate::cnf::{REFRESH_TOKEN_EXPIRY, SERVER_NAME};
+use crate::err::Error;
+use crate::iam::token::Claims;
+use crate::iam::{issue::expiration, revoke, verify};
+use crate::kvs::{Datastore, LockType::*, TransactionType::*};
+use crate::sql::{Algorithm, Duration, Thing};
+use crate::syn;
+use chrono::Utc;
+use jsonwebtoken::{encode, EncodingKey, Header};
+use std::time::Duration as StdDuration;
+use uuid::Uuid;
+
+/// Issue a refresh token for a record, which expires after the grant duration,
+/// or after the default refresh token expiry if there is no grant duration
+pub(super) fn issue(
+	alg: Algorithm,
+	key: &EncodingKey,
+	grant: Option<Duration>,
+	ns: &str,
+	db: &str,
+	ac: &str,
+	rid: &Thing,
+) -> Result<String, Error> {
+	// Refresh tokens always expire, so that they can be removed once revoked
+	let grant = grant.unwrap_or_else(|| StdDuration::from_secs(*REFRESH_TOKEN_EXPIRY).into());
+	// Create the refresh token claim
+	let val = Claims {
+		iss: Some(SERVER_NAME.to_owned()),
+		iat: Some(Utc::now().timestamp()),
+		nbf: Some(Utc::now().timestamp()),
+		exp: expiration(Some(grant))?,
+		jti: Some(Uuid::new_v4().to_string()),
+		ns: Some(ns.to_owned()),
+		db: Some(db.to_owned()),
+		ac: Some(ac.to_owned()),
+		id: Some(rid.to_raw()),
+		refresh: Some(true),
+		..Claims::default()
+	};
+	// Create the refresh token
+	encode(&Header::new(alg.into()), &val, key).map_err(|_| Error::TokenMakingFailed)
+}
+
+/// Exchange a refresh token for the record which it was issued to.
+///
+/// Refresh tokens can only be used once, so the token is revoked in the
+/// same transaction in which it is checked. The record must still exist.
+pub(super) async fn exchange(
+	kvs: &Datastore,
+	ns: &str,
+	db: &str,
+	ac: &str,
+	token: &str,
+) -> Result<Thing, Error> {
+	// Verify the refresh token
+	let claims = verify::refresh(kvs, token).await?;
+	// Check the token was issued by this access method
+	if claims.ns.as_deref() != Some(ns)
+		|| claims.db.as_deref() != Some(db)
+		|| claims.ac.as_deref() != Some(ac)
+	{
+		return Err(Error::InvalidAuth);
+	}
+	// Parse the token id and record id
+	let (Some(jti), Some(id)) = (claims.jti, claims.id) else {
+		return Err(Error::InvalidAuth);
+	};
+	let rid = syn::thing(&id)?;
+	// Create a new writeable transaction
+	let mut tx = kvs.transaction(Write, Optimistic).await?;
+	// Check the token has not been used already
+	if revoke::revoked(&mut tx, &jti).await? {
+		tx.cancel().await?;
+		return Err(Error::InvalidAuth);
+	}
+	// Check the record still exists
+	let key = crate::key::thing::new(ns, db, &rid.tb, &rid.id);
+	if tx.get(key).await?.is_none() {
+		tx.cancel().await?;
+		return Err(Error::InvalidAuth);
+	}
+	// Revoke the token so that it can not be used again
+	revoke::revoke(&mut tx, &jti, claims.exp).await?;
+	tx.commit().await?;
+	// Log the exchanged refresh token
+	trace!("Exchanged refresh token for access method `{}`", ac);
+	Ok(rid)
+}
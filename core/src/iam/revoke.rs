@@ -0,0 +1,146 @@
+use crate::cnf::REVOCATION_CHECK_INTERVAL;
+use crate::dbs::Session;
+use crate::err::Error;
+use crate::iam::verify;
+use crate::kvs::{Datastore, LockType::*, Transaction, TransactionType::*};
+use crate::sql::Value;
+use quick_cache::sync::Cache;
+use std::time::Duration;
+use trice::Instant;
+
+/// How many recently checked tokens are cached by each datastore
+const CHECKED_TOKENS: usize = 10_000;
+
+/// How many revoked tokens are checked for expiry in each batch
+const CLEANUP_BATCH_SIZE: u32 = 1000;
+
+/// The result of checking whether a token has been revoked
+#[derive(Clone, Copy)]
+enum Checked {
+	// The token has been revoked
+	Revoked,
+	// The token had not been revoked at this time
+	Valid(Instant),
+}
+
+/// A bounded cache of the tokens which sessions have recently been checked against.
+///
+/// A token can not be unrevoked, so revoked tokens stay cached. Tokens which
+/// had not been revoked are checked again once the revocation check interval
+/// has passed, so a token which is revoked on another node is rejected after
+/// at most that interval, while a token which is revoked on this node is
+/// rejected straight away.
+pub(crate) struct RevocationCache(Cache<String, Checked>);
+
+impl Default for RevocationCache {
+	fn default() -> Self {
+		Self(Cache::new(CHECKED_TOKENS))
+	}
+}
+
+/// Revoke an access or refresh token, so that it can no longer be used.
+///
+/// The token is verified before it is revoked, so that a token can only
+/// be revoked by someone who holds it. Sessions which were authenticated
+/// with an access token are rejected once the token has been revoked.
+pub async fn token(kvs: &Datastore, token: &str) -> Result<(), Error> {
+	// Decode the token without verifying
+	let claims = verify::claims(token)?;
+	// Verify the token according to its kind
+	match claims.refresh {
+		Some(true) => {
+			verify::refresh(kvs, token).await?;
+		}
+		_ => {
+			verify::token(kvs, &mut Session::default(), token).await?;
+		}
+	}
+	// Tokens without an id can not be revoked
+	let jti = claims.jti.ok_or(Error::InvalidAuth)?;
+	// Add the token to the revocation list
+	let mut tx = kvs.transaction(Write, Optimistic).await?;
+	revoke(&mut tx, &jti, claims.exp).await?;
+	tx.commit().await?;
+	// Sessions on this node are rejected straight away
+	kvs.revocations().0.insert(jti, Checked::Revoked);
+	Ok(())
+}
+
+/// Check that the token used to establish a session has not been revoked
+pub(crate) async fn check(kvs: &Datastore, sess: &Session) -> Result<(), Error> {
+	if let Some(Value::Object(tk)) = &sess.tk {
+		if let Some(Value::Strand(jti)) = tk.get("jti") {
+			let interval = Duration::from_secs(*REVOCATION_CHECK_INTERVAL);
+			let revoked = match kvs.revocations().0.get(jti.as_str()) {
+				Some(Checked::Revoked) => true,
+				Some(Checked::Valid(at)) if at.elapsed() < interval => false,
+				_ => {
+					let revoked = is_revoked(kvs, &jti.0).await?;
+					let checked = match revoked {
+						true => Checked::Revoked,
+						false => Checked::Valid(Instant::now()),
+					};
+					kvs.revocations().0.insert(jti.0.clone(), checked);
+					revoked
+				}
+			};
+			if revoked {
+				return Err(Error::RevokedToken);
+			}
+		}
+	}
+	Ok(())
+}
+
+/// Check if the token with the specified id has been revoked
+pub(super) async fn is_revoked(kvs: &Datastore, jti: &str) -> Result<bool, Error> {
+	let mut tx = kvs.transaction(Read, Optimistic).await?;
+	let res = revoked(&mut tx, jti).await;
+	tx.cancel().await?;
+	res
+}
+
+/// Check if the token with the specified id has been revoked within a transaction
+pub(super) async fn revoked(tx: &mut Transaction, jti: &str) -> Result<bool, Error> {
+	let key = crate::key::root::rv::new(jti);
+	Ok(tx.get(key).await?.is_some())
+}
+
+/// Add the token with the specified id to the revocation list within a transaction.
+///
+/// The expiry of the token is stored with it, so that the token can be removed
+/// from the revocation list once it has expired. Tokens which do not expire
+/// are kept in the revocation list.
+pub(super) async fn revoke(tx: &mut Transaction, jti: &str, exp: Option<i64>) -> Result<(), Error> {
+	let key = crate::key::root::rv::new(jti);
+	let val = match exp {
+		Some(exp) => exp.to_be_bytes().to_vec(),
+		None => vec![],
+	};
+	tx.set(key, val).await
+}
+
+/// Remove the tokens which expired before the given timestamp from the revocation list
+pub(crate) async fn cleanup(tx: &mut Transaction, ts: u64) -> Result<(), Error> {
+	let end = crate::key::root::rv::suffix();
+	let mut beg = crate::key::root::rv::prefix();
+	loop {
+		let batch = tx.scan(beg.clone()..end.clone(), CLEANUP_BATCH_SIZE).await?;
+		for (k, v) in batch.iter() {
+			// Tokens which do not expire are never removed
+			if let Ok(exp) = <[u8; 8]>::try_from(v.as_slice()) {
+				if i64::from_be_bytes(exp) < ts as i64 {
+					tx.del(k.clone()).await?;
+				}
+			}
+		}
+		// Continue on from the last token in the batch
+		match batch.last() {
+			Some((k, _)) if batch.len() == CLEANUP_BATCH_SIZE as usize => {
+				beg = k.clone();
+				beg.push(0x00);
+			}
+			_ => return Ok(()),
+		}
+	}
+}
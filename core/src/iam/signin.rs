@@ -4,7 +4,7 @@ use crate::cnf::{INSECURE_FORWARD_RECORD_ACCESS_ERRORS, SERVER_NAME};
 use crate::dbs::Session;
 use crate::err::Error;
 use crate::iam::issue::{config, expiration};
-use crate::iam::token::{Claims, HEADER};
+use crate::iam::token::{Claims, Token, HEADER};
 use crate::iam::Auth;
 use crate::kvs::{Datastore, LockType::*, TransactionType::*};
 use crate::sql::AccessType;
@@ -19,7 +19,7 @@ pub async fn signin(
 	kvs: &Datastore,
 	session: &mut Session,
 	vars: Object,
-) -> Result<Option<String>, Error> {
+) -> Result<Option<Token>, Error> {
 	// Parse the specified variables
 	let ns = vars.get("NS").or_else(|| vars.get("ns"));
 	let db = vars.get("DB").or_else(|| vars.get("db"));
@@ -51,7 +51,9 @@ pub async fn signin(
 					let user = user.to_raw_string();
 					let pass = pass.to_raw_string();
 					// Attempt to signin to database
-					super::signin::db_user(kvs, session, ns, db, user, pass).await
+					super::signin::db_user(kvs, session, ns, db, user, pass)
+						.await
+						.map(|v| v.map(Token::from))
 				}
 				_ => Err(Error::MissingUserOrPass),
 			}
@@ -70,7 +72,9 @@ pub async fn signin(
 					let user = user.to_raw_string();
 					let pass = pass.to_raw_string();
 					// Attempt to signin to namespace
-					super::signin::ns_user(kvs, session, ns, user, pass).await
+					super::signin::ns_user(kvs, session, ns, user, pass)
+						.await
+						.map(|v| v.map(Token::from))
 				}
 				_ => Err(Error::MissingUserOrPass),
 			}
@@ -88,7 +92,9 @@ pub async fn signin(
 					let user = user.to_raw_string();
 					let pass = pass.to_raw_string();
 					// Attempt to signin to root
-					super::signin::root_user(kvs, session, user, pass).await
+					super::signin::root_user(kvs, session, user, pass)
+						.await
+						.map(|v| v.map(Token::from))
 				}
 				_ => Err(Error::MissingUserOrPass),
 			}
@@ -104,7 +110,7 @@ pub async fn db_access(
 	db: String,
 	ac: String,
 	vars: Object,
-) -> Result<Option<String>, Error> {
+) -> Result<Option<Token>, Error> {
 	// Create a new readonly transaction
 	let mut tx = kvs.transaction(Read, Optimistic).await?;
 	// Fetch the specified access method from storage
@@ -125,72 +131,96 @@ pub async fn db_access(
 						Some(iss) => iss,
 						_ => return Err(Error::AccessMethodMismatch),
 					};
-					match at.signin {
-						// This record access allows signin
-						Some(val) => {
-							// Setup the query params
-							let vars = Some(vars.0);
-							// Setup the system session for finding the signin record
-							let mut sess = Session::editor().with_ns(&ns).with_db(&db);
-							sess.ip.clone_from(&session.ip);
-							sess.or.clone_from(&session.or);
-							// Compute the value with the params
-							match kvs.evaluate(val, &sess, vars).await {
-								// The signin value succeeded
-								Ok(val) => {
-									match val.record() {
+					// Check if a refresh token is being exchanged
+					let refresh = match at.refresh {
+						true => vars.get("refresh").map(Value::to_raw_string),
+						false => None,
+					};
+					let rid = match refresh {
+						// Find the record which the refresh token was issued to
+						Some(tk) => super::refresh::exchange(kvs, &ns, &db, &ac, &tk).await?,
+						// Find the record with the signin query
+						None => match at.signin {
+							// This record access allows signin
+							Some(val) => {
+								// Setup the query params
+								let vars = Some(vars.0);
+								// Setup the system session for finding the signin record
+								let mut sess = Session::editor().with_ns(&ns).with_db(&db);
+								sess.ip.clone_from(&session.ip);
+								sess.or.clone_from(&session.or);
+								// Compute the value with the params
+								match kvs.evaluate(val, &sess, vars).await {
+									// The signin value succeeded
+									Ok(val) => match val.record() {
 										// There is a record returned
-										Some(rid) => {
-											// Create the authentication key
-											let key = config(iss.alg, iss.key)?;
-											// Create the authentication claim
-											let val = Claims {
-												iss: Some(SERVER_NAME.to_owned()),
-												iat: Some(Utc::now().timestamp()),
-												nbf: Some(Utc::now().timestamp()),
-												exp: expiration(av.duration.token)?,
-												jti: Some(Uuid::new_v4().to_string()),
-												ns: Some(ns.to_owned()),
-												db: Some(db.to_owned()),
-												ac: Some(ac.to_owned()),
-												id: Some(rid.to_raw()),
-												..Claims::default()
-											};
-											// Log the authenticated access method info
-											trace!("Signing in with access method `{}`", ac);
-											// Create the authentication token
-											let enc =
-												encode(&Header::new(iss.alg.into()), &val, &key);
-											// Set the authentication on the session
-											session.tk = Some(val.into());
-											session.ns = Some(ns.to_owned());
-											session.db = Some(db.to_owned());
-											session.ac = Some(ac.to_owned());
-											session.rd = Some(Value::from(rid.to_owned()));
-											session.exp = expiration(av.duration.session)?;
-											session.au = Arc::new(Auth::new(Actor::new(
-												rid.to_string(),
-												Default::default(),
-												Level::Record(ns, db, rid.to_string()),
-											)));
-											// Check the authentication token
-											match enc {
-												// The auth token was created successfully
-												Ok(tk) => Ok(Some(tk)),
-												_ => Err(Error::TokenMakingFailed),
-											}
+										Some(rid) => rid,
+										_ => return Err(Error::NoRecordFound),
+									},
+									Err(e) => {
+										return match e {
+											Error::Thrown(_) => Err(e),
+											e if *INSECURE_FORWARD_RECORD_ACCESS_ERRORS => Err(e),
+											_ => Err(Error::AccessRecordSigninQueryFailed),
 										}
-										_ => Err(Error::NoRecordFound),
 									}
 								}
-								Err(e) => match e {
-									Error::Thrown(_) => Err(e),
-									e if *INSECURE_FORWARD_RECORD_ACCESS_ERRORS => Err(e),
-									_ => Err(Error::AccessRecordSigninQueryFailed),
-								},
 							}
-						}
-						_ => Err(Error::AccessRecordNoSignin),
+							_ => return Err(Error::AccessRecordNoSignin),
+						},
+					};
+					// Create the authentication key
+					let key = config(iss.alg, iss.key)?;
+					// Create the authentication claim
+					let val = Claims {
+						iss: Some(SERVER_NAME.to_owned()),
+						iat: Some(Utc::now().timestamp()),
+						nbf: Some(Utc::now().timestamp()),
+						exp: expiration(av.duration.token)?,
+						jti: Some(Uuid::new_v4().to_string()),
+						ns: Some(ns.to_owned()),
+						db: Some(db.to_owned()),
+						ac: Some(ac.to_owned()),
+						id: Some(rid.to_raw()),
+						..Claims::default()
+					};
+					// Log the authenticated access method info
+					trace!("Signing in with access method `{}`", ac);
+					// Create the authentication token
+					let enc = encode(&Header::new(iss.alg.into()), &val, &key);
+					// Create the refresh token
+					let refresh = match at.refresh {
+						true => Some(super::refresh::issue(
+							iss.alg,
+							&key,
+							av.duration.grant,
+							&ns,
+							&db,
+							&ac,
+							&rid,
+						)?),
+						false => None,
+					};
+					// Set the authentication on the session
+					session.tk = Some(val.into());
+					session.ns = Some(ns.to_owned());
+					session.db = Some(db.to_owned());
+					session.ac = Some(ac.to_owned());
+					session.rd = Some(Value::from(rid.to_owned()));
+					session.exp = expiration(av.duration.session)?;
+					session.au = Arc::new(Auth::new(Actor::new(
+						rid.to_string(),
+						Default::default(),
+						Level::Record(ns, db, rid.to_string()),
+					)));
+					// Check the authentication token
+					match enc {
+						// The auth token was created successfully
+						Ok(tk) => Ok(Some(Token {
+							access: tk,
+							refresh,
+						})),
+						_ => Err(Error::TokenMakingFailed),
 					}
 				}
 				_ => Err(Error::AccessMethodMismatch),
@@ -584,7 +614,7 @@ dn/RsYEONbwQSjIfMPkvxF+8HQ==
 				let val = Validation::new(Algorithm::RS256);
 				// Check that token can be verified with the defined public key
 				let token_data = decode::<Claims>(
-					&tk,
+					&tk.access,
 					&DecodingKey::from_rsa_pem(public_key.as_ref()).unwrap(),
 					&val,
 				)
@@ -616,6 +646,117 @@ dn/RsYEONbwQSjIfMPkvxF+8HQ==
 		}
 	}
 
+	#[tokio::test]
+	async fn test_signin_record_with_refresh() {
+		let ds = Datastore::new("memory").await.unwrap();
+		let sess = Session::owner().with_ns("test").with_db("test");
+		ds.execute(
+			r#"
+			DEFINE ACCESS user ON DATABASE TYPE RECORD
+				SIGNIN (
+					SELECT * FROM user WHERE name = $user AND crypto::argon2::compare(pass, $pass)
+				)
+				WITH JWT ALGORITHM HS512 KEY 'secret'
+				WITH REFRESH
+				DURATION FOR GRANT 1w, FOR TOKEN 15m
+			;
+
+			CREATE user:test CONTENT {
+				name: 'user',
+				pass: crypto::argon2::generate('pass')
+			}
+			"#,
+			&sess,
+			None,
+		)
+		.await
+		.unwrap();
+
+		let signin = |vars: HashMap<&str, Value>| {
+			let ds = &ds;
+			async move {
+				let mut sess = Session::default().with_ns("test").with_db("test");
+				let res = db_access(
+					ds,
+					&mut sess,
+					"test".to_string(),
+					"test".to_string(),
+					"user".to_string(),
+					vars.into(),
+				)
+				.await;
+				res.map(|tk| (tk.unwrap(), sess))
+			}
+		};
+
+		// Signin with credentials issues both tokens
+		let mut vars: HashMap<&str, Value> = HashMap::new();
+		vars.insert("user", "user".into());
+		vars.insert("pass", "pass".into());
+		let (tk, _) = signin(vars).await.unwrap();
+		let refresh = tk.refresh.expect("A refresh token should be issued");
+		// The refresh token expires after the grant duration
+		let claims = decode::<Claims>(&refresh, &DecodingKey::from_secret(b"secret"), &{
+			let mut validation = Validation::new(Algorithm::HS512);
+			validation.required_spec_claims.remove("exp");
+			validation
+		})
+		.unwrap()
+		.claims;
+		assert_eq!(claims.refresh, Some(true));
+		let min_exp = (Utc::now() + Duration::weeks(1) - Duration::seconds(10)).timestamp();
+		let max_exp = (Utc::now() + Duration::weeks(1) + Duration::seconds(10)).timestamp();
+		let exp = claims.exp.unwrap();
+		assert!(exp > min_exp && exp < max_exp, "Refresh token should expire after a week");
+		// The refresh token can not be used to authenticate
+		let res = super::super::verify::token(&ds, &mut Session::default(), &refresh).await;
+		assert!(res.is_err(), "Unexpected success authenticating with refresh token: {:?}", res);
+
+		// Signin with the refresh token issues new tokens for the same record
+		let mut vars: HashMap<&str, Value> = HashMap::new();
+		vars.insert("refresh", refresh.clone().into());
+		let (tk, sess) = signin(vars).await.unwrap();
+		assert_eq!(sess.au.id(), "user:test");
+		assert!(tk.refresh.is_some_and(|v| v != refresh), "A new refresh token should be issued");
+
+		// A refresh token can only be used once
+		let mut vars: HashMap<&str, Value> = HashMap::new();
+		vars.insert("refresh", refresh.into());
+		let res = signin(vars).await;
+		assert!(res.is_err(), "Unexpected success reusing refresh token: {:?}", res);
+
+		// An expired refresh token is rejected
+		let claims = Claims {
+			iat: Some((Utc::now() - Duration::hours(2)).timestamp()),
+			nbf: Some((Utc::now() - Duration::hours(2)).timestamp()),
+			exp: Some((Utc::now() - Duration::hours(1)).timestamp()),
+			jti: Some(Uuid::new_v4().to_string()),
+			ns: Some("test".to_string()),
+			db: Some("test".to_string()),
+			ac: Some("user".to_string()),
+			id: Some("user:test".to_string()),
+			refresh: Some(true),
+			..Claims::default()
+		};
+		let expired = encode(&HEADER, &claims, &EncodingKey::from_secret(b"secret")).unwrap();
+		let mut vars: HashMap<&str, Value> = HashMap::new();
+		vars.insert("refresh", expired.into());
+		let res = signin(vars).await;
+		assert!(res.is_err(), "Unexpected success with expired refresh token: {:?}", res);
+
+		// A refresh token without an expiry is rejected
+		let claims = Claims {
+			exp: None,
+			jti: Some(Uuid::new_v4().to_string()),
+			..claims
+		};
+		let unbounded = encode(&HEADER, &claims, &EncodingKey::from_secret(b"secret")).unwrap();
+		let mut vars: HashMap<&str, Value> = HashMap::new();
+		vars.insert("refresh", unbounded.into());
+		let res = signin(vars).await;
+		assert!(res.is_err(), "Unexpected success with unbounded refresh token: {:?}", res);
+	}
+
 	#[tokio::test]
 	async fn test_signin_db_user() {
 		//
@@ -2,7 +2,7 @@ use crate::cnf::{INSECURE_FORWARD_RECORD_ACCESS_ERRORS, SERVER_NAME};
 use crate::dbs::Session;
 use crate::err::Error;
 use crate::iam::issue::{config, expiration};
-use crate::iam::token::Claims;
+use crate::iam::token::{Claims, Token};
 use crate::iam::Auth;
 use crate::iam::{Actor, Level};
 use crate::kvs::{Datastore, LockType::*, TransactionType::*};
@@ -18,7 +18,7 @@ pub async fn signup(
 	kvs: &Datastore,
 	session: &mut Session,
 	vars: Object,
-) -> Result<Option<String>, Error> {
+) -> Result<Option<Token>, Error> {
 	// Parse the specified variables
 	let ns = vars.get("NS").or_else(|| vars.get("ns"));
 	let db = vars.get("DB").or_else(|| vars.get("db"));
@@ -45,7 +45,7 @@ pub async fn db_access(
 	db: String,
 	ac: String,
 	vars: Object,
-) -> Result<Option<String>, Error> {
+) -> Result<Option<Token>, Error> {
 	// Create a new readonly transaction
 	let mut tx = kvs.transaction(Read, Optimistic).await?;
 	// Fetch the specified access method from storage
@@ -100,6 +100,19 @@ pub async fn db_access(
 											// Create the authentication token
 											let enc =
 												encode(&Header::new(iss.alg.into()), &val, &key);
+											// Create the refresh token
+											let refresh = match at.refresh {
+												true => Some(super::refresh::issue(
+													iss.alg,
+													&key,
+													av.duration.grant,
+													&ns,
+													&db,
+													&ac,
+													&rid,
+												)?),
+												false => None,
+											};
 											// Set the authentication on the session
 											session.tk = Some(val.into());
 											session.ns = Some(ns.to_owned());
@@ -115,7 +128,10 @@ pub async fn db_access(
 											// Check the authentication token
 											match enc {
 												// The auth token was created successfully
-												Ok(tk) => Ok(Some(tk)),
+												Ok(tk) => Ok(Some(Token {
+													access: tk,
+													refresh,
+												})),
 												_ => Err(Error::TokenMakingFailed),
 											}
 										}
@@ -384,7 +400,7 @@ dn/RsYEONbwQSjIfMPkvxF+8HQ==
 				let val = Validation::new(Algorithm::RS256);
 				// Check that token can be verified with the defined public key
 				let token_data = decode::<Claims>(
-					&tk,
+					&tk.access,
 					&DecodingKey::from_rsa_pem(public_key.as_ref()).unwrap(),
 					&val,
 				)
@@ -56,6 +56,11 @@ pub struct Claims {
 	#[serde(alias = "https://surrealdb.com/roles")]
 	#[serde(skip_serializing_if = "Option::is_none")]
 	pub roles: Option<Vec<String>>,
+	#[serde(alias = "rt")]
+	#[serde(alias = "RT")]
+	#[serde(rename = "RT")]
+	#[serde(skip_serializing_if = "Option::is_none")]
+	pub refresh: Option<bool>,
 
 	#[serde(flatten)]
 	#[serde(skip_serializing_if = "Option::is_none")]
@@ -106,6 +111,10 @@ impl From<Claims> for Value {
 		if let Some(role) = v.roles {
 			out.insert("RL".to_string(), role.into());
 		}
+		// Add RT field if set
+		if let Some(refresh) = v.refresh {
+			out.insert("RT".to_string(), refresh.into());
+		}
 		// Add custom claims if set
 		if let Some(custom_claims) = v.custom_claims {
 			for (claim, value) in custom_claims {
@@ -132,3 +141,51 @@ impl From<Claims> for Value {
 		out.into()
 	}
 }
+
+/// The tokens which are issued when signing in or signing up.
+///
+/// A refresh token is only issued by record access methods which are
+/// defined `WITH REFRESH`, and can be exchanged once for a new pair of
+/// tokens by signing in with the `refresh` variable.
+#[derive(Debug, Clone, PartialEq, Eq)]
+#[non_exhaustive]
+pub struct Token {
+	/// The token used to authenticate
+	pub access: String,
+	/// The token used to obtain a new access token
+	pub refresh: Option<String>,
+}
+
+impl From<String> for Token {
+	fn from(access: String) -> Token {
+		Token {
+			access,
+			refresh: None,
+		}
+	}
+}
+
+impl From<Token> for Value {
+	fn from(v: Token) -> Value {
+		match v.refresh {
+			// Only the access token was issued
+			None => v.access.into(),
+			// Both tokens were issued
+			Some(refresh) => {
+				let mut out = Object::default();
+				out.insert("token".to_string(), v.access.into());
+				out.insert("refresh".to_string(), refresh.into());
+				out.into()
+			}
+		}
+	}
+}
+
+impl From<Option<Token>> for Value {
+	fn from(v: Option<Token>) -> Value {
+		match v {
+			Some(v) => Value::from(v),
+			None => Value::None,
+		}
+	}
+}
@@ -2,7 +2,7 @@ use crate::dbs::Session;
 use crate::err::Error;
 #[cfg(feature = "jwks")]
 use crate::iam::jwks;
-use crate::iam::{issue::expiration, token::Claims, Actor, Auth, Level, Role};
+use crate::iam::{issue::expiration, revoke, token::Claims, Actor, Auth, Level, Role};
 use crate::kvs::{Datastore, LockType::*, TransactionType::*};
 use crate::sql::access_type::{AccessType, JwtAccessVerify};
 use crate::sql::{statements::DefineUserStatement, Algorithm, Value};
@@ -81,6 +81,57 @@ static DUD: Lazy<Validation> = Lazy::new(|| {
 	validation
 });
 
+/// Decode the claims of a token without verifying it
+pub(super) fn claims(token: &str) -> Result<Claims, Error> {
+	Ok(decode::<Claims>(token, &KEY, &DUD)?.claims)
+}
+
+/// Verify a refresh token issued by a record access method, returning its claims
+pub(super) async fn refresh(kvs: &Datastore, token: &str) -> Result<Claims, Error> {
+	// Log the authentication type
+	trace!("Attempting refresh token verification");
+	// Decode the token without verifying
+	match claims(token)? {
+		Claims {
+			ns: Some(ns),
+			db: Some(db),
+			ac: Some(ac),
+			id: Some(_),
+			refresh: Some(true),
+			..
+		} => {
+			// Create a new readonly transaction
+			let mut tx = kvs.transaction(Read, Optimistic).await?;
+			// Get the database access method
+			let de = tx.get_db_access(&ns, &db, &ac).await?;
+			// Ensure that the transaction is cancelled
+			tx.cancel().await?;
+			// Obtain the configuration to verify the token based on the access method
+			let cf = match de.kind {
+				AccessType::Record(at) if at.refresh => match at.jwt.verify {
+					JwtAccessVerify::Key(key) => config(key.alg, key.key)?,
+					// Refresh tokens are always signed with the issuer key
+					_ => return Err(Error::AccessMethodMismatch),
+				},
+				_ => return Err(Error::AccessMethodMismatch),
+			};
+			// Verify the token
+			let token_data = decode::<Claims>(token, &cf.0, &cf.1)?;
+			// Check if the refresh token has been revoked
+			if let Some(jti) = &token_data.claims.jti {
+				if revoke::is_revoked(kvs, jti).await? {
+					trace!("The refresh token has been revoked");
+					return Err(Error::InvalidAuth);
+				}
+			}
+			// Log the success
+			debug!("Verified refresh token for access method `{}`", ac);
+			Ok(token_data.claims)
+		}
+		_ => Err(Error::InvalidAuth),
+	}
+}
+
 pub async fn basic(
 	kvs: &Datastore,
 	session: &mut Session,
@@ -135,6 +186,18 @@ pub async fn token(kvs: &Datastore, session: &mut Session, token: &str) -> Resul
 	let token_data = decode::<Claims>(token, &KEY, &DUD)?;
 	// Convert the token to a SurrealQL object value
 	let value = token_data.claims.clone().into();
+	// Refresh tokens can not be used to authenticate
+	if token_data.claims.refresh == Some(true) {
+		trace!("The authentication token is a refresh token");
+		return Err(Error::InvalidAuth);
+	}
+	// Check if the auth token has been revoked
+	if let Some(jti) = &token_data.claims.jti {
+		if revoke::is_revoked(kvs, jti).await? {
+			trace!("The authentication token has been revoked");
+			return Err(Error::InvalidAuth);
+		}
+	}
 	// Check if the auth token can be used
 	if let Some(nbf) = token_data.claims.nbf {
 		if nbf > Utc::now().timestamp() {
@@ -1653,4 +1716,70 @@ mod tests {
 
 		assert!(res.is_err(), "Unexpected success signing in with expired token: {:?}", res);
 	}
+
+	#[tokio::test]
+	async fn test_revoked_token() {
+		let secret = "jwt_secret";
+		let key = EncodingKey::from_secret(secret.as_ref());
+		let claims = Claims {
+			iss: Some("surrealdb-test".to_string()),
+			iat: Some(Utc::now().timestamp()),
+			nbf: Some(Utc::now().timestamp()),
+			exp: Some((Utc::now() + Duration::hours(1)).timestamp()),
+			jti: Some("revoked".to_string()),
+			ns: Some("test".to_string()),
+			db: Some("test".to_string()),
+			ac: Some("token".to_string()),
+			id: Some("user:test".to_string()),
+			..Claims::default()
+		};
+
+		let ds = Datastore::new("memory").await.unwrap();
+		let sess = Session::owner().with_ns("test").with_db("test");
+		ds.execute(
+			format!(
+				r#"
+			DEFINE ACCESS token ON DATABASE TYPE RECORD
+				WITH JWT ALGORITHM HS512 KEY '{secret}'
+				DURATION FOR SESSION 30d;
+
+			CREATE user:test;
+			"#
+			)
+			.as_str(),
+			&sess,
+			None,
+		)
+		.await
+		.unwrap();
+
+		// Create the token
+		let enc = encode(&HEADER, &claims, &key).unwrap();
+		// Signin with the token
+		let mut sess = Session::default();
+		token(&ds, &mut sess, &enc).await.unwrap();
+		assert!(ds.execute("SELECT * FROM user", &sess, None).await.is_ok());
+		// Revoke the token
+		revoke::token(&ds, &enc).await.unwrap();
+		// The session which used the token is rejected
+		let res = ds.execute("SELECT * FROM user", &sess, None).await;
+		assert!(matches!(res, Err(Error::RevokedToken)), "Unexpected result: {:?}", res);
+		// The token can no longer be used to authenticate
+		let res = token(&ds, &mut Session::default(), &enc).await;
+		assert!(res.is_err(), "Unexpected success signing in with revoked token: {:?}", res);
+		// The token is kept in the revocation list until it expires
+		let now = Utc::now().timestamp() as u64;
+		ds.garbage_collect_revoked_tokens(now).await.unwrap();
+		assert!(revoke::is_revoked(&ds, "revoked").await.unwrap());
+		ds.garbage_collect_revoked_tokens(now + 7200).await.unwrap();
+		assert!(!revoke::is_revoked(&ds, "revoked").await.unwrap());
+		// Other tokens can still be used to authenticate
+		let claims = Claims {
+			jti: Some("valid".to_string()),
+			..claims
+		};
+		let enc = encode(&HEADER, &claims, &key).unwrap();
+		let res = token(&ds, &mut Session::default(), &enc).await;
+		assert!(res.is_ok(), "Failed to signin with token: {:?}", res);
+	}
 }
@@ -27,7 +27,7 @@ use crate::idx::IndexKeyBase;
 use crate::kvs::{Key, TransactionType};
 use crate::sql::index::{Distance, Index};
 use crate::sql::statements::DefineIndexStatement;
-use crate::sql::{Cond, Expression, Idiom, Number, Object, Table, Thing, Value};
+use crate::sql::{Cond, Expression, Idiom, Number, Object, Operator, Table, Thing, Value};
 use reblessive::tree::Stk;
 use std::collections::hash_map::Entry;
 use std::collections::{HashMap, HashSet, VecDeque};
@@ -321,6 +321,42 @@ impl QueryExecutor {
 		}
 	}
 
+	/// Returns the indexed idiom if the iterator alone satisfies the whole
+	/// condition, so that the records it returns need not be checked again.
+	pub(crate) fn is_covering(&self, irf: IteratorRef, cond: &Cond) -> Option<&Idiom> {
+		match self.0.it_entries.get(irf as usize) {
+			Some(IteratorEntry::Single(e, io)) => match (io.op(), &cond.0) {
+				(
+					IndexOperator::Equality(_) | IndexOperator::Exactness(_),
+					Value::Expression(c),
+				) if c.as_ref().eq(e.as_ref())
+					&& matches!(e.operator(), Operator::Equal | Operator::Exact) =>
+				{
+					Some(io.id_ref())
+				}
+				_ => None,
+			},
+			Some(IteratorEntry::Range(es, ir, ..)) if Self::is_range_condition(&cond.0, es) => {
+				self.get_index_def(*ir).and_then(|ix| ix.cols.first())
+			}
+			_ => None,
+		}
+	}
+
+	fn is_range_condition(v: &Value, es: &HashSet<Arc<Expression>>) -> bool {
+		match v {
+			Value::Expression(e) => match e.as_ref() {
+				Expression::Binary {
+					l,
+					o: Operator::And,
+					r,
+				} => Self::is_range_condition(l, es) && Self::is_range_condition(r, es),
+				e => es.contains(e),
+			},
+			_ => false,
+		}
+	}
+
 	pub(crate) fn explain(&self, itr: IteratorRef) -> Value {
 		match self.0.it_entries.get(itr as usize) {
 			Some(ie) => ie.explain(self.0.index_definitions.as_slice()),
@@ -393,6 +429,16 @@ impl QueryExecutor {
 					value,
 				)))
 			}
+			IndexOperator::Prefix(value) => {
+				Some(ThingIterator::IndexEqual(IndexEqualThingIterator::prefix(
+					irf,
+					opt.ns()?,
+					opt.db()?,
+					&ix.what,
+					&ix.name,
+					value,
+				)))
+			}
 			IndexOperator::Union(value) => Some(ThingIterator::IndexUnion(
 				IndexUnionThingIterator::new(irf, opt.ns()?, opt.db()?, &ix.what, &ix.name, value),
 			)),
@@ -460,6 +506,16 @@ impl QueryExecutor {
 					value,
 				)))
 			}
+			IndexOperator::Prefix(value) => {
+				Some(ThingIterator::IndexEqual(IndexEqualThingIterator::prefix(
+					irf,
+					opt.ns()?,
+					opt.db()?,
+					&ix.what,
+					&ix.name,
+					value,
+				)))
+			}
 			IndexOperator::Union(value) => Some(ThingIterator::UniqueUnion(
 				UniqueUnionThingIterator::new(irf, opt, ix, value)?,
 			)),
@@ -163,6 +163,25 @@ impl IndexEqualThingIterator {
 		}
 	}
 
+	/// Iterates over the entries of a compound index whose leading column matches the value
+	pub(super) fn prefix(
+		irf: IteratorRef,
+		ns: &str,
+		db: &str,
+		ix_what: &Ident,
+		ix_name: &Ident,
+		v: &Value,
+	) -> Self {
+		let a = Array::from(vec![v.clone()]);
+		let beg = Index::prefix_ids_composite_beg(ns, db, ix_what, ix_name, &a);
+		let end = Index::prefix_ids_composite_end(ns, db, ix_what, ix_name, &a);
+		Self {
+			irf,
+			beg,
+			end,
+		}
+	}
+
 	async fn next_scan<B: IteratorBatch>(
 		tx: &mut kvs::Transaction,
 		irf: IteratorRef,
@@ -53,6 +53,13 @@ impl<'a> QueryPlanner<'a> {
 		t: Table,
 		it: &mut Iterator,
 	) -> Result<(), Error> {
+		// Check that every hinted index exists on the table
+		if let Some(With::Index(ixs)) = self.with {
+			let mut tx = ctx.tx_lock().await;
+			for ix in ixs {
+				tx.get_and_cache_tb_index(self.opt.ns()?, self.opt.db()?, &t.0, ix).await?;
+			}
+		}
 		let mut is_table_iterator = false;
 		let mut is_knn = false;
 		match Tree::build(stk, ctx, self.opt, &t, self.cond, self.with).await? {
@@ -34,8 +34,15 @@ impl PlanBuilder {
 		with: &Option<With>,
 		with_indexes: Vec<IndexRef>,
 	) -> Result<Plan, Error> {
-		if let Some(With::NoIndex) = with {
-			return Ok(Plan::TableIterator(Some("WITH NOINDEX".to_string())));
+		match with {
+			Some(With::NoIndex) => {
+				return Ok(Plan::TableIterator(Some("WITH NOINDEX".to_string())));
+			}
+			// None of the hinted indexes can be used with this condition
+			Some(With::Index(_)) if with_indexes.is_empty() => {
+				return Ok(Plan::TableIterator(Some("WITH INDEX NOT APPLICABLE".to_string())));
+			}
+			_ => (),
 		}
 		let mut b = PlanBuilder {
 			has_indexes: false,
@@ -176,6 +183,8 @@ pub(super) struct IndexOption {
 pub(super) enum IndexOperator {
 	Equality(Value),
 	Exactness(Value),
+	/// Matches the leading column of a compound index
+	Prefix(Value),
 	Union(Array),
 	Join(Vec<IndexOption>),
 	RangePart(Operator, Value),
@@ -200,7 +209,7 @@ impl IndexOption {
 	}
 
 	pub(super) fn require_distinct(&self) -> bool {
-		matches!(self.op.as_ref(), IndexOperator::Union(_))
+		matches!(self.op.as_ref(), IndexOperator::Union(_) | IndexOperator::Prefix(_))
 	}
 
 	pub(super) fn ix_ref(&self) -> IndexRef {
@@ -242,6 +251,10 @@ impl IndexOption {
 				e.insert("operator", Value::from(Operator::Exact.to_string()));
 				e.insert("value", Self::reduce_array(v));
 			}
+			IndexOperator::Prefix(v) => {
+				e.insert("operator", Value::from("prefix"));
+				e.insert("value", v.to_owned());
+			}
 			IndexOperator::Union(a) => {
 				e.insert("operator", Value::from("union"));
 				e.insert("value", Value::Array(a.clone()));
@@ -127,7 +127,7 @@ impl<'a> KnnConditionRewriter<'a> {
 			| Part::Field(_)
 			| Part::Index(_) => Some(p.clone()),
 			Part::Where(v) => self.eval_value(v).map(Part::Where),
-			Part::Graph(_) => None,
+			Part::Graph(_) | Part::Recurse(..) => None,
 			Part::Value(v) => self.eval_value(v).map(Part::Value),
 			Part::Start(v) => self.eval_value(v).map(Part::Start),
 			Part::Method(n, p) => self.eval_values(p).map(|v| Part::Method(n.clone(), v)),
@@ -225,7 +225,9 @@ impl<'a> TreeBuilder<'a> {
 		}
 		let mut irs = Vec::new();
 		for ix in schema.indexes.iter() {
-			if ix.cols.len() == 1 && ix.cols[0].eq(i) {
+			// A compound index can be used when the idiom is its leading column
+			let is_prefix = ix.cols.len() > 1 && matches!(ix.index, Index::Idx | Index::Uniq);
+			if (ix.cols.len() == 1 || is_prefix) && ix.cols[0].eq(i) {
 				let ixr = self.index_map.definitions.len() as IndexRef;
 				if let Some(With::Index(ixs)) = self.with {
 					if ixs.contains(&ix.name.0) {
@@ -399,6 +401,9 @@ impl<'a> TreeBuilder<'a> {
 		for ir in irs {
 			if let Some(ix) = self.index_map.definitions.get(*ir as usize) {
 				let op = match &ix.index {
+					Index::Idx | Index::Uniq if ix.cols.len() > 1 => {
+						Self::eval_prefix_operator(op, n)
+					}
 					Index::Idx => Self::eval_index_operator(op, n, p),
 					Index::Uniq => Self::eval_index_operator(op, n, p),
 					Index::Search {
@@ -421,7 +426,7 @@ impl<'a> TreeBuilder<'a> {
 		for ir in irs {
 			if let Some(ix) = self.index_map.definitions.get(*ir as usize) {
 				match &ix.index {
-					Index::Idx | Index::Uniq => return Some(*ir),
+					Index::Idx | Index::Uniq if ix.cols.len() == 1 => return Some(*ir),
 					_ => {}
 				};
 			}
@@ -489,6 +494,19 @@ impl<'a> TreeBuilder<'a> {
 		Ok(())
 	}
 
+	fn eval_prefix_operator(op: &Operator, n: &Node) -> Option<IndexOperator> {
+		if let Some(v) = n.is_computed() {
+			// Arrays are indexed per element, and unique indexes skip missing values
+			if v.is_array() || v.is_none_or_null() {
+				return None;
+			}
+			if let Operator::Equal | Operator::Exact = op {
+				return Some(IndexOperator::Prefix(v.clone()));
+			}
+		}
+		None
+	}
+
 	fn eval_index_operator(op: &Operator, n: &Node, p: IdiomPosition) -> Option<IndexOperator> {
 		if let Some(v) = n.is_computed() {
 			match (op, v, p) {
@@ -6,6 +6,8 @@ pub mod ml;
 pub mod pa;
 pub mod tb;
 pub mod ti;
+pub mod tk;
+pub mod tr;
 pub mod ts;
 pub mod us;
 pub mod vs;
@@ -0,0 +1,64 @@
+//! Stores the schedule of a DEFINE TASK definition
+use crate::key::error::KeyCategory;
+use crate::key::key_req::KeyRequirements;
+use derive::Key;
+use serde::{Deserialize, Serialize};
+
+#[derive(Clone, Debug, Eq, PartialEq, PartialOrd, Serialize, Deserialize, Key)]
+#[non_exhaustive]
+pub struct Tr<'a> {
+	__: u8,
+	_a: u8,
+	pub ns: &'a str,
+	_b: u8,
+	pub db: &'a str,
+	_c: u8,
+	_d: u8,
+	_e: u8,
+	pub tk: &'a str,
+}
+
+pub fn new<'a>(ns: &'a str, db: &'a str, tk: &'a str) -> Tr<'a> {
+	Tr::new(ns, db, tk)
+}
+
+impl KeyRequirements for Tr<'_> {
+	fn key_category(&self) -> KeyCategory {
+		KeyCategory::DatabaseTaskRun
+	}
+}
+
+impl<'a> Tr<'a> {
+	pub fn new(ns: &'a str, db: &'a str, tk: &'a str) -> Self {
+		Self {
+			__: b'/',
+			_a: b'*',
+			ns,
+			_b: b'*',
+			db,
+			_c: b'!',
+			_d: b't',
+			_e: b'r',
+			tk,
+		}
+	}
+}
+
+#[cfg(test)]
+mod tests {
+	#[test]
+	fn key() {
+		use super::*;
+		#[rustfmt::skip]
+		let val = Tr::new(
+			"testns",
+			"testdb",
+			"testtk",
+		);
+		let enc = Tr::encode(&val).unwrap();
+		assert_eq!(enc, b"/*testns\0*testdb\0!trtesttk\0");
+
+		let dec = Tr::decode(&enc).unwrap();
+		assert_eq!(val, dec);
+	}
+}
@@ -10,6 +10,8 @@ pub enum KeyCategory {
 	Root,
 	/// crate::key::root::ac                 /!ac{ac}
 	Access,
+	/// crate::key::root::cs                 /!cs
+	CursorSecret,
 	/// crate::key::root::hb                 /!hb{ts}/{nd}
 	Heartbeat,
 	/// crate::key::root::nd                 /!nd{nd}
@@ -18,6 +20,8 @@ pub enum KeyCategory {
 	NamespaceIdentifier,
 	/// crate::key::root::ns                 /!ns{ns}
 	Namespace,
+	/// crate::key::root::rv                 /!rv{rv}
+	RevokedToken,
 	/// crate::key::root::us                 /!us{us}
 	User,
 	///
@@ -57,6 +61,10 @@ pub enum KeyCategory {
 	DatabaseTable,
 	/// crate::key::database::ti             /+{ns id}*{db id}!ti
 	DatabaseTableIdentifier,
+	/// crate::key::database::tk             /*{ns}*{db}!tk{tk}
+	DatabaseTask,
+	/// crate::key::database::tr             /*{ns}*{db}!tr{tk}
+	DatabaseTaskRun,
 	/// crate::key::database::ts             /*{ns}*{db}!ts{ts}
 	DatabaseTimestamp,
 	/// crate::key::database::us             /*{ns}*{db}!us{us}
@@ -66,6 +74,8 @@ pub enum KeyCategory {
 	///
 	/// crate::key::table::all               /*{ns}*{db}*{tb}
 	TableRoot,
+	/// crate::key::table::cv                /*{ns}*{db}*{tb}!cv
+	TableCacheVersion,
 	/// crate::key::table::ev                /*{ns}*{db}*{tb}!ev{ev}
 	TableEvent,
 	/// crate::key::table::fd                /*{ns}*{db}*{tb}!fd{fd}
@@ -76,6 +86,8 @@ pub enum KeyCategory {
 	IndexDefinition,
 	/// crate::key::table::lq                /*{ns}*{db}*{tb}!lq{lq}
 	TableLiveQuery,
+	/// crate::key::table::sq                /*{ns}*{db}*{tb}!sq
+	TableSequence,
 	///
 	/// crate::key::index::all               /*{ns}*{db}*{tb}+{ix}
 	IndexRoot,
@@ -120,10 +132,12 @@ impl Display for KeyCategory {
 			KeyCategory::Unknown => "Unknown",
 			KeyCategory::Root => "Root",
 			KeyCategory::Access => "Access",
+			KeyCategory::CursorSecret => "CursorSecret",
 			KeyCategory::Heartbeat => "Heartbeat",
 			KeyCategory::Node => "Node",
 			KeyCategory::NamespaceIdentifier => "NamespaceIdentifier",
 			KeyCategory::Namespace => "Namespace",
+			KeyCategory::RevokedToken => "RevokedToken",
 			KeyCategory::User => "User",
 			KeyCategory::NodeRoot => "NodeRoot",
 			KeyCategory::NodeLiveQuery => "NodeLiveQuery",
@@ -142,15 +156,19 @@ impl Display for KeyCategory {
 			KeyCategory::DatabaseParameter => "DatabaseParameter",
 			KeyCategory::DatabaseTable => "DatabaseTable",
 			KeyCategory::DatabaseTableIdentifier => "DatabaseTableIdentifier",
+			KeyCategory::DatabaseTask => "DatabaseTask",
+			KeyCategory::DatabaseTaskRun => "DatabaseTaskRun",
 			KeyCategory::DatabaseTimestamp => "DatabaseTimestamp",
 			KeyCategory::DatabaseUser => "DatabaseUser",
 			KeyCategory::DatabaseVersionstamp => "DatabaseVersionstamp",
 			KeyCategory::TableRoot => "TableRoot",
+			KeyCategory::TableCacheVersion => "TableCacheVersion",
 			KeyCategory::TableEvent => "TableEvent",
 			KeyCategory::TableField => "TableField",
 			KeyCategory::TableView => "TableView",
 			KeyCategory::IndexDefinition => "IndexDefinition",
 			KeyCategory::TableLiveQuery => "TableLiveQuery",
+			KeyCategory::TableSequence => "TableSequence",
 			KeyCategory::IndexRoot => "IndexRoot",
 			KeyCategory::IndexTermDocList => "IndexTermDocList",
 			KeyCategory::IndexBTreeNode => "IndexBTreeNode",
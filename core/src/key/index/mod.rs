@@ -169,6 +169,27 @@ impl<'a> Index<'a> {
 		beg.extend_from_slice(&[0xff]);
 		beg
 	}
+
+	fn prefix_ids_composite(ns: &str, db: &str, tb: &str, ix: &str, fd: &Array) -> Vec<u8> {
+		let mut beg = Self::prefix_ids(ns, db, tb, ix, fd);
+		// Remove the array terminator, so that the remaining columns can follow
+		beg.pop();
+		beg
+	}
+
+	/// The first key of a compound index whose leading columns match `fd`
+	pub fn prefix_ids_composite_beg(ns: &str, db: &str, tb: &str, ix: &str, fd: &Array) -> Vec<u8> {
+		let mut beg = Self::prefix_ids_composite(ns, db, tb, ix, fd);
+		beg.extend_from_slice(&[0x00]);
+		beg
+	}
+
+	/// The last key of a compound index whose leading columns match `fd`
+	pub fn prefix_ids_composite_end(ns: &str, db: &str, tb: &str, ix: &str, fd: &Array) -> Vec<u8> {
+		let mut beg = Self::prefix_ids_composite(ns, db, tb, ix, fd);
+		beg.extend_from_slice(&[0xff]);
+		beg
+	}
 }
 
 #[cfg(test)]
@@ -189,4 +210,20 @@ mod tests {
 		let dec = Index::decode(&enc).unwrap();
 		assert_eq!(val, dec);
 	}
+
+	#[test]
+	fn composite_prefix() {
+		use super::*;
+		let fd = vec!["testfd1"].into();
+		let beg = Index::prefix_ids_composite_beg("testns", "testdb", "testtb", "testix", &fd);
+		assert_eq!(beg, b"/*testns\0*testdb\0*testtb\0+testix\0*\0\0\0\x04testfd1\0\0");
+		let end = Index::prefix_ids_composite_end("testns", "testdb", "testtb", "testix", &fd);
+		assert_eq!(end, b"/*testns\0*testdb\0*testtb\0+testix\0*\0\0\0\x04testfd1\0\xff");
+		// Every key of the compound index starting with the value is in the range
+		let fd = vec!["testfd1", "testfd2"].into();
+		let id = "testid".into();
+		let key = Index::new("testns", "testdb", "testtb", "testix", &fd, Some(&id));
+		let key = Index::encode(&key).unwrap();
+		assert!(beg <= key && key < end);
+	}
 }
@@ -1,10 +1,12 @@
 //! How the keys are structured in the key value store
 ///
 /// crate::key::root::all                /
+/// crate::key::root::cs                 /!cs
 /// crate::key::root::hb                 /!hb{ts}/{nd}
 /// crate::key::root::nd                 /!nd{nd}
 /// crate::key::root::ni                 /!ni
 /// crate::key::root::ns                 /!ns{ns}
+/// crate::key::root::rv                 /!rv{rv}
 /// crate::key::root::us                 /!us{us}
 ///
 /// crate::key::node::all                /${nd}
@@ -25,16 +27,20 @@
 /// crate::key::database::pa             /*{ns}*{db}!pa{pa}
 /// crate::key::database::tb             /*{ns}*{db}!tb{tb}
 /// crate::key::database::ti             /+{ns id}*{db id}!ti
+/// crate::key::database::tk             /*{ns}*{db}!tk{tk}
+/// crate::key::database::tr             /*{ns}*{db}!tr{tk}
 /// crate::key::database::ts             /*{ns}*{db}!ts{ts}
 /// crate::key::database::us             /*{ns}*{db}!us{us}
 /// crate::key::database::vs             /*{ns}*{db}!vs
 ///
 /// crate::key::table::all               /*{ns}*{db}*{tb}
+/// crate::key::table::cv                /*{ns}*{db}*{tb}!cv
 /// crate::key::table::ev                /*{ns}*{db}*{tb}!ev{ev}
 /// crate::key::table::fd                /*{ns}*{db}*{tb}!fd{fd}
 /// crate::key::table::ft                /*{ns}*{db}*{tb}!ft{ft}
 /// crate::key::table::ix                /*{ns}*{db}*{tb}!ix{ix}
 /// crate::key::table::lq                /*{ns}*{db}*{tb}!lq{lq}
+/// crate::key::table::sq                /*{ns}*{db}*{tb}!sq
 ///
 /// crate::key::index::all               /*{ns}*{db}*{tb}+{ix}
 /// crate::key::index::bc                /*{ns}*{db}*{tb}+{ix}!bc{id}
@@ -0,0 +1,54 @@
+//! Stores the key used to sign pagination cursors
+use crate::key::error::KeyCategory;
+use crate::key::key_req::KeyRequirements;
+use derive::Key;
+use serde::{Deserialize, Serialize};
+
+#[derive(Clone, Debug, Eq, PartialEq, PartialOrd, Serialize, Deserialize, Key)]
+#[non_exhaustive]
+pub struct Cs {
+	__: u8,
+	_a: u8,
+	_b: u8,
+	_c: u8,
+}
+
+pub fn new() -> Cs {
+	Cs::new()
+}
+
+impl Default for Cs {
+	fn default() -> Self {
+		Self::new()
+	}
+}
+
+impl KeyRequirements for Cs {
+	fn key_category(&self) -> KeyCategory {
+		KeyCategory::CursorSecret
+	}
+}
+
+impl Cs {
+	pub fn new() -> Self {
+		Self {
+			__: b'/',
+			_a: b'!',
+			_b: b'c',
+			_c: b's',
+		}
+	}
+}
+
+#[cfg(test)]
+mod tests {
+	#[test]
+	fn key() {
+		use super::*;
+		let val = Cs::new();
+		let enc = Cs::encode(&val).unwrap();
+		assert_eq!(enc, b"/!cs");
+		let dec = Cs::decode(&enc).unwrap();
+		assert_eq!(val, dec);
+	}
+}
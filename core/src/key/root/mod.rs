@@ -1,7 +1,9 @@
 pub mod ac;
 pub mod all;
+pub mod cs;
 pub mod hb;
 pub mod nd;
 pub mod ni;
 pub mod ns;
+pub mod rv;
 pub mod us;
@@ -0,0 +1,67 @@
+//! Stores the version of a table, which changes whenever the table is written to
+use crate::key::error::KeyCategory;
+use crate::key::key_req::KeyRequirements;
+use derive::Key;
+use serde::{Deserialize, Serialize};
+
+// Cv stands for Table Cache Version
+#[derive(Clone, Debug, Eq, PartialEq, PartialOrd, Serialize, Deserialize, Key)]
+#[non_exhaustive]
+pub struct Cv<'a> {
+	__: u8,
+	_a: u8,
+	pub ns: &'a str,
+	_b: u8,
+	pub db: &'a str,
+	_c: u8,
+	pub tb: &'a str,
+	_d: u8,
+	_e: u8,
+	_f: u8,
+}
+
+pub fn new<'a>(ns: &'a str, db: &'a str, tb: &'a str) -> Cv<'a> {
+	Cv::new(ns, db, tb)
+}
+
+impl KeyRequirements for Cv<'_> {
+	fn key_category(&self) -> KeyCategory {
+		KeyCategory::TableCacheVersion
+	}
+}
+
+impl<'a> Cv<'a> {
+	pub fn new(ns: &'a str, db: &'a str, tb: &'a str) -> Self {
+		Self {
+			__: b'/',
+			_a: b'*',
+			ns,
+			_b: b'*',
+			db,
+			_c: b'*',
+			tb,
+			_d: b'!',
+			_e: b'c',
+			_f: b'v',
+		}
+	}
+}
+
+#[cfg(test)]
+mod tests {
+	#[test]
+	fn key() {
+		use super::*;
+		#[rustfmt::skip]
+		let val = Cv::new(
+			"testns",
+			"testdb",
+			"testtb",
+		);
+		let enc = Cv::encode(&val).unwrap();
+		assert_eq!(enc, b"/*testns\0*testdb\0*testtb\0!cv");
+
+		let dec = Cv::decode(&enc).unwrap();
+		assert_eq!(val, dec);
+	}
+}
@@ -1,6 +1,8 @@
 pub mod all;
+pub mod cv;
 pub mod ev;
 pub mod fd;
 pub mod ft;
 pub mod ix;
 pub mod lq;
+pub mod sq;
@@ -0,0 +1,67 @@
+//! Stores the counter used to generate incrementing record ids for a table
+use crate::key::error::KeyCategory;
+use crate::key::key_req::KeyRequirements;
+use derive::Key;
+use serde::{Deserialize, Serialize};
+
+// Sq stands for Table Sequence
+#[derive(Clone, Debug, Eq, PartialEq, PartialOrd, Serialize, Deserialize, Key)]
+#[non_exhaustive]
+pub struct Sq<'a> {
+	__: u8,
+	_a: u8,
+	pub ns: &'a str,
+	_b: u8,
+	pub db: &'a str,
+	_c: u8,
+	pub tb: &'a str,
+	_d: u8,
+	_e: u8,
+	_f: u8,
+}
+
+pub fn new<'a>(ns: &'a str, db: &'a str, tb: &'a str) -> Sq<'a> {
+	Sq::new(ns, db, tb)
+}
+
+impl KeyRequirements for Sq<'_> {
+	fn key_category(&self) -> KeyCategory {
+		KeyCategory::TableSequence
+	}
+}
+
+impl<'a> Sq<'a> {
+	pub fn new(ns: &'a str, db: &'a str, tb: &'a str) -> Self {
+		Self {
+			__: b'/',
+			_a: b'*',
+			ns,
+			_b: b'*',
+			db,
+			_c: b'*',
+			tb,
+			_d: b'!',
+			_e: b's',
+			_f: b'q',
+		}
+	}
+}
+
+#[cfg(test)]
+mod tests {
+	#[test]
+	fn key() {
+		use super::*;
+		#[rustfmt::skip]
+		let val = Sq::new(
+			"testns",
+			"testdb",
+			"testtb",
+		);
+		let enc = Sq::encode(&val).unwrap();
+		assert_eq!(enc, b"/*testns\0*testdb\0*testtb\0!sq");
+
+		let dec = Sq::decode(&enc).unwrap();
+		assert_eq!(val, dec);
+	}
+}
@@ -11,6 +11,7 @@ use crate::sql::statements::DefineModelStatement;
 use crate::sql::statements::DefineNamespaceStatement;
 use crate::sql::statements::DefineParamStatement;
 use crate::sql::statements::DefineTableStatement;
+use crate::sql::statements::DefineTaskStatement;
 use crate::sql::statements::DefineUserStatement;
 use crate::sql::statements::LiveStatement;
 use std::collections::HashMap;
@@ -44,6 +45,7 @@ pub enum Entry {
 	Nus(Arc<[DefineUserStatement]>),
 	Pas(Arc<[DefineParamStatement]>),
 	Tbs(Arc<[DefineTableStatement]>),
+	Tks(Arc<[DefineTaskStatement]>),
 	// Sequences
 	Seq(U32),
 }
@@ -0,0 +1,39 @@
+//! Simulated transaction conflicts, which are only available in tests.
+use std::cell::Cell;
+
+thread_local! {
+	// The number of commits which fail with a simulated conflict
+	static CONFLICTS: Cell<u32> = const { Cell::new(0) };
+}
+
+/// Makes the next commits on this thread fail as if they conflicted with
+/// another transaction, until the simulation is dropped.
+///
+/// Tests run each datastore on their own thread, so a simulation does
+/// not cause conflicts in other tests which are running at the same time.
+#[must_use]
+pub(crate) struct SimulatedConflicts;
+
+impl SimulatedConflicts {
+	pub(crate) fn new(commits: u32) -> Self {
+		CONFLICTS.with(|c| c.set(commits));
+		Self
+	}
+}
+
+impl Drop for SimulatedConflicts {
+	fn drop(&mut self) {
+		CONFLICTS.with(|c| c.set(0));
+	}
+}
+
+/// Check if this commit should fail with a simulated conflict
+pub(super) fn take() -> bool {
+	CONFLICTS.with(|c| match c.get() {
+		0 => false,
+		n => {
+			c.set(n - 1);
+			true
+		}
+	})
+}
@@ -0,0 +1,333 @@
+use std::collections::BTreeMap;
+use std::mem;
+
+use crate::sql::{Datetime, Id, Kind, Number, Object, Thing, Value};
+
+/// Options for reading comma-separated values
+#[derive(Clone, Debug)]
+#[non_exhaustive]
+pub struct CsvOptions {
+	/// The character which separates the fields in a row
+	pub delimiter: char,
+	/// The character which surrounds a quoted field
+	pub quote: char,
+	/// Whether the first row contains the column names.
+	///
+	/// When there is no header row, the columns are named `col1`,
+	/// `col2`, and so on, in the order in which they appear.
+	pub header: bool,
+	/// The types which the values in each column are converted to.
+	///
+	/// The type of any column which is not specified is inferred from
+	/// each of its values, so that numbers, booleans, and datetimes are
+	/// imported as such, and anything else is imported as a string.
+	pub types: BTreeMap<String, Kind>,
+}
+
+impl Default for CsvOptions {
+	fn default() -> Self {
+		Self {
+			delimiter: ',',
+			quote: '"',
+			header: true,
+			types: BTreeMap::new(),
+		}
+	}
+}
+
+impl CsvOptions {
+	/// Set the character which separates the fields in a row
+	pub fn with_delimiter(mut self, delimiter: char) -> Self {
+		self.delimiter = delimiter;
+		self
+	}
+	/// Set the character which surrounds a quoted field
+	pub fn with_quote(mut self, quote: char) -> Self {
+		self.quote = quote;
+		self
+	}
+	/// Set whether the first row contains the column names
+	pub fn with_header(mut self, header: bool) -> Self {
+		self.header = header;
+		self
+	}
+	/// Set the type which the values in a column are converted to
+	pub fn with_type(mut self, column: impl Into<String>, kind: Kind) -> Self {
+		self.types.insert(column.into(), kind);
+		self
+	}
+}
+
+/// Reads records from comma-separated values, one line at a time
+pub(super) struct CsvReader<'a> {
+	opts: &'a CsvOptions,
+	// The column names, once they are known
+	columns: Option<Vec<String>>,
+	// The fields which have been read in the current row
+	fields: Vec<String>,
+	// The field which is currently being read
+	field: String,
+	// Whether the current field is quoted
+	quoted: bool,
+	// Whether the current field is within quotes
+	open: bool,
+	// The line on which the current row started
+	start: usize,
+}
+
+impl<'a> CsvReader<'a> {
+	pub(super) fn new(opts: &'a CsvOptions) -> Self {
+		Self {
+			opts,
+			columns: None,
+			fields: Vec::new(),
+			field: String::new(),
+			quoted: false,
+			open: false,
+			start: 0,
+		}
+	}
+	/// The line on which the current row started
+	pub(super) fn line(&self) -> usize {
+		self.start
+	}
+	/// Read the next line, returning a record once a full row has been read.
+	///
+	/// A quoted field may span multiple lines, in which case nothing is
+	/// returned until the line containing the closing quote has been read.
+	/// The header row, and any empty lines, do not produce a record.
+	pub(super) fn read(
+		&mut self,
+		tb: &str,
+		line: &str,
+		number: usize,
+	) -> Result<Option<Object>, String> {
+		if !self.open {
+			// Ignore any empty lines between rows
+			if line.is_empty() {
+				return Ok(None);
+			}
+			self.start = number;
+		} else {
+			// The newline is part of the quoted field
+			self.field.push('\n');
+		}
+		let mut chars = line.chars().peekable();
+		while let Some(c) = chars.next() {
+			if self.open {
+				if c != self.opts.quote {
+					self.field.push(c);
+				} else if chars.peek() == Some(&self.opts.quote) {
+					// A doubled quote is an escaped quote
+					self.field.push(c);
+					chars.next();
+				} else {
+					self.open = false;
+				}
+			} else if c == self.opts.delimiter {
+				self.end_field();
+			} else if self.quoted {
+				self.reset();
+				return Err(String::from("Expected a delimiter after a closing quote"));
+			} else if c == self.opts.quote && self.field.is_empty() {
+				self.quoted = true;
+				self.open = true;
+			} else {
+				self.field.push(c);
+			}
+		}
+		// Wait for the rest of the quoted field
+		if self.open {
+			return Ok(None);
+		}
+		self.end_field();
+		let fields = mem::take(&mut self.fields);
+		// Convert the row into a record
+		if let Some(columns) = &self.columns {
+			return self.record(tb, columns, fields).map(Some);
+		}
+		// The first row contains the column names
+		if self.opts.header {
+			self.columns = Some(fields);
+			return Ok(None);
+		}
+		// The columns are named by their position
+		let columns: Vec<String> = (1..=fields.len()).map(|i| format!("col{i}")).collect();
+		let record = self.record(tb, &columns, fields).map(Some);
+		self.columns = Some(columns);
+		record
+	}
+	/// Check that the input did not end within a quoted field
+	pub(super) fn finish(&mut self) -> Result<(), String> {
+		match self.open {
+			true => {
+				self.reset();
+				Err(String::from("Expected a closing quote before the end of the input"))
+			}
+			false => Ok(()),
+		}
+	}
+	/// Add the current field to the current row
+	fn end_field(&mut self) {
+		self.fields.push(mem::take(&mut self.field));
+		self.quoted = false;
+	}
+	/// Discard the current row
+	fn reset(&mut self) {
+		self.fields.clear();
+		self.field.clear();
+		self.quoted = false;
+		self.open = false;
+	}
+	/// Convert a row of fields into a record in the given table
+	fn record(&self, tb: &str, columns: &[String], fields: Vec<String>) -> Result<Object, String> {
+		if fields.len() != columns.len() {
+			return Err(format!("Expected {} fields but found {}", columns.len(), fields.len()));
+		}
+		let mut obj = Object::default();
+		for (column, field) in columns.iter().zip(fields) {
+			// Empty fields are left unset
+			if field.is_empty() {
+				continue;
+			}
+			let value = match self.opts.types.get(column) {
+				Some(kind) => {
+					Value::from(field.as_str()).convert_to(kind).map_err(|e| e.to_string())?
+				}
+				None => infer(&field),
+			};
+			obj.insert(column.to_owned(), value);
+		}
+		// Use the id column as the record id if there is one
+		let id = match obj.remove("id") {
+			Some(Value::Number(v)) => Id::from(v),
+			Some(Value::Strand(v)) => Id::from(v),
+			Some(v) => Id::from(v.as_raw_string()),
+			None => Id::rand(),
+		};
+		obj.insert("id".to_owned(), Value::Thing(Thing::from((tb, id))));
+		Ok(obj)
+	}
+}
+
+/// Infer the type of a field from its contents
+fn infer(field: &str) -> Value {
+	if field.eq_ignore_ascii_case("true") {
+		return Value::Bool(true);
+	}
+	if field.eq_ignore_ascii_case("false") {
+		return Value::Bool(false);
+	}
+	// Numbers with leading zeros, such as codes, are kept as strings
+	let digits = field.trim_start_matches(['-', '+']);
+	if !(digits.len() > 1 && digits.starts_with('0') && !digits.starts_with("0.")) {
+		if let Ok(v) = field.parse::<i64>() {
+			return Value::Number(Number::Int(v));
+		}
+		// Only plain decimal notation is treated as a float
+		if field.chars().all(|c| c.is_ascii_digit() || matches!(c, '-' | '+' | '.' | 'e' | 'E')) {
+			if let Ok(v) = field.parse::<f64>() {
+				return Value::Number(Number::Float(v));
+			}
+		}
+	}
+	if field.starts_with(|c: char| c.is_ascii_digit()) {
+		if let Ok(v) = Datetime::try_from(field) {
+			return Value::Datetime(v);
+		}
+	}
+	Value::from(field)
+}
+
+#[cfg(test)]
+mod tests {
+	use super::*;
+	use crate::syn::Parse;
+
+	fn read(opts: &CsvOptions, input: &str) -> Vec<Result<Object, String>> {
+		let mut reader = CsvReader::new(opts);
+		let mut out = Vec::new();
+		for (i, line) in input.lines().enumerate() {
+			if let Some(v) = reader.read("tb", line, i + 1).transpose() {
+				out.push(v);
+			}
+		}
+		if let Err(e) = reader.finish() {
+			out.push(Err(e));
+		}
+		out
+	}
+
+	#[test]
+	fn infers_types() {
+		assert_eq!(infer("1"), Value::from(1));
+		assert_eq!(infer("-2.5"), Value::from(-2.5));
+		assert_eq!(infer("TRUE"), Value::Bool(true));
+		assert_eq!(infer("false"), Value::Bool(false));
+		assert_eq!(infer("2024-01-02"), Value::parse("d'2024-01-02T00:00:00Z'"));
+		assert_eq!(infer("2024-01-02T03:04:05Z"), Value::parse("d'2024-01-02T03:04:05Z'"));
+		assert_eq!(infer("007"), Value::from("007"));
+		assert_eq!(infer("0.5"), Value::from(0.5));
+		assert_eq!(infer("inf"), Value::from("inf"));
+		assert_eq!(infer("hello"), Value::from("hello"));
+	}
+
+	#[test]
+	fn quoted_fields() {
+		let opts = CsvOptions::default();
+		let res =
+			read(&opts, "id,name,notes\n1,\"Smith, John\",\"Line one\nLine \"\"two\"\"\"\n2,Jane,");
+		assert_eq!(
+			res,
+			vec![
+				Ok(Object::from(map! {
+					"id".to_owned() => Value::parse("tb:1"),
+					"name".to_owned() => Value::from("Smith, John"),
+					"notes".to_owned() => Value::from("Line one\nLine \"two\""),
+				})),
+				Ok(Object::from(map! {
+					"id".to_owned() => Value::parse("tb:2"),
+					"name".to_owned() => Value::from("Jane"),
+				})),
+			]
+		);
+	}
+
+	#[test]
+	fn custom_delimiter_and_quote() {
+		let opts = CsvOptions::default().with_delimiter(';').with_quote('\'').with_header(false);
+		let res = read(&opts, "a;'b;c'\n'1';2");
+		assert_eq!(res.len(), 2);
+		let row = res[0].as_ref().unwrap();
+		assert_eq!(row.get("col1"), Some(&Value::from("a")));
+		assert_eq!(row.get("col2"), Some(&Value::from("b;c")));
+		let row = res[1].as_ref().unwrap();
+		assert_eq!(row.get("col1"), Some(&Value::from(1)));
+		assert_eq!(row.get("col2"), Some(&Value::from(2)));
+	}
+
+	#[test]
+	fn column_types() {
+		let opts = CsvOptions::default().with_type("code", Kind::String).with_type("n", Kind::Int);
+		let res = read(&opts, "id,code,n\nabc,123,4\nxyz,456,four");
+		let row = res[0].as_ref().unwrap();
+		assert_eq!(row.get("id"), Some(&Value::parse("tb:abc")));
+		assert_eq!(row.get("code"), Some(&Value::from("123")));
+		assert_eq!(row.get("n"), Some(&Value::from(4)));
+		assert!(res[1].is_err());
+	}
+
+	#[test]
+	fn malformed_rows() {
+		let opts = CsvOptions::default();
+		let res = read(&opts, "a,b\n1,2,3\n\"x\"y,2\n3,4\n\"unterminated,5");
+		assert_eq!(res.len(), 4);
+		assert_eq!(res[0], Err(String::from("Expected 2 fields but found 3")));
+		assert_eq!(res[1], Err(String::from("Expected a delimiter after a closing quote")));
+		assert_eq!(res[2].as_ref().unwrap().get("a"), Some(&Value::from(3)));
+		assert_eq!(
+			res[3],
+			Err(String::from("Expected a closing quote before the end of the input"))
+		);
+	}
+}
@@ -15,39 +15,50 @@ use std::time::{SystemTime, UNIX_EPOCH};
 
 use channel::{Receiver, Sender};
 use futures::{lock::Mutex, Future};
+use once_cell::sync::OnceCell;
 use reblessive::{tree::Stk, TreeStack};
 use tokio::sync::RwLock;
 use tracing::instrument;
 use tracing::trace;
+use trice::Instant;
 
 #[cfg(target_arch = "wasm32")]
 use wasmtimer::std::{SystemTime, UNIX_EPOCH};
 
 use super::tx::Transaction;
 use crate::cf;
+use crate::cnf;
 use crate::ctx::Context;
 #[cfg(feature = "jwks")]
 use crate::dbs::capabilities::NetTarget;
 use crate::dbs::{
-	node::Timestamp, Attach, Capabilities, Executor, Notification, Options, Response, Session,
-	Variables,
+	node::Timestamp, Attach, Capabilities, Executor, Notification, Options, Response, ResultStream,
+	Session, Variables,
 };
 use crate::err::Error;
 #[cfg(feature = "jwks")]
 use crate::iam::jwks::JwksCache;
+use crate::iam::revoke::RevocationCache;
 use crate::iam::{Action, Auth, Error as IamError, Resource, Role};
 use crate::idx::trees::store::IndexStores;
+use crate::key::error::KeyCategory;
 use crate::key::root::hb::Hb;
 use crate::kvs::clock::SizedClock;
 #[allow(unused_imports)]
 use crate::kvs::clock::SystemClock;
+use crate::kvs::export::ExportFormat;
 use crate::kvs::lq_cf::LiveQueryTracker;
 use crate::kvs::lq_structs::{LqValue, TrackedResult, UnreachableLqType};
 use crate::kvs::lq_v2_fut::process_lq_notifications;
+use crate::kvs::metrics::TransactionMetrics;
+use crate::kvs::query::QueryCache;
+use crate::kvs::results::ResultCache;
+use crate::kvs::scheduler::{Schedule, ScheduledTask};
+use crate::kvs::Metrics;
 use crate::kvs::{LockType, LockType::*, TransactionType, TransactionType::*};
 use crate::options::EngineOptions;
-use crate::sql::{self, statements::DefineUserStatement, Base, Query, Uuid, Value};
-use crate::syn;
+use crate::sql::statements::{BeginStatement, CommitStatement, DefineUserStatement};
+use crate::sql::{self, Base, Query, Statement, Statements, Uuid, Value};
 use crate::vs::{conv, Oracle, Versionstamp};
 
 // If there are an infinite number of heartbeats, then we want to go batch-by-batch spread over several checks
@@ -76,6 +87,14 @@ pub struct Datastore {
 	query_timeout: Option<Duration>,
 	// The maximum duration timeout for running multiple statements in a transaction
 	transaction_timeout: Option<Duration>,
+	// The duration after which a statement is logged as a slow query
+	slow_query_threshold: Option<Duration>,
+	// The maximum depth which a computation can reach
+	max_computation_depth: u32,
+	// The maximum number of rows which a statement can return
+	max_result_rows: Option<usize>,
+	// The maximum number of records which a statement can modify
+	max_mutated_records: Option<usize>,
 	// Capabilities for this datastore
 	capabilities: Capabilities,
 	pub(super) engine_options: EngineOptions,
@@ -88,6 +107,16 @@ pub struct Datastore {
 	clock: Arc<SizedClock>,
 	// The index store cache
 	index_stores: IndexStores,
+	// The cache of parsed queries
+	query_cache: QueryCache,
+	// The cache of SELECT statement results
+	result_cache: Arc<ResultCache>,
+	// The key used to sign pagination cursors
+	cursor_secret: Arc<OnceCell<Arc<[u8]>>>,
+	// The tokens which sessions have recently been checked against
+	revocations: Arc<RevocationCache>,
+	// The metrics describing the work done by this datastore
+	metrics: Arc<Metrics>,
 	#[cfg(feature = "jwks")]
 	// The JWKS object cache
 	jwks_cache: Arc<RwLock<JwksCache>>,
@@ -342,12 +371,24 @@ impl Datastore {
 			auth_enabled: false,
 			query_timeout: None,
 			transaction_timeout: None,
+			slow_query_threshold: match *cnf::SLOW_QUERY_THRESHOLD {
+				0 => None,
+				v => Some(Duration::from_millis(v)),
+			},
+			max_computation_depth: *cnf::MAX_COMPUTATION_DEPTH,
+			max_result_rows: Some(*cnf::MAX_RESULT_ROWS).filter(|v| *v > 0),
+			max_mutated_records: Some(*cnf::MAX_MUTATED_RECORDS).filter(|v| *v > 0),
 			notification_channel: None,
 			capabilities: Capabilities::default(),
 			engine_options: EngineOptions::default(),
 			versionstamp_oracle: Arc::new(Mutex::new(Oracle::systime_counter())),
 			clock,
 			index_stores: IndexStores::default(),
+			query_cache: QueryCache::new(*cnf::QUERY_CACHE_SIZE),
+			result_cache: Arc::new(ResultCache::new(*cnf::RESULT_CACHE_SIZE)),
+			cursor_secret: Arc::new(OnceCell::new()),
+			revocations: Arc::new(RevocationCache::default()),
+			metrics: Arc::new(Metrics::default()),
 			#[cfg(feature = "jwks")]
 			jwks_cache: Arc::new(RwLock::new(JwksCache::new())),
 			#[cfg(any(
@@ -392,6 +433,36 @@ impl Datastore {
 		self
 	}
 
+	/// Set the duration after which a statement is logged as a slow query
+	pub fn with_slow_query_threshold(mut self, duration: Option<Duration>) -> Self {
+		self.slow_query_threshold = duration;
+		self
+	}
+
+	/// Set the maximum depth which a computation can reach
+	pub fn with_max_computation_depth(mut self, depth: u32) -> Self {
+		self.max_computation_depth = depth;
+		self
+	}
+
+	/// Set the maximum number of rows which a statement can return
+	pub fn with_max_result_rows(mut self, rows: Option<usize>) -> Self {
+		self.max_result_rows = rows;
+		self
+	}
+
+	/// Set the maximum number of records which a statement can modify
+	pub fn with_max_mutated_records(mut self, records: Option<usize>) -> Self {
+		self.max_mutated_records = records;
+		self
+	}
+
+	/// Set how many results of SELECT statements with a CACHE clause are cached
+	pub fn with_result_cache_size(mut self, capacity: usize) -> Self {
+		self.result_cache = Arc::new(ResultCache::new(capacity));
+		self
+	}
+
 	/// Set whether authentication is enabled for this Datastore
 	pub fn with_auth_enabled(mut self, enabled: bool) -> Self {
 		self.auth_enabled = enabled;
@@ -426,6 +497,27 @@ impl Datastore {
 		&self.index_stores
 	}
 
+	/// The number of statements which have been retried because their
+	/// transaction conflicted with another transaction
+	pub fn transaction_retries(&self) -> u64 {
+		self.metrics.retries()
+	}
+
+	/// Get the metrics describing the work done by this datastore
+	pub fn metrics(&self) -> &Metrics {
+		&self.metrics
+	}
+
+	/// Get the tokens which sessions have recently been checked against
+	pub(crate) fn revocations(&self) -> &RevocationCache {
+		&self.revocations
+	}
+
+	/// Get the duration after which a statement is logged as a slow query
+	pub(crate) fn slow_query_threshold(&self) -> Option<Duration> {
+		self.slow_query_threshold
+	}
+
 	/// Is authentication enabled for this Datastore?
 	pub fn is_auth_enabled(&self) -> bool {
 		self.auth_enabled
@@ -831,11 +923,25 @@ impl Datastore {
 		trace!("Ticking at timestamp {} ({:?})", ts, conv::u64_to_versionstamp(ts));
 		let _vs = self.save_timestamp_for_versionstamp(ts).await?;
 		self.garbage_collect_stale_change_feeds(ts).await?;
+		self.garbage_collect_revoked_tokens(ts).await?;
 		// TODO Add LQ GC
 		// TODO Add Node GC?
 		Ok(())
 	}
 
+	// garbage_collect_revoked_tokens removes the revoked tokens which have expired,
+	// as an expired token is rejected without being checked against the revocation list.
+	pub(crate) async fn garbage_collect_revoked_tokens(&self, ts: u64) -> Result<(), Error> {
+		let mut tx = self.transaction(Write, Optimistic).await?;
+		match crate::iam::revoke::cleanup(&mut tx, ts).await {
+			Ok(()) => tx.commit().await,
+			Err(e) => {
+				let _ = tx.cancel().await;
+				Err(e)
+			}
+		}
+	}
+
 	// save_timestamp_for_versionstamp saves the current timestamp for the each database's current versionstamp.
 	// Note: the returned VS is flawed, as there are multiple {ts: vs} mappings per (ns, db)
 	pub(crate) async fn save_timestamp_for_versionstamp(
@@ -937,6 +1043,139 @@ impl Datastore {
 		Ok(())
 	}
 
+	// claim_tasks claims each DEFINE TASK definition which is due to run now.
+	// The claimed tasks are run separately from the tick, using run_task.
+	pub async fn claim_tasks(&self) -> Vec<ScheduledTask> {
+		match SystemTime::now().duration_since(UNIX_EPOCH) {
+			Ok(now) => self.claim_tasks_at(now.as_secs()).await,
+			Err(e) => {
+				warn!("Clock may have gone backwards: {:?}", e.duration());
+				vec![]
+			}
+		}
+	}
+
+	// claim_tasks_at claims each DEFINE TASK definition which is due at the given timestamp.
+	// Failures are logged rather than returned, so that they do not stop the node agent.
+	pub async fn claim_tasks_at(&self, ts: u64) -> Vec<ScheduledTask> {
+		// Find the task definitions in every database
+		let tasks = match self.find_tasks().await {
+			Ok(v) => v,
+			Err(e) => {
+				warn!("Unable to find scheduled tasks: {e}");
+				return vec![];
+			}
+		};
+		// Claim each task which is due, in its own transaction
+		let mut claimed = Vec::new();
+		for task in tasks {
+			match self.claim_task(&task, ts).await {
+				Ok(true) => claimed.push(task),
+				Ok(false) => {}
+				// Another node claimed the task at the same time
+				Err(Error::TxRetryable) => {}
+				Err(e) => {
+					warn!(
+						"Unable to claim scheduled task '{}' in {}/{}: {e}",
+						task.tk.name, task.ns, task.db
+					)
+				}
+			}
+		}
+		claimed
+	}
+
+	// run_task runs a claimed DEFINE TASK definition, and then releases its lease.
+	// The task runs in its own transaction, with editor permissions on its database.
+	pub async fn run_task(&self, task: ScheduledTask) {
+		let ScheduledTask {
+			ns,
+			db,
+			tk,
+		} = task;
+		let sess = Session::for_level((ns.as_str(), db.as_str()).into(), Role::Editor);
+		let mut stm = vec![Statement::Begin(BeginStatement)];
+		stm.extend(tk.then.iter().cloned().map(Statement::Value));
+		stm.push(Statement::Commit(CommitStatement));
+		// A failing task should not prevent other tasks from running
+		match self.process(Query(Statements(stm)), &sess, None).await {
+			Ok(res) => {
+				for r in res {
+					if let Err(e) = r.result {
+						warn!("Scheduled task '{}' in {ns}/{db} failed: {e}", tk.name);
+					}
+				}
+			}
+			Err(e) => warn!("Scheduled task '{}' in {ns}/{db} failed: {e}", tk.name),
+		}
+		if let Err(e) = self.release_task(&ns, &db, &tk).await {
+			warn!("Unable to release scheduled task '{}' in {ns}/{db}: {e}", tk.name);
+		}
+	}
+
+	// find_tasks fetches the DEFINE TASK definitions in every database.
+	async fn find_tasks(&self) -> Result<Vec<ScheduledTask>, Error> {
+		let mut out = Vec::new();
+		let mut tx = self.transaction(Read, Optimistic).await?;
+		for ns in tx.all_ns().await?.iter() {
+			for db in tx.all_db(&ns.name).await?.iter() {
+				for tk in tx.all_db_tasks(&ns.name, &db.name).await?.iter() {
+					out.push(ScheduledTask {
+						ns: ns.name.to_raw(),
+						db: db.name.to_raw(),
+						tk: tk.clone(),
+					});
+				}
+			}
+		}
+		tx.cancel().await?;
+		Ok(out)
+	}
+
+	// claim_task takes the lease on a task if it is due, so that no other node runs it.
+	async fn claim_task(&self, task: &ScheduledTask, ts: u64) -> Result<bool, Error> {
+		let every = task.tk.every.as_secs();
+		let key = crate::key::database::tr::new(&task.ns, &task.db, &task.tk.name);
+		let mut tx = self.transaction(Write, Optimistic).await?;
+		let mut schedule: Schedule = match tx.get(key.clone()).await? {
+			Some(v) => v.into(),
+			// The task runs on the next interval boundary
+			None => {
+				tx.set(key, Schedule::new(every, ts)).await?;
+				tx.commit().await?;
+				return Ok(false);
+			}
+		};
+		// Avoid writing to the datastore when the task is not due
+		if !schedule.is_due(ts) {
+			tx.cancel().await?;
+			return Ok(false);
+		}
+		let claimed = schedule.claim(self.id, every, ts, *cnf::TASK_LEASE_DURATION);
+		tx.set(key, schedule).await?;
+		tx.commit().await?;
+		Ok(claimed)
+	}
+
+	// release_task gives up the lease on a task once it has finished running.
+	async fn release_task(
+		&self,
+		ns: &str,
+		db: &str,
+		tk: &sql::statements::DefineTaskStatement,
+	) -> Result<(), Error> {
+		let key = crate::key::database::tr::new(ns, db, &tk.name);
+		let mut tx = self.transaction(Write, Optimistic).await?;
+		if let Some(v) = tx.get(key.clone()).await? {
+			let mut schedule: Schedule = v.into();
+			if schedule.release(self.id) {
+				tx.set(key, schedule).await?;
+				return tx.commit().await;
+			}
+		}
+		tx.cancel().await
+	}
+
 	// Creates a heartbeat entry for the member indicating to the cluster
 	// that the node is alive.
 	// This is the preferred way of creating heartbeats inside the database, so try to use this.
@@ -1043,6 +1282,10 @@ impl Datastore {
 			clock: self.clock.clone(),
 			prepared_async_events: (Arc::new(send), Arc::new(recv)),
 			engine_options: self.engine_options,
+			savepoints: Vec::new(),
+			results: self.result_cache.clone(),
+			changes: Vec::new(),
+			metrics: TransactionMetrics::new(self.metrics.clone()),
 		})
 	}
 
@@ -1070,7 +1313,7 @@ impl Datastore {
 		vars: Variables,
 	) -> Result<Vec<Response>, Error> {
 		// Parse the SQL query text
-		let ast = syn::parse(txt)?;
+		let ast = self.query_cache.parse(txt)?;
 		// Process the AST
 		self.process(ast, sess, vars).await
 	}
@@ -1098,11 +1341,73 @@ impl Datastore {
 		ast: Query,
 		sess: &Session,
 		vars: Variables,
+	) -> Result<Vec<Response>, Error> {
+		self.process_query(ast, sess, vars, None).await
+	}
+
+	/// Execute a query, sending the records of large SELECT statements to a stream
+	/// in batches as they are produced, instead of collecting them in the response
+	///
+	/// ```rust,no_run
+	/// use surrealdb_core::kvs::Datastore;
+	/// use surrealdb_core::err::Error;
+	/// use surrealdb_core::dbs::{ResultStream, Session};
+	///
+	/// #[tokio::main]
+	/// async fn main() -> Result<(), Error> {
+	///     let ds = Datastore::new("memory").await?;
+	///     let ses = Session::owner().with_ns("test").with_db("test");
+	///     let (send, recv) = channel::bounded(1);
+	///     let stream = ResultStream::new(send, 1000);
+	///     let (res, _) = futures::join!(
+	///         ds.execute_with_stream("SELECT * FROM person", &ses, None, stream),
+	///         async { while let Ok(_batch) = recv.recv().await {} },
+	///     );
+	///     res?;
+	///     Ok(())
+	/// }
+	/// ```
+	#[instrument(level = "debug", skip_all)]
+	pub async fn execute_with_stream(
+		&self,
+		txt: &str,
+		sess: &Session,
+		vars: Variables,
+		stream: ResultStream,
+	) -> Result<Vec<Response>, Error> {
+		// Parse the SQL query text
+		let ast = self.query_cache.parse(txt)?;
+		// Process the AST
+		self.process_query(ast, sess, vars, Some(stream)).await
+	}
+
+	/// Execute a pre-parsed SQL query, sending the records of large SELECT statements
+	/// to a stream in batches as they are produced, instead of collecting them in the response
+	#[instrument(level = "debug", skip_all)]
+	pub async fn process_with_stream(
+		&self,
+		ast: Query,
+		sess: &Session,
+		vars: Variables,
+		stream: ResultStream,
+	) -> Result<Vec<Response>, Error> {
+		self.process_query(ast, sess, vars, Some(stream)).await
+	}
+
+	/// Execute a pre-parsed SQL query, with an optional stream for SELECT results
+	async fn process_query(
+		&self,
+		ast: Query,
+		sess: &Session,
+		vars: Variables,
+		stream: Option<ResultStream>,
 	) -> Result<Vec<Response>, Error> {
 		// Check if the session has expired
 		if sess.expired() {
 			return Err(Error::ExpiredSession);
 		}
+		// Check if the session token has been revoked
+		crate::iam::revoke::check(self, sess).await?;
 		// Check if anonymous actors can execute queries when auth is enabled
 		// TODO(sgirones): Check this as part of the authorisation layer
 		if self.auth_enabled && sess.au.is_anon() && !self.capabilities.allows_guest_access() {
@@ -1121,9 +1426,12 @@ impl Datastore {
 			.with_live(sess.live())
 			.with_auth(sess.au.clone())
 			.with_strict(self.strict)
+			.with_max_computation_depth(self.max_computation_depth)
+			.with_max_result_rows(self.max_result_rows)
+			.with_max_mutated_records(self.max_mutated_records)
 			.with_auth_enabled(self.auth_enabled);
 		// Create a new query executor
-		let mut exe = Executor::new(self);
+		let mut exe = Executor::new(self).with_stream(stream);
 		// Create a default context
 		let mut ctx = Context::from_ds(
 			self.query_timeout,
@@ -1142,12 +1450,20 @@ impl Datastore {
 		if let Some(channel) = &self.notification_channel {
 			ctx.add_notifications(Some(&channel.0));
 		}
+		// Setup the key for signing cursors, if any are requested
+		if ast.iter().any(|s| matches!(s, Statement::Select(s) if s.cursor.is_some())) {
+			ctx.add_cursor_secret(self.cursor_secret().await?);
+		}
 		// Start an execution context
 		let ctx = sess.context(ctx);
 		// Store the query variables
 		let ctx = vars.attach(ctx)?;
+		// Cached results may depend on the schema
+		self.result_cache.invalidate_schema(&ast);
 		// Process all statements
+		let now = Instant::now();
 		let res = exe.execute(ctx, opt, ast).await;
+		self.metrics.query(now.elapsed());
 		match res {
 			Ok((responses, lives)) => {
 				// Register live queries
@@ -1158,6 +1474,44 @@ impl Datastore {
 		}
 	}
 
+	/// The key used to sign pagination cursors. A key which is not specified
+	/// in the configuration is generated once, and stored in the datastore.
+	async fn cursor_secret(&self) -> Result<Arc<[u8]>, Error> {
+		// Check if the key has already been loaded
+		if let Some(v) = self.cursor_secret.get() {
+			return Ok(v.clone());
+		}
+		let val = match cnf::CURSOR_SECRET.as_ref() {
+			Some(v) => v.clone(),
+			None => {
+				let key = crate::key::root::cs::new();
+				let mut run = self.transaction(Write, Optimistic).await?;
+				match run.get(key.clone()).await? {
+					Some(v) => {
+						run.cancel().await?;
+						v
+					}
+					None => {
+						let val = rand::random::<[u8; 32]>().to_vec();
+						run.put(KeyCategory::CursorSecret, key.clone(), val.clone()).await?;
+						match run.commit().await {
+							Ok(_) => val,
+							// Another node stored a key at the same time
+							Err(Error::TxRetryable) => {
+								let mut run = self.transaction(Read, Optimistic).await?;
+								let val = run.get(key).await?;
+								run.cancel().await?;
+								val.ok_or(Error::Unreachable("missing cursor secret"))?
+							}
+							Err(e) => return Err(e),
+						}
+					}
+				}
+			}
+		};
+		Ok(self.cursor_secret.get_or_init(|| Arc::from(val)).clone())
+	}
+
 	/// Ensure a SQL [`Value`] is fully computed
 	///
 	/// ```rust,no_run
@@ -1187,6 +1541,8 @@ impl Datastore {
 		if sess.expired() {
 			return Err(Error::ExpiredSession);
 		}
+		// Check if the session token has been revoked
+		crate::iam::revoke::check(self, sess).await?;
 
 		let mut stack = TreeStack::new();
 
@@ -1208,6 +1564,9 @@ impl Datastore {
 			.with_live(sess.live())
 			.with_auth(sess.au.clone())
 			.with_strict(self.strict)
+			.with_max_computation_depth(self.max_computation_depth)
+			.with_max_result_rows(self.max_result_rows)
+			.with_max_mutated_records(self.max_mutated_records)
 			.with_auth_enabled(self.auth_enabled);
 		// Create a default context
 		let mut ctx = Context::default();
@@ -1273,6 +1632,8 @@ impl Datastore {
 		if sess.expired() {
 			return Err(Error::ExpiredSession);
 		}
+		// Check if the session token has been revoked
+		crate::iam::revoke::check(self, sess).await?;
 
 		let mut stack = TreeStack::new();
 		// Create a new query options
@@ -1283,6 +1644,9 @@ impl Datastore {
 			.with_live(sess.live())
 			.with_auth(sess.au.clone())
 			.with_strict(self.strict)
+			.with_max_computation_depth(self.max_computation_depth)
+			.with_max_result_rows(self.max_result_rows)
+			.with_max_mutated_records(self.max_mutated_records)
 			.with_auth_enabled(self.auth_enabled);
 		// Create a default context
 		let mut ctx = Context::default();
@@ -1354,11 +1718,24 @@ impl Datastore {
 		&self,
 		sess: &Session,
 		chn: Sender<Vec<u8>>,
+	) -> Result<impl Future<Output = Result<(), Error>>, Error> {
+		self.export_with_format(sess, chn, ExportFormat::Sql).await
+	}
+
+	/// Performs a full database export in the given format
+	#[instrument(level = "debug", skip(self, sess, chn))]
+	pub async fn export_with_format(
+		&self,
+		sess: &Session,
+		chn: Sender<Vec<u8>>,
+		fmt: ExportFormat,
 	) -> Result<impl Future<Output = Result<(), Error>>, Error> {
 		// Check if the session has expired
 		if sess.expired() {
 			return Err(Error::ExpiredSession);
 		}
+		// Check if the session token has been revoked
+		crate::iam::revoke::check(self, sess).await?;
 		// Retrieve the provided NS and DB
 		let (ns, db) = crate::iam::check::check_ns_db(sess)?;
 		// Create a new readonly transaction
@@ -1366,7 +1743,10 @@ impl Datastore {
 		// Return an async export job
 		Ok(async move {
 			// Process the export
-			txn.export(&ns, &db, chn).await?;
+			match fmt {
+				ExportFormat::Sql => txn.export(&ns, &db, chn).await?,
+				ExportFormat::Ndjson => txn.export_ndjson(&ns, &db, chn).await?,
+			}
 			// Everything ok
 			Ok(())
 		})
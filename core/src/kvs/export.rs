@@ -0,0 +1,79 @@
+use channel::Sender;
+use serde_json::json;
+
+use super::tx::{ScanPage, Transaction};
+use crate::cnf::EXPORT_BATCH_SIZE;
+use crate::err::Error;
+use crate::sql::paths::{EDGE, IN, OUT};
+use crate::sql::Value;
+
+/// The format in which a database is exported
+#[derive(Clone, Copy, Debug, Default, Eq, PartialEq)]
+#[non_exhaustive]
+pub enum ExportFormat {
+	/// A SurrealQL script of DEFINE and INSERT statements
+	#[default]
+	Sql,
+	/// Newline-delimited JSON, with one definition or record on each line.
+	///
+	/// Definitions are written as `{"define":"DEFINE ..."}`, normal records
+	/// as `{"record":"{ ... }"}`, and graph edges as `{"relation":"{ ... }"}`.
+	/// Each record is written as a SurrealQL object literal, so that values
+	/// such as datetimes, durations, decimals, record links, and geometries
+	/// keep their types when imported. All of the definitions are written
+	/// before any of the records.
+	Ndjson,
+}
+
+impl Transaction {
+	/// Writes the full database contents as newline-delimited JSON.
+	pub async fn export_ndjson(
+		&mut self,
+		ns: &str,
+		db: &str,
+		chn: Sender<Vec<u8>>,
+	) -> Result<(), Error> {
+		// Output the database definitions
+		let mut defs = Vec::new();
+		defs.extend(self.all_db_users(ns, db).await?.iter().map(|v| v.to_string()));
+		defs.extend(self.all_db_accesses(ns, db).await?.iter().map(|v| v.to_string()));
+		defs.extend(self.all_db_params(ns, db).await?.iter().map(|v| v.to_string()));
+		defs.extend(self.all_db_functions(ns, db).await?.iter().map(|v| v.to_string()));
+		defs.extend(self.all_db_analyzers(ns, db).await?.iter().map(|v| v.to_string()));
+		defs.extend(self.all_db_tasks(ns, db).await?.iter().map(|v| v.to_string()));
+		// Output the table definitions
+		let tbs = self.all_tb(ns, db).await?;
+		for tb in tbs.iter() {
+			defs.push(tb.to_string());
+			defs.extend(self.all_tb_fields(ns, db, &tb.name).await?.iter().map(|v| v.to_string()));
+			defs.extend(self.all_tb_indexes(ns, db, &tb.name).await?.iter().map(|v| v.to_string()));
+			defs.extend(self.all_tb_events(ns, db, &tb.name).await?.iter().map(|v| v.to_string()));
+		}
+		for def in defs {
+			chn.send(bytes!(json!({ "define": def }))).await?;
+		}
+		// Output the table data
+		for tb in tbs.iter() {
+			let beg = crate::key::thing::prefix(ns, db, &tb.name);
+			let end = crate::key::thing::suffix(ns, db, &tb.name);
+			let mut nxt: Option<ScanPage<Vec<u8>>> = Some(ScanPage::from(beg..end));
+			while let Some(page) = nxt {
+				let res = self.scan_paged(page, *EXPORT_BATCH_SIZE).await?;
+				nxt = res.next_page;
+				for (_, v) in res.values.into_iter() {
+					let v: Value = (&v).into();
+					// Check if this is a graph edge
+					let line = match (v.pick(&*EDGE), v.pick(&*IN), v.pick(&*OUT)) {
+						(Value::Bool(true), Value::Thing(_), Value::Thing(_)) => {
+							json!({ "relation": v.to_string() })
+						}
+						_ => json!({ "record": v.to_string() }),
+					};
+					chn.send(bytes!(line)).await?;
+				}
+			}
+		}
+		// Everything exported
+		Ok(())
+	}
+}
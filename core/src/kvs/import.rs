@@ -0,0 +1,429 @@
+use futures::{AsyncBufRead, AsyncBufReadExt, StreamExt};
+use std::collections::BTreeMap;
+
+use super::csv::{CsvOptions, CsvReader};
+use super::Datastore;
+use crate::cnf::EXPORT_BATCH_SIZE;
+use crate::dbs::{Response, Session};
+use crate::err::Error;
+use crate::sql::block::Entry;
+use crate::sql::statements::{DeleteStatement, ForeachStatement, InsertStatement, UpsertStatement};
+use crate::sql::{Array, Block, Data, Idiom, Param, Part, Query, Statement, Value, Values};
+use crate::syn;
+
+/// How definitions and records which already exist are handled during an import.
+///
+/// Existing definitions are kept unless failing on conflicts, and any other
+/// error from a definition always fails the import.
+#[derive(Clone, Copy, Debug, Default, Eq, PartialEq)]
+#[non_exhaustive]
+pub enum ImportConflict {
+	/// Keep the existing record, and count the imported record as skipped
+	Skip,
+	/// Replace the existing record with the imported record
+	Overwrite,
+	/// Keep the existing record, and count the imported record as failed
+	#[default]
+	Fail,
+}
+
+impl ImportConflict {
+	/// Rewrite the statements of an import to handle existing definitions and records
+	fn rewrite(self, query: &mut Query) {
+		if self == ImportConflict::Fail {
+			return;
+		}
+		for stm in query.0 .0.iter_mut() {
+			match stm {
+				// Existing definitions are kept
+				Statement::Define(v) => v.set_if_not_exists(),
+				// Existing records are kept
+				Statement::Insert(v) if self == ImportConflict::Skip => v.ignore = true,
+				// Existing records are replaced
+				Statement::Insert(v) => {
+					if let Data::SingleExpression(data) = &v.data {
+						*stm = Self::replace(data.clone(), v.relation);
+					}
+				}
+				_ => (),
+			}
+		}
+	}
+	/// A statement which replaces each record in the given array
+	///
+	/// Relations are deleted and inserted again, so that the graph edges
+	/// which connect them to their `in` and `out` records are replaced too.
+	fn replace(data: Value, relation: bool) -> Statement {
+		let param = Param::from("v");
+		let id = Idiom::from(vec![Part::Start(param.clone().into()), Part::from("id")]);
+		let block = match relation {
+			false => vec![Entry::Upsert(UpsertStatement {
+				what: Values(vec![id.into()]),
+				data: Some(Data::ContentExpression(param.clone().into())),
+				..Default::default()
+			})],
+			true => vec![
+				Entry::Delete(DeleteStatement {
+					what: Values(vec![id.into()]),
+					..Default::default()
+				}),
+				Entry::Insert(InsertStatement {
+					data: Data::SingleExpression(param.clone().into()),
+					relation: true,
+					..Default::default()
+				}),
+			],
+		};
+		Statement::Foreach(ForeachStatement {
+			param,
+			range: data,
+			block: Block(block),
+		})
+	}
+}
+
+/// Options for importing newline-delimited JSON
+#[derive(Clone, Debug)]
+#[non_exhaustive]
+pub struct ImportOptions {
+	/// How records which already exist are handled
+	pub conflict: ImportConflict,
+	/// Whether lines which can not be parsed are skipped instead of failing the import
+	pub tolerate_malformed: bool,
+	/// The number of records which are written in each transaction
+	pub batch_size: usize,
+}
+
+impl Default for ImportOptions {
+	fn default() -> Self {
+		Self {
+			conflict: ImportConflict::default(),
+			tolerate_malformed: false,
+			batch_size: *EXPORT_BATCH_SIZE as usize,
+		}
+	}
+}
+
+impl ImportOptions {
+	/// Set how records which already exist are handled
+	pub fn with_conflict(mut self, conflict: ImportConflict) -> Self {
+		self.conflict = conflict;
+		self
+	}
+	/// Set whether lines which can not be parsed are skipped
+	pub fn with_tolerate_malformed(mut self, tolerate: bool) -> Self {
+		self.tolerate_malformed = tolerate;
+		self
+	}
+	/// Set the number of records which are written in each transaction
+	pub fn with_batch_size(mut self, size: usize) -> Self {
+		self.batch_size = size.max(1);
+		self
+	}
+}
+
+/// The outcome of a newline-delimited JSON import
+#[derive(Clone, Debug, Default, Eq, PartialEq)]
+#[non_exhaustive]
+pub struct ImportSummary {
+	/// The number of records which were written
+	pub inserted: usize,
+	/// The number of records which already existed and were skipped
+	pub skipped: usize,
+	/// The number of records which could not be written
+	pub failed: usize,
+	/// The line numbers of the malformed lines which were skipped
+	pub malformed: Vec<usize>,
+}
+
+/// A single parsed line of a newline-delimited JSON import
+enum Line {
+	Define(String),
+	Record(Value),
+	Relation(Value),
+}
+
+impl Line {
+	/// Parse a line in the format which is written by the JSON export
+	fn parse(line: &str) -> Result<Self, String> {
+		let Value::Object(mut obj) = syn::json(line).map_err(|e| e.to_string())? else {
+			return Err(String::from("Expected a JSON object"));
+		};
+		if obj.len() != 1 {
+			return Err(String::from("Expected a single 'define', 'record', or 'relation' key"));
+		}
+		match obj.0.pop_first() {
+			Some((k, Value::Strand(v))) if k == "define" => Ok(Line::Define(v.0)),
+			Some((k, v)) if k == "record" => Ok(Line::Record(Self::record(v, &["id"])?)),
+			Some((k, v)) if k == "relation" => {
+				Ok(Line::Relation(Self::record(v, &["id", "in", "out"])?))
+			}
+			_ => Err(String::from("Expected a single 'define', 'record', or 'relation' key")),
+		}
+	}
+	/// Parse a record, checking that the given fields are record ids
+	fn record(val: Value, fields: &[&str]) -> Result<Value, String> {
+		let mut obj = match val {
+			// Records are exported as SurrealQL object literals
+			Value::Strand(v) => match syn::value(&v).map_err(|e| e.to_string())? {
+				Value::Object(v) => v,
+				_ => return Err(String::from("Expected the record to be an object")),
+			},
+			// Plain JSON objects store record ids as strings
+			Value::Object(v) => v,
+			_ => return Err(String::from("Expected the record to be an object")),
+		};
+		for field in fields {
+			let thing = match obj.get(*field) {
+				Some(Value::Thing(v)) => v.clone(),
+				Some(Value::Strand(v)) => syn::thing(v).map_err(|e| e.to_string())?,
+				_ => return Err(format!("Expected the '{field}' field to be a record id")),
+			};
+			obj.insert(field.to_string(), Value::Thing(thing));
+		}
+		Ok(obj.into())
+	}
+}
+
+/// The records which are waiting to be written in the next transaction
+#[derive(Default)]
+struct Batch {
+	records: Vec<Value>,
+	relations: Vec<Value>,
+}
+
+impl Batch {
+	fn len(&self) -> usize {
+		self.records.len() + self.relations.len()
+	}
+}
+
+impl Datastore {
+	/// Performs a database import from newline-delimited JSON.
+	///
+	/// The input is read in the format which is written when exporting
+	/// with [`ExportFormat::Ndjson`](super::ExportFormat::Ndjson). Each
+	/// definition is applied in turn, and records are written in batches,
+	/// with each batch written in its own transaction.
+	pub async fn import_ndjson<R>(
+		&self,
+		sess: &Session,
+		input: R,
+		opts: &ImportOptions,
+	) -> Result<ImportSummary, Error>
+	where
+		R: AsyncBufRead + Unpin,
+	{
+		let mut summary = ImportSummary::default();
+		let mut batch = Batch::default();
+		let mut lines = input.lines();
+		let mut number = 0;
+		while let Some(line) = lines.next().await {
+			let line = line?;
+			number += 1;
+			// Ignore any empty lines
+			if line.trim().is_empty() {
+				continue;
+			}
+			match Line::parse(&line) {
+				Ok(Line::Define(sql)) => {
+					// Definitions must be applied before any subsequent records
+					self.import_batch(sess, &mut batch, opts, &mut summary).await?;
+					// Existing definitions are kept unless failing on conflicts
+					let mut query = syn::parse(&sql)?;
+					opts.conflict.rewrite(&mut query);
+					for res in self.process(query, sess, None).await? {
+						res.result?;
+					}
+				}
+				// Values such as bytes are exported as function calls
+				Ok(Line::Record(v)) => batch.records.push(self.literal(sess, v).await?),
+				Ok(Line::Relation(v)) => batch.relations.push(self.literal(sess, v).await?),
+				Err(message) => match opts.tolerate_malformed {
+					true => summary.malformed.push(number),
+					false => {
+						return Err(Error::InvalidImport {
+							line: number,
+							message,
+						})
+					}
+				},
+			}
+			if batch.len() >= opts.batch_size {
+				self.import_batch(sess, &mut batch, opts, &mut summary).await?;
+			}
+		}
+		self.import_batch(sess, &mut batch, opts, &mut summary).await?;
+		Ok(summary)
+	}
+
+	/// Performs a database import from SQL, such as a SQL export.
+	///
+	/// Existing definitions and records are handled with the given
+	/// conflict mode. Existing definitions are kept unless failing on
+	/// conflicts, and each INSERT statement either keeps or replaces
+	/// the records which already exist.
+	pub async fn import_with_conflict(
+		&self,
+		sql: &str,
+		sess: &Session,
+		conflict: ImportConflict,
+	) -> Result<Vec<Response>, Error> {
+		let mut query = syn::parse(sql)?;
+		conflict.rewrite(&mut query);
+		self.process(query, sess, None).await
+	}
+
+	/// Performs an import of comma-separated values into a table.
+	///
+	/// Each row is imported as a record in the given table. If there is
+	/// an `id` column then its values are used as the record ids, and
+	/// otherwise each record is given a random id. Records are written
+	/// in batches, with each batch written in its own transaction.
+	pub async fn import_csv<R>(
+		&self,
+		sess: &Session,
+		tb: &str,
+		input: R,
+		csv: &CsvOptions,
+		opts: &ImportOptions,
+	) -> Result<ImportSummary, Error>
+	where
+		R: AsyncBufRead + Unpin,
+	{
+		let mut summary = ImportSummary::default();
+		let mut batch = Batch::default();
+		let mut reader = CsvReader::new(csv);
+		let mut lines = input.lines();
+		let mut number = 0;
+		loop {
+			let res = match lines.next().await {
+				Some(line) => {
+					number += 1;
+					reader.read(tb, &line?, number)
+				}
+				// The input must not end within a quoted field
+				None => match reader.finish() {
+					Ok(()) => break,
+					Err(e) => Err(e),
+				},
+			};
+			match res {
+				Ok(Some(v)) => batch.records.push(v.into()),
+				Ok(None) => (),
+				Err(message) => match opts.tolerate_malformed {
+					true => summary.malformed.push(reader.line()),
+					false => {
+						return Err(Error::InvalidImport {
+							line: reader.line(),
+							message,
+						})
+					}
+				},
+			}
+			if batch.len() >= opts.batch_size {
+				self.import_batch(sess, &mut batch, opts, &mut summary).await?;
+			}
+		}
+		self.import_batch(sess, &mut batch, opts, &mut summary).await?;
+		Ok(summary)
+	}
+
+	/// Computes any parts of an imported record which are not literal values
+	async fn literal(&self, sess: &Session, val: Value) -> Result<Value, Error> {
+		match val.is_static() {
+			true => Ok(val),
+			false => self.compute(val, sess, None).await,
+		}
+	}
+
+	/// Writes a batch of imported records in a single transaction
+	async fn import_batch(
+		&self,
+		sess: &Session,
+		batch: &mut Batch,
+		opts: &ImportOptions,
+		summary: &mut ImportSummary,
+	) -> Result<(), Error> {
+		let Batch {
+			records,
+			relations,
+		} = std::mem::take(batch);
+		if records.is_empty() && relations.is_empty() {
+			return Ok(());
+		}
+		// Find which of the records already exist
+		let ids: Array = records.iter().chain(relations.iter()).map(|v| v.rid()).collect();
+		let vars = BTreeMap::from([("ids".to_owned(), Value::from(ids))]);
+		let existing =
+			match self.execute("SELECT VALUE id FROM $ids", sess, Some(vars)).await?.pop() {
+				Some(res) => match res.result? {
+					Value::Array(v) => v.0,
+					_ => Vec::new(),
+				},
+				None => Vec::new(),
+			};
+		// Separate the new records from those which already exist
+		let mut insert = Vec::new();
+		let mut relate = Vec::new();
+		let mut replace = Vec::new();
+		let mut delete = Vec::new();
+		for (v, edge) in
+			records.into_iter().map(|v| (v, false)).chain(relations.into_iter().map(|v| (v, true)))
+		{
+			if !existing.contains(&v.rid()) {
+				match edge {
+					false => insert.push(v),
+					true => relate.push(v),
+				}
+				continue;
+			}
+			match opts.conflict {
+				ImportConflict::Skip => summary.skipped += 1,
+				ImportConflict::Fail => summary.failed += 1,
+				ImportConflict::Overwrite => match edge {
+					false => replace.push(v),
+					// Existing relations are deleted, and then inserted again with their graph edges
+					true => {
+						delete.push(v.rid());
+						relate.push(v);
+					}
+				},
+			}
+		}
+		let count = insert.len() + relate.len() + replace.len();
+		if count == 0 {
+			return Ok(());
+		}
+		// Write the records in a single transaction
+		let mut sql = String::from("OPTION IMPORT; BEGIN TRANSACTION;");
+		if !insert.is_empty() {
+			sql.push_str("INSERT $insert;");
+		}
+		if !delete.is_empty() {
+			sql.push_str("DELETE $delete;");
+		}
+		if !relate.is_empty() {
+			sql.push_str("INSERT RELATION $relate;");
+		}
+		if !replace.is_empty() {
+			sql.push_str("FOR $v IN $replace { UPSERT $v.id CONTENT $v; };");
+		}
+		sql.push_str("COMMIT TRANSACTION;");
+		let vars = BTreeMap::from([
+			("insert".to_owned(), Value::from(insert)),
+			("relate".to_owned(), Value::from(relate)),
+			("replace".to_owned(), Value::from(replace)),
+			("delete".to_owned(), Value::from(delete)),
+		]);
+		let res = self.execute(&sql, sess, Some(vars)).await?;
+		match res.into_iter().find_map(|r| r.result.err()) {
+			Some(e) => {
+				warn!("Unable to import a batch of {count} records: {e}");
+				summary.failed += count;
+			}
+			None => summary.inserted += count,
+		}
+		Ok(())
+	}
+}
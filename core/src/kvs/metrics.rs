@@ -0,0 +1,303 @@
+use crate::sql::statement::KINDS;
+use std::fmt::Write;
+use std::sync::atomic::{AtomicU64, Ordering};
+use std::sync::Arc;
+use std::time::Duration;
+
+/// The upper bounds of the duration histogram buckets, in seconds
+const BUCKETS: [f64; 14] =
+	[0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1.0, 2.5, 5.0, 10.0];
+
+/// A histogram of durations, which can be updated without locking
+#[derive(Default)]
+struct Histogram {
+	// The number of observations in each bucket, with a final bucket for
+	// the observations which are larger than the largest bucket
+	buckets: [AtomicU64; BUCKETS.len() + 1],
+	// The sum of all observations, in nanoseconds
+	sum: AtomicU64,
+}
+
+impl Histogram {
+	fn observe(&self, duration: Duration) {
+		let secs = duration.as_secs_f64();
+		let bucket = BUCKETS.iter().position(|le| secs <= *le).unwrap_or(BUCKETS.len());
+		self.buckets[bucket].fetch_add(1, Ordering::Relaxed);
+		self.sum.fetch_add(duration.as_nanos() as u64, Ordering::Relaxed);
+	}
+	fn count(&self) -> u64 {
+		self.buckets.iter().map(|v| v.load(Ordering::Relaxed)).sum()
+	}
+	fn render(&self, out: &mut String, name: &str, labels: &str) {
+		let sep = if labels.is_empty() {
+			""
+		} else {
+			","
+		};
+		// Each bucket includes the observations in all smaller buckets
+		let mut count = 0;
+		for (bucket, le) in self.buckets.iter().zip(BUCKETS) {
+			count += bucket.load(Ordering::Relaxed);
+			let _ = writeln!(out, "{name}_bucket{{{labels}{sep}le=\"{le}\"}} {count}");
+		}
+		count += self.buckets[BUCKETS.len()].load(Ordering::Relaxed);
+		let _ = writeln!(out, "{name}_bucket{{{labels}{sep}le=\"+Inf\"}} {count}");
+		let labels = match labels.is_empty() {
+			true => String::new(),
+			false => format!("{{{labels}}}"),
+		};
+		let sum = self.sum.load(Ordering::Relaxed) as f64 / 1e9;
+		let _ = writeln!(out, "{name}_sum{labels} {sum}");
+		let _ = writeln!(out, "{name}_count{labels} {count}");
+	}
+}
+
+#[derive(Default)]
+struct Statements {
+	// The number of statements which failed
+	errors: AtomicU64,
+	// How long the statements took to run
+	durations: Histogram,
+}
+
+/// Counters and histograms describing the work done by a datastore.
+///
+/// Statements are grouped by their type, rather than by the query text,
+/// so that the number of metrics stays bounded however many distinct
+/// queries are run. All of the metrics are atomic counters, so recording
+/// a statement never waits on another query.
+pub struct Metrics {
+	// The statements which have been run, in the order of the statement types
+	statements: [Statements; KINDS.len()],
+	// How long each query took to run
+	queries: Histogram,
+	// The number of transactions which are currently open
+	transactions: AtomicU64,
+	// The number of statements which have been retried after a conflict
+	retries: AtomicU64,
+	// The number of read requests made to the key-value store
+	reads: AtomicU64,
+	// The number of write requests made to the key-value store
+	writes: AtomicU64,
+}
+
+impl Default for Metrics {
+	fn default() -> Self {
+		Self {
+			statements: std::array::from_fn(|_| Statements::default()),
+			queries: Histogram::default(),
+			transactions: AtomicU64::new(0),
+			retries: AtomicU64::new(0),
+			reads: AtomicU64::new(0),
+			writes: AtomicU64::new(0),
+		}
+	}
+}
+
+impl Metrics {
+	/// Record a statement of the given type which has been run
+	pub(crate) fn statement(&self, kind: &'static str, duration: Duration, failed: bool) {
+		let Some(stats) = KINDS.iter().position(|v| *v == kind).map(|i| &self.statements[i]) else {
+			return;
+		};
+		stats.durations.observe(duration);
+		if failed {
+			stats.errors.fetch_add(1, Ordering::Relaxed);
+		}
+	}
+	/// Record a query which has been run
+	pub(crate) fn query(&self, duration: Duration) {
+		self.queries.observe(duration);
+	}
+	/// Record a statement which is being retried after a conflict
+	pub(crate) fn retry(&self) {
+		self.retries.fetch_add(1, Ordering::Relaxed);
+	}
+	/// The number of statements which have been retried after a conflict
+	pub fn retries(&self) -> u64 {
+		self.retries.load(Ordering::Relaxed)
+	}
+	/// The number of transactions which are currently open
+	pub fn transactions(&self) -> u64 {
+		self.transactions.load(Ordering::Relaxed)
+	}
+	/// The number of read requests made to the key-value store
+	pub fn reads(&self) -> u64 {
+		self.reads.load(Ordering::Relaxed)
+	}
+	/// The number of write requests made to the key-value store
+	pub fn writes(&self) -> u64 {
+		self.writes.load(Ordering::Relaxed)
+	}
+	/// Render the metrics in the Prometheus text exposition format
+	pub fn prometheus(&self) -> String {
+		let mut out = String::new();
+		// Only output the statement types which have been run
+		let statements: Vec<(&str, &Statements)> = KINDS
+			.iter()
+			.copied()
+			.zip(self.statements.iter())
+			.filter(|(_, stats)| stats.durations.count() > 0)
+			.collect();
+		// Output the statement metrics
+		header(&mut out, "surrealdb_statements_total", "counter", "Statements which were run");
+		for (kind, stats) in statements.iter() {
+			let count = stats.durations.count();
+			let _ = writeln!(out, "surrealdb_statements_total{{type=\"{kind}\"}} {count}");
+		}
+		header(
+			&mut out,
+			"surrealdb_statement_errors_total",
+			"counter",
+			"Statements which returned an error",
+		);
+		for (kind, stats) in statements.iter() {
+			let errors = stats.errors.load(Ordering::Relaxed);
+			let _ = writeln!(out, "surrealdb_statement_errors_total{{type=\"{kind}\"}} {errors}");
+		}
+		header(
+			&mut out,
+			"surrealdb_statement_duration_seconds",
+			"histogram",
+			"How long statements took to run",
+		);
+		for (kind, stats) in statements.iter() {
+			let labels = format!("type=\"{kind}\"");
+			stats.durations.render(&mut out, "surrealdb_statement_duration_seconds", &labels);
+		}
+		// Output the query metrics
+		header(
+			&mut out,
+			"surrealdb_query_duration_seconds",
+			"histogram",
+			"How long queries took to run",
+		);
+		self.queries.render(&mut out, "surrealdb_query_duration_seconds", "");
+		// Output the transaction and storage metrics
+		for (name, kind, help, value) in [
+			(
+				"surrealdb_active_transactions",
+				"gauge",
+				"Transactions which are currently open",
+				self.transactions(),
+			),
+			(
+				"surrealdb_transaction_retries_total",
+				"counter",
+				"Statements which were retried after a transaction conflict",
+				self.retries(),
+			),
+			(
+				"surrealdb_kv_reads_total",
+				"counter",
+				"Read requests made to the datastore",
+				self.reads(),
+			),
+			(
+				"surrealdb_kv_writes_total",
+				"counter",
+				"Write requests made to the datastore",
+				self.writes(),
+			),
+		] {
+			header(&mut out, name, kind, help);
+			let _ = writeln!(out, "{name} {value}");
+		}
+		out
+	}
+}
+
+/// Output the description and type of a metric
+fn header(out: &mut String, name: &str, kind: &str, help: &str) {
+	let _ = writeln!(out, "# HELP {name} {help}");
+	let _ = writeln!(out, "# TYPE {name} {kind}");
+}
+
+/// Records the requests made by a transaction, and counts the
+/// transaction as open until it is dropped
+pub(crate) struct TransactionMetrics(Arc<Metrics>);
+
+impl TransactionMetrics {
+	pub(crate) fn new(metrics: Arc<Metrics>) -> Self {
+		metrics.transactions.fetch_add(1, Ordering::Relaxed);
+		Self(metrics)
+	}
+	/// Record a read request made to the key-value store
+	pub(crate) fn read(&self) {
+		self.0.reads.fetch_add(1, Ordering::Relaxed);
+	}
+	/// Record a write request made to the key-value store
+	pub(crate) fn write(&self) {
+		self.0.writes.fetch_add(1, Ordering::Relaxed);
+	}
+}
+
+impl Drop for TransactionMetrics {
+	fn drop(&mut self) {
+		self.0.transactions.fetch_sub(1, Ordering::Relaxed);
+	}
+}
+
+#[cfg(test)]
+mod tests {
+	use super::*;
+
+	#[test]
+	fn renders_statement_metrics() {
+		let metrics = Metrics::default();
+		metrics.statement("select", Duration::from_millis(2), false);
+		metrics.statement("select", Duration::from_millis(20), true);
+		metrics.statement("create", Duration::from_secs(20), false);
+		let out = metrics.prometheus();
+		assert!(out.contains("# TYPE surrealdb_statements_total counter\n"));
+		assert!(out.contains("surrealdb_statements_total{type=\"select\"} 2\n"));
+		assert!(out.contains("surrealdb_statements_total{type=\"create\"} 1\n"));
+		assert!(out.contains("surrealdb_statement_errors_total{type=\"select\"} 1\n"));
+		assert!(out.contains("surrealdb_statement_errors_total{type=\"create\"} 0\n"));
+		// Histogram buckets include all smaller observations
+		let name = "surrealdb_statement_duration_seconds";
+		assert!(out.contains(&format!("{name}_bucket{{type=\"select\",le=\"0.001\"}} 0\n")));
+		assert!(out.contains(&format!("{name}_bucket{{type=\"select\",le=\"0.0025\"}} 1\n")));
+		assert!(out.contains(&format!("{name}_bucket{{type=\"select\",le=\"0.025\"}} 2\n")));
+		assert!(out.contains(&format!("{name}_bucket{{type=\"create\",le=\"10\"}} 0\n")));
+		assert!(out.contains(&format!("{name}_bucket{{type=\"create\",le=\"+Inf\"}} 1\n")));
+		assert!(out.contains(&format!("{name}_sum{{type=\"create\"}} 20\n")));
+		assert!(out.contains(&format!("{name}_count{{type=\"select\"}} 2\n")));
+	}
+
+	#[test]
+	fn records_statements_concurrently() {
+		let metrics = Metrics::default();
+		std::thread::scope(|s| {
+			for _ in 0..4 {
+				s.spawn(|| {
+					for i in 0..1000 {
+						metrics.statement("select", Duration::from_micros(i), i % 10 == 0);
+					}
+				});
+			}
+		});
+		let out = metrics.prometheus();
+		assert!(out.contains("surrealdb_statements_total{type=\"select\"} 4000\n"));
+		assert!(out.contains("surrealdb_statement_errors_total{type=\"select\"} 400\n"));
+		// Statement types which have not been run are not output
+		assert!(!out.contains("type=\"create\""));
+	}
+
+	#[test]
+	fn tracks_transactions() {
+		let metrics = Arc::new(Metrics::default());
+		let tx = TransactionMetrics::new(metrics.clone());
+		tx.read();
+		tx.read();
+		tx.write();
+		assert_eq!(metrics.transactions(), 1);
+		drop(tx);
+		assert_eq!(metrics.transactions(), 0);
+		let out = metrics.prometheus();
+		assert!(out.contains("surrealdb_active_transactions 0\n"));
+		assert!(out.contains("surrealdb_kv_reads_total 2\n"));
+		assert!(out.contains("surrealdb_kv_writes_total 1\n"));
+		assert!(out.contains("surrealdb_query_duration_seconds_count 0\n"));
+	}
+}
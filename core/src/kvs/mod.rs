@@ -13,17 +13,27 @@
 //! - `mem`: in-memory database
 mod cache;
 mod clock;
+#[cfg(test)]
+pub(crate) mod conflicts;
+mod csv;
 mod ds;
+mod export;
 mod fdb;
+mod import;
 mod indxdb;
 mod kv;
 mod mem;
+mod metrics;
+mod query;
 mod rocksdb;
+mod savepoint;
+mod scheduler;
 mod surrealkv;
 mod tikv;
 mod tx;
 
 pub(crate) mod lq_structs;
+pub(crate) mod results;
 
 mod lq_cf;
 mod lq_v2_doc;
@@ -31,6 +41,11 @@ mod lq_v2_fut;
 #[cfg(test)]
 mod tests;
 
+pub use self::csv::CsvOptions;
 pub use self::ds::*;
+pub use self::export::ExportFormat;
+pub use self::import::{ImportConflict, ImportOptions, ImportSummary};
 pub use self::kv::*;
+pub use self::metrics::Metrics;
+pub use self::scheduler::ScheduledTask;
 pub use self::tx::*;
@@ -0,0 +1,146 @@
+use crate::err::Error;
+use crate::sql::Query;
+use crate::syn;
+use quick_cache::sync::Cache;
+use std::borrow::Cow;
+
+/// Queries which are longer than this are not cached, as large
+/// queries, such as imports, are unlikely to be executed again.
+const MAX_CACHED_QUERY_LENGTH: usize = 64 * 1024;
+
+/// A bounded cache of parsed queries, keyed by the normalised query text.
+///
+/// Query parameters are bound separately from the query text, so a
+/// query which is executed repeatedly with different parameters is
+/// only parsed once. Queries which only differ in their whitespace
+/// share a cached query. Parsing does not depend on the schema, so
+/// cached queries remain valid when the schema changes. A capacity
+/// of zero disables the cache.
+pub(crate) struct QueryCache(Option<Cache<String, Query>>);
+
+impl QueryCache {
+	/// Create a new cache holding up to the given number of queries
+	pub(crate) fn new(capacity: usize) -> Self {
+		match capacity {
+			0 => Self(None),
+			c => Self(Some(Cache::new(c))),
+		}
+	}
+	/// Parse the query text, reusing a previously parsed query if possible
+	pub(crate) fn parse(&self, txt: &str) -> Result<Query, Error> {
+		match &self.0 {
+			Some(cache) if txt.len() <= MAX_CACHED_QUERY_LENGTH => {
+				let key = normalise(txt);
+				match cache.get(key.as_ref()) {
+					Some(ast) => Ok(ast),
+					None => {
+						let ast = syn::parse(txt)?;
+						cache.insert(key.into_owned(), ast.clone());
+						Ok(ast)
+					}
+				}
+			}
+			_ => syn::parse(txt),
+		}
+	}
+	/// The number of queries which are currently cached
+	#[cfg(test)]
+	pub(crate) fn len(&self) -> usize {
+		self.0.as_ref().map_or(0, Cache::len)
+	}
+}
+
+/// Collapse each run of whitespace outside of quotes into a single space.
+///
+/// Whitespace within comments and regexes can not be told apart from the
+/// text around them without parsing the query, so queries which could
+/// contain either are left as they are.
+fn normalise(txt: &str) -> Cow<'_, str> {
+	if txt.contains(['/', '#', '⟨']) || txt.contains("--") {
+		return Cow::Borrowed(txt);
+	}
+	let mut out = String::with_capacity(txt.len());
+	let mut chars = txt.trim().chars();
+	let mut quote = None;
+	while let Some(c) = chars.next() {
+		match quote {
+			// Characters within quotes are kept as they are
+			Some(q) => {
+				out.push(c);
+				if c == '\\' {
+					out.extend(chars.next());
+				} else if c == q {
+					quote = None;
+				}
+			}
+			None if c.is_whitespace() => {
+				if !out.ends_with(' ') {
+					out.push(' ');
+				}
+			}
+			None => {
+				if matches!(c, '\'' | '"' | '`') {
+					quote = Some(c);
+				}
+				out.push(c);
+			}
+		}
+	}
+	Cow::Owned(out)
+}
+
+#[cfg(test)]
+mod tests {
+	use super::*;
+
+	#[test]
+	fn caches_parsed_queries() {
+		let cache = QueryCache::new(10);
+		let one = cache.parse("SELECT * FROM person WHERE age > $age").unwrap();
+		let two = cache.parse("SELECT * FROM person WHERE age > $age").unwrap();
+		assert_eq!(one, two);
+		assert_eq!(cache.len(), 1);
+		cache.parse("SELECT * FROM person WHERE age < $age").unwrap();
+		assert_eq!(cache.len(), 2);
+	}
+
+	#[test]
+	fn invalid_queries_are_not_cached() {
+		let cache = QueryCache::new(10);
+		assert!(cache.parse("SELECT * FROM").is_err());
+		assert_eq!(cache.len(), 0);
+	}
+
+	#[test]
+	fn queries_are_keyed_by_their_normalised_text() {
+		let cache = QueryCache::new(10);
+		cache.parse("SELECT * FROM person WHERE name = 'a  b'").unwrap();
+		cache.parse("  SELECT *\n\tFROM   person WHERE name = 'a  b'\n").unwrap();
+		assert_eq!(cache.len(), 1);
+		// Whitespace within quotes is significant
+		cache.parse("SELECT * FROM person WHERE name = 'a b'").unwrap();
+		assert_eq!(cache.len(), 2);
+		cache.parse("SELECT * FROM person WHERE name = \"a \\\"  b\"").unwrap();
+		cache.parse("SELECT * FROM person WHERE name = \"a \\\" b\"").unwrap();
+		assert_eq!(cache.len(), 4);
+		// Queries which may contain comments or regexes are not normalised
+		cache.parse("SELECT * FROM person -- comment\nWHERE age > 1").unwrap();
+		cache.parse("SELECT * FROM person -- comment WHERE age > 1").unwrap();
+		assert_eq!(cache.len(), 6);
+	}
+
+	#[test]
+	fn schema_changes_do_not_clear_the_cache() {
+		let cache = QueryCache::new(10);
+		cache.parse("SELECT * FROM person").unwrap();
+		cache.parse("DEFINE TABLE person SCHEMAFULL").unwrap();
+		assert_eq!(cache.len(), 2);
+	}
+
+	#[test]
+	fn zero_capacity_disables_the_cache() {
+		let cache = QueryCache::new(0);
+		cache.parse("SELECT * FROM person").unwrap();
+		assert_eq!(cache.len(), 0);
+	}
+}
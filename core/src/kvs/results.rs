@@ -0,0 +1,150 @@
+use crate::kvs::Val;
+use crate::sql::{Query, Statement, Value};
+use quick_cache::sync::Cache;
+use std::sync::Arc;
+use std::time::Duration;
+use trice::Instant;
+
+/// The versions of the tables which a cached result reads from, as stored
+/// in the datastore at the time the result was computed
+#[derive(Clone, Debug, Eq, PartialEq)]
+pub(crate) struct Stamp(pub(crate) Vec<Option<Val>>);
+
+struct Entry {
+	// The cached result of the query
+	value: Value,
+	// When the cached result expires
+	expires: Instant,
+	// The table versions at the time the result was computed
+	stamp: Stamp,
+}
+
+/// A bounded cache of the results of SELECT statements with a CACHE clause.
+///
+/// Cached results are keyed by the full query, including the values of
+/// any parameters, and the authenticated user which ran the query. Each
+/// write transaction stores a new version for every table it writes to,
+/// and each result is stamped with the versions of the tables it reads
+/// from, as read within the transaction which computed it. A result is
+/// only served to a transaction which reads the same versions, so writes
+/// which are committed by any node invalidate the result. Queries which
+/// could read from other records, such as through subqueries, graph edges,
+/// record links, or custom functions, are never cached. Schema changes only
+/// clear the cache on the node which made them. A capacity of zero disables
+/// the cache.
+pub(crate) struct ResultCache {
+	entries: Option<Cache<String, Arc<Entry>>>,
+}
+
+impl ResultCache {
+	/// Create a new cache holding up to the given number of results
+	pub(crate) fn new(capacity: usize) -> Self {
+		Self {
+			entries: match capacity {
+				0 => None,
+				c => Some(Cache::new(c)),
+			},
+		}
+	}
+	/// Check if the cache is enabled
+	pub(crate) fn enabled(&self) -> bool {
+		self.entries.is_some()
+	}
+	/// Fetch a result which has not expired, and which was computed from
+	/// the same table versions as the given stamp
+	pub(crate) fn get(&self, key: &str, stamp: &Stamp) -> Option<Value> {
+		let entries = self.entries.as_ref()?;
+		let entry = entries.get(key)?;
+		if entry.expires <= Instant::now() {
+			entries.remove(key);
+			return None;
+		}
+		match entry.stamp == *stamp {
+			true => Some(entry.value.clone()),
+			false => None,
+		}
+	}
+	/// Store a result which was computed from the given table versions
+	pub(crate) fn insert(&self, key: String, value: Value, ttl: Duration, stamp: Stamp) {
+		if let Some(entries) = &self.entries {
+			let entry = Entry {
+				value,
+				expires: Instant::now() + ttl,
+				stamp,
+			};
+			entries.insert(key, Arc::new(entry));
+		}
+	}
+	/// Remove all cached results if the query changes the schema
+	pub(crate) fn invalidate_schema(&self, ast: &Query) {
+		if let Some(entries) = &self.entries {
+			if ast.iter().any(|s| matches!(s, Statement::Define(_) | Statement::Remove(_))) {
+				entries.clear();
+			}
+		}
+	}
+	/// The number of results which are currently cached
+	#[cfg(test)]
+	pub(crate) fn len(&self) -> usize {
+		self.entries.as_ref().map_or(0, Cache::len)
+	}
+}
+
+#[cfg(test)]
+mod tests {
+	use super::*;
+	use crate::syn;
+
+	fn stamp(versions: &[Option<&str>]) -> Stamp {
+		Stamp(versions.iter().map(|v| v.map(|v| v.as_bytes().to_vec())).collect())
+	}
+
+	#[test]
+	fn serves_cached_results() {
+		let cache = ResultCache::new(10);
+		let ttl = Duration::from_secs(60);
+		cache.insert("key".into(), Value::from(1), ttl, stamp(&[Some("a")]));
+		assert_eq!(cache.get("key", &stamp(&[Some("a")])), Some(Value::from(1)));
+		assert_eq!(cache.get("other", &stamp(&[Some("a")])), None);
+	}
+
+	#[test]
+	fn results_expire() {
+		let cache = ResultCache::new(10);
+		cache.insert("key".into(), Value::from(1), Duration::ZERO, stamp(&[None]));
+		assert_eq!(cache.get("key", &stamp(&[None])), None);
+		assert_eq!(cache.len(), 0);
+	}
+
+	#[test]
+	fn writes_invalidate_dependent_results() {
+		let cache = ResultCache::new(10);
+		let ttl = Duration::from_secs(60);
+		cache.insert("key".into(), Value::from(1), ttl, stamp(&[None, Some("a")]));
+		// A table has been written to since the result was computed
+		assert_eq!(cache.get("key", &stamp(&[Some("b"), Some("a")])), None);
+		assert_eq!(cache.get("key", &stamp(&[None, Some("b")])), None);
+		// A transaction which reads the earlier versions is still served the result
+		assert_eq!(cache.get("key", &stamp(&[None, Some("a")])), Some(Value::from(1)));
+		// Results computed after a write are cached again
+		cache.insert("key".into(), Value::from(2), ttl, stamp(&[Some("b"), Some("a")]));
+		assert_eq!(cache.get("key", &stamp(&[Some("b"), Some("a")])), Some(Value::from(2)));
+	}
+
+	#[test]
+	fn schema_changes_clear_the_cache() {
+		let cache = ResultCache::new(10);
+		cache.insert("key".into(), Value::from(1), Duration::from_secs(60), stamp(&[None]));
+		cache.invalidate_schema(&syn::parse("SELECT * FROM person").unwrap());
+		assert_eq!(cache.len(), 1);
+		cache.invalidate_schema(&syn::parse("DEFINE FIELD name ON person").unwrap());
+		assert_eq!(cache.len(), 0);
+	}
+
+	#[test]
+	fn zero_capacity_disables_the_cache() {
+		let cache = ResultCache::new(0);
+		cache.insert("key".into(), Value::from(1), Duration::from_secs(60), stamp(&[None]));
+		assert_eq!(cache.get("key", &stamp(&[None])), None);
+	}
+}
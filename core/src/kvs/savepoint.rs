@@ -0,0 +1,46 @@
+use std::collections::HashMap;
+
+use crate::cf::writer::ChangeKey;
+
+use super::Key;
+use super::Val;
+
+/// A named layer in the write-set of a transaction.
+///
+/// Every key which is written while this savepoint is the
+/// innermost one has its previous value recorded here the
+/// first time it is touched, so that the writes made since
+/// the savepoint was defined can later be undone in place.
+pub(super) struct Savepoint {
+	/// The name given to this savepoint
+	pub(super) name: String,
+	/// The value of each key before this layer first modified it
+	pub(super) undo: HashMap<Key, Option<Val>>,
+	/// The number of buffered change feed mutations for each table
+	pub(super) changes: HashMap<ChangeKey, usize>,
+}
+
+impl Savepoint {
+	pub(super) fn new(name: String, changes: HashMap<ChangeKey, usize>) -> Self {
+		Self {
+			name,
+			undo: HashMap::new(),
+			changes,
+		}
+	}
+	/// Check if the previous value of a key is already recorded
+	pub(super) fn tracks(&self, key: &Key) -> bool {
+		self.undo.contains_key(key)
+	}
+	/// Record the previous value of a key
+	pub(super) fn track(&mut self, key: Key, val: Option<Val>) {
+		self.undo.entry(key).or_insert(val);
+	}
+	/// Fold a released inner layer into this one, keeping the
+	/// older previous value for any key which both layers touched
+	pub(super) fn absorb(&mut self, inner: Savepoint) {
+		for (key, val) in inner.undo {
+			self.track(key, val);
+		}
+	}
+}
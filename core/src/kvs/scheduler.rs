@@ -0,0 +1,141 @@
+use crate::sql::statements::DefineTaskStatement;
+use crate::sql::Uuid;
+use derive::Store;
+use revision::revisioned;
+use serde::{Deserialize, Serialize};
+
+/// The schedule of a DEFINE TASK definition.
+///
+/// Tasks run on the boundaries of their interval, so a task defined with
+/// `EVERY 1h` runs at the start of each hour. When a task is first seen,
+/// it runs at the next boundary rather than straight away. The schedule
+/// is persisted alongside the task definition, so that every node in a
+/// cluster agrees on when a task is next due. A node claims a run of the
+/// task by taking its lease in a write transaction, and a run which is
+/// due while the lease is held is skipped, so that only a single run of
+/// each task is in progress across the cluster.
+#[revisioned(revision = 1)]
+#[derive(Clone, Debug, Default, Eq, PartialEq, Serialize, Deserialize, Store)]
+#[non_exhaustive]
+pub struct Schedule {
+	// The timestamp at which the task is next due
+	pub next: u64,
+	// The node which holds the lease on the task
+	pub node: Option<Uuid>,
+	// The timestamp at which the lease on the task expires
+	pub expires: u64,
+}
+
+impl Schedule {
+	/// The schedule of a task which has not been seen before
+	pub(crate) fn new(every: u64, ts: u64) -> Self {
+		Self {
+			next: Self::next(every, ts),
+			node: None,
+			expires: 0,
+		}
+	}
+	/// Whether the task is due at the given timestamp
+	pub(crate) fn is_due(&self, ts: u64) -> bool {
+		ts >= self.next
+	}
+	/// Claim a run of the task for a node, if it is due at the given timestamp.
+	///
+	/// A task which is due is skipped if the lease on the task is held
+	/// and has not yet expired. A claimed task must be released once it
+	/// has finished running.
+	pub(crate) fn claim(&mut self, node: Uuid, every: u64, ts: u64, lease: u64) -> bool {
+		// Check if the task is due
+		if !self.is_due(ts) {
+			return false;
+		}
+		// Any runs which were missed are not caught up
+		self.next = Self::next(every, ts);
+		// Skip this run if the previous one is still in progress
+		if self.node.is_some() && ts < self.expires {
+			return false;
+		}
+		self.node = Some(node);
+		self.expires = ts.saturating_add(lease);
+		true
+	}
+	/// Release the lease on the task, if it is held by the given node
+	pub(crate) fn release(&mut self, node: Uuid) -> bool {
+		if self.node != Some(node) {
+			return false;
+		}
+		self.node = None;
+		self.expires = 0;
+		true
+	}
+	/// The first interval boundary after the given timestamp
+	fn next(every: u64, ts: u64) -> u64 {
+		// Intervals shorter than a second run on every second
+		let every = every.max(1);
+		(ts / every + 1).saturating_mul(every)
+	}
+}
+
+/// A run of a DEFINE TASK definition which has been claimed by this node
+#[derive(Clone, Debug)]
+#[non_exhaustive]
+pub struct ScheduledTask {
+	pub ns: String,
+	pub db: String,
+	pub tk: DefineTaskStatement,
+}
+
+#[cfg(test)]
+mod tests {
+	use super::*;
+
+	fn node(v: u128) -> Uuid {
+		Uuid(uuid::Uuid::from_u128(v))
+	}
+
+	#[test]
+	fn runs_on_interval_boundaries() {
+		// The task is not run when it is first seen
+		let mut schedule = Schedule::new(60, 90);
+		assert!(!schedule.claim(node(1), 60, 119, 600));
+		// The task is run once the next boundary is reached
+		assert!(schedule.claim(node(1), 60, 120, 600));
+		assert!(schedule.release(node(1)));
+		assert!(!schedule.claim(node(1), 60, 150, 600));
+		assert!(schedule.claim(node(1), 60, 185, 600));
+		assert!(schedule.release(node(1)));
+		// Missed runs are not caught up
+		assert!(schedule.claim(node(1), 60, 600, 600));
+		assert!(schedule.release(node(1)));
+		assert!(!schedule.claim(node(1), 60, 610, 600));
+		assert!(schedule.claim(node(1), 60, 660, 600));
+	}
+
+	#[test]
+	fn skips_overlapping_runs() {
+		let mut schedule = Schedule::new(10, 0);
+		assert!(schedule.claim(node(1), 10, 10, 600));
+		// The previous run has not been released yet
+		assert!(!schedule.claim(node(1), 10, 20, 600));
+		assert!(!schedule.claim(node(2), 10, 30, 600));
+		// The lease can only be released by the node which holds it
+		assert!(!schedule.release(node(2)));
+		assert!(schedule.release(node(1)));
+		// The skipped runs are not caught up
+		assert!(!schedule.claim(node(2), 10, 35, 600));
+		assert!(schedule.claim(node(2), 10, 40, 600));
+	}
+
+	#[test]
+	fn expired_leases_are_taken_over() {
+		let mut schedule = Schedule::new(10, 0);
+		assert!(schedule.claim(node(1), 10, 10, 25));
+		// The node holding the lease has not released it
+		assert!(!schedule.claim(node(2), 10, 20, 25));
+		assert!(!schedule.claim(node(2), 10, 30, 25));
+		// Once the lease expires another node can run the task
+		assert!(schedule.claim(node(2), 10, 40, 25));
+		assert!(!schedule.release(node(1)));
+		assert!(schedule.release(node(2)));
+	}
+}
@@ -71,6 +71,7 @@ mod mem {
 	include!("tblq.rs");
 	include!("tbnt.rs");
 	include!("tx_test.rs");
+	include!("tx_isolation.rs");
 }
 
 #[cfg(feature = "kv-rocksdb")]
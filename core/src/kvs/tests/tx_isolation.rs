@@ -0,0 +1,103 @@
+#[tokio::test]
+#[serial]
+async fn tx_isolation_uncommitted_writes_are_not_visible() {
+	// Create a new datastore
+	let node_id = Uuid::parse_str("4e2ba4a7-5d4b-4a79-8f36-3b43a0e3c1d2").unwrap();
+	let clock = Arc::new(SizedClock::Fake(FakeClock::new(Timestamp::default())));
+	let (ds, _) = new_ds(node_id, clock).await;
+	// Insert an initial key
+	let mut tx = ds.transaction(Write, Optimistic).await.unwrap();
+	tx.set("test", "some text").await.unwrap();
+	tx.commit().await.unwrap();
+	// Create a readonly transaction
+	let mut tx1 = ds.transaction(Read, Optimistic).await.unwrap();
+	// Create a writeable transaction
+	let mut tx2 = ds.transaction(Write, Optimistic).await.unwrap();
+	tx2.set("test", "other text").await.unwrap();
+	tx2.set("temp", "temp text").await.unwrap();
+	// The writeable transaction reads its own writes
+	let val = tx2.get("test").await.unwrap().unwrap();
+	assert_eq!(val, b"other text");
+	// The readonly transaction does not see the uncommitted writes
+	let val = tx1.get("test").await.unwrap().unwrap();
+	assert_eq!(val, b"some text");
+	assert!(tx1.get("temp").await.unwrap().is_none());
+	tx2.commit().await.unwrap();
+	// The readonly transaction still does not see the committed writes
+	let val = tx1.get("test").await.unwrap().unwrap();
+	assert_eq!(val, b"some text");
+	assert!(tx1.get("temp").await.unwrap().is_none());
+	tx1.cancel().await.unwrap();
+	// A new transaction sees the committed writes
+	let mut tx = ds.transaction(Read, Optimistic).await.unwrap();
+	let val = tx.get("test").await.unwrap().unwrap();
+	assert_eq!(val, b"other text");
+	let val = tx.get("temp").await.unwrap().unwrap();
+	assert_eq!(val, b"temp text");
+	tx.cancel().await.unwrap();
+}
+
+#[tokio::test]
+#[serial]
+async fn tx_isolation_cancelled_writes_are_discarded() {
+	// Create a new datastore
+	let node_id = Uuid::parse_str("b0d8a1c3-0f5e-4a4e-9d5b-7c2f6e8a9b10").unwrap();
+	let clock = Arc::new(SizedClock::Fake(FakeClock::new(Timestamp::default())));
+	let (ds, _) = new_ds(node_id, clock).await;
+	// Insert some initial keys
+	let mut tx = ds.transaction(Write, Optimistic).await.unwrap();
+	tx.set("test1", "some text").await.unwrap();
+	tx.set("test2", "some text").await.unwrap();
+	tx.commit().await.unwrap();
+	// Update, delete, and insert keys, and cancel the transaction
+	let mut tx = ds.transaction(Write, Optimistic).await.unwrap();
+	tx.set("test1", "other text").await.unwrap();
+	tx.del("test2").await.unwrap();
+	tx.set("test3", "other text").await.unwrap();
+	assert!(tx.get("test2").await.unwrap().is_none());
+	tx.cancel().await.unwrap();
+	// None of the writes were applied
+	let mut tx = ds.transaction(Read, Optimistic).await.unwrap();
+	let val = tx.scan("test0".."test9", u32::MAX).await.unwrap();
+	assert_eq!(val.len(), 2);
+	assert_eq!(val[0].0, b"test1");
+	assert_eq!(val[0].1, b"some text");
+	assert_eq!(val[1].0, b"test2");
+	assert_eq!(val[1].1, b"some text");
+	tx.cancel().await.unwrap();
+}
+
+#[tokio::test]
+#[serial]
+async fn tx_isolation_concurrent_updates_are_not_lost() {
+	// Create a new datastore
+	let node_id = Uuid::parse_str("7a3c5e1f-2b4d-4f6a-8c9e-0d1f2a3b4c5d").unwrap();
+	let clock = Arc::new(SizedClock::Fake(FakeClock::new(Timestamp::default())));
+	let (ds, _) = new_ds(node_id, clock).await;
+	// Insert an initial counter
+	let mut tx = ds.transaction(Write, Optimistic).await.unwrap();
+	tx.set("count", 0u64.to_be_bytes().to_vec()).await.unwrap();
+	tx.commit().await.unwrap();
+	// Increment the counter, retrying the transaction on a conflict
+	let ds = &ds;
+	let increment = || async move {
+		for _ in 0..10 {
+			let mut tx = ds.transaction(Write, Optimistic).await.unwrap();
+			let val = tx.get("count").await.unwrap().unwrap();
+			let val = u64::from_be_bytes(val.try_into().unwrap());
+			tokio::task::yield_now().await;
+			tx.set("count", (val + 1).to_be_bytes().to_vec()).await.unwrap();
+			if tx.commit().await.is_ok() {
+				return;
+			}
+		}
+		panic!("The transaction conflicted too many times");
+	};
+	// Run the transactions concurrently
+	futures::join!(increment(), increment(), increment());
+	// Check that every increment was applied
+	let mut tx = ds.transaction(Read, Optimistic).await.unwrap();
+	let val = tx.get("count").await.unwrap().unwrap();
+	assert_eq!(u64::from_be_bytes(val.try_into().unwrap()), 3);
+	tx.cancel().await.unwrap();
+}
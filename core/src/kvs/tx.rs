@@ -20,6 +20,7 @@ use sql::statements::DefineModelStatement;
 use sql::statements::DefineNamespaceStatement;
 use sql::statements::DefineParamStatement;
 use sql::statements::DefineTableStatement;
+use sql::statements::DefineTaskStatement;
 use sql::statements::DefineUserStatement;
 use sql::statements::LiveStatement;
 
@@ -37,6 +38,9 @@ use crate::kvs::cache::Cache;
 use crate::kvs::cache::Entry;
 use crate::kvs::clock::SizedClock;
 use crate::kvs::lq_structs::{LqValue, TrackedResult};
+use crate::kvs::metrics::TransactionMetrics;
+use crate::kvs::results::{ResultCache, Stamp};
+use crate::kvs::savepoint::Savepoint;
 use crate::kvs::Check;
 use crate::options::EngineOptions;
 use crate::sql;
@@ -99,6 +103,12 @@ pub struct Transaction {
 	pub(super) clock: Arc<SizedClock>,
 	pub(super) prepared_async_events: (Arc<Sender<TrackedResult>>, Arc<Receiver<TrackedResult>>),
 	pub(super) engine_options: EngineOptions,
+	pub(super) savepoints: Vec<Savepoint>,
+	pub(super) results: Arc<ResultCache>,
+	// The tables which have been written to in this transaction
+	pub(super) changes: Vec<(String, String, String)>,
+	// The metrics for the requests made by this transaction
+	pub(super) metrics: TransactionMetrics,
 }
 
 #[allow(clippy::large_enum_variant)]
@@ -282,6 +292,12 @@ impl Transaction {
 	pub async fn commit(&mut self) -> Result<(), Error> {
 		#[cfg(debug_assertions)]
 		trace!("Commit");
+		// Fail as if this conflicted with another transaction
+		#[cfg(test)]
+		if super::conflicts::take() {
+			self.cancel().await?;
+			return Err(Error::TxRetryable);
+		}
 		match self {
 			#[cfg(feature = "kv-mem")]
 			Transaction {
@@ -318,6 +334,135 @@ impl Transaction {
 		}
 	}
 
+	/// Record a write to a table, storing a new version of the table the
+	/// first time it is written to, so that any cached results which read
+	/// from it are invalidated once this transaction is committed.
+	pub(crate) async fn changed(&mut self, ns: &str, db: &str, tb: &str) -> Result<(), Error> {
+		if self.results.enabled()
+			&& !self.changes.iter().any(|(n, d, t)| n == ns && d == db && t == tb)
+		{
+			let key = crate::key::table::cv::new(ns, db, tb);
+			self.set(key, uuid::Uuid::new_v4().as_bytes().to_vec()).await?;
+			self.changes.push((ns.to_owned(), db.to_owned(), tb.to_owned()));
+		}
+		Ok(())
+	}
+
+	/// Read the current versions of the given tables
+	pub(crate) async fn table_versions(
+		&mut self,
+		ns: &str,
+		db: &str,
+		tbs: &[String],
+	) -> Result<Stamp, Error> {
+		let mut versions = Vec::with_capacity(tbs.len());
+		for tb in tbs {
+			versions.push(self.get(crate::key::table::cv::new(ns, db, tb)).await?);
+		}
+		Ok(Stamp(versions))
+	}
+
+	/// Check if any tables have been written to in this transaction
+	pub(crate) fn has_changes(&self) -> bool {
+		!self.changes.is_empty()
+	}
+
+	/// Get the cache of SELECT statement results
+	pub(crate) fn results(&self) -> Arc<ResultCache> {
+		self.results.clone()
+	}
+
+	/// Define a savepoint within this transaction.
+	///
+	/// Any changes made after this point can be undone with
+	/// [`Transaction::rollback_to_savepoint`], without affecting
+	/// the changes made before it. A savepoint with the same name
+	/// as an existing one hides the earlier savepoint until it is
+	/// released.
+	pub fn new_savepoint(&mut self, name: &str) {
+		#[cfg(debug_assertions)]
+		trace!("Savepoint {name}");
+		self.savepoints.push(Savepoint::new(name.to_owned(), self.cf.checkpoint()));
+	}
+
+	/// Release a savepoint, and any savepoints defined after it.
+	///
+	/// The changes made since the savepoint was defined are kept,
+	/// and become part of the enclosing savepoint, if there is one.
+	pub fn release_savepoint(&mut self, name: &str) -> Result<(), Error> {
+		#[cfg(debug_assertions)]
+		trace!("Release savepoint {name}");
+		let idx = self.savepoint_index(name)?;
+		let released = self.savepoints.split_off(idx);
+		if let Some(outer) = self.savepoints.last_mut() {
+			for sp in released {
+				outer.absorb(sp);
+			}
+		}
+		Ok(())
+	}
+
+	/// Roll back to a savepoint.
+	///
+	/// This reverses all changes made since the savepoint was defined,
+	/// including those made within any savepoints defined after it,
+	/// which are released. The savepoint itself remains defined.
+	pub async fn rollback_to_savepoint(&mut self, name: &str) -> Result<(), Error> {
+		#[cfg(debug_assertions)]
+		trace!("Rollback to savepoint {name}");
+		let idx = self.savepoint_index(name)?;
+		let layers = self.savepoints.split_off(idx);
+		let name = layers[0].name.clone();
+		let changes = layers[0].changes.clone();
+		// Restore the layers without recording the restored values
+		let outer = std::mem::take(&mut self.savepoints);
+		let res = self.restore(layers).await;
+		self.savepoints = outer;
+		res?;
+		// Discard any change feed mutations and cached definitions
+		self.cf.truncate(&changes);
+		self.clear_cache();
+		// Table versions may have been restored, so are stored again on the next write
+		self.changes.clear();
+		// Redefine the savepoint from this point
+		self.savepoints.push(Savepoint::new(name, changes));
+		Ok(())
+	}
+
+	/// Undo the writes recorded in each layer, innermost first
+	async fn restore(&mut self, layers: Vec<Savepoint>) -> Result<(), Error> {
+		for sp in layers.into_iter().rev() {
+			for (key, val) in sp.undo {
+				match val {
+					Some(val) => self.set(key, val).await?,
+					None => self.del(key).await?,
+				}
+			}
+		}
+		Ok(())
+	}
+
+	/// Find the innermost savepoint with the specified name
+	fn savepoint_index(&self, name: &str) -> Result<usize, Error> {
+		self.savepoints.iter().rposition(|sp| sp.name == name).ok_or_else(|| {
+			Error::SavepointNotFound {
+				name: name.to_owned(),
+			}
+		})
+	}
+
+	/// Record the previous value of a key in the innermost savepoint
+	async fn track(&mut self, key: &Key) -> Result<(), Error> {
+		if self.savepoints.last().map_or(true, |sp| sp.tracks(key)) {
+			return Ok(());
+		}
+		let val = self.get(key.clone()).await?;
+		if let Some(sp) = self.savepoints.last_mut() {
+			sp.track(key.clone(), val);
+		}
+		Ok(())
+	}
+
 	/// From the existing transaction, consume all the remaining live query registration events and return them synchronously
 	/// This function does not check that a transaction was committed, but the intention is to consume from this
 	/// only once the transaction is committed
@@ -352,6 +497,8 @@ impl Transaction {
 		let key = key.into();
 		#[cfg(debug_assertions)]
 		trace!("Del {}", sprint_key(&key));
+		self.track(&key).await?;
+		self.metrics.write();
 		match self {
 			#[cfg(feature = "kv-mem")]
 			Transaction {
@@ -396,6 +543,7 @@ impl Transaction {
 	{
 		#[cfg(debug_assertions)]
 		trace!("Exi {}", sprint_key(&key));
+		self.metrics.read();
 		match self {
 			#[cfg(feature = "kv-mem")]
 			Transaction {
@@ -441,6 +589,7 @@ impl Transaction {
 		let key = key.into();
 		#[cfg(debug_assertions)]
 		trace!("Get {}", sprint_key(&key));
+		self.metrics.read();
 		match self {
 			#[cfg(feature = "kv-mem")]
 			Transaction {
@@ -487,6 +636,8 @@ impl Transaction {
 		let key = key.into();
 		#[cfg(debug_assertions)]
 		trace!("Set {} => {:?}", sprint_key(&key), val);
+		self.track(&key).await?;
+		self.metrics.write();
 		match self {
 			#[cfg(feature = "kv-mem")]
 			Transaction {
@@ -619,6 +770,7 @@ impl Transaction {
 			sprint_key(&ts_key),
 			sprint_key(&suffix)
 		);
+		self.metrics.write();
 		match self {
 			#[cfg(feature = "kv-mem")]
 			Transaction {
@@ -677,6 +829,9 @@ impl Transaction {
 		K: Into<Key> + Debug,
 		V: Into<Val> + Debug,
 	{
+		let key = key.into();
+		self.track(&key).await?;
+		self.metrics.write();
 		match self {
 			#[cfg(feature = "kv-mem")]
 			Transaction {
@@ -727,6 +882,7 @@ impl Transaction {
 		};
 		#[cfg(debug_assertions)]
 		trace!("Scan {} - {}", sprint_key(&rng.start), sprint_key(&rng.end));
+		self.metrics.read();
 		match self {
 			#[cfg(feature = "kv-mem")]
 			Transaction {
@@ -778,6 +934,7 @@ impl Transaction {
 		#[cfg(debug_assertions)]
 		trace!("Scan paged {} - {}", sprint_key(&page.range.start), sprint_key(&page.range.end));
 		let range = page.range.clone();
+		self.metrics.read();
 		let res = match self {
 			#[cfg(feature = "kv-mem")]
 			Transaction {
@@ -846,6 +1003,8 @@ impl Transaction {
 		let key = key.into();
 		#[cfg(debug_assertions)]
 		trace!("Putc {} if {:?} => {:?}", sprint_key(&key), chk, val);
+		self.track(&key).await?;
+		self.metrics.write();
 		match self {
 			#[cfg(feature = "kv-mem")]
 			Transaction {
@@ -892,6 +1051,8 @@ impl Transaction {
 		let key = key.into();
 		#[cfg(debug_assertions)]
 		trace!("Delc {} if {:?}", sprint_key(&key), chk);
+		self.track(&key).await?;
+		self.metrics.write();
 		match self {
 			#[cfg(feature = "kv-mem")]
 			Transaction {
@@ -979,6 +1140,11 @@ impl Transaction {
 		};
 		#[cfg(debug_assertions)]
 		trace!("Delr {}..{} (limit: {limit})", sprint_key(&rng.start), sprint_key(&rng.end));
+		// Delete each key individually so that the
+		// open savepoints can record what was there
+		if !self.savepoints.is_empty() {
+			return self._delr(rng, limit).await;
+		}
 		match self {
 			#[cfg(feature = "kv-tikv")]
 			Transaction {
@@ -1543,6 +1709,29 @@ impl Transaction {
 		})
 	}
 
+	/// Retrieve all task definitions for a specific database.
+	pub async fn all_db_tasks(
+		&mut self,
+		ns: &str,
+		db: &str,
+	) -> Result<Arc<[DefineTaskStatement]>, Error> {
+		let key = crate::key::database::tk::prefix(ns, db);
+		Ok(if let Some(e) = self.cache.get(&key) {
+			if let Entry::Tks(v) = e {
+				v
+			} else {
+				unreachable!();
+			}
+		} else {
+			let beg = crate::key::database::tk::prefix(ns, db);
+			let end = crate::key::database::tk::suffix(ns, db);
+			let val = self.getr(beg..end, u32::MAX).await?;
+			let val = val.convert().into();
+			self.cache.set(key, Entry::Tks(Arc::clone(&val)));
+			val
+		})
+	}
+
 	/// Retrieve all model definitions for a specific database.
 	pub async fn all_db_models(
 		&mut self,
@@ -1631,7 +1820,8 @@ impl Transaction {
 			let beg = crate::key::table::fd::prefix(ns, db, tb);
 			let end = crate::key::table::fd::suffix(ns, db, tb);
 			let val = self.getr(beg..end, u32::MAX).await?;
-			let val = val.convert().into();
+			// Fields are processed after the fields their VALUE clauses depend on
+			let val = DefineFieldStatement::order(val.convert()).into();
 			self.cache.set(key, Entry::Fds(Arc::clone(&val)));
 			val
 		})
@@ -1873,6 +2063,20 @@ impl Transaction {
 		Ok(val.into())
 	}
 
+	/// Retrieve a specific task definition from a database.
+	pub async fn get_db_task(
+		&mut self,
+		ns: &str,
+		db: &str,
+		tk: &str,
+	) -> Result<DefineTaskStatement, Error> {
+		let key = crate::key::database::tk::new(ns, db, tk);
+		let val = self.get(key).await?.ok_or(Error::TkNotFound {
+			value: tk.to_owned(),
+		})?;
+		Ok(val.into())
+	}
+
 	/// Return the table stored at the lq address
 	pub async fn get_lq(
 		&mut self,
@@ -2423,6 +2627,20 @@ impl Transaction {
 				chn.send(bytes!("")).await?;
 			}
 		}
+		// Output TASKS
+		{
+			let tks = self.all_db_tasks(ns, db).await?;
+			if !tks.is_empty() {
+				chn.send(bytes!("-- ------------------------------")).await?;
+				chn.send(bytes!("-- TASKS")).await?;
+				chn.send(bytes!("-- ------------------------------")).await?;
+				chn.send(bytes!("")).await?;
+				for tk in tks.iter() {
+					chn.send(bytes!(format!("{tk};"))).await?;
+				}
+				chn.send(bytes!("")).await?;
+			}
+		}
 		// Output TABLES
 		{
 			let tbs = self.all_tb(ns, db).await?;
@@ -2653,6 +2871,29 @@ impl Transaction {
 		Ok(id)
 	}
 
+	/// Get the next record id for a table which generates incrementing ids
+	pub(crate) async fn get_next_record_id(
+		&mut self,
+		ns: &str,
+		db: &str,
+		tb: &str,
+	) -> Result<i64, Error> {
+		let key = crate::key::table::sq::new(ns, db, tb);
+		let mut next = match self.get(key.clone()).await? {
+			Some(v) => match <[u8; 8]>::try_from(v.as_slice()) {
+				Ok(v) => i64::from_be_bytes(v) + 1,
+				Err(_) => return Err(Error::Unreachable("Transaction::get_next_record_id")),
+			},
+			None => 1,
+		};
+		// Skip any ids which are already in use
+		while self.exi(crate::key::thing::new(ns, db, tb, &next.into()).encode()?).await? {
+			next += 1;
+		}
+		self.set(key, next.to_be_bytes().to_vec()).await?;
+		Ok(next)
+	}
+
 	// remove_tb_id removes the given tb id from the sequence.
 	#[allow(unused)]
 	pub(crate) async fn remove_tb_id(&mut self, ns: u32, db: u32, tb: u32) -> Result<(), Error> {
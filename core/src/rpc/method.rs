@@ -8,6 +8,7 @@ pub enum Method {
 	Signin,
 	Invalidate,
 	Authenticate,
+	Revoke,
 	Kill,
 	Live,
 	Set,
@@ -22,6 +23,7 @@ pub enum Method {
 	Delete,
 	Version,
 	Query,
+	Stream,
 	Relate,
 	Run,
 }
@@ -39,6 +41,7 @@ impl Method {
 			"signin" => Self::Signin,
 			"invalidate" => Self::Invalidate,
 			"authenticate" => Self::Authenticate,
+			"revoke" => Self::Revoke,
 			"kill" => Self::Kill,
 			"live" => Self::Live,
 			"let" | "set" => Self::Set,
@@ -53,6 +56,7 @@ impl Method {
 			"delete" => Self::Delete,
 			"version" => Self::Version,
 			"query" => Self::Query,
+			"stream" => Self::Stream,
 			"relate" => Self::Relate,
 			"run" => Self::Run,
 			_ => Self::Unknown,
@@ -71,6 +75,7 @@ impl Method {
 			Self::Signin => "signin",
 			Self::Invalidate => "invalidate",
 			Self::Authenticate => "authenticate",
+			Self::Revoke => "revoke",
 			Self::Kill => "kill",
 			Self::Live => "live",
 			Self::Set => "set",
@@ -85,6 +90,7 @@ impl Method {
 			Self::Delete => "delete",
 			Self::Version => "version",
 			Self::Query => "query",
+			Self::Stream => "stream",
 			Self::Relate => "relate",
 			Self::Run => "run",
 		}
@@ -110,8 +116,9 @@ impl Method {
 				| Method::Update | Method::Upsert
 				| Method::Merge | Method::Patch
 				| Method::Delete | Method::Version
-				| Method::Query | Method::Relate
-				| Method::Run | Method::Unknown
+				| Method::Query | Method::Stream
+				| Method::Relate | Method::Run
+				| Method::Unknown
 		)
 	}
 }
@@ -3,7 +3,7 @@ use std::collections::BTreeMap;
 use uuid::Uuid;
 
 use crate::{
-	dbs::{QueryType, Response, Session},
+	dbs::{QueryType, Response, ResultStream, Session},
 	kvs::Datastore,
 	rpc::args::Take,
 	sql::{Array, Function, Model, Statement, Strand, Value},
@@ -46,6 +46,7 @@ pub trait RpcContext {
 			Method::Authenticate => {
 				self.authenticate(params).await.map(Into::into).map_err(Into::into)
 			}
+			Method::Revoke => self.revoke(params).await.map(Into::into).map_err(Into::into),
 			Method::Kill => self.kill(params).await.map(Into::into).map_err(Into::into),
 			Method::Live => self.live(params).await.map(Into::into).map_err(Into::into),
 			Method::Set => self.set(params).await.map(Into::into).map_err(Into::into),
@@ -60,6 +61,8 @@ pub trait RpcContext {
 			Method::Delete => self.delete(params).await.map(Into::into).map_err(Into::into),
 			Method::Version => self.version(params).await.map(Into::into).map_err(Into::into),
 			Method::Query => self.query(params).await.map(Into::into).map_err(Into::into),
+			// Streaming needs a transport which can send the batches of records
+			Method::Stream => Err(RpcError::MethodNotFound),
 			Method::Relate => self.relate(params).await.map(Into::into).map_err(Into::into),
 			Method::Run => self.run(params).await.map(Into::into).map_err(Into::into),
 			Method::Unknown => Err(RpcError::MethodNotFound),
@@ -80,6 +83,8 @@ pub trait RpcContext {
 			Method::Delete => self.delete(params).await.map(Into::into).map_err(Into::into),
 			Method::Version => self.version(params).await.map(Into::into).map_err(Into::into),
 			Method::Query => self.query(params).await.map(Into::into).map_err(Into::into),
+			// Streaming needs a transport which can send the batches of records
+			Method::Stream => Err(RpcError::MethodNotFound),
 			Method::Relate => self.relate(params).await.map(Into::into).map_err(Into::into),
 			Method::Run => self.run(params).await.map(Into::into).map_err(Into::into),
 			Method::Unknown => Err(RpcError::MethodNotFound),
@@ -146,6 +151,14 @@ pub trait RpcContext {
 		Ok(Value::None)
 	}
 
+	async fn revoke(&mut self, params: Array) -> Result<impl Into<Data>, RpcError> {
+		let Ok(Value::Strand(token)) = params.needs_one() else {
+			return Err(RpcError::InvalidParams);
+		};
+		crate::iam::revoke::token(self.kvs(), &token.0).await?;
+		Ok(Value::None)
+	}
+
 	// ------------------------------
 	// Methods for identification
 	// ------------------------------
@@ -207,7 +220,7 @@ pub trait RpcContext {
 		};
 		// Execute the query on the database
 		// let mut res = self.query_with(Value::from(sql), Object::from(var)).await?;
-		let mut res = self.query_inner(Value::from(sql), Some(var)).await?;
+		let mut res = self.query_inner(Value::from(sql), Some(var), None).await?;
 		// Extract the first query result
 		let response = res.remove(0);
 		response.result.map_err(Into::into)
@@ -226,7 +239,7 @@ pub trait RpcContext {
 			=> &self.vars()
 		};
 		// Execute the query on the database
-		let mut res = self.query_inner(Value::from(sql), Some(var)).await?;
+		let mut res = self.query_inner(Value::from(sql), Some(var), None).await?;
 		// Extract the first query result
 		let response = res.remove(0);
 		response.result.map_err(Into::into)
@@ -532,25 +545,17 @@ pub trait RpcContext {
 	// ------------------------------
 
 	async fn query(&self, params: Array) -> Result<impl Into<Data>, RpcError> {
-		let Ok((query, o)) = params.needs_one_or_two() else {
-			return Err(RpcError::InvalidParams);
-		};
-		if !(query.is_query() || query.is_strand()) {
-			return Err(RpcError::InvalidParams);
-		}
-
-		let o = match o {
-			Value::Object(v) => Some(v),
-			Value::None | Value::Null => None,
-			_ => return Err(RpcError::InvalidParams),
-		};
+		self.query_params(params, None).await
+	}
 
-		// Specify the query parameters
-		let vars = match o {
-			Some(mut v) => Some(mrg! {v.0, &self.vars()}),
-			None => Some(self.vars().clone()),
-		};
-		self.query_inner(query, vars).await
+	/// Run a query, sending the records of large SELECT statements to the stream
+	/// in batches as they are produced, instead of collecting them in the response
+	async fn query_with_stream(
+		&self,
+		params: Array,
+		stream: ResultStream,
+	) -> Result<impl Into<Data>, RpcError> {
+		self.query_params(params, Some(stream)).await
 	}
 
 	// ------------------------------
@@ -597,19 +602,52 @@ pub trait RpcContext {
 	// Private methods
 	// ------------------------------
 
+	async fn query_params(
+		&self,
+		params: Array,
+		stream: Option<ResultStream>,
+	) -> Result<Vec<Response>, RpcError> {
+		let Ok((query, o)) = params.needs_one_or_two() else {
+			return Err(RpcError::InvalidParams);
+		};
+		if !(query.is_query() || query.is_strand()) {
+			return Err(RpcError::InvalidParams);
+		}
+
+		let o = match o {
+			Value::Object(v) => Some(v),
+			Value::None | Value::Null => None,
+			_ => return Err(RpcError::InvalidParams),
+		};
+
+		// Specify the query parameters
+		let vars = match o {
+			Some(mut v) => Some(mrg! {v.0, &self.vars()}),
+			None => Some(self.vars().clone()),
+		};
+		self.query_inner(query, vars, stream).await
+	}
+
 	async fn query_inner(
 		&self,
 		query: Value,
 		vars: Option<BTreeMap<String, Value>>,
+		stream: Option<ResultStream>,
 	) -> Result<Vec<Response>, RpcError> {
 		// If no live query handler force realtime off
 		if !Self::LQ_SUPPORT && self.session().rt {
 			return Err(RpcError::BadLQConfig);
 		}
 		// Execute the query on the database
-		let res = match query {
-			Value::Query(sql) => self.kvs().process(sql, self.session(), vars).await?,
-			Value::Strand(sql) => self.kvs().execute(&sql, self.session(), vars).await?,
+		let res = match (query, stream) {
+			(Value::Query(sql), None) => self.kvs().process(sql, self.session(), vars).await?,
+			(Value::Strand(sql), None) => self.kvs().execute(&sql, self.session(), vars).await?,
+			(Value::Query(sql), Some(stream)) => {
+				self.kvs().process_with_stream(sql, self.session(), vars, stream).await?
+			}
+			(Value::Strand(sql), Some(stream)) => {
+				self.kvs().execute_with_stream(&sql, self.session(), vars, stream).await?
+			}
 			_ => unreachable!(),
 		};
 
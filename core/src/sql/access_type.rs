@@ -33,8 +33,10 @@ impl AccessType {
 	#[allow(unreachable_patterns)]
 	pub fn can_issue_grants(&self) -> bool {
 		match self {
-			// The grants for JWT and record access methods are JWT
-			AccessType::Jwt(_) | AccessType::Record(_) => false,
+			// The grants for JWT access methods are JWT
+			AccessType::Jwt(_) => false,
+			// Record access methods can grant refresh tokens
+			AccessType::Record(ac) => ac.refresh,
 			// TODO(gguillemas): This arm should be reachable by the bearer access method
 			_ => unreachable!(),
 		}
@@ -167,13 +169,17 @@ pub struct JwtAccessVerifyJwks {
 	pub url: String,
 }
 
-#[revisioned(revision = 1)]
+#[revisioned(revision = 2)]
 #[derive(Debug, Serialize, Deserialize, Hash, Clone, Eq, PartialEq, PartialOrd)]
 #[cfg_attr(feature = "arbitrary", derive(arbitrary::Arbitrary))]
 pub struct RecordAccess {
 	pub signup: Option<Value>,
 	pub signin: Option<Value>,
 	pub jwt: JwtAccess,
+	// Whether refresh tokens are issued alongside access tokens
+	// Refresh tokens expire after the grant duration of the access method
+	#[revision(start = 2)]
+	pub refresh: bool,
 }
 
 impl Default for RecordAccess {
@@ -184,6 +190,7 @@ impl Default for RecordAccess {
 			jwt: JwtAccess {
 				..Default::default()
 			},
+			refresh: false,
 		}
 	}
 }
@@ -203,6 +210,9 @@ impl Display for AccessType {
 					write!(f, " SIGNIN {v}")?
 				}
 				write!(f, " WITH JWT {}", ac.jwt)?;
+				if ac.refresh {
+					f.write_str(" WITH REFRESH")?
+				}
 			}
 		}
 		Ok(())
@@ -227,6 +237,9 @@ impl InfoStructure for AccessType {
 					acc.insert("signin".to_string(), signin.structure());
 				}
 				acc.insert("jwt".to_string(), ac.jwt.structure());
+				if ac.refresh {
+					acc.insert("refresh".to_string(), true.into());
+				}
 			}
 		};
 
@@ -324,17 +324,19 @@ pub(crate) trait Difference<T> {
 }
 
 impl Difference<Array> for Array {
-	fn difference(self, mut other: Array) -> Array {
+	fn difference(self, other: Array) -> Array {
 		let mut out = Array::new();
-		for v in self.into_iter() {
-			if let Some(pos) = other.iter().position(|w| v == *w) {
-				other.remove(pos);
-			} else {
+		for v in self.iter() {
+			if !other.contains(v) {
+				out.push(v.clone());
+			}
+		}
+		for v in other.into_iter() {
+			if !self.contains(&v) {
 				out.push(v);
 			}
 		}
-		out.append(&mut other);
-		out
+		out.uniq()
 	}
 }
 
@@ -364,11 +366,10 @@ pub(crate) trait Intersect<T> {
 }
 
 impl Intersect<Self> for Array {
-	fn intersect(self, mut other: Self) -> Self {
+	fn intersect(self, other: Self) -> Self {
 		let mut out = Self::new();
 		for v in self.0.into_iter() {
-			if let Some(pos) = other.iter().position(|w| v == *w) {
-				other.remove(pos);
+			if other.contains(&v) && !out.contains(&v) {
 				out.push(v);
 			}
 		}
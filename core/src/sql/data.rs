@@ -10,7 +10,7 @@ use revision::revisioned;
 use serde::{Deserialize, Serialize};
 use std::fmt::{self, Display, Formatter};
 
-#[revisioned(revision = 1)]
+#[revisioned(revision = 2)]
 #[derive(Clone, Debug, Eq, PartialEq, PartialOrd, Serialize, Deserialize, Hash)]
 #[cfg_attr(feature = "arbitrary", derive(arbitrary::Arbitrary))]
 #[non_exhaustive]
@@ -25,6 +25,9 @@ pub enum Data {
 	SingleExpression(Value),
 	ValuesExpression(Vec<Vec<(Idiom, Value)>>),
 	UpdateExpression(Vec<(Idiom, Operator, Value)>),
+	/// A JSON Merge Patch (RFC 7386), where NULL removes a field
+	#[revision(start = 2)]
+	MergePatchExpression(Value),
 }
 
 impl Default for Data {
@@ -42,7 +45,7 @@ impl Data {
 		opt: &Options,
 	) -> Result<Option<Value>, Error> {
 		match self {
-			Self::MergeExpression(v) => match v {
+			Self::MergeExpression(v) | Self::MergePatchExpression(v) => match v {
 				Value::Param(v) => Ok(v.compute(stk, ctx, opt, None).await?.rid().some()),
 				Value::Object(_) => Ok(v.rid().some()),
 				_ => Ok(None),
@@ -89,6 +92,7 @@ impl Display for Data {
 			),
 			Self::PatchExpression(v) => write!(f, "PATCH {v}"),
 			Self::MergeExpression(v) => write!(f, "MERGE {v}"),
+			Self::MergePatchExpression(v) => write!(f, "MERGE PATCH {v}"),
 			Self::ReplaceExpression(v) => write!(f, "REPLACE {v}"),
 			Self::ContentExpression(v) => write!(f, "CONTENT {v}"),
 			Self::SingleExpression(v) => Display::fmt(v, f),
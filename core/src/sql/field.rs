@@ -3,7 +3,7 @@ use crate::dbs::Options;
 use crate::doc::CursorDoc;
 use crate::err::Error;
 use crate::sql::statements::info::InfoStructure;
-use crate::sql::{fmt::Fmt, Idiom, Part, Value};
+use crate::sql::{fmt::Fmt, param::ParamCollector, Array, Cond, Idiom, Orders, Part, Value};
 use crate::syn;
 use reblessive::tree::Stk;
 use revision::revisioned;
@@ -30,6 +30,84 @@ impl Fields {
 	pub fn other(&self) -> impl Iterator<Item = &Field> {
 		self.0.iter().filter(|v| !matches!(v, Field::All))
 	}
+	/// Check to see if any field is computed over all of the selected rows
+	pub fn has_windows(&self) -> bool {
+		self.0.iter().any(|v| matches!(v, Field::Window { .. }))
+	}
+	/// Check to see if any field is an aggregate over a subset of the grouped rows
+	pub fn has_filters(&self) -> bool {
+		self.0.iter().any(|v| matches!(v, Field::Filter { .. }))
+	}
+	/// Check to see if any filtered aggregate has the same name as another field
+	pub fn has_filter_conflicts(&self) -> bool {
+		let names: Vec<(Idiom, bool)> = self
+			.other()
+			.filter_map(|v| match v {
+				Field::Single {
+					expr,
+					alias,
+				}
+				| Field::Window {
+					expr,
+					alias,
+					..
+				} => Some((alias.clone().unwrap_or_else(|| expr.to_idiom()), false)),
+				Field::Filter {
+					expr,
+					alias,
+					..
+				} => Some((alias.clone().unwrap_or_else(|| expr.to_idiom()), true)),
+				Field::All => None,
+			})
+			.collect();
+		names.iter().enumerate().any(|(i, (a, filtered))| {
+			*filtered && names.iter().enumerate().any(|(j, (b, _))| i != j && a == b)
+		})
+	}
+	/// Collect the parameters which these fields refer to, returning
+	/// false if a field contains a value which can not be walked
+	pub(crate) fn params<'a>(&'a self, out: &mut impl ParamCollector<'a>) -> bool {
+		self.0.iter().all(|v| match v {
+			Field::All => true,
+			Field::Single {
+				expr,
+				..
+			} => expr.params(out),
+			Field::Window {
+				expr,
+				order,
+				..
+			} => expr.params(out) && order.iter().flat_map(|v| v.iter()).all(|v| v.params(out)),
+			Field::Filter {
+				expr,
+				cond,
+				..
+			} => expr.params(out) && cond.0.params(out),
+		})
+	}
+	/// Check if these fields only read from the current document
+	pub(crate) fn reads_only_document(&self) -> bool {
+		self.0.iter().all(|v| match v {
+			Field::All => true,
+			Field::Single {
+				expr,
+				..
+			} => expr.reads_only_document(),
+			Field::Window {
+				expr,
+				order,
+				..
+			} => {
+				expr.reads_only_document()
+					&& order.iter().flat_map(|v| v.iter()).all(|v| v.reads_only_document())
+			}
+			Field::Filter {
+				expr,
+				cond,
+				..
+			} => expr.reads_only_document() && cond.0.reads_only_document(),
+		})
+	}
 	/// Check to see if this field is a single VALUE clause
 	pub fn single(&self) -> Option<&Field> {
 		match (self.0.len(), self.1) {
@@ -237,13 +315,69 @@ impl Fields {
 						}
 					}
 				}
+				// The aggregate is computed once all of the rows are selected
+				Field::Window {
+					expr,
+					alias,
+					..
+				} => {
+					let name = alias
+						.as_ref()
+						.map(Cow::Borrowed)
+						.unwrap_or_else(|| Cow::Owned(expr.to_idiom()));
+					let x = match expr {
+						Value::Function(f) => match f.args().first() {
+							// If arguments, then pass the first value through
+							Some(v) => v.compute(stk, ctx, opt, Some(doc)).await?,
+							// If no function arguments, then compute the result
+							None => f.compute(stk, ctx, opt, Some(doc)).await?,
+						},
+						_ => expr.compute(stk, ctx, opt, Some(doc)).await?,
+					};
+					// Check if this is a single VALUE field expression
+					match self.single().is_some() {
+						false => out.set(stk, ctx, opt, name.as_ref(), x).await?,
+						true => out = x,
+					}
+				}
+				// The aggregate is only passed the rows which match the condition
+				Field::Filter {
+					expr,
+					cond,
+					alias,
+				} => {
+					let name = alias
+						.as_ref()
+						.map(Cow::Borrowed)
+						.unwrap_or_else(|| Cow::Owned(expr.to_idiom()));
+					// A row which doesn't match the condition is marked with an empty
+					// array, and the value of a row which matches is wrapped in an array,
+					// so that a NONE value is still passed to the aggregate
+					let x = match cond.compute(stk, ctx, opt, Some(doc)).await?.is_truthy() {
+						false => Value::from(Array::new()),
+						true => Value::from(Array::from(vec![match expr {
+							Value::Function(f) => match f.args().first() {
+								// If arguments, then pass the first value through
+								Some(v) => v.compute(stk, ctx, opt, Some(doc)).await?,
+								// If no function arguments, then compute the result
+								None => f.compute(stk, ctx, opt, Some(doc)).await?,
+							},
+							_ => expr.compute(stk, ctx, opt, Some(doc)).await?,
+						}])),
+					};
+					// Check if this is a single VALUE field expression
+					match self.single().is_some() {
+						false => out.set(stk, ctx, opt, name.as_ref(), x).await?,
+						true => out = x,
+					}
+				}
 			}
 		}
 		Ok(out)
 	}
 }
 
-#[revisioned(revision = 1)]
+#[revisioned(revision = 3)]
 #[derive(Clone, Debug, Default, Eq, PartialEq, PartialOrd, Serialize, Deserialize, Hash)]
 #[cfg_attr(feature = "arbitrary", derive(arbitrary::Arbitrary))]
 #[non_exhaustive]
@@ -257,6 +391,32 @@ pub enum Field {
 		/// The `quality` in `SELECT rating AS quality FROM ...`
 		alias: Option<Idiom>,
 	},
+	/// The 'math::sum(amount) OVER ()' in `SELECT math::sum(amount) OVER () FROM ...`
+	///
+	/// The aggregate is computed over all of the rows which are selected,
+	/// before any ORDER, START, or LIMIT clause is applied, and is added to
+	/// each row. With an ORDER BY, each row instead has the running aggregate
+	/// of the rows up to and including that row, in that order.
+	#[revision(start = 2)]
+	Window {
+		expr: Value,
+		/// The `ORDER BY time` in `OVER (ORDER BY time)`
+		order: Option<Orders>,
+		/// The `total` in `SELECT math::sum(amount) OVER () AS total FROM ...`
+		alias: Option<Idiom>,
+	},
+	/// The 'count() FILTER (WHERE open)' in `SELECT count() FILTER (WHERE open) FROM ... GROUP ALL`
+	///
+	/// Only the grouped rows which match the condition are aggregated, while
+	/// the other aggregates in the same query are passed all of the rows.
+	#[revision(start = 3)]
+	Filter {
+		expr: Value,
+		/// The `WHERE open` in `FILTER (WHERE open)`
+		cond: Cond,
+		/// The `total` in `SELECT count() FILTER (WHERE open) AS total FROM ...`
+		alias: Option<Idiom>,
+	},
 }
 
 impl Display for Field {
@@ -275,6 +435,36 @@ impl Display for Field {
 					Ok(())
 				}
 			}
+			Self::Window {
+				expr,
+				order,
+				alias,
+			} => {
+				Display::fmt(expr, f)?;
+				match order {
+					Some(order) => write!(f, " OVER ({order})")?,
+					None => f.write_str(" OVER ()")?,
+				}
+				if let Some(alias) = alias {
+					f.write_str(" AS ")?;
+					Display::fmt(alias, f)
+				} else {
+					Ok(())
+				}
+			}
+			Self::Filter {
+				expr,
+				cond,
+				alias,
+			} => {
+				write!(f, "{expr} FILTER ({cond})")?;
+				if let Some(alias) = alias {
+					f.write_str(" AS ")?;
+					Display::fmt(alias, f)
+				} else {
+					Ok(())
+				}
+			}
 		}
 	}
 }
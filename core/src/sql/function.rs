@@ -290,6 +290,8 @@ impl Function {
 							)
 						})
 						.await?;
+					// Scripts may make network requests
+					ctx.set_side_effect();
 					// Run the script function
 					fnc::script::run(ctx, opt, doc, s, a).await
 				}
@@ -3,7 +3,9 @@ use crate::ctx::Context;
 use crate::dbs::Options;
 use crate::doc::CursorDoc;
 use crate::err::Error;
-use crate::sql::{escape::escape_rid, Array, Number, Object, Strand, Thing, Uuid, Value};
+use crate::sql::{
+	escape::escape_rid, param::ParamCollector, Array, Number, Object, Strand, Thing, Uuid, Value,
+};
 use nanoid::nanoid;
 use reblessive::tree::Stk;
 use revision::revisioned;
@@ -180,6 +182,15 @@ impl Display for Id {
 }
 
 impl Id {
+	/// Collect the parameters which this ID refers to, returning
+	/// false if the ID contains a value which can not be walked
+	pub(crate) fn params<'a>(&'a self, out: &mut impl ParamCollector<'a>) -> bool {
+		match self {
+			Id::Array(v) => v.iter().all(|v| v.params(out)),
+			Id::Object(v) => v.values().all(|v| v.params(out)),
+			_ => true,
+		}
+	}
 	/// Process this type returning a computed simple Value
 	pub(crate) async fn compute(
 		&self,
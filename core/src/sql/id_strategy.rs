@@ -0,0 +1,178 @@
+use crate::dbs::Options;
+use crate::err::Error;
+use crate::sql::statements::info::InfoStructure;
+use crate::sql::{Id, Uuid, Value};
+use chrono::Utc;
+use once_cell::sync::Lazy;
+use rand::Rng;
+use revision::revisioned;
+use serde::{Deserialize, Serialize};
+use std::fmt::{self, Display};
+use std::sync::Mutex;
+use ulid::{Generator, Ulid};
+
+/// The start of the snowflake epoch, 2024-01-01T00:00:00Z, in milliseconds
+const SNOWFLAKE_EPOCH: i64 = 1_704_067_200_000;
+
+/// How the ids of new records in a table are generated, when no id is given.
+///
+/// `ULID`, `UUID`, and `SNOWFLAKE` ids are ordered by the time at which
+/// they were generated, and are strictly increasing on each node, even when
+/// many ids are generated within the same millisecond, or when the system
+/// clock moves backwards.
+///
+/// `RAND` and `ULID` ids contain 120 and 80 random bits respectively, and
+/// `UUID` ids contain 74 random bits, so collisions are vanishingly
+/// unlikely, even between nodes. `SNOWFLAKE` ids contain a 10 bit node
+/// number derived from the id of the node, and a 12 bit sequence number,
+/// so ids generated on different nodes can only collide if both nodes
+/// derive the same node number. `INCREMENT` ids are allocated from a
+/// counter stored in the table, which is updated in the same transaction
+/// as the record, so concurrent transactions either wait for each other
+/// or conflict and are retried. The counter skips any ids which are
+/// already in use, such as ids which were given explicitly.
+#[revisioned(revision = 1)]
+#[derive(Clone, Copy, Debug, Default, Eq, PartialEq, PartialOrd, Serialize, Deserialize, Hash)]
+#[cfg_attr(feature = "arbitrary", derive(arbitrary::Arbitrary))]
+#[non_exhaustive]
+pub enum IdStrategy {
+	#[default]
+	Rand,
+	Ulid,
+	Uuid,
+	Snowflake,
+	Increment,
+}
+
+/// The state of the time-ordered id generators on this node
+struct Generators {
+	// The generator for monotonic ULIDs
+	ulid: Generator,
+	// The millisecond and random bits of the last UUID
+	uuid: (u64, u128),
+	// The millisecond and sequence number of the last snowflake id
+	snowflake: (i64, i64),
+}
+
+static GENERATORS: Lazy<Mutex<Generators>> = Lazy::new(|| {
+	Mutex::new(Generators {
+		ulid: Generator::new(),
+		uuid: (0, 0),
+		snowflake: (0, 0),
+	})
+});
+
+impl IdStrategy {
+	/// Generate a new id, for the strategies which do not need a transaction
+	pub(crate) fn generate(&self, opt: &Options) -> Result<Option<Id>, Error> {
+		Ok(match self {
+			Self::Rand => Some(Id::rand()),
+			Self::Ulid => Some(Self::ulid()),
+			Self::Uuid => Some(Self::uuid()),
+			Self::Snowflake => Some(Self::snowflake(&opt.id()?)),
+			Self::Increment => None,
+		})
+	}
+	fn ulid() -> Id {
+		let mut generators = GENERATORS.lock().unwrap_or_else(|e| e.into_inner());
+		// The generator only fails once 2^80 ids are generated in a millisecond
+		let ulid = generators.ulid.generate().unwrap_or_else(|_| Ulid::new());
+		Id::String(ulid.to_string())
+	}
+	fn uuid() -> Id {
+		// The random bits are incremented within the same millisecond
+		const RANDOM: u128 = (1 << 74) - 1;
+		let mut generators = GENERATORS.lock().unwrap_or_else(|e| e.into_inner());
+		let (last, bits) = generators.uuid;
+		let now = Utc::now().timestamp_millis().max(0) as u64;
+		let (ms, bits) = if now > last {
+			(now, rand::thread_rng().gen::<u128>() & RANDOM)
+		} else if bits < RANDOM {
+			(last, bits + 1)
+		} else {
+			(last + 1, 0)
+		};
+		generators.uuid = (ms, bits);
+		// Lay out the bits in the UUID version 7 format
+		let mut random = [0; 10];
+		random[..2].copy_from_slice(&((bits >> 62) as u16).to_be_bytes());
+		random[2..].copy_from_slice(&(bits as u64).to_be_bytes());
+		let uuid = uuid::Builder::from_unix_timestamp_millis(ms, &random).into_uuid();
+		Id::String(Uuid(uuid).to_raw())
+	}
+	fn snowflake(node: &uuid::Uuid) -> Id {
+		// The sequence number is incremented within the same millisecond
+		const SEQUENCE: i64 = (1 << 12) - 1;
+		let node = (node.as_u128() % 1024) as i64;
+		let mut generators = GENERATORS.lock().unwrap_or_else(|e| e.into_inner());
+		let (last, seq) = generators.snowflake;
+		let now = (Utc::now().timestamp_millis() - SNOWFLAKE_EPOCH).max(0);
+		let (ms, seq) = if now > last {
+			(now, 0)
+		} else if seq < SEQUENCE {
+			(last, seq + 1)
+		} else {
+			(last + 1, 0)
+		};
+		generators.snowflake = (ms, seq);
+		Id::Number(ms << 22 | node << 12 | seq)
+	}
+}
+
+impl Display for IdStrategy {
+	fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {
+		f.write_str(match self {
+			Self::Rand => "RAND",
+			Self::Ulid => "ULID",
+			Self::Uuid => "UUID",
+			Self::Snowflake => "SNOWFLAKE",
+			Self::Increment => "INCREMENT",
+		})
+	}
+}
+
+impl InfoStructure for IdStrategy {
+	fn structure(self) -> Value {
+		self.to_string().into()
+	}
+}
+
+#[cfg(test)]
+mod tests {
+	use super::*;
+
+	fn generate(strategy: IdStrategy, node: uuid::Uuid) -> Id {
+		strategy.generate(&Options::default().with_id(node)).unwrap().unwrap()
+	}
+
+	#[test]
+	fn time_ordered_ids_are_strictly_increasing() {
+		for strategy in [IdStrategy::Ulid, IdStrategy::Uuid, IdStrategy::Snowflake] {
+			let node = uuid::Uuid::new_v4();
+			let ids: Vec<_> = (0..10_000).map(|_| generate(strategy, node)).collect();
+			for pair in ids.windows(2) {
+				assert!(pair[0] < pair[1], "{strategy}: {} is not before {}", pair[0], pair[1]);
+			}
+		}
+	}
+
+	#[test]
+	fn uuids_are_version_7() {
+		let Id::String(id) = generate(IdStrategy::Uuid, uuid::Uuid::nil()) else {
+			panic!("Expected a string id");
+		};
+		let uuid = uuid::Uuid::parse_str(&id).unwrap();
+		assert_eq!(uuid.get_version_num(), 7);
+		assert_eq!(uuid.get_variant(), uuid::Variant::RFC4122);
+	}
+
+	#[test]
+	fn snowflakes_contain_the_node() {
+		let node = uuid::Uuid::from_u128(1024 + 5);
+		let Id::Number(id) = generate(IdStrategy::Snowflake, node) else {
+			panic!("Expected a number id");
+		};
+		assert!(id > 0);
+		assert_eq!(id >> 12 & 0x3ff, 5);
+	}
+}
@@ -5,8 +5,9 @@ use crate::err::Error;
 use crate::sql::statements::info::InfoStructure;
 use crate::sql::{
 	fmt::{fmt_separated_by, Fmt},
+	param::ParamCollector,
 	part::Next,
-	paths::{ID, IN, META, OUT},
+	paths::{DELETED, ID, IN, META, OUT, VERSION},
 	Part, Value,
 };
 use md5::{Digest, Md5};
@@ -111,7 +112,12 @@ impl Idiom {
 		self.0
 			.iter()
 			.filter(|&p| {
-				matches!(p, Part::Field(_) | Part::Start(_) | Part::Value(_) | Part::Graph(_))
+				matches!(
+					p,
+					Part::Field(_)
+						| Part::Start(_) | Part::Value(_)
+						| Part::Graph(_) | Part::Recurse(..)
+				)
 			})
 			.cloned()
 			.collect::<Vec<_>>()
@@ -133,6 +139,14 @@ impl Idiom {
 	pub(crate) fn is_meta(&self) -> bool {
 		self.0.len() == 1 && self.0[0].eq(&META[0])
 	}
+	/// Check if this Idiom is a 'version' field
+	pub(crate) fn is_version(&self) -> bool {
+		self.0.len() == 1 && self.0[0].eq(&VERSION[0])
+	}
+	/// Check if this Idiom is a '__deleted' field
+	pub(crate) fn is_deleted(&self) -> bool {
+		self.0.len() == 1 && self.0[0].eq(&DELETED[0])
+	}
 	/// Check if this is an expression with multiple yields
 	pub(crate) fn is_multi_yield(&self) -> bool {
 		self.iter().any(Self::split_multi_yield)
@@ -154,6 +168,25 @@ impl Idiom {
 	pub(crate) fn writeable(&self) -> bool {
 		self.0.iter().any(|v| v.writeable())
 	}
+	/// Collect the parameters which this Idiom refers to, returning
+	/// false if the Idiom contains a value which can not be walked
+	pub(crate) fn params<'a>(&'a self, out: &mut impl ParamCollector<'a>) -> bool {
+		self.0.iter().all(|v| v.params(out))
+	}
+	/// Check if this Idiom only reads from the current document, returning
+	/// false if it could follow a record link or graph edge to another record
+	pub(crate) fn reads_only_document(&self) -> bool {
+		match self.0.split_first() {
+			// A nested field may be a record link, so only indexes and methods can follow a field
+			Some((Part::Field(_), rest)) => rest.iter().all(|v| match v {
+				Part::First | Part::Last | Part::Index(_) | Part::Flatten => true,
+				Part::Method(_, args) => args.iter().all(Value::reads_only_document),
+				_ => false,
+			}),
+			Some((Part::Start(v), [])) => v.reads_only_document(),
+			_ => false,
+		}
+	}
 	/// Process this type returning a computed simple Value
 	pub(crate) async fn compute(
 		&self,
@@ -192,6 +225,10 @@ impl Display for Idiom {
 				self.0.iter().enumerate().map(|args| {
 					Fmt::new(args, |(i, p), f| match (i, p) {
 						(0, Part::Field(v)) => Display::fmt(v, f),
+						// Separate any parts which follow a recursion depth
+						(i, p) if i > 0 && matches!(self.0[i - 1], Part::Recurse(..)) => {
+							write!(f, " {p}")
+						}
 						_ => Display::fmt(p, f),
 					})
 				}),
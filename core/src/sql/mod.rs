@@ -32,6 +32,7 @@ pub(crate) mod geometry;
 pub(crate) mod graph;
 pub(crate) mod group;
 pub(crate) mod id;
+pub(crate) mod id_strategy;
 pub(crate) mod ident;
 pub(crate) mod idiom;
 pub(crate) mod kind;
@@ -108,6 +109,7 @@ pub use self::graph::Graph;
 pub use self::group::Group;
 pub use self::group::Groups;
 pub use self::id::Id;
+pub use self::id_strategy::IdStrategy;
 pub use self::ident::Ident;
 pub use self::idiom::Idiom;
 pub use self::idiom::Idioms;
@@ -23,10 +23,10 @@ impl Orders {
 					let b = rand::random::<f64>();
 					a.partial_cmp(&b)
 				}
-				false => match order.direction {
+				false => Self::compare_nulls(a, b, order).or_else(|| match order.direction {
 					true => a.compare(b, order, order.collate, order.numeric),
 					false => b.compare(a, order, order.collate, order.numeric),
-				},
+				}),
 			};
 			//
 			match o {
@@ -38,6 +38,19 @@ impl Orders {
 		}
 		Ordering::Equal
 	}
+	/// Place NONE and NULL values before or after all other values,
+	/// whatever the direction, leaving any other values to be compared
+	fn compare_nulls(a: &Value, b: &Value, order: &Order) -> Option<Ordering> {
+		let first = order.nulls?;
+		match (a.pick(order).is_none_or_null(), b.pick(order).is_none_or_null()) {
+			(true, true) => Some(Ordering::Equal),
+			(true, false) if first => Some(Ordering::Less),
+			(true, false) => Some(Ordering::Greater),
+			(false, true) if first => Some(Ordering::Greater),
+			(false, true) => Some(Ordering::Less),
+			(false, false) => None,
+		}
+	}
 }
 
 impl Deref for Orders {
@@ -61,7 +74,7 @@ impl fmt::Display for Orders {
 	}
 }
 
-#[revisioned(revision = 1)]
+#[revisioned(revision = 2)]
 #[derive(Clone, Debug, Default, Eq, PartialEq, PartialOrd, Serialize, Deserialize, Hash)]
 #[cfg_attr(feature = "arbitrary", derive(arbitrary::Arbitrary))]
 #[non_exhaustive]
@@ -72,6 +85,10 @@ pub struct Order {
 	pub numeric: bool,
 	/// true if the direction is ascending
 	pub direction: bool,
+	/// true if NONE and NULL values are placed first, false if they are
+	/// placed last, or none if they sort as the lowest of all values
+	#[revision(start = 2)]
+	pub nulls: Option<bool>,
 }
 
 impl Deref for Order {
@@ -97,6 +114,11 @@ impl fmt::Display for Order {
 			false => write!(f, " DESC")?,
 			true => (),
 		};
+		match self.nulls {
+			Some(true) => write!(f, " NULLS FIRST")?,
+			Some(false) => write!(f, " NULLS LAST")?,
+			None => (),
+		};
 		Ok(())
 	}
 }
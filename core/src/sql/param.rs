@@ -13,6 +13,23 @@ use std::{fmt, ops::Deref, str};
 
 pub(crate) const TOKEN: &str = "$surrealdb::private::sql::Param";
 
+/// Collects the parameters which a value refers to, while it is walked
+pub(crate) trait ParamCollector<'a> {
+	/// Collect a parameter which is referred to
+	fn param(&mut self, v: &'a Param);
+	/// Walk a value which contains a block, model, or statement, returning
+	/// false if it is not walked, and could therefore refer to any parameter
+	fn other(&mut self, _: &'a Value) -> bool {
+		false
+	}
+}
+
+impl<'a> ParamCollector<'a> for Vec<&'a Param> {
+	fn param(&mut self, v: &'a Param) {
+		self.push(v);
+	}
+}
+
 #[revisioned(revision = 1)]
 #[derive(Clone, Debug, Default, Eq, PartialEq, PartialOrd, Serialize, Deserialize, Hash)]
 #[serde(rename = "$surrealdb::private::sql::Param")]
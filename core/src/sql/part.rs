@@ -1,10 +1,12 @@
-use crate::sql::{fmt::Fmt, strand::no_nul_bytes, Graph, Ident, Idiom, Number, Value};
+use crate::sql::{
+	fmt::Fmt, param::ParamCollector, strand::no_nul_bytes, Graph, Ident, Idiom, Number, Value,
+};
 use revision::revisioned;
 use serde::{Deserialize, Serialize};
 use std::fmt;
 use std::str;
 
-#[revisioned(revision = 1)]
+#[revisioned(revision = 2)]
 #[derive(Clone, Debug, Eq, PartialEq, PartialOrd, Serialize, Deserialize, Hash)]
 #[cfg_attr(feature = "arbitrary", derive(arbitrary::Arbitrary))]
 #[non_exhaustive]
@@ -20,6 +22,9 @@ pub enum Part {
 	Value(Value),
 	Start(Value),
 	Method(#[serde(with = "no_nul_bytes")] String, Vec<Value>),
+	/// A chain of graph parts which is repeated up to the given depth
+	#[revision(start = 2)]
+	Recurse(Vec<Part>, Option<u32>),
 }
 
 impl From<i32> for Part {
@@ -81,9 +86,28 @@ impl Part {
 			Part::Where(v) => v.writeable(),
 			Part::Value(v) => v.writeable(),
 			Part::Method(_, v) => v.iter().any(Value::writeable),
+			Part::Recurse(v, _) => v.iter().any(Part::writeable),
 			_ => false,
 		}
 	}
+	/// Collect the parameters which this part refers to, returning
+	/// false if the part contains a value which can not be walked
+	pub(crate) fn params<'a>(&'a self, out: &mut impl ParamCollector<'a>) -> bool {
+		match self {
+			Part::Start(v) => v.params(out),
+			Part::Where(v) => v.params(out),
+			Part::Value(v) => v.params(out),
+			Part::Method(_, v) => v.iter().all(|v| v.params(out)),
+			Part::Recurse(v, _) => v.iter().all(|v| v.params(out)),
+			Part::Graph(v) => {
+				v.expr.params(out)
+					&& v.cond.as_ref().map_or(true, |v| v.0.params(out))
+					&& v.limit.as_ref().map_or(true, |v| v.0.params(out))
+					&& v.start.as_ref().map_or(true, |v| v.0.params(out))
+			}
+			_ => true,
+		}
+	}
 	/// Returns a yield if an alias is specified
 	pub(crate) fn alias(&self) -> Option<&Idiom> {
 		match self {
@@ -107,6 +131,15 @@ impl fmt::Display for Part {
 			Part::Graph(v) => write!(f, "{v}"),
 			Part::Value(v) => write!(f, "[{v}]"),
 			Part::Method(v, a) => write!(f, ".{v}({})", Fmt::comma_separated(a)),
+			Part::Recurse(v, d) => {
+				for p in v {
+					write!(f, "{p}")?;
+				}
+				match d {
+					Some(d) => write!(f, " DEPTH {d}"),
+					None => f.write_str(" DEPTH"),
+				}
+			}
 		}
 	}
 }
@@ -28,3 +28,7 @@ pub static OUT: Lazy<[Part; 1]> = Lazy::new(|| [Part::from("out")]);
 pub static META: Lazy<[Part; 1]> = Lazy::new(|| [Part::from("__")]);
 
 pub static EDGE: Lazy<[Part; 1]> = Lazy::new(|| [Part::from("__")]);
+
+pub static VERSION: Lazy<[Part; 1]> = Lazy::new(|| [Part::from("version")]);
+
+pub static DELETED: Lazy<[Part; 1]> = Lazy::new(|| [Part::from("__deleted")]);
@@ -1,5 +1,6 @@
 use crate::ctx::Context;
 use crate::dbs::Options;
+use crate::dbs::ResultStream;
 use crate::doc::CursorDoc;
 use crate::err::Error;
 use crate::sql::statements::rebuild::RebuildStatement;
@@ -7,11 +8,11 @@ use crate::sql::{
 	fmt::{Fmt, Pretty},
 	statements::{
 		AnalyzeStatement, BeginStatement, BreakStatement, CancelStatement, CommitStatement,
-		ContinueStatement, CreateStatement, DefineStatement, DeleteStatement, ForeachStatement,
-		IfelseStatement, InfoStatement, InsertStatement, KillStatement, LiveStatement,
-		OptionStatement, OutputStatement, RelateStatement, RemoveStatement, SelectStatement,
-		SetStatement, ShowStatement, SleepStatement, ThrowStatement, UpdateStatement,
-		UpsertStatement, UseStatement,
+		ContinueStatement, CreateStatement, DefineStatement, DeleteStatement, ExplainStatement,
+		ForeachStatement, IfelseStatement, InfoStatement, InsertStatement, KillStatement,
+		LiveStatement, OptionStatement, OutputStatement, RelateStatement, RemoveStatement,
+		RestoreStatement, SavepointStatement, SelectStatement, SetStatement, ShowStatement,
+		SleepStatement, ThrowStatement, UpdateStatement, UpsertStatement, UseStatement,
 	},
 	value::Value,
 };
@@ -55,7 +56,7 @@ impl Display for Statements {
 	}
 }
 
-#[revisioned(revision = 3)]
+#[revisioned(revision = 6)]
 #[derive(Clone, Debug, Eq, PartialEq, PartialOrd, Serialize, Deserialize, Store, Hash)]
 #[cfg_attr(feature = "arbitrary", derive(arbitrary::Arbitrary))]
 #[non_exhaustive]
@@ -91,16 +92,97 @@ pub enum Statement {
 	Rebuild(RebuildStatement),
 	#[revision(start = 3)]
 	Upsert(UpsertStatement),
+	#[revision(start = 4)]
+	Savepoint(SavepointStatement),
+	#[revision(start = 5)]
+	Explain(ExplainStatement),
+	#[revision(start = 6)]
+	Restore(RestoreStatement),
 }
 
+/// The types of statement, as returned by [`Statement::kind`]
+pub(crate) const KINDS: &[&str] = &[
+	"value",
+	"analyze",
+	"begin",
+	"break",
+	"continue",
+	"cancel",
+	"commit",
+	"create",
+	"define",
+	"delete",
+	"foreach",
+	"ifelse",
+	"info",
+	"insert",
+	"kill",
+	"live",
+	"option",
+	"output",
+	"relate",
+	"remove",
+	"select",
+	"set",
+	"show",
+	"sleep",
+	"update",
+	"throw",
+	"use",
+	"rebuild",
+	"upsert",
+	"savepoint",
+	"explain",
+	"restore",
+];
+
 impl Statement {
+	/// Get the type of this statement, for grouping statements in metrics
+	pub fn kind(&self) -> &'static str {
+		match self {
+			Self::Value(_) => "value",
+			Self::Analyze(_) => "analyze",
+			Self::Begin(_) => "begin",
+			Self::Break(_) => "break",
+			Self::Continue(_) => "continue",
+			Self::Cancel(_) => "cancel",
+			Self::Commit(_) => "commit",
+			Self::Create(_) => "create",
+			Self::Define(_) => "define",
+			Self::Delete(_) => "delete",
+			Self::Foreach(_) => "foreach",
+			Self::Ifelse(_) => "ifelse",
+			Self::Info(_) => "info",
+			Self::Insert(_) => "insert",
+			Self::Kill(_) => "kill",
+			Self::Live(_) => "live",
+			Self::Option(_) => "option",
+			Self::Output(_) => "output",
+			Self::Relate(_) => "relate",
+			Self::Remove(_) => "remove",
+			Self::Select(_) => "select",
+			Self::Set(_) => "set",
+			Self::Show(_) => "show",
+			Self::Sleep(_) => "sleep",
+			Self::Update(_) => "update",
+			Self::Throw(_) => "throw",
+			Self::Use(_) => "use",
+			Self::Rebuild(_) => "rebuild",
+			Self::Upsert(_) => "upsert",
+			Self::Savepoint(_) => "savepoint",
+			Self::Explain(_) => "explain",
+			Self::Restore(_) => "restore",
+		}
+	}
 	/// Get the statement timeout duration, if any
 	pub fn timeout(&self) -> Option<Duration> {
 		match self {
 			Self::Create(v) => v.timeout.as_ref().map(|v| *v.0),
 			Self::Delete(v) => v.timeout.as_ref().map(|v| *v.0),
+			Self::Explain(v) => v.what.timeout.as_ref().map(|v| *v.0),
 			Self::Insert(v) => v.timeout.as_ref().map(|v| *v.0),
 			Self::Relate(v) => v.timeout.as_ref().map(|v| *v.0),
+			Self::Restore(v) => v.timeout.as_ref().map(|v| *v.0),
 			Self::Select(v) => v.timeout.as_ref().map(|v| *v.0),
 			Self::Upsert(v) => v.timeout.as_ref().map(|v| *v.0),
 			Self::Update(v) => v.timeout.as_ref().map(|v| *v.0),
@@ -117,6 +199,7 @@ impl Statement {
 			Self::Create(v) => v.writeable(),
 			Self::Define(_) => true,
 			Self::Delete(v) => v.writeable(),
+			Self::Explain(v) => v.writeable(),
 			Self::Foreach(v) => v.writeable(),
 			Self::Ifelse(v) => v.writeable(),
 			Self::Info(_) => false,
@@ -128,6 +211,7 @@ impl Statement {
 			Self::Rebuild(_) => true,
 			Self::Relate(v) => v.writeable(),
 			Self::Remove(_) => true,
+			Self::Restore(v) => v.writeable(),
 			Self::Select(v) => v.writeable(),
 			Self::Set(v) => v.writeable(),
 			Self::Show(_) => false,
@@ -154,6 +238,7 @@ impl Statement {
 			Self::Create(v) => v.compute(stk, ctx, opt, doc).await,
 			Self::Delete(v) => v.compute(stk, ctx, opt, doc).await,
 			Self::Define(v) => v.compute(stk, ctx, opt, doc).await,
+			Self::Explain(v) => v.compute(stk, ctx, opt, doc).await,
 			Self::Foreach(v) => v.compute(stk, ctx, opt, doc).await,
 			Self::Ifelse(v) => v.compute(stk, ctx, opt, doc).await,
 			Self::Info(v) => v.compute(ctx, opt, doc).await,
@@ -164,6 +249,7 @@ impl Statement {
 			Self::Relate(v) => v.compute(stk, ctx, opt, doc).await,
 			Self::Rebuild(v) => v.compute(stk, ctx, opt, doc).await,
 			Self::Remove(v) => v.compute(ctx, opt, doc).await,
+			Self::Restore(v) => v.compute(stk, ctx, opt, doc).await,
 			Self::Select(v) => v.compute(stk, ctx, opt, doc).await,
 			Self::Set(v) => v.compute(stk, ctx, opt, doc).await,
 			Self::Show(v) => v.compute(ctx, opt, doc).await,
@@ -180,6 +266,28 @@ impl Statement {
 			_ => unreachable!(),
 		}
 	}
+	/// Process this type returning a computed simple Value, along with
+	/// a cursor for the next page of any paginated SELECT statement.
+	/// If a stream is specified, the records of a SELECT statement are
+	/// sent to the stream where possible, and NONE is returned instead.
+	pub(crate) async fn compute_page(
+		&self,
+		stk: &mut Stk,
+		ctx: &Context<'_>,
+		opt: &Options,
+		stream: Option<&ResultStream>,
+	) -> Result<(Value, Option<String>), Error> {
+		match self {
+			Self::Select(v) => match stream {
+				Some(stream) if v.streamable() => v
+					.compute_records(stk, ctx, opt, None, Some(stream))
+					.await
+					.map(|_| (Value::None, None)),
+				_ => v.compute_page(stk, ctx, opt, None).await,
+			},
+			_ => self.compute(stk, ctx, opt, None).await.map(|v| (v, None)),
+		}
+	}
 }
 
 impl Display for Statement {
@@ -195,6 +303,7 @@ impl Display for Statement {
 			Self::Create(v) => write!(Pretty::from(f), "{v}"),
 			Self::Define(v) => write!(Pretty::from(f), "{v}"),
 			Self::Delete(v) => write!(Pretty::from(f), "{v}"),
+			Self::Explain(v) => write!(Pretty::from(f), "{v}"),
 			Self::Foreach(v) => write!(Pretty::from(f), "{v}"),
 			Self::Insert(v) => write!(Pretty::from(f), "{v}"),
 			Self::Ifelse(v) => write!(Pretty::from(f), "{v}"),
@@ -206,6 +315,8 @@ impl Display for Statement {
 			Self::Rebuild(v) => write!(Pretty::from(f), "{v}"),
 			Self::Relate(v) => write!(Pretty::from(f), "{v}"),
 			Self::Remove(v) => write!(Pretty::from(f), "{v}"),
+			Self::Restore(v) => write!(Pretty::from(f), "{v}"),
+			Self::Savepoint(v) => write!(Pretty::from(f), "{v}"),
 			Self::Select(v) => write!(Pretty::from(f), "{v}"),
 			Self::Set(v) => write!(Pretty::from(f), "{v}"),
 			Self::Show(v) => write!(Pretty::from(f), "{v}"),
@@ -0,0 +1,88 @@
+use crate::ctx::Context;
+use crate::dbs::Options;
+use crate::doc::CursorDoc;
+use crate::err::Error;
+use crate::sql::Value;
+use derive::Store;
+use reblessive::tree::Stk;
+use revision::revisioned;
+use serde::{Deserialize, Serialize};
+use std::fmt::{self, Display};
+
+#[revisioned(revision = 1)]
+#[derive(Clone, Debug, Default, Eq, PartialEq, PartialOrd, Serialize, Deserialize, Store, Hash)]
+#[cfg_attr(feature = "arbitrary", derive(arbitrary::Arbitrary))]
+#[non_exhaustive]
+pub struct CaseStatement {
+	/// The value which is compared against each WHEN, if there is one
+	pub subject: Option<Value>,
+	/// Each WHEN value or condition followed by its THEN result
+	pub exprs: Vec<(Value, Value)>,
+	/// the final else result, if there is one
+	pub close: Option<Value>,
+}
+
+impl CaseStatement {
+	/// Check if we require a writeable transaction
+	pub(crate) fn writeable(&self) -> bool {
+		if self.subject.as_ref().map_or(false, |v| v.writeable()) {
+			return true;
+		}
+		for (when, then) in self.exprs.iter() {
+			if when.writeable() || then.writeable() {
+				return true;
+			}
+		}
+		self.close.as_ref().map_or(false, |v| v.writeable())
+	}
+	/// Process this type returning a computed simple Value
+	pub(crate) async fn compute(
+		&self,
+		stk: &mut Stk,
+		ctx: &Context<'_>,
+		opt: &Options,
+		doc: Option<&CursorDoc<'_>>,
+	) -> Result<Value, Error> {
+		match self.subject {
+			// Compare the subject against each WHEN value
+			Some(ref subject) => {
+				let subject = subject.compute(stk, ctx, opt, doc).await?;
+				for (ref when, ref then) in &self.exprs {
+					let v = when.compute(stk, ctx, opt, doc).await?;
+					if subject.equal(&v) {
+						return then.compute(stk, ctx, opt, doc).await;
+					}
+				}
+			}
+			// Treat each WHEN as a condition, as with IF
+			None => {
+				for (ref when, ref then) in &self.exprs {
+					let v = when.compute(stk, ctx, opt, doc).await?;
+					if v.is_truthy() {
+						return then.compute(stk, ctx, opt, doc).await;
+					}
+				}
+			}
+		}
+		match self.close {
+			Some(ref v) => v.compute(stk, ctx, opt, doc).await,
+			None => Ok(Value::Null),
+		}
+	}
+}
+
+impl Display for CaseStatement {
+	fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {
+		f.write_str("CASE")?;
+		if let Some(ref v) = self.subject {
+			write!(f, " {v}")?;
+		}
+		for (when, then) in self.exprs.iter() {
+			write!(f, " WHEN {when} THEN {then}")?;
+		}
+		if let Some(ref v) = self.close {
+			write!(f, " ELSE {v}")?;
+		}
+		f.write_str(" END")
+	}
+}
@@ -2,7 +2,7 @@ use crate::ctx::Context;
 use crate::dbs::{Iterator, Options, Statement};
 use crate::doc::CursorDoc;
 use crate::err::Error;
-use crate::sql::{Data, Output, Timeout, Value, Values};
+use crate::sql::{Array, Data, Output, Timeout, Value, Values};
 use derive::Store;
 use reblessive::tree::Stk;
 use revision::revisioned;
@@ -44,6 +44,61 @@ impl CreateStatement {
 	) -> Result<Value, Error> {
 		// Valid options?
 		opt.valid_for_db()?;
+		// Check if the content is an array of records
+		if let Some(Data::ContentExpression(v @ (Value::Array(_) | Value::Param(_)))) = &self.data {
+			// Ensure futures are stored
+			let opt = &opt.new_with_futures(false);
+			if let Value::Array(v) = v.compute(stk, ctx, opt, doc).await? {
+				return self.compute_batch(stk, ctx, opt, doc, v).await;
+			}
+		}
+		// Create the records
+		self.compute_records(stk, ctx, opt, doc).await
+	}
+	/// Create a separate record for each value in an array of content.
+	/// The records are created within the same transaction, so if any
+	/// of the records fails to be created then none of them are kept.
+	async fn compute_batch(
+		&self,
+		stk: &mut Stk,
+		ctx: &Context<'_>,
+		opt: &Options,
+		doc: Option<&CursorDoc<'_>>,
+		content: Array,
+	) -> Result<Value, Error> {
+		let mut res = Vec::with_capacity(content.len());
+		for v in content {
+			let stm = CreateStatement {
+				only: false,
+				data: Some(Data::ContentExpression(v)),
+				..self.clone()
+			};
+			match stm.compute_records(stk, ctx, opt, doc).await? {
+				Value::Array(v) => res.extend(v),
+				v => res.push(v),
+			}
+		}
+		// Output the results
+		match self.only {
+			// This is a single record result
+			true => match res.len() {
+				// There was exactly one result
+				1 => Ok(res.remove(0)),
+				// There were no results
+				_ => Err(Error::SingleOnlyOutput),
+			},
+			// This is standard query result
+			false => Ok(res.into()),
+		}
+	}
+	/// Create the records for each of the targets
+	async fn compute_records(
+		&self,
+		stk: &mut Stk,
+		ctx: &Context<'_>,
+		opt: &Options,
+		doc: Option<&CursorDoc<'_>>,
+	) -> Result<Value, Error> {
 		// Create a new iterator
 		let mut i = Iterator::new();
 		// Assign the statement
@@ -13,9 +13,10 @@ use crate::sql::{Relation, TableType};
 use derive::Store;
 use revision::revisioned;
 use serde::{Deserialize, Serialize};
+use std::collections::BTreeSet;
 use std::fmt::{self, Display, Write};
 
-#[revisioned(revision = 3)]
+#[revisioned(revision = 4)]
 #[derive(Clone, Debug, Default, Eq, PartialEq, PartialOrd, Serialize, Deserialize, Store, Hash)]
 #[cfg_attr(feature = "arbitrary", derive(arbitrary::Arbitrary))]
 #[non_exhaustive]
@@ -28,6 +29,8 @@ pub struct DefineFieldStatement {
 	pub readonly: bool,
 	pub value: Option<Value>,
 	pub assert: Option<Value>,
+	#[revision(start = 4)]
+	pub assert_message: Option<Value>,
 	pub default: Option<Value>,
 	pub permissions: Permissions,
 	pub comment: Option<Strand>,
@@ -36,6 +39,77 @@ pub struct DefineFieldStatement {
 }
 
 impl DefineFieldStatement {
+	/// Check if the VALUE clause of this field refers to another field
+	fn depends_on(&self, other: &DefineFieldStatement) -> bool {
+		// Nested fields are already processed after the fields which contain them
+		if self.name.starts_with(&other.name) || other.name.starts_with(&self.name) {
+			return false;
+		}
+		let mut fields = Vec::new();
+		if let Some(v) = &self.value {
+			v.fields(&mut fields);
+		}
+		fields.iter().any(|f| f.starts_with(&other.name) || other.name.starts_with(f))
+	}
+	/// Find an order in which each field is processed after the fields which its
+	/// VALUE clause refers to, otherwise keeping the existing order. Returns the
+	/// position of a field in a cycle if the VALUE clauses depend on each other.
+	fn sort(fields: &[DefineFieldStatement]) -> Result<Vec<usize>, usize> {
+		// Without VALUE clauses there are no dependencies
+		if fields.iter().all(|fd| fd.value.is_none()) {
+			return Ok((0..fields.len()).collect());
+		}
+		// The number of fields each field is waiting on, and which fields wait on it
+		let mut waiting = vec![0usize; fields.len()];
+		let mut dependents = vec![Vec::new(); fields.len()];
+		for (i, a) in fields.iter().enumerate() {
+			for (j, b) in fields.iter().enumerate() {
+				if a.depends_on(b) {
+					waiting[i] += 1;
+					dependents[j].push(i);
+				}
+			}
+		}
+		// Always process the earliest field which is ready
+		let mut ready: BTreeSet<usize> = (0..fields.len()).filter(|&i| waiting[i] == 0).collect();
+		let mut order = Vec::with_capacity(fields.len());
+		while let Some(i) = ready.pop_first() {
+			order.push(i);
+			for &j in &dependents[i] {
+				waiting[j] -= 1;
+				if waiting[j] == 0 {
+					ready.insert(j);
+				}
+			}
+		}
+		// Any fields which are still waiting are part of a cycle
+		match (0..fields.len()).find(|&i| waiting[i] > 0) {
+			Some(i) => Err(i),
+			None => Ok(order),
+		}
+	}
+	/// Order field definitions so that each field is processed after the fields which
+	/// its VALUE clause refers to. This is done once when the definitions are loaded.
+	/// Cycles are rejected when a field is defined, so if the stored definitions still
+	/// contain one, the fields are processed in the order in which they are stored.
+	pub(crate) fn order(fields: Vec<DefineFieldStatement>) -> Vec<Self> {
+		match Self::sort(&fields) {
+			Ok(order) => {
+				let mut fields: Vec<Option<Self>> = fields.into_iter().map(Some).collect();
+				order.into_iter().filter_map(|i| fields[i].take()).collect()
+			}
+			Err(_) => fields,
+		}
+	}
+	/// Check that the VALUE clauses of the field definitions do not depend on each other in a cycle
+	fn check_cycles(fields: &[DefineFieldStatement]) -> Result<(), Error> {
+		match Self::sort(fields) {
+			Ok(_) => Ok(()),
+			Err(i) => Err(Error::FdCircularDependency {
+				value: fields[i].name.to_string(),
+			}),
+		}
+	}
 	/// Process this type returning a computed simple Value
 	pub(crate) async fn compute(
 		&self,
@@ -75,8 +149,12 @@ impl DefineFieldStatement {
 		)
 		.await?;
 
-		// find existing field definitions.
+		// find existing field definitions, checking that
+		// the VALUE clauses do not depend on each other in a cycle.
 		let fields = run.all_tb_fields(opt.ns()?, opt.db()?, &self.what).await.ok();
+		if let Some(fields) = &fields {
+			Self::check_cycles(fields)?;
+		}
 
 		// Process possible recursive_definitions.
 		if let Some(mut cur_kind) = self.kind.as_ref().and_then(|x| x.inner_kind()) {
@@ -192,7 +270,10 @@ impl Display for DefineFieldStatement {
 			write!(f, " VALUE {v}")?
 		}
 		if let Some(ref v) = self.assert {
-			write!(f, " ASSERT {v}")?
+			write!(f, " ASSERT {v}")?;
+			if let Some(ref v) = self.assert_message {
+				write!(f, " ELSE {v}")?
+			}
 		}
 		if let Some(ref v) = self.comment {
 			write!(f, " COMMENT {v}")?
@@ -218,6 +299,7 @@ impl InfoStructure for DefineFieldStatement {
 			readonly,
 			value,
 			assert,
+			assert_message,
 			default,
 			permissions,
 			comment,
@@ -245,6 +327,10 @@ impl InfoStructure for DefineFieldStatement {
 			acc.insert("assert".to_string(), assert.structure());
 		}
 
+		if let Some(assert_message) = assert_message {
+			acc.insert("assert_message".to_string(), assert_message.structure());
+		}
+
 		if let Some(default) = default {
 			acc.insert("default".to_string(), default.structure());
 		}
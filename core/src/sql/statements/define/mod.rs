@@ -9,6 +9,7 @@ mod model;
 mod namespace;
 mod param;
 mod table;
+mod task;
 mod user;
 
 pub use access::DefineAccessStatement;
@@ -22,6 +23,7 @@ pub use model::DefineModelStatement;
 pub use namespace::DefineNamespaceStatement;
 pub use param::DefineParamStatement;
 pub use table::DefineTableStatement;
+pub use task::DefineTaskStatement;
 pub use user::DefineUserStatement;
 
 use crate::ctx::Context;
@@ -35,7 +37,7 @@ use revision::revisioned;
 use serde::{Deserialize, Serialize};
 use std::fmt::{self, Display};
 
-#[revisioned(revision = 1)]
+#[revisioned(revision = 2)]
 #[derive(Clone, Debug, Eq, PartialEq, PartialOrd, Serialize, Deserialize, Store, Hash)]
 #[cfg_attr(feature = "arbitrary", derive(arbitrary::Arbitrary))]
 #[non_exhaustive]
@@ -52,6 +54,8 @@ pub enum DefineStatement {
 	User(DefineUserStatement),
 	Model(DefineModelStatement),
 	Access(DefineAccessStatement),
+	#[revision(start = 2)]
+	Task(DefineTaskStatement),
 }
 
 impl DefineStatement {
@@ -80,6 +84,25 @@ impl DefineStatement {
 			Self::User(ref v) => v.compute(ctx, opt, doc).await,
 			Self::Model(ref v) => v.compute(ctx, opt, doc).await,
 			Self::Access(ref v) => v.compute(ctx, opt, doc).await,
+			Self::Task(ref v) => v.compute(ctx, opt, doc).await,
+		}
+	}
+	/// Keep any existing definition instead of failing
+	pub(crate) fn set_if_not_exists(&mut self) {
+		match self {
+			Self::Namespace(v) => v.if_not_exists = true,
+			Self::Database(v) => v.if_not_exists = true,
+			Self::Function(v) => v.if_not_exists = true,
+			Self::Param(v) => v.if_not_exists = true,
+			Self::Table(v) => v.if_not_exists = true,
+			Self::Event(v) => v.if_not_exists = true,
+			Self::Field(v) => v.if_not_exists = true,
+			Self::Index(v) => v.if_not_exists = true,
+			Self::Analyzer(v) => v.if_not_exists = true,
+			Self::User(v) => v.if_not_exists = true,
+			Self::Model(v) => v.if_not_exists = true,
+			Self::Access(v) => v.if_not_exists = true,
+			Self::Task(v) => v.if_not_exists = true,
 		}
 	}
 }
@@ -99,6 +122,7 @@ impl Display for DefineStatement {
 			Self::Analyzer(v) => Display::fmt(v, f),
 			Self::Model(v) => Display::fmt(v, f),
 			Self::Access(v) => Display::fmt(v, f),
+			Self::Task(v) => Display::fmt(v, f),
 		}
 	}
 }
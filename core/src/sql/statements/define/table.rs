@@ -12,7 +12,7 @@ use crate::sql::{
 use std::sync::Arc;
 
 use crate::sql::statements::info::InfoStructure;
-use crate::sql::{Idiom, Kind, Part, Table, TableType};
+use crate::sql::{IdStrategy, Idiom, Kind, Part, Table, TableType};
 use derive::Store;
 use reblessive::tree::Stk;
 use revision::revisioned;
@@ -21,7 +21,7 @@ use std::fmt::{self, Display, Write};
 
 use super::DefineFieldStatement;
 
-#[revisioned(revision = 3)]
+#[revisioned(revision = 6)]
 #[derive(Clone, Debug, Default, Eq, PartialEq, PartialOrd, Serialize, Deserialize, Store, Hash)]
 #[cfg_attr(feature = "arbitrary", derive(arbitrary::Arbitrary))]
 #[non_exhaustive]
@@ -38,6 +38,15 @@ pub struct DefineTableStatement {
 	pub if_not_exists: bool,
 	#[revision(start = 3)]
 	pub kind: TableType,
+	/// Whether each record carries a version which is incremented on every write
+	#[revision(start = 4)]
+	pub versioned: bool,
+	/// How the ids of new records are generated, when no id is given
+	#[revision(start = 5)]
+	pub id_strategy: IdStrategy,
+	/// Whether deleted records are marked with a deletion time, instead of being removed
+	#[revision(start = 6)]
+	pub soft_delete: bool,
 }
 
 impl DefineTableStatement {
@@ -193,6 +202,15 @@ impl Display for DefineTableStatement {
 		if self.drop {
 			f.write_str(" DROP")?;
 		}
+		if self.versioned {
+			f.write_str(" VERSIONED")?;
+		}
+		if self.id_strategy != IdStrategy::Rand {
+			write!(f, " ID {}", self.id_strategy)?;
+		}
+		if self.soft_delete {
+			f.write_str(" SOFT DELETE")?;
+		}
 		f.write_str(if self.full {
 			" SCHEMAFULL"
 		} else {
@@ -229,6 +247,9 @@ impl InfoStructure for DefineTableStatement {
 			changefeed,
 			comment,
 			kind,
+			versioned,
+			id_strategy,
+			soft_delete,
 			..
 		} = self;
 		let mut acc = Object::default();
@@ -237,6 +258,7 @@ impl InfoStructure for DefineTableStatement {
 
 		acc.insert("drop".to_string(), drop.into());
 		acc.insert("full".to_string(), full.into());
+		acc.insert("versioned".to_string(), versioned.into());
 
 		if let Some(view) = view {
 			acc.insert("view".to_string(), view.structure());
@@ -254,6 +276,14 @@ impl InfoStructure for DefineTableStatement {
 
 		acc.insert("kind".to_string(), kind.structure());
 
+		if id_strategy != IdStrategy::Rand {
+			acc.insert("id".to_string(), id_strategy.structure());
+		}
+
+		if soft_delete {
+			acc.insert("soft_delete".to_string(), soft_delete.into());
+		}
+
 		Value::Object(acc)
 	}
 }
@@ -0,0 +1,107 @@
+use crate::ctx::Context;
+use crate::dbs::Options;
+use crate::doc::CursorDoc;
+use crate::err::Error;
+use crate::iam::{Action, ResourceKind};
+use crate::sql::statements::info::InfoStructure;
+use crate::sql::{Base, Duration, Ident, Object, Strand, Value, Values};
+use derive::Store;
+use revision::revisioned;
+use serde::{Deserialize, Serialize};
+use std::fmt::{self, Display};
+
+#[revisioned(revision = 1)]
+#[derive(Clone, Debug, Default, Eq, PartialEq, PartialOrd, Serialize, Deserialize, Store, Hash)]
+#[cfg_attr(feature = "arbitrary", derive(arbitrary::Arbitrary))]
+#[non_exhaustive]
+pub struct DefineTaskStatement {
+	pub name: Ident,
+	pub every: Duration,
+	pub then: Values,
+	pub comment: Option<Strand>,
+	pub if_not_exists: bool,
+}
+
+impl DefineTaskStatement {
+	/// Process this type returning a computed simple Value
+	pub(crate) async fn compute(
+		&self,
+		ctx: &Context<'_>,
+		opt: &Options,
+		_doc: Option<&CursorDoc<'_>>,
+	) -> Result<Value, Error> {
+		// Allowed to run?
+		opt.is_allowed(Action::Edit, ResourceKind::Database, &Base::Db)?;
+		// Claim transaction
+		let mut run = ctx.tx_lock().await;
+		// Clear the cache
+		run.clear_cache();
+		// Check if task already exists
+		if run.get_db_task(opt.ns()?, opt.db()?, &self.name).await.is_ok() {
+			if self.if_not_exists {
+				return Ok(Value::None);
+			} else {
+				return Err(Error::TkAlreadyExists {
+					value: self.name.to_string(),
+				});
+			}
+		}
+		// Process the statement
+		let key = crate::key::database::tk::new(opt.ns()?, opt.db()?, &self.name);
+		run.add_ns(opt.ns()?, opt.strict).await?;
+		run.add_db(opt.ns()?, opt.db()?, opt.strict).await?;
+		run.set(
+			key,
+			DefineTaskStatement {
+				// Don't persist the "IF NOT EXISTS" clause to schema
+				if_not_exists: false,
+				..self.clone()
+			},
+		)
+		.await?;
+		// Ok all good
+		Ok(Value::None)
+	}
+}
+
+impl Display for DefineTaskStatement {
+	fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {
+		write!(f, "DEFINE TASK")?;
+		if self.if_not_exists {
+			write!(f, " IF NOT EXISTS")?
+		}
+		write!(f, " {} EVERY {} THEN {}", self.name, self.every, self.then)?;
+		if let Some(ref v) = self.comment {
+			write!(f, " COMMENT {v}")?
+		}
+		Ok(())
+	}
+}
+
+impl InfoStructure for DefineTaskStatement {
+	fn structure(self) -> Value {
+		let Self {
+			name,
+			every,
+			then,
+			comment,
+			..
+		} = self;
+		let mut acc = Object::default();
+
+		acc.insert("name".to_string(), name.structure());
+
+		acc.insert("every".to_string(), every.into());
+
+		acc.insert(
+			"then".to_string(),
+			Value::Array(then.0.iter().map(|v| v.to_string().into()).collect()),
+		);
+
+		if let Some(comment) = comment {
+			acc.insert("comment".to_string(), comment.into());
+		}
+
+		Value::Object(acc)
+	}
+}
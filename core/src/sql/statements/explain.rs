@@ -0,0 +1,77 @@
+use crate::ctx::Context;
+use crate::dbs::{Iterator, Options};
+use crate::doc::CursorDoc;
+use crate::err::Error;
+use crate::sql::statements::SelectStatement;
+use crate::sql::{Array, Explain, Object, Value};
+use derive::Store;
+use reblessive::tree::Stk;
+use revision::revisioned;
+use serde::{Deserialize, Serialize};
+use std::fmt;
+
+#[revisioned(revision = 1)]
+#[derive(Clone, Debug, Default, Eq, PartialEq, PartialOrd, Serialize, Deserialize, Store, Hash)]
+#[cfg_attr(feature = "arbitrary", derive(arbitrary::Arbitrary))]
+#[non_exhaustive]
+pub struct ExplainStatement {
+	/// Whether the statement is also run, to count the records it fetches
+	pub full: bool,
+	pub what: SelectStatement,
+}
+
+impl ExplainStatement {
+	/// Check if we require a writeable transaction
+	pub(crate) fn writeable(&self) -> bool {
+		self.what.writeable()
+	}
+	/// Process this type returning a computed simple Value
+	pub(crate) async fn compute(
+		&self,
+		stk: &mut Stk,
+		ctx: &Context<'_>,
+		opt: &Options,
+		doc: Option<&CursorDoc<'_>>,
+	) -> Result<Value, Error> {
+		// Plan the statement without returning its records
+		let stm = SelectStatement {
+			explain: Some(Explain(self.full)),
+			..self.what.clone()
+		};
+		let mut i = Iterator::new().with_summary();
+		let plan = match stm.compute_iterator(stk, ctx, opt, doc, &mut i).await? {
+			Value::Array(v) => v,
+			v => Array::from(vec![v]),
+		};
+		// Summarise the plan
+		let summary = i.take_summary().unwrap_or_default();
+		let mut steps = Object::default();
+		if let Some(estimated) = summary.estimated {
+			steps.insert("estimated".to_owned(), estimated.into());
+			if summary.lower_bound {
+				steps.insert("lower_bound".to_owned(), true.into());
+			}
+		}
+		if let Some(actual) = summary.actual {
+			steps.insert("actual".to_owned(), actual.into());
+		}
+		let fields = self.what.expr.iter().map(|f| Value::from(f.to_string())).collect::<Vec<_>>();
+		Ok(Value::from(Object::from(map! {
+			"fields" => fields.into(),
+			"full_scan" => summary.full_scan.into(),
+			"indexes" => summary.indexes.into(),
+			"plan" => plan.into(),
+			"steps" => steps.into(),
+		})))
+	}
+}
+
+impl fmt::Display for ExplainStatement {
+	fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {
+		f.write_str("EXPLAIN ")?;
+		if self.full {
+			f.write_str("FULL ")?;
+		}
+		write!(f, "{}", self.what)
+	}
+}
@@ -3,14 +3,17 @@ use crate::dbs::Options;
 use crate::doc::CursorDoc;
 use crate::err::Error;
 use crate::sql::fmt::{fmt_separated_by, is_pretty, pretty_indent, Fmt, Pretty};
+use crate::sql::statements::SetStatement;
 use crate::sql::Value;
 use derive::Store;
+use futures::stream::{self, StreamExt};
 use reblessive::tree::Stk;
+use reblessive::TreeStack;
 use revision::revisioned;
 use serde::{Deserialize, Serialize};
 use std::fmt::{self, Display, Write};
 
-#[revisioned(revision = 1)]
+#[revisioned(revision = 3)]
 #[derive(Clone, Debug, Default, Eq, PartialEq, PartialOrd, Serialize, Deserialize, Store, Hash)]
 #[cfg_attr(feature = "arbitrary", derive(arbitrary::Arbitrary))]
 #[non_exhaustive]
@@ -19,11 +22,20 @@ pub struct IfelseStatement {
 	pub exprs: Vec<(Value, Value)>,
 	/// the final else body, if there is one
 	pub close: Option<Value>,
+	/// Whether the conditions of a top-level IF statement should be evaluated concurrently
+	#[revision(start = 2)]
+	pub parallel: bool,
+	/// The LET bindings which are scoped to each branch, by position
+	#[revision(start = 3)]
+	pub lets: Vec<Vec<SetStatement>>,
 }
 
 impl IfelseStatement {
 	/// Check if we require a writeable transaction
 	pub(crate) fn writeable(&self) -> bool {
+		if self.lets.iter().flatten().any(|v| v.writeable()) {
+			return true;
+		}
 		for (cond, then) in self.exprs.iter() {
 			if cond.writeable() || then.writeable() {
 				return true;
@@ -37,6 +49,35 @@ impl IfelseStatement {
 			&& (self.close.as_ref().is_none()
 				|| self.close.as_ref().is_some_and(|v| matches!(v, Value::Block(_))))
 	}
+	/// Check if the conditions can be evaluated concurrently
+	fn runs_in_parallel(&self) -> bool {
+		// Conditions which write data or depend on LET bindings are always evaluated in order
+		self.parallel
+			&& self.exprs.len() > 1
+			&& self.lets.iter().all(|v| v.is_empty())
+			&& self.exprs.iter().all(|(cond, _)| !cond.writeable())
+	}
+	/// Evaluate all of the conditions concurrently, returning the results in order
+	async fn compute_parallel(
+		&self,
+		ctx: &Context<'_>,
+		opt: &Options,
+		doc: Option<&CursorDoc<'_>>,
+	) -> Result<Vec<Result<Value, Error>>, Error> {
+		// Each condition runs on its own stack, so count it against the computation depth
+		let opt = &opt.dive(1)?;
+		Ok(stream::iter(self.exprs.iter().map(|(cond, _)| async move {
+			let mut stack = TreeStack::new();
+			stack.enter(|stk| cond.compute(stk, ctx, opt, doc)).finish().await
+		}))
+		.buffered(crate::cnf::MAX_CONCURRENT_TASKS)
+		.collect()
+		.await)
+	}
+	/// Get the LET bindings which are scoped to a branch
+	fn branch_lets(&self, index: usize) -> &[SetStatement] {
+		self.lets.get(index).map_or(&[], |v| v.as_slice())
+	}
 	/// Process this type returning a computed simple Value
 	pub(crate) async fn compute(
 		&self,
@@ -45,10 +86,37 @@ impl IfelseStatement {
 		opt: &Options,
 		doc: Option<&CursorDoc<'_>>,
 	) -> Result<Value, Error> {
-		for (ref cond, ref then) in &self.exprs {
-			let v = cond.compute(stk, ctx, opt, doc).await?;
+		// Evaluate the conditions concurrently if possible
+		let mut conds = match self.runs_in_parallel() {
+			true => Some(self.compute_parallel(ctx, opt, doc).await?.into_iter()),
+			false => None,
+		};
+		for (i, (ref cond, ref then)) in self.exprs.iter().enumerate() {
+			// Bind any LET statements for this branch only
+			let mut ctx = Context::new(ctx);
+			for v in self.branch_lets(i) {
+				let val = v.compute(stk, &ctx, opt, doc).await?;
+				ctx.add_value(v.name.to_owned(), val);
+			}
+			// The results are checked in order, so the earliest truthy branch always wins
+			let v = match conds.as_mut().and_then(|c| c.next()) {
+				Some(v) => v,
+				None => cond.compute(stk, &ctx, opt, doc).await,
+			};
+			let v = match v {
+				Ok(v) => v,
+				Err(e) => return Err(condition_error(i + 1, cond, e)),
+			};
+			// Check if the condition must be a boolean
+			if opt.strict_conditions && !matches!(v, Value::Bool(_) | Value::None | Value::Null) {
+				return Err(Error::IfelseConditionNotBool {
+					branch: i + 1,
+					cond: cond.to_string(),
+					value: v,
+				});
+			}
 			if v.is_truthy() {
-				return then.compute(stk, ctx, opt, doc).await;
+				return then.compute(stk, &ctx, opt, doc).await;
 			}
 		}
 		match self.close {
@@ -58,6 +126,42 @@ impl IfelseStatement {
 	}
 }
 
+/// Adds the position and text of a condition to an error raised while evaluating it
+fn condition_error(branch: usize, cond: &Value, e: Error) -> Error {
+	match e {
+		// Control flow, cancellation, and thrown errors are passed through unchanged
+		Error::Ignore
+		| Error::Break
+		| Error::Continue
+		| Error::RetryWithId(_)
+		| Error::QueryCancelled
+		| Error::QueryTimedout
+		| Error::Thrown(_) => e,
+		// Transaction conflicts must remain retryable, and datastore errors
+		// are not caused by the condition itself
+		Error::TxRetryable
+		| Error::Tx(_)
+		| Error::TxFailure
+		| Error::TxFinished
+		| Error::TxReadonly
+		| Error::TxConditionNotMet
+		| Error::TxKeyAlreadyExists
+		| Error::TxTooLarge
+		| Error::Ds(_)
+		| Error::Unreachable(_)
+		| Error::Internal(_) => e,
+		// This condition has already been described
+		Error::IfelseCondition {
+			..
+		} => e,
+		e => Error::IfelseCondition {
+			branch,
+			cond: cond.to_string(),
+			source: Box::new(e),
+		},
+	}
+}
+
 impl Display for IfelseStatement {
 	fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {
 		let mut f = Pretty::from(f);
@@ -67,15 +171,19 @@ impl Display for IfelseStatement {
 					f,
 					"{}",
 					&Fmt::new(
-						self.exprs.iter().map(|args| {
-							Fmt::new(args, |(cond, then), f| {
+						self.exprs.iter().enumerate().map(|(i, (cond, then))| {
+							Fmt::new((self.branch_lets(i), cond, then), |(lets, cond, then), f| {
+								f.write_str("IF ")?;
+								for v in lets {
+									write!(f, "{v}; ")?;
+								}
 								if is_pretty() {
-									write!(f, "IF {cond}")?;
+									write!(f, "{cond}")?;
 									let indent = pretty_indent();
 									write!(f, "{then}")?;
 									drop(indent);
 								} else {
-									write!(f, "IF {cond} {then}")?;
+									write!(f, "{cond} {then}")?;
 								}
 								Ok(())
 							})
@@ -97,6 +205,9 @@ impl Display for IfelseStatement {
 						write!(f, " ELSE {v}")?;
 					}
 				}
+				if self.parallel {
+					f.write_str(" PARALLEL")?;
+				}
 				Ok(())
 			}
 			false => {
@@ -104,15 +215,19 @@ impl Display for IfelseStatement {
 					f,
 					"{}",
 					&Fmt::new(
-						self.exprs.iter().map(|args| {
-							Fmt::new(args, |(cond, then), f| {
+						self.exprs.iter().enumerate().map(|(i, (cond, then))| {
+							Fmt::new((self.branch_lets(i), cond, then), |(lets, cond, then), f| {
+								f.write_str("IF ")?;
+								for v in lets {
+									write!(f, "{v}; ")?;
+								}
 								if is_pretty() {
-									write!(f, "IF {cond} THEN")?;
+									write!(f, "{cond} THEN")?;
 									let indent = pretty_indent();
 									write!(f, "{then}")?;
 									drop(indent);
 								} else {
-									write!(f, "IF {cond} THEN {then}")?;
+									write!(f, "{cond} THEN {then}")?;
 								}
 								Ok(())
 							})
@@ -139,8 +254,48 @@ impl Display for IfelseStatement {
 				} else {
 					f.write_str(" END")?;
 				}
+				if self.parallel {
+					f.write_str(" PARALLEL")?;
+				}
 				Ok(())
 			}
 		}
 	}
 }
+
+#[cfg(test)]
+mod tests {
+	use super::*;
+
+	#[test]
+	fn condition_errors_keep_their_source() {
+		let cond = Value::from(true);
+		let e = condition_error(
+			2,
+			&cond,
+			Error::InvalidArguments {
+				name: "string::len".to_owned(),
+				message: "Expected 1 argument.".to_owned(),
+			},
+		);
+		match e {
+			Error::IfelseCondition {
+				branch,
+				cond,
+				source,
+			} => {
+				assert_eq!(branch, 2);
+				assert_eq!(cond, "true");
+				assert!(matches!(*source, Error::InvalidArguments { .. }));
+			}
+			e => panic!("{e:?}"),
+		}
+	}
+
+	#[test]
+	fn transaction_errors_are_passed_through() {
+		let cond = Value::from(true);
+		assert!(matches!(condition_error(1, &cond, Error::TxRetryable), Error::TxRetryable));
+		assert!(matches!(condition_error(1, &cond, Error::Ds("".into())), Error::Ds(_)));
+	}
+}
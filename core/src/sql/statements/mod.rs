@@ -2,11 +2,13 @@ pub(crate) mod analyze;
 pub(crate) mod begin;
 pub(crate) mod r#break;
 pub(crate) mod cancel;
+pub(crate) mod case;
 pub(crate) mod commit;
 pub(crate) mod r#continue;
 pub(crate) mod create;
 pub(crate) mod define;
 pub(crate) mod delete;
+pub(crate) mod explain;
 pub(crate) mod foreach;
 pub(crate) mod ifelse;
 pub(crate) mod info;
@@ -18,6 +20,8 @@ pub(crate) mod output;
 pub(crate) mod rebuild;
 pub(crate) mod relate;
 pub(crate) mod remove;
+pub(crate) mod restore;
+pub(crate) mod savepoint;
 pub(crate) mod select;
 pub(crate) mod set;
 pub(crate) mod show;
@@ -30,9 +34,11 @@ pub(crate) mod r#use;
 pub use self::analyze::AnalyzeStatement;
 pub use self::begin::BeginStatement;
 pub use self::cancel::CancelStatement;
+pub use self::case::CaseStatement;
 pub use self::commit::CommitStatement;
 pub use self::create::CreateStatement;
 pub use self::delete::DeleteStatement;
+pub use self::explain::ExplainStatement;
 pub use self::foreach::ForeachStatement;
 pub use self::ifelse::IfelseStatement;
 pub use self::info::InfoStatement;
@@ -45,6 +51,8 @@ pub use self::r#break::BreakStatement;
 pub use self::r#continue::ContinueStatement;
 pub use self::r#use::UseStatement;
 pub use self::relate::RelateStatement;
+pub use self::restore::RestoreStatement;
+pub use self::savepoint::SavepointStatement;
 pub use self::select::SelectStatement;
 pub use self::set::SetStatement;
 pub use self::show::ShowStatement;
@@ -57,12 +65,12 @@ pub use self::define::{
 	DefineAccessStatement, DefineAnalyzerStatement, DefineDatabaseStatement, DefineEventStatement,
 	DefineFieldStatement, DefineFunctionStatement, DefineIndexStatement, DefineModelStatement,
 	DefineNamespaceStatement, DefineParamStatement, DefineStatement, DefineTableStatement,
-	DefineUserStatement,
+	DefineTaskStatement, DefineUserStatement,
 };
 
 pub use self::remove::{
 	RemoveAccessStatement, RemoveAnalyzerStatement, RemoveDatabaseStatement, RemoveEventStatement,
 	RemoveFieldStatement, RemoveFunctionStatement, RemoveIndexStatement, RemoveModelStatement,
 	RemoveNamespaceStatement, RemoveParamStatement, RemoveStatement, RemoveTableStatement,
-	RemoveUserStatement,
+	RemoveTaskStatement, RemoveUserStatement,
 };
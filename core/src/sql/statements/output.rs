@@ -3,7 +3,7 @@ use crate::dbs::Options;
 use crate::doc::CursorDoc;
 use crate::err::Error;
 use crate::sql::fetch::Fetchs;
-use crate::sql::value::Value;
+use crate::sql::value::{FetchCache, Value};
 use derive::Store;
 use reblessive::tree::Stk;
 use revision::revisioned;
@@ -38,8 +38,9 @@ impl OutputStatement {
 		let mut val = self.what.compute(stk, ctx, opt, doc).await?;
 		// Fetch any
 		if let Some(fetchs) = &self.fetch {
+			let cache = FetchCache::default();
 			for fetch in fetchs.iter() {
-				val.fetch(stk, ctx, opt, fetch).await?;
+				val.fetch(stk, ctx, opt, &cache, fetch).await?;
 			}
 		}
 		//
@@ -2,7 +2,7 @@ use crate::ctx::Context;
 use crate::dbs::Options;
 use crate::err::Error;
 use crate::iam::{Action, ResourceKind};
-use crate::sql::{Base, Ident, Value};
+use crate::sql::{Base, Ident, Index, Value};
 use derive::Store;
 use revision::revisioned;
 use serde::{Deserialize, Serialize};
@@ -29,10 +29,23 @@ impl RemoveAnalyzerStatement {
 			run.clear_cache();
 			// Get the definition
 			let az = run.get_db_analyzer(opt.ns()?, opt.db()?, &self.name).await?;
+			// Check that the analyzer is not used by any index
+			for tb in run.all_tb(opt.ns()?, opt.db()?).await?.iter() {
+				for ix in run.all_tb_indexes(opt.ns()?, opt.db()?, &tb.name).await?.iter() {
+					if let Index::Search(p) = &ix.index {
+						if p.az == az.name {
+							return Err(Error::AzInUse {
+								value: az.name.to_raw(),
+								table: tb.name.to_raw(),
+								index: ix.name.to_raw(),
+							});
+						}
+					}
+				}
+			}
 			// Delete the definition
 			let key = crate::key::database::az::new(opt.ns()?, opt.db()?, &az.name);
 			run.del(key).await?;
-			// TODO Check that the analyzer is not used in any schema
 			// Ok all good
 			Ok(Value::None)
 		}
@@ -9,6 +9,7 @@ mod model;
 mod namespace;
 mod param;
 mod table;
+mod task;
 mod user;
 
 pub use access::RemoveAccessStatement;
@@ -22,6 +23,7 @@ pub use model::RemoveModelStatement;
 pub use namespace::RemoveNamespaceStatement;
 pub use param::RemoveParamStatement;
 pub use table::RemoveTableStatement;
+pub use task::RemoveTaskStatement;
 pub use user::RemoveUserStatement;
 
 use crate::ctx::Context;
@@ -34,7 +36,7 @@ use revision::revisioned;
 use serde::{Deserialize, Serialize};
 use std::fmt::{self, Display, Formatter};
 
-#[revisioned(revision = 1)]
+#[revisioned(revision = 2)]
 #[derive(Clone, Debug, Eq, PartialEq, PartialOrd, Serialize, Deserialize, Store, Hash)]
 #[cfg_attr(feature = "arbitrary", derive(arbitrary::Arbitrary))]
 #[non_exhaustive]
@@ -51,6 +53,8 @@ pub enum RemoveStatement {
 	Index(RemoveIndexStatement),
 	User(RemoveUserStatement),
 	Model(RemoveModelStatement),
+	#[revision(start = 2)]
+	Task(RemoveTaskStatement),
 }
 
 impl RemoveStatement {
@@ -78,6 +82,7 @@ impl RemoveStatement {
 			Self::Analyzer(ref v) => v.compute(ctx, opt).await,
 			Self::User(ref v) => v.compute(ctx, opt).await,
 			Self::Model(ref v) => v.compute(ctx, opt).await,
+			Self::Task(ref v) => v.compute(ctx, opt).await,
 		}
 	}
 }
@@ -97,6 +102,7 @@ impl Display for RemoveStatement {
 			Self::Analyzer(v) => Display::fmt(v, f),
 			Self::User(v) => Display::fmt(v, f),
 			Self::Model(v) => Display::fmt(v, f),
+			Self::Task(v) => Display::fmt(v, f),
 		}
 	}
 }
@@ -0,0 +1,60 @@
+use crate::ctx::Context;
+use crate::dbs::Options;
+use crate::err::Error;
+use crate::iam::{Action, ResourceKind};
+use crate::sql::{Base, Ident, Value};
+use derive::Store;
+use revision::revisioned;
+use serde::{Deserialize, Serialize};
+use std::fmt::{self, Display, Formatter};
+
+#[revisioned(revision = 1)]
+#[derive(Clone, Debug, Default, Eq, PartialEq, PartialOrd, Serialize, Deserialize, Store, Hash)]
+#[cfg_attr(feature = "arbitrary", derive(arbitrary::Arbitrary))]
+#[non_exhaustive]
+pub struct RemoveTaskStatement {
+	pub name: Ident,
+	pub if_exists: bool,
+}
+
+impl RemoveTaskStatement {
+	/// Process this type returning a computed simple Value
+	pub(crate) async fn compute(&self, ctx: &Context<'_>, opt: &Options) -> Result<Value, Error> {
+		let future = async {
+			// Allowed to run?
+			opt.is_allowed(Action::Edit, ResourceKind::Database, &Base::Db)?;
+			// Claim transaction
+			let mut run = ctx.tx_lock().await;
+			// Clear the cache
+			run.clear_cache();
+			// Get the definition
+			let tk = run.get_db_task(opt.ns()?, opt.db()?, &self.name).await?;
+			// Delete the definition
+			let key = crate::key::database::tk::new(opt.ns()?, opt.db()?, &tk.name);
+			run.del(key).await?;
+			// Delete the schedule
+			let key = crate::key::database::tr::new(opt.ns()?, opt.db()?, &tk.name);
+			run.del(key).await?;
+			// Ok all good
+			Ok(Value::None)
+		}
+		.await;
+		match future {
+			Err(Error::TkNotFound {
+				..
+			}) if self.if_exists => Ok(Value::None),
+			v => v,
+		}
+	}
+}
+
+impl Display for RemoveTaskStatement {
+	fn fmt(&self, f: &mut Formatter) -> fmt::Result {
+		write!(f, "REMOVE TASK")?;
+		if self.if_exists {
+			write!(f, " IF EXISTS")?
+		}
+		write!(f, " {}", self.name)?;
+		Ok(())
+	}
+}
@@ -0,0 +1,89 @@
+use crate::ctx::Context;
+use crate::dbs::{Iterator, Options, Statement};
+use crate::doc::CursorDoc;
+use crate::err::Error;
+use crate::sql::{Cond, Output, Timeout, Value, Values};
+use derive::Store;
+use reblessive::tree::Stk;
+use revision::revisioned;
+use serde::{Deserialize, Serialize};
+use std::fmt;
+
+/// Restores records which were soft-deleted, by clearing their
+/// deletion marker. Records which are not soft-deleted are skipped.
+#[revisioned(revision = 1)]
+#[derive(Clone, Debug, Default, Eq, PartialEq, PartialOrd, Serialize, Deserialize, Store, Hash)]
+#[cfg_attr(feature = "arbitrary", derive(arbitrary::Arbitrary))]
+#[non_exhaustive]
+pub struct RestoreStatement {
+	pub only: bool,
+	pub what: Values,
+	pub cond: Option<Cond>,
+	pub output: Option<Output>,
+	pub timeout: Option<Timeout>,
+	pub parallel: bool,
+}
+
+impl RestoreStatement {
+	/// Check if we require a writeable transaction
+	pub(crate) fn writeable(&self) -> bool {
+		true
+	}
+	/// Process this type returning a computed simple Value
+	pub(crate) async fn compute(
+		&self,
+		stk: &mut Stk,
+		ctx: &Context<'_>,
+		opt: &Options,
+		doc: Option<&CursorDoc<'_>>,
+	) -> Result<Value, Error> {
+		// Valid options?
+		opt.valid_for_db()?;
+		// Create a new iterator
+		let mut i = Iterator::new();
+		// Assign the statement
+		let stm = Statement::from(self);
+		// Ensure futures are stored
+		let opt = &opt.new_with_futures(false).with_projections(false);
+		// Loop over the restore targets
+		for w in self.what.0.iter() {
+			let v = w.compute(stk, ctx, opt, doc).await?;
+			i.prepare(stk, ctx, opt, &stm, v).await?;
+		}
+		// Output the results
+		match i.output(stk, ctx, opt, &stm).await? {
+			// This is a single record result
+			Value::Array(mut a) if self.only => match a.len() {
+				// There was exactly one result
+				1 => Ok(a.remove(0)),
+				// There were no results
+				_ => Err(Error::SingleOnlyOutput),
+			},
+			// This is standard query result
+			v => Ok(v),
+		}
+	}
+}
+
+impl fmt::Display for RestoreStatement {
+	fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {
+		write!(f, "RESTORE")?;
+		if self.only {
+			f.write_str(" ONLY")?
+		}
+		write!(f, " {}", self.what)?;
+		if let Some(ref v) = self.cond {
+			write!(f, " {v}")?
+		}
+		if let Some(ref v) = self.output {
+			write!(f, " {v}")?
+		}
+		if let Some(ref v) = self.timeout {
+			write!(f, " {v}")?
+		}
+		if self.parallel {
+			f.write_str(" PARALLEL")?
+		}
+		Ok(())
+	}
+}
@@ -0,0 +1,28 @@
+use crate::sql::Ident;
+use derive::Store;
+use revision::revisioned;
+use serde::{Deserialize, Serialize};
+use std::fmt;
+
+#[revisioned(revision = 1)]
+#[derive(Clone, Debug, Eq, PartialEq, PartialOrd, Serialize, Deserialize, Store, Hash)]
+#[cfg_attr(feature = "arbitrary", derive(arbitrary::Arbitrary))]
+#[non_exhaustive]
+pub enum SavepointStatement {
+	/// Checkpoint the writes made so far in the transaction
+	Define(Ident),
+	/// Forget a checkpoint, keeping the writes made since
+	Release(Ident),
+	/// Undo the writes made since a checkpoint
+	Rollback(Ident),
+}
+
+impl fmt::Display for SavepointStatement {
+	fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {
+		match self {
+			Self::Define(v) => write!(f, "DEFINE SAVEPOINT {v}"),
+			Self::Release(v) => write!(f, "RELEASE SAVEPOINT {v}"),
+			Self::Rollback(v) => write!(f, "ROLLBACK TO SAVEPOINT {v}"),
+		}
+	}
+}
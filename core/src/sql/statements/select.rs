@@ -1,19 +1,22 @@
+use crate::cnf::RESULT_CACHE_TTL;
 use crate::ctx::Context;
-use crate::dbs::{Iterable, Iterator, Options, Statement};
+use crate::dbs::{cursor, Iterable, Iterator, Options, ResultStream, Statement};
 use crate::doc::CursorDoc;
 use crate::err::Error;
 use crate::idx::planner::QueryPlanner;
+use crate::sql::paths::ID;
 use crate::sql::{
-	Cond, Explain, Fetchs, Field, Fields, Groups, Idioms, Limit, Orders, Splits, Start, Timeout,
-	Value, Values, Version, With,
+	param::ParamCollector, Cond, Duration, Explain, Fetchs, Field, Fields, Groups, Idiom, Idioms,
+	Limit, Order, Orders, Range, Splits, Start, Timeout, Value, Values, Version, With,
 };
 use derive::Store;
 use reblessive::tree::Stk;
 use revision::revisioned;
 use serde::{Deserialize, Serialize};
 use std::fmt;
+use std::ops::Bound;
 
-#[revisioned(revision = 3)]
+#[revisioned(revision = 8)]
 #[derive(Clone, Debug, Default, Eq, PartialEq, PartialOrd, Serialize, Deserialize, Store, Hash)]
 #[cfg_attr(feature = "arbitrary", derive(arbitrary::Arbitrary))]
 #[non_exhaustive]
@@ -37,6 +40,22 @@ pub struct SelectStatement {
 	pub explain: Option<Explain>,
 	#[revision(start = 3)]
 	pub tempfiles: bool,
+	#[revision(start = 4)]
+	pub having: Option<Cond>,
+	/// The fields used to keep only the first row for each distinct key.
+	/// Without an ORDER clause the row which is kept is nondeterministic.
+	#[revision(start = 5)]
+	pub distinct: Option<Idioms>,
+	/// How long the result can be served from the result cache.
+	/// A zero duration uses the default expiry for cached results.
+	#[revision(start = 6)]
+	pub cache: Option<Duration>,
+	/// Whether soft-deleted records are included in the result
+	#[revision(start = 7)]
+	pub deleted: bool,
+	/// The cursor of a `START AFTER` clause, which is NONE for the first page
+	#[revision(start = 8)]
+	pub cursor: Option<Value>,
 }
 
 impl SelectStatement {
@@ -47,16 +66,73 @@ impl SelectStatement {
 			Field::Single {
 				expr,
 				..
+			}
+			| Field::Window {
+				expr,
+				..
 			} => expr.writeable(),
+			Field::Filter {
+				expr,
+				cond,
+				..
+			} => expr.writeable() || cond.writeable(),
 		}) {
 			return true;
 		}
 		if self.what.iter().any(|v| v.writeable()) {
 			return true;
 		}
+		if self.having.as_ref().map_or(false, |v| v.writeable()) {
+			return true;
+		}
 		self.cond.as_ref().map_or(false, |v| v.writeable())
 	}
 
+	/// Collect the parameters which this statement refers to, returning
+	/// false if the statement contains a value which can not be walked
+	pub(crate) fn params<'a>(&'a self, out: &mut impl ParamCollector<'a>) -> bool {
+		let idioms = self
+			.omit
+			.iter()
+			.flat_map(|v| v.iter())
+			.chain(self.split.iter().flat_map(|v| v.iter().map(|v| &v.0)))
+			.chain(self.group.iter().flat_map(|v| v.iter().map(|v| &v.0)))
+			.chain(self.order.iter().flat_map(|v| v.iter().map(|v| &v.order)))
+			.chain(self.distinct.iter().flat_map(|v| v.iter()))
+			.chain(self.fetch.iter().flat_map(|v| v.iter().map(|v| &v.0)));
+		let values = self
+			.what
+			.iter()
+			.chain(self.cond.iter().map(|v| &v.0))
+			.chain(self.having.iter().map(|v| &v.0))
+			.chain(self.limit.iter().map(|v| &v.0))
+			.chain(self.start.iter().map(|v| &v.0))
+			.chain(self.cursor.iter());
+		self.expr.params(out) && idioms.all(|v| v.params(out)) && values.all(|v| v.params(out))
+	}
+
+	/// Check if this statement only reads from the records which it selects
+	fn reads_only_selected(&self) -> bool {
+		let idioms = self
+			.omit
+			.iter()
+			.flat_map(|v| v.iter())
+			.chain(self.split.iter().flat_map(|v| v.iter().map(|v| &v.0)))
+			.chain(self.group.iter().flat_map(|v| v.iter().map(|v| &v.0)))
+			.chain(self.order.iter().flat_map(|v| v.iter().map(|v| &v.order)))
+			.chain(self.distinct.iter().flat_map(|v| v.iter()));
+		let values = self
+			.cond
+			.iter()
+			.map(|v| &v.0)
+			.chain(self.having.iter().map(|v| &v.0))
+			.chain(self.limit.iter().map(|v| &v.0))
+			.chain(self.start.iter().map(|v| &v.0));
+		self.expr.reads_only_document()
+			&& idioms.all(Idiom::reads_only_document)
+			&& values.all(Value::reads_only_document)
+	}
+
 	/// Process this type returning a computed simple Value
 	pub(crate) async fn compute(
 		&self,
@@ -65,14 +141,322 @@ impl SelectStatement {
 		opt: &Options,
 		doc: Option<&CursorDoc<'_>>,
 	) -> Result<Value, Error> {
+		self.compute_page(stk, ctx, opt, doc).await.map(|(v, _)| v)
+	}
+
+	/// Process this type returning a computed simple Value, along with
+	/// a cursor for the next page if the statement has a START AFTER clause
+	pub(crate) async fn compute_page(
+		&self,
+		stk: &mut Stk,
+		ctx: &Context<'_>,
+		opt: &Options,
+		doc: Option<&CursorDoc<'_>>,
+	) -> Result<(Value, Option<String>), Error> {
+		// Serve the result from the cache if this statement is cacheable
+		if let Some(ref ttl) = self.cache {
+			if doc.is_none() && !self.writeable() {
+				return self.compute_cached(stk, ctx, opt, ttl).await;
+			}
+		}
+		self.compute_uncached(stk, ctx, opt, doc).await
+	}
+
+	/// Process this type, storing the result in the result cache
+	async fn compute_cached(
+		&self,
+		stk: &mut Stk,
+		ctx: &Context<'_>,
+		opt: &Options,
+		ttl: &Duration,
+	) -> Result<(Value, Option<String>), Error> {
+		// Valid options?
+		opt.valid_for_db()?;
+		// Only the records in the selected tables are tracked
+		let (Some(tables), Some(key)) = (self.cache_tables(), self.cache_key(ctx, opt)?) else {
+			return self.compute_uncached(stk, ctx, opt, None).await;
+		};
+		// Uncommitted writes must not be cached, or served stale results
+		let (cache, stamp) = {
+			let mut run = ctx.tx_lock().await;
+			let cache = run.results();
+			if run.has_changes() || !cache.enabled() {
+				drop(run);
+				return self.compute_uncached(stk, ctx, opt, None).await;
+			}
+			// Read the table versions which the result depends on
+			(cache, run.table_versions(opt.ns()?, opt.db()?, &tables).await?)
+		};
+		// Check if there is a cached result
+		if let Some(v) = cache.get(&key, &stamp) {
+			return Ok((v, None));
+		}
+		// Compute the result
+		let (res, next) = self.compute_uncached(stk, ctx, opt, None).await?;
+		// Paginated results are not cached
+		if next.is_none() {
+			let ttl = match ttl.is_zero() {
+				true => std::time::Duration::from_secs(*RESULT_CACHE_TTL),
+				false => ttl.0,
+			};
+			cache.insert(key, res.clone(), ttl, stamp);
+		}
+		Ok((res, next))
+	}
+
+	/// The key for the result of this statement in the result cache,
+	/// including the values of any parameters and the authenticated user,
+	/// or none if the parameters which the statement uses are not known
+	fn cache_key(&self, ctx: &Context<'_>, opt: &Options) -> Result<Option<String>, Error> {
+		let mut params = Vec::new();
+		if !self.params(&mut params) {
+			return Ok(None);
+		}
+		params.sort_by(|a, b| a.0.cmp(&b.0));
+		params.dedup();
+		let mut key = format!("{}\0{}\0{:?}\0{self}", opt.ns()?, opt.db()?, opt.auth);
+		for p in params {
+			if let Some(v) = ctx.value(p.as_str()) {
+				key.push_str(&format!("\0{p}={v}"));
+			}
+		}
+		Ok(Some(key))
+	}
+
+	/// The tables which the result of this statement depends on, or none
+	/// if the statement does not only select from tables or records, or
+	/// if it could read from any records other than those it selects
+	fn cache_tables(&self) -> Option<Vec<String>> {
+		if self.fetch.is_some() || !self.reads_only_selected() {
+			return None;
+		}
+		let mut tables = Vec::new();
+		for w in self.what.iter() {
+			match w {
+				Value::Table(v) => tables.push(v.0.clone()),
+				Value::Thing(v) => tables.push(v.tb.clone()),
+				Value::Range(v) => tables.push(v.tb.clone()),
+				_ => return None,
+			}
+		}
+		Some(tables)
+	}
+
+	/// Process this type without using the result cache
+	async fn compute_uncached(
+		&self,
+		stk: &mut Stk,
+		ctx: &Context<'_>,
+		opt: &Options,
+		doc: Option<&CursorDoc<'_>>,
+	) -> Result<(Value, Option<String>), Error> {
+		match self.cursor {
+			// A START AFTER clause paginates the records with a cursor
+			Some(ref v) => match v.compute(stk, ctx, opt, doc).await? {
+				// This is the first page of a paginated query
+				Value::None => self.compute_cursor(stk, ctx, opt, doc, None).await,
+				// This query continues on from a previous page
+				Value::Strand(v) => self.compute_cursor(stk, ctx, opt, doc, Some(v.as_str())).await,
+				// A cursor is always a string
+				v => Err(Error::InvalidCursor {
+					value: v.to_string(),
+				}),
+			},
+			None => self.compute_records(stk, ctx, opt, doc, None).await.map(|v| (v, None)),
+		}
+	}
+
+	/// Process a page of records which follow on from a cursor
+	async fn compute_cursor(
+		&self,
+		stk: &mut Stk,
+		ctx: &Context<'_>,
+		opt: &Options,
+		doc: Option<&CursorDoc<'_>>,
+		cursor: Option<&str>,
+	) -> Result<(Value, Option<String>), Error> {
 		// Valid options?
 		opt.valid_for_db()?;
+		// Cursors can not seek within grouped or aggregated results
+		if self.only
+			|| self.group.is_some()
+			|| self.split.is_some()
+			|| self.distinct.is_some()
+			|| self.having.is_some()
+			|| self.expr.has_windows()
+		{
+			return Err(Error::CursorUnsupported);
+		}
+		// Get the key which cursors are signed with
+		let key = ctx.get_cursor_secret()?;
+		// Without an ORDER clause cursors seek within the natural record order
+		let Some(ref orders) = self.order else {
+			return self.compute_natural(stk, ctx, opt, doc, key, cursor).await;
+		};
+		// Cursors can not follow on from a random ordering
+		if orders.iter().any(|o| o.random) {
+			return Err(Error::CursorUnsupported);
+		}
+		// Records with the same ordered values are ordered by their id
+		let mut orders = orders.clone();
+		if !orders.iter().any(|o| o.order.0 == ID[..]) {
+			orders.0.push(Order {
+				order: Idiom::from(ID.to_vec()),
+				direction: true,
+				..Default::default()
+			});
+		}
+		// A cursor is only valid for the ordering which created it
+		let order = orders.to_string();
+		let mut i = match cursor {
+			Some(v) => Iterator::new().with_after(orders.clone(), cursor::decode(key, &order, v)?),
+			None => Iterator::new(),
+		};
+		// The cursor replaces any START clause
+		let stm = SelectStatement {
+			order: Some(orders.clone()),
+			start: None,
+			cursor: None,
+			..self.clone()
+		};
+		let res = stm.compute_iterator(stk, ctx, opt, doc, &mut i).await?;
+		// The next page follows on from the ordered values of the last record
+		let next = match res {
+			Value::Array(ref v) => match v.last() {
+				Some(v) if v.pick(&*ID).is_some() => {
+					let mut after = Value::base();
+					for o in orders.iter() {
+						after.put(o, v.pick(o));
+					}
+					Some(cursor::encode(key, &order, &after))
+				}
+				Some(_) => return Err(Error::CursorUnsupported),
+				None => None,
+			},
+			_ => None,
+		};
+		Ok((res, next))
+	}
+
+	/// Process a page of records which follow on from a cursor, in the natural record order
+	async fn compute_natural(
+		&self,
+		stk: &mut Stk,
+		ctx: &Context<'_>,
+		opt: &Options,
+		doc: Option<&CursorDoc<'_>>,
+		key: &[u8],
+		cursor: Option<&str>,
+	) -> Result<(Value, Option<String>), Error> {
+		// The natural order can only be followed within a single table
+		let tb = match self.what.0.as_slice() {
+			[v] => match v.compute(stk, ctx, opt, doc).await? {
+				Value::Table(v) => v.0,
+				_ => return Err(Error::CursorUnsupported),
+			},
+			_ => return Err(Error::CursorUnsupported),
+		};
+		// Check that the cursor belongs to this table
+		let beg = match cursor {
+			Some(c) => match cursor::decode(key, "", c)? {
+				Value::Thing(v) if v.tb == tb => Bound::Excluded(v.id),
+				_ => {
+					return Err(Error::InvalidCursor {
+						value: c.to_owned(),
+					})
+				}
+			},
+			None => Bound::Unbounded,
+		};
 		// Create a new iterator
 		let mut i = Iterator::new();
 		// Ensure futures are stored
 		let opt = &opt.new_with_futures(false).with_projections(true);
+		// Seek directly to the record after the cursor
+		i.ingest(Iterable::Range(Range {
+			tb,
+			beg,
+			end: Bound::Unbounded,
+		}));
+		// The cursor replaces any START clause
+		let stm = SelectStatement {
+			start: None,
+			cursor: None,
+			..self.clone()
+		};
+		let stm = Statement::from(&stm);
+		// Output the results
+		let res = i.output(stk, ctx, opt, &stm).await?;
+		// The next page follows on from the last record
+		let next = match res {
+			Value::Array(ref v) => match v.last() {
+				Some(v) => match v.rid().record() {
+					Some(v) => Some(cursor::encode(key, "", &Value::from(v))),
+					None => return Err(Error::CursorUnsupported),
+				},
+				None => None,
+			},
+			_ => None,
+		};
+		Ok((res, next))
+	}
+
+	/// Check if the records can be sent to a stream as they are produced
+	pub(crate) fn streamable(&self) -> bool {
+		!self.only
+			&& !self.tempfiles
+			&& self.start.is_none()
+			&& self.cursor.is_none()
+			&& self.order.is_none()
+			&& self.group.is_none()
+			&& self.split.is_none()
+			&& self.distinct.is_none()
+			&& self.having.is_none()
+			&& !self.expr.has_windows()
+			&& self.fetch.is_none()
+			&& self.explain.is_none()
+			&& self.cache.is_none()
+	}
+
+	/// Process the records which are selected by this statement,
+	/// sending them to the stream in batches if one is specified
+	pub(crate) async fn compute_records(
+		&self,
+		stk: &mut Stk,
+		ctx: &Context<'_>,
+		opt: &Options,
+		doc: Option<&CursorDoc<'_>>,
+		stream: Option<&ResultStream>,
+	) -> Result<Value, Error> {
+		// Create a new iterator
+		let mut i = match stream {
+			Some(stream) => Iterator::new().with_stream(stream.clone()),
+			None => Iterator::new(),
+		};
+		self.compute_iterator(stk, ctx, opt, doc, &mut i).await
+	}
+
+	/// Process the records which are selected by this statement with an iterator
+	pub(crate) async fn compute_iterator(
+		&self,
+		stk: &mut Stk,
+		ctx: &Context<'_>,
+		opt: &Options,
+		doc: Option<&CursorDoc<'_>>,
+		i: &mut Iterator,
+	) -> Result<Value, Error> {
+		// Valid options?
+		opt.valid_for_db()?;
+		// Ensure futures are stored
+		let opt = &opt.new_with_futures(false).with_projections(true);
+		// Soft-deleted records are not indexed, so indexes can not be used
+		let noindex = Some(With::NoIndex);
+		let with = match self.deleted {
+			true => &noindex,
+			false => &self.with,
+		};
 		// Get a query planner
-		let mut planner = QueryPlanner::new(opt, &self.with, &self.cond);
+		let mut planner = QueryPlanner::new(opt, with, &self.cond);
 		// Used for ONLY: is the limit 1?
 		let limit_is_one_or_zero = match &self.limit {
 			Some(l) => l.process(stk, ctx, opt, doc).await? <= 1,
@@ -91,7 +475,7 @@ impl SelectStatement {
 						return Err(Error::SingleOnlyOutput);
 					}
 
-					planner.add_iterables(stk, ctx, t, &mut i).await?;
+					planner.add_iterables(stk, ctx, t, i).await?;
 				}
 				Value::Thing(v) => i.ingest(Iterable::Thing(v)),
 				Value::Range(v) => {
@@ -125,7 +509,7 @@ impl SelectStatement {
 					for v in v {
 						match v {
 							Value::Table(t) => {
-								planner.add_iterables(stk, ctx, t, &mut i).await?;
+								planner.add_iterables(stk, ctx, t, i).await?;
 							}
 							Value::Thing(v) => i.ingest(Iterable::Thing(v)),
 							Value::Edges(v) => i.ingest(Iterable::Edges(*v)),
@@ -168,7 +552,11 @@ impl SelectStatement {
 
 impl fmt::Display for SelectStatement {
 	fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {
-		write!(f, "SELECT {}", self.expr)?;
+		f.write_str("SELECT")?;
+		if let Some(ref v) = self.distinct {
+			write!(f, " DISTINCT ON ({v})")?
+		}
+		write!(f, " {}", self.expr)?;
 		if let Some(ref v) = self.omit {
 			write!(f, " OMIT {v}")?
 		}
@@ -177,6 +565,9 @@ impl fmt::Display for SelectStatement {
 			f.write_str(" ONLY")?
 		}
 		write!(f, " {}", self.what)?;
+		if self.deleted {
+			f.write_str(" WITH DELETED")?
+		}
 		if let Some(ref v) = self.with {
 			write!(f, " {v}")?
 		}
@@ -189,6 +580,9 @@ impl fmt::Display for SelectStatement {
 		if let Some(ref v) = self.group {
 			write!(f, " {v}")?
 		}
+		if let Some(ref v) = self.having {
+			write!(f, " HAVING {}", v.0)?
+		}
 		if let Some(ref v) = self.order {
 			write!(f, " {v}")?
 		}
@@ -198,6 +592,9 @@ impl fmt::Display for SelectStatement {
 		if let Some(ref v) = self.start {
 			write!(f, " {v}")?
 		}
+		if let Some(ref v) = self.cursor {
+			write!(f, " START AFTER {v}")?
+		}
 		if let Some(ref v) = self.fetch {
 			write!(f, " {v}")?
 		}
@@ -207,6 +604,12 @@ impl fmt::Display for SelectStatement {
 		if let Some(ref v) = self.timeout {
 			write!(f, " {v}")?
 		}
+		if let Some(ref v) = self.cache {
+			match v.is_zero() {
+				true => f.write_str(" CACHE")?,
+				false => write!(f, " CACHE {v}")?,
+			}
+		}
 		if self.parallel {
 			f.write_str(" PARALLEL")?
 		}
@@ -3,20 +3,22 @@ use crate::ctx::Context;
 use crate::dbs::Options;
 use crate::doc::CursorDoc;
 use crate::err::Error;
-use crate::sql::Value;
+use crate::sql::{Kind, Number, Strand, Value};
 use derive::Store;
 use reblessive::tree::Stk;
 use revision::revisioned;
 use serde::{Deserialize, Serialize};
 use std::fmt;
 
-#[revisioned(revision = 1)]
+#[revisioned(revision = 2)]
 #[derive(Clone, Debug, Default, Eq, PartialEq, PartialOrd, Serialize, Deserialize, Store, Hash)]
 #[cfg_attr(feature = "arbitrary", derive(arbitrary::Arbitrary))]
 #[non_exhaustive]
 pub struct SetStatement {
 	pub name: String,
 	pub what: Value,
+	#[revision(start = 2)]
+	pub kind: Option<Kind>,
 }
 
 impl SetStatement {
@@ -24,6 +26,38 @@ impl SetStatement {
 	pub(crate) fn writeable(&self) -> bool {
 		self.what.writeable()
 	}
+	/// Check if this statement declares the type of a bound parameter
+	pub(crate) fn is_declaration(&self) -> bool {
+		self.kind.is_some() && matches!(&self.what, Value::Param(p) if p.as_str() == self.name)
+	}
+	/// Check the bound value of a declared parameter, coercing it to the declared type
+	pub(crate) fn check(&self, ctx: &Context<'_>) -> Result<Value, Error> {
+		self.coerce(ctx.value(&self.name).cloned().unwrap_or_default())
+	}
+	/// Coerce the value of this parameter to the declared type
+	fn coerce(&self, val: Value) -> Result<Value, Error> {
+		let Some(kind) = &self.kind else {
+			return Ok(val);
+		};
+		// A declared parameter must be bound, unless it is optional
+		if val.is_none() && self.is_declaration() && !matches!(kind, Kind::Any | Kind::Option(_)) {
+			return Err(Error::MissingParam {
+				name: self.name.to_owned(),
+				kind: kind.to_string(),
+			});
+		}
+		match coerce(val, kind) {
+			Err(Error::CoerceTo {
+				from,
+				..
+			}) => Err(Error::InvalidParamType {
+				name: self.name.to_owned(),
+				kind: kind.to_string(),
+				value: from.to_string(),
+			}),
+			res => res,
+		}
+	}
 	/// Process this type returning a computed simple Value
 	pub(crate) async fn compute(
 		&self,
@@ -35,7 +69,11 @@ impl SetStatement {
 		// Check if the variable is a protected variable
 		match PROTECTED_PARAM_NAMES.contains(&self.name.as_str()) {
 			// The variable isn't protected and can be stored
-			false => self.what.compute(stk, ctx, opt, doc).await,
+			false => match self.is_declaration() {
+				// The value of a declared parameter is bound to the query
+				true => self.check(ctx),
+				false => self.coerce(self.what.compute(stk, ctx, opt, doc).await?),
+			},
 			// The user tried to set a protected variable
 			true => Err(Error::InvalidParam {
 				// Move the parameter name, as we no longer need it
@@ -47,6 +85,98 @@ impl SetStatement {
 
 impl fmt::Display for SetStatement {
 	fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {
-		write!(f, "LET ${} = {}", self.name, self.what)
+		write!(f, "LET ${}", self.name)?;
+		if let Some(ref kind) = self.kind {
+			write!(f, ": {kind}")?;
+		}
+		if !self.is_declaration() {
+			write!(f, " = {}", self.what)?;
+		}
+		Ok(())
+	}
+}
+
+/// Coerce a value to the declared type of a parameter.
+///
+/// Values are coerced in the same way as the values of typed fields.
+/// Strings are also converted to numbers and datetimes, but only when
+/// converting the result back to a string gives the original string,
+/// so that no information is lost in the conversion.
+fn coerce(val: Value, kind: &Kind) -> Result<Value, Error> {
+	match val.coerce_to(kind) {
+		Err(Error::CoerceTo {
+			from: Value::Strand(v),
+			into,
+		}) => match convert(&v, kind) {
+			Some(v) => Ok(v),
+			None => Err(Error::CoerceTo {
+				from: Value::Strand(v),
+				into,
+			}),
+		},
+		res => res,
+	}
+}
+
+/// Convert a string to a number or a datetime, if it can be converted back
+fn convert(v: &Strand, kind: &Kind) -> Option<Value> {
+	match kind {
+		Kind::Option(k) => convert(v, k),
+		Kind::Either(k) => k.iter().find_map(|k| convert(v, k)),
+		Kind::Int | Kind::Float | Kind::Decimal | Kind::Number | Kind::Datetime => {
+			let val = Value::from(v.clone()).convert_to(kind).ok()?;
+			let reversible = match &val {
+				Value::Number(Number::Int(n)) => n.to_string() == v.0,
+				// Floats can be written with or without a fractional part
+				Value::Number(Number::Float(n)) => n.to_string() == v.0 || format!("{n:?}") == v.0,
+				Value::Number(Number::Decimal(n)) => n.to_string() == v.0,
+				Value::Datetime(d) => d.to_raw() == v.0,
+				_ => false,
+			};
+			reversible.then_some(val)
+		}
+		_ => None,
+	}
+}
+
+#[cfg(test)]
+mod tests {
+	use super::*;
+
+	#[test]
+	fn coerces_reversible_strings() {
+		let tests = [
+			("42", Kind::Int, Some("42")),
+			("-7", Kind::Number, Some("-7")),
+			("1.5", Kind::Float, Some("1.5f")),
+			("1.0", Kind::Float, Some("1f")),
+			("1.50", Kind::Decimal, Some("1.50dec")),
+			("42", Kind::Option(Box::new(Kind::Int)), Some("42")),
+			("42", Kind::Either(vec![Kind::Bool, Kind::Int]), Some("42")),
+			("2024-01-01T00:00:00Z", Kind::Datetime, Some("d'2024-01-01T00:00:00Z'")),
+			// Conversions which would lose information are not allowed
+			("042", Kind::Int, None),
+			("4.2", Kind::Int, None),
+			(" 42", Kind::Int, None),
+			("1e3", Kind::Float, None),
+			("2024-01-01T02:00:00+02:00", Kind::Datetime, None),
+			("2024-01-01", Kind::Datetime, None),
+			("true", Kind::Bool, None),
+		];
+		for (val, kind, expected) in tests {
+			let res = coerce(Value::from(val), &kind);
+			match expected {
+				Some(expected) => assert_eq!(res.unwrap().to_string(), expected, "{val} as {kind}"),
+				None => {
+					assert!(matches!(res, Err(Error::CoerceTo { .. })), "{val} as {kind}: {res:?}")
+				}
+			}
+		}
+	}
+
+	#[test]
+	fn strings_which_match_the_type_are_not_converted() {
+		let kind = Kind::Either(vec![Kind::Int, Kind::String]);
+		assert_eq!(coerce(Value::from("42"), &kind).unwrap(), Value::from("42"));
 	}
 }
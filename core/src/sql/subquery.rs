@@ -4,9 +4,9 @@ use crate::doc::CursorDoc;
 use crate::err::Error;
 use crate::sql::statements::rebuild::RebuildStatement;
 use crate::sql::statements::{
-	CreateStatement, DefineStatement, DeleteStatement, IfelseStatement, InsertStatement,
-	OutputStatement, RelateStatement, RemoveStatement, SelectStatement, UpdateStatement,
-	UpsertStatement,
+	CaseStatement, CreateStatement, DefineStatement, DeleteStatement, IfelseStatement,
+	InsertStatement, OutputStatement, RelateStatement, RemoveStatement, SelectStatement,
+	UpdateStatement, UpsertStatement,
 };
 use crate::sql::value::Value;
 use reblessive::tree::Stk;
@@ -17,7 +17,7 @@ use std::fmt::{self, Display, Formatter};
 
 pub(crate) const TOKEN: &str = "$surrealdb::private::sql::Subquery";
 
-#[revisioned(revision = 3)]
+#[revisioned(revision = 4)]
 #[derive(Clone, Debug, Eq, PartialEq, Serialize, Deserialize, Hash)]
 #[serde(rename = "$surrealdb::private::sql::Subquery")]
 #[cfg_attr(feature = "arbitrary", derive(arbitrary::Arbitrary))]
@@ -38,6 +38,8 @@ pub enum Subquery {
 	Rebuild(RebuildStatement),
 	#[revision(start = 3)]
 	Upsert(UpsertStatement),
+	#[revision(start = 4)]
+	Case(CaseStatement),
 }
 
 impl PartialOrd for Subquery {
@@ -64,6 +66,7 @@ impl Subquery {
 			Self::Define(v) => v.writeable(),
 			Self::Remove(v) => v.writeable(),
 			Self::Rebuild(v) => v.writeable(),
+			Self::Case(v) => v.writeable(),
 		}
 	}
 	/// Process this type returning a computed simple Value
@@ -95,6 +98,7 @@ impl Subquery {
 			Self::Delete(ref v) => v.compute(stk, &ctx, opt, doc).await,
 			Self::Relate(ref v) => v.compute(stk, &ctx, opt, doc).await,
 			Self::Insert(ref v) => v.compute(stk, &ctx, opt, doc).await,
+			Self::Case(ref v) => v.compute(stk, &ctx, opt, doc).await,
 		}
 	}
 }
@@ -115,6 +119,7 @@ impl Display for Subquery {
 			Self::Remove(v) => write!(f, "({v})"),
 			Self::Rebuild(v) => write!(f, "({v})"),
 			Self::Ifelse(v) => Display::fmt(v, f),
+			Self::Case(v) => Display::fmt(v, f),
 		}
 	}
 }
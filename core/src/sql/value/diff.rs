@@ -46,13 +46,13 @@ impl Value {
 					}
 					n += 1;
 				}
-				while n < a.len() {
-					if n >= b.len() {
-						ops.push(Operation::Remove {
-							path: path.clone().push(n.into()),
-						})
-					}
-					n += 1;
+				// Remove from the end, so that each index is still valid
+				n = a.len();
+				while n > b.len() {
+					n -= 1;
+					ops.push(Operation::Remove {
+						path: path.clone().push(n.into()),
+					})
 				}
 			}
 			(Value::Strand(a), Value::Strand(b)) if a != b => ops.push(Operation::Change {
@@ -112,6 +112,15 @@ mod tests {
 		assert_eq!(res.to_operations().unwrap(), old.diff(&now, Idiom::default()));
 	}
 
+	#[test]
+	fn diff_remove_array() {
+		let old = Value::parse("{ test: [1,2,3,4] }");
+		let now = Value::parse("{ test: [1,2] }");
+		let res =
+			Value::parse("[{ op: 'remove', path: '/test/3' }, { op: 'remove', path: '/test/2' }]");
+		assert_eq!(res.to_operations().unwrap(), old.diff(&now, Idiom::default()));
+	}
+
 	#[test]
 	fn diff_replace_embedded() {
 		let old = Value::parse("{ test: { other: 'test' } }");
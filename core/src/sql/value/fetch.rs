@@ -6,9 +6,46 @@ use crate::sql::field::{Field, Fields};
 use crate::sql::part::Next;
 use crate::sql::part::Part;
 use crate::sql::statements::select::SelectStatement;
+use crate::sql::thing::Thing;
 use crate::sql::value::{Value, Values};
 use futures::future::try_join_all;
+use futures::lock::Mutex;
 use reblessive::tree::Stk;
+use std::collections::HashMap;
+
+/// The records which have already been loaded while processing
+/// the FETCH clauses of a statement, so that a record which is
+/// linked from many places is only read from storage once.
+#[derive(Default)]
+pub(crate) struct FetchCache(Mutex<HashMap<Thing, Value>>);
+
+impl FetchCache {
+	/// Load a linked record, or NULL if it does not exist
+	async fn load(
+		&self,
+		stk: &mut Stk,
+		ctx: &Context<'_>,
+		opt: &Options,
+		rid: &Thing,
+	) -> Result<Value, Error> {
+		// The lock is not held while the record is loaded, as loading
+		// it may need to check the cache for other linked records
+		if let Some(v) = self.0.lock().await.get(rid) {
+			return Ok(v.clone());
+		}
+		let stm = SelectStatement {
+			expr: Fields(vec![Field::All], false),
+			what: Values(vec![Value::from(rid.clone())]),
+			..SelectStatement::default()
+		};
+		let v = match stm.compute(stk, ctx, opt, None).await?.first() {
+			Value::None => Value::Null,
+			v => v,
+		};
+		self.0.lock().await.insert(rid.clone(), v.clone());
+		Ok(v)
+	}
+}
 
 impl Value {
 	/// Was marked recursive
@@ -17,6 +54,7 @@ impl Value {
 		stk: &mut Stk,
 		ctx: &Context<'_>,
 		opt: &Options,
+		cache: &FetchCache,
 		path: &[Part],
 	) -> Result<(), Error> {
 		match path.first() {
@@ -27,19 +65,19 @@ impl Value {
 					Part::Graph(_) => match v.rid() {
 						Some(v) => {
 							let mut v = Value::Thing(v);
-							stk.run(|stk| v.fetch(stk, ctx, opt, path.next())).await
+							stk.run(|stk| v.fetch(stk, ctx, opt, cache, path.next())).await
 						}
 						None => Ok(()),
 					},
 					Part::Field(f) => match v.get_mut(f as &str) {
-						Some(v) => stk.run(|stk| v.fetch(stk, ctx, opt, path.next())).await,
+						Some(v) => stk.run(|stk| v.fetch(stk, ctx, opt, cache, path.next())).await,
 						None => Ok(()),
 					},
 					Part::Index(i) => match v.get_mut(&i.to_string()) {
-						Some(v) => stk.run(|stk| v.fetch(stk, ctx, opt, path.next())).await,
+						Some(v) => stk.run(|stk| v.fetch(stk, ctx, opt, cache, path.next())).await,
 						None => Ok(()),
 					},
-					Part::All => stk.run(|stk| self.fetch(stk, ctx, opt, path.next())).await,
+					Part::All => stk.run(|stk| self.fetch(stk, ctx, opt, cache, path.next())).await,
 					_ => Ok(()),
 				},
 				// Current path part is an array
@@ -47,23 +85,24 @@ impl Value {
 					Part::All => {
 						let path = path.next();
 						stk.scope(|scope| {
-							let futs =
-								v.iter_mut().map(|v| scope.run(|stk| v.fetch(stk, ctx, opt, path)));
+							let futs = v
+								.iter_mut()
+								.map(|v| scope.run(|stk| v.fetch(stk, ctx, opt, cache, path)));
 							try_join_all(futs)
 						})
 						.await?;
 						Ok(())
 					}
 					Part::First => match v.first_mut() {
-						Some(v) => stk.run(|stk| v.fetch(stk, ctx, opt, path.next())).await,
+						Some(v) => stk.run(|stk| v.fetch(stk, ctx, opt, cache, path.next())).await,
 						None => Ok(()),
 					},
 					Part::Last => match v.last_mut() {
-						Some(v) => stk.run(|stk| v.fetch(stk, ctx, opt, path.next())).await,
+						Some(v) => stk.run(|stk| v.fetch(stk, ctx, opt, cache, path.next())).await,
 						None => Ok(()),
 					},
 					Part::Index(i) => match v.get_mut(i.to_usize()) {
-						Some(v) => stk.run(|stk| v.fetch(stk, ctx, opt, path.next())).await,
+						Some(v) => stk.run(|stk| v.fetch(stk, ctx, opt, cache, path.next())).await,
 						None => Ok(()),
 					},
 					Part::Where(w) => {
@@ -71,15 +110,16 @@ impl Value {
 						for v in v.iter_mut() {
 							let cur = v.into();
 							if w.compute(stk, ctx, opt, Some(&cur)).await?.is_truthy() {
-								stk.run(|stk| v.fetch(stk, ctx, opt, path)).await?;
+								stk.run(|stk| v.fetch(stk, ctx, opt, cache, path)).await?;
 							}
 						}
 						Ok(())
 					}
 					_ => {
 						stk.scope(|scope| {
-							let futs =
-								v.iter_mut().map(|v| scope.run(|stk| v.fetch(stk, ctx, opt, path)));
+							let futs = v
+								.iter_mut()
+								.map(|v| scope.run(|stk| v.fetch(stk, ctx, opt, cache, path)));
 							try_join_all(futs)
 						})
 						.await?;
@@ -116,12 +156,7 @@ impl Value {
 						}
 						// This is a remote field expression
 						_ => {
-							let stm = SelectStatement {
-								expr: Fields(vec![Field::All], false),
-								what: Values(vec![Value::from(val)]),
-								..SelectStatement::default()
-							};
-							*self = stm.compute(stk, ctx, opt, None).await?.first();
+							*self = cache.load(stk, ctx, opt, &val).await?;
 							Ok(())
 						}
 					}
@@ -134,8 +169,9 @@ impl Value {
 				// Current path part is an array
 				Value::Array(v) => {
 					stk.scope(|scope| {
-						let futs =
-							v.iter_mut().map(|v| scope.run(|stk| v.fetch(stk, ctx, opt, path)));
+						let futs = v
+							.iter_mut()
+							.map(|v| scope.run(|stk| v.fetch(stk, ctx, opt, cache, path)));
 						try_join_all(futs)
 					})
 					.await?;
@@ -146,12 +182,7 @@ impl Value {
 					// Clone the thing
 					let val = v.clone();
 					// Fetch the remote embedded record
-					let stm = SelectStatement {
-						expr: Fields(vec![Field::All], false),
-						what: Values(vec![Value::from(val)]),
-						..SelectStatement::default()
-					};
-					*self = stm.compute(stk, ctx, opt, None).await?.first();
+					*self = cache.load(stk, ctx, opt, &val).await?;
 					Ok(())
 				}
 				// Ignore everything else
@@ -1,4 +1,4 @@
-use crate::cnf::MAX_COMPUTATION_DEPTH;
+use crate::cnf::{MAX_COMPUTATION_DEPTH, MAX_GRAPH_TRAVERSAL_DEPTH};
 use crate::ctx::Context;
 use crate::dbs::Options;
 use crate::doc::CursorDoc;
@@ -14,6 +14,7 @@ use crate::sql::statements::select::SelectStatement;
 use crate::sql::thing::Thing;
 use crate::sql::value::{Value, Values};
 use reblessive::tree::Stk;
+use std::collections::HashSet;
 
 impl Value {
 	/// Asynchronous method for getting a local or remote field from a `Value`
@@ -32,6 +33,11 @@ impl Value {
 			return Err(Error::ComputationDepthExceeded);
 		}
 		match path.first() {
+			// Current path part is a recursive graph traversal
+			Some(Part::Recurse(parts, depth)) => {
+				let v = stk.run(|stk| self.recurse(stk, ctx, opt, doc, parts, *depth)).await?;
+				stk.run(|stk| v.get(stk, ctx, opt, doc, path.next())).await
+			}
 			// Get the current value at the path
 			Some(p) => match self {
 				// Current value at path is a geometry
@@ -265,6 +271,56 @@ impl Value {
 			None => Ok(self.clone()),
 		}
 	}
+
+	/// Repeatedly follow a chain of graph parts from the records in this `Value`,
+	/// returning each distinct record which is reached. Records which have already
+	/// been visited are not followed again, so cyclic graphs terminate, and the
+	/// traversal stops once the depth is reached.
+	async fn recurse(
+		&self,
+		stk: &mut Stk,
+		ctx: &Context<'_>,
+		opt: &Options,
+		doc: Option<&CursorDoc<'_>>,
+		path: &[Part],
+		depth: Option<u32>,
+	) -> Result<Self, Error> {
+		// Never go deeper than the configured maximum
+		let depth = depth.map_or(*MAX_GRAPH_TRAVERSAL_DEPTH, |d| d.min(*MAX_GRAPH_TRAVERSAL_DEPTH));
+		// The records which have already been visited
+		let mut seen = HashSet::new();
+		// The records which are reached on each pass
+		let mut next = Vec::new();
+		records(self.clone(), &mut next);
+		next.retain(|v| seen.insert(v.clone()));
+		// The records which have been found
+		let mut res = Vec::new();
+		for _ in 0..depth {
+			if next.is_empty() {
+				break;
+			}
+			let v = Value::from(next.drain(..).map(Value::Thing).collect::<Vec<_>>());
+			let v = stk.run(|stk| v.get(stk, ctx, opt, doc, path)).await?;
+			records(v, &mut next);
+			next.retain(|v| seen.insert(v.clone()));
+			res.extend(next.iter().cloned().map(Value::Thing));
+		}
+		Ok(res.into())
+	}
+}
+
+/// Collect the record ids which are contained in a value
+fn records(v: Value, out: &mut Vec<Thing>) {
+	match v {
+		Value::Thing(v) => out.push(v),
+		Value::Object(v) => {
+			if let Some(v) = v.rid() {
+				out.push(v)
+			}
+		}
+		Value::Array(v) => v.into_iter().for_each(|v| records(v, out)),
+		_ => (),
+	}
 }
 
 #[cfg(test)]
@@ -0,0 +1,110 @@
+use crate::err::Error;
+use crate::sql::value::Value;
+
+impl Value {
+	/// Apply a JSON Merge Patch (RFC 7386) to this value. Unlike `merge`,
+	/// a NULL value removes a field, and an object in the patch replaces
+	/// any existing value which is not an object before being merged.
+	pub(crate) fn merge_patch(&mut self, val: Value) -> Result<(), Error> {
+		// If this value is not an object, then error
+		if !val.is_object() {
+			return Err(Error::InvalidMerge {
+				value: val,
+			});
+		}
+		// Otherwise merge the object
+		self.merge_patch_value(val);
+		Ok(())
+	}
+
+	fn merge_patch_value(&mut self, val: Value) {
+		match val {
+			// Objects are merged recursively
+			Value::Object(val) => {
+				if !self.is_object() {
+					*self = Value::Object(Default::default());
+				}
+				if let Value::Object(obj) = self {
+					for (k, v) in val.0 {
+						match v {
+							Value::Null | Value::None => {
+								obj.remove(&k);
+							}
+							v => obj.entry(k).or_default().merge_patch_value(v),
+						}
+					}
+				}
+			}
+			// Any other value, including an array, replaces this one
+			val => *self = val,
+		}
+	}
+}
+
+#[cfg(test)]
+mod tests {
+
+	use super::*;
+	use crate::syn::Parse;
+
+	#[tokio::test]
+	async fn merge_patch_none() {
+		let mut res = Value::parse("{ test: true }");
+		let none = Value::None;
+		match res.merge_patch(none.clone()).unwrap_err() {
+			Error::InvalidMerge {
+				value,
+			} => assert_eq!(value, none),
+			error => panic!("unexpected error: {error:?}"),
+		}
+	}
+
+	#[tokio::test]
+	async fn merge_patch_basic() {
+		let mut res = Value::parse(
+			"{
+				test: true,
+				name: {
+					first: 'Tobie',
+					last: 'Morgan Hitchcock',
+					initials: 'TMH',
+				},
+				tags: ['Rust', 'Go'],
+				other: 'text',
+			}",
+		);
+		let mrg = Value::parse(
+			"{
+				name: {
+					title: 'Mr',
+					initials: NULL,
+				},
+				tags: ['JavaScript'],
+				other: { nested: { value: true, removed: NULL } },
+				test: NULL,
+			}",
+		);
+		let val = Value::parse(
+			"{
+				name: {
+					title: 'Mr',
+					first: 'Tobie',
+					last: 'Morgan Hitchcock',
+				},
+				tags: ['JavaScript'],
+				other: { nested: { value: true } },
+			}",
+		);
+		res.merge_patch(mrg).unwrap();
+		assert_eq!(res, val);
+	}
+
+	#[tokio::test]
+	async fn merge_patch_empty_object() {
+		let mut res = Value::parse("{ test: 'text', other: { some: true } }");
+		let mrg = Value::parse("{ test: {}, other: {} }");
+		let val = Value::parse("{ test: {}, other: { some: true } }");
+		res.merge_patch(mrg).unwrap();
+		assert_eq!(res, val);
+	}
+}
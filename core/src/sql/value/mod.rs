@@ -1,5 +1,7 @@
 pub use self::value::*;
 
+pub(crate) use self::fetch::FetchCache;
+
 pub(super) mod serde;
 
 #[allow(clippy::module_inception)]
@@ -26,6 +28,7 @@ mod inc;
 mod increment;
 mod last;
 mod merge;
+mod merge_patch;
 mod patch;
 mod pick;
 mod put;
@@ -1,6 +1,8 @@
 use crate::err::Error;
 use crate::sql::operation::Operation;
+use crate::sql::part::Part;
 use crate::sql::value::Value;
+use crate::sql::Number;
 
 impl Value {
 	pub(crate) fn patch(&mut self, ops: Value) -> Result<(), Error> {
@@ -13,23 +15,23 @@ impl Value {
 				Operation::Add {
 					path,
 					value,
-				} => match tmp_val.pick(&path) {
-					Value::Array(_) => tmp_val.inc(&path, value),
-					_ => tmp_val.put(&path, value),
-				},
+				} => add(&mut tmp_val, &path, value)?,
 				Operation::Remove {
 					path,
-				} => tmp_val.cut(&path),
+				} => {
+					remove(&mut tmp_val, &path)?;
+				}
 				Operation::Replace {
 					path,
 					value,
-				} => tmp_val.put(&path, value),
+				} => *get_mut(&mut tmp_val, &path).ok_or_else(|| missing(&path))? = value,
 				Operation::Change {
 					path,
 					value,
 				} => {
+					let found_val = get_mut(&mut tmp_val, &path).ok_or_else(|| missing(&path))?;
 					if let Value::Strand(p) = value {
-						if let Value::Strand(v) = tmp_val.pick(&path) {
+						if let Value::Strand(v) = &*found_val {
 							let dmp = dmp::new();
 							let pch = dmp.patch_from_text(p.as_string()).map_err(|e| {
 								Error::InvalidPatch {
@@ -42,7 +44,7 @@ impl Value {
 								}
 							})?;
 							let txt = txt.into_iter().collect::<String>();
-							tmp_val.put(&path, Value::from(txt));
+							*found_val = Value::from(txt);
 						}
 					}
 				}
@@ -50,22 +52,29 @@ impl Value {
 					path,
 					from,
 				} => {
-					let found_val = tmp_val.pick(&from);
-					tmp_val.put(&path, found_val);
+					let found_val = get(&tmp_val, &from).ok_or_else(|| missing(&from))?.clone();
+					add(&mut tmp_val, &path, found_val)?;
 				}
 				Operation::Move {
 					path,
 					from,
 				} => {
-					let found_val = tmp_val.pick(&from);
-					tmp_val.put(&path, found_val);
-					tmp_val.cut(&from);
+					if path.len() > from.len() && path.starts_with(&from) {
+						return Err(Error::InvalidPatch {
+							message: format!(
+								"'{}' can not be moved into one of its children",
+								pointer(&from)
+							),
+						});
+					}
+					let found_val = remove(&mut tmp_val, &from)?;
+					add(&mut tmp_val, &path, found_val)?;
 				}
 				Operation::Test {
 					path,
 					value,
 				} => {
-					let found_val = tmp_val.pick(&path);
+					let found_val = get(&tmp_val, &path).cloned().unwrap_or_default();
 
 					if value != found_val {
 						return Err(Error::PatchTest {
@@ -82,6 +91,96 @@ impl Value {
 	}
 }
 
+/// Get the object key which a path token points to
+fn key(p: &Part) -> Option<String> {
+	match p {
+		Part::Field(f) => Some(f.0.clone()),
+		Part::Index(i) => Some(i.to_string()),
+		Part::Last => Some("-".to_owned()),
+		_ => None,
+	}
+}
+
+/// Get the array position which a path token points to
+fn index(p: &Part) -> Option<usize> {
+	match p {
+		Part::Index(Number::Int(i)) => usize::try_from(*i).ok(),
+		_ => None,
+	}
+}
+
+/// Format a path as a JSON Pointer for error messages
+fn pointer(path: &[Part]) -> String {
+	path.iter().map(|p| format!("/{}", key(p).unwrap_or_default())).collect()
+}
+
+fn missing(path: &[Part]) -> Error {
+	Error::InvalidPatch {
+		message: format!("The path '{}' does not exist", pointer(path)),
+	}
+}
+
+/// Get the existing value at a path
+fn get<'a>(mut v: &'a Value, path: &[Part]) -> Option<&'a Value> {
+	for p in path {
+		v = match v {
+			Value::Object(o) => o.get(&key(p)?)?,
+			Value::Array(a) => a.get(index(p)?)?,
+			_ => return None,
+		};
+	}
+	Some(v)
+}
+
+/// Get the existing value at a path, for modification
+fn get_mut<'a>(mut v: &'a mut Value, path: &[Part]) -> Option<&'a mut Value> {
+	for p in path {
+		v = match v {
+			Value::Object(o) => o.get_mut(&key(p)?)?,
+			Value::Array(a) => a.get_mut(index(p)?)?,
+			_ => return None,
+		};
+	}
+	Some(v)
+}
+
+/// Add a value to an object, or insert it into an array, replacing
+/// the whole document if the path is empty
+fn add(v: &mut Value, path: &[Part], val: Value) -> Result<(), Error> {
+	let Some((last, parent)) = path.split_last() else {
+		*v = val;
+		return Ok(());
+	};
+	match get_mut(v, parent) {
+		Some(Value::Object(o)) => {
+			o.insert(key(last).ok_or_else(|| missing(path))?, val);
+		}
+		// The `-` token appends to the end of an array
+		Some(Value::Array(a)) if matches!(last, Part::Last) => a.push(val),
+		Some(Value::Array(a)) => match index(last) {
+			Some(i) if i <= a.len() => a.insert(i, val),
+			_ => return Err(missing(path)),
+		},
+		_ => return Err(missing(path)),
+	}
+	Ok(())
+}
+
+/// Remove and return the existing value at a path
+fn remove(v: &mut Value, path: &[Part]) -> Result<Value, Error> {
+	let Some((last, parent)) = path.split_last() else {
+		return Err(Error::InvalidPatch {
+			message: String::from("The whole document can not be removed"),
+		});
+	};
+	match get_mut(v, parent) {
+		Some(Value::Object(o)) => key(last).and_then(|k| o.remove(&k)),
+		Some(Value::Array(a)) => index(last).filter(|i| *i < a.len()).map(|i| a.remove(i)),
+		_ => None,
+	}
+	.ok_or_else(|| missing(path))
+}
+
 #[cfg(test)]
 mod tests {
 
@@ -235,4 +334,121 @@ mod tests {
 		// It is important to test if patches applied even if test operation fails
 		assert_eq!(val, should);
 	}
+
+	#[tokio::test]
+	async fn patch_add_array_index() {
+		let mut val = Value::parse("{ test: [1, 3] }");
+		let ops = Value::parse("[{ op: 'add', path: '/test/1', value: 2 }]");
+		let res = Value::parse("{ test: [1, 2, 3] }");
+		val.patch(ops).unwrap();
+		assert_eq!(res, val);
+	}
+
+	#[tokio::test]
+	async fn patch_add_array_append() {
+		let mut val = Value::parse("{ test: [1, 2] }");
+		let ops = Value::parse(
+			"[{ op: 'add', path: '/test/-', value: 3 }, { op: 'add', path: '/test/3', value: 4 }]",
+		);
+		let res = Value::parse("{ test: [1, 2, 3, 4] }");
+		val.patch(ops).unwrap();
+		assert_eq!(res, val);
+	}
+
+	#[tokio::test]
+	async fn patch_add_replaces_existing() {
+		let mut val = Value::parse("{ test: [1, 2] }");
+		let ops = Value::parse("[{ op: 'add', path: '/test', value: [3] }]");
+		let res = Value::parse("{ test: [3] }");
+		val.patch(ops).unwrap();
+		assert_eq!(res, val);
+	}
+
+	#[tokio::test]
+	async fn patch_add_whole_document() {
+		let mut val = Value::parse("{ test: true }");
+		let ops = Value::parse("[{ op: 'add', path: '', value: { other: true } }]");
+		let res = Value::parse("{ other: true }");
+		val.patch(ops).unwrap();
+		assert_eq!(res, val);
+	}
+
+	#[tokio::test]
+	async fn patch_add_escaped_keys() {
+		let mut val = Value::parse("{ test: true }");
+		let ops = Value::parse(
+			"[{ op: 'add', path: '/a~1b', value: 1 }, { op: 'add', path: '/c~0d', value: 2 }, { op: 'add', path: '/e.f', value: 3 }]",
+		);
+		let res = Value::parse("{ 'a/b': 1, 'c~d': 2, 'e.f': 3, test: true }");
+		val.patch(ops).unwrap();
+		assert_eq!(res, val);
+	}
+
+	#[tokio::test]
+	async fn patch_remove_array_index() {
+		let mut val = Value::parse("{ test: [1, 2, 3] }");
+		let ops = Value::parse("[{ op: 'remove', path: '/test/0' }]");
+		let res = Value::parse("{ test: [2, 3] }");
+		val.patch(ops).unwrap();
+		assert_eq!(res, val);
+	}
+
+	#[tokio::test]
+	async fn patch_replace_array_index() {
+		let mut val = Value::parse("{ test: [1, 2, 3] }");
+		let ops = Value::parse("[{ op: 'replace', path: '/test/2', value: 4 }]");
+		let res = Value::parse("{ test: [1, 2, 4] }");
+		val.patch(ops).unwrap();
+		assert_eq!(res, val);
+	}
+
+	#[tokio::test]
+	async fn patch_move_array_index() {
+		let mut val = Value::parse("{ test: [1, 2, 3] }");
+		let ops = Value::parse("[{ op: 'move', path: '/test/-', from: '/test/0' }]");
+		let res = Value::parse("{ test: [2, 3, 1] }");
+		val.patch(ops).unwrap();
+		assert_eq!(res, val);
+	}
+
+	#[tokio::test]
+	async fn patch_copy_array_index() {
+		let mut val = Value::parse("{ test: [1, 2], other: { some: 0 } }");
+		let ops = Value::parse("[{ op: 'copy', path: '/test/0', from: '/other/some' }]");
+		let res = Value::parse("{ test: [0, 1, 2], other: { some: 0 } }");
+		val.patch(ops).unwrap();
+		assert_eq!(res, val);
+	}
+
+	#[tokio::test]
+	async fn patch_test_array_index() {
+		let mut val = Value::parse("{ test: [1, 2] }");
+		let ops = Value::parse(
+			"[{ op: 'test', path: '/test/1', value: 2 }, { op: 'remove', path: '/test/1' }]",
+		);
+		let res = Value::parse("{ test: [1] }");
+		val.patch(ops).unwrap();
+		assert_eq!(res, val);
+	}
+
+	#[tokio::test]
+	async fn patch_missing_paths_invalid() {
+		for ops in [
+			"[{ op: 'add', path: '/missing/test', value: true }]",
+			"[{ op: 'add', path: '/test/5', value: true }]",
+			"[{ op: 'add', path: '/test/01', value: true }]",
+			"[{ op: 'remove', path: '/missing' }]",
+			"[{ op: 'remove', path: '/test/2' }]",
+			"[{ op: 'replace', path: '/missing', value: true }]",
+			"[{ op: 'copy', path: '/other', from: '/missing' }]",
+			"[{ op: 'move', path: '/other', from: '/missing' }]",
+			"[{ op: 'move', path: '/test/0/inner', from: '/test' }]",
+			"[{ op: 'test', path: '/missing', value: true }]",
+		] {
+			let mut val = Value::parse("{ test: [1, 2] }");
+			let should = val.clone();
+			assert!(val.patch(Value::parse(ops)).is_err(), "{ops}");
+			assert_eq!(val, should);
+		}
+	}
 }
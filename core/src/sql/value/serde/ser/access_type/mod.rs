@@ -84,6 +84,7 @@ pub struct SerializeRecord {
 	pub signup: Option<Value>,
 	pub signin: Option<Value>,
 	pub jwt: JwtAccess,
+	pub refresh: bool,
 }
 
 impl serde::ser::SerializeStruct for SerializeRecord {
@@ -104,6 +105,9 @@ impl serde::ser::SerializeStruct for SerializeRecord {
 			"jwt" => {
 				self.jwt = value.serialize(SerializerJwt.wrap())?;
 			}
+			"refresh" => {
+				self.refresh = value.serialize(ser::primitive::bool::Serializer.wrap())?;
+			}
 			key => {
 				return Err(Error::custom(format!("unexpected field `RecordAccess::{key}`")));
 			}
@@ -116,6 +120,7 @@ impl serde::ser::SerializeStruct for SerializeRecord {
 			signup: self.signup,
 			signin: self.signin,
 			jwt: self.jwt,
+			refresh: self.refresh,
 		})
 	}
 }
@@ -64,6 +64,9 @@ impl ser::Serializer for Serializer {
 			"MergeExpression" => {
 				Ok(Data::MergeExpression(value.serialize(ser::value::Serializer.wrap())?))
 			}
+			"MergePatchExpression" => {
+				Ok(Data::MergePatchExpression(value.serialize(ser::value::Serializer.wrap())?))
+			}
 			"ReplaceExpression" => {
 				Ok(Data::ReplaceExpression(value.serialize(ser::value::Serializer.wrap())?))
 			}
@@ -416,6 +419,13 @@ mod tests {
 		assert_eq!(data, serialized);
 	}
 
+	#[test]
+	fn merge_patch_expression() {
+		let data = Data::MergePatchExpression(Default::default());
+		let serialized = data.serialize(Serializer.wrap()).unwrap();
+		assert_eq!(data, serialized);
+	}
+
 	#[test]
 	fn replace_expression() {
 		let data = Data::ReplaceExpression(Default::default());
@@ -2,8 +2,10 @@ pub(super) mod vec;
 
 use crate::err::Error;
 use crate::sql::value::serde::ser;
+use crate::sql::Cond;
 use crate::sql::Field;
 use crate::sql::Idiom;
+use crate::sql::Orders;
 use crate::sql::Value;
 use ser::Serializer as _;
 use serde::ser::Error as _;
@@ -22,7 +24,7 @@ impl ser::Serializer for Serializer {
 	type SerializeTupleVariant = Impossible<Field, Error>;
 	type SerializeMap = Impossible<Field, Error>;
 	type SerializeStruct = Impossible<Field, Error>;
-	type SerializeStructVariant = SerializeValueIdiomTuple;
+	type SerializeStructVariant = SerializeField;
 
 	const EXPECTED: &'static str = "an enum `Field`";
 
@@ -47,12 +49,133 @@ impl ser::Serializer for Serializer {
 		_len: usize,
 	) -> Result<Self::SerializeStructVariant, Self::Error> {
 		match variant {
-			"Single" => Ok(SerializeValueIdiomTuple::default()),
+			"Single" => Ok(SerializeField::Single(Default::default())),
+			"Window" => Ok(SerializeField::Window(Default::default())),
+			"Filter" => Ok(SerializeField::Filter(Default::default())),
 			variant => Err(Error::custom(format!("unexpected struct variant `{name}::{variant}`"))),
 		}
 	}
 }
 
+pub(super) enum SerializeField {
+	Single(SerializeValueIdiomTuple),
+	Window(SerializeWindow),
+	Filter(SerializeFilter),
+}
+
+impl serde::ser::SerializeStructVariant for SerializeField {
+	type Ok = Field;
+	type Error = Error;
+
+	fn serialize_field<T>(&mut self, key: &'static str, value: &T) -> Result<(), Self::Error>
+	where
+		T: Serialize + ?Sized,
+	{
+		match self {
+			Self::Single(v) => v.serialize_field(key, value),
+			Self::Window(v) => v.serialize_field(key, value),
+			Self::Filter(v) => v.serialize_field(key, value),
+		}
+	}
+
+	fn end(self) -> Result<Self::Ok, Self::Error> {
+		match self {
+			Self::Single(v) => v.end(),
+			Self::Window(v) => v.end(),
+			Self::Filter(v) => v.end(),
+		}
+	}
+}
+
+#[derive(Default)]
+pub(super) struct SerializeWindow {
+	value: Option<Value>,
+	order: Option<Option<Orders>>,
+	idiom: Option<Option<Idiom>>,
+}
+
+impl serde::ser::SerializeStructVariant for SerializeWindow {
+	type Ok = Field;
+	type Error = Error;
+
+	fn serialize_field<T>(&mut self, key: &'static str, value: &T) -> Result<(), Self::Error>
+	where
+		T: Serialize + ?Sized,
+	{
+		match key {
+			"expr" => {
+				self.value = Some(value.serialize(ser::value::Serializer.wrap())?);
+			}
+			"order" => {
+				self.order =
+					Some(value.serialize(ser::order::vec::opt::Serializer.wrap())?.map(Orders));
+			}
+			"alias" => {
+				self.idiom = Some(value.serialize(SerializeOptionIdiom.wrap())?);
+			}
+			key => {
+				return Err(Error::custom(format!("unexpected `Field::Window` field `{key}`")));
+			}
+		}
+		Ok(())
+	}
+
+	fn end(self) -> Result<Self::Ok, Self::Error> {
+		match (self.value, self.order, self.idiom) {
+			(Some(expr), Some(order), Some(alias)) => Ok(Field::Window {
+				expr,
+				order,
+				alias,
+			}),
+			_ => Err(Error::custom("`Field::Window` missing required value(s)")),
+		}
+	}
+}
+
+#[derive(Default)]
+pub(super) struct SerializeFilter {
+	value: Option<Value>,
+	cond: Option<Cond>,
+	idiom: Option<Option<Idiom>>,
+}
+
+impl serde::ser::SerializeStructVariant for SerializeFilter {
+	type Ok = Field;
+	type Error = Error;
+
+	fn serialize_field<T>(&mut self, key: &'static str, value: &T) -> Result<(), Self::Error>
+	where
+		T: Serialize + ?Sized,
+	{
+		match key {
+			"expr" => {
+				self.value = Some(value.serialize(ser::value::Serializer.wrap())?);
+			}
+			"cond" => {
+				self.cond = Some(Cond(value.serialize(ser::value::Serializer.wrap())?));
+			}
+			"alias" => {
+				self.idiom = Some(value.serialize(SerializeOptionIdiom.wrap())?);
+			}
+			key => {
+				return Err(Error::custom(format!("unexpected `Field::Filter` field `{key}`")));
+			}
+		}
+		Ok(())
+	}
+
+	fn end(self) -> Result<Self::Ok, Self::Error> {
+		match (self.value, self.cond, self.idiom) {
+			(Some(expr), Some(cond), Some(alias)) => Ok(Field::Filter {
+				expr,
+				cond,
+				alias,
+			}),
+			_ => Err(Error::custom("`Field::Filter` missing required value(s)")),
+		}
+	}
+}
+
 #[derive(Default)]
 pub(super) struct SerializeValueIdiomTuple {
 	value: Option<Value>,
@@ -153,4 +276,37 @@ mod tests {
 		let serialized = field.serialize(Serializer.wrap()).unwrap();
 		assert_eq!(field, serialized);
 	}
+
+	#[test]
+	fn window() {
+		let field = Field::Window {
+			expr: Default::default(),
+			order: None,
+			alias: None,
+		};
+		let serialized = field.serialize(Serializer.wrap()).unwrap();
+		assert_eq!(field, serialized);
+	}
+
+	#[test]
+	fn window_with_order_and_alias() {
+		let field = Field::Window {
+			expr: Default::default(),
+			order: Some(Default::default()),
+			alias: Some(Default::default()),
+		};
+		let serialized = field.serialize(Serializer.wrap()).unwrap();
+		assert_eq!(field, serialized);
+	}
+
+	#[test]
+	fn filter() {
+		let field = Field::Filter {
+			expr: Default::default(),
+			cond: Cond(Value::Bool(true)),
+			alias: Some(Default::default()),
+		};
+		let serialized = field.serialize(Serializer.wrap()).unwrap();
+		assert_eq!(field, serialized);
+	}
 }
@@ -0,0 +1,60 @@
+use crate::err::Error;
+use crate::sql::value::serde::ser;
+use crate::sql::IdStrategy;
+use serde::ser::Error as _;
+use serde::ser::Impossible;
+
+pub(super) struct Serializer;
+
+impl ser::Serializer for Serializer {
+	type Ok = IdStrategy;
+	type Error = Error;
+
+	type SerializeSeq = Impossible<IdStrategy, Error>;
+	type SerializeTuple = Impossible<IdStrategy, Error>;
+	type SerializeTupleStruct = Impossible<IdStrategy, Error>;
+	type SerializeTupleVariant = Impossible<IdStrategy, Error>;
+	type SerializeMap = Impossible<IdStrategy, Error>;
+	type SerializeStruct = Impossible<IdStrategy, Error>;
+	type SerializeStructVariant = Impossible<IdStrategy, Error>;
+
+	const EXPECTED: &'static str = "an enum `IdStrategy`";
+
+	#[inline]
+	fn serialize_unit_variant(
+		self,
+		name: &'static str,
+		_variant_index: u32,
+		variant: &'static str,
+	) -> Result<Self::Ok, Error> {
+		match variant {
+			"Rand" => Ok(IdStrategy::Rand),
+			"Ulid" => Ok(IdStrategy::Ulid),
+			"Uuid" => Ok(IdStrategy::Uuid),
+			"Snowflake" => Ok(IdStrategy::Snowflake),
+			"Increment" => Ok(IdStrategy::Increment),
+			variant => Err(Error::custom(format!("unknown variant `{name}::{variant}`"))),
+		}
+	}
+}
+
+#[cfg(test)]
+mod tests {
+	use super::*;
+	use ser::Serializer as _;
+	use serde::Serialize;
+
+	#[test]
+	fn all_variants() {
+		for strategy in [
+			IdStrategy::Rand,
+			IdStrategy::Ulid,
+			IdStrategy::Uuid,
+			IdStrategy::Snowflake,
+			IdStrategy::Increment,
+		] {
+			let serialized = strategy.serialize(Serializer.wrap()).unwrap();
+			assert_eq!(strategy, serialized);
+		}
+	}
+}
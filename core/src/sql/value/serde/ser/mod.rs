@@ -25,6 +25,7 @@ mod geometry;
 mod graph;
 mod group;
 mod id;
+mod id_strategy;
 mod ident;
 mod idiom;
 mod index;
@@ -42,6 +42,7 @@ pub(super) struct SerializeOrder {
 	collate: Option<bool>,
 	numeric: Option<bool>,
 	direction: Option<bool>,
+	nulls: Option<bool>,
 }
 
 impl serde::ser::SerializeStruct for SerializeOrder {
@@ -68,6 +69,9 @@ impl serde::ser::SerializeStruct for SerializeOrder {
 			"direction" => {
 				self.direction = Some(value.serialize(ser::primitive::bool::Serializer.wrap())?);
 			}
+			"nulls" => {
+				self.nulls = value.serialize(ser::primitive::opt::bool::Serializer.wrap())?;
+			}
 			key => {
 				return Err(Error::custom(format!("unexpected field `Order::{key}`")));
 			}
@@ -84,6 +88,7 @@ impl serde::ser::SerializeStruct for SerializeOrder {
 					collate,
 					numeric,
 					direction,
+					nulls: self.nulls,
 				})
 			}
 			_ => Err(Error::custom("`Order` missing required field(s)")),
@@ -102,4 +107,14 @@ mod tests {
 		let serialized = order.serialize(Serializer.wrap()).unwrap();
 		assert_eq!(order, serialized);
 	}
+
+	#[test]
+	fn nulls() {
+		let order = Order {
+			nulls: Some(false),
+			..Default::default()
+		};
+		let serialized = order.serialize(Serializer.wrap()).unwrap();
+		assert_eq!(order, serialized);
+	}
 }
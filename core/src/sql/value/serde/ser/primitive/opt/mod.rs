@@ -1,2 +1,3 @@
+pub mod bool;
 pub mod u32;
 pub mod u64;
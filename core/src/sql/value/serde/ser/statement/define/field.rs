@@ -49,6 +49,7 @@ pub struct SerializeDefineFieldStatement {
 	readonly: bool,
 	value: Option<Value>,
 	assert: Option<Value>,
+	assert_message: Option<Value>,
 	default: Option<Value>,
 	permissions: Permissions,
 	comment: Option<Strand>,
@@ -85,6 +86,9 @@ impl serde::ser::SerializeStruct for SerializeDefineFieldStatement {
 			"assert" => {
 				self.assert = value.serialize(ser::value::opt::Serializer.wrap())?;
 			}
+			"assert_message" => {
+				self.assert_message = value.serialize(ser::value::opt::Serializer.wrap())?;
+			}
 			"default" => {
 				self.default = value.serialize(ser::value::opt::Serializer.wrap())?;
 			}
@@ -115,6 +119,7 @@ impl serde::ser::SerializeStruct for SerializeDefineFieldStatement {
 			readonly: self.readonly,
 			value: self.value,
 			assert: self.assert,
+			assert_message: self.assert_message,
 			default: self.default,
 			permissions: self.permissions,
 			comment: self.comment,
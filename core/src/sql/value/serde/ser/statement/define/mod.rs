@@ -8,6 +8,7 @@ mod index;
 mod namespace;
 mod param;
 mod table;
+mod task;
 mod user;
 
 use crate::err::Error;
@@ -61,6 +62,7 @@ impl ser::Serializer for Serializer {
 			"Access" => Ok(DefineStatement::Access(value.serialize(access::Serializer.wrap())?)),
 			"Param" => Ok(DefineStatement::Param(value.serialize(param::Serializer.wrap())?)),
 			"Table" => Ok(DefineStatement::Table(value.serialize(table::Serializer.wrap())?)),
+			"Task" => Ok(DefineStatement::Task(value.serialize(task::Serializer.wrap())?)),
 			"Event" => Ok(DefineStatement::Event(value.serialize(event::Serializer.wrap())?)),
 			"Field" => Ok(DefineStatement::Field(value.serialize(field::Serializer.wrap())?)),
 			"Index" => Ok(DefineStatement::Index(value.serialize(index::Serializer.wrap())?)),
@@ -126,6 +128,13 @@ mod tests {
 		assert_eq!(stmt, serialized);
 	}
 
+	#[test]
+	fn task() {
+		let stmt = DefineStatement::Task(Default::default());
+		let serialized = stmt.serialize(Serializer.wrap()).unwrap();
+		assert_eq!(stmt, serialized);
+	}
+
 	#[test]
 	fn event() {
 		let stmt = DefineStatement::Event(Default::default());
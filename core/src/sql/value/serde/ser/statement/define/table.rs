@@ -2,6 +2,7 @@ use crate::err::Error;
 use crate::sql::changefeed::ChangeFeed;
 use crate::sql::statements::DefineTableStatement;
 use crate::sql::value::serde::ser;
+use crate::sql::IdStrategy;
 use crate::sql::Ident;
 use crate::sql::Permissions;
 use crate::sql::Strand;
@@ -52,6 +53,9 @@ pub struct SerializeDefineTableStatement {
 	comment: Option<Strand>,
 	if_not_exists: bool,
 	kind: TableType,
+	versioned: bool,
+	id_strategy: IdStrategy,
+	soft_delete: bool,
 }
 
 impl serde::ser::SerializeStruct for SerializeDefineTableStatement {
@@ -93,6 +97,15 @@ impl serde::ser::SerializeStruct for SerializeDefineTableStatement {
 			"if_not_exists" => {
 				self.if_not_exists = value.serialize(ser::primitive::bool::Serializer.wrap())?
 			}
+			"versioned" => {
+				self.versioned = value.serialize(ser::primitive::bool::Serializer.wrap())?
+			}
+			"id_strategy" => {
+				self.id_strategy = value.serialize(ser::id_strategy::Serializer.wrap())?
+			}
+			"soft_delete" => {
+				self.soft_delete = value.serialize(ser::primitive::bool::Serializer.wrap())?
+			}
 			key => {
 				return Err(Error::custom(format!(
 					"unexpected field `DefineTableStatement::{key}`"
@@ -114,6 +127,9 @@ impl serde::ser::SerializeStruct for SerializeDefineTableStatement {
 			comment: self.comment,
 			kind: self.kind,
 			if_not_exists: self.if_not_exists,
+			versioned: self.versioned,
+			id_strategy: self.id_strategy,
+			soft_delete: self.soft_delete,
 		})
 	}
 }
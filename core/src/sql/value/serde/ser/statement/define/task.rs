@@ -0,0 +1,104 @@
+use crate::err::Error;
+use crate::sql::statements::DefineTaskStatement;
+use crate::sql::value::serde::ser;
+use crate::sql::Duration;
+use crate::sql::Ident;
+use crate::sql::Strand;
+use crate::sql::Values;
+use ser::Serializer as _;
+use serde::ser::Error as _;
+use serde::ser::Impossible;
+use serde::ser::Serialize;
+
+#[non_exhaustive]
+pub struct Serializer;
+
+impl ser::Serializer for Serializer {
+	type Ok = DefineTaskStatement;
+	type Error = Error;
+
+	type SerializeSeq = Impossible<DefineTaskStatement, Error>;
+	type SerializeTuple = Impossible<DefineTaskStatement, Error>;
+	type SerializeTupleStruct = Impossible<DefineTaskStatement, Error>;
+	type SerializeTupleVariant = Impossible<DefineTaskStatement, Error>;
+	type SerializeMap = Impossible<DefineTaskStatement, Error>;
+	type SerializeStruct = SerializeDefineTaskStatement;
+	type SerializeStructVariant = Impossible<DefineTaskStatement, Error>;
+
+	const EXPECTED: &'static str = "a struct `DefineTaskStatement`";
+
+	#[inline]
+	fn serialize_struct(
+		self,
+		_name: &'static str,
+		_len: usize,
+	) -> Result<Self::SerializeStruct, Error> {
+		Ok(SerializeDefineTaskStatement::default())
+	}
+}
+
+#[derive(Default)]
+#[non_exhaustive]
+pub struct SerializeDefineTaskStatement {
+	name: Ident,
+	every: Duration,
+	then: Values,
+	comment: Option<Strand>,
+	if_not_exists: bool,
+}
+
+impl serde::ser::SerializeStruct for SerializeDefineTaskStatement {
+	type Ok = DefineTaskStatement;
+	type Error = Error;
+
+	fn serialize_field<T>(&mut self, key: &'static str, value: &T) -> Result<(), Error>
+	where
+		T: ?Sized + Serialize,
+	{
+		match key {
+			"name" => {
+				self.name = Ident(value.serialize(ser::string::Serializer.wrap())?);
+			}
+			"every" => {
+				self.every = Duration(value.serialize(ser::duration::Serializer.wrap())?);
+			}
+			"then" => {
+				self.then = Values(value.serialize(ser::value::vec::Serializer.wrap())?);
+			}
+			"comment" => {
+				self.comment = value.serialize(ser::strand::opt::Serializer.wrap())?;
+			}
+			"if_not_exists" => {
+				self.if_not_exists = value.serialize(ser::primitive::bool::Serializer.wrap())?
+			}
+			key => {
+				return Err(Error::custom(format!(
+					"unexpected field `DefineTaskStatement::{key}`"
+				)));
+			}
+		}
+		Ok(())
+	}
+
+	fn end(self) -> Result<Self::Ok, Error> {
+		Ok(DefineTaskStatement {
+			name: self.name,
+			every: self.every,
+			then: self.then,
+			comment: self.comment,
+			if_not_exists: self.if_not_exists,
+		})
+	}
+}
+
+#[cfg(test)]
+mod tests {
+	use super::*;
+
+	#[test]
+	fn default() {
+		let stmt = DefineTaskStatement::default();
+		let value: DefineTaskStatement = stmt.serialize(Serializer.wrap()).unwrap();
+		assert_eq!(value, stmt);
+	}
+}
@@ -0,0 +1,97 @@
+use crate::err::Error;
+use crate::sql::statements::ExplainStatement;
+use crate::sql::statements::SelectStatement;
+use crate::sql::value::serde::ser;
+use ser::Serializer as _;
+use serde::ser::Error as _;
+use serde::ser::Impossible;
+use serde::ser::Serialize;
+
+#[non_exhaustive]
+pub struct Serializer;
+
+impl ser::Serializer for Serializer {
+	type Ok = ExplainStatement;
+	type Error = Error;
+
+	type SerializeSeq = Impossible<ExplainStatement, Error>;
+	type SerializeTuple = Impossible<ExplainStatement, Error>;
+	type SerializeTupleStruct = Impossible<ExplainStatement, Error>;
+	type SerializeTupleVariant = Impossible<ExplainStatement, Error>;
+	type SerializeMap = Impossible<ExplainStatement, Error>;
+	type SerializeStruct = SerializeExplainStatement;
+	type SerializeStructVariant = Impossible<ExplainStatement, Error>;
+
+	const EXPECTED: &'static str = "a struct `ExplainStatement`";
+
+	#[inline]
+	fn serialize_struct(
+		self,
+		_name: &'static str,
+		_len: usize,
+	) -> Result<Self::SerializeStruct, Error> {
+		Ok(SerializeExplainStatement::default())
+	}
+}
+
+#[derive(Default)]
+#[non_exhaustive]
+pub struct SerializeExplainStatement {
+	full: Option<bool>,
+	what: Option<SelectStatement>,
+}
+
+impl serde::ser::SerializeStruct for SerializeExplainStatement {
+	type Ok = ExplainStatement;
+	type Error = Error;
+
+	fn serialize_field<T>(&mut self, key: &'static str, value: &T) -> Result<(), Error>
+	where
+		T: ?Sized + Serialize,
+	{
+		match key {
+			"full" => {
+				self.full = Some(value.serialize(ser::primitive::bool::Serializer.wrap())?);
+			}
+			"what" => {
+				self.what = Some(value.serialize(super::select::Serializer.wrap())?);
+			}
+			key => {
+				return Err(Error::custom(format!("unexpected field `ExplainStatement::{key}`")));
+			}
+		}
+		Ok(())
+	}
+
+	fn end(self) -> Result<Self::Ok, Error> {
+		match (self.full, self.what) {
+			(Some(full), Some(what)) => Ok(ExplainStatement {
+				full,
+				what,
+			}),
+			_ => Err(Error::custom("`ExplainStatement` missing required value(s)")),
+		}
+	}
+}
+
+#[cfg(test)]
+mod tests {
+	use super::*;
+
+	#[test]
+	fn default() {
+		let stmt = ExplainStatement::default();
+		let value: ExplainStatement = stmt.serialize(Serializer.wrap()).unwrap();
+		assert_eq!(value, stmt);
+	}
+
+	#[test]
+	fn with_full() {
+		let stmt = ExplainStatement {
+			full: true,
+			..Default::default()
+		};
+		let value: ExplainStatement = stmt.serialize(Serializer.wrap()).unwrap();
+		assert_eq!(value, stmt);
+	}
+}
@@ -1,5 +1,6 @@
 use crate::err::Error;
 use crate::sql::statements::IfelseStatement;
+use crate::sql::statements::SetStatement;
 use crate::sql::value::serde::ser;
 use crate::sql::Value;
 use ser::Serializer as _;
@@ -41,6 +42,8 @@ type ValueValueTuple = (Value, Value);
 pub struct SerializeIfelseStatement {
 	exprs: Vec<ValueValueTuple>,
 	close: Option<Value>,
+	parallel: bool,
+	lets: Vec<Vec<SetStatement>>,
 }
 
 impl serde::ser::SerializeStruct for SerializeIfelseStatement {
@@ -58,6 +61,12 @@ impl serde::ser::SerializeStruct for SerializeIfelseStatement {
 			"close" => {
 				self.close = value.serialize(ser::value::opt::Serializer.wrap())?;
 			}
+			"parallel" => {
+				self.parallel = value.serialize(ser::primitive::bool::Serializer.wrap())?;
+			}
+			"lets" => {
+				self.lets = value.serialize(SetStatementVecVecSerializer.wrap())?;
+			}
 			key => {
 				return Err(Error::custom(format!("unexpected field `IfelseStatement::{key}`")));
 			}
@@ -69,6 +78,8 @@ impl serde::ser::SerializeStruct for SerializeIfelseStatement {
 		Ok(IfelseStatement {
 			exprs: self.exprs,
 			close: self.close,
+			parallel: self.parallel,
+			lets: self.lets,
 		})
 	}
 }
@@ -176,6 +187,86 @@ impl serde::ser::SerializeTuple for SerializeValueValueTuple {
 	}
 }
 
+struct SetStatementVecVecSerializer;
+
+impl ser::Serializer for SetStatementVecVecSerializer {
+	type Ok = Vec<Vec<SetStatement>>;
+	type Error = Error;
+
+	type SerializeSeq = SerializeSetStatementVecVec;
+	type SerializeTuple = Impossible<Vec<Vec<SetStatement>>, Error>;
+	type SerializeTupleStruct = Impossible<Vec<Vec<SetStatement>>, Error>;
+	type SerializeTupleVariant = Impossible<Vec<Vec<SetStatement>>, Error>;
+	type SerializeMap = Impossible<Vec<Vec<SetStatement>>, Error>;
+	type SerializeStruct = Impossible<Vec<Vec<SetStatement>>, Error>;
+	type SerializeStructVariant = Impossible<Vec<Vec<SetStatement>>, Error>;
+
+	const EXPECTED: &'static str = "a `Vec<Vec<SetStatement>>`";
+
+	fn serialize_seq(self, len: Option<usize>) -> Result<Self::SerializeSeq, Error> {
+		Ok(SerializeSetStatementVecVec(Vec::with_capacity(len.unwrap_or_default())))
+	}
+}
+
+struct SerializeSetStatementVecVec(Vec<Vec<SetStatement>>);
+
+impl serde::ser::SerializeSeq for SerializeSetStatementVecVec {
+	type Ok = Vec<Vec<SetStatement>>;
+	type Error = Error;
+
+	fn serialize_element<T>(&mut self, value: &T) -> Result<(), Self::Error>
+	where
+		T: Serialize + ?Sized,
+	{
+		self.0.push(value.serialize(SetStatementVecSerializer.wrap())?);
+		Ok(())
+	}
+
+	fn end(self) -> Result<Self::Ok, Self::Error> {
+		Ok(self.0)
+	}
+}
+
+struct SetStatementVecSerializer;
+
+impl ser::Serializer for SetStatementVecSerializer {
+	type Ok = Vec<SetStatement>;
+	type Error = Error;
+
+	type SerializeSeq = SerializeSetStatementVec;
+	type SerializeTuple = Impossible<Vec<SetStatement>, Error>;
+	type SerializeTupleStruct = Impossible<Vec<SetStatement>, Error>;
+	type SerializeTupleVariant = Impossible<Vec<SetStatement>, Error>;
+	type SerializeMap = Impossible<Vec<SetStatement>, Error>;
+	type SerializeStruct = Impossible<Vec<SetStatement>, Error>;
+	type SerializeStructVariant = Impossible<Vec<SetStatement>, Error>;
+
+	const EXPECTED: &'static str = "a `Vec<SetStatement>`";
+
+	fn serialize_seq(self, len: Option<usize>) -> Result<Self::SerializeSeq, Error> {
+		Ok(SerializeSetStatementVec(Vec::with_capacity(len.unwrap_or_default())))
+	}
+}
+
+struct SerializeSetStatementVec(Vec<SetStatement>);
+
+impl serde::ser::SerializeSeq for SerializeSetStatementVec {
+	type Ok = Vec<SetStatement>;
+	type Error = Error;
+
+	fn serialize_element<T>(&mut self, value: &T) -> Result<(), Self::Error>
+	where
+		T: Serialize + ?Sized,
+	{
+		self.0.push(value.serialize(ser::statement::set::Serializer.wrap())?);
+		Ok(())
+	}
+
+	fn end(self) -> Result<Self::Ok, Self::Error> {
+		Ok(self.0)
+	}
+}
+
 #[cfg(test)]
 mod tests {
 	use super::*;
@@ -197,6 +288,31 @@ mod tests {
 		assert_eq!(value, stmt);
 	}
 
+	#[test]
+	fn with_parallel() {
+		let stmt = IfelseStatement {
+			parallel: true,
+			..Default::default()
+		};
+		let value: IfelseStatement = stmt.serialize(Serializer.wrap()).unwrap();
+		assert_eq!(value, stmt);
+	}
+
+	#[test]
+	fn with_lets() {
+		let stmt = IfelseStatement {
+			exprs: vec![(Default::default(), Default::default())],
+			lets: vec![vec![SetStatement {
+				name: "x".to_owned(),
+				what: Default::default(),
+				kind: None,
+			}]],
+			..Default::default()
+		};
+		let value: IfelseStatement = stmt.serialize(Serializer.wrap()).unwrap();
+		assert_eq!(value, stmt);
+	}
+
 	#[test]
 	fn with_close() {
 		let stmt = IfelseStatement {
@@ -7,6 +7,7 @@ pub mod r#continue;
 pub mod create;
 pub mod define;
 pub mod delete;
+pub mod explain;
 pub mod ifelse;
 pub mod info;
 pub mod insert;
@@ -17,6 +18,8 @@ pub mod output;
 pub mod rebuild;
 pub mod relate;
 pub mod remove;
+pub mod restore;
+pub mod savepoint;
 pub mod select;
 pub mod set;
 pub mod show;
@@ -71,6 +74,7 @@ impl ser::Serializer for Serializer {
 			"Create" => Ok(Statement::Create(value.serialize(create::Serializer.wrap())?)),
 			"Define" => Ok(Statement::Define(value.serialize(define::Serializer.wrap())?)),
 			"Delete" => Ok(Statement::Delete(value.serialize(delete::Serializer.wrap())?)),
+			"Explain" => Ok(Statement::Explain(value.serialize(explain::Serializer.wrap())?)),
 			"Ifelse" => Ok(Statement::Ifelse(value.serialize(ifelse::Serializer.wrap())?)),
 			"Info" => Ok(Statement::Info(value.serialize(info::Serializer.wrap())?)),
 			"Insert" => Ok(Statement::Insert(value.serialize(insert::Serializer.wrap())?)),
@@ -81,6 +85,8 @@ impl ser::Serializer for Serializer {
 			"Rebuild" => Ok(Statement::Rebuild(value.serialize(rebuild::Serializer.wrap())?)),
 			"Relate" => Ok(Statement::Relate(value.serialize(relate::Serializer.wrap())?)),
 			"Remove" => Ok(Statement::Remove(value.serialize(remove::Serializer.wrap())?)),
+			"Restore" => Ok(Statement::Restore(value.serialize(restore::Serializer.wrap())?)),
+			"Savepoint" => Ok(Statement::Savepoint(value.serialize(savepoint::Serializer.wrap())?)),
 			"Select" => Ok(Statement::Select(value.serialize(select::Serializer.wrap())?)),
 			"Set" => Ok(Statement::Set(value.serialize(set::Serializer.wrap())?)),
 			"Show" => Ok(Statement::Show(value.serialize(show::Serializer.wrap())?)),
@@ -156,6 +162,13 @@ mod tests {
 		assert_eq!(statement, serialized);
 	}
 
+	#[test]
+	fn explain() {
+		let statement = Statement::Explain(Default::default());
+		let serialized = statement.serialize(Serializer.wrap()).unwrap();
+		assert_eq!(statement, serialized);
+	}
+
 	#[test]
 	fn ifelse() {
 		let statement = Statement::Ifelse(Default::default());
@@ -219,6 +232,13 @@ mod tests {
 		assert_eq!(statement, serialized);
 	}
 
+	#[test]
+	fn restore() {
+		let statement = Statement::Restore(Default::default());
+		let serialized = statement.serialize(Serializer.wrap()).unwrap();
+		assert_eq!(statement, serialized);
+	}
+
 	#[test]
 	fn select() {
 		let statement = Statement::Select(Default::default());
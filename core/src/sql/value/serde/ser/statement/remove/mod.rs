@@ -8,6 +8,7 @@ mod index;
 mod namespace;
 mod param;
 mod table;
+mod task;
 mod user;
 
 use crate::err::Error;
@@ -61,6 +62,7 @@ impl ser::Serializer for Serializer {
 			"Access" => Ok(RemoveStatement::Access(value.serialize(access::Serializer.wrap())?)),
 			"Param" => Ok(RemoveStatement::Param(value.serialize(param::Serializer.wrap())?)),
 			"Table" => Ok(RemoveStatement::Table(value.serialize(table::Serializer.wrap())?)),
+			"Task" => Ok(RemoveStatement::Task(value.serialize(task::Serializer.wrap())?)),
 			"Event" => Ok(RemoveStatement::Event(value.serialize(event::Serializer.wrap())?)),
 			"Field" => Ok(RemoveStatement::Field(value.serialize(field::Serializer.wrap())?)),
 			"Index" => Ok(RemoveStatement::Index(value.serialize(index::Serializer.wrap())?)),
@@ -126,6 +128,13 @@ mod tests {
 		assert_eq!(stmt, serialized);
 	}
 
+	#[test]
+	fn task() {
+		let stmt = RemoveStatement::Task(Default::default());
+		let serialized = stmt.serialize(Serializer.wrap()).unwrap();
+		assert_eq!(stmt, serialized);
+	}
+
 	#[test]
 	fn event() {
 		let stmt = RemoveStatement::Event(Default::default());
@@ -0,0 +1,86 @@
+use crate::err::Error;
+use crate::sql::statements::RemoveTaskStatement;
+use crate::sql::value::serde::ser;
+use crate::sql::Ident;
+use ser::Serializer as _;
+use serde::ser::Error as _;
+use serde::ser::Impossible;
+use serde::ser::Serialize;
+
+#[non_exhaustive]
+pub struct Serializer;
+
+impl ser::Serializer for Serializer {
+	type Ok = RemoveTaskStatement;
+	type Error = Error;
+
+	type SerializeSeq = Impossible<RemoveTaskStatement, Error>;
+	type SerializeTuple = Impossible<RemoveTaskStatement, Error>;
+	type SerializeTupleStruct = Impossible<RemoveTaskStatement, Error>;
+	type SerializeTupleVariant = Impossible<RemoveTaskStatement, Error>;
+	type SerializeMap = Impossible<RemoveTaskStatement, Error>;
+	type SerializeStruct = SerializeRemoveTaskStatement;
+	type SerializeStructVariant = Impossible<RemoveTaskStatement, Error>;
+
+	const EXPECTED: &'static str = "a struct `RemoveTaskStatement`";
+
+	#[inline]
+	fn serialize_struct(
+		self,
+		_name: &'static str,
+		_len: usize,
+	) -> Result<Self::SerializeStruct, Error> {
+		Ok(SerializeRemoveTaskStatement::default())
+	}
+}
+
+#[derive(Default)]
+#[non_exhaustive]
+pub struct SerializeRemoveTaskStatement {
+	name: Ident,
+	if_exists: bool,
+}
+
+impl serde::ser::SerializeStruct for SerializeRemoveTaskStatement {
+	type Ok = RemoveTaskStatement;
+	type Error = Error;
+
+	fn serialize_field<T>(&mut self, key: &'static str, value: &T) -> Result<(), Error>
+	where
+		T: ?Sized + Serialize,
+	{
+		match key {
+			"name" => {
+				self.name = Ident(value.serialize(ser::string::Serializer.wrap())?);
+			}
+			"if_exists" => {
+				self.if_exists = value.serialize(ser::primitive::bool::Serializer.wrap())?;
+			}
+			key => {
+				return Err(Error::custom(format!(
+					"unexpected field `RemoveTaskStatement::{key}`"
+				)));
+			}
+		}
+		Ok(())
+	}
+
+	fn end(self) -> Result<Self::Ok, Error> {
+		Ok(RemoveTaskStatement {
+			name: self.name,
+			if_exists: self.if_exists,
+		})
+	}
+}
+
+#[cfg(test)]
+mod tests {
+	use super::*;
+
+	#[test]
+	fn default() {
+		let stmt = RemoveTaskStatement::default();
+		let value: RemoveTaskStatement = stmt.serialize(Serializer.wrap()).unwrap();
+		assert_eq!(value, stmt);
+	}
+}
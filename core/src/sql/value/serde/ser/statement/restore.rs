@@ -0,0 +1,140 @@
+use crate::err::Error;
+use crate::sql::statements::RestoreStatement;
+use crate::sql::value::serde::ser;
+use crate::sql::Cond;
+use crate::sql::Output;
+use crate::sql::Timeout;
+use crate::sql::Values;
+use ser::Serializer as _;
+use serde::ser::Error as _;
+use serde::ser::Impossible;
+use serde::ser::Serialize;
+
+#[non_exhaustive]
+pub struct Serializer;
+
+impl ser::Serializer for Serializer {
+	type Ok = RestoreStatement;
+	type Error = Error;
+
+	type SerializeSeq = Impossible<RestoreStatement, Error>;
+	type SerializeTuple = Impossible<RestoreStatement, Error>;
+	type SerializeTupleStruct = Impossible<RestoreStatement, Error>;
+	type SerializeTupleVariant = Impossible<RestoreStatement, Error>;
+	type SerializeMap = Impossible<RestoreStatement, Error>;
+	type SerializeStruct = SerializeRestoreStatement;
+	type SerializeStructVariant = Impossible<RestoreStatement, Error>;
+
+	const EXPECTED: &'static str = "a struct `RestoreStatement`";
+
+	#[inline]
+	fn serialize_struct(
+		self,
+		_name: &'static str,
+		_len: usize,
+	) -> Result<Self::SerializeStruct, Error> {
+		Ok(SerializeRestoreStatement::default())
+	}
+}
+
+#[derive(Default)]
+#[non_exhaustive]
+pub struct SerializeRestoreStatement {
+	only: Option<bool>,
+	what: Option<Values>,
+	cond: Option<Cond>,
+	output: Option<Output>,
+	timeout: Option<Timeout>,
+	parallel: Option<bool>,
+}
+
+impl serde::ser::SerializeStruct for SerializeRestoreStatement {
+	type Ok = RestoreStatement;
+	type Error = Error;
+
+	fn serialize_field<T>(&mut self, key: &'static str, value: &T) -> Result<(), Error>
+	where
+		T: ?Sized + Serialize,
+	{
+		match key {
+			"only" => {
+				self.only = Some(value.serialize(ser::primitive::bool::Serializer.wrap())?);
+			}
+			"what" => {
+				self.what = Some(Values(value.serialize(ser::value::vec::Serializer.wrap())?));
+			}
+			"cond" => {
+				self.cond = value.serialize(ser::cond::opt::Serializer.wrap())?;
+			}
+			"output" => {
+				self.output = value.serialize(ser::output::opt::Serializer.wrap())?;
+			}
+			"timeout" => {
+				self.timeout = value.serialize(ser::timeout::opt::Serializer.wrap())?;
+			}
+			"parallel" => {
+				self.parallel = Some(value.serialize(ser::primitive::bool::Serializer.wrap())?);
+			}
+			key => {
+				return Err(Error::custom(format!("unexpected field `RestoreStatement::{key}`")));
+			}
+		}
+		Ok(())
+	}
+
+	fn end(self) -> Result<Self::Ok, Error> {
+		match (self.what, self.parallel) {
+			(Some(what), Some(parallel)) => Ok(RestoreStatement {
+				only: self.only.is_some_and(|v| v),
+				what,
+				parallel,
+				cond: self.cond,
+				output: self.output,
+				timeout: self.timeout,
+			}),
+			_ => Err(Error::custom("`RestoreStatement` missing required value(s)")),
+		}
+	}
+}
+
+#[cfg(test)]
+mod tests {
+	use super::*;
+
+	#[test]
+	fn default() {
+		let stmt = RestoreStatement::default();
+		let value: RestoreStatement = stmt.serialize(Serializer.wrap()).unwrap();
+		assert_eq!(value, stmt);
+	}
+
+	#[test]
+	fn with_cond() {
+		let stmt = RestoreStatement {
+			cond: Some(Default::default()),
+			..Default::default()
+		};
+		let value: RestoreStatement = stmt.serialize(Serializer.wrap()).unwrap();
+		assert_eq!(value, stmt);
+	}
+
+	#[test]
+	fn with_output() {
+		let stmt = RestoreStatement {
+			output: Some(Default::default()),
+			..Default::default()
+		};
+		let value: RestoreStatement = stmt.serialize(Serializer.wrap()).unwrap();
+		assert_eq!(value, stmt);
+	}
+
+	#[test]
+	fn with_timeout() {
+		let stmt = RestoreStatement {
+			timeout: Some(Default::default()),
+			..Default::default()
+		};
+		let value: RestoreStatement = stmt.serialize(Serializer.wrap()).unwrap();
+		assert_eq!(value, stmt);
+	}
+}
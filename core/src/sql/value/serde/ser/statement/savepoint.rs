@@ -0,0 +1,73 @@
+use crate::err::Error;
+use crate::sql::statements::SavepointStatement;
+use crate::sql::value::serde::ser;
+use crate::sql::Ident;
+use ser::Serializer as _;
+use serde::ser::Error as _;
+use serde::ser::Impossible;
+use serde::ser::Serialize;
+
+pub(super) struct Serializer;
+
+impl ser::Serializer for Serializer {
+	type Ok = SavepointStatement;
+	type Error = Error;
+
+	type SerializeSeq = Impossible<SavepointStatement, Error>;
+	type SerializeTuple = Impossible<SavepointStatement, Error>;
+	type SerializeTupleStruct = Impossible<SavepointStatement, Error>;
+	type SerializeTupleVariant = Impossible<SavepointStatement, Error>;
+	type SerializeMap = Impossible<SavepointStatement, Error>;
+	type SerializeStruct = Impossible<SavepointStatement, Error>;
+	type SerializeStructVariant = Impossible<SavepointStatement, Error>;
+
+	const EXPECTED: &'static str = "an enum `SavepointStatement`";
+
+	#[inline]
+	fn serialize_newtype_variant<T>(
+		self,
+		name: &'static str,
+		_variant_index: u32,
+		variant: &'static str,
+		value: &T,
+	) -> Result<Self::Ok, Error>
+	where
+		T: ?Sized + Serialize,
+	{
+		let ident = || value.serialize(ser::string::Serializer.wrap()).map(Ident);
+		match variant {
+			"Define" => Ok(SavepointStatement::Define(ident()?)),
+			"Release" => Ok(SavepointStatement::Release(ident()?)),
+			"Rollback" => Ok(SavepointStatement::Rollback(ident()?)),
+			variant => {
+				Err(Error::custom(format!("unexpected newtype variant `{name}::{variant}`")))
+			}
+		}
+	}
+}
+
+#[cfg(test)]
+mod tests {
+	use super::*;
+
+	#[test]
+	fn define() {
+		let stmt = SavepointStatement::Define(Default::default());
+		let serialized = stmt.serialize(Serializer.wrap()).unwrap();
+		assert_eq!(stmt, serialized);
+	}
+
+	#[test]
+	fn release() {
+		let stmt = SavepointStatement::Release(Default::default());
+		let serialized = stmt.serialize(Serializer.wrap()).unwrap();
+		assert_eq!(stmt, serialized);
+	}
+
+	#[test]
+	fn rollback() {
+		let stmt = SavepointStatement::Rollback(Default::default());
+		let serialized = stmt.serialize(Serializer.wrap()).unwrap();
+		assert_eq!(stmt, serialized);
+	}
+}
@@ -4,6 +4,7 @@ use crate::sql::statements::SelectStatement;
 use crate::sql::value::serde::ser;
 use crate::sql::with::With;
 use crate::sql::Cond;
+use crate::sql::Duration;
 use crate::sql::Fetchs;
 use crate::sql::Fields;
 use crate::sql::Groups;
@@ -13,6 +14,7 @@ use crate::sql::Orders;
 use crate::sql::Splits;
 use crate::sql::Start;
 use crate::sql::Timeout;
+use crate::sql::Value;
 use crate::sql::Values;
 use crate::sql::Version;
 use ser::Serializer as _;
@@ -58,12 +60,17 @@ pub struct SerializeSelectStatement {
 	cond: Option<Cond>,
 	split: Option<Splits>,
 	group: Option<Groups>,
+	having: Option<Cond>,
+	distinct: Option<Idioms>,
 	order: Option<Orders>,
 	limit: Option<Limit>,
 	start: Option<Start>,
+	cursor: Option<Value>,
 	fetch: Option<Fetchs>,
 	version: Option<Version>,
 	timeout: Option<Timeout>,
+	cache: Option<Duration>,
+	deleted: bool,
 	parallel: Option<bool>,
 	explain: Option<Explain>,
 	tempfiles: Option<bool>,
@@ -102,6 +109,13 @@ impl serde::ser::SerializeStruct for SerializeSelectStatement {
 			"group" => {
 				self.group = value.serialize(ser::group::vec::opt::Serializer.wrap())?.map(Groups);
 			}
+			"having" => {
+				self.having = value.serialize(ser::cond::opt::Serializer.wrap())?;
+			}
+			"distinct" => {
+				self.distinct =
+					value.serialize(ser::idiom::vec::opt::Serializer.wrap())?.map(Idioms);
+			}
 			"order" => {
 				self.order = value.serialize(ser::order::vec::opt::Serializer.wrap())?.map(Orders);
 			}
@@ -111,6 +125,9 @@ impl serde::ser::SerializeStruct for SerializeSelectStatement {
 			"start" => {
 				self.start = value.serialize(ser::start::opt::Serializer.wrap())?;
 			}
+			"cursor" => {
+				self.cursor = value.serialize(ser::value::opt::Serializer.wrap())?;
+			}
 			"fetch" => {
 				self.fetch = value.serialize(ser::fetch::vec::opt::Serializer.wrap())?.map(Fetchs);
 			}
@@ -120,6 +137,13 @@ impl serde::ser::SerializeStruct for SerializeSelectStatement {
 			"timeout" => {
 				self.timeout = value.serialize(ser::timeout::opt::Serializer.wrap())?;
 			}
+			"cache" => {
+				self.cache =
+					value.serialize(ser::duration::opt::Serializer.wrap())?.map(Into::into);
+			}
+			"deleted" => {
+				self.deleted = value.serialize(ser::primitive::bool::Serializer.wrap())?;
+			}
 			"parallel" => {
 				self.parallel = Some(value.serialize(ser::primitive::bool::Serializer.wrap())?);
 			}
@@ -150,12 +174,17 @@ impl serde::ser::SerializeStruct for SerializeSelectStatement {
 				cond: self.cond,
 				split: self.split,
 				group: self.group,
+				having: self.having,
+				distinct: self.distinct,
 				order: self.order,
 				limit: self.limit,
 				start: self.start,
+				cursor: self.cursor,
 				fetch: self.fetch,
 				version: self.version,
 				timeout: self.timeout,
+				cache: self.cache,
+				deleted: self.deleted,
 			}),
 			_ => Err(Error::custom("`SelectStatement` missing required field(s)")),
 		}
@@ -203,6 +232,26 @@ mod tests {
 		assert_eq!(value, stmt);
 	}
 
+	#[test]
+	fn with_having() {
+		let stmt = SelectStatement {
+			having: Some(Default::default()),
+			..Default::default()
+		};
+		let value: SelectStatement = stmt.serialize(Serializer.wrap()).unwrap();
+		assert_eq!(value, stmt);
+	}
+
+	#[test]
+	fn with_distinct() {
+		let stmt = SelectStatement {
+			distinct: Some(Default::default()),
+			..Default::default()
+		};
+		let value: SelectStatement = stmt.serialize(Serializer.wrap()).unwrap();
+		assert_eq!(value, stmt);
+	}
+
 	#[test]
 	fn with_order() {
 		let stmt = SelectStatement {
@@ -263,6 +312,16 @@ mod tests {
 		assert_eq!(value, stmt);
 	}
 
+	#[test]
+	fn with_cache() {
+		let stmt = SelectStatement {
+			cache: Some(Default::default()),
+			..Default::default()
+		};
+		let value: SelectStatement = stmt.serialize(Serializer.wrap()).unwrap();
+		assert_eq!(value, stmt);
+	}
+
 	#[test]
 	fn with_explain() {
 		let stmt = SelectStatement {
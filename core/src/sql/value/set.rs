@@ -5,6 +5,7 @@ use crate::exe::try_join_all_buffered;
 use crate::sql::part::Next;
 use crate::sql::part::Part;
 use crate::sql::value::Value;
+use crate::sql::{Expression, Operator};
 use reblessive::tree::Stk;
 
 impl Value {
@@ -39,6 +40,15 @@ impl Value {
 						Some(v) if v.is_some() => {
 							stk.run(|stk| v.set(stk, ctx, opt, path.next(), val)).await
 						}
+						// Only create a filtered array if specified
+						_ if matches!(path.next().first(), Some(Part::Where(_))) => {
+							if opt.create_paths {
+								let mut arr = Value::from(Vec::<Value>::new());
+								stk.run(|stk| arr.set(stk, ctx, opt, path.next(), val)).await?;
+								v.insert(f.to_raw(), arr);
+							}
+							Ok(())
+						}
 						_ => {
 							let mut obj = Value::base();
 							stk.run(|stk| obj.set(stk, ctx, opt, path.next(), val)).await?;
@@ -99,39 +109,48 @@ impl Value {
 						Some(v) => stk.run(|stk| v.set(stk, ctx, opt, path.next(), val)).await,
 						None => Ok(()),
 					},
-					Part::Where(w) => match path.next().first() {
-						Some(Part::Index(_)) => {
-							let mut a = Vec::new();
-							let mut p = Vec::new();
-							// Store the elements and positions to update
-							for (i, o) in v.iter_mut().enumerate() {
-								let cur = o.into();
-								if w.compute(stk, ctx, opt, Some(&cur)).await?.is_truthy() {
-									a.push(o.clone());
-									p.push(i);
-								}
+					Part::Where(w) => {
+						let mut p = Vec::new();
+						// Store the positions of the elements to update
+						for (i, o) in v.iter().enumerate() {
+							let cur = o.into();
+							if w.compute(stk, ctx, opt, Some(&cur)).await?.is_truthy() {
+								p.push(i);
 							}
-							// Convert the matched elements array to a value
-							let mut a = Value::from(a);
-							// Set the new value on the matches elements
-							stk.run(|stk| a.set(stk, ctx, opt, path.next(), val.clone())).await?;
-							// Push the new values into the original array
-							for (i, p) in p.into_iter().enumerate() {
-								v[p] = a.pick(&[Part::Index(i.into())]);
+						}
+						// Add a matching element if there are no matches
+						if p.is_empty() && opt.create_paths {
+							let mut obj = Value::base();
+							if stk.run(|stk| template(stk, ctx, opt, w, &mut obj)).await? {
+								p.push(v.len());
+								v.push(obj);
 							}
-							Ok(())
 						}
-						_ => {
-							let path = path.next();
-							for v in v.iter_mut() {
-								let cur = v.into();
-								if w.compute(stk, ctx, opt, Some(&cur)).await?.is_truthy() {
-									stk.run(|stk| v.set(stk, ctx, opt, path, val.clone())).await?;
+						match path.next().first() {
+							Some(Part::Index(_) | Part::First | Part::Last) => {
+								// Convert the matched elements array to a value
+								let mut a = Value::from(
+									p.iter().map(|&i| v[i].clone()).collect::<Vec<_>>(),
+								);
+								// Set the new value on the matches elements
+								stk.run(|stk| a.set(stk, ctx, opt, path.next(), val.clone()))
+									.await?;
+								// Push the new values into the original array
+								for (i, p) in p.into_iter().enumerate() {
+									v[p] = a.pick(&[Part::Index(i.into())]);
 								}
+								Ok(())
+							}
+							_ => {
+								let path = path.next();
+								for i in p {
+									stk.run(|stk| v[i].set(stk, ctx, opt, path, val.clone()))
+										.await?;
+								}
+								Ok(())
 							}
-							Ok(())
 						}
-					},
+					}
 					Part::Value(x) => match x.compute(stk, ctx, opt, None).await? {
 						Value::Number(i) => match v.get_mut(i.to_usize()) {
 							Some(v) => stk.run(|stk| v.set(stk, ctx, opt, path.next(), val)).await,
@@ -173,6 +192,41 @@ impl Value {
 	}
 }
 
+/// Add the fields which a `[WHERE]` condition compares for equality to a
+/// new array element, so that the new element matches the condition.
+///
+/// Returns false if the condition does anything other than compare fields
+/// for equality, as a matching element can not be created in that case.
+async fn template(
+	stk: &mut Stk,
+	ctx: &Context<'_>,
+	opt: &Options,
+	cond: &Value,
+	obj: &mut Value,
+) -> Result<bool, Error> {
+	match cond {
+		Value::Expression(e) => match e.as_ref() {
+			Expression::Binary {
+				l,
+				o: Operator::And,
+				r,
+			} => Ok(stk.run(|stk| template(stk, ctx, opt, l, obj)).await?
+				&& stk.run(|stk| template(stk, ctx, opt, r, obj)).await?),
+			Expression::Binary {
+				l: Value::Idiom(i),
+				o: Operator::Equal | Operator::Exact,
+				r,
+			} if i.iter().all(|p| matches!(p, Part::Field(_))) => {
+				let val = r.compute(stk, ctx, opt, None).await?;
+				obj.put(i, val);
+				Ok(true)
+			}
+			_ => Ok(false),
+		},
+		_ => Ok(false),
+	}
+}
+
 #[cfg(test)]
 mod tests {
 
@@ -356,4 +410,76 @@ mod tests {
 		stack.enter(|stk| val.set(stk, &ctx, &opt, &idi, Value::from(21))).finish().await.unwrap();
 		assert_eq!(res, val);
 	}
+
+	#[tokio::test]
+	async fn set_array_where_fields_array_last_field() {
+		let (ctx, opt) = mock().await;
+		let idi = Idiom::parse("test.something[WHERE age > 30][$].age");
+		let mut val =
+			Value::parse("{ test: { something: [{ age: 34 }, { age: 36 }, { age: 20 }] } }");
+		let res = Value::parse("{ test: { something: [{ age: 34 }, { age: 21 }, { age: 20 }] } }");
+		let mut stack = reblessive::TreeStack::new();
+		stack.enter(|stk| val.set(stk, &ctx, &opt, &idi, Value::from(21))).finish().await.unwrap();
+		assert_eq!(res, val);
+	}
+
+	#[tokio::test]
+	async fn set_array_where_no_match() {
+		let (ctx, opt) = mock().await;
+		let idi = Idiom::parse("test.something[WHERE sku = 'c'].qty");
+		let mut val = Value::parse("{ test: { something: [{ sku: 'a', qty: 1 }] } }");
+		let res = Value::parse("{ test: { something: [{ sku: 'a', qty: 1 }] } }");
+		let mut stack = reblessive::TreeStack::new();
+		stack.enter(|stk| val.set(stk, &ctx, &opt, &idi, Value::from(5))).finish().await.unwrap();
+		assert_eq!(res, val);
+	}
+
+	#[tokio::test]
+	async fn set_array_where_missing() {
+		let (ctx, opt) = mock().await;
+		let idi = Idiom::parse("test.something[WHERE sku = 'c'].qty");
+		let mut val = Value::parse("{ test: { other: null } }");
+		let res = Value::parse("{ test: { other: null } }");
+		let mut stack = reblessive::TreeStack::new();
+		stack.enter(|stk| val.set(stk, &ctx, &opt, &idi, Value::from(5))).finish().await.unwrap();
+		assert_eq!(res, val);
+	}
+
+	#[tokio::test]
+	async fn set_array_where_create() {
+		let (ctx, opt) = mock().await;
+		let opt = opt.with_create_paths(true);
+		let idi = Idiom::parse("test.something[WHERE sku = 'c' AND size.name == 'L'].qty");
+		let mut val = Value::parse("{ test: { something: [{ sku: 'a', qty: 1 }] } }");
+		let res = Value::parse(
+			"{ test: { something: [{ sku: 'a', qty: 1 }, { sku: 'c', size: { name: 'L' }, qty: 5 }] } }",
+		);
+		let mut stack = reblessive::TreeStack::new();
+		stack.enter(|stk| val.set(stk, &ctx, &opt, &idi, Value::from(5))).finish().await.unwrap();
+		assert_eq!(res, val);
+	}
+
+	#[tokio::test]
+	async fn set_array_where_create_missing() {
+		let (ctx, opt) = mock().await;
+		let opt = opt.with_create_paths(true);
+		let idi = Idiom::parse("test.something[WHERE sku = 'c'].qty");
+		let mut val = Value::parse("{ test: { other: null } }");
+		let res = Value::parse("{ test: { other: null, something: [{ sku: 'c', qty: 5 }] } }");
+		let mut stack = reblessive::TreeStack::new();
+		stack.enter(|stk| val.set(stk, &ctx, &opt, &idi, Value::from(5))).finish().await.unwrap();
+		assert_eq!(res, val);
+	}
+
+	#[tokio::test]
+	async fn set_array_where_create_not_equality() {
+		let (ctx, opt) = mock().await;
+		let opt = opt.with_create_paths(true);
+		let idi = Idiom::parse("test.something[WHERE qty > 10].qty");
+		let mut val = Value::parse("{ test: { something: [{ sku: 'a', qty: 1 }] } }");
+		let res = Value::parse("{ test: { something: [{ sku: 'a', qty: 1 }] } }");
+		let mut stack = reblessive::TreeStack::new();
+		stack.enter(|stk| val.set(stk, &ctx, &opt, &idi, Value::from(5))).finish().await.unwrap();
+		assert_eq!(res, val);
+	}
 }
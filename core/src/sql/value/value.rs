@@ -11,6 +11,7 @@ use crate::sql::{
 	fmt::{Fmt, Pretty},
 	id::{Gen, Id},
 	model::Model,
+	param::ParamCollector,
 	Array, Block, Bytes, Cast, Constant, Datetime, Duration, Edges, Expression, Function, Future,
 	Geometry, Idiom, Kind, Mock, Number, Object, Operation, Param, Part, Query, Range, Regex,
 	Strand, Subquery, Table, Tables, Thing, Uuid,
@@ -27,7 +28,7 @@ use std::cmp::Ordering;
 use std::collections::BTreeMap;
 use std::collections::HashMap;
 use std::fmt::{self, Display, Formatter, Write};
-use std::ops::Deref;
+use std::ops::{Bound, Deref};
 
 pub(crate) const TOKEN: &str = "$surrealdb::private::sql::Value";
 
@@ -859,6 +860,12 @@ impl Value {
 	}
 
 	/// Check if this Value is truthy
+	///
+	/// NONE and NULL are never truthy. Booleans are truthy when true, numbers when non-zero,
+	/// and strings when non-empty and not equal to 'false'. Arrays, objects and bytes are
+	/// truthy when non-empty, durations when non-zero in length, and datetimes when not equal
+	/// to the Unix epoch. Uuids, record ids and geometries are always truthy. Any value which
+	/// has not yet been computed is not truthy.
 	pub fn is_truthy(&self) -> bool {
 		match self {
 			Value::Bool(v) => *v,
@@ -867,10 +874,11 @@ impl Value {
 			Value::Geometry(_) => true,
 			Value::Array(v) => !v.is_empty(),
 			Value::Object(v) => !v.is_empty(),
+			Value::Bytes(v) => !v.is_empty(),
 			Value::Strand(v) => !v.is_empty() && !v.eq_ignore_ascii_case("false"),
 			Value::Number(v) => v.is_truthy(),
 			Value::Duration(v) => v.as_nanos() > 0,
-			Value::Datetime(v) => v.timestamp() > 0,
+			Value::Datetime(v) => v.timestamp() != 0 || v.timestamp_subsec_nanos() != 0,
 			_ => false,
 		}
 	}
@@ -2344,11 +2352,24 @@ impl Value {
 
 	/// Converts this Value into a JSONPatch path
 	pub(crate) fn jsonpath(&self) -> Idiom {
-		self.to_raw_string()
-			.as_str()
-			.trim_start_matches('/')
-			.split(&['.', '/'][..])
-			.map(Part::from)
+		let path = self.to_raw_string();
+		// An empty path points to the whole document
+		if path.is_empty() {
+			return Idiom::default();
+		}
+		path.strip_prefix('/')
+			.unwrap_or(&path)
+			.split('/')
+			.map(|t| match t {
+				// The position after the last element of an array
+				"-" => Part::Last,
+				// An array index has no sign or leading zeros
+				t if t == "0" || (!t.starts_with('0') && t.bytes().all(|b| b.is_ascii_digit())) => {
+					Part::from(t)
+				}
+				// Any other token is an object key, with `/` and `~` escaped
+				t => Part::from(t.replace("~1", "/").replace("~0", "~")),
+			})
 			.collect::<Vec<Part>>()
 			.into()
 	}
@@ -2633,6 +2654,165 @@ impl Value {
 			_ => false,
 		}
 	}
+	/// Collect the document fields which this value refers to
+	pub(crate) fn fields<'a>(&'a self, out: &mut Vec<&'a Idiom>) {
+		match self {
+			Value::Idiom(v) => {
+				if let Some(Part::Field(_)) = v.first() {
+					out.push(v);
+				}
+			}
+			Value::Array(v) => v.iter().for_each(|v| v.fields(out)),
+			Value::Object(v) => v.values().for_each(|v| v.fields(out)),
+			Value::Function(v) => v.args().iter().for_each(|v| v.fields(out)),
+			Value::Cast(v) => v.1.fields(out),
+			Value::Expression(v) => match v.as_ref() {
+				Expression::Unary {
+					v,
+					..
+				} => v.fields(out),
+				Expression::Binary {
+					l,
+					r,
+					..
+				} => {
+					l.fields(out);
+					r.fields(out);
+				}
+			},
+			Value::Subquery(v) => match v.as_ref() {
+				Subquery::Value(v) => v.fields(out),
+				Subquery::Ifelse(v) => {
+					for (cond, then) in v.exprs.iter() {
+						cond.fields(out);
+						then.fields(out);
+					}
+					if let Some(v) = &v.close {
+						v.fields(out);
+					}
+				}
+				Subquery::Case(v) => {
+					if let Some(v) = &v.subject {
+						v.fields(out);
+					}
+					for (when, then) in v.exprs.iter() {
+						when.fields(out);
+						then.fields(out);
+					}
+					if let Some(v) = &v.close {
+						v.fields(out);
+					}
+				}
+				_ => (),
+			},
+			_ => (),
+		}
+	}
+	/// Collect the parameters which this value refers to, returning false
+	/// if the value contains a block, model, or statement which the
+	/// collector does not walk, and which could therefore refer to any
+	/// parameter
+	pub(crate) fn params<'a>(&'a self, out: &mut impl ParamCollector<'a>) -> bool {
+		match self {
+			Value::Param(v) => {
+				out.param(v);
+				true
+			}
+			Value::Idiom(v) => v.params(out),
+			Value::Array(v) => v.iter().all(|v| v.params(out)),
+			Value::Object(v) => v.values().all(|v| v.params(out)),
+			Value::Thing(v) => v.id.params(out),
+			Value::Range(v) => [&v.beg, &v.end].into_iter().all(|v| match v {
+				Bound::Included(v) | Bound::Excluded(v) => v.params(out),
+				Bound::Unbounded => true,
+			}),
+			Value::Function(v) => v.args().iter().all(|v| v.params(out)),
+			Value::Cast(v) => v.1.params(out),
+			Value::Expression(v) => match v.as_ref() {
+				Expression::Unary {
+					v,
+					..
+				} => v.params(out),
+				Expression::Binary {
+					l,
+					r,
+					..
+				} => l.params(out) && r.params(out),
+			},
+			Value::Subquery(v) => match v.as_ref() {
+				Subquery::Value(v) => v.params(out),
+				Subquery::Select(v) => v.params(out),
+				// LET bindings are scoped to their branch, so are left to the collector
+				Subquery::Ifelse(v) if v.lets.is_empty() => {
+					v.exprs.iter().all(|(cond, then)| cond.params(out) && then.params(out))
+						&& v.close.as_ref().map_or(true, |v| v.params(out))
+				}
+				Subquery::Case(v) => {
+					v.subject.as_ref().map_or(true, |v| v.params(out))
+						&& v.exprs.iter().all(|(when, then)| when.params(out) && then.params(out))
+						&& v.close.as_ref().map_or(true, |v| v.params(out))
+				}
+				_ => out.other(self),
+			},
+			Value::Block(_)
+			| Value::Edges(_)
+			| Value::Future(_)
+			| Value::Query(_)
+			| Value::Model(_) => out.other(self),
+			_ => true,
+		}
+	}
+	/// Check if this value only reads from the current document, returning
+	/// false if it could read from any other records, such as through a
+	/// subquery, graph traversal, record link, or custom function
+	pub(crate) fn reads_only_document(&self) -> bool {
+		match self {
+			Value::Idiom(v) => v.reads_only_document(),
+			Value::Array(v) => v.iter().all(Value::reads_only_document),
+			Value::Object(v) => v.values().all(Value::reads_only_document),
+			Value::Function(v) => match v.as_ref() {
+				// Some built-in functions read records from the datastore
+				Function::Normal(name, args) => {
+					!["record::", "search::", "http::"].iter().any(|p| name.starts_with(p))
+						&& args.iter().all(Value::reads_only_document)
+				}
+				_ => false,
+			},
+			Value::Cast(v) => v.1.reads_only_document(),
+			Value::Expression(v) => match v.as_ref() {
+				Expression::Unary {
+					v,
+					..
+				} => v.reads_only_document(),
+				Expression::Binary {
+					l,
+					r,
+					..
+				} => l.reads_only_document() && r.reads_only_document(),
+			},
+			Value::Subquery(v) => match v.as_ref() {
+				Subquery::Value(v) => v.reads_only_document(),
+				Subquery::Ifelse(v) => {
+					v.exprs.iter().all(|(c, t)| c.reads_only_document() && t.reads_only_document())
+						&& v.close.as_ref().map_or(true, Value::reads_only_document)
+				}
+				Subquery::Case(v) => {
+					v.subject.as_ref().map_or(true, Value::reads_only_document)
+						&& v.exprs
+							.iter()
+							.all(|(w, t)| w.reads_only_document() && t.reads_only_document())
+						&& v.close.as_ref().map_or(true, Value::reads_only_document)
+				}
+				_ => false,
+			},
+			Value::Block(_)
+			| Value::Edges(_)
+			| Value::Future(_)
+			| Value::Query(_)
+			| Value::Model(_) => false,
+			_ => true,
+		}
+	}
 	/// Process this type returning a computed simple Value
 	///
 	/// Is used recursively.
@@ -2849,6 +3029,46 @@ mod tests {
 		assert!(Value::from(Uuid::new()).is_truthy());
 	}
 
+	#[test]
+	fn convert_truthy_types() {
+		let epoch = DateTime::from_timestamp(0, 0).unwrap();
+		let cases = [
+			(Value::None, false),
+			(Value::Null, false),
+			(Value::Bool(false), false),
+			(Value::Bool(true), true),
+			(Value::from(0), false),
+			(Value::from(0.0), false),
+			(Value::from(Decimal::ZERO), false),
+			(Value::from(1), true),
+			(Value::from(""), false),
+			(Value::from("false"), false),
+			(Value::from("FALSE"), false),
+			(Value::from("text"), true),
+			(Value::from(Duration::from_nanos(0)), false),
+			(Value::from(Duration::from_nanos(1)), true),
+			(Value::from(epoch), false),
+			(Value::from(DateTime::from_timestamp(0, 1).unwrap()), true),
+			(Value::from(DateTime::from_timestamp(-1000, 0).unwrap()), true),
+			(Value::from(DateTime::from_timestamp(1000, 0).unwrap()), true),
+			(Value::from(Uuid::new()), true),
+			(Value::from(Array::new()), false),
+			(Value::from(vec![Value::None]), true),
+			(Value::from(Object::default()), false),
+			(Value::parse("{ a: NONE }"), true),
+			(Value::from(Bytes::from(vec![])), false),
+			(Value::from(Bytes::from(vec![0])), true),
+			(Value::from((0.0, 0.0)), true),
+			(Value::parse("person:test"), true),
+			(Value::from(Param::from("param")), false),
+			(Value::from(Idiom::from("field")), false),
+			(Value::from(Table::from("table")), false),
+		];
+		for (value, expected) in cases {
+			assert_eq!(value.is_truthy(), expected, "truthiness of {value}");
+		}
+	}
+
 	#[test]
 	fn convert_string() {
 		assert_eq!(String::from("NONE"), Value::None.as_string());
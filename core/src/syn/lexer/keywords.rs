@@ -15,6 +15,7 @@ pub static RESERVED_KEYWORD: phf::Set<UniCase<&'static str>> = phf_set! {
 	UniCase::ascii("CONTINUE"),
 	UniCase::ascii("CREATE"),
 	UniCase::ascii("DEFINE"),
+	UniCase::ascii("EXPLAIN"),
 	UniCase::ascii("FOR"),
 	UniCase::ascii("IF"),
 	UniCase::ascii("INFO"),
@@ -25,7 +26,9 @@ pub static RESERVED_KEYWORD: phf::Set<UniCase<&'static str>> = phf_set! {
 	UniCase::ascii("REBUILD"),
 	UniCase::ascii("RETURN"),
 	UniCase::ascii("RELATE"),
+	UniCase::ascii("RELEASE"),
 	UniCase::ascii("REMOVE"),
+	UniCase::ascii("ROLLBACK"),
 	UniCase::ascii("SELECT"),
 	UniCase::ascii("LET"),
 	UniCase::ascii("SHOW"),
@@ -73,8 +76,10 @@ pub(crate) static KEYWORDS: phf::Map<UniCase<&'static str>, TokenKind> = phf_map
 	UniCase::ascii("BM25") => TokenKind::Keyword(Keyword::Bm25),
 	UniCase::ascii("BREAK") => TokenKind::Keyword(Keyword::Break),
 	UniCase::ascii("BY") => TokenKind::Keyword(Keyword::By),
+	UniCase::ascii("CACHE") => TokenKind::Keyword(Keyword::Cache),
 	UniCase::ascii("CAMEL") => TokenKind::Keyword(Keyword::Camel),
 	UniCase::ascii("CANCEL") => TokenKind::Keyword(Keyword::Cancel),
+	UniCase::ascii("CASE") => TokenKind::Keyword(Keyword::Case),
 	UniCase::ascii("CHANGEFEED") => TokenKind::Keyword(Keyword::ChangeFeed),
 	UniCase::ascii("CHANGES") => TokenKind::Keyword(Keyword::Changes),
 	UniCase::ascii("CAPACITY") => TokenKind::Keyword(Keyword::Capacity),
@@ -89,11 +94,14 @@ pub(crate) static KEYWORDS: phf::Map<UniCase<&'static str>, TokenKind> = phf_map
 	UniCase::ascii("DEFAULT") => TokenKind::Keyword(Keyword::Default),
 	UniCase::ascii("DEFINE") => TokenKind::Keyword(Keyword::Define),
 	UniCase::ascii("DELETE") => TokenKind::Keyword(Keyword::Delete),
+	UniCase::ascii("DELETED") => TokenKind::Keyword(Keyword::Deleted),
+	UniCase::ascii("DEPTH") => TokenKind::Keyword(Keyword::Depth),
 	UniCase::ascii("DESCENDING") => TokenKind::Keyword(Keyword::Descending),
 	UniCase::ascii("DESC") => TokenKind::Keyword(Keyword::Descending),
 	UniCase::ascii("DIFF") => TokenKind::Keyword(Keyword::Diff),
 	UniCase::ascii("DIMENSION") => TokenKind::Keyword(Keyword::Dimension),
 	UniCase::ascii("DISTANCE") => TokenKind::Keyword(Keyword::Distance),
+	UniCase::ascii("DISTINCT") => TokenKind::Keyword(Keyword::Distinct),
 	UniCase::ascii("DIST") => TokenKind::Keyword(Keyword::Distance),
 	UniCase::ascii("DOC_IDS_CACHE") => TokenKind::Keyword(Keyword::DocIdsCache),
 	UniCase::ascii("DOC_IDS_ORDER") => TokenKind::Keyword(Keyword::DocIdsOrder),
@@ -104,6 +112,7 @@ pub(crate) static KEYWORDS: phf::Map<UniCase<&'static str>, TokenKind> = phf_map
 	UniCase::ascii("EDGENGRAM") => TokenKind::Keyword(Keyword::Edgengram),
 	UniCase::ascii("EFC") => TokenKind::Keyword(Keyword::Efc),
 	UniCase::ascii("EVENT") => TokenKind::Keyword(Keyword::Event),
+	UniCase::ascii("EVERY") => TokenKind::Keyword(Keyword::Every),
 	UniCase::ascii("ELSE") => TokenKind::Keyword(Keyword::Else),
 	UniCase::ascii("END") => TokenKind::Keyword(Keyword::End),
 	UniCase::ascii("EXISTS") => TokenKind::Keyword(Keyword::Exists),
@@ -114,7 +123,9 @@ pub(crate) static KEYWORDS: phf::Map<UniCase<&'static str>, TokenKind> = phf_map
 	UniCase::ascii("FIELD") => TokenKind::Keyword(Keyword::Field),
 	UniCase::ascii("FIELDS") => TokenKind::Keyword(Keyword::Fields),
 	UniCase::ascii("COLUMNS") => TokenKind::Keyword(Keyword::Fields),
+	UniCase::ascii("FILTER") => TokenKind::Keyword(Keyword::Filter),
 	UniCase::ascii("FILTERS") => TokenKind::Keyword(Keyword::Filters),
+	UniCase::ascii("FIRST") => TokenKind::Keyword(Keyword::First),
 	UniCase::ascii("FLEXIBLE") => TokenKind::Keyword(Keyword::Flexible),
 	UniCase::ascii("FLEXI") => TokenKind::Keyword(Keyword::Flexible),
 	UniCase::ascii("FLEX") => TokenKind::Keyword(Keyword::Flexible),
@@ -124,10 +135,13 @@ pub(crate) static KEYWORDS: phf::Map<UniCase<&'static str>, TokenKind> = phf_map
 	UniCase::ascii("FUNCTION") => TokenKind::Keyword(Keyword::Function),
 	UniCase::ascii("GRANT") => TokenKind::Keyword(Keyword::Grant),
 	UniCase::ascii("GROUP") => TokenKind::Keyword(Keyword::Group),
+	UniCase::ascii("HAVING") => TokenKind::Keyword(Keyword::Having),
 	UniCase::ascii("HIGHLIGHTS") => TokenKind::Keyword(Keyword::Highlights),
 	UniCase::ascii("HNSW") => TokenKind::Keyword(Keyword::Hnsw),
+	UniCase::ascii("ID") => TokenKind::Keyword(Keyword::Id),
 	UniCase::ascii("IGNORE") => TokenKind::Keyword(Keyword::Ignore),
 	UniCase::ascii("INCLUDE") => TokenKind::Keyword(Keyword::Include),
+	UniCase::ascii("INCREMENT") => TokenKind::Keyword(Keyword::Increment),
 	UniCase::ascii("INDEX") => TokenKind::Keyword(Keyword::Index),
 	UniCase::ascii("INFO") => TokenKind::Keyword(Keyword::Info),
 	UniCase::ascii("INSERT") => TokenKind::Keyword(Keyword::Insert),
@@ -140,6 +154,7 @@ pub(crate) static KEYWORDS: phf::Map<UniCase<&'static str>, TokenKind> = phf_map
 	UniCase::ascii("KEY") => TokenKind::Keyword(Keyword::Key),
 	UniCase::ascii("KEEP_PRUNED_CONNECTIONS") => TokenKind::Keyword(Keyword::KeepPrunedConnections),
 	UniCase::ascii("KILL") => TokenKind::Keyword(Keyword::Kill),
+	UniCase::ascii("LAST") => TokenKind::Keyword(Keyword::Last),
 	UniCase::ascii("LET") => TokenKind::Keyword(Keyword::Let),
 	UniCase::ascii("LIMIT") => TokenKind::Keyword(Keyword::Limit),
 	UniCase::ascii("LIVE") => TokenKind::Keyword(Keyword::Live),
@@ -159,12 +174,14 @@ pub(crate) static KEYWORDS: phf::Map<UniCase<&'static str>, TokenKind> = phf_map
 	UniCase::ascii("NOINDEX") => TokenKind::Keyword(Keyword::NoIndex),
 	UniCase::ascii("NONE") => TokenKind::Keyword(Keyword::None),
 	UniCase::ascii("NULL") => TokenKind::Keyword(Keyword::Null),
+	UniCase::ascii("NULLS") => TokenKind::Keyword(Keyword::Nulls),
 	UniCase::ascii("NUMERIC") => TokenKind::Keyword(Keyword::Numeric),
 	UniCase::ascii("OMIT") => TokenKind::Keyword(Keyword::Omit),
 	UniCase::ascii("ON") => TokenKind::Keyword(Keyword::On),
 	UniCase::ascii("ONLY") => TokenKind::Keyword(Keyword::Only),
 	UniCase::ascii("OPTION") => TokenKind::Keyword(Keyword::Option),
 	UniCase::ascii("ORDER") => TokenKind::Keyword(Keyword::Order),
+	UniCase::ascii("OVER") => TokenKind::Keyword(Keyword::Over),
 	UniCase::ascii("ORIGINAL") => TokenKind::Keyword(Keyword::Original),
 	UniCase::ascii("PARALLEL") => TokenKind::Keyword(Keyword::Parallel),
 	UniCase::ascii("PARAM") => TokenKind::Keyword(Keyword::Param),
@@ -178,13 +195,18 @@ pub(crate) static KEYWORDS: phf::Map<UniCase<&'static str>, TokenKind> = phf_map
 	UniCase::ascii("READONLY") => TokenKind::Keyword(Keyword::Readonly),
 	UniCase::ascii("RELATE") => TokenKind::Keyword(Keyword::Relate),
 	UniCase::ascii("RELATION") => TokenKind::Keyword(Keyword::Relation),
+	UniCase::ascii("RELEASE") => TokenKind::Keyword(Keyword::Release),
 	UniCase::ascii("REBUILD") => TokenKind::Keyword(Keyword::Rebuild),
+	UniCase::ascii("REFRESH") => TokenKind::Keyword(Keyword::Refresh),
 	UniCase::ascii("REMOVE") => TokenKind::Keyword(Keyword::Remove),
 	UniCase::ascii("REPLACE") => TokenKind::Keyword(Keyword::Replace),
+	UniCase::ascii("RESTORE") => TokenKind::Keyword(Keyword::Restore),
 	UniCase::ascii("RETURN") => TokenKind::Keyword(Keyword::Return),
 	UniCase::ascii("ROLES") => TokenKind::Keyword(Keyword::Roles),
+	UniCase::ascii("ROLLBACK") => TokenKind::Keyword(Keyword::Rollback),
 	UniCase::ascii("ROOT") => TokenKind::Keyword(Keyword::Root),
 	UniCase::ascii("KV") => TokenKind::Keyword(Keyword::Root),
+	UniCase::ascii("SAVEPOINT") => TokenKind::Keyword(Keyword::Savepoint),
 	UniCase::ascii("SCHEMAFULL") => TokenKind::Keyword(Keyword::Schemafull),
 	UniCase::ascii("SCHEMAFUL") => TokenKind::Keyword(Keyword::Schemafull),
 	UniCase::ascii("SCHEMALESS") => TokenKind::Keyword(Keyword::Schemaless),
@@ -200,11 +222,14 @@ pub(crate) static KEYWORDS: phf::Map<UniCase<&'static str>, TokenKind> = phf_map
 	UniCase::ascii("SINCE") => TokenKind::Keyword(Keyword::Since),
 	UniCase::ascii("SLEEP") => TokenKind::Keyword(Keyword::Sleep),
 	UniCase::ascii("SNOWBALL") => TokenKind::Keyword(Keyword::Snowball),
+	UniCase::ascii("SNOWFLAKE") => TokenKind::Keyword(Keyword::Snowflake),
+	UniCase::ascii("SOFT") => TokenKind::Keyword(Keyword::Soft),
 	UniCase::ascii("SPLIT") => TokenKind::Keyword(Keyword::Split),
 	UniCase::ascii("START") => TokenKind::Keyword(Keyword::Start),
 	UniCase::ascii("STRUCTURE") => TokenKind::Keyword(Keyword::Structure),
 	UniCase::ascii("TABLE") => TokenKind::Keyword(Keyword::Table),
 	UniCase::ascii("TB") => TokenKind::Keyword(Keyword::Table),
+	UniCase::ascii("TASK") => TokenKind::Keyword(Keyword::Task),
 	UniCase::ascii("TEMPFILES") => TokenKind::Keyword(Keyword::TempFiles),
 	UniCase::ascii("TERMS_CACHE") => TokenKind::Keyword(Keyword::TermsCache),
 	UniCase::ascii("TERMS_ORDER") => TokenKind::Keyword(Keyword::TermsOrder),
@@ -228,6 +253,7 @@ pub(crate) static KEYWORDS: phf::Map<UniCase<&'static str>, TokenKind> = phf_map
 	UniCase::ascii("VALUE") => TokenKind::Keyword(Keyword::Value),
 	UniCase::ascii("VALUES") => TokenKind::Keyword(Keyword::Values),
 	UniCase::ascii("VERSION") => TokenKind::Keyword(Keyword::Version),
+	UniCase::ascii("VERSIONED") => TokenKind::Keyword(Keyword::Versioned),
 	UniCase::ascii("VS") => TokenKind::Keyword(Keyword::Vs),
 	UniCase::ascii("WHEN") => TokenKind::Keyword(Keyword::When),
 	UniCase::ascii("WHERE") => TokenKind::Keyword(Keyword::Where),
@@ -237,6 +263,8 @@ pub(crate) static KEYWORDS: phf::Map<UniCase<&'static str>, TokenKind> = phf_map
 	UniCase::ascii("ANYINSIDE") => TokenKind::Keyword(Keyword::AnyInside),
 	UniCase::ascii("INSIDE") => TokenKind::Keyword(Keyword::Inside),
 	UniCase::ascii("INTERSECTS") => TokenKind::Keyword(Keyword::Intersects),
+	UniCase::ascii("MATCHES") => TokenKind::Keyword(Keyword::Matches),
+	UniCase::ascii("MATCH") => TokenKind::Keyword(Keyword::Matches),
 	UniCase::ascii("NONEINSIDE") => TokenKind::Keyword(Keyword::NoneInside),
 	UniCase::ascii("NOTINSIDE") => TokenKind::Keyword(Keyword::NotInside),
 	UniCase::ascii("OR") => TokenKind::Keyword(Keyword::OrKw),
@@ -91,6 +91,7 @@ pub(crate) static PATHS: phf::Map<UniCase<&'static str>, PathKind> = phf_map! {
 		UniCase::ascii("array::boolean_not") => PathKind::Function,
 		UniCase::ascii("array::boolean_or") => PathKind::Function,
 		UniCase::ascii("array::boolean_xor") => PathKind::Function,
+		UniCase::ascii("array::chunk") => PathKind::Function,
 		UniCase::ascii("array::clump") => PathKind::Function,
 		UniCase::ascii("array::combine") => PathKind::Function,
 		UniCase::ascii("array::complement") => PathKind::Function,
@@ -101,7 +102,9 @@ pub(crate) static PATHS: phf::Map<UniCase<&'static str>, PathKind> = phf_map! {
 		UniCase::ascii("array::find_index") => PathKind::Function,
 		UniCase::ascii("array::first") => PathKind::Function,
 		UniCase::ascii("array::flatten") => PathKind::Function,
+		UniCase::ascii("array::flatten_deep") => PathKind::Function,
 		UniCase::ascii("array::group") => PathKind::Function,
+		UniCase::ascii("array::group_by") => PathKind::Function,
 		UniCase::ascii("array::insert") => PathKind::Function,
 		UniCase::ascii("array::intersect") => PathKind::Function,
 		UniCase::ascii("array::join") => PathKind::Function,
@@ -123,6 +126,7 @@ pub(crate) static PATHS: phf::Map<UniCase<&'static str>, PathKind> = phf_map! {
 		UniCase::ascii("array::sort") => PathKind::Function,
 		UniCase::ascii("array::transpose") => PathKind::Function,
 		UniCase::ascii("array::union") => PathKind::Function,
+		UniCase::ascii("array::windows") => PathKind::Function,
 		UniCase::ascii("array::sort::asc") => PathKind::Function,
 		UniCase::ascii("array::sort::desc") => PathKind::Function,
 		//
@@ -166,6 +170,7 @@ pub(crate) static PATHS: phf::Map<UniCase<&'static str>, PathKind> = phf_map! {
 		UniCase::ascii("geo::area") => PathKind::Function,
 		UniCase::ascii("geo::bearing") => PathKind::Function,
 		UniCase::ascii("geo::centroid") => PathKind::Function,
+		UniCase::ascii("geo::contains") => PathKind::Function,
 		UniCase::ascii("geo::distance") => PathKind::Function,
 		UniCase::ascii("geo::hash::decode") => PathKind::Function,
 		UniCase::ascii("geo::hash::encode") => PathKind::Function,
@@ -254,15 +259,18 @@ pub(crate) static PATHS: phf::Map<UniCase<&'static str>, PathKind> = phf_map! {
 		UniCase::ascii("string::concat") => PathKind::Function,
 		UniCase::ascii("string::contains") => PathKind::Function,
 		UniCase::ascii("string::endsWith") => PathKind::Function,
+		UniCase::ascii("string::extract") => PathKind::Function,
 		UniCase::ascii("string::join") => PathKind::Function,
 		UniCase::ascii("string::len") => PathKind::Function,
 		UniCase::ascii("string::lowercase") => PathKind::Function,
 		UniCase::ascii("string::repeat") => PathKind::Function,
 		UniCase::ascii("string::replace") => PathKind::Function,
+		UniCase::ascii("string::replace_regex") => PathKind::Function,
 		UniCase::ascii("string::reverse") => PathKind::Function,
 		UniCase::ascii("string::slice") => PathKind::Function,
 		UniCase::ascii("string::slug") => PathKind::Function,
 		UniCase::ascii("string::split") => PathKind::Function,
+		UniCase::ascii("string::split_regex") => PathKind::Function,
 		UniCase::ascii("string::startsWith") => PathKind::Function,
 		UniCase::ascii("string::trim") => PathKind::Function,
 		UniCase::ascii("string::uppercase") => PathKind::Function,
@@ -299,6 +307,7 @@ pub(crate) static PATHS: phf::Map<UniCase<&'static str>, PathKind> = phf_map! {
 		UniCase::ascii("string::semver::set::patch") => PathKind::Function,
 		UniCase::ascii("string::similarity::fuzzy") => PathKind::Function,
 		UniCase::ascii("string::similarity::jaro") => PathKind::Function,
+		UniCase::ascii("string::similarity::jaro_winkler") => PathKind::Function,
 		UniCase::ascii("string::similarity::smithwaterman") => PathKind::Function,
 		UniCase::ascii("string::matches") => PathKind::Function,
 		//
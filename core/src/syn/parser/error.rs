@@ -24,6 +24,7 @@ pub enum MissingKind {
 	Group,
 	Split,
 	Order,
+	Distinct,
 }
 
 #[derive(Debug)]
@@ -296,6 +297,9 @@ impl ParseError {
 					MissingKind::Order => {
 						format!("Missing order idiom `{idiom}` in statement selection")
 					}
+					MissingKind::Distinct => {
+						format!("Missing distinct idiom `{idiom}` in statement selection")
+					}
 				};
 				let locations = Location::range_of_span(source, at);
 				let snippet_error = Snippet::from_source_location_range(source, locations, None);
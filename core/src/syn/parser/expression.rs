@@ -4,7 +4,8 @@ use reblessive::Stk;
 
 use super::mac::unexpected;
 use super::ParseError;
-use crate::sql::{value::TryNeg, Cast, Expression, Number, Operator, Value};
+use crate::sql::statements::IfelseStatement;
+use crate::sql::{value::TryNeg, Cast, Expression, Number, Operator, Subquery, Value};
 use crate::syn::token::Token;
 use crate::syn::{
 	parser::{mac::expected, ParseErrorKind, ParseResult, Parser},
@@ -66,6 +67,8 @@ impl Parser<'_> {
 		match token {
 			// assigment operators have the lowest binding power.
 			//t!("+=") | t!("-=") | t!("+?=") => Some((2, 1)),
+			// the ternary operator is right associative.
+			t!("?") => Some((2, 1)),
 			t!("||") | t!("OR") => Some((3, 4)),
 			t!("&&") | t!("AND") => Some((5, 6)),
 
@@ -80,7 +83,8 @@ impl Parser<'_> {
 			| t!("!~")
 			| t!("*~")
 			| t!("?~")
-			| t!("@") => Some((7, 8)),
+			| t!("@")
+			| t!("MATCHES") => Some((7, 8)),
 
 			t!("<")
 			| t!("<=")
@@ -262,6 +266,7 @@ impl Parser<'_> {
 					.transpose()?;
 				Operator::Matches(reference)
 			}
+			t!("MATCHES") => Operator::Matches(None),
 			t!("<=") => Operator::LessThanOrEqual,
 			t!("<") => Operator::LessThan,
 			t!(">=") => Operator::MoreThanOrEqual,
@@ -309,6 +314,48 @@ impl Parser<'_> {
 		})))
 	}
 
+	/// Parses the branches of a ternary `cond ? then : else` expression.
+	///
+	/// The expression is parsed into an if-else statement so that it is evaluated the same way.
+	async fn parse_ternary(
+		&mut self,
+		ctx: &mut Stk,
+		min_bp: u8,
+		cond: Value,
+	) -> ParseResult<Value> {
+		// Eat the `?`
+		self.pop_peek();
+		let old = self.ternary_then;
+		self.ternary_then = true;
+		let then = ctx.run(|ctx| self.pratt_parse_expr(ctx, 0)).await;
+		self.ternary_then = old;
+		let then = then?;
+		// A `:` directly after an identifier is parsed as part of a record id
+		if matches!(then, Value::Thing(_)) && self.peek_kind() != t!(":") {
+			unexpected!(self, self.peek_kind(), "whitespace before the `:` of a ternary expression" => "`a ? x:y` is parsed as `a ? (x:y)`, use `a ? x : y` instead");
+		}
+		expected!(self, t!(":"));
+		let close = ctx.run(|ctx| self.pratt_parse_expr(ctx, min_bp)).await?;
+		let mut stmt = IfelseStatement {
+			exprs: vec![(cond, then)],
+			close: None,
+			parallel: false,
+			lets: Vec::new(),
+		};
+		// Flatten chained ternaries into a single if-else statement
+		match close {
+			Value::Subquery(x) => match *x {
+				Subquery::Ifelse(x) if !x.parallel && x.lets.is_empty() => {
+					stmt.exprs.extend(x.exprs);
+					stmt.close = x.close;
+				}
+				x => stmt.close = Some(Value::Subquery(Box::new(x))),
+			},
+			x => stmt.close = Some(x),
+		}
+		Ok(Value::Subquery(Box::new(Subquery::Ifelse(stmt))))
+	}
+
 	/// The pratt parsing loop.
 	/// Parses expression according to binding power.
 	async fn pratt_parse_expr(&mut self, ctx: &mut Stk, min_bp: u8) -> ParseResult<Value> {
@@ -340,6 +387,11 @@ impl Parser<'_> {
 				break;
 			}
 
+			if token.kind == t!("?") {
+				lhs = self.parse_ternary(ctx, r_bp, lhs).await?;
+				continue;
+			}
+
 			lhs = self.parse_infix_op(ctx, r_bp, lhs).await?;
 		}
 
@@ -369,6 +421,70 @@ mod test {
 		assert_eq!(out, Value::from(Cast(Kind::String, 1.2345.into())));
 	}
 
+	#[test]
+	fn ternary() {
+		let sql = "a ? b : c";
+		let out = Value::parse(sql);
+		assert_eq!("IF a THEN b ELSE c END", format!("{}", out));
+		assert_eq!(out, Value::parse(&out.to_string()));
+	}
+
+	#[test]
+	fn ternary_right_associative() {
+		let sql = "a ? b : c ? d : e";
+		let out = Value::parse(sql);
+		assert_eq!("IF a THEN b ELSE IF c THEN d ELSE e END", format!("{}", out));
+		assert_eq!(out, Value::parse(&out.to_string()));
+	}
+
+	#[test]
+	fn ternary_nested_then() {
+		let sql = "a ? b ? c : d : e";
+		let out = Value::parse(sql);
+		assert_eq!("IF a THEN IF b THEN c ELSE d END ELSE e END", format!("{}", out));
+		assert_eq!(out, Value::parse(&out.to_string()));
+	}
+
+	#[test]
+	fn ternary_precedence() {
+		let sql = "a > 1 OR b ? c + 1 : d * 2";
+		let out = Value::parse(sql);
+		assert_eq!("IF a > 1 OR b THEN c + 1 ELSE d * 2 END", format!("{}", out));
+		assert_eq!(out, Value::parse(&out.to_string()));
+	}
+
+	#[test]
+	fn ternary_in_array_and_function() {
+		let sql = "[a ? 1 : 2, string::len(b ? 'x' : 'yy')]";
+		let out = Value::parse(sql);
+		assert_eq!(
+			"[IF a THEN 1 ELSE 2 END, string::len(IF b THEN 'x' ELSE 'yy' END)]",
+			format!("{}", out)
+		);
+		assert_eq!(out, Value::parse(&out.to_string()));
+	}
+
+	#[test]
+	fn ternary_record_id() {
+		let sql = "a ? person:one : person:two";
+		let out = Value::parse(sql);
+		assert_eq!("IF a THEN person:one ELSE person:two END", format!("{}", out));
+	}
+
+	#[test]
+	fn ternary_requires_whitespace_before_colon() {
+		// Values which can not be record ids don't need whitespace
+		let out = Value::parse("$a ? 1:2");
+		assert_eq!("IF $a THEN 1 ELSE 2 END", format!("{}", out));
+		let out = Value::parse("$a ? x :y");
+		assert_eq!("IF $a THEN x ELSE y END", format!("{}", out));
+		// The then branch is parsed as a record id without whitespace
+		let err = crate::syn::value("$a ? x:y").unwrap_err();
+		assert!(err.to_string().contains("whitespace before the `:`"), "{err}");
+		let out = Value::parse("$a ? x:y :z");
+		assert_eq!("IF $a THEN x:y ELSE z END", format!("{}", out));
+	}
+
 	#[test]
 	fn expression_statement() {
 		let sql = "true AND false";
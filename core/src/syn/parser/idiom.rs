@@ -1,11 +1,14 @@
 use reblessive::Stk;
 
 use crate::{
-	sql::{Dir, Edges, Field, Fields, Graph, Ident, Idiom, Part, Table, Tables, Value},
+	sql::{Cond, Dir, Edges, Field, Fields, Graph, Ident, Idiom, Part, Table, Tables, Value},
 	syn::token::{t, Span, TokenKind},
 };
 
-use super::{mac::unexpected, ParseError, ParseErrorKind, ParseResult, Parser};
+use super::{
+	mac::{expected, unexpected},
+	ParseError, ParseErrorKind, ParseResult, Parser,
+};
 
 impl Parser<'_> {
 	/// Parse fields of a selecting query: `foo, bar` in `SELECT foo, bar FROM baz`.
@@ -13,36 +16,30 @@ impl Parser<'_> {
 	/// # Parser State
 	/// Expects the next tokens to be of a field set.
 	pub async fn parse_fields(&mut self, ctx: &mut Stk) -> ParseResult<Fields> {
+		self.parse_field_list(ctx, false).await
+	}
+
+	/// Parse fields of a select statement, which can include window aggregates:
+	/// `count() OVER ()` in `SELECT *, count() OVER () FROM baz`, and filtered aggregates:
+	/// `count() FILTER (WHERE open)` in `SELECT count() FILTER (WHERE open) FROM baz GROUP ALL`.
+	///
+	/// # Parser State
+	/// Expects the next tokens to be of a field set.
+	pub(crate) async fn parse_select_fields(&mut self, ctx: &mut Stk) -> ParseResult<Fields> {
+		self.parse_field_list(ctx, true).await
+	}
+
+	async fn parse_field_list(&mut self, ctx: &mut Stk, aggregates: bool) -> ParseResult<Fields> {
 		if self.eat(t!("VALUE")) {
-			let expr = ctx.run(|ctx| self.parse_value_field(ctx)).await?;
-			let alias = if self.eat(t!("AS")) {
-				Some(self.parse_plain_idiom(ctx).await?)
-			} else {
-				None
-			};
-			Ok(Fields(
-				vec![Field::Single {
-					expr,
-					alias,
-				}],
-				true,
-			))
+			let field = self.parse_field(ctx, aggregates).await?;
+			Ok(Fields(vec![field], true))
 		} else {
 			let mut fields = Vec::new();
 			loop {
 				let field = if self.eat(t!("*")) {
 					Field::All
 				} else {
-					let expr = ctx.run(|ctx| self.parse_value_field(ctx)).await?;
-					let alias = if self.eat(t!("AS")) {
-						Some(self.parse_plain_idiom(ctx).await?)
-					} else {
-						None
-					};
-					Field::Single {
-						expr,
-						alias,
-					}
+					self.parse_field(ctx, aggregates).await?
 				};
 				fields.push(field);
 				if !self.eat(t!(",")) {
@@ -53,6 +50,60 @@ impl Parser<'_> {
 		}
 	}
 
+	/// Parses a single field with an optional alias, and an optional window or filter if allowed.
+	async fn parse_field(&mut self, ctx: &mut Stk, aggregates: bool) -> ParseResult<Field> {
+		let expr = ctx.run(|ctx| self.parse_value_field(ctx)).await?;
+		let is_aggregate = matches!(&expr, Value::Function(f) if f.is_aggregate());
+		let cond = if aggregates && self.eat(t!("FILTER")) {
+			if !is_aggregate {
+				unexpected!(self, t!("FILTER"), "an aggregate function" => "only aggregate functions can be filtered");
+			}
+			let start = expected!(self, t!("(")).span;
+			expected!(self, t!("WHERE"));
+			let cond = ctx.run(|ctx| self.parse_value_field(ctx)).await?;
+			self.expect_closing_delimiter(t!(")"), start)?;
+			Some(Cond(cond))
+		} else {
+			None
+		};
+		let order = if aggregates && self.eat(t!("OVER")) {
+			if !is_aggregate {
+				unexpected!(self, t!("OVER"), "an aggregate function" => "only aggregate functions can be computed over a window");
+			}
+			if cond.is_some() {
+				unexpected!(self, t!("OVER"), "no window" => "filtered aggregates can not be computed over a window");
+			}
+			let window = self.parse_window()?;
+			if window.is_some() && !matches!(&expr, Value::Function(f) if f.is_rolling()) {
+				unexpected!(self, t!("ORDER"), "a rolling aggregate function" => "only count, math::max, math::mean, math::min, math::sum, time::max, and time::min can be computed over an ordered window");
+			}
+			Some(window)
+		} else {
+			None
+		};
+		let alias = if self.eat(t!("AS")) {
+			Some(self.parse_plain_idiom(ctx).await?)
+		} else {
+			None
+		};
+		Ok(match (order, cond) {
+			(Some(order), _) => Field::Window {
+				expr,
+				order,
+				alias,
+			},
+			(None, Some(cond)) => Field::Filter {
+				expr,
+				cond,
+				alias,
+			},
+			(None, None) => Field::Single {
+				expr,
+				alias,
+			},
+		})
+	}
+
 	/// Parses a list of idioms seperated by a `,`
 	pub async fn parse_idiom_list(&mut self, ctx: &mut Stk) -> ParseResult<Vec<Idiom>> {
 		let mut res = vec![self.parse_plain_idiom(ctx).await?];
@@ -102,6 +153,10 @@ impl Parser<'_> {
 					let graph = stk.run(|stk| self.parse_graph(stk, Dir::In)).await?;
 					res.push(Part::Graph(graph))
 				}
+				t!("DEPTH") if Self::ends_with_graph(&res) => {
+					self.pop_peek();
+					self.parse_depth_part(&mut res)?;
+				}
 				t!("..") => {
 					return Err(ParseError::new(
 						ParseErrorKind::UnexpectedExplain {
@@ -163,6 +218,10 @@ impl Parser<'_> {
 						return Ok(x);
 					}
 				}
+				t!("DEPTH") if Self::ends_with_graph(&res) => {
+					self.pop_peek();
+					self.parse_depth_part(&mut res)?;
+				}
 				t!("..") => {
 					return Err(ParseError::new(
 						ParseErrorKind::UnexpectedExplain {
@@ -200,7 +259,8 @@ impl Parser<'_> {
 					};
 					let value = Value::Edges(Box::new(edge));
 
-					if !Self::continues_idiom(self.peek_kind()) {
+					let kind = self.peek_kind();
+					if !Self::continues_idiom(kind) && !matches!(kind, t!("DEPTH")) {
 						return Ok(Some(value));
 					}
 					res[0] = Part::Start(value);
@@ -215,6 +275,47 @@ impl Parser<'_> {
 		Ok(None)
 	}
 
+	/// Returns if the idiom parts end with a chain of graph parts which can be recursed
+	fn ends_with_graph(res: &[Part]) -> bool {
+		match res {
+			[Part::Start(Value::Edges(_))] => true,
+			[.., Part::Graph(_)] => true,
+			_ => false,
+		}
+	}
+
+	/// Parse the depth after a `DEPTH` keyword, replacing the trailing chain of graph parts
+	/// with a single recursive part: `->knows->person DEPTH 3`.
+	///
+	/// # Parser State
+	/// Expects the `DEPTH` keyword to already be eaten.
+	fn parse_depth_part(&mut self, res: &mut Vec<Part>) -> ParseResult<()> {
+		let depth = match self.peek_kind() {
+			TokenKind::Digits => Some(self.next_token_value::<u32>()?),
+			_ => None,
+		};
+		// Find where the chain of graph parts starts
+		let at = res.iter().rposition(|p| !matches!(p, Part::Graph(_))).map_or(0, |i| i + 1);
+		let mut parts = res.split_off(at);
+		// A `Thing -> Ident` start was rewritten as an edge, so split it back up
+		if let [Part::Start(Value::Edges(_))] = res.as_slice() {
+			if let Some(Part::Start(Value::Edges(e))) = res.pop() {
+				res.push(Part::Start(Value::Thing(e.from)));
+				parts.insert(
+					0,
+					Part::Graph(Graph {
+						dir: e.dir,
+						expr: Fields::all(),
+						what: e.what,
+						..Default::default()
+					}),
+				);
+			}
+		}
+		res.push(Part::Recurse(parts, depth));
+		Ok(())
+	}
+
 	/// Returns if the token kind could continua an idiom
 	pub fn continues_idiom(kind: TokenKind) -> bool {
 		matches!(kind, t!("->") | t!("<->") | t!("<-") | t!("[") | t!(".") | t!("..."))
@@ -734,6 +835,67 @@ mod tests {
 		);
 	}
 
+	#[test]
+	fn idiom_start_thing_recursive_traversal() {
+		let sql = "person:test->knows->person DEPTH 3 .name";
+		let out = Value::parse(sql);
+		assert_eq!("person:test->knows->person DEPTH 3 .name", format!("{}", out));
+		assert_eq!(
+			out,
+			Value::from(Idiom(vec![
+				Part::Start(Thing::from(("person", "test")).into()),
+				Part::Recurse(
+					vec![
+						Part::Graph(Graph {
+							dir: Dir::Out,
+							expr: Fields::all(),
+							what: Table::from("knows").into(),
+							..Default::default()
+						}),
+						Part::Graph(Graph {
+							dir: Dir::Out,
+							expr: Fields::all(),
+							what: Table::from("person").into(),
+							..Default::default()
+						}),
+					],
+					Some(3)
+				),
+				Part::from("name"),
+			]))
+		);
+	}
+
+	#[test]
+	fn idiom_recursive_traversal_default_depth() {
+		let sql = "friend->knows->person DEPTH";
+		let out = Value::parse(sql);
+		assert_eq!("friend->knows->person DEPTH", format!("{}", out));
+		assert_eq!(
+			out,
+			Value::from(Idiom(vec![
+				Part::from("friend"),
+				Part::Recurse(
+					vec![
+						Part::Graph(Graph {
+							dir: Dir::Out,
+							expr: Fields::all(),
+							what: Table::from("knows").into(),
+							..Default::default()
+						}),
+						Part::Graph(Graph {
+							dir: Dir::Out,
+							expr: Fields::all(),
+							what: Table::from("person").into(),
+							..Default::default()
+						}),
+					],
+					None
+				),
+			]))
+		);
+	}
+
 	#[test]
 	fn part_all() {
 		let sql = "{}[*]";
@@ -114,6 +114,7 @@ pub struct Parser<'a> {
 	last_span: Span,
 	token_buffer: TokenBuffer<4>,
 	table_as_field: bool,
+	ternary_then: bool,
 	legacy_strands: bool,
 	flexible_record_id: bool,
 	object_recursion: usize,
@@ -128,6 +129,7 @@ impl<'a> Parser<'a> {
 			last_span: Span::empty(),
 			token_buffer: TokenBuffer::new(),
 			table_as_field: false,
+			ternary_then: false,
 			legacy_strands: false,
 			flexible_record_id: true,
 			object_recursion: 100,
@@ -174,6 +176,7 @@ impl<'a> Parser<'a> {
 		self.last_span = Span::empty();
 		self.token_buffer.clear();
 		self.table_as_field = false;
+		self.ternary_then = false;
 		self.lexer.reset();
 	}
 
@@ -186,6 +189,7 @@ impl<'a> Parser<'a> {
 			legacy_strands: self.legacy_strands,
 			flexible_record_id: self.flexible_record_id,
 			table_as_field: false,
+			ternary_then: false,
 			object_recursion: self.object_recursion,
 			query_recursion: self.query_recursion,
 		}
@@ -232,6 +232,13 @@ impl Parser<'_> {
 					Value::Subquery(Box::new(Subquery::Ifelse(stmt)))
 				})
 			}
+			t!("CASE") if self.peek_starts_case() => {
+				enter_query_recursion!(this = self => {
+					this.pop_peek();
+					let stmt = ctx.run(|ctx| this.parse_case_stmt(ctx)).await?;
+					Value::Subquery(Box::new(Subquery::Case(stmt)))
+				})
+			}
 			t!("(") => {
 				self.pop_peek();
 				self.parse_inner_subquery_or_coordinate(ctx, token.span).await?
@@ -260,7 +267,20 @@ impl Parser<'_> {
 			_ => {
 				self.glue()?;
 
-				match self.peek_token_at(1).kind {
+				// Within the then branch of a ternary, a `:` after whitespace ends the branch
+				// instead of starting a record id.
+				let next = if self.ternary_then
+					&& self.peek_whitespace_token_at(1).kind == TokenKind::WhiteSpace
+				{
+					match self.peek_token_at(2).kind {
+						t!(":") => TokenKind::WhiteSpace,
+						x => x,
+					}
+				} else {
+					self.peek_token_at(1).kind
+				};
+
+				match next {
 					t!("::") | t!("(") => {
 						self.pop_peek();
 						self.parse_builtin(ctx, token.span).await?
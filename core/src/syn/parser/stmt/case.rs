@@ -0,0 +1,95 @@
+use reblessive::Stk;
+
+use crate::{
+	sql::statements::CaseStatement,
+	syn::{
+		parser::{
+			mac::{expected, unexpected},
+			ParseResult, Parser,
+		},
+		token::{t, TokenKind},
+	},
+};
+
+impl Parser<'_> {
+	/// Returns if the `CASE` keyword which is about to be parsed starts a case expression.
+	///
+	/// `CASE` is not a reserved keyword, so it can still be used as an identifier, such as a
+	/// field named `case`. It only starts a case expression when it is followed by `WHEN`, or
+	/// by a token which starts the value being compared.
+	pub fn peek_starts_case(&mut self) -> bool {
+		let case = self.peek_token_at(0);
+		let next = self.peek_token_at(1);
+		match next.kind {
+			t!("WHEN")
+			| t!("NONE")
+			| t!("NULL")
+			| t!("true")
+			| t!("false")
+			| t!("(")
+			| t!("{")
+			| t!("$param")
+			| t!("'")
+			| t!("\"")
+			| t!("r\"")
+			| t!("r'")
+			| t!("d\"")
+			| t!("d'")
+			| t!("u\"")
+			| t!("u'")
+			| TokenKind::Strand
+			| TokenKind::Number(_)
+			| TokenKind::Digits
+			| TokenKind::Duration
+			| TokenKind::Identifier => true,
+			// A keyword such as `value`, `type`, or `id` is only the subject when followed by `WHEN`
+			TokenKind::Keyword(_) => self.peek_token_at(2).kind == t!("WHEN"),
+			// Directly after `case`, these index or traverse from a field named `case`
+			t!("[") | t!("->") | t!("<-") | t!("<->") => !case.is_followed_by(&next),
+			// A negative subject is written with the `-` directly before its value
+			t!("-") => !case.is_followed_by(&next) && next.is_followed_by(&self.peek_token_at(2)),
+			// A cast to a kind, unlike a comparison with a field named `case`
+			t!("<") => {
+				matches!(self.peek_token_at(2).kind, TokenKind::Keyword(_) | TokenKind::Identifier)
+					&& matches!(self.peek_token_at(3).kind, t!(">") | t!("<") | t!("|"))
+			}
+			_ => false,
+		}
+	}
+
+	pub async fn parse_case_stmt(&mut self, ctx: &mut Stk) -> ParseResult<CaseStatement> {
+		let subject = if self.peek_kind() == t!("WHEN") {
+			None
+		} else {
+			Some(ctx.run(|ctx| self.parse_value_field(ctx)).await?)
+		};
+
+		let mut res = CaseStatement {
+			subject,
+			exprs: Vec::new(),
+			close: None,
+		};
+
+		expected!(self, t!("WHEN"));
+		loop {
+			let when = ctx.run(|ctx| self.parse_value_field(ctx)).await?;
+			expected!(self, t!("THEN"));
+			let then = ctx.run(|ctx| self.parse_value_field(ctx)).await?;
+			self.eat(t!(";"));
+			res.exprs.push((when, then));
+
+			match self.next().kind {
+				t!("WHEN") => continue,
+				t!("END") => return Ok(res),
+				t!("ELSE") => {
+					let value = ctx.run(|ctx| self.parse_value_field(ctx)).await?;
+					self.eat(t!(";"));
+					expected!(self, t!("END"));
+					res.close = Some(value);
+					return Ok(res);
+				}
+				x => unexpected!(self, x, "WHEN, ELSE or END"),
+			}
+		}
+	}
+}
@@ -12,12 +12,12 @@ use crate::{
 			DefineAccessStatement, DefineAnalyzerStatement, DefineDatabaseStatement,
 			DefineEventStatement, DefineFieldStatement, DefineFunctionStatement,
 			DefineIndexStatement, DefineNamespaceStatement, DefineParamStatement, DefineStatement,
-			DefineTableStatement, DefineUserStatement,
+			DefineTableStatement, DefineTaskStatement, DefineUserStatement,
 		},
 		table_type,
 		tokenizer::Tokenizer,
-		user, AccessType, Ident, Idioms, Index, Kind, Param, Permissions, Scoring, Strand,
-		TableType, Values,
+		user, AccessType, Duration, IdStrategy, Ident, Idioms, Index, Kind, Param, Permissions,
+		Scoring, Strand, TableType, Values,
 	},
 	syn::{
 		parser::{
@@ -50,6 +50,9 @@ impl Parser<'_> {
 			t!("INDEX") => self.parse_define_index().map(DefineStatement::Index),
 			t!("ANALYZER") => self.parse_define_analyzer().map(DefineStatement::Analyzer),
 			t!("ACCESS") => self.parse_define_access(ctx).await.map(DefineStatement::Access),
+			t!("TASK") => {
+				ctx.run(|ctx| self.parse_define_task(ctx)).await.map(DefineStatement::Task)
+			}
 			x => unexpected!(self, x, "a define statement keyword"),
 		}
 	}
@@ -305,9 +308,12 @@ impl Parser<'_> {
 									_ => break,
 								}
 							}
-							if self.eat(t!("WITH")) {
-								expected!(self, t!("JWT"));
-								ac.jwt = self.parse_jwt()?;
+							while self.eat(t!("WITH")) {
+								match self.next().kind {
+									t!("JWT") => ac.jwt = self.parse_jwt()?,
+									t!("REFRESH") => ac.refresh = true,
+									x => unexpected!(self, x, "`JWT` or `REFRESH`"),
+								}
 							}
 							res.kind = AccessType::Record(ac);
 						}
@@ -586,6 +592,30 @@ impl Parser<'_> {
 					self.pop_peek();
 					res.drop = true;
 				}
+				t!("VERSIONED") => {
+					self.pop_peek();
+					res.versioned = true;
+				}
+				t!("SOFT") => {
+					self.pop_peek();
+					expected!(self, t!("DELETE"));
+					res.soft_delete = true;
+				}
+				t!("ID") => {
+					self.pop_peek();
+					res.id_strategy = match self.next().kind {
+						t!("RAND") => IdStrategy::Rand,
+						t!("ULID") => IdStrategy::Ulid,
+						t!("UUID") => IdStrategy::Uuid,
+						t!("SNOWFLAKE") => IdStrategy::Snowflake,
+						t!("INCREMENT") => IdStrategy::Increment,
+						x => unexpected!(
+							self,
+							x,
+							"`RAND`, `ULID`, `UUID`, `SNOWFLAKE`, or `INCREMENT`"
+						),
+					};
+				}
 				t!("TYPE") => {
 					self.pop_peek();
 					match self.peek_kind() {
@@ -684,6 +714,44 @@ impl Parser<'_> {
 		Ok(res)
 	}
 
+	pub async fn parse_define_task(&mut self, ctx: &mut Stk) -> ParseResult<DefineTaskStatement> {
+		let if_not_exists = if self.eat(t!("IF")) {
+			expected!(self, t!("NOT"));
+			expected!(self, t!("EXISTS"));
+			true
+		} else {
+			false
+		};
+		let name = self.next_token_value()?;
+		expected!(self, t!("EVERY"));
+		let every = self.next_token_value::<Duration>()?;
+
+		let mut res = DefineTaskStatement {
+			name,
+			every,
+			if_not_exists,
+			..Default::default()
+		};
+
+		loop {
+			match self.peek_kind() {
+				t!("THEN") => {
+					self.pop_peek();
+					res.then = Values(vec![ctx.run(|ctx| self.parse_value(ctx)).await?]);
+					while self.eat(t!(",")) {
+						res.then.0.push(ctx.run(|ctx| self.parse_value(ctx)).await?)
+					}
+				}
+				t!("COMMENT") => {
+					self.pop_peek();
+					res.comment = Some(self.next_token_value()?);
+				}
+				_ => break,
+			}
+		}
+		Ok(res)
+	}
+
 	pub async fn parse_define_field(&mut self, ctx: &mut Stk) -> ParseResult<DefineFieldStatement> {
 		let if_not_exists = if self.eat(t!("IF")) {
 			expected!(self, t!("NOT"));
@@ -726,6 +794,11 @@ impl Parser<'_> {
 				t!("ASSERT") => {
 					self.pop_peek();
 					res.assert = Some(ctx.run(|ctx| self.parse_value(ctx)).await?);
+					res.assert_message = if self.eat(t!("ELSE")) {
+						Some(ctx.run(|ctx| self.parse_value(ctx)).await?)
+					} else {
+						None
+					};
 				}
 				t!("DEFAULT") => {
 					self.pop_peek();
@@ -1192,8 +1265,10 @@ impl Parser<'_> {
 			x => unexpected!(self, x, "`ALGORITHM`, or `URL`"),
 		}
 
-		if self.eat(t!("WITH")) {
-			expected!(self, t!("ISSUER"));
+		// A following WITH clause may belong to the access method instead
+		if self.peek_kind() == t!("WITH") && self.peek_token_at(1).kind == t!("ISSUER") {
+			self.pop_peek();
+			self.pop_peek();
 			loop {
 				match self.peek_kind() {
 					t!("ALGORITHM") => {
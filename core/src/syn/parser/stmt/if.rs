@@ -1,7 +1,10 @@
 use reblessive::Stk;
 
 use crate::{
-	sql::statements::IfelseStatement,
+	sql::{
+		statements::{IfelseStatement, SetStatement},
+		Value,
+	},
 	syn::{
 		parser::{
 			mac::{expected, unexpected},
@@ -13,11 +16,14 @@ use crate::{
 
 impl Parser<'_> {
 	pub async fn parse_if_stmt(&mut self, ctx: &mut Stk) -> ParseResult<IfelseStatement> {
+		let lets = self.parse_if_lets(ctx).await?;
 		let condition = ctx.run(|ctx| self.parse_value_field(ctx)).await?;
 
 		let mut res = IfelseStatement {
 			exprs: Vec::new(),
 			close: None,
+			parallel: false,
+			lets: Vec::new(),
 		};
 
 		let next = self.next();
@@ -25,12 +31,12 @@ impl Parser<'_> {
 			t!("THEN") => {
 				let body = ctx.run(|ctx| self.parse_value_field(ctx)).await?;
 				self.eat(t!(";"));
-				res.exprs.push((condition, body));
+				push_branch(&mut res, lets, condition, body);
 				self.parse_worded_tail(ctx, &mut res).await?;
 			}
 			t!("{") => {
 				let body = self.parse_block(ctx, next.span).await?;
-				res.exprs.push((condition, body.into()));
+				push_branch(&mut res, lets, condition, body.into());
 				self.parse_bracketed_tail(ctx, &mut res).await?;
 			}
 			x => unexpected!(self, x, "THEN or '{'"),
@@ -39,6 +45,17 @@ impl Parser<'_> {
 		Ok(res)
 	}
 
+	/// Parses any `LET $x = value;` bindings which precede a branch condition.
+	async fn parse_if_lets(&mut self, ctx: &mut Stk) -> ParseResult<Vec<SetStatement>> {
+		let mut lets = Vec::new();
+		while self.eat(t!("LET")) {
+			let v = ctx.run(|ctx| self.parse_let_stmt(ctx)).await?;
+			expected!(self, t!(";"));
+			lets.push(v);
+		}
+		Ok(lets)
+	}
+
 	async fn parse_worded_tail(
 		&mut self,
 		ctx: &mut Stk,
@@ -49,11 +66,12 @@ impl Parser<'_> {
 				t!("END") => return Ok(()),
 				t!("ELSE") => {
 					if self.eat(t!("IF")) {
+						let lets = self.parse_if_lets(ctx).await?;
 						let condition = ctx.run(|ctx| self.parse_value_field(ctx)).await?;
 						expected!(self, t!("THEN"));
 						let body = ctx.run(|ctx| self.parse_value_field(ctx)).await?;
 						self.eat(t!(";"));
-						res.exprs.push((condition, body));
+						push_branch(res, lets, condition, body);
 					} else {
 						let value = ctx.run(|ctx| self.parse_value_field(ctx)).await?;
 						self.eat(t!(";"));
@@ -77,10 +95,11 @@ impl Parser<'_> {
 				t!("ELSE") => {
 					self.pop_peek();
 					if self.eat(t!("IF")) {
+						let lets = self.parse_if_lets(ctx).await?;
 						let condition = ctx.run(|ctx| self.parse_value_field(ctx)).await?;
 						let span = expected!(self, t!("{")).span;
 						let body = self.parse_block(ctx, span).await?;
-						res.exprs.push((condition, body.into()));
+						push_branch(res, lets, condition, body.into());
 					} else {
 						let span = expected!(self, t!("{")).span;
 						let value = self.parse_block(ctx, span).await?;
@@ -93,3 +112,12 @@ impl Parser<'_> {
 		}
 	}
 }
+
+/// Adds a branch to the statement, keeping any LET bindings aligned with their branch.
+fn push_branch(res: &mut IfelseStatement, lets: Vec<SetStatement>, cond: Value, body: Value) {
+	if !lets.is_empty() {
+		res.lets.resize(res.exprs.len(), Vec::new());
+		res.lets.push(lets);
+	}
+	res.exprs.push((cond, body));
+}
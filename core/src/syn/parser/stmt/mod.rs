@@ -6,7 +6,8 @@ use crate::sql::statements::rebuild::{RebuildIndexStatement, RebuildStatement};
 use crate::sql::statements::show::{ShowSince, ShowStatement};
 use crate::sql::statements::sleep::SleepStatement;
 use crate::sql::statements::{
-	KillStatement, LiveStatement, OptionStatement, SetStatement, ThrowStatement,
+	ExplainStatement, KillStatement, LiveStatement, OptionStatement, SavepointStatement,
+	SetStatement, ThrowStatement,
 };
 use crate::sql::{Fields, Ident, Param};
 use crate::syn::parser::{ParseError, ParseErrorKind};
@@ -25,6 +26,7 @@ use crate::{
 
 use super::{mac::expected, ParseResult, Parser};
 
+mod case;
 mod create;
 mod define;
 mod delete;
@@ -33,6 +35,7 @@ mod insert;
 mod parts;
 mod relate;
 mod remove;
+mod restore;
 mod select;
 mod update;
 mod upsert;
@@ -82,15 +85,19 @@ impl Parser<'_> {
 			kind,
 			t!("ANALYZE")
 				| t!("BEGIN") | t!("BREAK")
-				| t!("CANCEL") | t!("COMMIT")
-				| t!("CONTINUE") | t!("CREATE")
+				| t!("CANCEL") | t!("CASE")
+				| t!("COMMIT") | t!("CONTINUE")
+				| t!("CREATE")
 				| t!("DEFINE") | t!("DELETE")
-				| t!("FOR") | t!("IF")
+				| t!("EXPLAIN") | t!("FOR")
+				| t!("IF")
 				| t!("INFO") | t!("INSERT")
 				| t!("KILL") | t!("LIVE")
 				| t!("OPTION") | t!("REBUILD")
 				| t!("RETURN") | t!("RELATE")
-				| t!("REMOVE") | t!("SELECT")
+				| t!("RELEASE") | t!("REMOVE")
+				| t!("RESTORE") | t!("ROLLBACK")
+				| t!("SELECT")
 				| t!("LET") | t!("SHOW")
 				| t!("SLEEP") | t!("THROW")
 				| t!("UPDATE") | t!("UPSERT")
@@ -135,6 +142,11 @@ impl Parser<'_> {
 				self.pop_peek();
 				ctx.run(|ctx| self.parse_create_stmt(ctx)).await.map(Statement::Create)
 			}
+			t!("DEFINE") if self.peek_token_at(1).kind == t!("SAVEPOINT") => {
+				self.pop_peek();
+				self.pop_peek();
+				self.next_token_value().map(SavepointStatement::Define).map(Statement::Savepoint)
+			}
 			t!("DEFINE") => {
 				self.pop_peek();
 				ctx.run(|ctx| self.parse_define_stmt(ctx)).await.map(Statement::Define)
@@ -143,13 +155,27 @@ impl Parser<'_> {
 				self.pop_peek();
 				ctx.run(|ctx| self.parse_delete_stmt(ctx)).await.map(Statement::Delete)
 			}
+			t!("EXPLAIN") => {
+				self.pop_peek();
+				let full = self.eat(t!("FULL"));
+				expected!(self, t!("SELECT"));
+				let what = ctx.run(|ctx| self.parse_select_stmt(ctx)).await?;
+				Ok(Statement::Explain(ExplainStatement {
+					full,
+					what,
+				}))
+			}
 			t!("FOR") => {
 				self.pop_peek();
 				ctx.run(|ctx| self.parse_for_stmt(ctx)).await.map(Statement::Foreach)
 			}
 			t!("IF") => {
 				self.pop_peek();
-				ctx.run(|ctx| self.parse_if_stmt(ctx)).await.map(Statement::Ifelse)
+				let mut stmt = ctx.run(|ctx| self.parse_if_stmt(ctx)).await?;
+				// Only a top-level IF statement takes a PARALLEL clause, as within
+				// another statement it belongs to the enclosing statement
+				stmt.parallel = self.eat(t!("PARALLEL"));
+				Ok(Statement::Ifelse(stmt))
 			}
 			t!("INFO") => {
 				self.pop_peek();
@@ -183,10 +209,22 @@ impl Parser<'_> {
 				self.pop_peek();
 				ctx.run(|ctx| self.parse_relate_stmt(ctx)).await.map(Statement::Relate)
 			}
+			t!("RELEASE") => {
+				self.pop_peek();
+				self.parse_release().map(Statement::Savepoint)
+			}
 			t!("REMOVE") => {
 				self.pop_peek();
 				self.parse_remove_stmt().map(Statement::Remove)
 			}
+			t!("RESTORE") => {
+				self.pop_peek();
+				ctx.run(|ctx| self.parse_restore_stmt(ctx)).await.map(Statement::Restore)
+			}
+			t!("ROLLBACK") => {
+				self.pop_peek();
+				self.parse_rollback().map(Statement::Savepoint)
+			}
 			t!("SELECT") => {
 				self.pop_peek();
 				ctx.run(|ctx| self.parse_select_stmt(ctx)).await.map(Statement::Select)
@@ -325,6 +363,7 @@ impl Parser<'_> {
 					return Statement::Set(crate::sql::statements::SetStatement {
 						name: x.0 .0,
 						what: r,
+						kind: None,
 					});
 				}
 				Statement::Value(Value::Expression(x))
@@ -345,6 +384,7 @@ impl Parser<'_> {
 					return Entry::Set(crate::sql::statements::SetStatement {
 						name: x.0 .0,
 						what: r,
+						kind: None,
 					});
 				}
 				Entry::Value(Value::Expression(x))
@@ -397,6 +437,27 @@ impl Parser<'_> {
 		Ok(CommitStatement)
 	}
 
+	/// Parsers a release savepoint statement.
+	///
+	/// # Parser State
+	/// Expects `RELEASE` to already be consumed.
+	fn parse_release(&mut self) -> ParseResult<SavepointStatement> {
+		self.eat(t!("SAVEPOINT"));
+		let name = self.next_token_value()?;
+		Ok(SavepointStatement::Release(name))
+	}
+
+	/// Parsers a rollback to savepoint statement.
+	///
+	/// # Parser State
+	/// Expects `ROLLBACK` to already be consumed.
+	fn parse_rollback(&mut self) -> ParseResult<SavepointStatement> {
+		expected!(self, t!("TO"));
+		self.eat(t!("SAVEPOINT"));
+		let name = self.next_token_value()?;
+		Ok(SavepointStatement::Rollback(name))
+	}
+
 	/// Parsers a USE statement.
 	///
 	/// # Parser State
@@ -587,11 +648,22 @@ impl Parser<'_> {
 	/// Expects `LET` to already be consumed.
 	pub(crate) async fn parse_let_stmt(&mut self, ctx: &mut Stk) -> ParseResult<SetStatement> {
 		let name = self.next_token_value::<Param>()?.0 .0;
-		expected!(self, t!("="));
-		let what = self.parse_value(ctx).await?;
+		let kind = match self.eat(t!(":")) {
+			true => Some(ctx.run(|ctx| self.parse_inner_kind(ctx)).await?),
+			false => None,
+		};
+		let what = if self.eat(t!("=")) {
+			self.parse_value(ctx).await?
+		} else if kind.is_some() {
+			// A typed parameter without a value declares the type of a bound parameter
+			Value::Param(Param::from(name.clone()))
+		} else {
+			unexpected!(self, self.peek_kind(), "`=` or `:`")
+		};
 		Ok(SetStatement {
 			name,
 			what,
+			kind,
 		})
 	}
 
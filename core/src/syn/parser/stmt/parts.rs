@@ -48,7 +48,11 @@ impl Parser<'_> {
 			}
 			t!("MERGE") => {
 				self.pop_peek();
-				Data::MergeExpression(ctx.run(|ctx| self.parse_value(ctx)).await?)
+				if self.eat(t!("PATCH")) {
+					Data::MergePatchExpression(ctx.run(|ctx| self.parse_value(ctx)).await?)
+				} else {
+					Data::MergeExpression(ctx.run(|ctx| self.parse_value(ctx)).await?)
+				}
 			}
 			t!("REPLACE") => {
 				self.pop_peek();
@@ -128,10 +132,20 @@ impl Parser<'_> {
 	) -> ParseResult<&'a Field> {
 		let mut found = None;
 		for field in fields.iter() {
-			let Field::Single {
+			let (Field::Single {
+				expr,
+				alias,
+			}
+			| Field::Window {
+				expr,
+				alias,
+				..
+			}
+			| Field::Filter {
 				expr,
 				alias,
-			} = field
+				..
+			}) = field
 			else {
 				unreachable!()
 			};
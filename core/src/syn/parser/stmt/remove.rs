@@ -4,7 +4,7 @@ use crate::{
 			remove::RemoveAnalyzerStatement, RemoveAccessStatement, RemoveDatabaseStatement,
 			RemoveEventStatement, RemoveFieldStatement, RemoveFunctionStatement,
 			RemoveIndexStatement, RemoveNamespaceStatement, RemoveParamStatement, RemoveStatement,
-			RemoveUserStatement,
+			RemoveTaskStatement, RemoveUserStatement,
 		},
 		Param,
 	},
@@ -196,6 +196,20 @@ impl Parser<'_> {
 					if_exists,
 				})
 			}
+			t!("TASK") => {
+				let if_exists = if self.eat(t!("IF")) {
+					expected!(self, t!("EXISTS"));
+					true
+				} else {
+					false
+				};
+				let name = self.next_token_value()?;
+
+				RemoveStatement::Task(RemoveTaskStatement {
+					name,
+					if_exists,
+				})
+			}
 			x => unexpected!(self, x, "a remove statement keyword"),
 		};
 		Ok(res)
@@ -0,0 +1,29 @@
+use reblessive::Stk;
+
+use crate::{
+	sql::{statements::RestoreStatement, Values},
+	syn::{
+		parser::{ParseResult, Parser},
+		token::t,
+	},
+};
+
+impl Parser<'_> {
+	pub async fn parse_restore_stmt(&mut self, ctx: &mut Stk) -> ParseResult<RestoreStatement> {
+		let only = self.eat(t!("ONLY"));
+		let what = Values(self.parse_what_list(ctx).await?);
+		let cond = self.try_parse_condition(ctx).await?;
+		let output = self.try_parse_output(ctx).await?;
+		let timeout = self.try_parse_timeout()?;
+		let parallel = self.eat(t!("PARALLEL"));
+
+		Ok(RestoreStatement {
+			only,
+			what,
+			cond,
+			output,
+			timeout,
+			parallel,
+		})
+	}
+}
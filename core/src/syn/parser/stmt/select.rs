@@ -2,8 +2,8 @@ use reblessive::Stk;
 
 use crate::{
 	sql::{
-		statements::SelectStatement, Explain, Field, Fields, Ident, Idioms, Limit, Order, Orders,
-		Split, Splits, Start, Values, Version, With,
+		statements::SelectStatement, Cond, Duration, Explain, Field, Fields, Ident, Idiom, Idioms,
+		Limit, Order, Orders, Split, Splits, Start, Value, Values, Version, With,
 	},
 	syn::{
 		parser::{
@@ -11,7 +11,7 @@ use crate::{
 			mac::{expected, unexpected},
 			ParseResult, Parser,
 		},
-		token::{t, Span},
+		token::{t, Span, TokenKind},
 	},
 };
 
@@ -20,10 +20,45 @@ impl Parser<'_> {
 		&mut self,
 		stk: &mut Stk,
 	) -> ParseResult<SelectStatement> {
+		let distinct = self.try_parse_distinct()?;
+
 		let before = self.peek().span;
-		let expr = self.parse_fields(stk).await?;
+		let expr = self.parse_select_fields(stk).await?;
 		let fields_span = before.covers(self.last_span());
 
+		// Windows are ordered by the selected fields of each row
+		if expr.has_windows() && !expr.contains(&Field::All) {
+			for field in expr.iter() {
+				if let Field::Window {
+					order: Some(orders),
+					..
+				} = field
+				{
+					for order in orders.iter() {
+						Self::check_idiom(
+							MissingKind::Order,
+							&expr,
+							fields_span,
+							order,
+							fields_span,
+						)?;
+					}
+				}
+			}
+		}
+
+		let distinct = match distinct {
+			Some(idioms) => {
+				if !expr.contains(&Field::All) {
+					for (idiom, span) in idioms.iter() {
+						Self::check_idiom(MissingKind::Distinct, &expr, fields_span, idiom, *span)?;
+					}
+				}
+				Some(Idioms(idioms.into_iter().map(|(idiom, _)| idiom).collect()))
+			}
+			None => None,
+		};
+
 		let omit = if self.eat(t!("OMIT")) {
 			Some(Idioms(self.parse_idiom_list(stk).await?))
 		} else {
@@ -40,12 +75,23 @@ impl Parser<'_> {
 		}
 		let what = Values(what);
 
+		let deleted = self.try_parse_with_deleted();
 		let with = self.try_parse_with()?;
 		let cond = self.try_parse_condition(stk).await?;
 		let split = self.try_parse_split(&expr, fields_span)?;
+		if expr.has_windows() && self.peek_kind() == t!("GROUP") {
+			unexpected!(self, t!("GROUP"), "no GROUP clause" => "window functions can not be used with a GROUP clause");
+		}
 		let group = self.try_parse_group(&expr, fields_span)?;
+		let having = self.try_parse_having(stk).await?;
+		if expr.has_filters() && group.is_none() && having.is_none() {
+			unexpected!(self, self.peek_kind(), "a GROUP clause" => "filtered aggregates can only be used with a GROUP or HAVING clause");
+		}
+		if expr.has_filter_conflicts() {
+			unexpected!(@ fields_span, self, self.peek_kind(), "a unique name for each field" => "filtered aggregates can not have the same name as another field, use an AS alias");
+		}
 		let order = self.try_parse_orders(&expr, fields_span)?;
-		let (limit, start) = if let t!("START") = self.peek_kind() {
+		let (limit, (start, cursor)) = if let t!("START") = self.peek_kind() {
 			let start = self.try_parse_start(stk).await?;
 			let limit = self.try_parse_limit(stk).await?;
 			(limit, start)
@@ -57,6 +103,7 @@ impl Parser<'_> {
 		let fetch = self.try_parse_fetch(stk).await?;
 		let version = self.try_parse_version()?;
 		let timeout = self.try_parse_timeout()?;
+		let cache = self.try_parse_cache()?;
 		let parallel = self.eat(t!("PARALLEL"));
 		let tempfiles = self.eat(t!("TEMPFILES"));
 		let explain = self.eat(t!("EXPLAIN")).then(|| Explain(self.eat(t!("FULL"))));
@@ -67,21 +114,48 @@ impl Parser<'_> {
 			only,
 			what,
 			with,
+			deleted,
 			cond,
 			split,
 			group,
+			having,
+			distinct,
 			order,
 			limit,
 			start,
+			cursor,
 			fetch,
 			version,
 			timeout,
+			cache,
 			parallel,
 			tempfiles,
 			explain,
 		})
 	}
 
+	/// Parses a `CACHE` clause, with an optional expiry duration.
+	fn try_parse_cache(&mut self) -> ParseResult<Option<Duration>> {
+		if !self.eat(t!("CACHE")) {
+			return Ok(None);
+		}
+		// Without a duration the default expiry is used
+		if let TokenKind::Digits = self.peek_kind() {
+			return Ok(Some(self.next_token_value()?));
+		}
+		Ok(Some(Duration::default()))
+	}
+
+	/// Parses a `WITH DELETED` clause, which includes soft-deleted records.
+	fn try_parse_with_deleted(&mut self) -> bool {
+		if self.peek_kind() != t!("WITH") || self.peek_token_at(1).kind != t!("DELETED") {
+			return false;
+		}
+		self.pop_peek();
+		self.pop_peek();
+		true
+	}
+
 	fn try_parse_with(&mut self) -> ParseResult<Option<With>> {
 		if !self.eat(t!("WITH")) {
 			return Ok(None);
@@ -104,6 +178,36 @@ impl Parser<'_> {
 		Ok(Some(with))
 	}
 
+	/// Parses a `DISTINCT ON (...)` clause, returning each idiom with its span.
+	fn try_parse_distinct(&mut self) -> ParseResult<Option<Vec<(Idiom, Span)>>> {
+		// Only treat DISTINCT as a keyword when followed by ON, so it can still be a field
+		if self.peek_kind() != t!("DISTINCT") || self.peek_token_at(1).kind != t!("ON") {
+			return Ok(None);
+		}
+		self.pop_peek();
+		self.pop_peek();
+		let start = expected!(self, t!("(")).span;
+		let mut idioms = Vec::new();
+		loop {
+			let before = self.peek().span;
+			let idiom = self.parse_basic_idiom()?;
+			idioms.push((idiom, before.covers(self.last_span())));
+			if !self.eat(t!(",")) {
+				break;
+			}
+		}
+		self.expect_closing_delimiter(t!(")"), start)?;
+		Ok(Some(idioms))
+	}
+
+	async fn try_parse_having(&mut self, stk: &mut Stk) -> ParseResult<Option<Cond>> {
+		if !self.eat(t!("HAVING")) {
+			return Ok(None);
+		}
+		let v = stk.run(|stk| self.parse_value_field(stk)).await?;
+		Ok(Some(Cond(v)))
+	}
+
 	fn try_parse_split(
 		&mut self,
 		fields: &Fields,
@@ -158,6 +262,7 @@ impl Parser<'_> {
 				collate: false,
 				numeric: false,
 				direction: true,
+				nulls: None,
 			}])));
 		};
 
@@ -184,6 +289,22 @@ impl Parser<'_> {
 		Ok(Some(Orders(orders)))
 	}
 
+	/// Parses the window of a window aggregate: `(ORDER BY time)` in `math::sum(amount) OVER (ORDER BY time)`.
+	pub(crate) fn parse_window(&mut self) -> ParseResult<Option<Orders>> {
+		let start = expected!(self, t!("(")).span;
+		if self.eat(t!(")")) {
+			return Ok(None);
+		}
+		expected!(self, t!("ORDER"));
+		self.eat(t!("BY"));
+		let mut orders = vec![self.parse_order()?];
+		while self.eat(t!(",")) {
+			orders.push(self.parse_order()?);
+		}
+		self.expect_closing_delimiter(t!(")"), start)?;
+		Ok(Some(Orders(orders)))
+	}
+
 	fn parse_order(&mut self) -> ParseResult<Order> {
 		let start = self.parse_basic_idiom()?;
 		let collate = self.eat(t!("COLLATE"));
@@ -199,12 +320,22 @@ impl Parser<'_> {
 			}
 			_ => true,
 		};
+		let nulls = if self.eat(t!("NULLS")) {
+			match self.next().kind {
+				t!("FIRST") => Some(true),
+				t!("LAST") => Some(false),
+				x => unexpected!(self, x, "'FIRST' or 'LAST'"),
+			}
+		} else {
+			None
+		};
 		Ok(Order {
 			order: start,
 			random: false,
 			collate,
 			numeric,
 			direction,
+			nulls,
 		})
 	}
 
@@ -217,13 +348,22 @@ impl Parser<'_> {
 		Ok(Some(Limit(value)))
 	}
 
-	async fn try_parse_start(&mut self, ctx: &mut Stk) -> ParseResult<Option<Start>> {
+	/// Parses a `START` clause, returning either the number of records to skip,
+	/// or the cursor from a `START AFTER` clause which the records resume after.
+	async fn try_parse_start(
+		&mut self,
+		ctx: &mut Stk,
+	) -> ParseResult<(Option<Start>, Option<Value>)> {
 		if !self.eat(t!("START")) {
-			return Ok(None);
+			return Ok((None, None));
+		}
+		if self.eat(t!("AFTER")) {
+			let value = ctx.run(|ctx| self.parse_value(ctx)).await?;
+			return Ok((None, Some(value)));
 		}
 		self.eat(t!("AT"));
 		let value = ctx.run(|ctx| self.parse_value(ctx)).await?;
-		Ok(Some(Start(value)))
+		Ok((Some(Start(value)), None))
 	}
 
 	fn try_parse_version(&mut self) -> ParseResult<Option<Version>> {
@@ -14,26 +14,28 @@ use crate::{
 			analyze::AnalyzeStatement,
 			show::{ShowSince, ShowStatement},
 			sleep::SleepStatement,
-			BeginStatement, BreakStatement, CancelStatement, CommitStatement, ContinueStatement,
-			CreateStatement, DefineAccessStatement, DefineAnalyzerStatement,
+			BeginStatement, BreakStatement, CancelStatement, CaseStatement, CommitStatement,
+			ContinueStatement, CreateStatement, DefineAccessStatement, DefineAnalyzerStatement,
 			DefineDatabaseStatement, DefineEventStatement, DefineFieldStatement,
 			DefineFunctionStatement, DefineIndexStatement, DefineNamespaceStatement,
-			DefineParamStatement, DefineStatement, DefineTableStatement, DeleteStatement,
-			ForeachStatement, IfelseStatement, InfoStatement, InsertStatement, KillStatement,
-			OptionStatement, OutputStatement, RelateStatement, RemoveAccessStatement,
-			RemoveAnalyzerStatement, RemoveDatabaseStatement, RemoveEventStatement,
-			RemoveFieldStatement, RemoveFunctionStatement, RemoveIndexStatement,
-			RemoveNamespaceStatement, RemoveParamStatement, RemoveStatement, RemoveTableStatement,
-			RemoveUserStatement, SelectStatement, SetStatement, ThrowStatement, UpdateStatement,
+			DefineParamStatement, DefineStatement, DefineTableStatement, DefineTaskStatement,
+			DeleteStatement, ExplainStatement, ForeachStatement, IfelseStatement, InfoStatement,
+			InsertStatement, KillStatement, OptionStatement, OutputStatement, RelateStatement,
+			RemoveAccessStatement, RemoveAnalyzerStatement, RemoveDatabaseStatement,
+			RemoveEventStatement, RemoveFieldStatement, RemoveFunctionStatement,
+			RemoveIndexStatement, RemoveNamespaceStatement, RemoveParamStatement, RemoveStatement,
+			RemoveTableStatement, RemoveTaskStatement, RemoveUserStatement, RestoreStatement,
+			SavepointStatement, SelectStatement, SetStatement, ThrowStatement, UpdateStatement,
 			UpsertStatement, UseStatement,
 		},
 		tokenizer::Tokenizer,
 		user::UserDuration,
 		Algorithm, Array, Base, Block, Cond, Data, Datetime, Dir, Duration, Edges, Explain,
-		Expression, Fetch, Fetchs, Field, Fields, Future, Graph, Group, Groups, Id, Ident, Idiom,
-		Idioms, Index, Kind, Limit, Number, Object, Operator, Order, Orders, Output, Param, Part,
-		Permission, Permissions, Scoring, Split, Splits, Start, Statement, Strand, Subquery, Table,
-		TableType, Tables, Thing, Timeout, Uuid, Value, Values, Version, With,
+		Expression, Fetch, Fetchs, Field, Fields, Function, Future, Graph, Group, Groups, Id,
+		IdStrategy, Ident, Idiom, Idioms, Index, Kind, Limit, Number, Object, Operator, Order,
+		Orders, Output, Param, Part, Permission, Permissions, Scoring, Split, Splits, Start,
+		Statement, Strand, Subquery, Table, TableType, Tables, Thing, Timeout, Uuid, Value, Values,
+		Version, With,
 	},
 	syn::parser::mac::test_parse,
 };
@@ -78,6 +80,36 @@ pub fn parse_commit() {
 	assert_eq!(res, Statement::Commit(CommitStatement));
 }
 
+#[test]
+pub fn parse_savepoint() {
+	let res = test_parse!(parse_stmt, r#"DEFINE SAVEPOINT checkpoint"#).unwrap();
+	assert_eq!(
+		res,
+		Statement::Savepoint(SavepointStatement::Define(Ident("checkpoint".to_owned())))
+	);
+	let res = test_parse!(parse_stmt, r#"RELEASE SAVEPOINT checkpoint"#).unwrap();
+	assert_eq!(
+		res,
+		Statement::Savepoint(SavepointStatement::Release(Ident("checkpoint".to_owned())))
+	);
+	let res = test_parse!(parse_stmt, r#"RELEASE checkpoint"#).unwrap();
+	assert_eq!(
+		res,
+		Statement::Savepoint(SavepointStatement::Release(Ident("checkpoint".to_owned())))
+	);
+	let res = test_parse!(parse_stmt, r#"ROLLBACK TO SAVEPOINT checkpoint"#).unwrap();
+	assert_eq!(
+		res,
+		Statement::Savepoint(SavepointStatement::Rollback(Ident("checkpoint".to_owned())))
+	);
+	let res = test_parse!(parse_stmt, r#"ROLLBACK TO checkpoint"#).unwrap();
+	assert_eq!(
+		res,
+		Statement::Savepoint(SavepointStatement::Rollback(Ident("checkpoint".to_owned())))
+	);
+	test_parse!(parse_stmt, r#"ROLLBACK checkpoint"#).unwrap_err();
+}
+
 #[test]
 pub fn parse_continue() {
 	let res = test_parse!(parse_stmt, r#"CONTINUE"#).unwrap();
@@ -1034,7 +1066,8 @@ fn parse_define_access_record() {
 							// Issuer key matches verification key by default in symmetric algorithms.
 							key: "foo".to_string(),
 						}),
-					}
+					},
+					refresh: false,
 				}),
 				duration: AccessDuration {
 					grant: None,
@@ -1070,7 +1103,8 @@ fn parse_define_access_record() {
 							alg: Algorithm::Ps512,
 							key: "bar".to_string(),
 						}),
-					}
+					},
+					refresh: false,
 				}),
 				duration: AccessDuration {
 					grant: None,
@@ -1106,7 +1140,8 @@ fn parse_define_access_record() {
 							alg: Algorithm::Rs256,
 							key: "bar".to_string(),
 						}),
-					}
+					},
+					refresh: false,
 				}),
 				duration: AccessDuration {
 					grant: None,
@@ -1151,7 +1186,8 @@ fn parse_define_access_record_with_jwt() {
 						key: "foo".to_string(),
 					}),
 					issue: None,
-				}
+				},
+				refresh: false,
 			}),
 			// Default durations.
 			duration: AccessDuration {
@@ -1165,6 +1201,47 @@ fn parse_define_access_record_with_jwt() {
 	)
 }
 
+#[test]
+fn parse_define_access_record_with_refresh() {
+	let res = test_parse!(
+		parse_stmt,
+		r#"DEFINE ACCESS a ON DATABASE TYPE RECORD WITH JWT ALGORITHM HS512 KEY "foo" WITH ISSUER KEY "foo" WITH REFRESH DURATION FOR GRANT 1w, FOR TOKEN 15m"#
+	)
+	.unwrap();
+	let expected = Statement::Define(DefineStatement::Access(DefineAccessStatement {
+		name: Ident("a".to_string()),
+		base: Base::Db,
+		kind: AccessType::Record(RecordAccess {
+			signup: None,
+			signin: None,
+			jwt: JwtAccess {
+				verify: JwtAccessVerify::Key(JwtAccessVerifyKey {
+					alg: Algorithm::Hs512,
+					key: "foo".to_string(),
+				}),
+				issue: Some(JwtAccessIssue {
+					alg: Algorithm::Hs512,
+					key: "foo".to_string(),
+				}),
+			},
+			refresh: true,
+		}),
+		duration: AccessDuration {
+			grant: Some(Duration::from_hours(168)),
+			token: Some(Duration::from_mins(15)),
+			session: None,
+		},
+		comment: None,
+		if_not_exists: false,
+	}));
+	assert_eq!(res, expected);
+	// The grant duration is displayed once refresh tokens are enabled
+	assert_eq!(
+		res.to_string(),
+		"DEFINE ACCESS a ON DATABASE TYPE RECORD WITH JWT ALGORITHM HS512 KEY 'foo' WITH ISSUER KEY 'foo' WITH REFRESH DURATION FOR GRANT 1w, FOR TOKEN 15m, FOR SESSION NONE"
+	);
+}
+
 #[test]
 fn parse_define_param() {
 	let res =
@@ -1193,7 +1270,7 @@ fn parse_define_param() {
 #[test]
 fn parse_define_table() {
 	let res =
-		test_parse!(parse_stmt, r#"DEFINE TABLE name DROP SCHEMAFUL CHANGEFEED 1s INCLUDE ORIGINAL PERMISSIONS FOR SELECT WHERE a = 1 AS SELECT foo FROM bar GROUP BY foo"#)
+		test_parse!(parse_stmt, r#"DEFINE TABLE name DROP VERSIONED SCHEMAFUL CHANGEFEED 1s INCLUDE ORIGINAL PERMISSIONS FOR SELECT WHERE a = 1 AS SELECT foo FROM bar GROUP BY foo"#)
 			.unwrap();
 
 	assert_eq!(
@@ -1234,10 +1311,66 @@ fn parse_define_table() {
 			comment: None,
 			if_not_exists: false,
 			kind: TableType::Any,
+			versioned: true,
+			id_strategy: IdStrategy::Rand,
+			soft_delete: false,
 		}))
 	);
 }
 
+#[test]
+fn parse_define_table_id_strategy() {
+	for (sql, strategy) in [
+		("DEFINE TABLE name ID RAND", IdStrategy::Rand),
+		("DEFINE TABLE name ID ULID", IdStrategy::Ulid),
+		("DEFINE TABLE name ID uuid", IdStrategy::Uuid),
+		("DEFINE TABLE name SCHEMAFULL ID SNOWFLAKE", IdStrategy::Snowflake),
+		("DEFINE TABLE name ID INCREMENT TYPE NORMAL", IdStrategy::Increment),
+	] {
+		let res = test_parse!(parse_stmt, sql).unwrap();
+		let Statement::Define(DefineStatement::Table(stmt)) = res else {
+			panic!("Expected a DEFINE TABLE statement");
+		};
+		assert_eq!(stmt.id_strategy, strategy);
+	}
+	assert!(test_parse!(parse_stmt, "DEFINE TABLE name ID SEQUENCE").is_err());
+}
+
+#[test]
+fn parse_define_table_soft_delete() {
+	let res = test_parse!(parse_stmt, "DEFINE TABLE name SOFT DELETE SCHEMAFULL").unwrap();
+	let Statement::Define(DefineStatement::Table(ref stmt)) = res else {
+		panic!("Expected a DEFINE TABLE statement");
+	};
+	assert!(stmt.soft_delete);
+	assert_eq!(
+		res.to_string(),
+		"DEFINE TABLE name TYPE ANY SOFT DELETE SCHEMAFULL PERMISSIONS NONE"
+	);
+	assert!(test_parse!(parse_stmt, "DEFINE TABLE name SOFT").is_err());
+}
+
+#[test]
+fn parse_define_field_assert_message() {
+	let res = test_parse!(
+		parse_stmt,
+		r#"DEFINE FIELD price ON product ASSERT $value > 0 ELSE "price must be positive" COMMENT "cost""#
+	)
+	.unwrap();
+	let Statement::Define(DefineStatement::Field(stmt)) = res else {
+		panic!()
+	};
+	assert_eq!(
+		stmt.assert_message,
+		Some(Value::Strand(Strand("price must be positive".to_owned())))
+	);
+	assert_eq!(stmt.comment, Some(Strand("cost".to_owned())));
+	assert_eq!(
+		stmt.to_string(),
+		"DEFINE FIELD price ON product ASSERT $value > 0 ELSE 'price must be positive' COMMENT 'cost' PERMISSIONS FULL"
+	);
+}
+
 #[test]
 fn parse_define_event() {
 	let res =
@@ -1257,6 +1390,26 @@ fn parse_define_event() {
 	)
 }
 
+#[test]
+fn parse_define_task() {
+	let res = test_parse!(
+		parse_stmt,
+		r#"DEFINE TASK IF NOT EXISTS cleanup EVERY 1h THEN null,none COMMENT "test""#
+	)
+	.unwrap();
+
+	assert_eq!(
+		res,
+		Statement::Define(DefineStatement::Task(DefineTaskStatement {
+			name: Ident("cleanup".to_owned()),
+			every: Duration(std::time::Duration::from_secs(3600)),
+			then: Values(vec![Value::Null, Value::None]),
+			comment: Some(Strand("test".to_string())),
+			if_not_exists: true,
+		}))
+	)
+}
+
 #[test]
 fn parse_define_field() {
 	let res = test_parse!(
@@ -1282,6 +1435,7 @@ fn parse_define_field() {
 			readonly: false,
 			value: Some(Value::Null),
 			assert: Some(Value::Bool(true)),
+			assert_message: None,
 			default: Some(Value::Bool(false)),
 			permissions: Permissions {
 				delete: Permission::None,
@@ -1491,6 +1645,37 @@ fn parse_delete_2() {
 	)
 }
 
+#[test]
+fn parse_restore() {
+	let res = test_parse!(
+		parse_stmt,
+		"RESTORE ONLY person:tobie WHERE age > 18 RETURN AFTER TIMEOUT 1s PARALLEL"
+	)
+	.unwrap();
+	assert_eq!(
+		res,
+		Statement::Restore(RestoreStatement {
+			only: true,
+			what: Values(vec![Value::Thing(Thing {
+				tb: "person".to_owned(),
+				id: Id::String("tobie".to_owned()),
+			})]),
+			cond: Some(Cond(Value::Expression(Box::new(Expression::Binary {
+				l: Value::Idiom(Idiom(vec![Part::Field(Ident("age".to_owned()))])),
+				o: Operator::MoreThan,
+				r: Value::Number(Number::Int(18)),
+			})))),
+			output: Some(Output::After),
+			timeout: Some(Timeout(Duration(std::time::Duration::from_secs(1)))),
+			parallel: true,
+		})
+	);
+	assert_eq!(
+		res.to_string(),
+		"RESTORE ONLY person:tobie WHERE age > 18 RETURN AFTER TIMEOUT 1s PARALLEL"
+	);
+}
+
 #[test]
 pub fn parse_for() {
 	let res = test_parse!(
@@ -1542,7 +1727,9 @@ fn parse_if() {
 					Value::Idiom(Idiom(vec![Part::Field(Ident("baz".to_owned()))]))
 				)
 			],
-			close: Some(Value::Idiom(Idiom(vec![Part::Field(Ident("baq".to_owned()))])))
+			close: Some(Value::Idiom(Idiom(vec![Part::Field(Ident("baq".to_owned()))]))),
+			parallel: false,
+			lets: Vec::new(),
 		})
 	)
 }
@@ -1571,10 +1758,344 @@ fn parse_if_block() {
 			close: Some(Value::Block(Box::new(Block(vec![Entry::Value(Value::Idiom(Idiom(
 				vec![Part::Field(Ident("baq".to_owned()))]
 			)))])))),
+			parallel: false,
+			lets: Vec::new(),
 		})
 	)
 }
 
+#[test]
+fn parse_select_having() {
+	let res = test_parse!(
+		parse_stmt,
+		r#"SELECT bar, count() AS total FROM foo GROUP BY bar HAVING total > 1"#
+	)
+	.unwrap();
+	let Statement::Select(ref stmt) = res else {
+		panic!("expected a select statement")
+	};
+	assert_eq!(
+		stmt.having,
+		Some(Cond(Value::Expression(Box::new(Expression::Binary {
+			l: Value::Idiom(Idiom(vec![Part::Field(Ident("total".to_owned()))])),
+			o: Operator::MoreThan,
+			r: Value::Number(Number::Int(1)),
+		}))))
+	);
+	assert_eq!(
+		res.to_string(),
+		"SELECT bar, count() AS total FROM foo GROUP BY bar HAVING total > 1"
+	);
+}
+
+#[test]
+fn parse_select_distinct_on() {
+	let res =
+		test_parse!(parse_stmt, r#"SELECT DISTINCT ON (foo, bar.baz) * FROM test ORDER BY foo"#)
+			.unwrap();
+	let Statement::Select(ref stmt) = res else {
+		panic!("expected a select statement")
+	};
+	assert_eq!(
+		stmt.distinct,
+		Some(Idioms(vec![
+			Idiom(vec![Part::Field(Ident("foo".to_owned()))]),
+			Idiom(vec![Part::Field(Ident("bar".to_owned())), Part::Field(Ident("baz".to_owned()))]),
+		]))
+	);
+	assert_eq!(res.to_string(), "SELECT DISTINCT ON (foo, bar.baz) * FROM test ORDER BY foo");
+	// The distinct idioms must be part of the selection
+	test_parse!(parse_stmt, r#"SELECT DISTINCT ON (foo) bar FROM test"#).unwrap_err();
+	// DISTINCT is still usable as a field name
+	test_parse!(parse_stmt, r#"SELECT distinct FROM test"#).unwrap();
+}
+
+#[test]
+fn parse_select_cache() {
+	let res = test_parse!(parse_stmt, r#"SELECT * FROM test CACHE 30s"#).unwrap();
+	let Statement::Select(ref stmt) = res else {
+		panic!("expected a select statement")
+	};
+	assert_eq!(stmt.cache, Some(Duration(std::time::Duration::from_secs(30))));
+	assert_eq!(res.to_string(), "SELECT * FROM test CACHE 30s");
+	// Without a duration the default expiry is used
+	let res = test_parse!(parse_stmt, r#"SELECT * FROM test CACHE"#).unwrap();
+	let Statement::Select(ref stmt) = res else {
+		panic!("expected a select statement")
+	};
+	assert_eq!(stmt.cache, Some(Duration::default()));
+	assert_eq!(res.to_string(), "SELECT * FROM test CACHE");
+	let res = test_parse!(parse_stmt, r#"SELECT * FROM test TIMEOUT 1s CACHE PARALLEL"#).unwrap();
+	assert_eq!(res.to_string(), "SELECT * FROM test TIMEOUT 1s CACHE PARALLEL");
+}
+
+#[test]
+fn parse_select_with_deleted() {
+	let res = test_parse!(parse_stmt, r#"SELECT * FROM test WITH DELETED WHERE a"#).unwrap();
+	let Statement::Select(ref stmt) = res else {
+		panic!("expected a select statement")
+	};
+	assert!(stmt.deleted);
+	assert_eq!(stmt.with, None);
+	assert_eq!(res.to_string(), "SELECT * FROM test WITH DELETED WHERE a");
+	let res = test_parse!(parse_stmt, r#"SELECT * FROM test WITH DELETED WITH INDEX idx"#).unwrap();
+	let Statement::Select(ref stmt) = res else {
+		panic!("expected a select statement")
+	};
+	assert!(stmt.deleted);
+	assert_eq!(stmt.with, Some(With::Index(vec!["idx".to_owned()])));
+	assert_eq!(res.to_string(), "SELECT * FROM test WITH DELETED WITH INDEX idx");
+	// The deleted keyword can still be used as a table name
+	let res = test_parse!(parse_stmt, r#"SELECT * FROM deleted"#).unwrap();
+	assert_eq!(res.to_string(), "SELECT * FROM deleted");
+}
+
+#[test]
+fn parse_select_window() {
+	let res = test_parse!(
+		parse_stmt,
+		r#"SELECT *, math::sum(amount) OVER (ORDER BY time DESC) AS running FROM sale"#
+	)
+	.unwrap();
+	let Statement::Select(ref stmt) = res else {
+		panic!("expected a select statement")
+	};
+	assert_eq!(
+		stmt.expr.0[1],
+		Field::Window {
+			expr: Value::Function(Box::new(Function::Normal(
+				"math::sum".to_owned(),
+				vec![Value::Idiom(Idiom(vec![Part::Field(Ident("amount".to_owned()))]))]
+			))),
+			order: Some(Orders(vec![Order {
+				order: Idiom(vec![Part::Field(Ident("time".to_owned()))]),
+				random: false,
+				collate: false,
+				numeric: false,
+				direction: false,
+				nulls: None,
+			}])),
+			alias: Some(Idiom(vec![Part::Field(Ident("running".to_owned()))])),
+		}
+	);
+	assert_eq!(
+		res.to_string(),
+		"SELECT *, math::sum(amount) OVER (ORDER BY time DESC) AS running FROM sale"
+	);
+	let res = test_parse!(parse_stmt, r#"SELECT VALUE count() OVER () FROM sale"#).unwrap();
+	assert_eq!(res.to_string(), "SELECT VALUE count() OVER () FROM sale");
+	// Only aggregate functions can be computed over a window
+	test_parse!(parse_stmt, r#"SELECT string::len(name) OVER () FROM sale"#).unwrap_err();
+	// Windows can not be used with a GROUP clause
+	test_parse!(parse_stmt, r#"SELECT count() OVER () FROM sale GROUP ALL"#).unwrap_err();
+	// Windows are only parsed in select statements
+	test_parse!(parse_stmt, r#"UPDATE sale RETURN count() OVER ()"#).unwrap_err();
+	// Only rolling aggregates can be computed over an ordered window
+	test_parse!(parse_stmt, r#"SELECT time, math::median(amount) OVER (ORDER BY time) FROM sale"#)
+		.unwrap_err();
+	// Ordered windows can only be ordered by the selected fields
+	test_parse!(parse_stmt, r#"SELECT id, math::sum(amount) OVER (ORDER BY time) FROM sale"#)
+		.unwrap_err();
+}
+
+#[test]
+fn parse_select_filter() {
+	let res = test_parse!(
+		parse_stmt,
+		r#"SELECT region, count() FILTER (WHERE status = "open") AS open FROM sale GROUP BY region"#
+	)
+	.unwrap();
+	let Statement::Select(ref stmt) = res else {
+		panic!("expected a select statement")
+	};
+	assert_eq!(
+		stmt.expr.0[1],
+		Field::Filter {
+			expr: Value::Function(Box::new(Function::Normal("count".to_owned(), vec![]))),
+			cond: Cond(Value::Expression(Box::new(Expression::Binary {
+				l: Value::Idiom(Idiom(vec![Part::Field(Ident("status".to_owned()))])),
+				o: Operator::Equal,
+				r: Value::Strand(Strand("open".to_owned())),
+			}))),
+			alias: Some(Idiom(vec![Part::Field(Ident("open".to_owned()))])),
+		}
+	);
+	assert_eq!(
+		res.to_string(),
+		"SELECT region, count() FILTER (WHERE status = 'open') AS open FROM sale GROUP BY region"
+	);
+	let res = test_parse!(
+		parse_stmt,
+		r#"SELECT math::sum(amount) FILTER (WHERE eu) FROM sale GROUP ALL"#
+	)
+	.unwrap();
+	assert_eq!(res.to_string(), "SELECT math::sum(amount) FILTER (WHERE eu) FROM sale GROUP ALL");
+	// Only aggregate functions can be filtered
+	test_parse!(parse_stmt, r#"SELECT string::len(name) FILTER (WHERE eu) FROM sale GROUP ALL"#)
+		.unwrap_err();
+	// Filtered aggregates need a GROUP or HAVING clause
+	test_parse!(parse_stmt, r#"SELECT count() FILTER (WHERE eu) FROM sale"#).unwrap_err();
+	// Filtered aggregates can not be computed over a window
+	test_parse!(parse_stmt, r#"SELECT count() FILTER (WHERE eu) OVER () FROM sale GROUP ALL"#)
+		.unwrap_err();
+	// Filtered aggregates can not have the same name as another field
+	test_parse!(parse_stmt, r#"SELECT count(), count() FILTER (WHERE eu) FROM sale GROUP ALL"#)
+		.unwrap_err();
+	test_parse!(
+		parse_stmt,
+		r#"SELECT count() AS n, count() FILTER (WHERE eu) AS n FROM sale GROUP ALL"#
+	)
+	.unwrap_err();
+	test_parse!(
+		parse_stmt,
+		r#"SELECT count(), count() FILTER (WHERE eu) AS eu FROM sale GROUP ALL"#
+	)
+	.unwrap();
+	// Filtered aggregates are only parsed in select statements
+	test_parse!(parse_stmt, r#"UPDATE sale RETURN count() FILTER (WHERE eu)"#).unwrap_err();
+}
+
+#[test]
+fn parse_if_parallel() {
+	let res =
+		test_parse!(parse_stmt, r#"IF foo THEN bar ELSE IF faz THEN baz END PARALLEL"#).unwrap();
+	assert_eq!(
+		res,
+		Statement::Ifelse(IfelseStatement {
+			exprs: vec![
+				(
+					Value::Idiom(Idiom(vec![Part::Field(Ident("foo".to_owned()))])),
+					Value::Idiom(Idiom(vec![Part::Field(Ident("bar".to_owned()))]))
+				),
+				(
+					Value::Idiom(Idiom(vec![Part::Field(Ident("faz".to_owned()))])),
+					Value::Idiom(Idiom(vec![Part::Field(Ident("baz".to_owned()))]))
+				)
+			],
+			close: None,
+			parallel: true,
+			lets: Vec::new(),
+		})
+	);
+	assert_eq!(res.to_string(), "IF foo THEN bar ELSE IF faz THEN baz END PARALLEL");
+	// Within another statement, PARALLEL belongs to the enclosing statement
+	let res =
+		test_parse!(parse_stmt, r#"UPDATE t SET a = IF $x { 1 } ELSE { 2 } PARALLEL"#).unwrap();
+	let Statement::Update(ref stmt) = res else {
+		panic!("expected an update statement")
+	};
+	assert!(stmt.parallel);
+	assert_eq!(res.to_string(), "UPDATE t SET a = IF $x { 1 } ELSE { 2 } PARALLEL");
+}
+
+#[test]
+fn parse_if_lets() {
+	let res =
+		test_parse!(parse_stmt, r#"IF foo THEN bar ELSE IF LET $x = 1; $x THEN $x END"#).unwrap();
+	assert_eq!(
+		res,
+		Statement::Ifelse(IfelseStatement {
+			exprs: vec![
+				(
+					Value::Idiom(Idiom(vec![Part::Field(Ident("foo".to_owned()))])),
+					Value::Idiom(Idiom(vec![Part::Field(Ident("bar".to_owned()))]))
+				),
+				(
+					Value::Param(Param(Ident("x".to_owned()))),
+					Value::Param(Param(Ident("x".to_owned())))
+				)
+			],
+			close: None,
+			parallel: false,
+			lets: vec![
+				vec![],
+				vec![SetStatement {
+					name: "x".to_owned(),
+					what: Value::Number(Number::Int(1)),
+					kind: None,
+				}]
+			],
+		})
+	);
+	assert_eq!(res.to_string(), "IF foo THEN bar ELSE IF LET $x = 1; $x THEN $x END");
+}
+
+#[test]
+fn parse_case() {
+	let res = test_parse!(parse_stmt, r#"CASE foo WHEN 1 THEN bar WHEN 2 THEN baz ELSE baq END"#)
+		.unwrap();
+	assert_eq!(
+		res,
+		Statement::Value(Value::Subquery(Box::new(Subquery::Case(CaseStatement {
+			subject: Some(Value::Idiom(Idiom(vec![Part::Field(Ident("foo".to_owned()))]))),
+			exprs: vec![
+				(
+					Value::Number(Number::Int(1)),
+					Value::Idiom(Idiom(vec![Part::Field(Ident("bar".to_owned()))]))
+				),
+				(
+					Value::Number(Number::Int(2)),
+					Value::Idiom(Idiom(vec![Part::Field(Ident("baz".to_owned()))]))
+				)
+			],
+			close: Some(Value::Idiom(Idiom(vec![Part::Field(Ident("baq".to_owned()))])))
+		}))))
+	)
+}
+
+#[test]
+fn parse_case_without_subject() {
+	let res = test_parse!(parse_stmt, r#"CASE WHEN foo THEN bar END"#).unwrap();
+	assert_eq!(
+		res,
+		Statement::Value(Value::Subquery(Box::new(Subquery::Case(CaseStatement {
+			subject: None,
+			exprs: vec![(
+				Value::Idiom(Idiom(vec![Part::Field(Ident("foo".to_owned()))])),
+				Value::Idiom(Idiom(vec![Part::Field(Ident("bar".to_owned()))]))
+			)],
+			close: None,
+		}))))
+	)
+}
+
+#[test]
+fn parse_case_as_identifier() {
+	let res = test_parse!(parse_stmt, r#"SELECT case, case.id FROM case WHERE case = 1"#).unwrap();
+	assert_eq!(res.to_string(), "SELECT case, case.id FROM case WHERE case = 1");
+	let res = test_parse!(parse_stmt, r#"SELECT * FROM case:one ORDER BY case"#).unwrap();
+	assert_eq!(res.to_string(), "SELECT * FROM case:one ORDER BY case");
+	let sql = "SELECT case[0], case - 1, case < 5, case->likes FROM t";
+	let res = test_parse!(parse_stmt, sql).unwrap();
+	assert_eq!(res.to_string(), sql);
+	// CASE starts a case expression when followed by WHEN or a value
+	let res = test_parse!(parse_stmt, r#"SELECT CASE $x WHEN 1 THEN case END FROM t"#).unwrap();
+	assert_eq!(res.to_string(), "SELECT CASE $x WHEN 1 THEN case END FROM t");
+}
+
+#[test]
+fn parse_case_subjects() {
+	for sql in [
+		"RETURN CASE value WHEN 1 THEN 2 END",
+		"RETURN CASE type WHEN 'a' THEN 2 END",
+		"RETURN CASE id WHEN person:one THEN 2 END",
+		"RETURN CASE [1, 2] WHEN [1, 2] THEN 2 END",
+		"RETURN CASE -1 WHEN -1 THEN 2 END",
+		"RETURN CASE <string> $x WHEN '1' THEN 2 END",
+		"RETURN CASE <array<int>> $x WHEN [1] THEN 2 END",
+		"RETURN CASE ->likes->person WHEN [] THEN 2 END",
+	] {
+		let res = test_parse!(parse_stmt, sql).unwrap();
+		let Statement::Output(v) = res else {
+			panic!("expected a RETURN statement for {sql}");
+		};
+		assert!(
+			matches!(v.what, Value::Subquery(ref v) if matches!(**v, Subquery::Case(_))),
+			"{sql}"
+		);
+	}
+}
+
 #[test]
 fn parse_info() {
 	let res = test_parse!(parse_stmt, "INFO FOR ROOT").unwrap();
@@ -1676,6 +2197,7 @@ SELECT bar as foo,[1,2],bar OMIT bar FROM ONLY a,1
 				collate: true,
 				numeric: true,
 				direction: true,
+				nulls: None,
 			}])),
 			limit: Some(Limit(Value::Thing(Thing {
 				tb: "a".to_owned(),
@@ -1689,11 +2211,90 @@ SELECT bar as foo,[1,2],bar OMIT bar FROM ONLY a,1
 			timeout: None,
 			parallel: false,
 			tempfiles: false,
+			having: None,
+			distinct: None,
+			cache: None,
+			deleted: false,
+			cursor: None,
 			explain: Some(Explain(true)),
 		}),
 	);
 }
 
+#[test]
+fn parse_select_order_nulls() {
+	let res = test_parse!(
+		parse_stmt,
+		r#"SELECT * FROM person ORDER BY name COLLATE NULLS LAST, age DESC NULLS FIRST"#
+	)
+	.unwrap();
+	assert_eq!(
+		res,
+		Statement::Select(SelectStatement {
+			expr: Fields::all(),
+			what: Values(vec![Value::Table(Table("person".to_owned()))]),
+			order: Some(Orders(vec![
+				Order {
+					order: Idiom(vec![Part::Field(Ident("name".to_owned()))]),
+					random: false,
+					collate: true,
+					numeric: false,
+					direction: true,
+					nulls: Some(false),
+				},
+				Order {
+					order: Idiom(vec![Part::Field(Ident("age".to_owned()))]),
+					random: false,
+					collate: false,
+					numeric: false,
+					direction: false,
+					nulls: Some(true),
+				},
+			])),
+			..Default::default()
+		})
+	);
+	assert_eq!(
+		res.to_string(),
+		"SELECT * FROM person ORDER BY name COLLATE NULLS LAST, age DESC NULLS FIRST"
+	);
+}
+
+#[test]
+fn parse_explain() {
+	let res =
+		test_parse!(parse_stmt, r#"EXPLAIN FULL SELECT * FROM person WHERE age > 18"#).unwrap();
+	assert_eq!(
+		res,
+		Statement::Explain(ExplainStatement {
+			full: true,
+			what: SelectStatement {
+				expr: Fields::all(),
+				what: Values(vec![Value::Table(Table("person".to_owned()))]),
+				cond: Some(Cond(Value::Expression(Box::new(Expression::Binary {
+					l: Value::Idiom(Idiom(vec![Part::Field(Ident("age".to_owned()))])),
+					o: Operator::MoreThan,
+					r: Value::Number(Number::Int(18)),
+				})))),
+				..Default::default()
+			},
+		})
+	);
+	let res = test_parse!(parse_stmt, r#"EXPLAIN SELECT * FROM person"#).unwrap();
+	assert_eq!(
+		res,
+		Statement::Explain(ExplainStatement {
+			full: false,
+			what: SelectStatement {
+				expr: Fields::all(),
+				what: Values(vec![Value::Table(Table("person".to_owned()))]),
+				..Default::default()
+			},
+		})
+	);
+	test_parse!(parse_stmt, r#"EXPLAIN UPDATE person"#).unwrap_err();
+}
+
 #[test]
 fn parse_let() {
 	let res = test_parse!(parse_stmt, r#"LET $param = 1"#).unwrap();
@@ -1701,7 +2302,8 @@ fn parse_let() {
 		res,
 		Statement::Set(SetStatement {
 			name: "param".to_owned(),
-			what: Value::Number(Number::Int(1))
+			what: Value::Number(Number::Int(1)),
+			kind: None,
 		})
 	);
 
@@ -1710,9 +2312,37 @@ fn parse_let() {
 		res,
 		Statement::Set(SetStatement {
 			name: "param".to_owned(),
-			what: Value::Number(Number::Int(1))
+			what: Value::Number(Number::Int(1)),
+			kind: None,
+		})
+	);
+}
+
+#[test]
+fn parse_let_with_kind() {
+	let res = test_parse!(parse_stmt, r#"LET $param: int = '1'"#).unwrap();
+	assert_eq!(
+		res,
+		Statement::Set(SetStatement {
+			name: "param".to_owned(),
+			what: Value::Strand(Strand("1".to_owned())),
+			kind: Some(Kind::Int),
+		})
+	);
+	assert_eq!(res.to_string(), "LET $param: int = '1'");
+
+	let res = test_parse!(parse_stmt, r#"LET $param: option<datetime>"#).unwrap();
+	assert_eq!(
+		res,
+		Statement::Set(SetStatement {
+			name: "param".to_owned(),
+			what: Value::Param(Param::from("param")),
+			kind: Some(Kind::Option(Box::new(Kind::Datetime))),
 		})
 	);
+	assert_eq!(res.to_string(), "LET $param: option<datetime>");
+
+	test_parse!(parse_stmt, r#"LET $param"#).unwrap_err();
 }
 
 #[test]
@@ -2138,6 +2768,15 @@ fn parse_remove() {
 			if_exists: false,
 		}))
 	);
+
+	let res = test_parse!(parse_stmt, r#"REMOVE TASK IF EXISTS cleanup"#).unwrap();
+	assert_eq!(
+		res,
+		Statement::Remove(RemoveStatement::Task(RemoveTaskStatement {
+			name: Ident("cleanup".to_owned()),
+			if_exists: true,
+		}))
+	);
 }
 
 #[test]
@@ -2186,6 +2825,22 @@ fn parse_update() {
 	);
 }
 
+#[test]
+fn parse_update_merge_patch() {
+	let res = test_parse!(parse_stmt, r#"UPDATE a MERGE PATCH { b: NULL }"#).unwrap();
+	assert_eq!(
+		res,
+		Statement::Update(UpdateStatement {
+			what: Values(vec![Value::Table(Table("a".to_owned()))]),
+			data: Some(Data::MergePatchExpression(Value::Object(Object(
+				[("b".to_owned(), Value::Null)].into_iter().collect()
+			)))),
+			..Default::default()
+		})
+	);
+	assert_eq!(res.to_string(), "UPDATE a MERGE PATCH { b: NULL }");
+}
+
 #[test]
 fn parse_upsert() {
 	let res = test_parse!(
@@ -21,10 +21,10 @@ use crate::{
 		},
 		tokenizer::Tokenizer,
 		Algorithm, Array, Base, Block, Cond, Data, Datetime, Dir, Duration, Edges, Explain,
-		Expression, Fetch, Fetchs, Field, Fields, Future, Graph, Group, Groups, Id, Ident, Idiom,
-		Idioms, Index, Kind, Limit, Number, Object, Operator, Order, Orders, Output, Param, Part,
-		Permission, Permissions, Scoring, Split, Splits, Start, Statement, Strand, Subquery, Table,
-		TableType, Tables, Thing, Timeout, Uuid, Value, Values, Version, With,
+		Expression, Fetch, Fetchs, Field, Fields, Future, Graph, Group, Groups, Id, IdStrategy,
+		Ident, Idiom, Idioms, Index, Kind, Limit, Number, Object, Operator, Order, Orders, Output,
+		Param, Part, Permission, Permissions, Scoring, Split, Splits, Start, Statement, Strand,
+		Subquery, Table, TableType, Tables, Thing, Timeout, Uuid, Value, Values, Version, With,
 	},
 	syn::parser::{Parser, PartialResult},
 };
@@ -207,6 +207,7 @@ fn statements() -> Vec<Statement> {
 					}),
 					issue: None,
 				},
+				refresh: false,
 			}),
 			// Default durations.
 			duration: AccessDuration {
@@ -267,6 +268,9 @@ fn statements() -> Vec<Statement> {
 			comment: None,
 			if_not_exists: false,
 			kind: TableType::Any,
+			versioned: false,
+			id_strategy: IdStrategy::Rand,
+			soft_delete: false,
 		})),
 		Statement::Define(DefineStatement::Event(DefineEventStatement {
 			name: Ident("event".to_owned()),
@@ -292,6 +296,7 @@ fn statements() -> Vec<Statement> {
 			readonly: false,
 			value: Some(Value::Null),
 			assert: Some(Value::Bool(true)),
+			assert_message: None,
 			default: Some(Value::Bool(false)),
 			permissions: Permissions {
 				delete: Permission::None,
@@ -431,6 +436,8 @@ fn statements() -> Vec<Statement> {
 				),
 			],
 			close: Some(Value::Idiom(Idiom(vec![Part::Field(Ident("baq".to_owned()))]))),
+			parallel: false,
+			lets: Vec::new(),
 		}),
 		Statement::Ifelse(IfelseStatement {
 			exprs: vec![
@@ -450,6 +457,8 @@ fn statements() -> Vec<Statement> {
 			close: Some(Value::Block(Box::new(Block(vec![Entry::Value(Value::Idiom(Idiom(
 				vec![Part::Field(Ident("baq".to_owned()))],
 			)))])))),
+			parallel: false,
+			lets: Vec::new(),
 		}),
 		Statement::Info(InfoStatement::Root(false)),
 		Statement::Info(InfoStatement::Ns(false)),
@@ -494,6 +503,7 @@ fn statements() -> Vec<Statement> {
 				collate: true,
 				numeric: true,
 				direction: true,
+				nulls: None,
 			}])),
 			limit: Some(Limit(Value::Thing(Thing {
 				tb: "a".to_owned(),
@@ -507,11 +517,17 @@ fn statements() -> Vec<Statement> {
 			timeout: None,
 			parallel: false,
 			tempfiles: false,
+			having: None,
+			distinct: None,
+			cache: None,
+			deleted: false,
+			cursor: None,
 			explain: Some(Explain(true)),
 		}),
 		Statement::Set(SetStatement {
 			name: "param".to_owned(),
 			what: Value::Number(Number::Int(1)),
+			kind: None,
 		}),
 		Statement::Show(ShowStatement {
 			table: Some(Table("foo".to_owned())),
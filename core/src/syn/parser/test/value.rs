@@ -172,3 +172,12 @@ fn scientific_number() {
 fn empty_string() {
 	test_parse!(parse_value, "").unwrap_err();
 }
+
+#[test]
+fn matches_keyword() {
+	let expected = test_parse!(parse_value, r#" title @@ 'fox' "#).unwrap();
+	let out = test_parse!(parse_value, r#" title MATCHES 'fox' "#).unwrap();
+	assert_eq!(out, expected);
+	let out = test_parse!(parse_value, r#" title match 'fox' "#).unwrap();
+	assert_eq!(out, expected);
+}
@@ -41,8 +41,10 @@ keyword! {
 	Bm25 => "BM25",
 	Break => "BREAK",
 	By => "BY",
+	Cache => "CACHE",
 	Camel => "CAMEL",
 	Cancel => "CANCEL",
+	Case => "CASE",
 	ChangeFeed => "CHANGEFEED",
 	Changes => "CHANGES",
 	Capacity => "CAPACITY",
@@ -56,10 +58,13 @@ keyword! {
 	Default => "DEFAULT",
 	Define => "DEFINE",
 	Delete => "DELETE",
+	Deleted => "DELETED",
+	Depth => "DEPTH",
 	Descending => "DESCENDING",
 	Diff => "DIFF",
 	Dimension => "DIMENSION",
 	Distance => "DISTANCE",
+	Distinct => "DISTINCT",
 	DocIdsCache => "DOC_IDS_CACHE",
 	DocIdsOrder => "DOC_IDS_ORDER",
 	DocLengthsCache => "DOC_LENGTHS_CACHE",
@@ -69,6 +74,7 @@ keyword! {
 	Efc => "EFC",
 	Edgengram => "EDGENGRAM",
 	Event => "EVENT",
+	Every => "EVERY",
 	Else => "ELSE",
 	End => "END",
 	Exists => "EXISTS",
@@ -78,7 +84,9 @@ keyword! {
 	Fetch => "FETCH",
 	Field => "FIELD",
 	Fields => "FIELDS",
+	Filter => "FILTER",
 	Filters => "FILTERS",
+	First => "FIRST",
 	Flexible => "FLEXIBLE",
 	For => "FOR",
 	From => "FROM",
@@ -86,10 +94,13 @@ keyword! {
 	Function => "FUNCTION",
 	Grant => "GRANT",
 	Group => "GROUP",
+	Having => "HAVING",
 	Highlights => "HIGHLIGHTS",
 	Hnsw => "HNSW",
+	Id => "ID",
 	Ignore => "IGNORE",
 	Include => "INCLUDE",
+	Increment => "INCREMENT",
 	Index => "INDEX",
 	Info => "INFO",
 	Insert => "INSERT",
@@ -102,6 +113,7 @@ keyword! {
 	Key => "KEY",
 	KeepPrunedConnections => "KEEP_PRUNED_CONNECTIONS",
 	Kill => "KILL",
+	Last => "LAST",
 	Let => "LET",
 	Limit => "LIMIT",
 	Live => "LIVE",
@@ -119,12 +131,14 @@ keyword! {
 	NoIndex => "NOINDEX",
 	None => "NONE",
 	Null => "NULL",
+	Nulls => "NULLS",
 	Numeric => "NUMERIC",
 	Omit => "OMIT",
 	On => "ON",
 	Only => "ONLY",
 	Option => "OPTION",
 	Order => "ORDER",
+	Over => "OVER",
 	Original => "ORIGINAL",
 	Parallel => "PARALLEL",
 	Param => "PARAM",
@@ -137,13 +151,18 @@ keyword! {
 	Punct => "PUNCT",
 	Readonly => "READONLY",
 	Rebuild => "REBUILD",
+	Refresh => "REFRESH",
 	Relate => "RELATE",
 	Relation => "RELATION",
+	Release => "RELEASE",
 	Remove => "REMOVE",
 	Replace => "REPLACE",
+	Restore => "RESTORE",
 	Return => "RETURN",
 	Roles => "ROLES",
+	Rollback => "ROLLBACK",
 	Root => "ROOT",
+	Savepoint => "SAVEPOINT",
 	Schemafull => "SCHEMAFULL",
 	Schemaless => "SCHEMALESS",
 	Scope => "SCOPE",
@@ -157,10 +176,13 @@ keyword! {
 	Since => "SINCE",
 	Sleep => "SLEEP",
 	Snowball => "SNOWBALL",
+	Snowflake => "SNOWFLAKE",
+	Soft => "SOFT",
 	Split => "SPLIT",
 	Start => "START",
 	Structure => "STRUCTURE",
 	Table => "TABLE",
+	Task => "TASK",
 	TempFiles => "TEMPFILES",
 	TermsCache => "TERMS_CACHE",
 	TermsOrder => "TERMS_ORDER",
@@ -184,6 +206,7 @@ keyword! {
 	Value => "VALUE",
 	Values => "VALUES",
 	Version => "VERSION",
+	Versioned => "VERSIONED",
 	Vs => "VS",
 	When => "WHEN",
 	Where => "WHERE",
@@ -193,6 +216,7 @@ keyword! {
 	AnyInside => "ANYINSIDE",
 	Inside => "INSIDE",
 	Intersects => "INTERSECTS",
+	Matches => "MATCHES",
 	NoneInside => "NONEINSIDE",
 	NotInside => "NOTINSIDE",
 	OrKw => "OR",
@@ -33,6 +33,38 @@ fn bench_processor(c: &mut Criterion) {
 			.iter(|| run(&i, "SELECT * FROM item WHERE number=4 PARALLEL", i.count))
 	});
 
+	group.bench_function("table-iterator-where", |b| {
+		b.to_async(Runtime::new().unwrap())
+			.iter(|| run(&i, "SELECT * FROM item WITH NOINDEX WHERE number=4", i.count))
+	});
+
+	group.bench_function("non-uniq-index-range-iterator", |b| {
+		b.to_async(Runtime::new().unwrap())
+			.iter(|| run(&i, "SELECT * FROM item WHERE number>2", i.count * 2))
+	});
+
+	group.bench_function("compound-index-prefix-iterator", |b| {
+		b.to_async(Runtime::new().unwrap())
+			.iter(|| run(&i, "SELECT * FROM item WHERE tag='echo'", i.count))
+	});
+
+	// Counted from the keys, without fetching the records
+	group.bench_function("count-table", |b| {
+		b.to_async(Runtime::new().unwrap())
+			.iter(|| run(&i, "SELECT count() FROM item GROUP ALL", 1))
+	});
+
+	// Every record is fetched and processed to evaluate the argument
+	group.bench_function("count-table-processed", |b| {
+		b.to_async(Runtime::new().unwrap())
+			.iter(|| run(&i, "SELECT count(true) FROM item GROUP ALL", 1))
+	});
+
+	group.bench_function("count-index", |b| {
+		b.to_async(Runtime::new().unwrap())
+			.iter(|| run(&i, "SELECT count() FROM item WHERE number=4 GROUP ALL", 1))
+	});
+
 	group.bench_function("full-text-index-iterator", |b| {
 		b.to_async(Runtime::new().unwrap())
 			.iter(|| run(&i, "SELECT * FROM item WHERE label @@ 'charlie'", i.count))
@@ -55,12 +87,14 @@ struct Input {
 async fn prepare_data() -> Input {
 	let dbs = Datastore::new("memory").await.unwrap();
 	let ses = Session::owner().with_ns("bench").with_db("bench");
-	let sql = r"DEFINE INDEX number ON item FIELDS number;
+	let sql = r"DEFINE FIELD number ON item TYPE int;
+		DEFINE INDEX number ON item FIELDS number;
+		DEFINE INDEX tag_number ON item FIELDS tag, number;
 		DEFINE ANALYZER simple TOKENIZERS blank,class;
 		DEFINE INDEX search ON item FIELDS label SEARCH ANALYZER simple BM25"
 		.to_owned();
 	let res = &mut dbs.execute(&sql, &ses, None).await.unwrap();
-	for _ in 0..3 {
+	for _ in 0..5 {
 		assert!(res.remove(0).result.is_ok());
 	}
 
@@ -78,11 +112,11 @@ async fn prepare_data() -> Input {
 		let d = j + 3;
 		let e = j + 4;
 		let sql = format!(
-			r"CREATE item SET id = {a}, name = '{a}', number = 0, label='alpha';
-		CREATE item SET id = {b}, name = '{b}', number = 1, label='bravo';
-		CREATE item SET id = {c}, name = '{c}', number = 2, label='charlie';
-		CREATE item SET id = {d}, name = '{d}', number = 3, label='delta';
-		CREATE item SET id = {e}, name = '{e}', number = 4, label='echo';",
+			r"CREATE item SET id = {a}, name = '{a}', number = 0, label='alpha', tag='alpha';
+		CREATE item SET id = {b}, name = '{b}', number = 1, label='bravo', tag='bravo';
+		CREATE item SET id = {c}, name = '{c}', number = 2, label='charlie', tag='charlie';
+		CREATE item SET id = {d}, name = '{d}', number = 3, label='delta', tag='delta';
+		CREATE item SET id = {e}, name = '{e}', number = 4, label='echo', tag='echo';",
 		);
 		let res = &mut dbs.execute(&sql, &ses, None).await.unwrap();
 		for _ in 0..5 {
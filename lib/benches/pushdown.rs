@@ -0,0 +1,85 @@
+use criterion::{black_box, criterion_group, criterion_main, Criterion, Throughput};
+use surrealdb::dbs::Session;
+use surrealdb::kvs::Datastore;
+use surrealdb::sql::Value;
+use tokio::runtime::Runtime;
+
+/// The number of records in the table
+const RECORDS: usize = 1_000;
+
+// The comparison is checked while each record is decoded, skipping 90% of the records
+const SELECTIVE: &str = "SELECT id, name FROM order WHERE status = 'refunded'";
+// The same records are selected, but the first condition has to be computed,
+// so the comparison can not be checked until each record is fully decoded
+const COMPUTED: &str =
+	"SELECT id, name FROM order WHERE string::len(status) > 0 AND status = 'refunded'";
+// The comparison is checked while decoding, but most of the records match
+const UNSELECTIVE: &str = "SELECT id, name FROM order WHERE status != 'refunded'";
+
+fn bench_pushdown(c: &mut Criterion) {
+	let rt = Runtime::new().unwrap();
+	let (dbs, ses) = rt.block_on(prepare_data());
+
+	let mut group = c.benchmark_group("pushdown");
+	group.throughput(Throughput::Elements(RECORDS as u64));
+	group.sample_size(10);
+
+	group.bench_function("selective-comparison", |b| {
+		b.to_async(Runtime::new().unwrap()).iter(|| run(&dbs, &ses, SELECTIVE, RECORDS / 10))
+	});
+
+	group.bench_function("computed-condition", |b| {
+		b.to_async(Runtime::new().unwrap()).iter(|| run(&dbs, &ses, COMPUTED, RECORDS / 10))
+	});
+
+	group.bench_function("unselective-comparison", |b| {
+		b.to_async(Runtime::new().unwrap())
+			.iter(|| run(&dbs, &ses, UNSELECTIVE, RECORDS - RECORDS / 10))
+	});
+
+	group.finish();
+}
+
+async fn prepare_data() -> (Datastore, Session) {
+	let dbs = Datastore::new("memory").await.unwrap();
+	let ses = Session::owner().with_ns("bench").with_db("bench");
+	for i in 0..RECORDS {
+		let status = match i % 10 {
+			0 => "refunded",
+			1..=3 => "pending",
+			_ => "shipped",
+		};
+		let sql = format!(
+			"CREATE order:{i} CONTENT {{
+				name: 'Order {i}',
+				created: time::now(),
+				customer: {{ name: 'Customer {i}', email: 'customer{i}@example.com' }},
+				items: [
+					{{ sku: 'A-{i}', quantity: 1, price: 9.99 }},
+					{{ sku: 'B-{i}', quantity: 2, price: 24.5 }},
+				],
+				shipping: {{ street: '{i} Long Street', city: 'London', postcode: 'SW1A 1AA' }},
+				status: '{status}',
+				total: 58.99,
+			}}"
+		);
+		let res = &mut dbs.execute(&sql, &ses, None).await.unwrap();
+		assert!(res.remove(0).result.is_ok());
+	}
+	(dbs, ses)
+}
+
+async fn run(dbs: &Datastore, ses: &Session, sql: &str, expected: usize) {
+	let mut r = dbs.execute(black_box(sql), ses, None).await.unwrap();
+	if cfg!(debug_assertions) {
+		if let Value::Array(a) = r.remove(0).result.unwrap() {
+			assert_eq!(a.len(), expected);
+		} else {
+			panic!("Fail");
+		}
+	}
+	black_box(r);
+}
+
+criterion_group!(benches, bench_pushdown);
+criterion_main!(benches);
@@ -0,0 +1,75 @@
+use criterion::{black_box, criterion_group, criterion_main, Criterion, Throughput};
+use futures::Future;
+use pprof::criterion::{Output, PProfProfiler};
+use std::collections::BTreeMap;
+use surrealdb::{
+	dbs::{Capabilities, Session},
+	kvs::Datastore,
+	sql::{self, Value},
+};
+
+const SETUP: &str =
+	"CREATE person:one SET age = 30, name = 'One'; CREATE person:two SET age = 40, name = 'Two';";
+
+const QUERY: &str = "
+	LET $min = $age - 10;
+	SELECT id, name, age, age > $min AS older, string::uppercase(name) AS upper
+		FROM person
+		WHERE age >= $min AND name != 'Three'
+		ORDER BY age DESC
+		LIMIT 10;
+";
+
+#[tokio::main]
+async fn block_on<T>(future: impl Future<Output = T>) -> T {
+	future.await
+}
+
+fn setup() -> (Datastore, Session) {
+	block_on(async {
+		let dbs = Datastore::new("memory").await.unwrap().with_capabilities(Capabilities::all());
+		let ses = Session::owner().with_ns("test").with_db("test");
+		dbs.execute(SETUP, &ses, None).await.unwrap();
+		(dbs, ses)
+	})
+}
+
+fn vars(age: i64) -> Option<BTreeMap<String, Value>> {
+	Some([("age".to_owned(), Value::from(age))].into())
+}
+
+fn bench_query_cache(c: &mut Criterion) {
+	let mut c = c.benchmark_group("query_cache");
+	c.throughput(Throughput::Elements(1));
+	// The query text is parsed once, and the cached query is reused
+	c.bench_function("cached", |b| {
+		let (dbs, ses) = setup();
+		let mut age = 0;
+		b.iter(|| {
+			age += 1;
+			block_on(async {
+				black_box(dbs.execute(black_box(QUERY), &ses, vars(age)).await).unwrap();
+			});
+		})
+	});
+	// The query text is parsed on every execution
+	c.bench_function("uncached", |b| {
+		let (dbs, ses) = setup();
+		let mut age = 0;
+		b.iter(|| {
+			age += 1;
+			block_on(async {
+				let ast = sql::parse(black_box(QUERY)).unwrap();
+				black_box(dbs.process(ast, &ses, vars(age)).await).unwrap();
+			});
+		})
+	});
+	c.finish();
+}
+
+criterion_group!(
+	name = benches;
+	config = Criterion::default().with_profiler(PProfProfiler::new(1000, Output::Flamegraph(None)));
+	targets = bench_query_cache
+);
+criterion_main!(benches);
@@ -78,7 +78,7 @@ pub fn start_tasks(opt: &EngineOptions, dbs: Arc<Datastore>) -> (Tasks, [Sender<
 
 // The init starts a long-running thread for periodically calling Datastore.tick.
 // Datastore.tick is responsible for running garbage collection and other
-// background tasks.
+// background tasks, and each scheduled DEFINE TASK run is spawned alongside.
 //
 // This function needs to be called before after the dbs::init and before the net::init functions.
 // It needs to be before net::init because the net::init function blocks until the web server stops.
@@ -112,6 +112,11 @@ fn init(opt: &EngineOptions, dbs: Arc<Datastore>) -> (FutureTask, Sender<()>) {
 				error!("Error running node agent tick: {}", e);
 				break;
 			}
+			// Scheduled tasks run separately, so that a slow task does not hold up the tick
+			for task in dbs.claim_tasks().await {
+				let dbs = dbs.clone();
+				spawn_future(async move { dbs.run_task(task).await });
+			}
 		}
 
 		#[cfg(target_arch = "wasm32")]
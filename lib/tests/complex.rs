@@ -206,6 +206,38 @@ fn excessive_cast_chain_depth() -> Result<(), Error> {
 	})
 }
 
+#[test]
+fn cyclic_events() -> Result<(), Error> {
+	// Ensure a good stack size for tests
+	with_enough_stack(async {
+		let mut res = run_queries(
+			"
+			DEFINE EVENT ping ON ping WHEN $event = 'CREATE' THEN (CREATE pong);
+			DEFINE EVENT pong ON pong WHEN $event = 'CREATE' THEN (CREATE ping);
+			CREATE ping;
+			SELECT * FROM ping, pong;
+			",
+		)
+		.await?;
+		//
+		assert_eq!(res.len(), 4);
+		//
+		for _ in 0..2 {
+			let tmp = res.next().unwrap();
+			assert!(tmp.is_ok());
+		}
+		//
+		let tmp = res.next().unwrap();
+		assert!(matches!(tmp, Err(Error::ComputationDepthExceeded)), "found {:?}", tmp);
+		// The cascade is rolled back along with the triggering write
+		let tmp = res.next().unwrap()?;
+		let val = Value::parse("[]");
+		assert_eq!(tmp, val);
+		//
+		Ok(())
+	})
+}
+
 async fn run_queries(
 	sql: &str,
 ) -> Result<
@@ -2,9 +2,13 @@ mod parse;
 use parse::Parse;
 mod helpers;
 use helpers::new_ds;
+use std::collections::HashSet;
+use std::sync::Arc;
 use surrealdb::dbs::Session;
 use surrealdb::err::Error;
 use surrealdb::iam::Role;
+use surrealdb::kvs::Datastore;
+use surrealdb::sql::Id;
 use surrealdb::sql::Part;
 use surrealdb::sql::Thing;
 use surrealdb::sql::Value;
@@ -265,6 +269,83 @@ async fn create_or_insert_with_permissions() -> Result<(), Error> {
 	Ok(())
 }
 
+#[tokio::test]
+async fn create_batch_from_array_content() -> Result<(), Error> {
+	let sql = "
+		CREATE person CONTENT [
+			{ id: person:tobie, name: 'Tobie' },
+			{ id: person:jaime, name: 'Jaime' },
+		];
+		CREATE person CONTENT [{ name: 'One' }, { name: 'Two' }, { name: 'Three' }];
+		CREATE person CONTENT $people;
+		SELECT count() FROM person GROUP ALL;
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let vars = [("people".to_owned(), Value::parse("[{ name: 'Four' }, { name: 'Five' }]"))];
+	let res = &mut dbs.execute(sql, &ses, Some(vars.into())).await?;
+	assert_eq!(res.len(), 4);
+	// Explicit ids are used for each record
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{ id: person:tobie, name: 'Tobie' },
+			{ id: person:jaime, name: 'Jaime' },
+		]",
+	);
+	assert_eq!(tmp, val);
+	// A distinct id is generated for each record
+	let tmp = res.remove(0).result?;
+	let Value::Array(v) = tmp else {
+		panic!("Expected an array of records, found {tmp}");
+	};
+	assert_eq!(v.len(), 3);
+	let ids: HashSet<_> = v.iter().map(|v| v.pick(&[Part::from("id")])).collect();
+	assert_eq!(ids.len(), 3);
+	assert!(ids.iter().all(|v| matches!(v, Value::Thing(t) if t.tb == "person")));
+	//
+	let tmp = res.remove(0).result?;
+	let Value::Array(v) = tmp else {
+		panic!("Expected an array of records, found {tmp}");
+	};
+	assert_eq!(v.len(), 2);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ count: 7 }]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn create_batch_rolls_back_on_invalid_record() -> Result<(), Error> {
+	let sql = "
+		DEFINE FIELD age ON person TYPE int;
+		CREATE person CONTENT [
+			{ id: person:one, age: 30 },
+			{ id: person:two, age: 'thirty' },
+			{ id: person:three, age: 40 },
+		];
+		SELECT * FROM person;
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 3);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(Error::FieldCheck { .. })), "Expected a field error: {tmp:?}");
+	// None of the records in the batch were created
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
 #[tokio::test]
 async fn create_on_none_values_with_unique_index() -> Result<(), Error> {
 	let sql = "
@@ -447,6 +528,94 @@ async fn create_with_subquery_execution() -> Result<(), Error> {
 	Ok(())
 }
 
+async fn create_ids(
+	dbs: &Datastore,
+	ses: &Session,
+	tb: &str,
+	count: usize,
+) -> Result<Vec<Id>, Error> {
+	let sql = format!("CREATE ONLY {tb} RETURN VALUE id;").repeat(count);
+	let res = dbs.execute(&sql, ses, None).await?;
+	let mut ids = Vec::with_capacity(count);
+	for res in res {
+		match res.result? {
+			Value::Thing(v) if v.tb == tb => ids.push(v.id),
+			v => panic!("Expected a record id, found {v}"),
+		}
+	}
+	Ok(ids)
+}
+
+#[tokio::test]
+async fn create_with_id_strategy() -> Result<(), Error> {
+	let sql = "
+		DEFINE TABLE seq ID INCREMENT;
+		DEFINE TABLE flake ID SNOWFLAKE;
+		DEFINE TABLE sortable ID ULID;
+		DEFINE TABLE uuids ID UUID;
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	for res in dbs.execute(sql, &ses, None).await? {
+		res.result?;
+	}
+	// Time-ordered ids sort in the order in which they were created
+	for tb in ["flake", "sortable", "uuids"] {
+		let ids = create_ids(&dbs, &ses, tb, 100).await?;
+		assert!(ids.windows(2).all(|v| v[0] < v[1]), "{tb} ids are not sorted: {ids:?}");
+	}
+	let ids = create_ids(&dbs, &ses, "flake", 1).await?;
+	assert!(matches!(ids[0], Id::Number(v) if v > 0), "{ids:?}");
+	// Incrementing ids start from one
+	let ids = create_ids(&dbs, &ses, "seq", 3).await?;
+	assert_eq!(ids, vec![Id::from(1), Id::from(2), Id::from(3)]);
+	// Explicit ids are used as they are, without affecting the counter
+	let res = &mut dbs.execute("CREATE seq:10; CREATE seq:4", &ses, None).await?;
+	assert_eq!(res.remove(0).result?, Value::parse("[{ id: seq:10 }]"));
+	assert_eq!(res.remove(0).result?, Value::parse("[{ id: seq:4 }]"));
+	// Ids which are already in use are skipped
+	let ids = create_ids(&dbs, &ses, "seq", 7).await?;
+	assert_eq!(ids, [5, 6, 7, 8, 9, 11, 12].map(Id::from));
+	// Tables without a strategy use random ids
+	let ids = create_ids(&dbs, &ses, "other", 1).await?;
+	assert!(matches!(&ids[0], Id::String(v) if v.len() == 20), "{ids:?}");
+	Ok(())
+}
+
+#[tokio::test(flavor = "multi_thread", worker_threads = 4)]
+async fn create_with_id_strategy_concurrently() -> Result<(), Error> {
+	let sql = "
+		DEFINE TABLE seq ID INCREMENT;
+		DEFINE TABLE flake ID SNOWFLAKE;
+		DEFINE TABLE sortable ID ULID;
+		DEFINE TABLE uuids ID UUID;
+	";
+	let dbs = Arc::new(new_ds().await?);
+	let ses = Session::owner().with_ns("test").with_db("test");
+	for res in dbs.execute(sql, &ses, None).await? {
+		res.result?;
+	}
+	for tb in ["seq", "flake", "sortable", "uuids"] {
+		let tasks: Vec<_> = (0..8)
+			.map(|_| {
+				let (dbs, ses) = (dbs.clone(), ses.clone());
+				tokio::spawn(async move { create_ids(&dbs, &ses, tb, 25).await })
+			})
+			.collect();
+		let mut ids = HashSet::new();
+		for task in tasks {
+			ids.extend(task.await.unwrap()?);
+		}
+		// Every record was given a different id
+		assert_eq!(ids.len(), 200, "{tb} ids are not unique");
+		if tb == "seq" {
+			// No incrementing ids were skipped or repeated
+			assert_eq!(ids, (1..=200).map(Id::from).collect::<HashSet<Id>>());
+		}
+	}
+	Ok(())
+}
+
 //
 // Permissions
 //
@@ -472,6 +472,58 @@ async fn define_statement_event_when_logic() -> Result<(), Error> {
 	Ok(())
 }
 
+#[tokio::test]
+async fn define_statement_event_maintains_counter() -> Result<(), Error> {
+	let sql = "
+		DEFINE EVENT post_created ON post WHEN $event = 'CREATE' THEN (UPSERT stats:posts SET total += 1);
+		DEFINE EVENT post_deleted ON post WHEN $event = 'DELETE' THEN (UPSERT stats:posts SET total -= 1);
+		DEFINE EVENT post_guard ON post WHEN $event = 'CREATE' AND $after.title = NONE THEN {
+			THROW 'A post needs a title';
+		};
+		CREATE post:1 SET title = 'one';
+		CREATE post:2 SET title = 'two';
+		CREATE post:3;
+		DELETE post:1;
+		SELECT * FROM stats:posts;
+		SELECT VALUE id FROM post;
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 9);
+	//
+	for _ in 0..5 {
+		let tmp = res.remove(0).result;
+		assert!(tmp.is_ok());
+	}
+	// A failing event rolls back the triggering write
+	let tmp = res.remove(0).result;
+	assert!(matches!(
+		tmp.err(),
+		Some(e) if e.to_string() == "An error occurred: A post needs a title"
+	));
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	// The counter only reflects the committed writes
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				id: stats:posts,
+				total: 1,
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[post:2]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
 #[tokio::test]
 async fn define_statement_field() -> Result<(), Error> {
 	let sql = "
@@ -42,6 +42,241 @@ async fn delete() -> Result<(), Error> {
 	Ok(())
 }
 
+#[tokio::test]
+async fn delete_soft() -> Result<(), Error> {
+	let sql = "
+		DEFINE TABLE person SOFT DELETE SCHEMAFULL;
+		DEFINE FIELD name ON person TYPE string;
+		CREATE person:one SET name = 'One';
+		CREATE person:two SET name = 'Two';
+		DELETE person:one;
+		SELECT * FROM person;
+		SELECT * FROM person:one;
+		SELECT * FROM person WITH DELETED;
+		DELETE person:one RETURN BEFORE;
+		RESTORE person;
+		SELECT * FROM person;
+		RESTORE person;
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 12);
+	//
+	for _ in 0..4 {
+		res.remove(0).result?;
+	}
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	// Soft-deleted records are hidden by default
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:two, name: 'Two' }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	// Soft-deleted records are visible with WITH DELETED
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{ id: person:one, name: 'One' },
+			{ id: person:two, name: 'Two' },
+		]",
+	);
+	assert_eq!(tmp, val);
+	// Soft-deleted records can not be deleted again
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	// Only soft-deleted records are restored
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:one, name: 'One' }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{ id: person:one, name: 'One' },
+			{ id: person:two, name: 'Two' },
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn delete_soft_info() -> Result<(), Error> {
+	let sql = "
+		DEFINE TABLE person SOFT DELETE;
+		INFO FOR DB;
+		CREATE person:test;
+		DELETE ONLY person:test RETURN AFTER;
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 4);
+	//
+	res.remove(0).result?;
+	//
+	let tmp = res.remove(0).result?;
+	assert!(tmp.to_string().contains("SOFT DELETE"), "{tmp}");
+	//
+	res.remove(0).result?;
+	// The deletion marker is not visible
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("{ id: person:test }");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn delete_soft_hides_record_from_writes() -> Result<(), Error> {
+	let sql = "
+		DEFINE TABLE person SOFT DELETE;
+		DEFINE INDEX email ON person FIELDS email UNIQUE;
+		CREATE person:one SET email = 'one@example.com', deleted_at = NULL;
+		CREATE person:two SET email = 'two@example.com';
+		DELETE person:one, person:two;
+		UPDATE person:one SET name = 'Updated';
+		UPDATE person SET name = 'Updated';
+		CREATE person:three SET email = 'one@example.com';
+		CREATE person:two SET email = 'new@example.com';
+		UPSERT person:one SET name = 'Upserted';
+		SELECT * FROM person WITH DELETED;
+		RESTORE person:three;
+		RESTORE person;
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 13);
+	//
+	for _ in 0..2 {
+		res.remove(0).result?;
+	}
+	// A user field named deleted_at does not hide the record
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:one, email: 'one@example.com', deleted_at: NULL }]");
+	assert_eq!(tmp, val);
+	//
+	for _ in 0..2 {
+		res.remove(0).result?;
+	}
+	// Soft-deleted records are not updated
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	// Soft-deleted records are removed from unique indexes
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:three, email: 'one@example.com' }]");
+	assert_eq!(tmp, val);
+	// Soft-deleted records can be replaced
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:two, email: 'new@example.com' }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:one, name: 'Upserted' }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{ id: person:one, name: 'Upserted' },
+			{ id: person:three, email: 'one@example.com' },
+			{ id: person:two, email: 'new@example.com' },
+		]",
+	);
+	assert_eq!(tmp, val);
+	// Records which are not soft-deleted are not restored
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn delete_soft_restore_checks_unique_indexes() -> Result<(), Error> {
+	let sql = "
+		DEFINE TABLE person SOFT DELETE;
+		DEFINE INDEX email ON person FIELDS email UNIQUE;
+		CREATE person:one SET email = 'one@example.com';
+		DELETE person:one;
+		CREATE person:two SET email = 'one@example.com';
+		RESTORE person:one;
+		DELETE person:two;
+		RESTORE person:one;
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 8);
+	//
+	for _ in 0..5 {
+		res.remove(0).result?;
+	}
+	// The restored record must not conflict with other records
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(Error::IndexExists { .. })), "{tmp:?}");
+	//
+	res.remove(0).result?;
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:one, email: 'one@example.com' }]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn delete_soft_live_notification() -> Result<(), Error> {
+	let dbs = new_ds().await?.with_notifications();
+	let ses = Session::owner().with_ns("test").with_db("test").with_rt(true);
+	let sql = "
+		DEFINE TABLE person SOFT DELETE;
+		CREATE person:test SET name = 'Tester';
+	";
+	for r in dbs.execute(sql, &ses, None).await? {
+		r.result?;
+	}
+	let res = &mut dbs.execute("LIVE SELECT * FROM person", &ses, None).await?;
+	let live_id = match res.remove(0).result? {
+		Value::Uuid(id) => id,
+		_ => panic!("expected uuid"),
+	};
+	let notifications = dbs.notifications().expect("expected notifications");
+	// Soft-deleting a record sends a DELETE notification
+	dbs.execute("DELETE person:test", &ses, None).await?.remove(0).result?;
+	let notification = notifications.recv().await.unwrap();
+	let val = Value::parse("{ id: person:test, name: 'Tester' }");
+	assert_eq!(notification, Notification::new(live_id, Action::Delete, val.clone()));
+	// Restoring a record sends a CREATE notification
+	dbs.execute("RESTORE person:test", &ses, None).await?.remove(0).result?;
+	let notification = notifications.recv().await.unwrap();
+	assert_eq!(notification, Notification::new(live_id, Action::Create, val));
+	//
+	Ok(())
+}
+
 //
 // Permissions
 //
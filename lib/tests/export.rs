@@ -0,0 +1,411 @@
+mod parse;
+use parse::Parse;
+mod helpers;
+use helpers::new_ds;
+use surrealdb::channel;
+use surrealdb::dbs::Session;
+use surrealdb::err::Error;
+use surrealdb::kvs::{CsvOptions, Datastore, ExportFormat, ImportConflict, ImportOptions};
+use surrealdb::sql::{Kind, Value};
+
+const SCHEMA_AND_DATA: &str = "
+	DEFINE TABLE person SCHEMAFULL;
+	DEFINE FIELD name ON person TYPE string;
+	DEFINE INDEX person_name ON person FIELDS name UNIQUE;
+	DEFINE TABLE likes TYPE RELATION;
+	CREATE person:a SET name = 'Alice';
+	CREATE person:b SET name = 'Bob';
+	RELATE person:a->likes->person:b SET id = likes:1, weight = 5;
+";
+
+async fn export(ds: &Datastore, ses: &Session, fmt: ExportFormat) -> Result<String, Error> {
+	let (snd, rcv) = channel::unbounded();
+	ds.export_with_format(ses, snd, fmt).await?.await?;
+	let mut out = Vec::new();
+	while let Ok(v) = rcv.try_recv() {
+		out.extend(v);
+	}
+	Ok(String::from_utf8(out).unwrap())
+}
+
+async fn snapshot(ds: &Datastore, ses: &Session) -> Result<Vec<Value>, Error> {
+	let sql = "
+		INFO FOR DB;
+		INFO FOR TABLE person;
+		SELECT * FROM person;
+		SELECT * FROM likes;
+		SELECT VALUE ->likes->person FROM person:a;
+	";
+	let res = ds.execute(sql, ses, None).await?;
+	res.into_iter().map(|r| r.result).collect()
+}
+
+#[tokio::test]
+async fn export_sql_round_trip() -> Result<(), Error> {
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = dbs.execute(SCHEMA_AND_DATA, &ses, None).await?;
+	for r in res {
+		assert!(r.result.is_ok());
+	}
+	let before = snapshot(&dbs, &ses).await?;
+	// Export the database
+	let sql = export(&dbs, &ses, ExportFormat::Sql).await?;
+	// The schema is defined before any data is inserted
+	let schema = sql.find("DEFINE TABLE person").unwrap();
+	let data = sql.find("INSERT [").unwrap();
+	assert!(schema < data, "{sql}");
+	// Wipe the database
+	let res = &mut dbs.execute("REMOVE DATABASE test", &ses, None).await?;
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	let res = &mut dbs.execute("SELECT * FROM person", &ses, None).await?;
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp, Value::parse("[]"));
+	// Import the export and check everything was restored
+	let res = dbs.import(&sql, &ses).await?;
+	for r in res {
+		assert!(r.result.is_ok());
+	}
+	let after = snapshot(&dbs, &ses).await?;
+	assert_eq!(before, after);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn export_ndjson() -> Result<(), Error> {
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = dbs.execute(SCHEMA_AND_DATA, &ses, None).await?;
+	for r in res {
+		assert!(r.result.is_ok());
+	}
+	let out = export(&dbs, &ses, ExportFormat::Ndjson).await?;
+	let lines: Vec<serde_json::Value> =
+		out.lines().map(|l| serde_json::from_str(l).unwrap()).collect();
+	assert_eq!(lines.len(), 7, "{out}");
+	// The definitions come before the records
+	let defs: Vec<&str> = lines[..4].iter().map(|l| l["define"].as_str().unwrap()).collect();
+	assert_eq!(
+		defs,
+		[
+			"DEFINE TABLE likes TYPE RELATION SCHEMALESS PERMISSIONS NONE",
+			"DEFINE TABLE person TYPE ANY SCHEMAFULL PERMISSIONS NONE",
+			"DEFINE FIELD name ON person TYPE string PERMISSIONS FULL",
+			"DEFINE INDEX person_name ON person FIELDS name UNIQUE",
+		]
+	);
+	// Graph edges are kept apart from normal records
+	let val = Value::parse("{ id: likes:1, in: person:a, out: person:b, weight: 5 }");
+	assert_eq!(lines[4]["relation"], val.to_string());
+	let val = Value::parse("{ id: person:a, name: 'Alice' }");
+	assert_eq!(lines[5]["record"], val.to_string());
+	let val = Value::parse("{ id: person:b, name: 'Bob' }");
+	assert_eq!(lines[6]["record"], val.to_string());
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn import_ndjson_round_trip() -> Result<(), Error> {
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = dbs.execute(SCHEMA_AND_DATA, &ses, None).await?;
+	for r in res {
+		assert!(r.result.is_ok());
+	}
+	let before = snapshot(&dbs, &ses).await?;
+	let out = export(&dbs, &ses, ExportFormat::Ndjson).await?;
+	// Wipe the database
+	let res = &mut dbs.execute("REMOVE DATABASE test", &ses, None).await?;
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	// Import the export and check everything was restored
+	let opts = ImportOptions::default().with_batch_size(2);
+	let sum = dbs.import_ndjson(&ses, out.as_bytes(), &opts).await?;
+	assert_eq!(sum.inserted, 3);
+	assert_eq!(sum.skipped, 0);
+	assert_eq!(sum.failed, 0);
+	assert!(sum.malformed.is_empty());
+	let after = snapshot(&dbs, &ses).await?;
+	assert_eq!(before, after);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn import_ndjson_round_trip_typed_values() -> Result<(), Error> {
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let sql = "
+		CREATE item:1 CONTENT {
+			created: d'2024-01-02T03:04:05Z',
+			every: 1h30m,
+			price: 19.99dec,
+			owner: person:a,
+			location: (-0.118092, 51.509865),
+			area: {
+				type: 'Polygon',
+				coordinates: [[[0, 0], [0, 1], [1, 1], [0, 0]]],
+			},
+			tags: [NONE, 'sale', NULL],
+			data: <bytes> 'abc',
+			text: 'person:b',
+		};
+	";
+	let res = dbs.execute(sql, &ses, None).await?;
+	for r in res {
+		assert!(r.result.is_ok());
+	}
+	let res = &mut dbs.execute("SELECT * FROM item", &ses, None).await?;
+	let before = res.remove(0).result?;
+	let out = export(&dbs, &ses, ExportFormat::Ndjson).await?;
+	// Wipe the database
+	let res = &mut dbs.execute("REMOVE DATABASE test", &ses, None).await?;
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	// Import the export and check the values kept their types
+	let sum = dbs.import_ndjson(&ses, out.as_bytes(), &ImportOptions::default()).await?;
+	assert_eq!(sum.inserted, 1);
+	let res = &mut dbs.execute("SELECT * FROM item", &ses, None).await?;
+	let after = res.remove(0).result?;
+	assert_eq!(before, after);
+	let sql = "
+		SELECT VALUE [
+			type::is::datetime(created),
+			type::is::duration(every),
+			type::is::decimal(price),
+			type::is::record(owner, 'person'),
+			type::is::point(location),
+			type::is::geometry(area),
+			type::is::none(tags[0]),
+			type::is::null(tags[2]),
+			type::is::bytes(data),
+			type::is::string(text),
+		] FROM item:1;
+	";
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[[true, true, true, true, true, true, true, true, true, true]]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn import_ndjson_conflict_modes() -> Result<(), Error> {
+	let input = r#"{"record":{"id":"person:a","name":"Alice"}}
+{"record":{"id":"person:c","name":"Carol"}}
+{"relation":{"id":"likes:1","in":"person:a","out":"person:c","weight":1}}
+"#;
+	let sql = "
+		CREATE person:a SET name = 'Existing';
+		RELATE person:a->likes->person:b SET id = likes:1, weight = 5;
+	";
+	for (conflict, inserted, skipped, failed, name, weight, out) in [
+		(ImportConflict::Skip, 1, 2, 0, "Existing", 5, "person:b"),
+		(ImportConflict::Overwrite, 3, 0, 0, "Alice", 1, "person:c"),
+		(ImportConflict::Fail, 1, 0, 2, "Existing", 5, "person:b"),
+	] {
+		let dbs = new_ds().await?;
+		let ses = Session::owner().with_ns("test").with_db("test");
+		let res = dbs.execute(sql, &ses, None).await?;
+		for r in res {
+			assert!(r.result.is_ok());
+		}
+		let opts = ImportOptions::default().with_conflict(conflict);
+		let sum = dbs.import_ndjson(&ses, input.as_bytes(), &opts).await?;
+		assert_eq!(sum.inserted, inserted, "{conflict:?}");
+		assert_eq!(sum.skipped, skipped, "{conflict:?}");
+		assert_eq!(sum.failed, failed, "{conflict:?}");
+		// New records are always imported
+		let res = &mut dbs.execute("SELECT VALUE name FROM person:c", &ses, None).await?;
+		let tmp = res.remove(0).result?;
+		assert_eq!(tmp, Value::parse("['Carol']"));
+		// Existing records depend on the conflict mode
+		let res = &mut dbs.execute("SELECT VALUE name FROM person:a", &ses, None).await?;
+		let tmp = res.remove(0).result?;
+		assert_eq!(tmp, Value::parse(&format!("['{name}']")), "{conflict:?}");
+		let res = &mut dbs.execute("SELECT VALUE weight FROM likes:1", &ses, None).await?;
+		let tmp = res.remove(0).result?;
+		assert_eq!(tmp, Value::parse(&format!("[{weight}]")), "{conflict:?}");
+		// Replaced relations keep their graph edges
+		let sql = "SELECT VALUE [in, out, ->person] FROM likes:1; SELECT VALUE ->likes->person FROM person:a";
+		let res = &mut dbs.execute(sql, &ses, None).await?;
+		let tmp = res.remove(0).result?;
+		let val = Value::parse(&format!("[[person:a, {out}, [{out}]]]"));
+		assert_eq!(tmp, val, "{conflict:?}");
+		let tmp = res.remove(0).result?;
+		assert_eq!(tmp, Value::parse(&format!("[[{out}]]")), "{conflict:?}");
+	}
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn import_ndjson_only_tolerates_existing_definitions() -> Result<(), Error> {
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = dbs.execute("DEFINE TABLE person SCHEMALESS", &ses, None).await?;
+	for r in res {
+		assert!(r.result.is_ok());
+	}
+	let opts = ImportOptions::default().with_conflict(ImportConflict::Skip);
+	// Existing definitions are kept
+	let input = r#"{"define":"DEFINE TABLE person SCHEMAFULL"}"#;
+	let sum = dbs.import_ndjson(&ses, input.as_bytes(), &opts).await?;
+	assert_eq!(sum, Default::default());
+	let res = &mut dbs.execute("INFO FOR TABLE person", &ses, None).await?;
+	let tmp = res.remove(0).result?;
+	assert!(tmp.to_string().contains("SCHEMALESS"), "{tmp}");
+	// Other failing definitions still fail the import
+	let input = r#"{"define":"DEFINE NAMESPACE other"}"#;
+	let ses = Session::viewer().with_ns("test").with_db("test");
+	let res = dbs.import_ndjson(&ses, input.as_bytes(), &opts).await;
+	assert!(res.is_err());
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn import_sql_conflict_modes() -> Result<(), Error> {
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = dbs.execute(SCHEMA_AND_DATA, &ses, None).await?;
+	for r in res {
+		assert!(r.result.is_ok());
+	}
+	let sql = export(&dbs, &ses, ExportFormat::Sql).await?;
+	for (conflict, name, weight) in
+		[(ImportConflict::Skip, "Existing", 1), (ImportConflict::Overwrite, "Alice", 5)]
+	{
+		let dbs = new_ds().await?;
+		let setup = "
+			DEFINE TABLE person SCHEMAFULL;
+			DEFINE FIELD name ON person TYPE string;
+			CREATE person:a SET name = 'Existing';
+			RELATE person:a->likes->person:b SET id = likes:1, weight = 1;
+		";
+		let res = dbs.execute(setup, &ses, None).await?;
+		for r in res {
+			assert!(r.result.is_ok());
+		}
+		let res = dbs.import_with_conflict(&sql, &ses, conflict).await?;
+		for r in res {
+			assert!(r.result.is_ok(), "{conflict:?}: {:?}", r.result);
+		}
+		// New records are always imported
+		let res = &mut dbs.execute("SELECT VALUE name FROM person:b", &ses, None).await?;
+		let tmp = res.remove(0).result?;
+		assert_eq!(tmp, Value::parse("['Bob']"));
+		// Existing records depend on the conflict mode
+		let res = &mut dbs.execute("SELECT VALUE name FROM person:a", &ses, None).await?;
+		let tmp = res.remove(0).result?;
+		assert_eq!(tmp, Value::parse(&format!("['{name}']")), "{conflict:?}");
+		let res = &mut dbs.execute("SELECT VALUE weight FROM likes:1", &ses, None).await?;
+		let tmp = res.remove(0).result?;
+		assert_eq!(tmp, Value::parse(&format!("[{weight}]")), "{conflict:?}");
+		// Replaced relations keep their graph edges
+		let sql = "SELECT VALUE [in, out, ->person] FROM likes:1; SELECT VALUE ->likes->person FROM person:a";
+		let res = &mut dbs.execute(sql, &ses, None).await?;
+		let tmp = res.remove(0).result?;
+		assert_eq!(tmp, Value::parse("[[person:a, person:b, [person:b]]]"), "{conflict:?}");
+		let tmp = res.remove(0).result?;
+		assert_eq!(tmp, Value::parse("[[person:b]]"), "{conflict:?}");
+	}
+	// Existing definitions and records fail the import by default
+	let res = dbs.import_with_conflict(&sql, &ses, ImportConflict::Fail).await?;
+	assert!(res.iter().any(|r| r.result.is_err()));
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn import_ndjson_malformed_lines() -> Result<(), Error> {
+	let input = r#"{"define":"DEFINE TABLE person SCHEMALESS"}
+{"record":{"id":"person:a","name":"Alice"}}
+{"record":{"id":"person:b","name":
+{"unknown":{"id":"person:c"}}
+
+{"record":{"name":"Dave"}}
+{"record":{"id":"person:e","name":"Eve"}}
+"#;
+	// Malformed lines fail the import by default
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let opts = ImportOptions::default().with_batch_size(1);
+	let res = dbs.import_ndjson(&ses, input.as_bytes(), &opts).await;
+	assert!(matches!(
+		res,
+		Err(e) if e.to_string().starts_with("Unable to import line 3:")
+	));
+	// Records before the malformed line have already been imported
+	let res = &mut dbs.execute("SELECT VALUE id FROM person", &ses, None).await?;
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp, Value::parse("[person:a]"));
+	// Malformed lines can be tolerated and reported
+	let dbs = new_ds().await?;
+	let opts = ImportOptions::default().with_tolerate_malformed(true);
+	let sum = dbs.import_ndjson(&ses, input.as_bytes(), &opts).await?;
+	assert_eq!(sum.inserted, 2);
+	assert_eq!(sum.skipped, 0);
+	assert_eq!(sum.failed, 0);
+	assert_eq!(sum.malformed, vec![3, 4, 6]);
+	let res = &mut dbs.execute("SELECT VALUE id FROM person", &ses, None).await?;
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp, Value::parse("[person:a, person:e]"));
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn import_csv() -> Result<(), Error> {
+	let input = "id;name;joined;active;notes;zip
+1;\"Smith; John\";2024-01-02;true;\"Likes \"\"quotes\"\"
+and newlines\";01234
+2;Jane;2024-02-03;FALSE;;90210
+3;Broken;2024-03-04
+";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let csv = CsvOptions::default().with_delimiter(';').with_type("zip", Kind::String);
+	let opts = ImportOptions::default().with_tolerate_malformed(true);
+	let sum = dbs.import_csv(&ses, "person", input.as_bytes(), &csv, &opts).await?;
+	assert_eq!(sum.inserted, 2);
+	assert_eq!(sum.malformed, vec![5]);
+	let res = &mut dbs.execute("SELECT * FROM person", &ses, None).await?;
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				id: person:1,
+				name: 'Smith; John',
+				joined: d'2024-01-02T00:00:00Z',
+				active: true,
+				notes: 'Likes \"quotes\"\nand newlines',
+				zip: '01234',
+			},
+			{
+				id: person:2,
+				name: 'Jane',
+				joined: d'2024-02-03T00:00:00Z',
+				active: false,
+				zip: '90210',
+			},
+		]",
+	);
+	assert_eq!(tmp, val);
+	// Importing the same ids again follows the conflict mode
+	let opts = ImportOptions::default().with_conflict(ImportConflict::Skip);
+	let input = "id,name\n2,Janet\n4,Bob\n";
+	let sum =
+		dbs.import_csv(&ses, "person", input.as_bytes(), &CsvOptions::default(), &opts).await?;
+	assert_eq!(sum.inserted, 1);
+	assert_eq!(sum.skipped, 1);
+	let res = &mut dbs.execute("SELECT VALUE name FROM person", &ses, None).await?;
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp, Value::parse("['Smith; John', 'Jane', 'Bob']"));
+	//
+	Ok(())
+}
@@ -7,3 +7,76 @@ async fn modulo() -> Result<(), Error> {
 	Test::new("8 % 3").await?.expect_val("2")?;
 	Ok(())
 }
+
+#[tokio::test]
+async fn null_coalescing() -> Result<(), Error> {
+	let sql = "
+		NONE ?? 1;
+		NULL ?? 1;
+		0 ?? 1;
+		'' ?? 1;
+		false ?? 1;
+		NONE ?? NULL ?? 3;
+		NONE ?? 2 ?? 3;
+	";
+	Test::new(sql).await?.expect_vals(&["1", "1", "0", "''", "false", "3", "2"])?;
+	Ok(())
+}
+
+#[tokio::test]
+async fn null_coalescing_does_not_evaluate_right_operand() -> Result<(), Error> {
+	let sql = "
+		1 ?? (CREATE person:test);
+		SELECT * FROM person;
+		NONE ?? (CREATE person:test RETURN id);
+		SELECT * FROM person;
+	";
+	Test::new(sql).await?.expect_vals(&[
+		"1",
+		"[]",
+		"[{ id: person:test }]",
+		"[{ id: person:test }]",
+	])?;
+	Ok(())
+}
+
+#[tokio::test]
+async fn null_coalescing_nested_fields() -> Result<(), Error> {
+	let sql = "
+		CREATE person:test SET name = { first: 'Tobie' }, tags = [];
+		SELECT VALUE name.first ?? 'unknown' FROM person:test;
+		SELECT VALUE name.last ?? 'unknown' FROM person:test;
+		SELECT VALUE address.city.name ?? 'unknown' FROM person:test;
+		SELECT VALUE tags ?? 'unknown' FROM person:test;
+	";
+	Test::new(sql).await?.skip_ok(1)?.expect_vals(&[
+		"['Tobie']",
+		"['unknown']",
+		"['unknown']",
+		"[[]]",
+	])?;
+	Ok(())
+}
+
+#[tokio::test]
+async fn ternary_conditional() -> Result<(), Error> {
+	let sql = "
+		true ? 1 : 2;
+		false ? 1 : 2;
+		[] ? 1 : 2;
+		false ? 1 : NONE ? 2 : 3;
+		[0 ? 'yes' : 'no', string::len(true ? 'x' : 'yy')];
+		CREATE person:test SET name = 'Tobie', age = 21;
+		SELECT VALUE age >= 18 ? name : 'minor' FROM person;
+	";
+	Test::new(sql).await?.expect_vals(&[
+		"1",
+		"2",
+		"2",
+		"3",
+		"['no', 1]",
+		"[{ age: 21, id: person:test, name: 'Tobie' }]",
+		"['Tobie']",
+	])?;
+	Ok(())
+}
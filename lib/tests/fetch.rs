@@ -279,3 +279,97 @@ async fn create_relate_select() -> Result<(), Error> {
 	//
 	Ok(())
 }
+
+#[tokio::test]
+async fn select_fetch_array_of_links() -> Result<(), Error> {
+	let sql = "
+		CREATE company:surreal SET name = 'SurrealDB';
+		CREATE person:tobie SET name = 'Tobie', company = company:surreal;
+		CREATE person:jaime SET name = 'Jaime', company = company:surreal;
+		CREATE post:1 SET authors = [person:tobie, person:jaime, person:tobie, person:missing];
+		SELECT * FROM post FETCH authors, authors.company;
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 5);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				id: post:1,
+				authors: [
+					{
+						id: person:tobie,
+						name: 'Tobie',
+						company: { id: company:surreal, name: 'SurrealDB' }
+					},
+					{
+						id: person:jaime,
+						name: 'Jaime',
+						company: { id: company:surreal, name: 'SurrealDB' }
+					},
+					{
+						id: person:tobie,
+						name: 'Tobie',
+						company: { id: company:surreal, name: 'SurrealDB' }
+					},
+					NULL
+				]
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn select_fetch_circular_links() -> Result<(), Error> {
+	let sql = "
+		CREATE person:tobie SET name = 'Tobie', friend = person:jaime;
+		CREATE person:jaime SET name = 'Jaime', friend = person:tobie;
+		SELECT * FROM person:tobie FETCH friend, friend.friend;
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 3);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				id: person:tobie,
+				name: 'Tobie',
+				friend: {
+					id: person:jaime,
+					name: 'Jaime',
+					friend: {
+						id: person:tobie,
+						name: 'Tobie',
+						friend: person:jaime
+					}
+				}
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
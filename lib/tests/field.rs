@@ -138,6 +138,107 @@ async fn field_definition_value_assert_success() -> Result<(), Error> {
 	Ok(())
 }
 
+#[tokio::test]
+async fn field_definition_value_assert_message() -> Result<(), Error> {
+	let sql = "
+		DEFINE TABLE product SCHEMAFULL;
+		DEFINE FIELD name ON product TYPE string ASSERT string::len($value) > 0;
+		DEFINE FIELD price ON product TYPE number ASSERT $value > 0 ELSE 'price must be positive';
+		DEFINE FIELD stock ON product TYPE int ASSERT $value >= 0 ELSE string::concat($field, ' can not be ', $value);
+		CREATE product:one SET name = 'Pen', price = -1, stock = 1;
+		CREATE product:one SET name = 'Pen', price = 1, stock = -5;
+		CREATE product:one SET name = '', price = 1, stock = 1;
+		CREATE product:one SET name = 'Pen', price = 1, stock = 1;
+		UPDATE product:one SET price = 0;
+	";
+	let mut t = Test::new(sql).await?;
+	t.skip_ok(4)?;
+	t.expect_error("price must be positive")?;
+	t.expect_error("stock can not be -5")?;
+	t.expect_error("Found '' for field `name`, with record `product:one`, but field must conform to: string::len($value) > 0")?;
+	t.expect_val("[{ id: product:one, name: 'Pen', price: 1, stock: 1 }]")?;
+	t.expect_error("price must be positive")?;
+	Ok(())
+}
+
+#[tokio::test]
+async fn field_definition_value_dependency_chain() -> Result<(), Error> {
+	let sql = "
+		DEFINE TABLE person SCHEMAFULL;
+		DEFINE FIELD first ON person TYPE string;
+		DEFINE FIELD last ON person TYPE string;
+		DEFINE FIELD alias ON person VALUE display + '!';
+		DEFINE FIELD display ON person VALUE string::uppercase(full_name);
+		DEFINE FIELD full_name ON person VALUE string::concat(first, ' ', last);
+		CREATE person:test SET first = 'Tobie', last = 'Morgan', full_name = 'Ignored', alias = 'Ignored';
+		UPDATE person:test SET last = 'Hitchcock', display = 'Ignored';
+	";
+	let mut t = Test::new(sql).await?;
+	t.skip_ok(6)?;
+	t.expect_val(
+		"[
+			{
+				alias: 'TOBIE MORGAN!',
+				display: 'TOBIE MORGAN',
+				first: 'Tobie',
+				full_name: 'Tobie Morgan',
+				id: person:test,
+				last: 'Morgan',
+			}
+		]",
+	)?;
+	t.expect_val(
+		"[
+			{
+				alias: 'TOBIE HITCHCOCK!',
+				display: 'TOBIE HITCHCOCK',
+				first: 'Tobie',
+				full_name: 'Tobie Hitchcock',
+				id: person:test,
+				last: 'Hitchcock',
+			}
+		]",
+	)?;
+	Ok(())
+}
+
+#[tokio::test]
+async fn field_definition_value_circular_dependency() -> Result<(), Error> {
+	let sql = "
+		DEFINE FIELD a ON test VALUE b + 1;
+		DEFINE FIELD b ON test VALUE c + 1;
+		DEFINE FIELD c ON test VALUE a + 1;
+		DEFINE FIELD c ON test VALUE 1;
+		DEFINE FIELD d ON test VALUE (d OR 0) + 1;
+		CREATE test:one;
+		UPDATE test:one;
+	";
+	let mut t = Test::new(sql).await?;
+	t.skip_ok(2)?;
+	t.expect_error(
+		"The field 'a' has a VALUE clause which depends on itself through other fields",
+	)?;
+	t.skip_ok(2)?;
+	t.expect_val("[{ id: test:one, a: 3, b: 2, c: 1, d: 1 }]")?;
+	t.expect_val("[{ id: test:one, a: 3, b: 2, c: 1, d: 2 }]")?;
+	Ok(())
+}
+
+#[tokio::test]
+async fn field_definition_value_nested_fields() -> Result<(), Error> {
+	let sql = "
+		DEFINE FIELD settings ON user VALUE settings ?? {};
+		DEFINE FIELD settings.theme ON user VALUE settings.theme ?? 'dark';
+		CREATE user:one;
+		CREATE user:two SET settings.theme = 'light';
+	";
+	let mut t = Test::new(sql).await?;
+	t.skip_ok(2)?;
+	t.expect_val("[{ id: user:one, settings: { theme: 'dark' } }]")?;
+	t.expect_val("[{ id: user:two, settings: { theme: 'light' } }]")?;
+	Ok(())
+}
+
 #[tokio::test]
 async fn field_definition_empty_nested_objects() -> Result<(), Error> {
 	let sql = "
@@ -866,6 +967,65 @@ async fn field_definition_edge_permissions() -> Result<(), Error> {
 	Ok(())
 }
 
+#[tokio::test]
+async fn field_definition_permissions_per_record() -> Result<(), Error> {
+	let sql = "
+		DEFINE TABLE user SCHEMAFULL PERMISSIONS FULL;
+		DEFINE FIELD name ON user TYPE string;
+		DEFINE FIELD email ON user TYPE string PERMISSIONS FOR select, update WHERE id = $auth.id;
+		DEFINE FIELD salary ON user TYPE int PERMISSIONS FOR select, update NONE;
+		CREATE user:one SET name = 'John', email = 'john@example.com', salary = 10;
+		CREATE user:two SET name = 'Lucy', email = 'lucy@example.com', salary = 20;
+	";
+	let dbs = new_ds().await?.with_auth_enabled(true);
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 6);
+	for _ in 0..6 {
+		res.remove(0).result?;
+	}
+	// A record user can only see the fields they are allowed to
+	let sql = "
+		SELECT * FROM user;
+		UPDATE user:one SET email = 'john@surrealdb.com', salary = 99;
+		UPDATE user:two SET email = 'john@surrealdb.com', name = 'Lucy Jones';
+	";
+	let ses = Session::for_record("test", "test", "test", Thing::from(("user", "one")).into());
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 3);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{ id: user:one, name: 'John', email: 'john@example.com' },
+			{ id: user:two, name: 'Lucy' },
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: user:one, name: 'John', email: 'john@surrealdb.com' }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: user:two, name: 'Lucy Jones' }]");
+	assert_eq!(tmp, val);
+	// The disallowed changes were reverted
+	let sql = "SELECT * FROM user";
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{ id: user:one, name: 'John', email: 'john@surrealdb.com', salary: 10 },
+			{ id: user:two, name: 'Lucy Jones', email: 'lucy@example.com', salary: 20 },
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
 #[tokio::test]
 async fn field_definition_readonly() -> Result<(), Error> {
 	let sql = "
@@ -186,6 +186,29 @@ async fn function_array_combine() -> Result<(), Error> {
 	Ok(())
 }
 
+#[tokio::test]
+async fn function_array_chunk() -> Result<(), Error> {
+	let sql = r#"
+		RETURN array::chunk([], 2);
+		RETURN array::chunk([0, 1, 2, 3], 2);
+		RETURN array::chunk([0, 1, 2, 3, 4], 2);
+		RETURN array::chunk([0, 1, 2], 5);
+		RETURN array::chunk([0, 1, 2], 0);
+		RETURN array::chunk([0, 1, 2], -1);
+	"#;
+	// The function is an alias of array::clump
+	let error = "Incorrect arguments for function array::clump(). The second argument must be an integer greater than 0";
+	Test::new(sql)
+		.await?
+		.expect_val("[]")?
+		.expect_val("[[0, 1], [2, 3]]")?
+		.expect_val("[[0, 1], [2, 3], [4]]")?
+		.expect_val("[[0, 1, 2]]")?
+		.expect_error(error)?
+		.expect_error(error)?;
+	Ok(())
+}
+
 #[tokio::test]
 async fn function_array_clump() -> Result<(), Error> {
 	let sql = r#"
@@ -359,6 +382,24 @@ async fn function_array_flatten() -> Result<(), Error> {
 	Ok(())
 }
 
+#[tokio::test]
+async fn function_array_flatten_deep() -> Result<(), Error> {
+	let sql = r#"
+		RETURN array::flatten_deep([]);
+		RETURN array::flatten_deep("some text");
+		RETURN array::flatten_deep([[1,2], [3,4]]);
+		RETURN array::flatten_deep([[1,2], [3, 4], 'SurrealDB', [5, 6, [7, [8, []]]]]);
+	"#;
+	let error = "Incorrect arguments for function array::flatten_deep(). Argument 1 was the wrong type. Expected a array but found 'some text'";
+	Test::new(sql)
+		.await?
+		.expect_val("[]")?
+		.expect_error(error)?
+		.expect_val("[1, 2, 3, 4]")?
+		.expect_val("[1, 2, 3, 4, 'SurrealDB', 5, 6, 7, 8]")?;
+	Ok(())
+}
+
 #[tokio::test]
 async fn function_array_group() -> Result<(), Error> {
 	let sql = r#"
@@ -389,6 +430,48 @@ async fn function_array_group() -> Result<(), Error> {
 	Ok(())
 }
 
+#[tokio::test]
+async fn function_array_group_by() -> Result<(), Error> {
+	let sql = r#"
+		RETURN array::group_by([], 'kind');
+		RETURN array::group_by([
+			{ name: 'a', kind: 'x' },
+			{ name: 'b', kind: 'y' },
+			{ name: 'c', kind: 'x' },
+			{ name: 'd' },
+		], 'kind');
+		RETURN array::group_by([
+			{ name: 'a', tags: { size: 1 } },
+			{ name: 'b', tags: { size: 2 } },
+			{ name: 'c', tags: { size: 1 } },
+		], 'tags.size');
+		RETURN array::group_by([{ kind: 1 }, { kind: '1' }, { kind: [1] }], 'kind');
+		RETURN array::group_by([{ kind: 'x' }, 1], 'kind');
+	"#;
+	Test::new(sql)
+		.await?
+		.expect_val("[]")?
+		.expect_val(
+			"[
+				[NONE, [{ name: 'd' }]],
+				['x', [{ name: 'a', kind: 'x' }, { name: 'c', kind: 'x' }]],
+				['y', [{ name: 'b', kind: 'y' }]],
+			]",
+		)?
+		.expect_val(
+			"[
+				[1, [{ name: 'a', tags: { size: 1 } }, { name: 'c', tags: { size: 1 } }]],
+				[2, [{ name: 'b', tags: { size: 2 } }]],
+			]",
+		)?
+		// Values of different types are grouped separately
+		.expect_val("[[1, [{ kind: 1 }]], ['1', [{ kind: '1' }]], [[1], [{ kind: [1] }]]]")?
+		.expect_error(
+			"Incorrect arguments for function array::group_by(). Expected an array of objects but found 1",
+		)?;
+	Ok(())
+}
+
 #[tokio::test]
 async fn function_array_insert() -> Result<(), Error> {
 	let sql = r#"
@@ -1036,6 +1119,66 @@ async fn function_array_union() -> Result<(), Error> {
 	Ok(())
 }
 
+#[tokio::test]
+async fn function_array_windows() -> Result<(), Error> {
+	let sql = r#"
+		RETURN array::windows([], 2);
+		RETURN array::windows([0, 1, 2, 3], 2);
+		RETURN array::windows([0, 1, 2, 3, 4], 3);
+		RETURN array::windows([0, 1, 2], 5);
+		RETURN array::windows([0, 1, 2], 0);
+	"#;
+	Test::new(sql)
+		.await?
+		.expect_val("[]")?
+		.expect_val("[[0, 1], [1, 2], [2, 3]]")?
+		.expect_val("[[0, 1, 2], [1, 2, 3], [2, 3, 4]]")?
+		.expect_val("[]")?
+		.expect_error(
+			"Incorrect arguments for function array::windows(). The second argument must be an integer greater than 0",
+		)?;
+	Ok(())
+}
+
+#[tokio::test]
+async fn function_array_set_operations() -> Result<(), Error> {
+	let sql = r#"
+		RETURN array::union([1,1,2], [2,3,3]);
+		RETURN array::intersect([1,1,2,3], [3,1,1]);
+		RETURN array::difference([1,1,2,3], [3,4,4]);
+		RETURN array::distinct([1,1,2,2,1]);
+		RETURN array::union([1,'1',true,NONE], ['1',true,false,NONE]);
+		RETURN array::intersect([1,'1',true,person:one], ['1',person:one,1f]);
+		RETURN array::difference([1,'1',true], ['1',false]);
+		RETURN array::union([{ a: 1, b: { c: 2 } }], [{ b: { c: 2 }, a: 1 }, { a: 2 }]);
+		RETURN array::intersect([{ a: 1, b: [1,2] }, { a: 2 }], [{ a: 1, b: [1,2] }, { a: 1, b: [2,1] }]);
+		RETURN array::difference([{ a: 1 }, { a: 2 }], [{ a: 2 }, { a: 3 }]);
+		RETURN array::distinct([{ a: { b: 1 } }, { a: { b: 1 } }, { a: { b: 2 } }]);
+		RETURN array::union(NULL, [1,2]);
+		RETURN array::intersect([1,2], NULL);
+		RETURN array::difference(NULL, NULL);
+		RETURN array::distinct(NULL);
+	"#;
+	Test::new(sql).await?.expect_vals(&[
+		"[1,2,3]",
+		"[1,3]",
+		"[1,2,4]",
+		"[1,2]",
+		"[1,'1',true,NONE,false]",
+		"[1,'1',person:one]",
+		"[1,true,false]",
+		"[{ a: 1, b: { c: 2 } }, { a: 2 }]",
+		"[{ a: 1, b: [1,2] }]",
+		"[{ a: 1 }, { a: 3 }]",
+		"[{ a: { b: 1 } }, { a: { b: 2 } }]",
+		"[1,2]",
+		"[]",
+		"[]",
+		"[]",
+	])?;
+	Ok(())
+}
+
 // --------------------------------------------------
 // bytes
 // --------------------------------------------------
@@ -1175,6 +1318,38 @@ async fn function_crypto_sha512() -> Result<(), Error> {
 	Ok(())
 }
 
+#[tokio::test]
+async fn function_crypto_argon2() -> Result<(), Error> {
+	let sql = r#"
+		LET $hash = crypto::argon2::generate('this is a strong password');
+		RETURN string::starts_with($hash, '$argon2id$v=19$m=19456,t=2,p=1$');
+		RETURN crypto::argon2::compare($hash, 'this is a strong password');
+		RETURN crypto::argon2::compare($hash, 'this is a wrong password');
+		RETURN crypto::argon2::compare('not a hash', 'this is a strong password');
+		RETURN $hash != crypto::argon2::generate('this is a strong password');
+	"#;
+	let mut test = Test::new(sql).await?;
+	test.skip_ok(1)?;
+	test.expect_vals(&["true", "true", "false", "false", "true"])?;
+	Ok(())
+}
+
+#[tokio::test]
+async fn function_crypto_bcrypt() -> Result<(), Error> {
+	let sql = r#"
+		LET $hash = crypto::bcrypt::generate('this is a strong password');
+		RETURN string::starts_with($hash, '$2b$12$');
+		RETURN crypto::bcrypt::compare($hash, 'this is a strong password');
+		RETURN crypto::bcrypt::compare($hash, 'this is a wrong password');
+		RETURN crypto::bcrypt::compare('not a hash', 'this is a strong password');
+		RETURN $hash != crypto::bcrypt::generate('this is a strong password');
+	"#;
+	let mut test = Test::new(sql).await?;
+	test.skip_ok(1)?;
+	test.expect_vals(&["true", "true", "false", "false", "true"])?;
+	Ok(())
+}
+
 // --------------------------------------------------
 // duration
 // --------------------------------------------------
@@ -1666,6 +1841,113 @@ async fn function_parse_geo_centroid() -> Result<(), Error> {
 	Ok(())
 }
 
+#[tokio::test]
+async fn function_parse_geo_contains() -> Result<(), Error> {
+	let sql = r#"
+		LET $london = {
+			type: 'Polygon',
+			coordinates: [
+				[
+					[-0.38314819, 51.37692386], [0.1785278, 51.37692386],
+					[0.1785278, 51.61460570], [-0.38314819, 51.61460570],
+					[-0.38314819, 51.37692386]
+				],
+				[
+					[-0.19, 51.50], [-0.15, 51.50],
+					[-0.15, 51.51], [-0.19, 51.51],
+					[-0.19, 51.50]
+				]
+			]
+		};
+		RETURN geo::contains($london, (-0.1246, 51.5007));
+		RETURN geo::contains($london, (2.3522, 48.8566));
+		RETURN geo::contains($london, (-0.17, 51.505));
+		RETURN geo::contains($london, (51.5007, -0.1246));
+		RETURN geo::contains($london, 'London');
+	"#;
+	let mut test = Test::new(sql).await?;
+	test.skip_ok(1)?;
+	test.expect_vals(&["true", "false", "false", "false", "NONE"])?;
+	Ok(())
+}
+
+#[tokio::test]
+async fn function_parse_geo_area_with_hole() -> Result<(), Error> {
+	let sql = r#"
+		RETURN geo::area({
+			type: 'Polygon',
+			coordinates: [
+				[
+					[-0.38314819, 51.37692386], [0.1785278, 51.37692386],
+					[0.1785278, 51.61460570], [-0.38314819, 51.61460570],
+					[-0.38314819, 51.37692386]
+				],
+				[
+					[-0.19, 51.50], [-0.15, 51.50],
+					[-0.15, 51.51], [-0.19, 51.51],
+					[-0.19, 51.50]
+				]
+			]
+		}) + geo::area({
+			type: 'Polygon',
+			coordinates: [[
+				[-0.19, 51.50], [-0.15, 51.50],
+				[-0.15, 51.51], [-0.19, 51.51],
+				[-0.19, 51.50]
+			]]
+		});
+	"#;
+	let mut test = Test::new(sql).await?;
+	test.expect_float(1029944667.4192368, 0.001)?;
+	Ok(())
+}
+
+#[tokio::test]
+async fn function_parse_geo_antimeridian() -> Result<(), Error> {
+	let sql = r#"
+		LET $fiji = {
+			type: 'Polygon',
+			coordinates: [[
+				[179, -17], [-179, -17],
+				[-179, -16], [179, -16],
+				[179, -17]
+			]]
+		};
+		LET $shifted = {
+			type: 'Polygon',
+			coordinates: [[
+				[10, -17], [12, -17],
+				[12, -16], [10, -16],
+				[10, -17]
+			]]
+		};
+		LET $band = {
+			type: 'Polygon',
+			coordinates: [[
+				[-100, 0], [0, 0], [100, 0],
+				[100, 10], [0, 10], [-100, 10],
+				[-100, 0]
+			]]
+		};
+		RETURN geo::contains($fiji, (179.5, -16.5));
+		RETURN geo::contains($fiji, (-179.5, -16.5));
+		RETURN geo::contains($fiji, (0, -16.5));
+		RETURN geo::contains($fiji, (178, -16.5));
+		RETURN math::abs(geo::area($fiji) - geo::area($shifted)) < 1;
+		RETURN geo::area($fiji) > 23000000000 AND geo::area($fiji) < 24000000000;
+		RETURN geo::distance((179.5, 0), (-179.5, 0));
+		RETURN geo::contains($band, (0, 5));
+		RETURN geo::contains($band, (-150, 5));
+	"#;
+	let mut test = Test::new(sql).await?;
+	test.skip_ok(3)?;
+	test.expect_vals(&["true", "true", "false", "false", "true", "true"])?;
+	test.expect_float(111195.08, 0.01)?;
+	// A geometry which spans more than half of the globe without crossing the antimeridian
+	test.expect_vals(&["true", "false"])?;
+	Ok(())
+}
+
 #[tokio::test]
 async fn function_parse_geo_distance() -> Result<(), Error> {
 	let sql = r#"
@@ -2401,6 +2683,75 @@ async fn function_math_stddev() -> Result<(), Error> {
 	Ok(())
 }
 
+#[tokio::test]
+async fn function_math_distribution_ignores_null() -> Result<(), Error> {
+	let sql = r#"
+		RETURN math::median([3, 1, 2]);
+		RETURN math::median([4, 1, 3, 2]);
+		RETURN math::median([1, NULL, 3, NONE]);
+		RETURN math::median([NULL]);
+		RETURN math::mode([1, 2, 2, 3]);
+		RETURN math::mode([1, NULL, 3, 3]);
+		RETURN math::percentile([1, 2, 3, 4], 0);
+		RETURN math::percentile([1, 2, 3, 4], 25);
+		RETURN math::percentile([1, 2, 3, 4], 50);
+		RETURN math::percentile([1, 2, 3, 4], 100);
+		RETURN math::percentile([10, NULL, 20], 50);
+		RETURN math::stddev([2, 4, 4, 4, 5, 5, 7, 9]);
+		RETURN math::stddev([1, NULL, 3]);
+	"#;
+	let mut test = Test::new(sql).await?;
+	test.expect_vals(&["2", "2.5", "2", "NONE", "2", "3", "1", "1.75", "2.5", "4", "15"])?;
+	test.expect_floats(&[2.138089935299395, std::f64::consts::SQRT_2], 1e-9)?;
+	Ok(())
+}
+
+#[tokio::test]
+async fn function_math_distribution_in_group_by() -> Result<(), Error> {
+	let sql = r#"
+		CREATE score:1 SET team = 'a', value = 1;
+		CREATE score:2 SET team = 'a', value = 2;
+		CREATE score:3 SET team = 'a', value = 2;
+		CREATE score:4 SET team = 'a', value = 4;
+		CREATE score:5 SET team = 'a', value = NULL;
+		CREATE score:6 SET team = 'b', value = 5;
+		CREATE score:7 SET team = 'b', value = 5;
+		CREATE score:8 SET team = 'b', value = 8;
+		CREATE score:9 SET team = 'b';
+		SELECT
+			team,
+			math::median(value) AS median,
+			math::mode(value) AS mode,
+			math::percentile(value, 75) AS p75,
+			math::stddev(value) AS stddev
+		FROM score GROUP BY team;
+	"#;
+	let mut test = Test::new(sql).await?;
+	test.skip_ok(9)?;
+	let Value::Array(mut rows) = test.next_value()? else {
+		panic!("Expected an array of groups");
+	};
+	assert_eq!(rows.len(), 2);
+	// The standard deviations are compared separately, as they are not exact
+	for (row, expected) in rows.iter_mut().zip([1.2583057392117916, 3f64.sqrt()]) {
+		let Value::Object(row) = row else {
+			panic!("Expected an object: {row}");
+		};
+		let Some(Value::Number(Number::Float(stddev))) = row.remove("stddev") else {
+			panic!("Expected a float stddev in {row}");
+		};
+		assert!((stddev - expected).abs() < 1e-9, "{stddev} does not match {expected}");
+	}
+	let val = Value::parse(
+		"[
+			{ team: 'a', median: 2, mode: 2, p75: 2.5 },
+			{ team: 'b', median: 5, mode: 5, p75: 6.5 },
+		]",
+	);
+	assert_eq!(Value::Array(rows), val);
+	Ok(())
+}
+
 #[tokio::test]
 async fn function_math_sum() -> Result<(), Error> {
 	let sql = r#"
@@ -3146,6 +3497,43 @@ async fn function_search_analyzer() -> Result<(), Error> {
 	Ok(())
 }
 
+#[test_log::test(tokio::test)]
+async fn function_search_analyzer_tokens() -> Result<(), Error> {
+	let sql = r#"
+		DEFINE ANALYZER blank TOKENIZERS blank;
+		DEFINE ANALYZER class TOKENIZERS class;
+		DEFINE ANALYZER lower TOKENIZERS blank,class FILTERS lowercase;
+		DEFINE ANALYZER folded TOKENIZERS blank FILTERS ascii,lowercase;
+		DEFINE ANALYZER english TOKENIZERS blank,class FILTERS lowercase,snowball(english);
+		DEFINE ANALYZER prefixes TOKENIZERS blank FILTERS lowercase,edgengram(2,4);
+		RETURN search::analyze('blank', 'Hello World3000!');
+		RETURN search::analyze('class', 'World3000!');
+		RETURN search::analyze('lower', 'Hello World3000!');
+		RETURN search::analyze('folded', 'Éléphant Ça');
+		RETURN search::analyze('english', 'The foxes were running');
+		RETURN search::analyze('prefixes', 'Surreal');
+	"#;
+	let mut test = Test::new(sql).await?;
+	//
+	for _ in 0..6 {
+		let tmp = test.next()?.result;
+		assert!(tmp.is_ok());
+	}
+	//
+	for expected in [
+		"['Hello', 'World3000!']",
+		"['World', '3000', '!']",
+		"['hello', 'world', '3000', '!']",
+		"['elephant', 'ca']",
+		"['the', 'fox', 'were', 'run']",
+		"['su', 'sur', 'surr']",
+	] {
+		let tmp = test.next()?.result?;
+		assert_eq!(format!("{:#}", tmp), format!("{:#}", Value::parse(expected)));
+	}
+	Ok(())
+}
+
 #[test_log::test(tokio::test)]
 async fn function_search_analyzer_invalid_arguments() -> Result<(), Error> {
 	let sql = r#"
@@ -3560,6 +3948,39 @@ async fn function_parse_is_uuid() -> Result<(), Error> {
 	Ok(())
 }
 
+#[tokio::test]
+async fn function_string_extract() -> Result<(), Error> {
+	let sql = r#"
+		RETURN string::extract("order-1234-gb", /order-(\d+)-(\w+)/, 1);
+		RETURN string::extract("order-1234-gb", /order-(\d+)-(\w+)/, 2);
+		RETURN string::extract("order-1234-gb", "order-(?P<id>\\d+)", "id");
+		RETURN string::extract("order-1234-gb", /order-\d+/);
+		RETURN string::extract("invoice-1234", /order-(\d+)/, 1);
+		RETURN string::extract("order-", /order-(\d+)?/, 1);
+		RETURN string::extract("order-1234", /order-(\d+)/, 2);
+		RETURN string::extract("order-1234", /order-(?P<id>\d+)/, "name");
+		RETURN string::extract("order-1234", "order-(\\d+", 1);
+	"#;
+	let mut test = Test::new(sql).await?;
+	// Numbered and named capture groups
+	test.expect_vals(&["'1234'", "'gb'", "'1234'"])?;
+	// The whole match is returned by default
+	test.expect_val("'order-1234'")?;
+	// The expression or the group did not match
+	test.expect_vals(&["NONE", "NONE"])?;
+	// The group does not exist
+	test.expect_errors(&[
+		"Incorrect arguments for function string::extract(). Argument 3 is not a capture group in the regular expression /order-(\\d+)/",
+		"Incorrect arguments for function string::extract(). Argument 3 is not a capture group in the regular expression /order-(?P<id>\\d+)/",
+	])?;
+	// The expression is not valid
+	test.expect_error_func(|e| {
+		matches!(e, Error::InvalidArguments { name, message }
+			if name == "string::extract" && message.contains("Invalid regular expression"))
+	})?;
+	Ok(())
+}
+
 #[tokio::test]
 async fn function_string_join() -> Result<(), Error> {
 	let sql = r#"
@@ -3657,6 +4078,8 @@ async fn function_string_matches() -> Result<(), Error> {
 	let sql = r#"
 		RETURN string::matches("foo", /foo/);
 		RETURN string::matches("bar", /foo/);
+		RETURN string::matches("foo123", "^[a-z]+\\d+$");
+		RETURN string::matches("foo", "(foo");
 	"#;
 	let mut test = Test::new(sql).await?;
 	//
@@ -3667,6 +4090,12 @@ async fn function_string_matches() -> Result<(), Error> {
 	let tmp = test.next()?.result?;
 	let val = Value::from(false);
 	assert_eq!(tmp, val);
+	// Patterns can be given as strings
+	test.expect_val("true")?;
+	test.expect_error_func(|e| {
+		matches!(e, Error::InvalidArguments { name, message }
+			if name == "string::matches" && message.contains("Invalid regular expression"))
+	})?;
 	Ok(())
 }
 
@@ -3694,6 +4123,24 @@ async fn function_string_repeat() -> Result<(), Error> {
 	Ok(())
 }
 
+#[tokio::test]
+async fn function_string_replace_regex() -> Result<(), Error> {
+	let sql = r#"
+		RETURN string::replace_regex("2024-01-31", /(\d+)-(\d+)-(\d+)/, "$3/$2/$1");
+		RETURN string::replace_regex("Tobie Morgan", "(?P<first>\\w+) (?P<last>\\w+)", "${last}, ${first}");
+		RETURN string::replace_regex("a1b22c333", /\d+/, "X");
+		RETURN string::replace_regex("no digits", /\d+/, "X");
+		RETURN string::replace_regex("abc", "[a-", "");
+	"#;
+	let mut test = Test::new(sql).await?;
+	test.expect_vals(&["'31/01/2024'", "'Morgan, Tobie'", "'aXbXcX'", "'no digits'"])?;
+	test.expect_error_func(|e| {
+		matches!(e, Error::InvalidArguments { name, message }
+			if name == "string::replace_regex" && message.contains("Invalid regular expression"))
+	})?;
+	Ok(())
+}
+
 #[tokio::test]
 async fn function_string_replace() -> Result<(), Error> {
 	let sql = r#"
@@ -3749,25 +4196,23 @@ async fn function_string_similarity_fuzzy() -> Result<(), Error> {
 		RETURN string::similarity::fuzzy("some", "text");
 		RETURN string::similarity::fuzzy("text", "TEXT");
 		RETURN string::similarity::fuzzy("TEXT", "TEXT");
+		RETURN string::similarity::fuzzy("crème", "crème");
 		RETURN string::similarity::fuzzy("this could be a tricky test", "this test");
+		RETURN string::similarity::fuzzy(NULL, "text");
+		RETURN string::similarity::fuzzy("text", NONE);
 	"#;
 	let mut test = Test::new(sql).await?;
+	// The score is normalised against the score of the pattern matched against itself
+	test.expect_floats(&[1.0, 0.0, 83.0 / 91.0, 1.0, 1.0], 0.001)?;
 	//
 	let tmp = test.next()?.result?;
-	assert_eq!(tmp, Value::from(0));
-	//
-	let tmp = test.next()?.result?;
-	assert_eq!(tmp, Value::from(0));
-	//
-	let tmp = test.next()?.result?;
-	assert_eq!(tmp, Value::from(83));
-	//
-	let tmp = test.next()?.result?;
-	assert_eq!(tmp, Value::from(91));
-	//
-	let tmp = test.next()?.result?;
-	assert_eq!(tmp, Value::from(174));
+	let Value::Number(sim) = tmp else {
+		panic!("Expected a number: {tmp:?}");
+	};
+	let sim = sim.as_float();
+	assert!(sim > 0.0 && sim < 1.0, "{sim}");
 	//
+	test.expect_vals(&["NONE", "NONE"])?;
 	Ok(())
 }
 
@@ -3800,6 +4245,52 @@ async fn function_string_similarity_smithwaterman() -> Result<(), Error> {
 	Ok(())
 }
 
+#[tokio::test]
+async fn function_string_similarity_jaro() -> Result<(), Error> {
+	let sql = r#"
+		RETURN string::similarity::jaro("", "");
+		RETURN string::similarity::jaro("", "text");
+		RETURN string::similarity::jaro("text", "text");
+		RETURN string::similarity::jaro("martha", "marhta");
+		RETURN string::similarity::jaro("crème", "creme");
+		RETURN string::similarity::jaro(NULL, "text");
+		RETURN string::similarity::jaro("text", NONE);
+	"#;
+	let mut test = Test::new(sql).await?;
+	test.expect_floats(&[1.0, 0.0, 1.0, 0.944, 0.867], 0.001)?;
+	test.expect_vals(&["NONE", "NONE"])?;
+	Ok(())
+}
+
+#[tokio::test]
+async fn function_string_similarity_jaro_winkler() -> Result<(), Error> {
+	let sql = r#"
+		RETURN string::similarity::jaro_winkler("", "");
+		RETURN string::similarity::jaro_winkler("some", "text");
+		RETURN string::similarity::jaro_winkler("martha", "marhta");
+		RETURN string::similarity::jaro_winkler("dwayne", "duane");
+		RETURN string::similarity::jaro_winkler(NULL, NONE);
+	"#;
+	let mut test = Test::new(sql).await?;
+	test.expect_floats(&[1.0, 0.0, 0.961, 0.84], 0.001)?;
+	test.expect_val("NONE")?;
+	Ok(())
+}
+
+#[tokio::test]
+async fn function_string_distance_levenshtein() -> Result<(), Error> {
+	let sql = r#"
+		RETURN string::distance::levenshtein("", "");
+		RETURN string::distance::levenshtein("", "abc");
+		RETURN string::distance::levenshtein("kitten", "sitting");
+		RETURN string::distance::levenshtein("café", "cafe");
+		RETURN string::distance::levenshtein("日本語", "日本");
+		RETURN string::distance::levenshtein("text", NULL);
+	"#;
+	Test::new(sql).await?.expect_vals(&["0", "3", "3", "1", "1", "NONE"])?;
+	Ok(())
+}
+
 #[tokio::test]
 async fn function_string_slice() -> Result<(), Error> {
 	let sql = r#"
@@ -3892,6 +4383,23 @@ async fn function_string_split() -> Result<(), Error> {
 	Ok(())
 }
 
+#[tokio::test]
+async fn function_string_split_regex() -> Result<(), Error> {
+	let sql = r#"
+		RETURN string::split_regex("a, b;c ;  d", /\s*[,;]\s*/);
+		RETURN string::split_regex("abc", /\d/);
+		RETURN string::split_regex("", /,/);
+		RETURN string::split_regex("abc", "(");
+	"#;
+	let mut test = Test::new(sql).await?;
+	test.expect_vals(&["['a', 'b', 'c', 'd']", "['abc']", "['']"])?;
+	test.expect_error_func(|e| {
+		matches!(e, Error::InvalidArguments { name, message }
+			if name == "string::split_regex" && message.contains("Invalid regular expression"))
+	})?;
+	Ok(())
+}
+
 #[tokio::test]
 async fn function_string_starts_with() -> Result<(), Error> {
 	let sql = r#"
@@ -4096,6 +4604,64 @@ async fn function_time_group() -> Result<(), Error> {
 	Ok(())
 }
 
+#[tokio::test]
+async fn function_time_bucketing() -> Result<(), Error> {
+	let sql = r#"
+		RETURN time::group(d"1987-06-24T08:30:45Z", 'week');
+		RETURN time::group(d"1987-06-22T00:00:00Z", 'week');
+		RETURN time::floor(d"2023-03-26T01:30:00+01:00", 1h);
+		RETURN time::floor(d"2023-03-26T03:30:00+02:00", 1h);
+		RETURN time::group(d"2023-03-26T03:30:00+02:00", 'day');
+		RETURN time::ceil(d"2023-10-29T02:30:00+02:00", 1h);
+		RETURN time::ceil(d"2023-10-29T02:30:00+01:00", 1h);
+		RETURN time::floor(d"2023-05-11T03:09:00.123456789Z", 100ms);
+		RETURN time::ceil(d"2023-05-11T03:09:00.123456789Z", 250ms);
+		RETURN time::floor(d"2023-05-11T03:09:00.123456789Z", 1µs);
+		RETURN time::ceil(d"2023-05-11T03:09:00.123456789Z", 10ns);
+		RETURN time::group(d"1987-06-22T08:30:45Z", 'fortnight');
+	"#;
+	let error = "Incorrect arguments for function time::group(). The second argument must be a string, and can be one of 'year', 'month', 'week', 'day', 'hour', 'minute', or 'second'.";
+	Test::new(sql)
+		.await?
+		.expect_vals(&[
+			"d'1987-06-22T00:00:00Z'",
+			"d'1987-06-22T00:00:00Z'",
+			"d'2023-03-26T00:00:00Z'",
+			"d'2023-03-26T01:00:00Z'",
+			"d'2023-03-26T00:00:00Z'",
+			"d'2023-10-29T01:00:00Z'",
+			"d'2023-10-29T02:00:00Z'",
+			"d'2023-05-11T03:09:00.100Z'",
+			"d'2023-05-11T03:09:00.250Z'",
+			"d'2023-05-11T03:09:00.123456Z'",
+			"d'2023-05-11T03:09:00.123456790Z'",
+		])?
+		.expect_error(error)?;
+	Ok(())
+}
+
+#[tokio::test]
+async fn function_time_bucketing_in_group_by() -> Result<(), Error> {
+	let sql = r#"
+		CREATE reading:1 SET at = d"2023-03-26T00:10:00Z", value = 1;
+		CREATE reading:2 SET at = d"2023-03-26T00:50:00Z", value = 2;
+		CREATE reading:3 SET at = d"2023-03-26T03:20:00+02:00", value = 3;
+		CREATE reading:4 SET at = d"2023-03-26T01:59:59.999Z", value = 4;
+		SELECT time::floor(at, 1h) AS bucket, math::sum(value) AS total FROM reading GROUP BY bucket;
+		SELECT time::group(at, 'day') AS bucket, count() AS total FROM reading GROUP BY bucket;
+	"#;
+	let mut test = Test::new(sql).await?;
+	test.skip_ok(4)?;
+	test.expect_val(
+		"[
+			{ bucket: d'2023-03-26T00:00:00Z', total: 3 },
+			{ bucket: d'2023-03-26T01:00:00Z', total: 7 },
+		]",
+	)?;
+	test.expect_val("[{ bucket: d'2023-03-26T00:00:00Z', total: 4 }]")?;
+	Ok(())
+}
+
 #[tokio::test]
 async fn function_time_hour() -> Result<(), Error> {
 	let sql = r#"
@@ -5172,6 +5738,25 @@ async fn function_type_string() -> Result<(), Error> {
 	Ok(())
 }
 
+#[tokio::test]
+async fn function_type_conversions_in_field_definitions() -> Result<(), Error> {
+	let sql = r#"
+		DEFINE FIELD age ON person VALUE type::int($value) ASSERT type::is::int($value) OR type::is::none($value);
+		DEFINE FIELD born ON person VALUE type::datetime($value);
+		CREATE person:one SET age = '42', born = '2024-01-01T00:00:00Z';
+		CREATE person:two;
+		CREATE person:three SET age = 'old';
+		RETURN [type::number(NONE), type::string(NULL), type::bool(NULL), type::is::string(NULL)];
+	"#;
+	let mut t = Test::new(sql).await?;
+	t.skip_ok(2)?;
+	t.expect_val("[{ id: person:one, age: 42, born: d'2024-01-01T00:00:00Z' }]")?;
+	t.expect_val("[{ id: person:two }]")?;
+	t.expect_error("Expected a int but cannot convert 'old' into a int")?;
+	t.expect_val("[NONE, NULL, NULL, false]")?;
+	Ok(())
+}
+
 #[tokio::test]
 async fn function_type_table() -> Result<(), Error> {
 	let sql = r#"
@@ -6066,6 +6651,37 @@ async fn function_custom_optional_args() -> Result<(), Error> {
 	Ok(())
 }
 
+#[tokio::test]
+async fn function_custom_nested_and_recursive_calls() -> Result<(), Error> {
+	let sql = r#"
+		DEFINE FUNCTION fn::greet($first: string, $last: string) { RETURN 'Hello ' + $first + ' ' + $last; };
+		DEFINE FUNCTION fn::shout($name: string) { RETURN string::uppercase(fn::greet($name, 'Doe')); };
+		DEFINE FUNCTION fn::factorial($n: int) { RETURN IF $n <= 1 { 1 } ELSE { $n * fn::factorial($n - 1) }; };
+		DEFINE FUNCTION fn::forever($n: int) { RETURN fn::forever($n + 1); };
+		RETURN fn::greet('Jane', 'Doe');
+		RETURN fn::shout('John');
+		RETURN fn::factorial(5);
+		RETURN fn::forever(0);
+		REMOVE FUNCTION fn::greet;
+		RETURN fn::greet('Jane', 'Doe');
+	"#;
+	let mut test = Test::new(sql).await?;
+	test.skip_ok(4)?;
+	// The arguments are bound in order
+	test.expect_val("'Hello Jane Doe'")?;
+	// Functions can call other functions
+	test.expect_val("'HELLO JOHN DOE'")?;
+	// Functions can call themselves
+	test.expect_val("120")?;
+	// Unbounded recursion is stopped
+	test.expect_error(
+		"Reached excessive computation depth due to functions, subqueries, or futures",
+	)?;
+	test.skip_ok(1)?;
+	test.expect_error("The function 'fn::greet' does not exist")?;
+	Ok(())
+}
+
 #[tokio::test]
 async fn function_outside_database() -> Result<(), Error> {
 	let sql = "RETURN fn::does_not_exist();";
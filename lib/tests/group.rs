@@ -5,7 +5,7 @@ use helpers::new_ds;
 use helpers::skip_ok;
 use surrealdb::dbs::Session;
 use surrealdb::err::Error;
-use surrealdb::sql::Value;
+use surrealdb::sql::{Thing, Value};
 
 #[tokio::test]
 async fn select_aggregate() -> Result<(), Error> {
@@ -674,3 +674,332 @@ async fn select_array_count_subquery_group_by() -> Result<(), Error> {
 	//
 	Ok(())
 }
+
+#[tokio::test]
+async fn select_having() -> Result<(), Error> {
+	let sql = "
+		CREATE sale:1 SET category = 'a', price = 10;
+		CREATE sale:2 SET category = 'a', price = 20;
+		CREATE sale:3 SET category = 'a', price = 30;
+		CREATE sale:4 SET category = 'b', price = 5;
+		CREATE sale:5 SET category = 'b', price = 15;
+		CREATE sale:6 SET category = 'c', price = 100;
+		SELECT category, count() AS total FROM sale GROUP BY category HAVING total > 1 ORDER BY category;
+		SELECT category, math::sum(price) AS sum FROM sale GROUP BY category HAVING sum >= 60 ORDER BY category;
+		SELECT category, math::min(price) AS min, math::max(price) AS max FROM sale GROUP BY category HAVING min < 10 OR max >= 100 ORDER BY category;
+		SELECT category, count() AS total, math::sum(price) AS sum FROM sale GROUP BY category HAVING total > 1 AND sum < 50;
+		SELECT category, count() AS total FROM sale GROUP BY category HAVING total >= 1 ORDER BY category LIMIT 1 START 1;
+		SELECT count() AS total, math::sum(price) AS sum FROM sale HAVING total > 5;
+		SELECT count() AS total FROM sale HAVING total > 10;
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let mut res = dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 13);
+	//
+	skip_ok(&mut res, 6)?;
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				category: 'a',
+				total: 3
+			},
+			{
+				category: 'b',
+				total: 2
+			}
+		]",
+	);
+	assert_eq!(format!("{tmp:#}"), format!("{val:#}"));
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				category: 'a',
+				sum: 60
+			},
+			{
+				category: 'c',
+				sum: 100
+			}
+		]",
+	);
+	assert_eq!(format!("{tmp:#}"), format!("{val:#}"));
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				category: 'b',
+				max: 15,
+				min: 5
+			},
+			{
+				category: 'c',
+				max: 100,
+				min: 100
+			}
+		]",
+	);
+	assert_eq!(format!("{tmp:#}"), format!("{val:#}"));
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				category: 'b',
+				sum: 20,
+				total: 2
+			}
+		]",
+	);
+	assert_eq!(format!("{tmp:#}"), format!("{val:#}"));
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				category: 'b',
+				total: 2
+			}
+		]",
+	);
+	assert_eq!(format!("{tmp:#}"), format!("{val:#}"));
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				sum: 180,
+				total: 6
+			}
+		]",
+	);
+	assert_eq!(format!("{tmp:#}"), format!("{val:#}"));
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(format!("{tmp:#}"), format!("{val:#}"));
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn select_filtered_aggregates() -> Result<(), Error> {
+	let sql = "
+		CREATE sale:1 SET region = 'EU', status = 'open', amount = 10;
+		CREATE sale:2 SET region = 'EU', status = 'closed', amount = 20;
+		CREATE sale:3 SET region = 'EU', status = 'open', amount = 30;
+		CREATE sale:4 SET region = 'US', status = 'open', amount = 5;
+		CREATE sale:5 SET region = 'US', status = 'closed', amount = 15;
+		CREATE sale:6 SET region = 'APAC', status = 'closed', amount = 100;
+		SELECT
+			region,
+			count() AS total,
+			count() FILTER (WHERE status = 'open') AS open,
+			math::sum(amount) AS sum,
+			math::sum(amount) FILTER (WHERE status = 'open') AS open_sum,
+			math::max(amount) FILTER (WHERE status = 'closed') AS closed_max
+		FROM sale GROUP BY region ORDER BY region;
+		SELECT
+			count() AS total,
+			count(amount > 10) FILTER (WHERE region = 'EU') AS eu_large,
+			math::mean(amount) FILTER (WHERE region = 'US') AS us_mean,
+			array::group(region) FILTER (WHERE status = 'open') AS open_regions
+		FROM sale GROUP ALL;
+		SELECT count() FILTER (WHERE status = 'open') AS open FROM sale HAVING open > 1;
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let mut res = dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 9);
+	//
+	skip_ok(&mut res, 6)?;
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				closed_max: 100,
+				open: 0,
+				open_sum: 0,
+				region: 'APAC',
+				sum: 100,
+				total: 1
+			},
+			{
+				closed_max: 20,
+				open: 2,
+				open_sum: 40,
+				region: 'EU',
+				sum: 60,
+				total: 3
+			},
+			{
+				closed_max: 15,
+				open: 1,
+				open_sum: 5,
+				region: 'US',
+				sum: 20,
+				total: 2
+			}
+		]",
+	);
+	assert_eq!(format!("{tmp:#}"), format!("{val:#}"));
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				eu_large: 2,
+				open_regions: ['EU', 'US'],
+				total: 6,
+				us_mean: 10
+			}
+		]",
+	);
+	assert_eq!(format!("{tmp:#}"), format!("{val:#}"));
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ open: 3 }]");
+	assert_eq!(format!("{tmp:#}"), format!("{val:#}"));
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn select_filtered_aggregates_with_none_values() -> Result<(), Error> {
+	let sql = "
+		CREATE sale:1 SET status = 'open';
+		CREATE sale:2 SET status = 'closed', discount = 10;
+		CREATE sale:3 SET status = 'open', discount = 5;
+		SELECT array::distinct(discount) FILTER (WHERE status = 'open') AS discounts FROM sale GROUP ALL;
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let mut res = dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 4);
+	//
+	skip_ok(&mut res, 3)?;
+	//
+	// A row which matches the filter is passed to the aggregate, even without a value
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ discounts: [NONE, 5] }]");
+	assert_eq!(format!("{tmp:#}"), format!("{val:#}"));
+	//
+	// A filtered aggregate can not have the same name as another field
+	let sql = "SELECT count(), count() FILTER (WHERE status = 'open') FROM sale GROUP ALL";
+	let res = dbs.execute(sql, &ses, None).await;
+	assert!(res.is_err());
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn select_count_without_fetching_records() -> Result<(), Error> {
+	let sql = "
+		DEFINE FIELD age ON person TYPE int;
+		DEFINE INDEX idx_age ON person FIELDS age;
+		CREATE person:1 SET age = 20, category = 'a';
+		CREATE person:2 SET age = 30, category = 'a';
+		CREATE person:3 SET age = 30, category = 'b';
+		CREATE person:4 SET age = 40, category = 'b';
+		CREATE person:5 SET age = 50, category = 'a';
+		CREATE person:6 SET age = 60, category = 'c';
+		SELECT count() FROM person GROUP ALL;
+		SELECT count() AS total FROM person GROUP ALL;
+		SELECT count() FROM person;
+		SELECT count() FROM person LIMIT 2;
+		SELECT count() FROM person WHERE age = 30 GROUP ALL;
+		SELECT count() FROM person WHERE age > 25 GROUP ALL;
+		SELECT count() FROM person WHERE age > 25 AND category = 'a' GROUP ALL;
+		SELECT category, count() FROM person GROUP BY category ORDER BY category;
+		SELECT count() FROM nobody GROUP ALL;
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let mut res = dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 17);
+	//
+	skip_ok(&mut res, 8)?;
+	//
+	for expected in [
+		"[{ count: 6 }]",
+		"[{ total: 6 }]",
+		"[{ count: 1 }, { count: 1 }, { count: 1 }, { count: 1 }, { count: 1 }, { count: 1 }]",
+		"[{ count: 1 }, { count: 1 }]",
+		"[{ count: 2 }]",
+		"[{ count: 5 }]",
+		"[{ count: 2 }]",
+		"[
+			{ category: 'a', count: 3 },
+			{ category: 'b', count: 2 },
+			{ category: 'c', count: 1 }
+		]",
+		"[]",
+	] {
+		let tmp = res.remove(0).result?;
+		let val = Value::parse(expected);
+		assert_eq!(format!("{tmp:#}"), format!("{val:#}"));
+	}
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn select_count_with_soft_deleted_records() -> Result<(), Error> {
+	let sql = "
+		DEFINE TABLE person SOFT DELETE;
+		DEFINE FIELD age ON person TYPE int;
+		DEFINE INDEX idx_age ON person FIELDS age;
+		CREATE person:1 SET age = 20;
+		CREATE person:2 SET age = 30;
+		CREATE person:3 SET age = 30;
+		DELETE person:1, person:2;
+		SELECT count() FROM person GROUP ALL;
+		SELECT count() FROM person;
+		SELECT count() FROM person WHERE age = 30 GROUP ALL;
+		SELECT count() FROM person WITH DELETED GROUP ALL;
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let mut res = dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 11);
+	//
+	skip_ok(&mut res, 7)?;
+	// Soft-deleted records are only counted with WITH DELETED
+	for expected in ["[{ count: 1 }]", "[{ count: 1 }]", "[{ count: 1 }]", "[{ count: 3 }]"] {
+		let tmp = res.remove(0).result?;
+		let val = Value::parse(expected);
+		assert_eq!(format!("{tmp:#}"), format!("{val:#}"));
+	}
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn select_count_with_table_permissions() -> Result<(), Error> {
+	let sql = "
+		DEFINE TABLE person SCHEMALESS PERMISSIONS FOR select WHERE public = true;
+		CREATE person:1 SET public = true;
+		CREATE person:2 SET public = false;
+		CREATE person:3 SET public = true;
+	";
+	let dbs = new_ds().await?.with_auth_enabled(true);
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 4);
+	skip_ok(res, 4)?;
+	// The records which cannot be selected are not counted
+	let sql = "SELECT count() FROM person GROUP ALL";
+	let ses = Session::for_record("test", "test", "test", Thing::from(("user", "test")).into());
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ count: 2 }]");
+	assert_eq!(format!("{tmp:#}"), format!("{val:#}"));
+	//
+	Ok(())
+}
@@ -193,6 +193,53 @@ async fn insert_statement_duplicate_key_update() -> Result<(), Error> {
 	Ok(())
 }
 
+#[tokio::test]
+async fn insert_statement_duplicate_key_update_batch() -> Result<(), Error> {
+	let sql = "
+		DEFINE INDEX email ON TABLE user COLUMNS email UNIQUE;
+		CREATE user:one SET email = 'one@surrealdb.com', logins = 1;
+		INSERT INTO user [
+			{ id: user:two, email: 'two@surrealdb.com', logins: 1 },
+			{ id: user:dup, email: 'one@surrealdb.com', logins: 1 },
+			{ id: user:three, email: 'three@surrealdb.com', logins: 1 },
+		] ON DUPLICATE KEY UPDATE logins += $input.logins;
+		SELECT * FROM user ORDER BY id;
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 4);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	// The colliding row updates the existing record, and the
+	// remaining rows in the batch are still inserted
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{ id: user:two, email: 'two@surrealdb.com', logins: 1 },
+			{ id: user:one, email: 'one@surrealdb.com', logins: 2 },
+			{ id: user:three, email: 'three@surrealdb.com', logins: 1 },
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{ id: user:one, email: 'one@surrealdb.com', logins: 2 },
+			{ id: user:three, email: 'three@surrealdb.com', logins: 1 },
+			{ id: user:two, email: 'two@surrealdb.com', logins: 1 },
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
 //
 // Permissions
 //
@@ -0,0 +1,152 @@
+mod parse;
+use parse::Parse;
+mod helpers;
+use helpers::new_ds;
+use surrealdb::dbs::Session;
+use surrealdb::err::Error;
+use surrealdb::sql::Value;
+
+#[tokio::test]
+async fn limit_result_rows() -> Result<(), Error> {
+	let sql = "
+		INSERT INTO item [{ id: 1 }, { id: 2 }, { id: 3 }, { id: 4 }, { id: 5 }] RETURN NONE;
+		SELECT * FROM item;
+		SELECT * FROM item ORDER BY id DESC;
+		SELECT * FROM item START 1;
+		SELECT * FROM item START 2;
+		SELECT * FROM item ORDER BY id DESC LIMIT 3;
+		SELECT count() FROM item GROUP ALL;
+	";
+	let dbs = new_ds().await?.with_max_result_rows(Some(3));
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 7);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	for _ in 0..3 {
+		let tmp = res.remove(0).result;
+		assert!(
+			matches!(
+				tmp,
+				Err(Error::ResultRowsExceeded {
+					limit: 3
+				})
+			),
+			"found {tmp:?}"
+		);
+	}
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: item:3 }, { id: item:4 }, { id: item:5 }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: item:5 }, { id: item:4 }, { id: item:3 }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ count: 5 }]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn limit_mutated_records() -> Result<(), Error> {
+	let sql = "
+		INSERT INTO item [{ id: 1 }, { id: 2 }, { id: 3 }, { id: 4 }];
+		INSERT INTO item [{ id: 1 }, { id: 2 }, { id: 3 }];
+		CREATE item:4;
+		BEGIN;
+		CREATE log:1;
+		UPDATE item SET done = true;
+		COMMIT;
+		SELECT * FROM log;
+		SELECT * FROM item WHERE done;
+		DELETE item:1, item:2, item:3;
+		SELECT * FROM item;
+	";
+	let dbs = new_ds().await?.with_max_mutated_records(Some(3));
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 9);
+	// The statement is rolled back
+	let tmp = res.remove(0).result;
+	assert!(
+		matches!(
+			tmp,
+			Err(Error::MutatedRecordsExceeded {
+				limit: 3
+			})
+		),
+		"found {tmp:?}"
+	);
+	//
+	for _ in 0..2 {
+		let tmp = res.remove(0).result;
+		assert!(tmp.is_ok());
+	}
+	// The whole transaction is rolled back
+	let tmp = res.remove(0).result;
+	assert!(matches!(
+		tmp.err(),
+		Some(e) if e.to_string() == r#"The query was not executed due to a failed transaction"#
+	));
+	//
+	let tmp = res.remove(0).result;
+	assert!(
+		matches!(
+			tmp,
+			Err(Error::MutatedRecordsExceeded {
+				limit: 3
+			})
+		),
+		"found {tmp:?}"
+	);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: item:4 }]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn limit_computation_depth() -> Result<(), Error> {
+	let sql = "
+		DEFINE FUNCTION fn::recurse($n: int) {
+			RETURN IF $n > 0 THEN fn::recurse($n - 1) ELSE $n END;
+		};
+		RETURN fn::recurse(2);
+		RETURN fn::recurse(50);
+	";
+	let dbs = new_ds().await?.with_max_computation_depth(40);
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 3);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::from(0);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(Error::ComputationDepthExceeded)), "found {tmp:?}");
+	//
+	Ok(())
+}
@@ -2,7 +2,7 @@ mod helpers;
 mod parse;
 
 use helpers::new_ds;
-use surrealdb::dbs::Session;
+use surrealdb::dbs::{Action, Notification, Session};
 use surrealdb::err::Error;
 use surrealdb::fflags::FFLAGS;
 use surrealdb::sql::Value;
@@ -97,3 +97,53 @@ async fn live_query_sends_registered_lq_details() -> Result<(), Error> {
 
 	Ok(())
 }
+
+#[tokio::test]
+async fn live_query_update_moves_record_into_and_out_of_filter() -> Result<(), Error> {
+	if FFLAGS.change_feed_live_queries.enabled() {
+		return Ok(());
+	}
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test").with_rt(true);
+	let res = &mut dbs.execute("LIVE SELECT * FROM person WHERE age >= 18", &ses, None).await?;
+	let live_id = match res.remove(0).result? {
+		Value::Uuid(id) => id,
+		v => panic!("Expected a UUID, found {v}"),
+	};
+	let notifications = dbs.notifications().expect("expected notifications");
+	// Each statement is followed by the notification it should send, if any. Notifications are
+	// flushed in the background, so an unexpected one is caught by the next expected notification.
+	let steps = [
+		("CREATE person:one SET age = 16", None),
+		("UPDATE person:one SET age = 18", Some((Action::Create, "{ id: person:one, age: 18 }"))),
+		("UPDATE person:one SET age = 19", Some((Action::Update, "{ id: person:one, age: 19 }"))),
+		("UPDATE person:one SET age = 10", Some((Action::Delete, "{ id: person:one, age: 19 }"))),
+		("UPDATE person:one SET age = 11", None),
+		("CREATE person:two SET age = 30", Some((Action::Create, "{ id: person:two, age: 30 }"))),
+		("DELETE person:one", None),
+		("DELETE person:two", Some((Action::Delete, "{ id: person:two, age: 30 }"))),
+	];
+	for (sql, expected) in steps {
+		let res = &mut dbs.execute(sql, &ses, None).await?;
+		res.remove(0).result?;
+		match expected {
+			Some((action, result)) => {
+				let notification = notifications.recv().await.expect(sql);
+				assert_eq!(
+					notification,
+					Notification::new(live_id, action, Value::parse(result)),
+					"{sql}"
+				);
+			}
+			None => (),
+		}
+	}
+	// No notifications are sent once the live query is killed
+	let res = &mut dbs.execute(&format!("KILL {live_id}"), &ses, None).await?;
+	res.remove(0).result?;
+	let res = &mut dbs.execute("CREATE person:three SET age = 40", &ses, None).await?;
+	res.remove(0).result?;
+	tokio::time::sleep(std::time::Duration::from_millis(100)).await;
+	assert!(notifications.try_recv().is_err());
+	Ok(())
+}
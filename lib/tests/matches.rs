@@ -5,7 +5,7 @@ use crate::helpers::skip_ok;
 use helpers::new_ds;
 use surrealdb::dbs::Session;
 use surrealdb::err::Error;
-use surrealdb::sql::Value;
+use surrealdb::sql::{Part, Value};
 
 #[tokio::test]
 async fn select_where_matches_using_index() -> Result<(), Error> {
@@ -704,3 +704,43 @@ async fn select_where_matches_mixing_indexes() -> Result<(), Error> {
 	assert_eq!(format!("{:#}", tmp), format!("{:#}", val));
 	Ok(())
 }
+
+#[tokio::test]
+async fn select_where_matches_multiple_terms_ordered_by_score() -> Result<(), Error> {
+	let sql = r"
+		DEFINE ANALYZER simple TOKENIZERS blank,class FILTERS lowercase,snowball(english);
+		DEFINE INDEX blog_title ON blog FIELDS title SEARCH ANALYZER simple BM25;
+		CREATE blog:1 SET title = 'The Quick brown Foxes';
+		CREATE blog:2 SET title = 'quick fox quick fox';
+		CREATE blog:3 SET title = 'the lazy brown dog';
+		CREATE blog:4 SET title = 'a quiet sleepy cat';
+		CREATE blog:5 SET title = 'the dog sat there';
+		CREATE blog:6 SET title = 'the cat sat there';
+		SELECT id, search::score(1) AS score FROM blog WHERE title @1@ 'quick fox' ORDER BY score DESC;
+		SELECT id FROM blog WHERE title MATCHES 'QUICK FOXES' ORDER BY id;
+		UPDATE blog:2 SET title = 'quick dog';
+		SELECT id FROM blog WHERE title @@ 'quick fox';
+		DELETE blog:1;
+		SELECT id FROM blog WHERE title @@ 'quick fox';
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 14);
+	//
+	skip_ok(res, 8)?;
+	// Only the records with every term are returned, with the most relevant first
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp.pick(&[Part::from("id")]), Value::parse("[blog:2, blog:1]"));
+	// MATCHES is the same as @@, and terms are analyzed like the indexed text
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp, Value::parse("[{ id: blog:1 }, { id: blog:2 }]"));
+	// The index is kept up to date as records change
+	skip_ok(res, 1)?;
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp, Value::parse("[{ id: blog:1 }]"));
+	skip_ok(res, 1)?;
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp, Value::parse("[]"));
+	Ok(())
+}
@@ -56,3 +56,61 @@ async fn merge_record() -> Result<(), Error> {
 	//
 	Ok(())
 }
+
+#[tokio::test]
+async fn merge_patch_record() -> Result<(), Error> {
+	let sql = "
+		CREATE person:test CONTENT {
+			name: { first: 'Tobie', last: 'Morgan Hitchcock', initials: 'TMH' },
+			nickname: 'tobie',
+			tags: ['a', 'b'],
+		};
+		UPDATE person:test MERGE { name: { initials: NULL }, tags: ['c'] };
+		UPDATE person:test MERGE PATCH {
+			name: { title: 'Mr', initials: NULL },
+			nickname: NULL,
+			tags: ['d'],
+		};
+		UPDATE person:test MERGE PATCH ['e'];
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 4);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	// MERGE sets a field to NULL, and replaces arrays
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				id: person:test,
+				name: { first: 'Tobie', initials: NULL, last: 'Morgan Hitchcock' },
+				nickname: 'tobie',
+				tags: ['c'],
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	// MERGE PATCH removes a field which is NULL, at any depth
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				id: person:test,
+				name: { first: 'Tobie', last: 'Morgan Hitchcock', title: 'Mr' },
+				tags: ['d'],
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	// The patch must be an object
+	let tmp = res.remove(0).result;
+	assert!(matches!(
+		tmp.err(),
+		Some(e) if e.to_string() == r#"Can not use ['e'] in a MERGE clause"#
+	));
+	//
+	Ok(())
+}
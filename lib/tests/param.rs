@@ -2,6 +2,7 @@ mod parse;
 use parse::Parse;
 mod helpers;
 use helpers::new_ds;
+use std::collections::BTreeMap;
 use surrealdb::dbs::Session;
 use surrealdb::err::Error;
 use surrealdb::sql::Value;
@@ -101,3 +102,190 @@ async fn parameter_outside_database() -> Result<(), Error> {
 
 	Ok(())
 }
+
+#[tokio::test]
+async fn declared_params_are_coerced() -> Result<(), Error> {
+	let sql = "
+		LET $age: int;
+		LET $born: datetime;
+		LET $nickname: option<string>;
+		RETURN [$age, $born, $nickname];
+		LET $total: int = '10';
+		RETURN $total + $age;
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let vars = BTreeMap::from([
+		("age".to_owned(), Value::from("42")),
+		("born".to_owned(), Value::from("2024-01-01T00:00:00Z")),
+	]);
+	let res = &mut dbs.execute(sql, &ses, Some(vars)).await?;
+	assert_eq!(res.len(), 6);
+	//
+	for _ in 0..3 {
+		let tmp = res.remove(0).result;
+		assert!(tmp.is_ok());
+	}
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[42, d'2024-01-01T00:00:00Z', NONE]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("52");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn declared_param_is_missing() -> Result<(), Error> {
+	let sql = "
+		CREATE person:test;
+		LET $age: int;
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = dbs.execute(sql, &ses, None).await;
+	match res {
+		Err(Error::MissingParam {
+			name,
+			kind,
+		}) => {
+			assert_eq!(name, "age");
+			assert_eq!(kind, "int");
+		}
+		_ => panic!("Query should have failed with a missing parameter error: {res:?}"),
+	}
+	// The query fails before any statements are run
+	let res = &mut dbs.execute("SELECT * FROM person", &ses, None).await?;
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn declared_param_has_invalid_type() -> Result<(), Error> {
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let tests = [
+		("LET $age: int;", Value::from("042"), "int", "'042'"),
+		("LET $age: int;", Value::from("4.2"), "int", "'4.2'"),
+		("LET $age: datetime;", Value::from("2024-01-01"), "datetime", "'2024-01-01'"),
+		("LET $age: number;", Value::from(true), "number", "true"),
+		("LET $age: array<int>;", Value::parse("[1, 'two']"), "array<int>", "[1, 'two']"),
+	];
+	for (sql, val, expected, value) in tests {
+		let vars = BTreeMap::from([("age".to_owned(), val)]);
+		let res = dbs.execute(sql, &ses, Some(vars)).await;
+		match res {
+			Err(Error::InvalidParamType {
+				name,
+				kind,
+				value: v,
+			}) => {
+				assert_eq!(name, "age");
+				assert_eq!(kind, expected);
+				assert_eq!(v, value);
+			}
+			_ => panic!("Query should have failed with an invalid parameter error: {res:?}"),
+		}
+	}
+	// The values of typed LET statements are also checked
+	let res = &mut dbs.execute("LET $age: int = 'old'", &ses, None).await?;
+	match res.remove(0).result {
+		Err(Error::InvalidParamType {
+			name,
+			..
+		}) => assert_eq!(name, "age"),
+		res => panic!("Statement should have failed with an invalid parameter error: {res:?}"),
+	}
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn declared_params_make_unknown_params_an_error() -> Result<(), Error> {
+	let sql = "
+		LET $age: int;
+		RETURN $age + $agee;
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let vars = BTreeMap::from([("age".to_owned(), Value::from(42))]);
+	let res = &mut dbs.execute(sql, &ses, Some(vars)).await?;
+	assert_eq!(res.len(), 2);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	match res.remove(0).result {
+		Err(Error::UnknownParam {
+			name,
+		}) => assert_eq!(name, "agee"),
+		res => panic!("Statement should have failed with an unknown parameter error: {res:?}"),
+	}
+	// Queries without declarations still resolve unknown parameters to NONE
+	let res = &mut dbs.execute("RETURN $agee", &ses, None).await?;
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp, Value::None);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn strict_params_only_apply_to_the_query() -> Result<(), Error> {
+	let sql = "
+		DEFINE PARAM $limit VALUE 10;
+		DEFINE FUNCTION fn::missing() { RETURN $missing; };
+		DEFINE TABLE person PERMISSIONS FOR select WHERE $auth.admin = true;
+		DEFINE EVENT created ON person WHEN $event = 'CREATE' THEN {
+			CREATE log SET missing = $missing;
+		};
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	for res in res.drain(..) {
+		res.result?;
+	}
+	let sql = "
+		LET $name: string;
+		CREATE person:test SET name = $name, limit = $limit, other = fn::missing();
+		SELECT VALUE missing FROM log;
+		FOR $v IN [1, 2] { CREATE person SET value = $v, other = $other; };
+		SELECT count() FROM person GROUP ALL;
+	";
+	let vars = BTreeMap::from([("name".to_owned(), Value::from("Tobie"))]);
+	let res = &mut dbs.execute(sql, &ses, Some(vars)).await?;
+	assert_eq!(res.len(), 5);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	// Parameters in stored definitions resolve to NONE as normal
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:test, limit: 10, name: 'Tobie' }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[NONE]");
+	assert_eq!(tmp, val);
+	// The statement fails before any records are created
+	match res.remove(0).result {
+		Err(Error::UnknownParam {
+			name,
+		}) => assert_eq!(name, "other"),
+		res => panic!("Statement should have failed with an unknown parameter error: {res:?}"),
+	}
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ count: 1 }]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
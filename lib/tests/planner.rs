@@ -6,7 +6,7 @@ use helpers::{new_ds, skip_ok};
 use surrealdb::dbs::{Response, Session};
 use surrealdb::err::Error;
 use surrealdb::kvs::Datastore;
-use surrealdb::sql::Value;
+use surrealdb::sql::{Part, Value};
 
 #[tokio::test]
 async fn select_where_iterate_three_multi_index() -> Result<(), Error> {
@@ -2600,3 +2600,273 @@ async fn select_with_non_boolean_expression() -> Result<(), Error> {
 	//
 	Ok(())
 }
+
+#[tokio::test]
+async fn explain_statement_with_index() -> Result<(), Error> {
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	//
+	let sql = "
+		DEFINE INDEX idx_age ON TABLE person COLUMNS age;
+		CREATE person:tobie SET name = 'Tobie', age = 30;
+		CREATE person:jaime SET name = 'Jaime', age = 35;
+		EXPLAIN SELECT name FROM person WHERE age = 30;
+		EXPLAIN FULL SELECT name FROM person WHERE age = 30;
+	";
+	let mut res = dbs.execute(sql, &ses, None).await?;
+	//
+	assert_eq!(res.len(), 5);
+	skip_ok(&mut res, 3)?;
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		r#"{
+			fields: ['name'],
+			full_scan: false,
+			indexes: [
+				{
+					index: 'idx_age',
+					operator: '=',
+					value: 30
+				}
+			],
+			plan: [
+				{
+					detail: {
+						plan: {
+							index: 'idx_age',
+							operator: '=',
+							value: 30
+						},
+						table: 'person'
+					},
+					operation: 'Iterate Index'
+				},
+				{
+					detail: {
+						type: 'Memory'
+					},
+					operation: 'Collector'
+				}
+			],
+			steps: {
+				estimated: 1
+			}
+		}"#,
+	);
+	assert_eq!(format!("{:#}", tmp), format!("{:#}", val));
+	//
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp.pick(&[Part::from("full_scan")]), Value::Bool(false));
+	assert_eq!(tmp.pick(&[Part::from("steps"), Part::from("actual")]), Value::from(1));
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn explain_statement_without_index() -> Result<(), Error> {
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	//
+	let sql = "
+		DEFINE INDEX idx_age ON TABLE person COLUMNS age;
+		CREATE person:tobie SET name = 'Tobie', age = 30;
+		CREATE person:jaime SET name = 'Jaime', age = 35;
+		EXPLAIN SELECT * FROM person WHERE name = 'Tobie';
+		EXPLAIN FULL SELECT * FROM person WHERE name = 'Tobie';
+		EXPLAIN SELECT * FROM person:tobie, person:jaime;
+	";
+	let mut res = dbs.execute(sql, &ses, None).await?;
+	//
+	assert_eq!(res.len(), 6);
+	skip_ok(&mut res, 3)?;
+	//
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp.pick(&[Part::from("fields")]), Value::parse("['*']"));
+	assert_eq!(tmp.pick(&[Part::from("full_scan")]), Value::Bool(true));
+	assert_eq!(tmp.pick(&[Part::from("indexes")]), Value::parse("[]"));
+	assert_eq!(tmp.pick(&[Part::from("steps")]), Value::parse("{ estimated: 2 }"));
+	//
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp.pick(&[Part::from("full_scan")]), Value::Bool(true));
+	assert_eq!(tmp.pick(&[Part::from("steps")]), Value::parse("{ actual: 1, estimated: 2 }"));
+	//
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp.pick(&[Part::from("full_scan")]), Value::Bool(false));
+	assert_eq!(tmp.pick(&[Part::from("steps")]), Value::parse("{ estimated: 2 }"));
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn explain_statement_estimate_is_sampled() -> Result<(), Error> {
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	//
+	let sql = "
+		DEFINE INDEX idx_age ON TABLE person COLUMNS age;
+		CREATE |person:1500| SET age = 30;
+		CREATE |animal:10|;
+		EXPLAIN SELECT * FROM person;
+		EXPLAIN SELECT * FROM person WHERE age = 30;
+		EXPLAIN SELECT * FROM animal;
+	";
+	let mut res = dbs.execute(sql, &ses, None).await?;
+	//
+	assert_eq!(res.len(), 6);
+	skip_ok(&mut res, 3)?;
+	// Large tables and indexes are only counted up to the sample size
+	for _ in 0..2 {
+		let tmp = res.remove(0).result?;
+		let val = Value::parse("{ estimated: 1000, lower_bound: true }");
+		assert_eq!(tmp.pick(&[Part::from("steps")]), val);
+	}
+	//
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp.pick(&[Part::from("steps")]), Value::parse("{ estimated: 10 }"));
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn select_indexed_and_unindexed_results_match() -> Result<(), Error> {
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	//
+	let mut sql = "
+		DEFINE INDEX idx_number ON TABLE item COLUMNS number;
+		DEFINE INDEX uniq_name ON TABLE item COLUMNS name UNIQUE;
+		DEFINE INDEX idx_label_number ON TABLE item COLUMNS label, number;
+		DEFINE INDEX uniq_code_number ON TABLE item COLUMNS code, number UNIQUE;
+	"
+	.to_owned();
+	for i in 0..20 {
+		let label = if i % 2 == 0 {
+			"even"
+		} else {
+			"odd"
+		};
+		sql.push_str(&format!(
+			"CREATE item:{i} SET name = 'n{i}', number = {}, label = '{label}', code = {};",
+			i % 5,
+			i % 4
+		));
+	}
+	let mut res = dbs.execute(&sql, &ses, None).await?;
+	assert_eq!(res.len(), 24);
+	skip_ok(&mut res, 24)?;
+	//
+	for (cond, index) in [
+		("number = 3", "idx_number"),
+		("3 = number", "idx_number"),
+		("number > 2", "idx_number"),
+		("number >= 1 AND number < 3", "idx_number"),
+		("name = 'n7'", "uniq_name"),
+		("label = 'even'", "idx_label_number"),
+		("code = 2", "uniq_code_number"),
+	] {
+		let sql = format!(
+			"
+			SELECT * FROM item WHERE {cond} ORDER BY id;
+			SELECT * FROM item WITH NOINDEX WHERE {cond} ORDER BY id;
+			SELECT * FROM item WHERE {cond} EXPLAIN;
+		"
+		);
+		let mut res = dbs.execute(&sql, &ses, None).await?;
+		assert_eq!(res.len(), 3);
+		//
+		let indexed = res.remove(0).result?;
+		let unindexed = res.remove(0).result?;
+		assert_ne!(indexed, Value::parse("[]"), "No results for {cond}");
+		assert_eq!(format!("{:#}", indexed), format!("{:#}", unindexed), "Mismatch for {cond}");
+		//
+		let explain = res.remove(0).result?;
+		let plan = explain.pick(&[Part::from(0)]);
+		assert_eq!(plan.pick(&[Part::from("operation")]), Value::from("Iterate Index"), "{cond}");
+		let name = plan.pick(&[Part::from("detail"), Part::from("plan"), Part::from("index")]);
+		assert_eq!(name, Value::from(index), "{cond}");
+	}
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn select_with_index_hint() -> Result<(), Error> {
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	//
+	let sql = "
+		DEFINE INDEX idx_name ON TABLE person COLUMNS name;
+		DEFINE INDEX idx_age ON TABLE person COLUMNS age;
+		CREATE person:tobie SET name = 'Tobie', age = 30;
+		CREATE person:jaime SET name = 'Jaime', age = 30;
+		CREATE person:lizzie SET name = 'Tobie', age = 35;
+	";
+	let mut res = dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 5);
+	skip_ok(&mut res, 5)?;
+	// The hinted index is the one which is used
+	for index in ["idx_name", "idx_age"] {
+		let sql = format!(
+			"
+			SELECT name, age FROM person WITH INDEX {index} WHERE name = 'Tobie' AND age = 30;
+			SELECT name, age FROM person WITH INDEX {index} WHERE name = 'Tobie' AND age = 30 EXPLAIN;
+		"
+		);
+		let mut res = dbs.execute(&sql, &ses, None).await?;
+		assert_eq!(res.len(), 2);
+		check_result(&mut res, "[{ age: 30, name: 'Tobie' }]")?;
+		let explain = res.remove(0).result?;
+		let plan = explain.pick(&[Part::from(0)]);
+		assert_eq!(plan.pick(&[Part::from("operation")]), Value::from("Iterate Index"));
+		let name = plan.pick(&[Part::from("detail"), Part::from("plan"), Part::from("index")]);
+		assert_eq!(name, Value::from(index));
+	}
+	// A hinted index which can not be used falls back to a table scan
+	let sql = "
+		SELECT name, age FROM person WITH INDEX idx_age WHERE name = 'Jaime';
+		SELECT name, age FROM person WITH INDEX idx_age WHERE name = 'Jaime' EXPLAIN;
+	";
+	let mut res = dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 2);
+	check_result(&mut res, "[{ age: 30, name: 'Jaime' }]")?;
+	check_result(
+		&mut res,
+		"[
+			{
+				detail: {
+					table: 'person'
+				},
+				operation: 'Iterate Table'
+			},
+			{
+				detail: {
+					reason: 'WITH INDEX NOT APPLICABLE'
+				},
+				operation: 'Fallback'
+			},
+			{
+				detail: {
+					type: 'Memory'
+				},
+				operation: 'Collector'
+			}
+		]",
+	)?;
+	// A hinted index must exist on the table
+	let sql = "
+		SELECT name FROM person WITH INDEX idx_missing WHERE name = 'Tobie';
+		SELECT name FROM person WITH INDEX idx_name, idx_missing;
+	";
+	let mut res = dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 2);
+	for _ in 0..2 {
+		let tmp = res.remove(0).result;
+		assert!(
+			matches!(&tmp, Err(Error::IxNotFound { value }) if value == "idx_missing"),
+			"{tmp:?}"
+		);
+	}
+	//
+	Ok(())
+}
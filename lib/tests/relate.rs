@@ -180,3 +180,117 @@ async fn relate_with_param_or_subquery() -> Result<(), Error> {
 	assert_eq!(tmp, val);
 	Ok(())
 }
+
+#[tokio::test]
+async fn relate_recursive_traversal_with_cycle() -> Result<(), Error> {
+	let sql = "
+		CREATE person:a, person:b, person:c, person:d;
+		RELATE person:a->knows->person:b;
+		RELATE person:b->knows->person:c;
+		RELATE person:c->knows->person:a;
+		RELATE person:c->knows->person:d;
+		RETURN person:a->knows->person DEPTH;
+		RETURN person:a->knows->person DEPTH 2;
+		SELECT VALUE ->knows->person DEPTH 1 FROM person:b;
+		RETURN person:d->knows->person DEPTH 10;
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 9);
+	//
+	for _ in 0..5 {
+		let tmp = res.remove(0).result;
+		assert!(tmp.is_ok());
+	}
+	// The cycle back to person:a is not followed again
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[person:b, person:c, person:d]");
+	assert_eq!(tmp, val);
+	// The traversal stops once the depth is reached
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[person:b, person:c]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[[person:c]]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn relate_edge_properties_in_both_directions() -> Result<(), Error> {
+	let sql = "
+		CREATE person:a, person:b, person:c, person:d;
+		RELATE person:a->likes->person:b SET id = likes:1, weight = 5;
+		RELATE person:a->likes->person:c SET id = likes:2, weight = 2;
+		RELATE person:d->likes->person:b SET id = likes:3, weight = 4;
+		RETURN person:a->(likes WHERE weight > 3)->person;
+		RETURN person:b<-(likes WHERE weight > 3)<-person;
+		RETURN person:b<-(likes WHERE weight > 4)<-person;
+		SELECT VALUE ->(likes WHERE weight < 3)->person FROM person:a;
+		DELETE likes:1;
+		RETURN person:a->likes->person;
+		RETURN person:b<-likes<-person;
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 11);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	// The edge records persist their properties
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				id: likes:1,
+				in: person:a,
+				out: person:b,
+				weight: 5,
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	for _ in 0..2 {
+		let tmp = res.remove(0).result;
+		assert!(tmp.is_ok());
+	}
+	// Outgoing traversal filtered on the edge properties
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[person:b]");
+	assert_eq!(tmp, val);
+	// Incoming traversal filtered on the edge properties
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[person:a, person:d]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[person:a]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[person:c]");
+	assert_eq!(tmp, val);
+	// Deleting the edge removes it from both directions
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[person:c]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[person:d]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
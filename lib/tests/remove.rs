@@ -179,6 +179,37 @@ async fn remove_statement_analyzer() -> Result<(), Error> {
 	Ok(())
 }
 
+#[tokio::test]
+async fn remove_statement_analyzer_used_by_index() -> Result<(), Error> {
+	let sql = "
+		DEFINE ANALYZER simple TOKENIZERS blank,class FILTERS lowercase;
+		DEFINE INDEX ft_title ON book FIELDS title SEARCH ANALYZER simple BM25;
+		REMOVE ANALYZER simple;
+		REMOVE INDEX ft_title ON book;
+		REMOVE ANALYZER simple;
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 5);
+	for _ in 0..2 {
+		let tmp = res.remove(0).result;
+		assert!(tmp.is_ok());
+	}
+	// The analyzer is still used by the index
+	let tmp = res.remove(0).result.unwrap_err();
+	assert_eq!(
+		tmp.to_string(),
+		"The analyzer 'simple' is used by the index 'ft_title' on the table 'book'"
+	);
+	// Once the index is removed so can the analyzer be
+	for _ in 0..2 {
+		let tmp = res.remove(0).result;
+		assert!(tmp.is_ok());
+	}
+	Ok(())
+}
+
 #[tokio::test]
 async fn remove_statement_index() -> Result<(), Error> {
 	let sql = "
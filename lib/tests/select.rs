@@ -2,10 +2,11 @@ mod parse;
 use parse::Parse;
 mod helpers;
 use helpers::new_ds;
-use surrealdb::dbs::Session;
+use std::collections::BTreeMap;
+use surrealdb::dbs::{ResultStream, Session};
 use surrealdb::err::Error;
 use surrealdb::iam::Role;
-use surrealdb::sql::Value;
+use surrealdb::sql::{Part, Value};
 
 #[tokio::test]
 async fn select_field_value() -> Result<(), Error> {
@@ -1155,3 +1156,509 @@ async fn select_issue_3510() -> Result<(), Error> {
 	assert_eq!(format!("{:#}", tmp), format!("{:#}", val));
 	Ok(())
 }
+
+#[tokio::test]
+async fn select_distinct_on() -> Result<(), Error> {
+	let sql = "
+		CREATE purchase:1 SET customer = 'a', region = 'eu', total = 10;
+		CREATE purchase:2 SET customer = 'a', region = 'eu', total = 30;
+		CREATE purchase:3 SET customer = 'a', region = 'us', total = 20;
+		CREATE purchase:4 SET customer = 'b', region = 'eu', total = 5;
+		CREATE purchase:5 SET customer = NULL, region = 'eu', total = 7;
+		CREATE purchase:6 SET customer = NULL, region = 'eu', total = 9;
+		SELECT DISTINCT ON (customer, region) customer, region, total FROM purchase ORDER BY total DESC;
+		SELECT DISTINCT ON (customer, region) customer, region, total FROM purchase ORDER BY total DESC LIMIT 2 START 1;
+		SELECT DISTINCT ON (customer) * FROM purchase ORDER BY total;
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 9);
+	//
+	for _ in 0..6 {
+		let tmp = res.remove(0).result;
+		assert!(tmp.is_ok());
+	}
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{ customer: 'a', region: 'eu', total: 30 },
+			{ customer: 'a', region: 'us', total: 20 },
+			{ customer: NULL, region: 'eu', total: 9 },
+			{ customer: 'b', region: 'eu', total: 5 },
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{ customer: 'a', region: 'us', total: 20 },
+			{ customer: NULL, region: 'eu', total: 9 },
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{ customer: 'b', id: purchase:4, region: 'eu', total: 5 },
+			{ customer: NULL, id: purchase:5, region: 'eu', total: 7 },
+			{ customer: 'a', id: purchase:1, region: 'eu', total: 10 },
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn select_order_collate_and_nulls() -> Result<(), Error> {
+	let sql = "
+		CREATE person:1 SET name = 'Émile';
+		CREATE person:2 SET name = 'adam';
+		CREATE person:3 SET name = 'Zoe';
+		CREATE person:4 SET name = 'eve';
+		CREATE person:5 SET name = NULL;
+		CREATE person:6;
+		SELECT * FROM person ORDER BY name;
+		SELECT * FROM person ORDER BY name COLLATE;
+		SELECT * FROM person ORDER BY name COLLATE DESC;
+		SELECT * FROM person ORDER BY name COLLATE NULLS LAST, id;
+		SELECT * FROM person ORDER BY name COLLATE DESC NULLS FIRST, id;
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 11);
+	//
+	for _ in 0..6 {
+		let tmp = res.remove(0).result;
+		assert!(tmp.is_ok());
+	}
+	// Without COLLATE strings are ordered by their bytes
+	let tmp = res.remove(0).result?.pick(&[Part::from("id")]);
+	let val = Value::parse("[person:6, person:5, person:3, person:2, person:4, person:1]");
+	assert_eq!(tmp, val);
+	// With COLLATE case and accents are folded
+	let tmp = res.remove(0).result?.pick(&[Part::from("id")]);
+	let val = Value::parse("[person:6, person:5, person:2, person:1, person:4, person:3]");
+	assert_eq!(tmp, val);
+	// NONE and NULL values are otherwise the lowest values
+	let tmp = res.remove(0).result?.pick(&[Part::from("id")]);
+	let val = Value::parse("[person:3, person:4, person:1, person:2, person:5, person:6]");
+	assert_eq!(tmp, val);
+	// NULLS LAST places NONE and NULL values after all others
+	let tmp = res.remove(0).result?.pick(&[Part::from("id")]);
+	let val = Value::parse("[person:2, person:1, person:4, person:3, person:5, person:6]");
+	assert_eq!(tmp, val);
+	// NULLS FIRST places them first, whatever the direction
+	let tmp = res.remove(0).result?.pick(&[Part::from("id")]);
+	let val = Value::parse("[person:5, person:6, person:3, person:4, person:1, person:2]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn select_start_after_cursor() -> Result<(), Error> {
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let sql = "
+		CREATE item:1, item:2, item:3, item:4, item:5;
+		CREATE other:1;
+	";
+	dbs.execute(sql, &ses, None).await?;
+	let page = "SELECT id FROM item LIMIT 2 START AFTER $cursor";
+	// The first page starts from the beginning of the table
+	let res = &mut dbs.execute(page, &ses, None).await?;
+	let res = res.remove(0);
+	let cursor = res.cursor.clone().expect("a cursor for the next page");
+	assert_eq!(res.result?, Value::parse("[{ id: item:1 }, { id: item:2 }]"));
+	// Records created before or after the cursor do not shift the pages
+	dbs.execute("CREATE item:0, item:6", &ses, None).await?;
+	let vars = BTreeMap::from([("cursor".to_owned(), Value::from(cursor))]);
+	let res = &mut dbs.execute(page, &ses, Some(vars)).await?;
+	let res = res.remove(0);
+	let cursor = res.cursor.clone().expect("a cursor for the next page");
+	assert_eq!(res.result?, Value::parse("[{ id: item:3 }, { id: item:4 }]"));
+	//
+	let vars = BTreeMap::from([("cursor".to_owned(), Value::from(cursor))]);
+	let res = &mut dbs.execute(page, &ses, Some(vars)).await?;
+	let res = res.remove(0);
+	let cursor = res.cursor.clone().expect("a cursor for the next page");
+	assert_eq!(res.result?, Value::parse("[{ id: item:5 }, { id: item:6 }]"));
+	// The final page is empty, and has no cursor
+	let vars = BTreeMap::from([("cursor".to_owned(), Value::from(cursor.clone()))]);
+	let res = &mut dbs.execute(page, &ses, Some(vars)).await?;
+	let res = res.remove(0);
+	assert_eq!(res.cursor, None);
+	assert_eq!(res.result?, Value::parse("[]"));
+	// A cursor can not be used with a different table
+	let vars = BTreeMap::from([("cursor".to_owned(), Value::from(cursor.clone()))]);
+	let res =
+		&mut dbs.execute("SELECT id FROM other START AFTER $cursor", &ses, Some(vars)).await?;
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(Error::InvalidCursor { .. })), "{tmp:?}");
+	// A cursor which has been altered is rejected
+	let vars = BTreeMap::from([("cursor".to_owned(), Value::from(format!("{cursor}x")))]);
+	let res = &mut dbs.execute(page, &ses, Some(vars)).await?;
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(Error::InvalidCursor { .. })), "{tmp:?}");
+	// A cursor can not be used with a different ordering
+	let vars = BTreeMap::from([("cursor".to_owned(), Value::from(cursor.clone()))]);
+	let sql = "SELECT id FROM item ORDER BY id DESC START AFTER $cursor";
+	let res = &mut dbs.execute(sql, &ses, Some(vars)).await?;
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(Error::InvalidCursor { .. })), "{tmp:?}");
+	// A cursor must be a string
+	let res = &mut dbs.execute("SELECT id FROM item START AFTER 1", &ses, None).await?;
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(Error::InvalidCursor { .. })), "{tmp:?}");
+	// Numeric START clauses are not affected
+	let vars = BTreeMap::from([("start".to_owned(), Value::from(1))]);
+	let res =
+		&mut dbs.execute("SELECT id FROM item LIMIT 1 START $start", &ses, Some(vars)).await?;
+	let res = res.remove(0);
+	assert_eq!(res.cursor, None);
+	assert_eq!(res.result?, Value::parse("[{ id: item:1 }]"));
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn select_start_after_cursor_with_order() -> Result<(), Error> {
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let sql = "
+		CREATE item:1 SET score = 3;
+		CREATE item:2 SET score = 1;
+		CREATE item:3 SET score = 2;
+		CREATE item:4 SET score = 2;
+		CREATE item:5 SET score = 1;
+	";
+	dbs.execute(sql, &ses, None).await?;
+	let page = "SELECT id, score FROM item ORDER BY score DESC LIMIT 2 START AFTER $cursor";
+	// Records with the same score are ordered by their id
+	let res = &mut dbs.execute(page, &ses, None).await?;
+	let res = res.remove(0);
+	let cursor = res.cursor.clone().expect("a cursor for the next page");
+	assert_eq!(res.result?, Value::parse("[{ id: item:1, score: 3 }, { id: item:3, score: 2 }]"));
+	// Records created before the cursor do not shift the pages
+	dbs.execute("CREATE item:0 SET score = 5", &ses, None).await?;
+	let vars = BTreeMap::from([("cursor".to_owned(), Value::from(cursor))]);
+	let res = &mut dbs.execute(page, &ses, Some(vars)).await?;
+	let res = res.remove(0);
+	let cursor = res.cursor.clone().expect("a cursor for the next page");
+	assert_eq!(res.result?, Value::parse("[{ id: item:4, score: 2 }, { id: item:2, score: 1 }]"));
+	//
+	let vars = BTreeMap::from([("cursor".to_owned(), Value::from(cursor))]);
+	let res = &mut dbs.execute(page, &ses, Some(vars)).await?;
+	let res = res.remove(0);
+	let cursor = res.cursor.clone().expect("a cursor for the next page");
+	assert_eq!(res.result?, Value::parse("[{ id: item:5, score: 1 }]"));
+	// The final page is empty, and has no cursor
+	let vars = BTreeMap::from([("cursor".to_owned(), Value::from(cursor.clone()))]);
+	let res = &mut dbs.execute(page, &ses, Some(vars)).await?;
+	let res = res.remove(0);
+	assert_eq!(res.cursor, None);
+	assert_eq!(res.result?, Value::parse("[]"));
+	// A cursor can not be used without the ordering which created it
+	let vars = BTreeMap::from([("cursor".to_owned(), Value::from(cursor))]);
+	let sql = "SELECT id, score FROM item LIMIT 2 START AFTER $cursor";
+	let res = &mut dbs.execute(sql, &ses, Some(vars)).await?;
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(Error::InvalidCursor { .. })), "{tmp:?}");
+	// Cursors can not follow a random ordering
+	let sql = "SELECT id FROM item ORDER BY RAND() START AFTER NONE";
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(Error::CursorUnsupported)), "{tmp:?}");
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn select_with_stream() -> Result<(), Error> {
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	dbs.execute("CREATE |item:10000|", &ses, None).await?;
+	//
+	let sql = "
+		SELECT * FROM item;
+		SELECT * FROM item LIMIT 250;
+		SELECT * FROM item ORDER BY id LIMIT 3;
+	";
+	let (send, recv) = surrealdb::channel::bounded(1);
+	let stream = ResultStream::new(send, 100);
+	let (res, batches) = futures::join!(dbs.execute_with_stream(sql, &ses, None, stream), async {
+		let mut batches = Vec::new();
+		while let Ok(batch) = recv.recv().await {
+			batches.push((batch.query, batch.values.len()));
+		}
+		batches
+	});
+	let res = &mut res?;
+	assert_eq!(res.len(), 3);
+	// Streamed statements return no result in the response
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp, Value::None);
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp, Value::None);
+	// Statements which need the full result set are not streamed
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: item:1 }, { id: item:2 }, { id: item:3 }]");
+	assert_eq!(tmp, val);
+	// No batch holds more records than the batch size
+	assert!(batches.iter().all(|(_, len)| *len <= 100));
+	let count =
+		|query| batches.iter().filter(|(q, _)| *q == query).map(|(_, len)| len).sum::<usize>();
+	assert_eq!(count(0), 10000);
+	assert_eq!(count(1), 250);
+	assert_eq!(count(2), 0);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn select_with_stream_is_incremental() -> Result<(), Error> {
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	dbs.execute("CREATE |item:1000|", &ses, None).await?;
+	//
+	let (send, recv) = surrealdb::channel::bounded(1);
+	let stream = ResultStream::new(send, 10);
+	let query = dbs.execute_with_stream("SELECT * FROM item", &ses, None, stream);
+	futures::pin_mut!(query);
+	// The first batch is received while the statement is still running
+	let batch = tokio::select! {
+		biased;
+		res = &mut query => panic!("The query completed before any batch was received: {res:?}"),
+		batch = recv.recv() => batch.unwrap(),
+	};
+	assert_eq!(batch.query, 0);
+	assert_eq!(batch.values.len(), 10);
+	// The remaining batches are received as they are produced
+	let (res, count) = futures::join!(query, async {
+		let mut count = batch.values.len();
+		while let Ok(batch) = recv.recv().await {
+			count += batch.values.len();
+		}
+		count
+	});
+	let tmp = res?.remove(0).result?;
+	assert_eq!(tmp, Value::None);
+	assert_eq!(count, 1000);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn select_with_cache() -> Result<(), Error> {
+	let dbs = new_ds().await?.with_result_cache_size(1000);
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let sql = "CREATE person:tobie SET name = 'Tobie'; DEFINE FUNCTION fn::one() { RETURN 1; }";
+	dbs.execute(sql, &ses, None).await?;
+	//
+	let sql = "SELECT rand::uuid() AS key, name FROM person WHERE name != $name CACHE";
+	let (dbs, ses) = (&dbs, &ses);
+	let run = move |name: &str| {
+		let vars = BTreeMap::from([("name".to_owned(), Value::from(name))]);
+		async move { dbs.execute(sql, ses, Some(vars)).await.unwrap().remove(0).result.unwrap() }
+	};
+	// A cached result is served until it is invalidated
+	let first = run("").await;
+	assert_eq!(run("").await, first);
+	// Queries with different parameters are cached separately
+	let other = run("Jaime").await;
+	assert_ne!(other, first);
+	assert_eq!(run("Jaime").await, other);
+	// Queries without a CACHE clause are not cached
+	let sql = "SELECT rand::uuid() AS key FROM person";
+	let res = &mut dbs.execute(&format!("{sql}; {sql}"), ses, None).await?;
+	assert_ne!(res.remove(0).result?, res.remove(0).result?);
+	// Queries which do not only select from tables are not cached
+	let sql = "SELECT rand::uuid() AS key FROM (SELECT * FROM person) CACHE";
+	let res = &mut dbs.execute(&format!("{sql}; {sql}"), ses, None).await?;
+	assert_ne!(res.remove(0).result?, res.remove(0).result?);
+	// Queries which could read from other records are not cached
+	for sql in [
+		"SELECT rand::uuid() AS key, (SELECT VALUE name FROM post) AS titles FROM person CACHE",
+		"SELECT rand::uuid() AS key, ->likes->post AS likes FROM person CACHE",
+		"SELECT rand::uuid() AS key, best.title AS best FROM person CACHE",
+		"SELECT rand::uuid() AS key, fn::one() AS one FROM person CACHE",
+		"SELECT rand::uuid() AS key, best FROM person FETCH best CACHE",
+	] {
+		let res = &mut dbs.execute(&format!("{sql}; {sql}"), ses, None).await?;
+		assert_ne!(res.remove(0).result?, res.remove(0).result?, "{sql}");
+	}
+	// Writes to other tables leave the cached result in place
+	dbs.execute("CREATE post:one SET title = 'Hello'", ses, None).await?;
+	assert_eq!(run("").await, first);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn select_with_cache_disabled_by_default() -> Result<(), Error> {
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	dbs.execute("CREATE person:tobie SET name = 'Tobie'", &ses, None).await?;
+	//
+	let sql = "SELECT rand::uuid() AS key FROM person CACHE";
+	let res = &mut dbs.execute(&format!("{sql}; {sql}"), &ses, None).await?;
+	assert_ne!(res.remove(0).result?, res.remove(0).result?);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn select_with_cache_expiry() -> Result<(), Error> {
+	let dbs = new_ds().await?.with_result_cache_size(1000);
+	let ses = Session::owner().with_ns("test").with_db("test");
+	dbs.execute("CREATE person:tobie SET name = 'Tobie'", &ses, None).await?;
+	//
+	let sql = "SELECT rand::uuid() AS key FROM person CACHE 100ms";
+	let first = dbs.execute(sql, &ses, None).await?.remove(0).result?;
+	let tmp = dbs.execute(sql, &ses, None).await?.remove(0).result?;
+	assert_eq!(tmp, first);
+	// The cached result expires after the duration in the CACHE clause
+	tokio::time::sleep(std::time::Duration::from_millis(200)).await;
+	let tmp = dbs.execute(sql, &ses, None).await?.remove(0).result?;
+	assert_ne!(tmp, first);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn select_with_cache_invalidation() -> Result<(), Error> {
+	let dbs = new_ds().await?.with_result_cache_size(1000);
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let sql = "SELECT VALUE name FROM person ORDER BY name CACHE";
+	let (dbs, ses) = (&dbs, &ses);
+	let select =
+		move || async move { dbs.execute(sql, ses, None).await.unwrap().remove(0).result.unwrap() };
+	assert_eq!(select().await, Value::parse("[]"));
+	// Creating a record invalidates the cached result
+	dbs.execute("CREATE person:tobie SET name = 'Tobie'", ses, None).await?;
+	assert_eq!(select().await, Value::parse("['Tobie']"));
+	// Updating a record invalidates the cached result
+	dbs.execute("UPDATE person:tobie SET name = 'Jaime'", ses, None).await?;
+	assert_eq!(select().await, Value::parse("['Jaime']"));
+	// Deleting a record invalidates the cached result
+	dbs.execute("DELETE person:tobie", ses, None).await?;
+	assert_eq!(select().await, Value::parse("[]"));
+	// Cancelled writes are not visible
+	dbs.execute("BEGIN; CREATE person:tobie SET name = 'Tobie'; CANCEL", ses, None).await?;
+	assert_eq!(select().await, Value::parse("[]"));
+	// Uncommitted writes are visible within the transaction
+	let txn = format!("BEGIN; CREATE person:tobie SET name = 'Tobie'; {sql}; COMMIT");
+	let res = &mut dbs.execute(&txn, ses, None).await?;
+	assert_eq!(res.remove(1).result?, Value::parse("['Tobie']"));
+	assert_eq!(select().await, Value::parse("['Tobie']"));
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn select_window_aggregates() -> Result<(), Error> {
+	let sql = "
+		CREATE sale:1 SET region = 'eu', amount = 10, time = 1;
+		CREATE sale:2 SET region = 'eu', amount = 30, time = 2;
+		CREATE sale:3 SET region = 'us', amount = 20, time = 3;
+		CREATE sale:4 SET region = 'eu', amount = 5, time = 4;
+		SELECT id, math::sum(amount) OVER () AS total FROM sale;
+		SELECT id, time, math::sum(amount) OVER (ORDER BY time) AS running FROM sale ORDER BY time DESC;
+		SELECT id, count() OVER () AS total FROM sale WHERE region = 'eu' LIMIT 1;
+		SELECT VALUE math::max(amount) OVER () FROM sale;
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 8);
+	//
+	for _ in 0..4 {
+		let tmp = res.remove(0).result;
+		assert!(tmp.is_ok());
+	}
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{ id: sale:1, total: 65 },
+			{ id: sale:2, total: 65 },
+			{ id: sale:3, total: 65 },
+			{ id: sale:4, total: 65 },
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{ id: sale:4, time: 4, running: 65 },
+			{ id: sale:3, time: 3, running: 60 },
+			{ id: sale:2, time: 2, running: 40 },
+			{ id: sale:1, time: 1, running: 10 },
+		]",
+	);
+	assert_eq!(tmp, val);
+	// The aggregate is computed before the LIMIT clause is applied
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: sale:1, total: 3 }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[30, 30, 30, 30]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn select_where_with_top_level_comparisons() -> Result<(), Error> {
+	let sql = "
+		CREATE item:1 SET a = 1, b = 'x', z = true;
+		CREATE item:2 SET a = 2, b = 'y';
+		CREATE item:3 SET b = 'x', z = false;
+		CREATE item:4 SET a = <future> { 1 + 1 }, b = 'x';
+		CREATE item:5 SET a = [1, 2], b = 'x';
+		SELECT VALUE id FROM item WHERE a = 2;
+		SELECT VALUE id FROM item WHERE b = 'x' AND a >= 1;
+		SELECT VALUE id FROM item WHERE 2 > a AND z;
+		SELECT VALUE id FROM item WHERE a = NONE;
+		SELECT VALUE id FROM item:2..=4 WHERE b != 'y';
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 10);
+	//
+	for _ in 0..5 {
+		let tmp = res.remove(0).result;
+		assert!(tmp.is_ok());
+	}
+	// Values which need to be computed are still compared
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[item:2, item:4]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[item:1, item:4, item:5]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[item:1]");
+	assert_eq!(tmp, val);
+	// Missing fields are compared as NONE
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[item:3]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[item:3, item:4]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
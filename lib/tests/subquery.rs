@@ -354,3 +354,211 @@ async fn subquery_ifelse_array() -> Result<(), Error> {
 	//
 	Ok(())
 }
+
+#[tokio::test]
+async fn subquery_case() -> Result<(), Error> {
+	let sql = "
+		LET $status = 'open';
+		-- Compare the subject against each WHEN value
+		CASE $status WHEN 'closed' THEN 1 WHEN 'open' THEN 2 ELSE 3 END;
+		-- Fall through to the ELSE value
+		CASE $status WHEN 'closed' THEN 1 ELSE 3 END;
+		-- Return NULL when nothing matches and there is no ELSE
+		CASE $status WHEN 'closed' THEN 1 END;
+		-- Evaluate each WHEN as a condition when there is no subject
+		CASE WHEN $status = 'closed' THEN 1 WHEN $status = 'open' THEN 2 END;
+		-- Use a CASE expression within a projection
+		CREATE person:test SET age = 21;
+		SELECT CASE WHEN age >= 18 THEN 'adult' ELSE 'child' END AS category FROM person;
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 7);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::None;
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::from(2);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::from(3);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::Null;
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::from(2);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ age: 21, id: person:test }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ category: 'adult' }]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn subquery_ifelse_condition_errors() -> Result<(), Error> {
+	let sql = "
+		-- Report which branch failed to evaluate
+		IF false THEN 1 ELSE IF string::len(1, 2) THEN 2 END;
+		-- Non-boolean conditions are coerced by default
+		IF 'yes' THEN 1 ELSE 2 END;
+		-- Non-boolean conditions error in strict mode
+		OPTION STRICT_CONDITIONS;
+		IF 'yes' THEN 1 ELSE 2 END;
+		IF NONE THEN 1 ELSE 2 END;
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 4);
+	//
+	let tmp = res.remove(0).result;
+	assert!(
+		matches!(
+			tmp,
+			Err(Error::IfelseCondition {
+				branch: 2,
+				ref cond,
+				ref source,
+			}) if cond == "string::len(1, 2)" && matches!(**source, Error::InvalidArguments { .. })
+		),
+		"{tmp:?}"
+	);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::from(1);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result;
+	assert!(
+		matches!(
+			tmp,
+			Err(Error::IfelseConditionNotBool {
+				branch: 1,
+				..
+			})
+		),
+		"{tmp:?}"
+	);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::from(2);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn subquery_ifelse_parallel() -> Result<(), Error> {
+	let sql = "
+		CREATE person:test SET age = 21;
+		-- The first truthy branch wins
+		IF false THEN 1 ELSE IF true THEN 2 ELSE IF true THEN 3 END PARALLEL;
+		IF (SELECT VALUE age FROM ONLY person:test) > 18 THEN 'adult' ELSE IF true THEN 'child' END PARALLEL;
+		-- Errors are reported for the earliest failing branch
+		IF false THEN 1 ELSE IF string::len(1, 2) THEN 2 ELSE IF true THEN 3 END PARALLEL;
+		-- Errors in branches after the first truthy branch are ignored
+		IF true THEN 1 ELSE IF string::len(1, 2) THEN 2 END PARALLEL;
+		-- Writeable conditions are evaluated in order
+		IF (UPDATE person:test SET age += 1 RETURN VALUE age)[0] > 30 THEN 1 ELSE IF true THEN 2 END PARALLEL;
+		SELECT VALUE age FROM ONLY person:test;
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 7);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::from(2);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::from("adult");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result;
+	assert!(
+		matches!(
+			tmp,
+			Err(Error::IfelseCondition {
+				branch: 2,
+				..
+			})
+		),
+		"{tmp:?}"
+	);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::from(1);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::from(2);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::from(22);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn subquery_ifelse_branch_lets() -> Result<(), Error> {
+	let sql = "
+		-- The binding is available to the condition and the body
+		IF LET $x = 5; $x > 3 THEN $x * 2 ELSE 0 END;
+		-- The binding does not leak into the following branches
+		IF LET $x = 1; $x > 3 THEN $x ELSE IF $x = NONE THEN 'next' ELSE 'close' END;
+		IF LET $x = 1; $x > 3 { $x } ELSE { $x };
+		-- Each branch may have its own bindings
+		IF LET $x = 1; $x > 3 THEN $x ELSE IF LET $y = 10; $y > 3 THEN $y END;
+		-- Outer parameters are shadowed only within the branch
+		LET $z = 'outer';
+		IF LET $z = 'inner'; false THEN $z ELSE $z END;
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 6);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::from(10);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::from("next");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::None;
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::from(10);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::from("outer");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
@@ -0,0 +1,152 @@
+mod parse;
+use parse::Parse;
+mod helpers;
+use helpers::new_ds;
+use surrealdb::dbs::Session;
+use surrealdb::err::Error;
+use surrealdb::kvs::Datastore;
+use surrealdb::sql::Value;
+
+async fn run_tasks_at(dbs: &Datastore, ts: u64) {
+	for task in dbs.claim_tasks_at(ts).await {
+		dbs.run_task(task).await;
+	}
+}
+
+#[tokio::test]
+async fn define_statement_task() -> Result<(), Error> {
+	let sql = "
+		DEFINE TASK cleanup EVERY 1h THEN (DELETE tmp WHERE created < time::now() - 1d);
+		DEFINE TASK cleanup EVERY 1h THEN (DELETE tmp);
+		DEFINE TASK IF NOT EXISTS cleanup EVERY 1h THEN (DELETE tmp);
+		REMOVE TASK cleanup;
+		REMOVE TASK cleanup;
+		REMOVE TASK IF EXISTS cleanup;
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 6);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(
+		tmp.err(),
+		Some(e) if e.to_string() == "The task 'cleanup' already exists"
+	));
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(
+		tmp.err(),
+		Some(e) if e.to_string() == "The task 'cleanup' does not exist"
+	));
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn task_runs_on_schedule() -> Result<(), Error> {
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let sql = "
+		DEFINE TASK counter EVERY 1m THEN (UPSERT stats:task SET runs += 1);
+	";
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 1);
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	// The task first runs on the next interval boundary
+	run_tasks_at(&dbs, 90).await;
+	run_tasks_at(&dbs, 119).await;
+	let res = &mut dbs.execute("SELECT * FROM stats", &ses, None).await?;
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	// The task runs once per interval
+	run_tasks_at(&dbs, 120).await;
+	run_tasks_at(&dbs, 150).await;
+	run_tasks_at(&dbs, 180).await;
+	let res = &mut dbs.execute("SELECT * FROM stats", &ses, None).await?;
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: stats:task, runs: 2 }]");
+	assert_eq!(tmp, val);
+	// A removed task no longer runs
+	let res = &mut dbs.execute("REMOVE TASK counter", &ses, None).await?;
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	run_tasks_at(&dbs, 240).await;
+	let res = &mut dbs.execute("SELECT * FROM stats", &ses, None).await?;
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: stats:task, runs: 2 }]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn task_failure_is_rolled_back() -> Result<(), Error> {
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let sql = "
+		DEFINE TASK broken EVERY 10s THEN {
+			CREATE log:broken;
+			THROW 'failed';
+		};
+		DEFINE TASK working EVERY 10s THEN (CREATE log:working);
+	";
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 2);
+	for _ in 0..2 {
+		let tmp = res.remove(0).result;
+		assert!(tmp.is_ok());
+	}
+	run_tasks_at(&dbs, 0).await;
+	run_tasks_at(&dbs, 10).await;
+	// A failing task does not stop other tasks from running
+	let res = &mut dbs.execute("SELECT VALUE id FROM log", &ses, None).await?;
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[log:working]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn task_runs_do_not_overlap() -> Result<(), Error> {
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let sql = "
+		DEFINE TASK counter EVERY 10s THEN (UPSERT stats:task SET runs += 1);
+	";
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 1);
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	// The task is claimed once it is due
+	assert!(dbs.claim_tasks_at(0).await.is_empty());
+	let mut tasks = dbs.claim_tasks_at(10).await;
+	assert_eq!(tasks.len(), 1);
+	// The task is not claimed again while the previous run is in progress
+	assert!(dbs.claim_tasks_at(20).await.is_empty());
+	dbs.run_task(tasks.remove(0)).await;
+	// The skipped run is not caught up
+	assert!(dbs.claim_tasks_at(25).await.is_empty());
+	assert_eq!(dbs.claim_tasks_at(30).await.len(), 1);
+	let res = &mut dbs.execute("SELECT * FROM stats", &ses, None).await?;
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: stats:task, runs: 1 }]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
@@ -1,7 +1,7 @@
 mod parse;
 use parse::Parse;
 mod helpers;
-use helpers::new_ds;
+use helpers::{new_ds, skip_ok};
 use surrealdb::dbs::Session;
 use surrealdb::err::Error;
 use surrealdb::sql::Value;
@@ -185,3 +185,301 @@ async fn transaction_with_throw_and_return() -> Result<(), Error> {
 	//
 	Ok(())
 }
+
+#[tokio::test]
+async fn transaction_read_your_writes() -> Result<(), Error> {
+	let sql = "
+		BEGIN;
+		CREATE person:tobie SET name = 'Tobie';
+		UPDATE person:tobie SET name = 'Tobias';
+		SELECT name FROM person;
+		RETURN count(SELECT * FROM person);
+		COMMIT;
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 1);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("1");
+	assert_eq!(tmp, val);
+	//
+	let res = &mut dbs.execute("SELECT name FROM person", &ses, None).await?;
+	assert_eq!(res.len(), 1);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ name: 'Tobias' }]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn transaction_cancel_discards_writes() -> Result<(), Error> {
+	let sql = "
+		BEGIN;
+		CREATE person:tobie;
+		SELECT * FROM person;
+		CANCEL;
+		SELECT * FROM person;
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 3);
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(
+		tmp.err(),
+		Some(e) if e.to_string() == r#"The query was not executed due to a cancelled transaction"#
+	));
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(
+		tmp.err(),
+		Some(e) if e.to_string() == r#"The query was not executed due to a cancelled transaction"#
+	));
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn transaction_without_commit_is_cancelled() -> Result<(), Error> {
+	let sql = "
+		BEGIN;
+		CREATE person:tobie;
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 1);
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(
+		tmp.err(),
+		Some(e) if e.to_string() == r#"The query was not executed due to a cancelled transaction"#
+	));
+	//
+	let res = &mut dbs.execute("SELECT * FROM person", &ses, None).await?;
+	assert_eq!(res.len(), 1);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn transaction_with_failure_rolls_back_earlier_writes() -> Result<(), Error> {
+	let sql = "
+		CREATE person:jaime;
+		BEGIN;
+		CREATE person:tobie;
+		UPDATE person:jaime SET name = 'Jaime';
+		CREATE person:jaime;
+		COMMIT;
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 4);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(
+		tmp.err(),
+		Some(e) if e.to_string() == r#"The query was not executed due to a failed transaction"#
+	));
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(
+		tmp.err(),
+		Some(e) if e.to_string() == r#"The query was not executed due to a failed transaction"#
+	));
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(
+		tmp.err(),
+		Some(e) if e.to_string() == r#"Database record `person:jaime` already exists"#
+	));
+	//
+	let res = &mut dbs.execute("SELECT * FROM person", &ses, None).await?;
+	assert_eq!(res.len(), 1);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:jaime }]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn transaction_with_parse_error_makes_no_changes() -> Result<(), Error> {
+	let sql = "
+		BEGIN;
+		CREATE person:tobie;
+		CREATE person:jaime SET;
+		COMMIT;
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = dbs.execute(sql, &ses, None).await;
+	assert!(matches!(res, Err(Error::InvalidQuery(_))));
+	//
+	let res = &mut dbs.execute("SELECT * FROM person", &ses, None).await?;
+	assert_eq!(res.len(), 1);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn transaction_with_nested_savepoints() -> Result<(), Error> {
+	let sql = "
+		BEGIN;
+		CREATE person:one;
+		DEFINE SAVEPOINT first;
+		CREATE person:two;
+		UPDATE person:one SET name = 'One';
+		DEFINE SAVEPOINT second;
+		CREATE person:three;
+		DELETE person:two;
+		ROLLBACK TO SAVEPOINT second;
+		CREATE person:four;
+		ROLLBACK TO SAVEPOINT first;
+		CREATE person:five;
+		COMMIT;
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 11);
+	skip_ok(res, 11)?;
+	//
+	let res = &mut dbs.execute("SELECT * FROM person", &ses, None).await?;
+	assert_eq!(res.len(), 1);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				id: person:five,
+			},
+			{
+				id: person:one,
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn transaction_with_released_savepoint() -> Result<(), Error> {
+	let sql = "
+		BEGIN;
+		CREATE person:one;
+		DEFINE SAVEPOINT outer;
+		CREATE person:two;
+		DEFINE SAVEPOINT inner;
+		UPDATE person:one SET name = 'One';
+		CREATE person:three;
+		RELEASE SAVEPOINT inner;
+		SELECT VALUE id FROM person;
+		ROLLBACK TO SAVEPOINT outer;
+		SELECT VALUE id FROM person;
+		UPDATE person:one SET tags = ['first'];
+		COMMIT;
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 11);
+	skip_ok(res, 7)?;
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[person:one, person:three, person:two]");
+	assert_eq!(tmp, val);
+	//
+	skip_ok(res, 1)?;
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[person:one]");
+	assert_eq!(tmp, val);
+	//
+	skip_ok(res, 1)?;
+	//
+	let res = &mut dbs.execute("SELECT * FROM person", &ses, None).await?;
+	assert_eq!(res.len(), 1);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				id: person:one,
+				tags: ['first'],
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn transaction_with_missing_savepoint() -> Result<(), Error> {
+	let sql = "
+		DEFINE SAVEPOINT first;
+		BEGIN;
+		CREATE person:one;
+		DEFINE SAVEPOINT first;
+		RELEASE SAVEPOINT first;
+		ROLLBACK TO SAVEPOINT first;
+		COMMIT;
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 5);
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(
+		tmp.err(),
+		Some(e) if e.to_string() == r#"Savepoints can only be used within a transaction"#
+	));
+	//
+	for _ in 0..3 {
+		let tmp = res.remove(0).result;
+		assert!(matches!(
+			tmp.err(),
+			Some(e) if e.to_string() == r#"The query was not executed due to a failed transaction"#
+		));
+	}
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(
+		tmp.err(),
+		Some(e) if e.to_string() == r#"The savepoint 'first' does not exist"#
+	));
+	//
+	let res = &mut dbs.execute("SELECT * FROM person", &ses, None).await?;
+	assert_eq!(res.len(), 1);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
@@ -3,6 +3,7 @@ use parse::Parse;
 mod helpers;
 use crate::helpers::Test;
 use helpers::new_ds;
+use std::collections::BTreeMap;
 use surrealdb::dbs::Session;
 use surrealdb::err::Error;
 use surrealdb::iam::Role;
@@ -282,6 +283,247 @@ async fn update_with_return_clause() -> Result<(), Error> {
 	Ok(())
 }
 
+#[tokio::test]
+async fn update_with_return_modes() -> Result<(), Error> {
+	let sql = "
+		CREATE person:test CONTENT { name: 'Tobie', settings: { theme: { dark: false }, volume: 5 }, tags: ['a'] } RETURN NONE;
+		UPDATE person:test SET settings.volume = 7, settings.theme.dark = true, settings.lang = 'en', tags += 'b' RETURN DIFF;
+		UPDATE person:test SET settings.volume = 8 RETURN BEFORE;
+		UPDATE person:test SET settings.volume = 9 RETURN AFTER;
+		UPDATE person:test SET settings.volume = 10 RETURN name, settings.volume;
+		DELETE person:test RETURN BEFORE;
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 6);
+	// Nothing is output
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	// The changes to the nested objects are output
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			[
+				{ op: 'add', path: '/settings/lang', value: 'en' },
+				{ op: 'replace', path: '/settings/theme/dark', value: true },
+				{ op: 'replace', path: '/settings/volume', value: 7 },
+				{ op: 'add', path: '/tags/1', value: 'b' }
+			]
+		]",
+	);
+	assert_eq!(tmp, val);
+	// The record before the changes is output
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				id: person:test,
+				name: 'Tobie',
+				settings: { lang: 'en', theme: { dark: true }, volume: 7 },
+				tags: ['a', 'b']
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	// The record after the changes is output
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				id: person:test,
+				name: 'Tobie',
+				settings: { lang: 'en', theme: { dark: true }, volume: 9 },
+				tags: ['a', 'b']
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	// Only the projected fields are output
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				name: 'Tobie',
+				settings: { volume: 10 }
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				id: person:test,
+				name: 'Tobie',
+				settings: { lang: 'en', theme: { dark: true }, volume: 10 },
+				tags: ['a', 'b']
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn update_versioned_table() -> Result<(), Error> {
+	let sql = "
+		DEFINE TABLE person SCHEMAFULL VERSIONED;
+		DEFINE FIELD name ON person TYPE string;
+		CREATE person:test SET name = 'Tobie';
+		UPDATE person:test SET name = 'Jaime', version = 10 WHERE version = 1;
+		UPDATE person:test SET name = 'Tobie' WHERE version = 1;
+		UPDATE person:test SET name = 'Tobie' WHERE name = 'Jaime' AND version = 2;
+		UPDATE person SET name = 'Jaime' WHERE version = 1;
+		SELECT name, version FROM person;
+	";
+	let mut t = Test::new(sql).await?;
+	t.skip_ok(2)?;
+	t.expect_val("[{ id: person:test, name: 'Tobie', version: 1 }]")?;
+	t.expect_val("[{ id: person:test, name: 'Jaime', version: 2 }]")?;
+	t.expect_error("Database record `person:test` is at version 2, but version 1 was expected")?;
+	t.expect_val("[{ id: person:test, name: 'Tobie', version: 3 }]")?;
+	// Updates of many records skip the records at another version
+	t.expect_val("[]")?;
+	t.expect_val("[{ name: 'Tobie', version: 3 }]")?;
+	Ok(())
+}
+
+#[tokio::test]
+async fn update_versioned_lost_update_is_retried() -> Result<(), Error> {
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let sql = "
+		DEFINE TABLE account VERSIONED;
+		CREATE account:one SET balance = 100;
+	";
+	dbs.execute(sql, &ses, None).await?;
+	let read = "SELECT VALUE version FROM ONLY account:one";
+	let write = "UPDATE account:one SET balance += $amount WHERE version = $version";
+	// Both clients read the same version of the record
+	let first = dbs.execute(read, &ses, None).await?.remove(0).result?;
+	let second = dbs.execute(read, &ses, None).await?.remove(0).result?;
+	assert_eq!(first, Value::from(1));
+	assert_eq!(second, Value::from(1));
+	// The first client writes successfully
+	let vars =
+		BTreeMap::from([("amount".to_owned(), Value::from(10)), ("version".to_owned(), first)]);
+	let res = dbs.execute(write, &ses, Some(vars)).await?.remove(0).result?;
+	assert_eq!(res, Value::parse("[{ id: account:one, balance: 110, version: 2 }]"));
+	// The second client's write would lose the first update
+	let vars =
+		BTreeMap::from([("amount".to_owned(), Value::from(-5)), ("version".to_owned(), second)]);
+	let res = dbs.execute(write, &ses, Some(vars)).await?.remove(0).result;
+	assert!(matches!(
+		res,
+		Err(Error::RecordVersionConflict {
+			actual: 2,
+			..
+		})
+	));
+	// So it reads the record again and retries
+	let second = dbs.execute(read, &ses, None).await?.remove(0).result?;
+	let vars =
+		BTreeMap::from([("amount".to_owned(), Value::from(-5)), ("version".to_owned(), second)]);
+	let res = dbs.execute(write, &ses, Some(vars)).await?.remove(0).result?;
+	assert_eq!(res, Value::parse("[{ id: account:one, balance: 105, version: 3 }]"));
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn update_with_json_patch() -> Result<(), Error> {
+	let sql = "
+		CREATE person:test CONTENT { name: 'Tobie', tags: ['a', 'c'], address: { city: 'London' } };
+		UPDATE person:test PATCH [
+			{ op: 'add', path: '/tags/1', value: 'b' },
+			{ op: 'add', path: '/tags/-', value: 'd' },
+			{ op: 'replace', path: '/name', value: 'Jaime' },
+			{ op: 'copy', path: '/home', from: '/address' },
+			{ op: 'move', path: '/address/town', from: '/address/city' },
+			{ op: 'remove', path: '/tags/0' },
+			{ op: 'test', path: '/tags', value: ['b', 'c', 'd'] },
+		];
+		UPDATE person:test PATCH [
+			{ op: 'remove', path: '/home' },
+			{ op: 'test', path: '/name', value: 'Tobie' },
+		];
+		UPDATE person:test PATCH [{ op: 'remove', path: '/tags/5' }];
+		SELECT * FROM person:test;
+	";
+	let mut t = Test::new(sql).await?;
+	t.skip_ok(1)?;
+	let val = "[
+		{
+			address: { town: 'London' },
+			home: { city: 'London' },
+			id: person:test,
+			name: 'Jaime',
+			tags: ['b', 'c', 'd'],
+		}
+	]";
+	t.expect_val(val)?;
+	// A failing test operation leaves the record unchanged
+	t.expect_error(
+		"Given test operation failed for JSON Patch. Expected `'Tobie'`, but got `'Jaime'` instead.",
+	)?;
+	t.expect_error(
+		"The JSON Patch contains invalid operations. The path '/tags/5' does not exist",
+	)?;
+	t.expect_val(val)?;
+	Ok(())
+}
+
+#[tokio::test]
+async fn update_nested_array_with_filter() -> Result<(), Error> {
+	let sql = "
+		CREATE order:test CONTENT {
+			items: [
+				{ sku: 'a', qty: 1, tags: [{ name: 'x' }, { name: 'y' }] },
+				{ sku: 'b', qty: 2, tags: [{ name: 'x' }] },
+				{ sku: 'a', qty: 3, tags: [] },
+			]
+		};
+		UPDATE order:test SET items[WHERE sku = 'c'].qty = 5 RETURN VALUE items.qty;
+		UPDATE order:test SET items[WHERE sku = 'b'].qty = 5 RETURN VALUE items.qty;
+		UPDATE order:test SET items[WHERE sku = 'a'].qty = 10 RETURN VALUE items.qty;
+		UPDATE order:test SET items[WHERE sku = 'a'][0].qty = 0 RETURN VALUE items.qty;
+		UPDATE order:test SET items[WHERE qty > 1].tags[WHERE name = 'x'].seen = true RETURN VALUE items.tags;
+		UPDATE order:test SET missing[WHERE sku = 'c'].qty = 5 RETURN VALUE missing;
+		OPTION CREATE_PATHS = true;
+		UPDATE order:test SET items[WHERE sku = 'c'].qty = 5 RETURN VALUE items[WHERE sku = 'c'];
+		UPDATE order:test SET items[WHERE sku = 'c'].qty = 6 RETURN VALUE items[WHERE sku = 'c'];
+	";
+	let mut t = Test::new(sql).await?;
+	t.expect_size(9)?;
+	t.skip_ok(1)?;
+	// No elements match the filter
+	t.expect_val("[[1, 2, 3]]")?;
+	// A single element matches the filter
+	t.expect_val("[[1, 5, 3]]")?;
+	// All of the matching elements are updated
+	t.expect_val("[[10, 5, 10]]")?;
+	// Only the first matching element is updated
+	t.expect_val("[[0, 5, 10]]")?;
+	// Filters can be nested
+	t.expect_val(
+		"[[
+			[{ name: 'x' }, { name: 'y' }],
+			[{ name: 'x', seen: true }],
+			[],
+		]]",
+	)?;
+	// Missing arrays are not created by default
+	t.expect_val("[NONE]")?;
+	// A matching element is created if specified
+	t.expect_val("[[{ sku: 'c', qty: 5 }]]")?;
+	t.expect_val("[[{ sku: 'c', qty: 6 }]]")?;
+	Ok(())
+}
+
 //
 // Permissions
 //
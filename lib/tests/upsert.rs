@@ -282,6 +282,102 @@ async fn upsert_with_return_clause() -> Result<(), Error> {
 	Ok(())
 }
 
+#[tokio::test]
+async fn upsert_create_and_update_paths() -> Result<(), Error> {
+	let sql = "
+		UPSERT person:test SET name = 'Tobie', visits = 1;
+		UPSERT person:test SET visits += 1;
+		UPSERT person:test PATCH [{ op: 'replace', path: '/name', value: 'Jaime' }];
+		SELECT * FROM person;
+	";
+	let dbs = new_ds().await?;
+	let ses = Session::owner().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 4);
+	// The record does not exist, so it is created
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				id: person:test,
+				name: 'Tobie',
+				visits: 1
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	// The record exists, so it is updated
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				id: person:test,
+				name: 'Tobie',
+				visits: 2
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				id: person:test,
+				name: 'Jaime',
+				visits: 2
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				id: person:test,
+				name: 'Jaime',
+				visits: 2
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn upsert_concurrently_on_the_same_id() -> Result<(), Error> {
+	let dbs = std::sync::Arc::new(new_ds().await?);
+	let ses = Session::owner().with_ns("test").with_db("test");
+	// Run a number of upserts on the same record at the same time
+	let tasks = (0..10).map(|_| {
+		let dbs = dbs.clone();
+		let ses = ses.clone();
+		tokio::spawn(async move {
+			// An upsert may only fail if it conflicted with another one, so try it again
+			let mut attempts = 0;
+			loop {
+				let res = &mut dbs.execute("UPSERT counter:one SET n += 1", &ses, None).await?;
+				match res.remove(0).result {
+					Err(_) if attempts < 100 => attempts += 1,
+					res => return res,
+				}
+			}
+		})
+	});
+	for task in futures::future::join_all(tasks).await {
+		task.unwrap()?;
+	}
+	// Every upsert was applied exactly once to a single record
+	let res = &mut dbs.execute("SELECT * FROM counter", &ses, None).await?;
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: counter:one, n: 10 }]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
 //
 // Permissions
 //
@@ -4,7 +4,7 @@ use crate::helpers::{new_ds, skip_ok, Test};
 use parse::Parse;
 use surrealdb::dbs::Session;
 use surrealdb::err::Error;
-use surrealdb::sql::Value;
+use surrealdb::sql::{Part, Value};
 
 #[tokio::test]
 async fn select_where_mtree_knn() -> Result<(), Error> {
@@ -520,3 +520,82 @@ async fn select_bruteforce_knn_with_condition() -> Result<(), Error> {
 	//
 	Ok(())
 }
+
+#[tokio::test]
+async fn select_knn_indexes_match_bruteforce() -> Result<(), Error> {
+	let mut sql = String::new();
+	for i in 1..=30 {
+		sql.push_str(&format!(
+			"CREATE pts:{i} SET point = [{}, {}, {}];",
+			i % 7,
+			(i * i) % 11,
+			(3 * i) % 5
+		));
+	}
+	sql.push_str(
+		r"
+		DEFINE INDEX mt_pts ON pts FIELDS point MTREE DIMENSION 3 DIST EUCLIDEAN;
+		LET $pt = [3.2, 4.1, 1.3];
+		SELECT id, vector::distance::knn() AS dist FROM pts WHERE point <|6|> $pt ORDER BY dist, id;
+		REMOVE INDEX mt_pts ON pts;
+		DEFINE INDEX hnsw_pts ON pts FIELDS point HNSW DIMENSION 3 DIST EUCLIDEAN;
+		SELECT id, vector::distance::knn() AS dist FROM pts WHERE point <|6,40|> $pt ORDER BY dist, id;
+		REMOVE INDEX hnsw_pts ON pts;
+		SELECT id, vector::distance::knn() AS dist FROM pts WHERE point <|6,EUCLIDEAN|> $pt ORDER BY dist, id;
+	",
+	);
+	let mut t = Test::new(&sql).await?;
+	t.skip_ok(32)?;
+	let mtree = t.next_value()?;
+	t.skip_ok(2)?;
+	let hnsw = t.next_value()?;
+	t.skip_ok(1)?;
+	let brute = t.next_value()?;
+	let expected = Value::parse("[pts:24, pts:17, pts:2, pts:4, pts:9, pts:16]");
+	// The indexed searches must return the same neighbours as a full scan
+	for res in [&mtree, &hnsw, &brute] {
+		assert_eq!(res.pick(&[Part::from("id")]), expected, "{res:#}");
+	}
+	Ok(())
+}
+
+#[tokio::test]
+async fn knn_dimension_mismatch() -> Result<(), Error> {
+	let sql = r"
+		CREATE pts:1 SET point = [1,2,3];
+		CREATE pts:2 SET point = [4,5,6];
+		DEFINE INDEX mt_pts ON pts FIELDS point MTREE DIMENSION 3 DIST EUCLIDEAN;
+		CREATE pts:3 SET point = [7,8];
+		SELECT id FROM pts WHERE point <|1|> [1,2];
+		REMOVE INDEX mt_pts ON pts;
+		SELECT id FROM pts WHERE point <|1,EUCLIDEAN|> [1,2];
+	";
+	let mut t = Test::new(sql).await?;
+	t.skip_ok(3)?;
+	// Inserting a vector with the wrong dimension into an index
+	t.expect_error_func(|e| {
+		matches!(
+			e,
+			Error::InvalidVectorDimension {
+				current: 2,
+				expected: 3
+			}
+		)
+	})?;
+	// Querying an index with a vector of the wrong dimension
+	t.expect_error_func(|e| {
+		matches!(
+			e,
+			Error::InvalidVectorDimension {
+				current: 2,
+				expected: 3
+			}
+		)
+	})?;
+	t.skip_ok(1)?;
+	// Brute force comparison of vectors with different dimensions
+	t.expect_error(
+		"Incorrect arguments for function vector::distance::euclidean(). The two vectors must be of the same dimension.",
+	)?;
+	Ok(())
+}
@@ -43,6 +43,16 @@ pub static WEBSOCKET_MAX_MESSAGE_SIZE: Lazy<usize> =
 pub static WEBSOCKET_MAX_CONCURRENT_REQUESTS: Lazy<usize> =
 	lazy_env_parse!("SURREAL_WEBSOCKET_MAX_CONCURRENT_REQUESTS", usize, 24);
 
+/// How many records are sent in each batch when streaming query results (defaults to 1000)
+pub static STREAM_BATCH_SIZE: Lazy<usize> =
+	lazy_env_parse!("SURREAL_STREAM_BATCH_SIZE", usize, 1000);
+
+/// How many requests each client can make within each rate limit window (defaults to 0, which disables rate limiting)
+pub static RATE_LIMIT_REQUESTS: Lazy<u32> = lazy_env_parse!("SURREAL_RATE_LIMIT_REQUESTS", u32, 0);
+
+/// The number of seconds over which the rate limit is measured (defaults to 1)
+pub static RATE_LIMIT_WINDOW: Lazy<u64> = lazy_env_parse!("SURREAL_RATE_LIMIT_WINDOW", u64, 1);
+
 /// What is the runtime thread memory stack size (defaults to 10MiB)
 pub static RUNTIME_STACK_SIZE: Lazy<usize> =
 	lazy_env_parse_or_else!("SURREAL_RUNTIME_STACK_SIZE", usize, |_| {
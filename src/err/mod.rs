@@ -37,6 +37,9 @@ pub enum Error {
 	#[error("The operation is unsupported")]
 	OperationUnsupported,
 
+	#[error("Too many requests were made, please try again later")]
+	TooManyRequests,
+
 	#[error("There was a problem parsing the header {0}: {1}")]
 	InvalidHeader(HeaderName, TypedHeaderRejection),
 
@@ -195,6 +198,15 @@ impl IntoResponse for Error {
 					information: None,
 				}),
 			),
+			Error::TooManyRequests => (
+				StatusCode::TOO_MANY_REQUESTS,
+				Json(Message {
+					code: StatusCode::TOO_MANY_REQUESTS.as_u16(),
+					details: Some("Too many requests".to_string()),
+					description: Some("The rate limit for this client has been exceeded. Wait before making further requests.".to_string()),
+					information: Some(self.to_string()),
+				}),
+			),
 			Error::InvalidStorage => (
 				StatusCode::INTERNAL_SERVER_ERROR,
 				Json(Message {
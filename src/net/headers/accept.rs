@@ -9,6 +9,7 @@ pub enum Accept {
 	ApplicationJson,
 	ApplicationCbor,
 	ApplicationPack,
+	ApplicationNdjson,
 	ApplicationOctetStream,
 	Surrealdb,
 }
@@ -20,6 +21,7 @@ impl std::fmt::Display for Accept {
 			Accept::ApplicationJson => write!(f, "application/json"),
 			Accept::ApplicationCbor => write!(f, "application/cbor"),
 			Accept::ApplicationPack => write!(f, "application/pack"),
+			Accept::ApplicationNdjson => write!(f, "application/x-ndjson"),
 			Accept::ApplicationOctetStream => write!(f, "application/octet-stream"),
 			Accept::Surrealdb => write!(f, "application/surrealdb"),
 		}
@@ -44,6 +46,7 @@ impl Header for Accept {
 			"application/json" => Ok(Accept::ApplicationJson),
 			"application/cbor" => Ok(Accept::ApplicationCbor),
 			"application/pack" => Ok(Accept::ApplicationPack),
+			"application/x-ndjson" => Ok(Accept::ApplicationNdjson),
 			"application/octet-stream" => Ok(Accept::ApplicationOctetStream),
 			"application/surrealdb" => Ok(Accept::Surrealdb),
 			// TODO: Support more (all?) mime-types
@@ -0,0 +1,249 @@
+use crate::cnf::{RATE_LIMIT_REQUESTS, RATE_LIMIT_WINDOW};
+use crate::err::Error;
+use axum::extract::{ConnectInfo, State};
+use axum::middleware::Next;
+use axum::response::{IntoResponse, Response};
+use http::Request;
+use once_cell::sync::Lazy;
+use std::collections::HashMap;
+use std::net::{IpAddr, SocketAddr};
+use std::sync::Mutex;
+use std::time::{Duration, Instant};
+use surrealdb::dbs::Session;
+
+/// The rate limiter which is shared by all HTTP and WebSocket requests
+pub(crate) static RATE_LIMITER: Lazy<RateLimiter> =
+	Lazy::new(|| RateLimiter::new(*RATE_LIMIT_REQUESTS, Duration::from_secs(*RATE_LIMIT_WINDOW)));
+
+#[derive(Debug)]
+struct Bucket {
+	// The number of requests which can currently be made
+	tokens: f64,
+	// When the number of tokens was last calculated
+	updated: Instant,
+}
+
+#[derive(Debug)]
+struct Buckets {
+	// The bucket for each client which has made a recent request
+	clients: HashMap<String, Bucket>,
+	// When the idle buckets were last removed
+	swept: Instant,
+}
+
+/// A token bucket rate limiter, with a separate bucket for each client.
+///
+/// Each bucket holds enough tokens for the configured number of requests,
+/// and is refilled at a constant rate so that it is full again once the
+/// window has passed. A bucket which has been idle for the whole window
+/// is full, and so is no different from a new bucket. Such buckets are
+/// removed periodically, so that clients which only connect briefly do
+/// not use up memory.
+#[derive(Debug)]
+pub(crate) struct RateLimiter {
+	// The maximum number of requests in each window, or 0 if disabled
+	requests: u32,
+	// The period over which the bucket is refilled
+	window: Duration,
+	// The buckets, keyed by client
+	buckets: Mutex<Buckets>,
+}
+
+impl RateLimiter {
+	pub(crate) fn new(requests: u32, window: Duration) -> Self {
+		Self {
+			requests,
+			window,
+			buckets: Mutex::new(Buckets {
+				clients: HashMap::new(),
+				swept: Instant::now(),
+			}),
+		}
+	}
+	/// Check whether the client can make another request now
+	pub(crate) fn check(&self, key: &str) -> bool {
+		self.check_at(key, Instant::now())
+	}
+	/// Check whether the client can make another request at the given time
+	fn check_at(&self, key: &str, now: Instant) -> bool {
+		// Check if rate limiting is enabled
+		if self.requests == 0 {
+			return true;
+		}
+		let capacity = self.requests as f64;
+		let mut buckets = self.buckets.lock().unwrap_or_else(|e| e.into_inner());
+		// Remove any buckets which have been idle for a whole window
+		if now.saturating_duration_since(buckets.swept) >= self.window {
+			let window = self.window;
+			buckets.clients.retain(|_, b| now.saturating_duration_since(b.updated) < window);
+			buckets.swept = now;
+		}
+		let bucket = buckets.clients.entry(key.to_owned()).or_insert(Bucket {
+			tokens: capacity,
+			updated: now,
+		});
+		// Refill the bucket for the time since the last request
+		let elapsed = now.saturating_duration_since(bucket.updated).as_secs_f64();
+		let refill = match self.window.is_zero() {
+			true => capacity,
+			false => elapsed * capacity / self.window.as_secs_f64(),
+		};
+		bucket.tokens = (bucket.tokens + refill).min(capacity);
+		bucket.updated = now;
+		// Take a token if there is one available
+		if bucket.tokens >= 1.0 {
+			bucket.tokens -= 1.0;
+			true
+		} else {
+			false
+		}
+	}
+	#[cfg(test)]
+	fn len(&self) -> usize {
+		self.buckets.lock().unwrap().clients.len()
+	}
+}
+
+/// Identifies the client which is making a request.
+///
+/// Authenticated requests are limited for each user, so that a user with
+/// many connections shares a single limit. Anonymous requests are limited
+/// for the IP address of the connected peer, rather than the client IP of
+/// the session, which can be taken from request headers that the client
+/// controls. Anonymous requests share a single limit if the peer is not
+/// known.
+pub(crate) fn client_key(session: &Session, peer: Option<IpAddr>) -> String {
+	if !session.au.is_anon() {
+		return format!("{}{}", session.au.level(), session.au.id());
+	}
+	match peer {
+		Some(ip) => format!("ip:{ip}"),
+		None => String::from("anonymous"),
+	}
+}
+
+pub(super) async fn rate_limit_middleware<B>(
+	State(limiter): State<&'static RateLimiter>,
+	request: Request<B>,
+	next: Next<B>,
+) -> Response
+where
+	B: Send,
+{
+	// The session is added by the authentication layer
+	if let Some(session) = request.extensions().get::<Session>() {
+		let peer = request.extensions().get::<ConnectInfo<SocketAddr>>().map(|c| c.0.ip());
+		if !limiter.check(&client_key(session, peer)) {
+			return Error::TooManyRequests.into_response();
+		}
+	}
+	next.run(request).await
+}
+
+#[cfg(test)]
+mod tests {
+	use super::*;
+	use axum::routing::get;
+	use axum::{middleware, Router};
+	use http::StatusCode;
+	use hyper::Body;
+	use std::sync::Arc;
+	use std::thread;
+	use tower::ServiceExt;
+
+	#[test]
+	fn disabled_when_zero() {
+		let limiter = RateLimiter::new(0, Duration::from_secs(1));
+		let now = Instant::now();
+		for _ in 0..1000 {
+			assert!(limiter.check_at("client", now));
+		}
+		assert_eq!(limiter.len(), 0);
+	}
+
+	#[test]
+	fn throttles_and_recovers() {
+		let limiter = RateLimiter::new(3, Duration::from_secs(10));
+		let now = Instant::now();
+		// The client can make a burst of requests up to the limit
+		for _ in 0..3 {
+			assert!(limiter.check_at("client", now));
+		}
+		assert!(!limiter.check_at("client", now));
+		assert!(!limiter.check_at("client", now + Duration::from_secs(1)));
+		// Other clients are limited separately
+		assert!(limiter.check_at("other", now));
+		// A token is refilled after a third of the window
+		let now = now + Duration::from_secs(4);
+		assert!(limiter.check_at("client", now));
+		assert!(!limiter.check_at("client", now));
+		// The bucket is full again once the window has passed
+		let now = now + Duration::from_secs(10);
+		for _ in 0..3 {
+			assert!(limiter.check_at("client", now));
+		}
+		assert!(!limiter.check_at("client", now));
+	}
+
+	#[test]
+	fn idle_buckets_expire() {
+		let limiter = RateLimiter::new(5, Duration::from_secs(1));
+		let now = Instant::now();
+		for i in 0..100 {
+			assert!(limiter.check_at(&format!("client{i}"), now));
+		}
+		assert_eq!(limiter.len(), 100);
+		// Buckets which are still refilling are kept
+		assert!(limiter.check_at("active", now + Duration::from_millis(500)));
+		assert_eq!(limiter.len(), 101);
+		// Idle buckets are removed once the window has passed
+		assert!(limiter.check_at("active", now + Duration::from_millis(1200)));
+		assert_eq!(limiter.len(), 1);
+	}
+
+	#[test]
+	fn concurrent_requests_share_the_limit() {
+		let limiter = Arc::new(RateLimiter::new(50, Duration::from_secs(3600)));
+		let now = Instant::now();
+		let threads: Vec<_> = (0..8)
+			.map(|_| {
+				let limiter = limiter.clone();
+				thread::spawn(move || (0..20).filter(|_| limiter.check_at("client", now)).count())
+			})
+			.collect();
+		let allowed: usize = threads.into_iter().map(|t| t.join().unwrap()).sum();
+		assert_eq!(allowed, 50);
+	}
+
+	#[tokio::test]
+	async fn router_limits_anonymous_clients_by_peer() {
+		let limiter = Box::leak(Box::new(RateLimiter::new(2, Duration::from_secs(3600))));
+		let app = Router::new()
+			.route("/", get(|| async { "OK" }))
+			.layer(middleware::from_fn_with_state(&*limiter, rate_limit_middleware));
+		// The session is added by the authentication layer, with the client IP from the headers
+		let request = |peer: &str, ip: &str| {
+			let mut session = Session::default();
+			session.ip = Some(ip.to_owned());
+			Request::builder()
+				.uri("/")
+				.extension(session)
+				.extension(ConnectInfo(peer.parse::<SocketAddr>().unwrap()))
+				.body(Body::empty())
+				.unwrap()
+		};
+		// The client can make requests up to the limit
+		for _ in 0..2 {
+			let res = app.clone().oneshot(request("10.0.0.1:5000", "1.1.1.1")).await.unwrap();
+			assert_eq!(res.status(), StatusCode::OK);
+		}
+		let res = app.clone().oneshot(request("10.0.0.1:5000", "1.1.1.1")).await.unwrap();
+		assert_eq!(res.status(), StatusCode::TOO_MANY_REQUESTS);
+		// Changing the client IP header or the port does not reset the limit
+		let res = app.clone().oneshot(request("10.0.0.1:5001", "2.2.2.2")).await.unwrap();
+		assert_eq!(res.status(), StatusCode::TOO_MANY_REQUESTS);
+		// Other peers are limited separately
+		let res = app.clone().oneshot(request("10.0.0.2:5000", "1.1.1.1")).await.unwrap();
+		assert_eq!(res.status(), StatusCode::OK);
+	}
+}
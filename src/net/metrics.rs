@@ -0,0 +1,30 @@
+use crate::dbs::DB;
+use crate::err::Error;
+use axum::response::IntoResponse;
+use axum::routing::get;
+use axum::{Extension, Router};
+use http::header::CONTENT_TYPE;
+use http_body::Body as HttpBody;
+use surrealdb::dbs::Session;
+use surrealdb::iam::Action::View;
+use surrealdb::iam::ResourceKind::Any;
+
+/// The content type of the Prometheus text exposition format
+const PROMETHEUS_TEXT: &str = "text/plain; version=0.0.4; charset=utf-8";
+
+pub(super) fn router<S, B>() -> Router<S, B>
+where
+	B: HttpBody + Send + 'static,
+	S: Clone + Send + Sync + 'static,
+{
+	Router::new().route("/metrics", get(handler))
+}
+
+async fn handler(Extension(session): Extension<Session>) -> Result<impl IntoResponse, Error> {
+	// Get the datastore reference
+	let db = DB.get().unwrap();
+	// The metrics describe the whole server
+	db.check(&session, View, Any.on_root())?;
+	// Output the datastore metrics
+	Ok(([(CONTENT_TYPE, PROMETHEUS_TEXT)], db.metrics().prometheus()))
+}
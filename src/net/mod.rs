@@ -6,6 +6,8 @@ mod health;
 mod import;
 mod input;
 mod key;
+pub(crate) mod limiter;
+mod metrics;
 pub(crate) mod output;
 mod params;
 mod rpc;
@@ -137,6 +139,10 @@ pub async fn init(ct: CancellationToken) -> Result<(), Error> {
 		.layer(HttpMetricsLayer)
 		.layer(SetSensitiveResponseHeadersLayer::from_shared(headers))
 		.layer(AsyncRequireAuthorizationLayer::new(auth::SurrealAuth))
+		.layer(middleware::from_fn_with_state(
+			&*limiter::RATE_LIMITER,
+			limiter::rate_limit_middleware,
+		))
 		.layer(headers::add_server_header(!opt.no_identification_headers))
 		.layer(headers::add_version_header(!opt.no_identification_headers))
 		.layer(
@@ -160,6 +166,7 @@ pub async fn init(ct: CancellationToken) -> Result<(), Error> {
 		.route("/", get(|| async { Redirect::temporary(cnf::APP_ENDPOINT) }))
 		.route("/status", get(|| async {}))
 		.merge(health::router())
+		.merge(metrics::router())
 		.merge(export::router())
 		.merge(import::router())
 		.merge(rpc::router())
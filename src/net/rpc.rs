@@ -1,4 +1,5 @@
 use std::collections::BTreeMap;
+use std::net::{IpAddr, SocketAddr};
 use std::ops::Deref;
 
 use crate::cnf;
@@ -9,6 +10,7 @@ use crate::rpc::format::HttpFormat;
 use crate::rpc::post_context::PostRpcContext;
 use crate::rpc::response::IntoRpcResponse;
 use crate::rpc::WEBSOCKETS;
+use axum::extract::ConnectInfo;
 use axum::routing::get;
 use axum::routing::post;
 use axum::TypedHeader;
@@ -46,7 +48,10 @@ async fn get_handler(
 	ws: WebSocketUpgrade,
 	Extension(id): Extension<RequestId>,
 	Extension(sess): Extension<Session>,
+	peer: Option<ConnectInfo<SocketAddr>>,
 ) -> Result<impl IntoResponse, impl IntoResponse> {
+	// Get the IP address of the connected peer
+	let peer = peer.map(|ConnectInfo(addr)| addr.ip());
 	// Check if there is a request id header specified
 	let id = match id.header_value().is_empty() {
 		// No request id was specified so create a new id
@@ -77,10 +82,10 @@ async fn get_handler(
 		// Set the maximum WebSocket message size
 		.max_message_size(*cnf::WEBSOCKET_MAX_MESSAGE_SIZE)
 		// Handle the WebSocket upgrade and process messages
-		.on_upgrade(move |socket| handle_socket(socket, sess, id)))
+		.on_upgrade(move |socket| handle_socket(socket, sess, peer, id)))
 }
 
-async fn handle_socket(ws: WebSocket, sess: Session, id: Uuid) {
+async fn handle_socket(ws: WebSocket, sess: Session, peer: Option<IpAddr>, id: Uuid) {
 	// Check if there is a WebSocket protocol specified
 	let format = match ws.protocol().map(HeaderValue::to_str) {
 		// Any selected protocol will always be a valie value
@@ -90,7 +95,7 @@ async fn handle_socket(ws: WebSocket, sess: Session, id: Uuid) {
 	};
 	// Format::Unsupported is not in the PROTOCOLS list so cannot be the value of format here
 	// Create a new connection instance
-	let rpc = Connection::new(id, sess, format);
+	let rpc = Connection::new(id, sess, peer, format);
 	// Serve the socket connection requests
 	Connection::serve(rpc, ws).await;
 }
@@ -10,6 +10,7 @@ use bytes::Bytes;
 use http_body::Body as HttpBody;
 use serde::Serialize;
 use surrealdb::dbs::Session;
+use surrealdb::iam::token::Token;
 use surrealdb::sql::Value;
 use tower_http::limit::RequestBodyLimitLayer;
 
@@ -22,12 +23,19 @@ struct Success {
 	code: u16,
 	details: String,
 	token: Option<String>,
+	#[serde(skip_serializing_if = "Option::is_none")]
+	refresh: Option<String>,
 }
 
 impl Success {
-	fn new(token: Option<String>) -> Success {
+	fn new(token: Option<Token>) -> Success {
+		let (token, refresh) = match token {
+			Some(v) => (Some(v.access), v.refresh),
+			None => (None, None),
+		};
 		Success {
 			token,
+			refresh,
 			code: 200,
 			details: String::from("Authentication succeeded"),
 		}
@@ -69,7 +77,9 @@ async fn handler(
 					Some(Accept::ApplicationCbor) => Ok(output::cbor(&Success::new(v))),
 					Some(Accept::ApplicationPack) => Ok(output::pack(&Success::new(v))),
 					// Text serialization
-					Some(Accept::TextPlain) => Ok(output::text(v.unwrap_or_default())),
+					Some(Accept::TextPlain) => {
+						Ok(output::text(v.map(|v| v.access).unwrap_or_default()))
+					}
 					// Internal serialization
 					Some(Accept::Surrealdb) => Ok(output::full(&Success::new(v))),
 					// Return nothing
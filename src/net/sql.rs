@@ -1,3 +1,4 @@
+use crate::cnf::STREAM_BATCH_SIZE;
 use crate::dbs::DB;
 use crate::err::Error;
 use crate::net::input::bytes_to_utf8;
@@ -9,14 +10,21 @@ use axum::extract::DefaultBodyLimit;
 use axum::extract::Query;
 use axum::extract::WebSocketUpgrade;
 use axum::response::IntoResponse;
+use axum::response::Response;
 use axum::routing::options;
 use axum::Extension;
 use axum::Router;
 use axum::TypedHeader;
 use bytes::Bytes;
 use futures::{SinkExt, StreamExt};
+use http::header::{HeaderValue, CONTENT_TYPE};
+use http::StatusCode;
 use http_body::Body as HttpBody;
-use surrealdb::dbs::Session;
+use hyper::body::Body;
+use serde_json::json;
+use std::collections::BTreeMap;
+use surrealdb::dbs::{ResultStream, Session};
+use surrealdb::sql::Value;
 use tower_http::limit::RequestBodyLimitLayer;
 
 use super::headers::Accept;
@@ -41,11 +49,15 @@ async fn post_handler(
 	output: Option<TypedHeader<Accept>>,
 	params: Query<Params>,
 	sql: Bytes,
-) -> Result<impl IntoResponse, impl IntoResponse> {
+) -> Result<Response, Error> {
 	// Get a database reference
 	let db = DB.get().unwrap();
 	// Convert the received sql query
 	let sql = bytes_to_utf8(&sql)?;
+	// Stream the records of large SELECT statements
+	if let Some(Accept::ApplicationNdjson) = output.as_deref() {
+		return stream(sql, session, params.0.parse()).await;
+	}
 	// Execute the received sql query
 	match db.execute(sql, &session, params.0.parse().into()).await {
 		Ok(res) => match output.as_deref() {
@@ -57,12 +69,66 @@ async fn post_handler(
 			Some(Accept::Surrealdb) => Ok(output::full(&res)),
 			// An incorrect content-type was requested
 			_ => Err(Error::InvalidType),
-		},
+		}
+		.map(IntoResponse::into_response),
 		// There was an error when executing the query
 		Err(err) => Err(Error::from(err)),
 	}
 }
 
+/// Executes the query, sending each batch of records from large SELECT
+/// statements as a line of JSON as soon as it is produced. Each batch line
+/// holds the `query` index of the statement and the `result` records, and
+/// the last line holds the query responses, in which the streamed
+/// statements have a result of NONE.
+async fn stream(
+	sql: &str,
+	session: Session,
+	vars: BTreeMap<String, Value>,
+) -> Result<Response, Error> {
+	// Get a database reference
+	let db = DB.get().unwrap();
+	// Parse the query before responding, so that parse errors are returned
+	let ast = surrealdb::syn::parse(sql)?;
+	// Create a chunked response
+	let (mut chn, body) = Body::channel();
+	// Create a new bounded channel
+	let (snd, rcv) = surrealdb::channel::bounded(1);
+	// Spawn a new query execution job
+	tokio::spawn(async move {
+		let stream = ResultStream::new(snd, *STREAM_BATCH_SIZE);
+		// Send each batch of records as it is received
+		let send = async {
+			let rcv = rcv;
+			while let Ok(batch) = rcv.recv().await {
+				let line = json!({
+					"query": batch.query,
+					"result": output::simplify(batch.values),
+				});
+				// Stop the query if the client has gone away
+				if chn.send_data(Bytes::from(format!("{line}\n"))).await.is_err() {
+					break;
+				}
+			}
+		};
+		let (res, _) =
+			futures::join!(db.process_with_stream(ast, &session, Some(vars), stream), send);
+		// Send the query responses as the last line
+		let line = match res {
+			Ok(res) => output::simplify(res),
+			Err(err) => json!({ "error": Error::from(err).to_string() }),
+		};
+		let _ = chn.send_data(Bytes::from(format!("{line}\n"))).await;
+	});
+	// Return the chunked body
+	Ok(Response::builder()
+		.status(StatusCode::OK)
+		.header(CONTENT_TYPE, HeaderValue::from(Accept::ApplicationNdjson))
+		.body(body)
+		.unwrap()
+		.into_response())
+}
+
 async fn ws_handler(
 	ws: WebSocketUpgrade,
 	Extension(sess): Extension<Session>,
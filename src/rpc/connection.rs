@@ -1,10 +1,13 @@
 use crate::cnf::{
-	PKG_NAME, PKG_VERSION, WEBSOCKET_MAX_CONCURRENT_REQUESTS, WEBSOCKET_PING_FREQUENCY,
+	PKG_NAME, PKG_VERSION, STREAM_BATCH_SIZE, WEBSOCKET_MAX_CONCURRENT_REQUESTS,
+	WEBSOCKET_PING_FREQUENCY,
 };
 use crate::dbs::DB;
+use crate::err::Error;
+use crate::net::limiter::{client_key, RATE_LIMITER};
 use crate::rpc::failure::Failure;
 use crate::rpc::format::WsFormat;
-use crate::rpc::response::{failure, IntoRpcResponse};
+use crate::rpc::response::{failure, success, IntoRpcResponse};
 use crate::rpc::{CONN_CLOSED_ERR, LIVE_QUERIES, WEBSOCKETS};
 use crate::telemetry;
 use crate::telemetry::metrics::ws::RequestContext;
@@ -15,9 +18,10 @@ use futures_util::{SinkExt, StreamExt};
 use opentelemetry::trace::FutureExt;
 use opentelemetry::Context as TelemetryContext;
 use std::collections::BTreeMap;
+use std::net::IpAddr;
 use std::sync::Arc;
 use surrealdb::channel::{self, Receiver, Sender};
-use surrealdb::dbs::Session;
+use surrealdb::dbs::{ResultStream, Session};
 use surrealdb::kvs::Datastore;
 use surrealdb::rpc::args::Take;
 use surrealdb::rpc::format::Format;
@@ -37,6 +41,8 @@ pub struct Connection {
 	pub(crate) id: Uuid,
 	pub(crate) format: Format,
 	pub(crate) session: Session,
+	// The IP address of the connected peer
+	pub(crate) peer: Option<IpAddr>,
 	pub(crate) vars: BTreeMap<String, Value>,
 	pub(crate) limiter: Arc<Semaphore>,
 	pub(crate) canceller: CancellationToken,
@@ -45,7 +51,12 @@ pub struct Connection {
 
 impl Connection {
 	/// Instantiate a new RPC
-	pub fn new(id: Uuid, mut session: Session, format: Format) -> Arc<RwLock<Connection>> {
+	pub fn new(
+		id: Uuid,
+		mut session: Session,
+		peer: Option<IpAddr>,
+		format: Format,
+	) -> Arc<RwLock<Connection>> {
 		// Enable real-time mode
 		session.rt = true;
 		// Create and store the RPC connection
@@ -53,6 +64,7 @@ impl Connection {
 			id,
 			format,
 			session,
+			peer,
 			vars: BTreeMap::new(),
 			limiter: Arc::new(Semaphore::new(*WEBSOCKET_MAX_CONCURRENT_REQUESTS)),
 			canceller: CancellationToken::new(),
@@ -307,9 +319,27 @@ impl Connection {
 					let otel_cx = Arc::new(TelemetryContext::current_with_value(
 						req_cx.with_method(&req.method).with_size(len),
 					));
+					// Check the rate limit for this client
+					let key = {
+						let rpc = rpc.read().await;
+						client_key(&rpc.session, rpc.peer)
+					};
 					// Process the message
-					let res =
-						Connection::process_message(rpc.clone(), &req.method, req.params).await;
+					let res = match RATE_LIMITER.check(&key) {
+						true => match Method::parse(&req.method) {
+							// Send the streamed records before the response
+							Method::Stream => {
+								let id = req.id.clone();
+								Connection::process_stream(rpc.clone(), id, req.params, fmt, &chn)
+									.await
+							}
+							_ => {
+								Connection::process_message(rpc.clone(), &req.method, req.params)
+									.await
+							}
+						},
+						false => Err(Error::TooManyRequests.into()),
+					};
 					// Process the response
 					res.into_response(req.id)
 						.send(otel_cx.clone(), fmt, &chn)
@@ -331,6 +361,40 @@ impl Connection {
 		drop(permit);
 	}
 
+	/// Process a streamed query, sending the records of large SELECT statements
+	/// to the client in batches as they are produced. Each batch is sent before
+	/// the response, without an id, so that it does not complete the request.
+	/// The result of each batch holds the `stream` id of the request, the
+	/// `query` index of the statement, and the `result` records.
+	pub async fn process_stream(
+		rpc: Arc<RwLock<Connection>>,
+		id: Option<Value>,
+		params: Array,
+		fmt: Format,
+		chn: &Sender<Message>,
+	) -> Result<Data, Failure> {
+		debug!("Process RPC stream request");
+		let rpc = rpc.read().await;
+		// Create a new bounded channel
+		let (snd, rcv) = channel::bounded(1);
+		let stream = ResultStream::new(snd, *STREAM_BATCH_SIZE);
+		// Send each batch of records as it is received
+		let send = async {
+			let rcv = rcv;
+			while let Ok(batch) = rcv.recv().await {
+				let data = map! {
+					"stream" => id.clone().unwrap_or_default(),
+					"query" => Value::from(batch.query),
+					"result" => Value::from(batch.values),
+				};
+				let cx = Arc::new(TelemetryContext::current());
+				success(None, Value::from(data)).send(cx, fmt, chn).await;
+			}
+		};
+		let (res, _) = futures::join!(rpc.query_with_stream(params, stream), send);
+		res.map(Into::into).map_err(Into::into)
+	}
+
 	pub async fn process_message(
 		rpc: Arc<RwLock<Connection>>,
 		method: &str,
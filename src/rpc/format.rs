@@ -17,6 +17,7 @@ impl From<&Accept> for Format {
 			Accept::ApplicationJson => Format::Json,
 			Accept::ApplicationCbor => Format::Cbor,
 			Accept::ApplicationPack => Format::Msgpack,
+			Accept::ApplicationNdjson => Format::Unsupported,
 			Accept::ApplicationOctetStream => Format::Unsupported,
 			Accept::Surrealdb => Format::Bincode,
 		}
@@ -589,6 +589,38 @@ async fn query() -> Result<(), Box<dyn std::error::Error>> {
 	Ok(())
 }
 
+#[test(tokio::test)]
+async fn stream() -> Result<(), Box<dyn std::error::Error>> {
+	// Setup database server
+	let (addr, mut server) = common::start_server_with_defaults().await.unwrap();
+	// Connect to WebSocket
+	let mut socket = Socket::connect(&addr, SERVER, FORMAT).await?;
+	// Authenticate the connection
+	socket.send_message_signin(USER, PASS, None, None, None).await?;
+	// Specify a namespace and database
+	socket.send_message_use(Some(NS), Some(DB)).await?;
+	// Send STREAM command
+	let res = socket
+		.send_request("stream", json!(["CREATE |tester:250|; SELECT * FROM tester;",]))
+		.await?;
+	assert!(res.is_object(), "result: {:?}", res);
+	assert!(res["result"].is_array(), "result: {:?}", res);
+	let res = res["result"].as_array().unwrap();
+	assert_eq!(res.len(), 2, "result: {:?}", res);
+	// The streamed statement has no result in the response
+	assert!(res[1]["result"].is_null(), "result: {:?}", res);
+	// The records were sent in a batch before the response
+	let msgs = socket.receive_all_other_messages(1, Duration::from_secs(1)).await?;
+	let batch = &msgs[0]["result"];
+	assert_eq!(batch["query"], 1, "result: {:?}", batch);
+	assert!(batch["stream"].is_number(), "result: {:?}", batch);
+	let records = batch["result"].as_array().unwrap();
+	assert_eq!(records.len(), 250, "result: {:?}", batch);
+	// Test passed
+	server.finish().unwrap();
+	Ok(())
+}
+
 #[test(tokio::test)]
 async fn version() -> Result<(), Box<dyn std::error::Error>> {
 	// Setup database server
@@ -352,6 +352,63 @@ mod http_integration {
 		Ok(())
 	}
 
+	/// Read the value of a metric from the Prometheus text format
+	fn metric(body: &str, name: &str) -> f64 {
+		body.lines()
+			.find_map(|l| l.strip_prefix(name)?.strip_prefix(' ')?.parse().ok())
+			.unwrap_or_default()
+	}
+
+	#[test(tokio::test)]
+	async fn metrics_endpoint() -> Result<(), Box<dyn std::error::Error>> {
+		let (addr, _server) = common::start_server_with_defaults().await.unwrap();
+		let url = &format!("http://{addr}/metrics");
+		let client = Client::default();
+
+		// When no auth is provided, the endpoint returns a 403
+		let res = client.get(url).send().await?;
+		assert_eq!(res.status(), 403, "response: {:#?}", res);
+
+		// When root auth is provided, the endpoint returns the metrics
+		let res = client.get(url).basic_auth(USER, Some(PASS)).send().await?;
+		assert_eq!(res.status(), 200, "response: {:#?}", res);
+		let content_type = res.headers().get(header::CONTENT_TYPE).unwrap().to_str()?;
+		assert!(content_type.starts_with("text/plain; version=0.0.4"), "{content_type}");
+		let before = res.text().await?;
+		assert!(before.contains("# TYPE surrealdb_statements_total counter"), "body: {before}");
+
+		// Run some queries
+		let res = client
+			.post(format!("http://{addr}/sql"))
+			.basic_auth(USER, Some(PASS))
+			.header("surreal-ns", Ulid::new().to_string())
+			.header("surreal-db", Ulid::new().to_string())
+			.header(header::ACCEPT, "application/json")
+			.body("CREATE foo:1; CREATE foo:2; CREATE foo:1; SELECT * FROM foo;")
+			.send()
+			.await?;
+		assert_eq!(res.status(), 200);
+
+		// The counters have increased
+		let after = client.get(url).basic_auth(USER, Some(PASS)).send().await?.text().await?;
+		let diff = |name: &str| metric(&after, name) - metric(&before, name);
+		assert_eq!(diff("surrealdb_statements_total{type=\"create\"}"), 3.0, "body: {after}");
+		assert_eq!(diff("surrealdb_statements_total{type=\"select\"}"), 1.0, "body: {after}");
+		assert_eq!(diff("surrealdb_statement_errors_total{type=\"create\"}"), 1.0, "body: {after}");
+		assert_eq!(diff("surrealdb_statement_errors_total{type=\"select\"}"), 0.0, "body: {after}");
+		assert_eq!(
+			diff("surrealdb_statement_duration_seconds_count{type=\"create\"}"),
+			3.0,
+			"body: {after}"
+		);
+		assert!(diff("surrealdb_query_duration_seconds_count") >= 1.0, "body: {after}");
+		assert!(diff("surrealdb_kv_reads_total") > 0.0, "body: {after}");
+		assert!(diff("surrealdb_kv_writes_total") > 0.0, "body: {after}");
+		assert!(after.contains("surrealdb_active_transactions "), "body: {after}");
+
+		Ok(())
+	}
+
 	#[test(tokio::test)]
 	async fn no_server_id_headers() -> Result<(), Box<dyn std::error::Error>> {
 		// default server has the id headers
@@ -887,6 +944,58 @@ mod http_integration {
 		Ok(())
 	}
 
+	#[test(tokio::test)]
+	async fn sql_endpoint_with_stream() -> Result<(), Box<dyn std::error::Error>> {
+		let (addr, _server) = common::start_server_with_defaults().await.unwrap();
+		let url = &format!("http://{addr}/sql");
+
+		// Prepare HTTP client
+		let mut headers = reqwest::header::HeaderMap::new();
+		headers.insert("surreal-ns", Ulid::new().to_string().parse()?);
+		headers.insert("surreal-db", Ulid::new().to_string().parse()?);
+		headers.insert(header::ACCEPT, "application/x-ndjson".parse()?);
+
+		let client = reqwest::Client::builder()
+			.connect_timeout(Duration::from_millis(10))
+			.default_headers(headers)
+			.build()?;
+
+		// The records of SELECT statements are sent before the responses
+		{
+			let res = client
+				.post(url)
+				.basic_auth(USER, Some(PASS))
+				.body("CREATE |item:250|; SELECT * FROM item; SELECT * FROM item ORDER BY id LIMIT 1;")
+				.send()
+				.await?;
+			assert_eq!(res.status(), 200);
+			assert_eq!(res.headers()[header::CONTENT_TYPE], "application/x-ndjson");
+
+			let body = res.text().await?;
+			let lines = body
+				.lines()
+				.map(serde_json::from_str)
+				.collect::<Result<Vec<serde_json::Value>, _>>()?;
+			assert_eq!(lines.len(), 2, "body: {}", body);
+			assert_eq!(lines[0]["query"], 1, "body: {}", body);
+			assert_eq!(lines[0]["result"].as_array().unwrap().len(), 250, "body: {}", body);
+			// Streamed statements have no result in the responses
+			let responses = lines[1].as_array().unwrap();
+			assert_eq!(responses.len(), 3, "body: {}", body);
+			assert_eq!(responses[1]["status"], "OK", "body: {}", body);
+			assert_eq!(responses[1]["result"], serde_json::Value::Null, "body: {}", body);
+			assert_eq!(responses[2]["result"], json!([{ "id": "item:1" }]), "body: {}", body);
+		}
+
+		// Invalid queries return an error before streaming
+		{
+			let res = client.post(url).basic_auth(USER, Some(PASS)).body("SELEC").send().await?;
+			assert_eq!(res.status(), 400);
+		}
+
+		Ok(())
+	}
+
 	#[test(tokio::test)]
 	#[cfg(feature = "http-compression")]
 	async fn sql_endpoint_with_compression() -> Result<(), Box<dyn std::error::Error>> {